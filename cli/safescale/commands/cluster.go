@@ -1391,7 +1391,7 @@ var clusterAddFeatureCommand = cli.Command{
 		if !results.Successful() {
 			msg := fmt.Sprintf("failed to install feature '%s' on cluster '%s'", featureName, clusterName)
 			if Debug || Verbose {
-				msg += fmt.Sprintf(":\n%s", results.AllErrorMessages())
+				msg += fmt.Sprintf(":\n%s", results.Report())
 			}
 			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.Run, msg))
 		}
@@ -1457,7 +1457,7 @@ var clusterCheckFeatureCommand = cli.Command{
 		if !results.Successful() {
 			msg := fmt.Sprintf("Feature '%s' not found on cluster '%s'", featureName, clusterName)
 			if Verbose || Debug {
-				msg += fmt.Sprintf(":\n%s", results.AllErrorMessages())
+				msg += fmt.Sprintf(":\n%s", results.Report())
 			}
 			return clitools.FailureResponse(clitools.ExitOnNotFound(msg))
 		}
@@ -1523,7 +1523,7 @@ var clusterDeleteFeatureCommand = cli.Command{
 		if !results.Successful() {
 			msg := fmt.Sprintf("failed to delete feature '%s' from cluster '%s'", featureName, clusterName)
 			if Verbose || Debug {
-				msg += fmt.Sprintf(":\n%s\n", results.AllErrorMessages())
+				msg += fmt.Sprintf(":\n%s\n", results.Report())
 			}
 			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.Run, msg))
 		}
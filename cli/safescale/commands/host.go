@@ -19,8 +19,11 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/sirupsen/logrus"
 
@@ -29,6 +32,8 @@ import (
 	pb "github.com/CS-SI/SafeScale/lib"
 	"github.com/CS-SI/SafeScale/lib/client"
 	"github.com/CS-SI/SafeScale/lib/server/install"
+	"github.com/CS-SI/SafeScale/lib/server/install/enums/method"
+	"github.com/CS-SI/SafeScale/lib/system"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	clitools "github.com/CS-SI/SafeScale/lib/utils/cli"
 	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/exitcode"
@@ -44,17 +49,32 @@ var HostCmd = cli.Command{
 	Usage: "host COMMAND",
 	Subcommands: []cli.Command{
 		hostList,
+		hostAudit,
 		hostCreate,
 		hostResize,
 		hostDelete,
 		hostInspect,
 		hostStatus,
+		hostMetrics,
+		hostTop,
 		hostSSH,
 		hostReboot,
 		hostStart,
 		hostStop,
+		hostFreeze,
+		hostThaw,
+		hostIPForwarding,
+		hostSecurityGroups,
+		hostSysctl,
+		hostPortForward,
+		hostConsole,
+		hostSnapshot,
+		hostRename,
+		hostClone,
+		hostCheckConnectivity,
 		hostCheckFeatureCommand,
 		hostAddFeatureCommand,
+		hostEnsureFeatureCommand,
 		hostDeleteFeatureCommand,
 		hostListFeaturesCommand,
 	},
@@ -142,6 +162,368 @@ var hostReboot = cli.Command{
 	},
 }
 
+var hostConsole = cli.Command{
+	Name:      "console",
+	Usage:     "Shows the console output of Host, available even if Host is not reachable with SSH",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "lines, n",
+			Value: 100,
+			Usage: "Number of lines to show from the end of the console output, 0 meaning all",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+		if c.Int("lines") < 0 {
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("--lines cannot be negative."))
+		}
+
+		output, err := client.New().Host.Console(c.Args().First(), c.Int("lines"), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "console of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(output)
+	},
+}
+
+var hostSnapshot = cli.Command{
+	Name:      "snapshot",
+	Usage:     "Creates a snapshot of the boot disk of Host, usable with 'host create --from-snapshot'",
+	ArgsUsage: "<Host_name|Host_ID> <Snapshot_name>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "quiesce",
+			Usage: "Suspends the writes on the root filesystem of Host during the snapshot, to get a consistent one",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(
+				clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name> or <Snapshot_name>."),
+			)
+		}
+
+		snapshot, err := client.New().Host.Snapshot(
+			c.Args().Get(0), c.Args().Get(1), c.Bool("quiesce"), temporal.GetExecutionTimeout(),
+		)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "snapshot of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(snapshot)
+	},
+}
+
+var hostRename = cli.Command{
+	Name:      "rename",
+	Usage:     "Renames Host; the instance is renamed too if the provider allows it",
+	ArgsUsage: "<Host_name|Host_ID> <New_name>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(
+				clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name> or <New_name>."),
+			)
+		}
+
+		host, err := client.New().Host.Rename(c.Args().Get(0), c.Args().Get(1), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "rename of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(host)
+	},
+}
+
+var hostClone = cli.Command{
+	Name:      "clone",
+	Usage:     "Creates a Host configured as another one (sizing, networks, security groups, features); data are not copied, use snapshots for that",
+	ArgsUsage: "<Host_name|Host_ID> <New_name>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "S, sizing",
+			Usage: `Replaces the sizing of the source Host, in the format of "safescale host create --sizing"`,
+		},
+		cli.StringFlag{
+			Name:  "net, network",
+			Usage: "Replaces the networks of the source Host (comma-separated list, the first one is the default one)",
+		},
+		cli.BoolFlag{
+			Name:  "with-volumes",
+			Usage: "Creates empty volumes of the same size, speed and mount point as the ones of the source Host",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(
+				clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name> or <New_name>."),
+			)
+		}
+
+		var sizing *pb.HostSizing
+		if c.IsSet("sizing") {
+			def, err := constructPBHostDefinitionFromCLI(c, "sizing")
+			if err != nil {
+				return err
+			}
+			sizing = def.Sizing
+		}
+
+		host, err := client.New().Host.Clone(
+			c.Args().Get(0), c.Args().Get(1), sizing, c.String("net"), c.Bool("with-volumes"),
+			temporal.GetExecutionTimeout(),
+		)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "clone of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(host)
+	},
+}
+
+var hostCheckConnectivity = cli.Command{
+	Name:      "check-connectivity",
+	Usage:     "Checks from Host the reachability of other hosts or endpoints",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "to",
+			Usage: "Target to check, as <host>:<port> or as URL; can be used several times",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+		if len(c.StringSlice("to")) == 0 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory option --to."))
+		}
+
+		results, err := client.New().Host.CheckConnectivity(c.Args().First(), c.StringSlice("to"), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "check of host connectivity", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(results.GetResults())
+	},
+}
+
+var hostFreeze = cli.Command{
+	Name:      "freeze",
+	Usage:     "Protects Host against deletion, resize, stop, reboot and any other change (unless safescale --ignore-frozen)",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+
+		err := client.New().Host.Freeze(c.Args().First(), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "freeze of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(nil)
+	},
+}
+
+var hostThaw = cli.Command{
+	Name:      "thaw",
+	Usage:     "Removes the protection of Host set by freeze",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+
+		err := client.New().Host.Thaw(c.Args().First(), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "thaw of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(nil)
+	},
+}
+
+var hostIPForwarding = cli.Command{
+	Name:      "ip-forwarding",
+	Usage:     "Allows (--enable) or forbids (--disable) Host to forward traffic not addressed to it, to act as NAT or VPN host",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "enable",
+			Usage: "Allows IP forwarding",
+		},
+		cli.BoolFlag{
+			Name:  "disable",
+			Usage: "Forbids IP forwarding",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+		if c.Bool("enable") == c.Bool("disable") {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Exactly one of --enable and --disable is required."))
+		}
+
+		err := client.New().Host.SetIPForwarding(c.Args().First(), c.Bool("enable"), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "change of IP forwarding of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(nil)
+	},
+}
+
+var hostSecurityGroups = cli.Command{
+	Name:      "security-groups",
+	Usage:     "Replaces the security groups bound to Host by the ones given (none unbinding them all), keeping the ones of its network",
+	ArgsUsage: "<Host_name|Host_ID> [<security_group>...]",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() < 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+
+		err := client.New().Host.ReplaceSecurityGroups(c.Args().First(), c.Args().Tail(), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "replacement of security groups of host", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(nil)
+	},
+}
+
+var hostSysctl = cli.Command{
+	Name:      "sysctl",
+	Usage:     "Applies kernel parameters on Host, at runtime and after reboot; displays their previous values",
+	ArgsUsage: "<Host_name|Host_ID> <name>=<value>...",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() < 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(
+				clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name> or <name>=<value>."),
+			)
+		}
+		params := map[string]string{}
+		for _, v := range c.Args().Tail() {
+			parts := strings.SplitN(v, "=", 2)
+			if len(parts) != 2 {
+				return clitools.FailureResponse(
+					clitools.ExitOnInvalidArgument(fmt.Sprintf("Invalid kernel parameter '%s', expected '<name>=<value>'.", v)),
+				)
+			}
+			params[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+
+		previous, err := client.New().Host.ApplyKernelParameters(c.Args().First(), params, temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "application of host kernel parameters", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(map[string]interface{}{"previous": previous.GetParameters()})
+	},
+}
+
+var hostPortForward = cli.Command{
+	Name:      "port-forward",
+	Usage:     "Forwards a local port to a service reachable from Host, through its gateways, until interrupted",
+	ArgsUsage: "<Host_name|Host_ID> [<local_port>:]<remote_host>:<remote_port>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(
+				clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name> or <remote_host>:<remote_port>."),
+			)
+		}
+		localPort, remoteHost, remotePort, err := parsePortForward(c.Args().Get(1))
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument(err.Error()))
+		}
+
+		port, tunnels, err := client.New().Host.PortForward(
+			c.Args().First(), localPort, remoteHost, remotePort, temporal.GetExecutionTimeout(),
+		)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "port forwarding", false).Error())),
+			)
+		}
+
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(stop)
+		fmt.Fprintf(
+			os.Stderr, "Forwarding 127.0.0.1:%d to %s:%d through host '%s', interrupt to stop\n", port, remoteHost,
+			remotePort, c.Args().First(),
+		)
+		<-stop
+
+		err = system.CloseTunnels(tunnels)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnErrorWithMessage(exitcode.Run, fmt.Sprintf("Failed to stop port forwarding: %s", err.Error())),
+			)
+		}
+		return clitools.SuccessResponse(map[string]interface{}{"local_port": port})
+	},
+}
+
+// parsePortForward parses the forwarding "[<local_port>:]<remote_host>:<remote_port>", the local port being 0 (to
+// choose one) if not set
+func parsePortForward(spec string) (localPort int, remoteHost string, remotePort int, err error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+	if len(parts) != 3 || parts[1] == "" {
+		return 0, "", 0, fmt.Errorf("invalid forwarding '%s', expected '[<local_port>:]<remote_host>:<remote_port>'", spec)
+	}
+	localPort, err = strconv.Atoi(parts[0])
+	if err != nil || localPort < 0 || localPort > 65535 {
+		return 0, "", 0, fmt.Errorf("invalid local port '%s'", parts[0])
+	}
+	remotePort, err = strconv.Atoi(parts[2])
+	if err != nil || remotePort <= 0 || remotePort > 65535 {
+		return 0, "", 0, fmt.Errorf("invalid remote port '%s'", parts[2])
+	}
+	return localPort, parts[1], remotePort, nil
+}
+
 var hostList = cli.Command{
 	Name:    "list",
 	Aliases: []string{"ls"},
@@ -151,10 +533,44 @@ var hostList = cli.Command{
 			Name:  "all",
 			Usage: "List all hosts on tenant (not only those created by SafeScale)",
 		},
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "Lists at most this number of hosts; the marker to get the next ones is displayed",
+		},
+		cli.StringFlag{
+			Name:  "marker",
+			Usage: "Lists the hosts following the one with this ID, as displayed by a previous listing with --limit",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "Lists the hosts of this region of the tenant instead of the configured one",
+		},
+		cli.StringFlag{
+			Name:  "zone",
+			Usage: "Lists the hosts of this zone of the region (required with --region by some providers)",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
-		hosts, err := client.New().Host.List(c.Bool("all"), temporal.GetExecutionTimeout())
+		paged := c.Int("limit") > 0 || c.String("marker") != ""
+		var (
+			hosts *pb.HostList
+			err   error
+		)
+		inRegion := c.String("region") != "" || c.String("zone") != ""
+		if paged && inRegion {
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("--region and --zone cannot be used with --limit or --marker."))
+		}
+		switch {
+		case paged:
+			hosts, err = client.New().Host.ListPage(c.String("marker"), c.Int("limit"), temporal.GetExecutionTimeout())
+		case inRegion:
+			hosts, err = client.New().Host.ListInRegion(
+				c.Bool("all"), c.String("region"), c.String("zone"), temporal.GetExecutionTimeout(),
+			)
+		default:
+			hosts, err = client.New().Host.List(c.Bool("all"), temporal.GetExecutionTimeout())
+		}
 		if err != nil {
 			return clitools.FailureResponse(
 				clitools.ExitOnRPC(
@@ -181,15 +597,65 @@ var hostList = cli.Command{
 			delete(v, "state")
 			delete(v, "gateway_id")
 		}
+		if paged {
+			return clitools.SuccessResponse(
+				map[string]interface{}{
+					"hosts":       result,
+					"next_marker": hosts.GetNextMarker(),
+				},
+			)
+		}
 		return clitools.SuccessResponse(result)
 	},
 }
 
+var hostAudit = cli.Command{
+	Name:  "audit",
+	Usage: "Compare the hosts recorded by SafeScale with the hosts of the provider",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		report, err := client.New().Host.Audit(temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "audit of hosts", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(
+			map[string]interface{}{
+				"missing":   report.GetMissing(),
+				"unmanaged": report.GetUnmanaged(),
+			},
+		)
+	},
+}
+
 var hostInspect = cli.Command{
 	Name:      "inspect",
 	Aliases:   []string{"show"},
 	Usage:     "inspect Host",
 	ArgsUsage: "<Host_name|Host_ID>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "volumes",
+			Usage: "Also displays the volumes attached to the host, with their mount details",
+		},
+		cli.BoolFlag{
+			Name:  "disk",
+			Usage: "Also displays the space and inode usage of the filesystems mounted on the host",
+		},
+		cli.BoolFlag{
+			Name:  "nics",
+			Usage: "Also displays the network interfaces of the host, with their MAC and IP addresses",
+		},
+		cli.BoolFlag{
+			Name:  "sysctl",
+			Usage: "Also displays the kernel parameters applied on the host by SafeScale",
+		},
+		cli.BoolFlag{
+			Name:  "ports",
+			Usage: "Also displays the TCP and UDP ports on which the host is listening, with the listening processes",
+		},
+	},
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
 		if c.NArg() != 1 {
@@ -208,10 +674,156 @@ var hostInspect = cli.Command{
 				),
 			)
 		}
+		if !c.Bool("volumes") && !c.Bool("disk") && !c.Bool("nics") && !c.Bool("sysctl") && !c.Bool("ports") {
+			return clitools.SuccessResponse(resp)
+		}
+		result := map[string]interface{}{"host": resp}
+		if c.Bool("volumes") {
+			volumes, err := client.New().Host.ListVolumes(c.Args().First(), temporal.GetExecutionTimeout())
+			if err != nil {
+				return clitools.FailureResponse(
+					clitools.ExitOnRPC(
+						utils.Capitalize(
+							client.DecorateError(
+								err, "listing of host volumes", false,
+							).Error(),
+						),
+					),
+				)
+			}
+			result["volumes"] = volumes.GetVolumes()
+		}
+		if c.Bool("disk") {
+			usage, err := client.New().Host.DiskUsage(c.Args().First(), temporal.GetExecutionTimeout())
+			if err != nil {
+				return clitools.FailureResponse(
+					clitools.ExitOnRPC(
+						utils.Capitalize(
+							client.DecorateError(
+								err, "retrieval of host disk usage", false,
+							).Error(),
+						),
+					),
+				)
+			}
+			result["disk"] = usage.GetFilesystems()
+		}
+		if c.Bool("nics") {
+			nics, err := client.New().Host.ListNetworkInterfaces(c.Args().First(), temporal.GetExecutionTimeout())
+			if err != nil {
+				return clitools.FailureResponse(
+					clitools.ExitOnRPC(
+						utils.Capitalize(
+							client.DecorateError(
+								err, "listing of host network interfaces", false,
+							).Error(),
+						),
+					),
+				)
+			}
+			result["nics"] = nics.GetInterfaces()
+		}
+		if c.Bool("sysctl") {
+			params, err := client.New().Host.ListKernelParameters(c.Args().First(), temporal.GetExecutionTimeout())
+			if err != nil {
+				return clitools.FailureResponse(
+					clitools.ExitOnRPC(
+						utils.Capitalize(
+							client.DecorateError(
+								err, "listing of host kernel parameters", false,
+							).Error(),
+						),
+					),
+				)
+			}
+			result["sysctl"] = params.GetParameters()
+		}
+		if c.Bool("ports") {
+			ports, err := client.New().Host.ListListeningPorts(c.Args().First(), temporal.GetExecutionTimeout())
+			if err != nil {
+				return clitools.FailureResponse(
+					clitools.ExitOnRPC(
+						utils.Capitalize(
+							client.DecorateError(
+								err, "listing of host listening ports", false,
+							).Error(),
+						),
+					),
+				)
+			}
+			result["ports"] = ports.GetPorts()
+		}
+		return clitools.SuccessResponse(result)
+	},
+}
+
+var hostMetrics = cli.Command{
+	Name:      "metrics",
+	Usage:     "Shows uptime, load, memory and CPU count of Host",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+		resp, err := client.New().Host.GetMetrics(c.Args().First(), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(
+					utils.Capitalize(
+						client.DecorateError(
+							err, "metrics of host", false,
+						).Error(),
+					),
+				),
+			)
+		}
 		return clitools.SuccessResponse(resp)
 	},
 }
 
+var hostTop = cli.Command{
+	Name:      "top",
+	Usage:     "Shows the processes of Host using the most CPU or memory",
+	ArgsUsage: "<Host_name|Host_ID>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "sort",
+			Value: "cpu",
+			Usage: "Sorts the processes by 'cpu' or 'memory' usage",
+		},
+		cli.IntFlag{
+			Name:  "n, count",
+			Value: 10,
+			Usage: "Number of processes shown; 0 shows all of them",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+		if c.String("sort") != "cpu" && c.String("sort") != "memory" {
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("--sort must be 'cpu' or 'memory'."))
+		}
+		if c.Int("count") < 0 {
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("--count cannot be negative."))
+		}
+
+		resp, err := client.New().Host.ListProcesses(
+			c.Args().First(), c.String("sort"), c.Int("count"), temporal.GetExecutionTimeout(),
+		)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "listing of host processes", false).Error())),
+			)
+		}
+		return clitools.SuccessResponse(resp.GetProcesses())
+	},
+}
+
 var hostStatus = cli.Command{
 	Name:      "status",
 	Usage:     "status Host",
@@ -247,7 +859,11 @@ var hostCreate = cli.Command{
 		cli.StringFlag{
 			Name:  "net,network",
 			Value: "",
-			Usage: "network name or network id",
+			Usage: "network name or network id; a comma-separated list attaches the host to several networks, the first one holding the default route",
+		},
+		cli.BoolFlag{
+			Name:  "allow-cross-network",
+			Usage: "If set, the networks of --net may belong to different provider networks than the first one (default: not set)",
 		},
 		cli.StringFlag{
 			Name:  "domain",
@@ -270,6 +886,82 @@ var hostCreate = cli.Command{
 			Name:  "keep-on-failure, k",
 			Usage: "If set, the abstract are not deleted on failure (default: not set)",
 		},
+		cli.BoolFlag{
+			Name:  "skip-default-security-group",
+			Usage: "If set, no security group dedicated to the host is created; the host is only protected by the security group(s) of its network (default: not set)",
+		},
+		cli.BoolFlag{
+			Name:  "shielded-vm",
+			Usage: "If set, the host uses verified boot (secure boot, vTPM and integrity monitoring), for providers supporting it (default: not set)",
+		},
+		cli.BoolFlag{
+			Name:  "confidential-vm",
+			Usage: "If set, the memory of the host is encrypted by the CPU, for providers supporting it; the template and the image must support it (default: not set)",
+		},
+		cli.IntFlag{
+			Name:  "system-disk-size",
+			Usage: "Size of the system disk in GB, overriding the disk size of the sizing and of the template (default: sized by the provider)",
+		},
+		cli.StringFlag{
+			Name:  "disk-type",
+			Usage: "Type of the system disk, depending on the provider (pd-standard, pd-balanced or pd-ssd on GCP, SATA, SAS or SSD on huaweicloud) (default: chosen by the provider)",
+		},
+		cli.StringFlag{
+			Name:  "affinity-group",
+			Usage: "Placement group of the host, created at first use; the hosts of a group run on the same physical hosts, unless --anti-affinity is used",
+		},
+		cli.BoolFlag{
+			Name:  "anti-affinity",
+			Usage: "Run the hosts of the placement group given by --affinity-group on distinct physical hosts",
+		},
+		cli.StringSliceFlag{
+			Name:  "tag",
+			Usage: "Tag to set on the host and its volumes on provider side, as <name>=<value> (can be used several times); merged with the mandatory tags of the tenant",
+		},
+		cli.StringFlag{
+			Name:  "from-snapshot",
+			Usage: "ID of a provider snapshot of a host to restore instead of installing the OS; only credentials are set up on the restored host",
+		},
+		cli.BoolFlag{
+			Name:  "provision-from-scratch",
+			Usage: "If set with --from-snapshot, all the provisioning phases are run on the restored host (default: not set)",
+		},
+		cli.StringSliceFlag{
+			Name:  "security-group",
+			Usage: "Name of an existing security group to bind to the host; can be used several times (default: none)",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "Creates the host in this region of the tenant instead of the configured one; the networks must exist in this region",
+		},
+		cli.StringFlag{
+			Name:  "zone",
+			Usage: "Zone of the host in the region (required with --region by some providers)",
+		},
+		cli.StringSliceFlag{
+			Name:  "volume",
+			Usage: "Volume to create and mount on the host once ready, as '<size>:<mountpoint>[:<speed>[:<format>]]' (size in GB, speed HDD and format ext4 by default); can be used several times",
+		},
+		cli.StringSliceFlag{
+			Name:  "nic",
+			Usage: "Position and optional name of the network interface on a network of --net, as '<network>:<index>[:<name>]' (index 0 being the first interface); can be used several times (default: order of --net)",
+		},
+		cli.StringSliceFlag{
+			Name:  "fixed-ip",
+			Usage: "IP address to give to the host on a network of --net, as '<network>:<ip>', on providers supporting it; can be used once per network (default: chosen by the provider)",
+		},
+		cli.BoolFlag{
+			Name:  "spot",
+			Usage: "If set, creates a spot/preemptible host, cheaper but that the provider can reclaim at any time (default: not set)",
+		},
+		cli.Float64Flag{
+			Name:  "max-price",
+			Usage: "With --spot, maximum hourly price accepted for the host, on providers supporting it (default: market price)",
+		},
+		cli.BoolFlag{
+			Name:  "skip-reboots",
+			Usage: "If set, the host is not rebooted at the end of the provisioning; use only with images applying the configuration live (default: not set)",
+		},
 		cli.StringFlag{
 			Name: "S, sizing",
 			Usage: `Describe sizing of host in format "<component><operator><value>[,...]" where:
@@ -426,6 +1118,12 @@ var hostDelete = cli.Command{
 	Aliases:   []string{"rm", "remove"},
 	Usage:     "Delete host",
 	ArgsUsage: "<Host_name|Host_ID> [<Host_name|Host_ID>...]",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "detach-volumes",
+			Usage: "Detaches the volumes attached to the host instead of refusing to delete it",
+		},
+	},
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
 		if c.NArg() < 1 {
@@ -436,7 +1134,12 @@ var hostDelete = cli.Command{
 		hostList = append(hostList, c.Args().First())
 		hostList = append(hostList, c.Args().Tail()...)
 
-		err := client.New().Host.Delete(hostList, temporal.GetExecutionTimeout())
+		var err error
+		if c.Bool("detach-volumes") {
+			err = client.New().Host.DeleteDetachingVolumes(hostList, temporal.GetExecutionTimeout())
+		} else {
+			err = client.New().Host.Delete(hostList, temporal.GetExecutionTimeout())
+		}
 		if err != nil {
 			return clitools.FailureResponse(
 				clitools.ExitOnRPC(
@@ -494,66 +1197,112 @@ var hostAddFeatureCommand = cli.Command{
 			Name:  "skip-proxy",
 			Usage: "Disable reverse proxy rules",
 		},
+		cli.BoolFlag{
+			Name:  "wait-cloud-init",
+			Usage: "Wait cloud-init has finished on the host before installing, to not compete with it for the package manager locks",
+		},
+		cli.StringFlag{
+			Name:  "method",
+			Usage: "Force the installation method of the feature (apt, yum, dnf, bash), instead of the one preferred for the host",
+		},
 	},
 
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
-		err := extractHostArgument(c, 0)
+		req, err := hostFeatureRequestFromCLI(c)
 		if err != nil {
 			return clitools.FailureResponse(err)
 		}
 
-		err = extractFeatureArgument(c)
+		err = client.New().Host.AddFeature(req, temporal.GetLongOperationTimeout())
 		if err != nil {
-			return clitools.FailureResponse(err)
+			msg := fmt.Sprintf("error adding feature '%s' on host '%s': %s", featureName, hostName, err.Error())
+			return clitools.FailureResponse(clitools.ExitOnRPC(msg))
 		}
+		return clitools.SuccessResponse(nil)
+	},
+}
 
-		feature, err := install.NewFeature(concurrency.RootTask(), featureName)
-		if err != nil {
-			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.Run, err.Error()))
-		}
-		if feature == nil {
-			msg := fmt.Sprintf("failed to find a feature named '%s'.", featureName)
-			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.NotFound, msg))
-		}
-		values := install.Variables{}
-		params := c.StringSlice("param")
-		for _, k := range params {
-			res := strings.Split(k, "=")
-			if len(res[0]) > 0 {
-				values[res[0]] = strings.Join(res[1:], "=")
-			}
-		}
+// parseForcedInstallMethod returns the installation method requested with '--method', 0 if none has been requested
+func parseForcedInstallMethod(c *cli.Context) (method.Enum, error) {
+	value := c.String("method")
+	if value == "" {
+		return 0, nil
+	}
+	return method.Parse(value)
+}
 
-		settings := install.Settings{}
-		settings.SkipProxy = c.Bool("skip-proxy")
+// hostFeatureRequestFromCLI builds the request of the feature command from the arguments and the flags of the command
+func hostFeatureRequestFromCLI(c *cli.Context) (*pb.HostFeatureRequest, error) {
+	err := extractHostArgument(c, 0)
+	if err != nil {
+		return nil, err
+	}
+	err = extractFeatureArgument(c)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = parseForcedInstallMethod(c); err != nil {
+		return nil, clitools.ExitOnInvalidOption(err.Error())
+	}
 
-		// Wait for SSH service on remote host first
-		err = client.New().SSH.WaitReady(hostInstance.Id, temporal.GetConnectionTimeout())
-		if err != nil {
-			msg := fmt.Sprintf(
-				"failed to reach '%s': %s", hostName, client.DecorateError(err, "waiting ssh on host", false),
-			)
-			return clitools.FailureResponse(clitools.ExitOnRPC(msg))
+	req := &pb.HostFeatureRequest{
+		Host:       &pb.Reference{Id: hostInstance.Id},
+		Name:       featureName,
+		Parameters: map[string]string{},
+		Method:     c.String("method"),
+	}
+	for _, k := range c.StringSlice("param") {
+		res := strings.Split(k, "=")
+		if len(res[0]) > 0 {
+			req.Parameters[res[0]] = strings.Join(res[1:], "=")
 		}
+	}
+	if c.Command.HasName("add-feature") || c.Command.HasName("ensure-feature") {
+		req.SkipProxy = c.Bool("skip-proxy")
+		req.WaitCloudInit = c.Bool("wait-cloud-init")
+	}
+	return req, nil
+}
 
-		target, err := install.NewHostTarget(hostInstance)
+// hostEnsureFeatureCommand handles 'safescale host ensure-feature <host name> <feature name>'
+var hostEnsureFeatureCommand = cli.Command{
+	Name:      "ensure-feature",
+	Usage:     "Installs a feature on host only if it is not already installed, in the version of its specification",
+	ArgsUsage: "HOSTNAME FEATURENAME",
+
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "param, p",
+			Usage: "Allow to define content of feature parameters",
+		},
+		cli.BoolFlag{
+			Name:  "skip-proxy",
+			Usage: "Disable reverse proxy rules",
+		},
+		cli.BoolFlag{
+			Name:  "wait-cloud-init",
+			Usage: "Wait cloud-init has finished on the host before installing, to not compete with it for the package manager locks",
+		},
+		cli.StringFlag{
+			Name:  "method",
+			Usage: "Force the installation method of the feature (apt, yum, dnf, bash), instead of the one preferred for the host",
+		},
+	},
+
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
+		req, err := hostFeatureRequestFromCLI(c)
 		if err != nil {
 			return clitools.FailureResponse(err)
 		}
-		results, err := feature.Add(target, values, settings)
+
+		result, err := client.New().Host.EnsureFeature(req, temporal.GetLongOperationTimeout())
 		if err != nil {
-			msg := fmt.Sprintf("error adding feature '%s' on host '%s': %s", featureName, hostName, err.Error())
+			msg := fmt.Sprintf("error ensuring feature '%s' on host '%s': %s", featureName, hostName, err.Error())
 			return clitools.FailureResponse(clitools.ExitOnRPC(msg))
 		}
-		if !results.Successful() {
-			msg := fmt.Sprintf("failed to add feature '%s' on host '%s'", featureName, hostName)
-			if Debug || Verbose {
-				msg += fmt.Sprintf(":\n%s", results.AllErrorMessages())
-			}
-			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.Run, msg))
-		}
-		return clitools.SuccessResponse(nil)
+		return clitools.SuccessResponse(map[string]string{"result": result})
 	},
 }
 
@@ -593,6 +1342,10 @@ var hostCheckFeatureCommand = cli.Command{
 			Name:  "param, p",
 			Usage: "Allow to define content of feature parameters",
 		},
+		cli.StringFlag{
+			Name:  "method",
+			Usage: "Force the installation method of the feature (apt, yum, dnf, bash), instead of the one preferred for the host",
+		},
 	},
 
 	Action: func(c *cli.Context) error {
@@ -638,7 +1391,12 @@ var hostCheckFeatureCommand = cli.Command{
 		if err != nil {
 			return clitools.FailureResponse(err)
 		}
-		results, err := feature.Check(target, values, install.Settings{})
+		settings := install.Settings{}
+		settings.ForceMethod, err = parseForcedInstallMethod(c)
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnInvalidOption(err.Error()))
+		}
+		results, err := feature.Check(target, values, settings)
 		if err != nil {
 			msg := fmt.Sprintf(
 				"error checking if feature '%s' is installed on '%s': %s\n", featureName, hostName, err.Error(),
@@ -648,7 +1406,7 @@ var hostCheckFeatureCommand = cli.Command{
 		if !results.Successful() {
 			msg := fmt.Sprintf("Feature '%s' not found on host '%s'", featureName, hostName)
 			if Verbose || Debug {
-				msg += fmt.Sprintf(":\n%s", results.AllErrorMessages())
+				msg += fmt.Sprintf(":\n%s", results.Report())
 			}
 			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.NotFound, msg))
 		}
@@ -672,59 +1430,16 @@ var hostDeleteFeatureCommand = cli.Command{
 
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", hostCmdName, c.Command.Name, c.Args())
-		err := extractHostArgument(c, 0)
-		if err != nil {
-			return clitools.FailureResponse(err)
-		}
-
-		err = extractFeatureArgument(c)
+		req, err := hostFeatureRequestFromCLI(c)
 		if err != nil {
 			return clitools.FailureResponse(err)
 		}
 
-		feature, err := install.NewFeature(concurrency.RootTask(), featureName)
+		err = client.New().Host.RemoveFeature(req, temporal.GetLongOperationTimeout())
 		if err != nil {
-			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.Run, err.Error()))
-		}
-		if feature == nil {
-			msg := fmt.Sprintf("failed to find a feature named '%s'.", featureName)
-			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.NotFound, msg))
-		}
-
-		values := install.Variables{}
-		params := c.StringSlice("param")
-		for _, k := range params {
-			res := strings.Split(k, "=")
-			if len(res[0]) > 0 {
-				values[res[0]] = strings.Join(res[1:], "=")
-			}
-		}
-
-		// Wait for SSH service on remote host first
-		err = client.New().SSH.WaitReady(hostInstance.Id, temporal.GetConnectionTimeout())
-		if err != nil {
-			msg := fmt.Sprintf(
-				"failed to reach '%s': %s", hostName, client.DecorateError(err, "waiting ssh on host", false),
-			)
+			msg := fmt.Sprintf("error uninstalling feature '%s' on '%s': %s", featureName, hostName, err.Error())
 			return clitools.FailureResponse(clitools.ExitOnRPC(msg))
 		}
-
-		target, err := install.NewHostTarget(hostInstance)
-		if err != nil {
-			return clitools.FailureResponse(err)
-		}
-		results, err := feature.Remove(target, values, install.Settings{})
-		if err != nil {
-			msg := fmt.Sprintf("error uninstalling feature '%s' on '%s': %s\n", featureName, hostName, err.Error())
-			return clitools.FailureResponse(clitools.ExitOnRPC(msg))
-		}
-		if !results.Successful() {
-			msg := fmt.Sprintf("failed to delete feature '%s' from host '%s'", featureName, hostName)
-			if Verbose || Debug {
-				msg += fmt.Sprintf(":\n%s", results.AllErrorMessages())
-			}
-			return clitools.FailureResponse(clitools.ExitOnErrorWithMessage(exitcode.Run, msg))
-		}
 		return clitools.SuccessResponse(nil)
 	},
 }
@@ -759,6 +1474,17 @@ func constructPBHostDefinitionFromCLI(c *cli.Context, key string) (*pb.HostDefin
 		return nil, clitools.FailureResponse(clitools.ExitOnInvalidArgument(err.Error()))
 	}
 
+	tags := map[string]string{}
+	for _, v := range c.StringSlice("tag") {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, clitools.FailureResponse(
+				clitools.ExitOnInvalidArgument(fmt.Sprintf("Invalid tag '%s', expected '<name>=<value>'.", v)),
+			)
+		}
+		tags[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
 	def := pb.HostDefinition{
 		Name:          c.Args().First(),
 		ImageId:       c.String("os"),
@@ -768,6 +1494,27 @@ func constructPBHostDefinitionFromCLI(c *cli.Context, key string) (*pb.HostDefin
 		Domain:        c.String("domain"),
 		Sizing:        &pb.HostSizing{},
 		KeepOnFailure: c.Bool("keep-on-failure"),
+
+		SkipDefaultSecurityGroup: c.Bool("skip-default-security-group"),
+		SourceSnapshot:           c.String("from-snapshot"),
+		ProvisionFromScratch:     c.Bool("provision-from-scratch"),
+		SkipReboots:              c.Bool("skip-reboots"),
+		Spot:                     c.Bool("spot"),
+		MaxPrice:                 float32(c.Float64("max-price")),
+		SecurityGroups:           c.StringSlice("security-group"),
+		Nics:                     c.StringSlice("nic"),
+		FixedIps:                 c.StringSlice("fixed-ip"),
+		Volumes:                  c.StringSlice("volume"),
+		AllowCrossNetwork:        c.Bool("allow-cross-network"),
+		ShieldedVm:               c.Bool("shielded-vm"),
+		ConfidentialVm:           c.Bool("confidential-vm"),
+		Tags:                     tags,
+		SystemDiskSize:           int32(c.Int("system-disk-size")),
+		DiskType:                 c.String("disk-type"),
+		AffinityGroup:            c.String("affinity-group"),
+		AntiAffinity:             c.Bool("anti-affinity"),
+		Region:                   c.String("region"),
+		Zone:                     c.String("zone"),
 	}
 	if t, ok := tokens["cpu"]; ok {
 		min, max, err := t.Validate()
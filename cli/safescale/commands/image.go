@@ -20,6 +20,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
+	pb "github.com/CS-SI/SafeScale/lib"
 	"github.com/CS-SI/SafeScale/lib/client"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	clitools "github.com/CS-SI/SafeScale/lib/utils/cli"
@@ -46,10 +47,28 @@ var imageList = cli.Command{
 			Name:  "all",
 			Usage: "List all available images in tenant (without any filter)",
 		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "List the images of this region of the tenant instead of the configured one",
+		},
+		cli.StringFlag{
+			Name:  "zone",
+			Usage: "Zone of the region (required with --region by some providers)",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", imageCmdName, c.Command.Name, c.Args())
-		images, err := client.New().Image.List(c.Bool("all"), temporal.GetExecutionTimeout())
+		var (
+			images *pb.ImageList
+			err    error
+		)
+		if c.String("region") != "" || c.String("zone") != "" {
+			images, err = client.New().Image.ListInRegion(
+				c.Bool("all"), c.String("region"), c.String("zone"), temporal.GetExecutionTimeout(),
+			)
+		} else {
+			images, err = client.New().Image.List(c.Bool("all"), temporal.GetExecutionTimeout())
+		}
 		if err != nil {
 			return clitools.FailureResponse(
 				clitools.ExitOnRPC(
@@ -18,6 +18,9 @@ package commands
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -41,6 +44,8 @@ var NetworkCmd = cli.Command{
 		networkDelete,
 		networkInspect,
 		networkList,
+		networkDNS,
+		networkSubnet,
 	},
 }
 
@@ -82,6 +87,10 @@ var networkDelete = cli.Command{
 			Name:  "force, f",
 			Usage: "If used, deletes the network ignoring metadata discrepancies",
 		},
+		cli.UintFlag{
+			Name:  "wait-for-hosts",
+			Usage: "Waits up to this number of seconds for the deletion of the hosts still attached to the network",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
@@ -110,7 +119,10 @@ var networkDelete = cli.Command{
 				)
 			}
 		} else {
-			err := client.New().Network.Delete(networkList, temporal.GetExecutionTimeout())
+			waitForHosts := time.Duration(c.Uint("wait-for-hosts")) * time.Second
+			err := client.New().Network.DeleteWaitingForHosts(
+				networkList, waitForHosts, temporal.GetExecutionTimeout(),
+			)
 			if err != nil {
 				return clitools.FailureResponse(
 					clitools.ExitOnRPC(
@@ -132,6 +144,12 @@ var networkInspect = cli.Command{
 	Aliases:   []string{"show"},
 	Usage:     "inspect NETWORK",
 	ArgsUsage: "<network_name>",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "tree",
+			Usage: "Renders the topology of the network as a tree: gateways, hosts and subnets",
+		},
+	},
 	Action: func(c *cli.Context) error {
 		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
 		if c.NArg() != 1 {
@@ -139,6 +157,18 @@ var networkInspect = cli.Command{
 			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <network_name>."))
 		}
 
+		if c.Bool("tree") {
+			topology, err := client.New().Network.InspectTopology(c.Args().First(), temporal.GetExecutionTimeout())
+			if err != nil {
+				return clitools.FailureResponse(
+					clitools.ExitOnRPC(
+						utils.Capitalize(client.DecorateError(err, "inspection of network topology", false).Error()),
+					),
+				)
+			}
+			return clitools.SuccessResponse(renderNetworkTopology(topology))
+		}
+
 		network, err := client.New().Network.Inspect(c.Args().First(), temporal.GetExecutionTimeout())
 		if err != nil {
 			return clitools.FailureResponse(
@@ -190,6 +220,202 @@ var networkInspect = cli.Command{
 	},
 }
 
+// renderNetworkTopology renders the topology of a network as a tree
+func renderNetworkTopology(topology *pb.NetworkTopology) string {
+	var b strings.Builder
+	name := topology.GetName()
+	if name == "" {
+		name = topology.GetId()
+	}
+	b.WriteString(fmt.Sprintf("%s (%s)\n", name, topology.GetCidr()))
+	writeNetworkTopologyChildren(&b, topology, "")
+	return b.String()
+}
+
+func writeNetworkTopologyChildren(b *strings.Builder, topology *pb.NetworkTopology, indent string) {
+	var items []string
+	for _, gw := range topology.GetGateways() {
+		role := "secondary"
+		if gw.GetPrimary() {
+			role = "primary"
+		}
+		if gw.GetMissing() {
+			items = append(items, fmt.Sprintf("gateway %s (%s): metadata missing", gw.GetId(), role))
+			continue
+		}
+		item := fmt.Sprintf("gateway %s (%s) %s", gw.GetName(), role, gw.GetPrivateIp())
+		if gw.GetPublicIp() != "" {
+			item += " / " + gw.GetPublicIp()
+		}
+		items = append(items, item)
+	}
+	for _, h := range topology.GetHosts() {
+		items = append(items, "host "+h)
+	}
+	subnets := topology.GetSubnets()
+	for i, item := range items {
+		branch, last := "├── ", i == len(items)-1 && len(subnets) == 0
+		if last {
+			branch = "└── "
+		}
+		b.WriteString(indent + branch + item + "\n")
+	}
+	for i, subnet := range subnets {
+		branch, next := "├── ", indent+"│   "
+		if i == len(subnets)-1 {
+			branch, next = "└── ", indent+"    "
+		}
+		name := subnet.GetName()
+		if name == "" {
+			name = subnet.GetId()
+		}
+		b.WriteString(fmt.Sprintf("%ssubnet %s (%s, %d hosts)\n", indent+branch, name, subnet.GetCidr(), len(subnet.GetHosts())))
+		writeNetworkTopologyChildren(b, subnet, next)
+	}
+}
+
+var networkDNS = cli.Command{
+	Name:      "dns",
+	Usage:     "replace the DNS servers of a network and of its started hosts",
+	ArgsUsage: "<network_name>",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "dns",
+			Usage: "IP address of a DNS server; can be used several times",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "only list the hosts that would be updated",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <network_name>."))
+		}
+		if len(c.StringSlice("dns")) == 0 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory option --dns."))
+		}
+
+		hosts, err := client.New().Network.UpdateDNSServers(
+			c.Args().First(), c.StringSlice("dns"), c.Bool("dry-run"), temporal.GetExecutionTimeout(),
+		)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(
+					utils.Capitalize(
+						client.DecorateError(
+							err, "update of DNS servers of network", false,
+						).Error(),
+					),
+				),
+			)
+		}
+		return clitools.SuccessResponse(map[string]interface{}{"hosts": hosts, "dry_run": c.Bool("dry-run")})
+	},
+}
+
+// networkSubnet groups the commands acting on the subnet of a network
+var networkSubnet = cli.Command{
+	Name:  "subnet",
+	Usage: "subnet COMMAND",
+	Subcommands: []cli.Command{
+		networkSubnetAddRoute,
+		networkSubnetListRoutes,
+		networkSubnetRemoveRoute,
+		networkSubnetAllocateCIDR,
+	},
+}
+
+var networkSubnetAddRoute = cli.Command{
+	Name:      "add-route",
+	Usage:     "inject a static route in the subnet of a network",
+	ArgsUsage: "<network_name> <destination_cidr> <next_hop_ip>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 3 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <network_name>, <destination_cidr> or <next_hop_ip>."))
+		}
+
+		err := client.New().Network.AddRoute(c.Args().Get(0), c.Args().Get(1), c.Args().Get(2), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "add of route to network", false).Error())))
+		}
+		return clitools.SuccessResponse(nil)
+	},
+}
+
+var networkSubnetListRoutes = cli.Command{
+	Name:      "list-routes",
+	Usage:     "list the static routes of the subnet of a network",
+	ArgsUsage: "<network_name>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <network_name>."))
+		}
+
+		list, err := client.New().Network.ListRoutes(c.Args().First(), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "list of routes of network", false).Error())))
+		}
+		return clitools.SuccessResponse(list.GetRoutes())
+	},
+}
+
+var networkSubnetRemoveRoute = cli.Command{
+	Name:      "remove-route",
+	Usage:     "remove a static route from the subnet of a network",
+	ArgsUsage: "<network_name> <destination_cidr>",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <network_name> or <destination_cidr>."))
+		}
+
+		err := client.New().Network.RemoveRoute(c.Args().Get(0), c.Args().Get(1), temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "removal of route from network", false).Error())))
+		}
+		return clitools.SuccessResponse(nil)
+	},
+}
+
+var networkSubnetAllocateCIDR = cli.Command{
+	Name:      "allocate-cidr",
+	Usage:     "find the first free CIDR of the requested size in the address space of a network",
+	ArgsUsage: "<network_name>",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "prefix-len",
+			Value: 24,
+			Usage: "size of the CIDR, as the length of its prefix",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", networkCmdName, c.Command.Name, c.Args())
+		if c.NArg() != 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <network_name>."))
+		}
+		prefixLen := c.Int("prefix-len")
+		if prefixLen <= 0 || prefixLen > 32 {
+			return clitools.FailureResponse(clitools.ExitOnInvalidOption("--prefix-len must be between 1 and 32"))
+		}
+
+		cidr, err := client.New().Network.AllocateSubnetCIDR(c.Args().First(), prefixLen, temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "allocation of subnet CIDR", false).Error())))
+		}
+		return clitools.SuccessResponse(map[string]string{"cidr": cidr})
+	},
+}
+
 var networkCreate = cli.Command{
 	Name:      "create",
 	Aliases:   []string{"new"},
@@ -211,6 +437,10 @@ var networkCreate = cli.Command{
 			Value: "",
 			Usage: "Defines the domain used to define host FQDN (default: empty)",
 		},
+		cli.StringSliceFlag{
+			Name:  "dns",
+			Usage: "DNS server to use on hosts of the network (can be used several times; default: tenant or provider settings)",
+		},
 		cli.StringFlag{
 			Name:  "gwname",
 			Value: "",
@@ -282,6 +512,7 @@ var networkCreate = cli.Command{
 				Sizing:  def.Sizing,
 			},
 			KeepOnFailure: c.Bool("keep-on-failure"),
+			DnsServers:    c.StringSlice("dns"),
 		}
 		network, err := client.New().Network.Create(&netdef, temporal.GetExecutionTimeout())
 		if err != nil {
@@ -43,6 +43,8 @@ var SSHCmd = cli.Command{
 	Usage: "ssh COMMAND",
 	Subcommands: []cli.Command{
 		sshRun,
+		sshRunOnHosts,
+		sshRunScript,
 		sshCopy,
 		sshConnect,
 		sshTunnel,
@@ -100,6 +102,97 @@ var sshRun = cli.Command{
 	},
 }
 
+var sshRunOnHosts = cli.Command{
+	Name:      "run-many",
+	Usage:     "Run a command on several hosts and report the result of each one",
+	ArgsUsage: "<Host_name|Host_ID> [<Host_name|Host_ID>...]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "c",
+			Usage: "Command to execute",
+		},
+		cli.IntFlag{
+			Name:  "parallelism",
+			Value: 0,
+			Usage: "Number of hosts the command runs on at the same time (0: the maximum allowed)",
+		},
+		cli.StringFlag{
+			Name:  "timeout",
+			Value: "5",
+			Usage: "timeout in minutes",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", sshCmdName, c.Command.Name, c.Args())
+		if c.NArg() < 1 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name>."))
+		}
+		if c.String("c") == "" {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidOption("Missing mandatory option -c."))
+		}
+
+		var timeout time.Duration
+		if c.IsSet("timeout") {
+			timeout = time.Duration(c.Float64("timeout")) * time.Minute
+		} else {
+			timeout = temporal.GetHostTimeout()
+		}
+		resp, err := client.New().SSH.RunOnHosts(c.Args(), c.String("c"), c.Int("parallelism"), timeout)
+		if err != nil {
+			return clitools.FailureResponse(clitools.ExitOnRPC(utils.Capitalize(client.DecorateError(err, "ssh run-many", false).Error())))
+		}
+		return clitools.SuccessResponse(resp.GetResults())
+	},
+}
+
+var sshRunScript = cli.Command{
+	Name:      "run-script",
+	Usage:     "Upload a local script file on the host and run it",
+	ArgsUsage: "<Host_name|Host_ID> <script_file> [script_args...]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "timeout",
+			Value: "5",
+			Usage: "timeout in minutes",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", sshCmdName, c.Command.Name, c.Args())
+		if c.NArg() < 2 {
+			_ = cli.ShowSubcommandHelp(c)
+			return clitools.FailureResponse(clitools.ExitOnInvalidArgument("Missing mandatory argument <Host_name> and/or <script_file>."))
+		}
+
+		var timeout time.Duration
+		if c.IsSet("timeout") {
+			timeout = time.Duration(c.Float64("timeout")) * time.Minute
+		} else {
+			timeout = temporal.GetHostTimeout()
+		}
+		retcode, _, _, err := client.New().SSH.RunScript(
+			c.Args().Get(0), normalizeFileName(c.Args().Get(1)), c.Args().Tail()[1:], outputs.DISPLAY,
+			temporal.GetConnectionTimeout(), timeout,
+		)
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(
+					utils.Capitalize(
+						client.DecorateError(
+							err, "ssh run-script", false,
+						).Error(),
+					),
+				),
+			)
+		}
+		if retcode != 0 {
+			return cli.NewExitError("", retcode)
+		}
+		return nil
+	},
+}
+
 func normalizeFileName(fileName string) string {
 	absPath, _ := filepath.Abs(fileName)
 	if _, err := os.Stat(absPath); err != nil {
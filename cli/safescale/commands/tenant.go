@@ -36,6 +36,7 @@ var TenantCmd = cli.Command{
 		tenantList,
 		tenantGet,
 		tenantSet,
+		tenantQuotas,
 		// tenantStorageList,
 		// tenantStorageGet,
 		// tenantStorageSet,
@@ -111,6 +112,27 @@ var tenantSet = cli.Command{
 	},
 }
 
+var tenantQuotas = cli.Command{
+	Name:  "quotas",
+	Usage: "Display the limits and usages of the resources of the current tenant",
+	Action: func(c *cli.Context) error {
+		logrus.Tracef("SafeScale command: {%s}, {%s} with args {%s}", tenantCmdName, c.Command.Name, c.Args())
+		quotas, err := client.New().Tenant.Quotas(temporal.GetExecutionTimeout())
+		if err != nil {
+			return clitools.FailureResponse(
+				clitools.ExitOnRPC(
+					utils.Capitalize(
+						client.DecorateError(
+							err, "get tenant quotas", false,
+						).Error(),
+					),
+				),
+			)
+		}
+		return clitools.SuccessResponse(quotas)
+	},
+}
+
 // var tenantStorageList = cli.Command{
 // 	Name:    "storage-list",
 // 	Aliases: []string{"storage-ls"},
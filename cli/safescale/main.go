@@ -102,6 +102,10 @@ func main() {
 			Name:  "debug, d",
 			Usage: "Show debug information",
 		},
+		cli.BoolFlag{
+			Name:  "ignore-frozen",
+			Usage: "Allow to mutate frozen hosts (administrators only)",
+		},
 		cli.StringFlag{
 			Name:  "profile",
 			Usage: "Profiles binary; can contain 'cpu', 'ram', 'web' and a combination of them (ie 'cpu,ram')",
@@ -123,6 +127,8 @@ func main() {
 		// 	logrus.Errorf(err.Error())
 		// }
 
+		utils.SetIgnoreFrozen(c.Bool("ignore-frozen"))
+
 		// Sets profiling
 		if c.IsSet("profile") {
 			what := c.String("profile")
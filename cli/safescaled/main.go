@@ -37,15 +37,20 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/listeners"
 	"github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/debug"
+	"github.com/CS-SI/SafeScale/lib/utils/debug/tracing"
 
 	_ "github.com/CS-SI/SafeScale/lib/server"
 )
 
-var profileCloseFunc = func() {}
+var (
+	profileCloseFunc = func() {}
+	tracingCloseFunc = func() {}
+)
 
 func cleanup(onAbort bool) {
 	fmt.Println("cleanup")
 	profileCloseFunc()
+	tracingCloseFunc()
 	os.Exit(0)
 }
 
@@ -167,6 +172,13 @@ func main() {
 			profileCloseFunc = debug.Profile(what)
 		}
 
+		// Sets the export of the spans of the tracers, if SAFESCALE_TRACING_ENDPOINT is set
+		closeFunc, err := tracing.Configure("safescaled")
+		if err != nil {
+			return err
+		}
+		tracingCloseFunc = closeFunc
+
 		if strings.Contains(path.Base(os.Args[0]), "-cover") {
 			logrus.SetLevel(logrus.TraceLevel)
 			utils.Verbose = true
@@ -407,7 +407,13 @@ func analyzeTenant(group *sync.WaitGroup, theTenant string) (err error) {
 			hostHandler := handlers.NewHostHandler(serviceProvider)
 
 			host, err := hostHandler.Create(
-				context.Background(), hostName, network.Name, "Ubuntu 18.04", true, template.Name, false, "", false,
+				context.Background(), handlers.HostCreateRequest{
+					Name:     hostName,
+					Networks: network.Name,
+					OS:       "Ubuntu 18.04",
+					Public:   true,
+					Sizing:   template.Name,
+				},
 			)
 			if err != nil {
 				logrus.Warnf("template [%s] host '%s': error creation: %v\n", template.Name, hostName, err.Error())
@@ -416,7 +422,7 @@ func analyzeTenant(group *sync.WaitGroup, theTenant string) (err error) {
 
 			defer func() {
 				logrus.Infof("Trying to delete host '%s' with ID '%s'", hostName, host.ID)
-				delerr := hostHandler.Delete(context.Background(), host.ID)
+				delerr := hostHandler.Delete(context.Background(), host.ID, false)
 				if delerr != nil {
 					logrus.Warnf("Error deleting host '%s'", host.ID)
 				}
@@ -17,15 +17,22 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"time"
 
+	googleprotobuf "github.com/golang/protobuf/ptypes/empty"
+
 	pb "github.com/CS-SI/SafeScale/lib"
 	srvutils "github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/system"
 	clitools "github.com/CS-SI/SafeScale/lib/utils/cli"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
+	"github.com/CS-SI/SafeScale/lib/utils/retry"
+	"github.com/CS-SI/SafeScale/lib/utils/temporal"
 )
 
 // var sshCfgCache = cache.NewMapCache()
@@ -49,6 +56,33 @@ func (h *host) List(all bool, timeout time.Duration) (*pb.HostList, error) {
 	return service.List(ctx, &pb.HostListRequest{All: all})
 }
 
+// ListInRegion lists the hosts of the region 'region' (and of the zone 'zone' if set) of the tenant, instead of the
+// configured ones
+func (h *host) ListInRegion(all bool, region, zone string, timeout time.Duration) (*pb.HostList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.List(ctx, &pb.HostListRequest{All: all, Region: region, Zone: zone})
+}
+
+// ListPage returns at most 'limit' hosts created by SafeScale, starting after the host whose ID is 'marker'
+func (h *host) ListPage(marker string, limit int, timeout time.Duration) (*pb.HostList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.List(ctx, &pb.HostListRequest{Marker: marker, Limit: int32(limit)})
+}
+
 // Inspect ...
 func (h *host) Inspect(name string, timeout time.Duration) (*pb.Host, error) {
 	h.session.Connect()
@@ -63,6 +97,179 @@ func (h *host) Inspect(name string, timeout time.Duration) (*pb.Host, error) {
 
 }
 
+// GetGateways returns the primary and secondary gateways of the default network of the host
+// A gateway is its own primary gateway; secondary is nil if the network has only one gateway.
+func (h *host) GetGateways(name string, timeout time.Duration) (*pb.HostGateways, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.GetGateways(ctx, &pb.Reference{Name: name})
+}
+
+// ListVolumes returns the volumes attached to the host, with their mount details
+func (h *host) ListVolumes(name string, timeout time.Duration) (*pb.HostVolumeList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ListVolumes(ctx, &pb.Reference{Name: name})
+}
+
+// ListNetworkInterfaces returns the network interfaces of host, with their MAC and IP addresses
+func (h *host) ListNetworkInterfaces(name string, timeout time.Duration) (*pb.HostNetworkInterfaceList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ListNetworkInterfaces(ctx, &pb.Reference{Name: name})
+}
+
+// ListListeningPorts returns the TCP and UDP ports on which the host is listening, with the listening processes
+func (h *host) ListListeningPorts(name string, timeout time.Duration) (*pb.HostListeningPortList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ListListeningPorts(ctx, &pb.Reference{Name: name})
+}
+
+// ListProcesses returns the 'limit' processes of the host using the most CPU or memory, according to 'sortBy'
+// ("cpu" or "memory"); a limit of 0 returns all of them
+func (h *host) ListProcesses(name string, sortBy string, limit int, timeout time.Duration) (*pb.HostProcessList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ListProcesses(
+		ctx, &pb.HostProcessListRequest{Host: &pb.Reference{Name: name}, SortBy: sortBy, Limit: int32(limit)},
+	)
+}
+
+// DiskUsage returns the space and inode usage of the filesystems mounted on the host
+func (h *host) DiskUsage(name string, timeout time.Duration) (*pb.HostDiskUsage, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.DiskUsage(ctx, &pb.Reference{Name: name})
+}
+
+// GetMetrics returns the uptime, load, memory and CPU count of the host
+func (h *host) GetMetrics(name string, timeout time.Duration) (*pb.HostMetrics, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.GetMetrics(ctx, &pb.Reference{Name: name})
+}
+
+// Console returns the last 'lines' lines (all if 0) of the console output of host
+func (h *host) Console(name string, lines int, timeout time.Duration) (string, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := service.Console(ctx, &pb.HostConsoleRequest{Host: &pb.Reference{Name: name}, Lines: int32(lines)})
+	if err != nil {
+		return "", err
+	}
+	return out.GetOutput(), nil
+}
+
+// Snapshot creates the snapshot 'name' of the boot disk of host, quiescing its root filesystem if asked
+func (h *host) Snapshot(name string, snapshotName string, quiesce bool, timeout time.Duration) (*pb.HostSnapshot, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Snapshot(
+		ctx, &pb.HostSnapshotRequest{Host: &pb.Reference{Name: name}, Name: snapshotName, Quiesce: quiesce},
+	)
+}
+
+// Rename renames the host 'name' to 'newName'
+func (h *host) Rename(name string, newName string, timeout time.Duration) (*pb.Host, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Rename(ctx, &pb.HostRenameRequest{Host: &pb.Reference{Name: name}, NewName: newName})
+}
+
+// Clone creates the host 'newName' configured as the host 'name'; 'sizing' and 'network', if set, replace the ones of
+// the source host
+func (h *host) Clone(name string, newName string, sizing *pb.HostSizing, network string, withVolumes bool, timeout time.Duration) (*pb.Host, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Clone(ctx, &pb.HostCloneRequest{
+		Host:        &pb.Reference{Name: name},
+		NewName:     newName,
+		Sizing:      sizing,
+		Network:     network,
+		WithVolumes: withVolumes,
+	})
+}
+
+// CheckConnectivity checks from the host the reachability of targets, given as "host:port" or URL
+func (h *host) CheckConnectivity(name string, targets []string, timeout time.Duration) (*pb.HostConnectivityResultList, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.CheckConnectivity(ctx, &pb.HostConnectivityRequest{Host: &pb.Reference{Name: name}, Targets: targets})
+}
+
 // Get host status
 func (h *host) Status(name string, timeout time.Duration) (*pb.HostStatus, error) {
 	h.session.Connect()
@@ -90,6 +297,105 @@ func (h *host) Reboot(name string, timeout time.Duration) error {
 	return err
 }
 
+// Freeze protects host against deletion, resize, stop and reboot
+func (h *host) Freeze(name string, timeout time.Duration) error {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.Freeze(ctx, &pb.Reference{Name: name})
+	return err
+}
+
+// Thaw removes the protection of host set by Freeze
+func (h *host) Thaw(name string, timeout time.Duration) error {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.Thaw(ctx, &pb.Reference{Name: name})
+	return err
+}
+
+// SetIPForwarding allows or forbids host to forward traffic not addressed to it
+func (h *host) SetIPForwarding(name string, enabled bool, timeout time.Duration) error {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.SetIPForwarding(ctx, &pb.HostIPForwardingRequest{Host: &pb.Reference{Name: name}, Enabled: enabled})
+	return err
+}
+
+// ReplaceSecurityGroups makes the security groups bound on request to host exactly securityGroups
+func (h *host) ReplaceSecurityGroups(name string, securityGroups []string, timeout time.Duration) error {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.ReplaceSecurityGroups(
+		ctx, &pb.HostSecurityGroupsRequest{Host: &pb.Reference{Name: name}, SecurityGroups: securityGroups},
+	)
+	return err
+}
+
+// ApplyKernelParameters sets kernel parameters (sysctl) on host, returning their previous values
+func (h *host) ApplyKernelParameters(name string, params map[string]string, timeout time.Duration) (*pb.HostKernelParameters, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ApplyKernelParameters(
+		ctx, &pb.HostKernelParametersRequest{Host: &pb.Reference{Name: name}, Parameters: params},
+	)
+}
+
+// ListKernelParameters returns the kernel parameters applied on host by SafeScale
+func (h *host) ListKernelParameters(name string, timeout time.Duration) (*pb.HostKernelParameters, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ListKernelParameters(ctx, &pb.Reference{Name: name})
+}
+
+// Audit compares the hosts recorded in metadata with the hosts of the provider
+func (h *host) Audit(timeout time.Duration) (*pb.HostAuditReport, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Audit(ctx, &googleprotobuf.Empty{})
+}
+
 // Start host
 func (h *host) Start(name string, timeout time.Duration) error {
 	h.session.Connect()
@@ -136,6 +442,15 @@ func (h *host) Create(def *pb.HostDefinition, timeout time.Duration) (*pb.Host,
 
 // Delete deletes several hosts at the same time in goroutines
 func (h *host) Delete(names []string, timeout time.Duration) error {
+	return h.delete(names, false, timeout)
+}
+
+// DeleteDetachingVolumes deletes several hosts at the same time in goroutines, detaching first their volumes
+func (h *host) DeleteDetachingVolumes(names []string, timeout time.Duration) error {
+	return h.delete(names, true, timeout)
+}
+
+func (h *host) delete(names []string, detachVolumes bool, timeout time.Duration) error {
 	h.session.Connect()
 	defer h.session.Disconnect()
 	service := pb.NewHostServiceClient(h.session.connection)
@@ -152,7 +467,12 @@ func (h *host) Delete(names []string, timeout time.Duration) error {
 
 	hostDeleter := func(aname string) {
 		defer wg.Done()
-		_, err := service.Delete(ctx, &pb.Reference{Name: aname})
+		_, err := service.Delete(
+			ctx, &pb.HostDeleteRequest{
+				Host:          &pb.Reference{Name: aname},
+				DetachVolumes: detachVolumes,
+			},
+		)
 		if err != nil {
 			mutex.Lock()
 			errs = append(errs, err.Error())
@@ -174,6 +494,11 @@ func (h *host) Delete(names []string, timeout time.Duration) error {
 
 // SSHConfig ...
 func (h *host) SSHConfig(name string) (*system.SSHConfig, error) {
+	return h.sshConfig(name, 0)
+}
+
+// sshConfig returns the ssh configuration of the host, waiting at most timeout for the daemon (no limit if 0)
+func (h *host) sshConfig(name string, timeout time.Duration) (*system.SSHConfig, error) {
 	// if anon, ok := sshCfgCache.Get(name); ok {
 	// 	return anon.(*system.SSHConfig), nil
 	// }
@@ -185,6 +510,11 @@ func (h *host) SSHConfig(name string) (*system.SSHConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
 	pbSSHCfg, err := service.SSH(ctx, &pb.Reference{Name: name})
 	if err != nil {
@@ -198,6 +528,56 @@ func (h *host) SSHConfig(name string) (*system.SSHConfig, error) {
 	return sshCfg, err
 }
 
+// PortForward forwards the local port 'localPort' (chosen if 0) to 'remoteHost':'remotePort' as seen from host;
+// returns the local port used and the tunnels to close with system.CloseTunnels
+// Fails if the forwarding is not listening locally after timeout.
+func (h *host) PortForward(name string, localPort int, remoteHost string, remotePort int, timeout time.Duration) (int, []*system.SSHTunnel, error) {
+	start := time.Now()
+	sshCfg, err := h.sshConfig(name, timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+	port, tunnels, err := sshCfg.PortForward(localPort, remoteHost, remotePort)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	remaining := timeout - time.Since(start)
+	if timeout <= 0 {
+		remaining = temporal.GetConnectionTimeout()
+	} else if remaining < time.Second {
+		remaining = time.Second
+	}
+	err = waitLocalPort(port, remaining)
+	if err != nil {
+		if cerr := system.CloseTunnels(tunnels); cerr != nil {
+			err = fail.AddConsequence(err, cerr)
+		}
+		return 0, nil, err
+	}
+	return port, tunnels, nil
+}
+
+// waitLocalPort waits until something listens on the local port, at most timeout
+func waitLocalPort(port int, timeout time.Duration) error {
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+	err := retry.WhileUnsuccessful(
+		func() error {
+			conn, err := net.DialTimeout("tcp", address, time.Second)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+		100*time.Millisecond,
+		timeout,
+	)
+	if err != nil {
+		return fail.TimeoutError(fmt.Sprintf("port forwarding not listening on %s after %s", address, timeout), timeout, err)
+	}
+	return nil
+}
+
 func (h *host) Resize(def *pb.HostDefinition, duration time.Duration) (*pb.Host, error) {
 	if def == nil {
 		return nil, fail.InvalidParameterError("def", "cannot be nil")
@@ -213,3 +593,64 @@ func (h *host) Resize(def *pb.HostDefinition, duration time.Duration) (*pb.Host,
 
 	return service.Resize(ctx, def)
 }
+
+// AddFeature installs on the host the feature described by req, and records it in the metadata of the host
+func (h *host) AddFeature(req *pb.HostFeatureRequest, timeout time.Duration) error {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	_, err = service.AddFeature(ctx, req)
+	return err
+}
+
+// EnsureFeature installs on the host the feature described by req only if it is not already installed in the version
+// of its specification; returns "already-present", "installed" or "upgraded"
+func (h *host) EnsureFeature(req *pb.HostFeatureRequest, timeout time.Duration) (string, error) {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return "", err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := service.EnsureFeature(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.GetResult(), nil
+}
+
+// RemoveFeature uninstalls from the host the feature described by req, and forgets it in the metadata of the host
+func (h *host) RemoveFeature(req *pb.HostFeatureRequest, timeout time.Duration) error {
+	h.session.Connect()
+	defer h.session.Disconnect()
+	service := pb.NewHostServiceClient(h.session.connection)
+	ctx, err := srvutils.GetContext(true)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	_, err = service.RemoveFeature(ctx, req)
+	return err
+}
@@ -41,3 +41,17 @@ func (img *image) List(all bool, timeout time.Duration) (*pb.ImageList, error) {
 
 	return service.List(ctx, &pb.ImageListRequest{All: all})
 }
+
+// ListInRegion returns the list of available images in the region 'region' (and the zone 'zone' if set) of the
+// current tenant, instead of the configured ones
+func (img *image) ListInRegion(all bool, region, zone string, timeout time.Duration) (*pb.ImageList, error) {
+	img.session.Connect()
+	defer img.session.Disconnect()
+	service := pb.NewImageServiceClient(img.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.List(ctx, &pb.ImageListRequest{All: all, Region: region, Zone: zone})
+}
@@ -52,6 +52,16 @@ func (n *network) List(all bool, timeout time.Duration) (*pb.NetworkList, error)
 
 // Delete deletes several networks at the same time in goroutines
 func (n *network) Delete(names []string, timeout time.Duration) error {
+	return n.delete(names, 0, timeout)
+}
+
+// DeleteWaitingForHosts deletes several networks at the same time in goroutines, waiting up to waitForHosts for the
+// deletion of the hosts still attached to them
+func (n *network) DeleteWaitingForHosts(names []string, waitForHosts time.Duration, timeout time.Duration) error {
+	return n.delete(names, waitForHosts, timeout)
+}
+
+func (n *network) delete(names []string, waitForHosts time.Duration, timeout time.Duration) error {
 	n.session.Connect()
 	defer n.session.Disconnect()
 	service := pb.NewNetworkServiceClient(n.session.connection)
@@ -68,8 +78,12 @@ func (n *network) Delete(names []string, timeout time.Duration) error {
 
 	networkDeleter := func(aname string) {
 		defer wg.Done()
-		_, err := service.Delete(ctx, &pb.Reference{Name: aname})
-
+		_, err := service.Delete(
+			ctx, &pb.NetworkDeleteRequest{
+				Network:      &pb.Reference{Name: aname},
+				WaitForHosts: int32(waitForHosts.Seconds()),
+			},
+		)
 		if err != nil {
 			mutex.Lock()
 			defer mutex.Unlock()
@@ -142,6 +156,113 @@ func (n *network) Inspect(name string, timeout time.Duration) (*pb.Network, erro
 
 }
 
+// UpdateDNSServers replaces the DNS servers of a network and of its started hosts; returns the names of the hosts
+// updated (or that would be updated if dryRun is true)
+func (n *network) UpdateDNSServers(name string, dnsServers []string, dryRun bool, timeout time.Duration) ([]string, error) {
+	n.session.Connect()
+	defer n.session.Disconnect()
+	service := pb.NewNetworkServiceClient(n.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := service.UpdateDNSServers(
+		ctx, &pb.NetworkDNSServersRequest{
+			Network:    &pb.Reference{Name: name},
+			DnsServers: dnsServers,
+			DryRun:     dryRun,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetHosts(), nil
+}
+
+// AddRoute injects in a network a static route to destination through nextHop
+func (n *network) AddRoute(name string, destination string, nextHop string, timeout time.Duration) error {
+	n.session.Connect()
+	defer n.session.Disconnect()
+	service := pb.NewNetworkServiceClient(n.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.AddRoute(
+		ctx, &pb.NetworkRouteRequest{
+			Network: &pb.Reference{Name: name},
+			Route:   &pb.NetworkRoute{Destination: destination, NextHop: nextHop},
+		},
+	)
+	return err
+}
+
+// RemoveRoute removes from a network the static route to destination
+func (n *network) RemoveRoute(name string, destination string, timeout time.Duration) error {
+	n.session.Connect()
+	defer n.session.Disconnect()
+	service := pb.NewNetworkServiceClient(n.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.RemoveRoute(
+		ctx, &pb.NetworkRouteRequest{
+			Network: &pb.Reference{Name: name},
+			Route:   &pb.NetworkRoute{Destination: destination},
+		},
+	)
+	return err
+}
+
+// ListRoutes lists the static routes of a network
+func (n *network) ListRoutes(name string, timeout time.Duration) (*pb.NetworkRouteList, error) {
+	n.session.Connect()
+	defer n.session.Disconnect()
+	service := pb.NewNetworkServiceClient(n.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.ListRoutes(ctx, &pb.Reference{Name: name})
+}
+
+// AllocateSubnetCIDR returns the first free CIDR of size prefixLen in the address space of the network
+func (n *network) AllocateSubnetCIDR(name string, prefixLen int, timeout time.Duration) (string, error) {
+	n.session.Connect()
+	defer n.session.Disconnect()
+	service := pb.NewNetworkServiceClient(n.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := service.AllocateSubnetCIDR(
+		ctx, &pb.NetworkSubnetCIDRRequest{Network: &pb.Reference{Name: name}, PrefixLen: int32(prefixLen)},
+	)
+	if err != nil {
+		return "", err
+	}
+	return resp.GetCidr(), nil
+}
+
+// InspectTopology returns the topology of a network: its gateways, its hosts and its subnets
+func (n *network) InspectTopology(name string, timeout time.Duration) (*pb.NetworkTopology, error) {
+	n.session.Connect()
+	defer n.session.Disconnect()
+	service := pb.NewNetworkServiceClient(n.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.InspectTopology(ctx, &pb.Reference{Name: name})
+}
+
 // Create ...
 func (n *network) Create(def *pb.NetworkDefinition, timeout time.Duration) (*pb.Network, error) {
 	if def == nil {
@@ -17,8 +17,11 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -29,6 +32,7 @@ import (
 	pb "github.com/CS-SI/SafeScale/lib"
 	"github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/system"
+	commonutils "github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 	"github.com/CS-SI/SafeScale/lib/utils/retry"
@@ -82,10 +86,12 @@ func (s *ssh) Run(hostName, command string, outs outputs.Enum, connectionTimeout
 
 			retcode, stdout, stderr, breakErr = sshCmd.RunWithTimeout(nil, outs, executionTimeout)
 
-			// If an error occurred and is not a timeout one, stop the loop and propagates this error
+			// If an error occurred, stop the loop and propagates this error
 			if breakErr != nil {
-				if _, ok := breakErr.(*fail.ErrTimeout); ok {
-					return breakErr
+				if _, ok := breakErr.(fail.ErrTimeout); ok {
+					// the command ran out of time and has been killed, running it again will not help
+					retcode = system.RetCodeTimeout
+					return nil
 				}
 				retcode = -1
 				return nil
@@ -107,11 +113,100 @@ func (s *ssh) Run(hostName, command string, outs outputs.Enum, connectionTimeout
 		return -1, "", "", retryErr
 	}
 	if breakErr != nil {
-		return -1, "", "", breakErr
+		return retcode, "", "", breakErr
 	}
 	return retcode, stdout, stderr, nil
 }
 
+// RunScript uploads the local script file 'localPath' to the remote temporary folder of the host, executes it with bash
+// using 'args' as arguments, then removes the uploaded file
+func (s *ssh) RunScript(hostName, localPath string, args []string, outs outputs.Enum, connectionTimeout, executionTimeout time.Duration) (retcode int, stdout string, stderr string, err error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return -1, "", "", fmt.Errorf("failed to read script file '%s': %s", localPath, err.Error())
+	}
+	if !info.Mode().IsRegular() {
+		return -1, "", "", fmt.Errorf("script file '%s' is not a regular file", localPath)
+	}
+
+	remotePath := fmt.Sprintf("%s/script_%d_%s", commonutils.TempFolder, time.Now().UnixNano(), filepath.Base(localPath))
+	// The script removes itself when it runs; on failure to upload or to run it, it has to be removed here
+	defer func() {
+		if err != nil {
+			_, _, _, derr := s.Run(hostName, "sudo rm -f "+system.ShellQuote(remotePath), outputs.COLLECT, connectionTimeout, executionTimeout)
+			if derr != nil {
+				log.Warnf("failed to remove script '%s' from host '%s': %v", remotePath, hostName, derr)
+			}
+		}
+	}()
+
+	retcode, _, stderr, err = s.Copy(localPath, hostName+protocolSeparator+remotePath, connectionTimeout, executionTimeout)
+	if err != nil {
+		return -1, "", "", err
+	}
+	if retcode != 0 {
+		err = fmt.Errorf("failed to upload script file '%s': %s", localPath, system.SSHErrorString(retcode))
+		return retcode, "", stderr, err
+	}
+
+	return s.Run(hostName, runScriptCommand(remotePath, args), outs, connectionTimeout, executionTimeout)
+}
+
+// runScriptCommand returns the command executing the remote script 'remotePath' with 'args' as arguments, then
+// removing it while keeping its exit code
+func runScriptCommand(remotePath string, args []string) string {
+	quotedPath := system.ShellQuote(remotePath)
+	cmd := "sudo bash " + quotedPath
+	for _, v := range args {
+		cmd += " " + system.ShellQuote(v)
+	}
+	return cmd + "; rc=$?; sudo rm -f " + quotedPath + "; exit $rc"
+}
+
+// transfer asks the daemon to copy a file from a host to another host
+func (s *ssh) transfer(from, to string) (int, string, string, error) {
+	s.session.Connect()
+	defer s.session.Disconnect()
+	service := pb.NewSshServiceClient(s.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return -1, "", "", err
+	}
+
+	resp, err := service.Copy(ctx, &pb.SshCopyCommand{Source: from, Destination: to})
+	if err != nil {
+		return -1, "", "", DecorateError(err, "copy between hosts", true)
+	}
+	return int(resp.GetStatus()), resp.GetOutputStd(), resp.GetOutputErr(), nil
+}
+
+// RunOnHosts asks the daemon to run the command on the hosts, on at most 'parallelism' hosts at the same time (0 for
+// the maximum allowed); the failure on a host is reported in its result and does not stop the run on the others
+func (s *ssh) RunOnHosts(hostNames []string, command string, parallelism int, timeout time.Duration) (*pb.SshRunOnHostsResponse, error) {
+	s.session.Connect()
+	defer s.session.Disconnect()
+	service := pb.NewSshServiceClient(s.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req := &pb.SshRunOnHostsRequest{Command: command, Parallelism: int32(parallelism)}
+	for _, name := range hostNames {
+		req.Hosts = append(req.Hosts, &pb.Reference{Name: name})
+	}
+	resp, err := service.RunOnHosts(ctx, req)
+	if err != nil {
+		return nil, DecorateError(err, "run on hosts", true)
+	}
+	return resp, nil
+}
+
 func (s *ssh) getHostSSHConfig(hostname string) (*system.SSHConfig, error) {
 	host := &host{session: s.session}
 	cfg, err := host.SSHConfig(hostname)
@@ -174,7 +269,8 @@ func (s *ssh) Copy(from, to string, connectionTimeout, executionTimeout time.Dur
 
 	// Host checks
 	if hostFrom != "" && hostTo != "" {
-		return -1, "", "", fmt.Errorf("copy between 2 hosts is not supported yet")
+		// the daemon streams the file directly from one host to the other
+		return s.transfer(from, to)
 	}
 	if hostFrom == "" && hostTo == "" {
 		return -1, "", "", fmt.Errorf("no host name specified neither in from nor to")
@@ -300,11 +396,15 @@ func (s *ssh) CreateTunnel(name string, localPort int, remotePort int, timeout t
 
 	if sshCfg.GatewayConfig == nil {
 		sshCfg.GatewayConfig = &system.SSHConfig{
-			User:          sshCfg.User,
-			Host:          sshCfg.Host,
-			PrivateKey:    sshCfg.PrivateKey,
-			Port:          sshCfg.Port,
-			GatewayConfig: nil,
+			User:                 sshCfg.User,
+			Host:                 sshCfg.Host,
+			PrivateKey:           sshCfg.PrivateKey,
+			Port:                 sshCfg.Port,
+			GatewayConfig:        nil,
+			HostKey:              sshCfg.HostKey,
+			AgentSocket:          sshCfg.AgentSocket,
+			KeyPath:              sshCfg.KeyPath,
+			SSHConnectionOptions: sshCfg.SSHConnectionOptions,
 		}
 		sshCfg.Host = "127.0.0.1"
 	}
@@ -345,11 +445,15 @@ func (s *ssh) CloseTunnels(name string, localPort string, remotePort string, tim
 
 	if sshCfg.GatewayConfig == nil {
 		sshCfg.GatewayConfig = &system.SSHConfig{
-			User:          sshCfg.User,
-			Host:          sshCfg.Host,
-			PrivateKey:    sshCfg.PrivateKey,
-			Port:          sshCfg.Port,
-			GatewayConfig: nil,
+			User:                 sshCfg.User,
+			Host:                 sshCfg.Host,
+			PrivateKey:           sshCfg.PrivateKey,
+			Port:                 sshCfg.Port,
+			GatewayConfig:        nil,
+			HostKey:              sshCfg.HostKey,
+			AgentSocket:          sshCfg.AgentSocket,
+			KeyPath:              sshCfg.KeyPath,
+			SSHConnectionOptions: sshCfg.SSHConnectionOptions,
 		}
 		sshCfg.Host = "127.0.0.1"
 	}
@@ -392,3 +496,12 @@ func (s *ssh) WaitReady(hostName string, timeout time.Duration) error {
 	_, err = sshCfg.WaitServerReady("ready", timeout)
 	return err
 }
+
+// WaitCloudInitDone waits cloud-init has finished on remote host, for 'timeout' duration
+func (s *ssh) WaitCloudInitDone(hostName string, timeout time.Duration) error {
+	sshCfg, err := s.getHostSSHConfig(hostName)
+	if err != nil {
+		return err
+	}
+	return sshCfg.WaitCloudInitDone(timeout)
+}
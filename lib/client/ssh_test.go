@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScriptCommandQuoting(t *testing.T) {
+	cmd := runScriptCommand("/tmp/script_1_my script.sh", []string{"a b", "it's", "$HOME"})
+	assert.Equal(
+		t,
+		`sudo bash '/tmp/script_1_my script.sh' 'a b' 'it'"'"'s' '$HOME'; rc=$?; sudo rm -f '/tmp/script_1_my script.sh'; exit $rc`,
+		cmd,
+	)
+	assert.Equal(t, "sudo bash '/tmp/s.sh'; rc=$?; sudo rm -f '/tmp/s.sh'; exit $rc", runScriptCommand("/tmp/s.sh", nil))
+}
+
+func TestRunScriptCommandPassesArguments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runscript")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "my script.sh")
+	require.Nil(t, ioutil.WriteFile(path, []byte("for a in \"$@\"; do echo \"[$a]\"; done\nexit 3\n"), 0600))
+
+	// runs the command locally, without sudo
+	cmd := strings.Replace(runScriptCommand(path, []string{"a b", "it's", "$HOME", "`id`", ""}), "sudo ", "", -1)
+	out, err := exec.Command("bash", "-c", cmd).Output()
+	require.NotNil(t, err)
+	exitErr, ok := err.(*exec.ExitError)
+	require.True(t, ok)
+	assert.Equal(t, 3, exitErr.Sys().(syscall.WaitStatus).ExitStatus())
+	assert.Equal(t, "[a b]\n[it's]\n[$HOME]\n[`id`]\n[]\n", string(out))
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -70,3 +70,16 @@ func (t *tenant) Set(name string, timeout time.Duration) error {
 	_, err = service.Set(ctx, &pb.TenantName{Name: name})
 	return err
 }
+
+// Quotas ...
+func (t *tenant) Quotas(timeout time.Duration) (*pb.TenantQuotas, error) {
+	t.session.Connect()
+	defer t.session.Disconnect()
+	service := pb.NewTenantServiceClient(t.session.connection)
+	ctx, err := utils.GetContext(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Quotas(ctx, &googleprotobuf.Empty{})
+}
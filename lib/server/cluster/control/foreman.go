@@ -50,6 +50,7 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/providers"
 	"github.com/CS-SI/SafeScale/lib/server/install"
 	providermetadata "github.com/CS-SI/SafeScale/lib/server/metadata"
 	srvutils "github.com/CS-SI/SafeScale/lib/server/utils"
@@ -332,8 +333,7 @@ func (b *foreman) construct(task concurrency.Task, req Request) (err error) {
 	}
 
 	// Determine if Gateway Failover must be set
-	caps := svc.GetCapabilities()
-	gwFailoverDisabled := req.Complexity == complexity.Small || !caps.PrivateVirtualIP
+	gwFailoverDisabled := req.Complexity == complexity.Small || !svc.SupportsFeature(providers.VIP)
 	for k := range req.DisabledDefaultFeatures {
 		if k == "gateway-failover" {
 			gwFailoverDisabled = true
@@ -429,7 +429,9 @@ func (b *foreman) construct(task concurrency.Task, req Request) (err error) {
 		if err != nil && !req.KeepOnFailure {
 			derr := svc.DeleteKeyPair(kpName)
 			if derr != nil {
-				err = fail.AddConsequence(err, derr)
+				if _, ok := derr.(fail.ErrNotFound); !ok {
+					err = fail.AddConsequence(err, derr)
+				}
 			}
 		}
 	}()
@@ -33,6 +33,7 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/cluster/enums/complexity"
 	"github.com/CS-SI/SafeScale/lib/server/cluster/enums/nodetype"
 	"github.com/CS-SI/SafeScale/lib/server/cluster/enums/property"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/providers"
 	"github.com/CS-SI/SafeScale/lib/server/install"
 	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
 	"github.com/CS-SI/SafeScale/lib/utils/concurrency"
@@ -143,7 +144,7 @@ func configureCluster(task concurrency.Task, foreman control.Foreman, req contro
 	// If cluster complexity is not small or cloud provider provides support for VIP, creates such a VIP if not already done
 	var controlPlaneV1 *clusterpropsv1.ControlPlane
 	svc := cluster.GetService(task)
-	if identity.Complexity != complexity.Small && cluster.GetService(task).GetCapabilities().PrivateVirtualIP {
+	if identity.Complexity != complexity.Small && cluster.GetService(task).SupportsFeature(providers.VIP) {
 		err = cluster.GetProperties(task).LockForWrite(property.ControlPlaneV1).ThenUse(
 			func(clonable data.Clonable) error {
 				controlPlaneV1 = clonable.(*clusterpropsv1.ControlPlane)
@@ -19,11 +19,15 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"os"
 	"os/user"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,14 +42,17 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/networkproperty"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/volumespeed"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/providers"
 	"github.com/CS-SI/SafeScale/lib/server/install"
 	"github.com/CS-SI/SafeScale/lib/server/metadata"
 	srvutils "github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/system"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
+	"github.com/CS-SI/SafeScale/lib/utils/concurrency"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 	"github.com/CS-SI/SafeScale/lib/utils/retry"
@@ -59,16 +66,50 @@ import (
 
 // HostAPI defines API to manipulate hosts
 type HostAPI interface {
-	Create(ctx context.Context, name string, net string, os string, public bool, sizingParam interface{}, force bool, domain string, keeponfailure bool) (*abstract.Host, error)
+	Create(ctx context.Context, req HostCreateRequest) (*abstract.Host, error)
 	List(ctx context.Context, all bool) ([]*abstract.Host, error)
+	ListPage(ctx context.Context, marker string, limit int) ([]*abstract.Host, string, error)
+	ListFiltered(ctx context.Context, filter HostFilter) ([]*abstract.Host, int, error)
+	Audit(ctx context.Context) (*HostAudit, error)
 	ForceInspect(ctx context.Context, ref string) (*abstract.Host, error)
 	Inspect(ctx context.Context, ref string) (*abstract.Host, error)
-	Delete(ctx context.Context, ref string) error
+	InspectFull(ctx context.Context, ref string) (*abstract.HostDetails, error)
+	GetAvailabilityZone(ctx context.Context, ref string) (string, error)
+	Delete(ctx context.Context, ref string, detachVolumes bool) error
 	SSH(ctx context.Context, ref string) (*system.SSHConfig, error)
 	Reboot(ctx context.Context, ref string) error
 	Resize(ctx context.Context, name string, cpu int, ram float32, disk int, gpuNumber int, freq float32) (*abstract.Host, error)
 	Start(ctx context.Context, ref string) error
 	Stop(ctx context.Context, ref string) error
+	WaitState(ctx context.Context, ref string, target hoststate.Enum, timeout time.Duration, notify func(current hoststate.Enum)) error
+	GetAttachedVolume(ctx context.Context, ref string, volumeRef string) (*abstract.AttachedVolume, error)
+	ListAttachedVolumes(ctx context.Context, ref string) ([]*abstract.AttachedVolume, error)
+	DetachAllVolumes(ctx context.Context, ref string) ([]string, error)
+	ChangePassword(ctx context.Context, ref string, newPassword string) error
+	SetHostname(ctx context.Context, ref string, hostname string) error
+	AddFeature(ctx context.Context, ref string, featureName string, vars install.Variables, settings install.Settings) error
+	EnsureFeature(ctx context.Context, ref string, featureName string, vars install.Variables, settings install.Settings) (FeatureEnsureResult, error)
+	RemoveFeature(ctx context.Context, ref string, featureName string, vars install.Variables, settings install.Settings) error
+	GetGateways(ctx context.Context, ref string) (*abstract.Host, *abstract.Host, error)
+	Adopt(ctx context.Context, providerRef string, networkRef string, privateKey string) (*abstract.Host, error)
+	StreamProvisioningLogs(ctx context.Context, ref string, w io.Writer) error
+	DiskUsage(ctx context.Context, ref string) ([]*abstract.FilesystemUsage, error)
+	GetMetrics(ctx context.Context, ref string) (*abstract.HostMetrics, error)
+	Freeze(ctx context.Context, ref string) error
+	Console(ctx context.Context, ref string, lines int) (string, error)
+	Snapshot(ctx context.Context, ref string, name string, quiesce bool) (string, error)
+	Rename(ctx context.Context, ref string, newName string) (*abstract.Host, error)
+	Clone(ctx context.Context, sourceRef string, newName string, overrides HostCloneOverrides) (*abstract.Host, error)
+	GetNetworkInterfaces(ctx context.Context, ref string) ([]abstract.HostNetworkInterface, error)
+	GetListeningPorts(ctx context.Context, ref string) ([]abstract.ListeningPort, error)
+	GetProcessList(ctx context.Context, ref string, opts ProcessListOptions) ([]abstract.ProcessInfo, error)
+	CheckConnectivity(ctx context.Context, ref string, targets []abstract.ConnTarget) ([]abstract.ConnResult, error)
+	Thaw(ctx context.Context, ref string) error
+	SetIPForwarding(ctx context.Context, ref string, enabled bool) error
+	ReplaceSecurityGroups(ctx context.Context, ref string, securityGroups []string) error
+	ApplyKernelParameters(ctx context.Context, ref string, params map[string]string) (map[string]string, error)
+	ListKernelParameters(ctx context.Context, ref string) (map[string]string, error)
+	WaitForCloudInitDone(ctx context.Context, ref string, timeout time.Duration) error
 }
 
 // HostHandler host service
@@ -113,12 +154,9 @@ func (handler *HostHandler) Start(ctx context.Context, ref string) (err error) {
 		return retryErr
 	}
 
-	return retryOnCommunicationFailure(
-		func() error {
-			return handler.service.WaitHostState(id, hoststate.STARTED, temporal.GetHostTimeout())
-		},
-		0,
-	)
+	err = handler.WaitState(ctx, id, hoststate.STARTED, 0, logWaitedHostState(id))
+	handler.notifyWaitedHostState(mhm, hoststate.STARTED, err)
+	return err
 }
 
 // Stop stops a host
@@ -139,30 +177,37 @@ func (handler *HostHandler) Stop(ctx context.Context, ref string) (err error) {
 	if err != nil {
 		return err
 	}
+	if err = checkNotFrozen(ctx, mhm); err != nil {
+		return err
+	}
 
 	id := mhm.ID
-	err = handler.service.StopHost(id)
+	err = retryOnCommunicationFailure(
+		func() error {
+			return handler.service.StopHost(id)
+		},
+		0,
+	)
 	if err != nil {
-		switch err.(type) {
-		case fail.ErrNotFound, fail.ErrTimeout:
-			return err
-		default:
-			return err
-		}
+		return err
 	}
 
-	err = handler.service.WaitHostState(id, hoststate.STOPPED, temporal.GetHostTimeout())
-	if err != nil {
-		switch err.(type) {
-		case fail.ErrNotFound, fail.ErrTimeout:
-			return err
-		default:
-			return err
-		}
-	}
+	err = handler.WaitState(ctx, id, hoststate.STOPPED, 0, logWaitedHostState(id))
+	handler.notifyWaitedHostState(mhm, hoststate.STOPPED, err)
 	return err
 }
 
+// notifyWaitedHostState publishes the state reached by the host after WaitState returned 'err': 'target' on success,
+// ERROR if the host fell in error
+func (handler *HostHandler) notifyWaitedHostState(host *abstract.Host, target hoststate.Enum, err error) {
+	switch err.(type) {
+	case nil:
+		handler.notifyHostState(host, host.LastState, target)
+	case fail.ErrNotAvailable:
+		handler.notifyHostState(host, host.LastState, hoststate.ERROR)
+	}
+}
+
 // Reboot reboots a host
 func (handler *HostHandler) Reboot(ctx context.Context, ref string) (err error) {
 	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
@@ -182,35 +227,110 @@ func (handler *HostHandler) Reboot(ctx context.Context, ref string) (err error)
 		logrus.Warnf("Problem loading metadata itself")
 		return err
 	}
+	if err = checkNotFrozen(ctx, mhm); err != nil {
+		return err
+	}
 
 	id := mhm.ID
-	err = handler.service.RebootHost(id)
+	err = retryOnCommunicationFailure(
+		func() error {
+			return handler.service.RebootHost(id)
+		},
+		0,
+	)
 	if err != nil {
-		switch err.(type) {
-		case fail.ErrNotFound, fail.ErrTimeout:
+		return err
+	}
+	return handler.WaitState(ctx, id, hoststate.STARTED, 0, logWaitedHostState(id))
+}
+
+// WaitState waits until the host reaches the state 'target'
+// If timeout is 0, temporal.GetHostTimeout() is used; the timeout is capped by the deadline of ctx, if any. 'notify',
+// if not nil, is called each time a new state of the host is observed.
+// Returns fail.ErrNotAvailable if the host falls in ERROR state, fail.ErrTimeout if timeout is reached.
+func (handler *HostHandler) WaitState(
+	ctx context.Context, ref string, target hoststate.Enum, timeout time.Duration, notify func(current hoststate.Enum),
+) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %s, %v)", ref, target.String(), timeout), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	if timeout <= 0 {
+		timeout = temporal.GetHostTimeout()
+	}
+	timeout = temporal.EffectiveTimeout(ctx, timeout)
+
+	id := ref
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); !ok {
 			return err
-		default:
+		}
+	} else {
+		mhm, err := mh.Get()
+		if err != nil {
 			return err
 		}
+		id = mhm.ID
 	}
-	retryErr := retry.WhileUnsuccessfulDelay5Seconds(
-		func() error {
-			return handler.service.WaitHostState(id, hoststate.STARTED, temporal.GetHostTimeout())
-		},
-		temporal.GetHostTimeout(),
-	)
-	if retryErr != nil {
-		switch retryErr.(type) {
+
+	// svc.WaitHostState is called for short periods, to notify the state observed in between
+	deadline := time.Now().Add(timeout)
+	previous := hoststate.UNKNOWN
+	observed := false
+	observe := func(current hoststate.Enum) {
+		if notify != nil && (!observed || current != previous) {
+			notify(current)
+		}
+		observed = true
+		previous = current
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return fail.AbortedError("wait of host state cancelled", ctx.Err())
+		default:
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fail.TimeoutError(
+				fmt.Sprintf("timeout waiting host '%s' to reach state %s (current: %s)", ref, target.String(), previous.String()),
+				timeout, nil,
+			)
+		}
+		step := temporal.GetDefaultDelay()
+		if step > remaining {
+			step = remaining
+		}
+
+		err = handler.service.WaitHostState(id, target, step)
+		switch err.(type) {
+		case nil:
+			observe(target)
+			return nil
 		case fail.ErrTimeout:
-			return retryErr
-		case fail.ErrNotFound:
-			return retryErr
+			if current, stateErr := handler.service.GetHostState(id); stateErr == nil {
+				observe(current)
+			}
 		default:
-			return retryErr
+			return err
 		}
 	}
+}
 
-	return nil
+// logWaitedHostState returns a notify function for WaitState logging the state transitions of the host
+func logWaitedHostState(ref string) func(hoststate.Enum) {
+	return func(current hoststate.Enum) {
+		logrus.Infof("Host '%s': waiting (current: %s)", ref, current.String())
+	}
 }
 
 // Resize ...
@@ -233,6 +353,9 @@ func (handler *HostHandler) Resize(ctx context.Context, ref string, cpu int, ram
 	if err != nil {
 		return nil, err
 	}
+	if err = checkNotFrozen(ctx, mhm); err != nil {
+		return nil, err
+	}
 
 	id := mhm.ID
 	hostSizeRequest := abstract.SizingRequirements{
@@ -295,14 +418,68 @@ func (handler *HostHandler) Resize(ctx context.Context, ref string, cpu int, ram
 	return newHost, err
 }
 
+// HostCreateRequest contains the parameters of a host creation
+type HostCreateRequest struct {
+	Name string
+	// Networks may contain a comma-separated list of networks to attach the host to; the first one is the network
+	// holding the default route
+	Networks string
+	OS       string
+	Public   bool
+	// Sizing is either a *abstract.SizingRequirements or the name of a template
+	Sizing        interface{}
+	Force         bool
+	Domain        string
+	KeepOnFailure bool
+	// SkipDefaultSecurityGroup prevents the creation of the security group dedicated to the host (on stacks creating
+	// one); the host is then only protected by the security group(s) of its network, and its rules cannot be tuned
+	// per host
+	SkipDefaultSecurityGroup bool
+	// SourceSnapshot restores the host from this provider snapshot instead of installing it from OS, and only the
+	// credentials are set up, unless ProvisionFromScratch is set
+	SourceSnapshot       string
+	ProvisionFromScratch bool
+	// SkipReboots prevents the reboot of the host at the end of the provisioning
+	SkipReboots    bool
+	Spot           bool
+	MaxPrice       float64
+	SecurityGroups []string
+	// NICs may set the position and the name of the network interfaces, as "<network>:<index>[:<name>]" items;
+	// networks without item take the remaining positions in the order of Networks
+	NICs []string
+	// FixedIPs lists the IP addresses to give to the host instead of letting the provider choose them, as
+	// "<network>:<ip>" items, one per network at most
+	FixedIPs []string
+	// Volumes lists the volumes to create, format and mount once the host is ready, as
+	// "<size>:<mountpoint>[:<speed>[:<format>]]" items (HDD and ext4 by default); a failure deletes them with the host
+	Volumes []string
+	// AllowCrossNetwork allows Networks to belong to other provider networks than the default one
+	AllowCrossNetwork bool
+	// ShieldedVM makes the host use verified boot; ConfidentialVM makes the CPU encrypt its memory (the provider may
+	// then restrict the templates and the images usable)
+	ShieldedVM     bool
+	ConfidentialVM bool
+	// Tags are set on the host and its volumes on provider side, merged with the mandatory tags of the tenant; the
+	// creation fails if a mandatory tag has no value
+	Tags map[string]string
+	// SystemDiskSize (in GB) forces the size of the system disk, whatever the sizing and the template; 0 lets the
+	// provider size it
+	SystemDiskSize int
+	// DiskType is the type of the system disk, whose meaning depends on the provider ('pd-ssd' on GCP, 'SATA' on
+	// huaweicloud, ...); empty lets the provider choose it
+	DiskType string
+	// AffinityGroup places the host in the named placement group, created at first use: with AntiAffinity, the hosts
+	// of the group run on distinct physical hosts (to not lose them all at once), otherwise on the same ones
+	AffinityGroup string
+	AntiAffinity  bool
+}
+
 // Create creates a host
 // func (handler *HostHandler) Create(
 // 	ctx context.Context,
 // 	name string, net string, cpu int, ram float32, disk int, los string, public bool, gpuNumber int, freq float32,
 // 	force bool,
-func (handler *HostHandler) Create(
-	ctx context.Context,
-	name string, net string, los string, public bool, sizingParam interface{}, force bool, domain string, keeponfailure bool) (newHost *abstract.Host, err error) {
+func (handler *HostHandler) Create(ctx context.Context, req HostCreateRequest) (newHost *abstract.Host, err error) {
 
 	if handler == nil {
 		return nil, fail.InvalidInstanceError()
@@ -310,27 +487,38 @@ func (handler *HostHandler) Create(
 	if ctx == nil {
 		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
 	}
-	if name == "" {
+	if req.Name == "" {
 		return nil, fail.InvalidParameterError("name", "cannot be empty string")
 	}
+	if req.SystemDiskSize < 0 {
+		return nil, fail.InvalidParameterError("systemDiskSize", "cannot be negative")
+	}
 
 	defer func() {
 		if newHost == nil && err == nil {
 			logrus.Debugf("host is nil, should not without an error")
 		}
+		if err == nil {
+			handler.notifyHostState(newHost, hoststate.UNKNOWN, hoststate.STARTED)
+		}
 	}()
 
 	tracer := debug.NewTracer(
-		nil, fmt.Sprintf("('%s', '%s', '%s', %v, <sizingParam>, %v)", name, net, los, public, force), true,
-	).WithStopwatch().GoingIn()
+		nil,
+		fmt.Sprintf("('%s', '%s', '%s', %v, <sizingParam>, %v)", req.Name, req.Networks, req.OS, req.Public, req.Force),
+		true,
+	).WithStopwatch().WithContext(ctx).WithSpanAttribute("provider", handler.service.GetName()).
+		WithSpanAttribute("host.name", req.Name).WithSpanAttribute("phase", "init").GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+	// the spans of the callees are children of the span of the creation
+	ctx = tracer.Context()
 
 	var (
 		sizing       *abstract.SizingRequirements
 		templateName string
 	)
-	switch sizingParam := sizingParam.(type) {
+	switch sizingParam := req.Sizing.(type) {
 	case *abstract.SizingRequirements:
 		sizing = sizingParam
 	case string:
@@ -340,7 +528,7 @@ func (handler *HostHandler) Create(
 	}
 
 	// Check if host already exist in SafeScale scope
-	_, err = metadata.LoadHost(handler.service, name)
+	_, err = metadata.LoadHost(handler.service, req.Name)
 	if err != nil {
 		switch err.(type) {
 		case fail.ErrNotFound:
@@ -349,11 +537,11 @@ func (handler *HostHandler) Create(
 			return nil, err
 		}
 	} else {
-		return nil, fail.DuplicateError(fmt.Sprintf("host '%s' already exists", name))
+		return nil, fail.DuplicateError(fmt.Sprintf("host '%s' already exists", req.Name))
 	}
 
 	// Check if host exist outside SafeScale scope
-	host, err := handler.service.GetHostByName(name)
+	host, err := handler.service.GetHostByName(req.Name)
 	if err != nil {
 		switch err.(type) {
 		case fail.ErrNotFound:
@@ -364,14 +552,14 @@ func (handler *HostHandler) Create(
 			return nil, err
 		}
 	} else {
-		hostThere, hsErr := handler.service.GetHostState(name)
+		hostThere, hsErr := handler.service.GetHostState(req.Name)
 		if hsErr == nil {
-			logrus.Warnf("we have a host %s with status: %s", name, hostThere.String())
+			logrus.Warnf("we have a host %s with status: %s", req.Name, hostThere.String())
 			if hostThere != hoststate.TERMINATED {
-				return nil, abstract.ResourceDuplicateError("host", name)
+				return nil, abstract.ResourceDuplicateError("host", req.Name)
 			}
 		}
-		return nil, abstract.ResourceDuplicateError("host", name)
+		return nil, abstract.ResourceDuplicateError("host", req.Name)
 	}
 
 	var (
@@ -381,9 +569,15 @@ func (handler *HostHandler) Create(
 		// secondaryGateway *abstract.Host
 		defaultRouteIP string
 	)
-	if net != "" && net != "net-safescale" {
+	// 'net' may contain a comma-separated list of networks; the first one holds the default route of the host
+	netNames := splitNetworkList(req.Networks)
+	defaultNet := ""
+	if len(netNames) > 0 {
+		defaultNet = netNames[0]
+	}
+	if defaultNet != "" && defaultNet != "net-safescale" {
 		networkHandler := NewNetworkHandler(handler.service)
-		defaultNetwork, err = networkHandler.Inspect(ctx, net)
+		defaultNetwork, err = networkHandler.Inspect(ctx, defaultNet)
 		if err != nil {
 			if _, ok := err.(fail.ErrNotFound); ok {
 				return nil, err
@@ -391,16 +585,43 @@ func (handler *HostHandler) Create(
 			return nil, err
 		}
 		if defaultNetwork == nil {
-			return nil, fail.Errorf(fmt.Sprintf("failed to find network '%s'", net), nil)
+			return nil, fail.Errorf(fmt.Sprintf("failed to find network '%s'", defaultNet), nil)
 		}
 		networks = append(networks, defaultNetwork)
 
+		for _, v := range netNames[1:] {
+			otherNetwork, err := networkHandler.Inspect(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+			if otherNetwork == nil {
+				return nil, fail.Errorf(fmt.Sprintf("failed to find network '%s'", v), nil)
+			}
+			for _, n := range networks {
+				if n.ID == otherNetwork.ID {
+					return nil, fail.InvalidRequestError(fmt.Sprintf("network '%s' is requested more than once", v))
+				}
+			}
+			if len(otherNetwork.Subnetworks) > 1 {
+				return nil, fail.InvalidRequestError(
+					fmt.Sprintf(
+						"network '%s' contains %d subnetworks, cannot decide which one to attach host to", v,
+						len(otherNetwork.Subnetworks),
+					),
+				)
+			}
+			networks = append(networks, otherNetwork)
+		}
+		if err = checkNetworksCompatibility(networks, req.AllowCrossNetwork); err != nil {
+			return nil, err
+		}
+
 		mgw, err := metadata.LoadHost(handler.service, defaultNetwork.GatewayID)
 		if err != nil {
 			return nil, err
 		}
 		if mgw == nil {
-			return nil, fail.Errorf(fmt.Sprintf("failed to find gateway of network '%s'", net), nil)
+			return nil, fail.Errorf(fmt.Sprintf("failed to find gateway of network '%s'", defaultNet), nil)
 		}
 		primaryGateway, err = mgw.Get()
 		if err != nil {
@@ -412,6 +633,9 @@ func (handler *HostHandler) Create(
 			defaultRouteIP = primaryGateway.GetPrivateIP()
 		}
 	} else {
+		if len(netNames) > 1 {
+			return nil, fail.InvalidRequestError("cannot attach host to additional networks without an explicit default network")
+		}
 		net, err := handler.getOrCreateDefaultNetwork()
 		if err != nil {
 			return nil, err
@@ -419,9 +643,105 @@ func (handler *HostHandler) Create(
 		networks = append(networks, net)
 	}
 
+	if len(req.SecurityGroups) > 0 && !handler.service.SupportsFeature(providers.SecurityGroups) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support security groups", req.Name),
+		)
+	}
+
+	if req.MaxPrice > 0 && !req.Spot {
+		return nil, fail.InvalidParameterError("maxPrice", "cannot be set without spot")
+	}
+	if req.Spot && !handler.service.SupportsFeature(providers.SpotInstances) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support spot instances", req.Name),
+		)
+	}
+	if req.MaxPrice > 0 && !handler.service.SupportsFeature(providers.SpotMaxPrice) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support a maximum price for spot instances", req.Name),
+		)
+	}
+	if req.ShieldedVM && !handler.service.SupportsFeature(providers.ShieldedVM) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support shielded VMs", req.Name),
+		)
+	}
+	if req.ConfidentialVM && !handler.service.SupportsFeature(providers.ConfidentialVM) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support confidential VMs", req.Name),
+		)
+	}
+	if req.AntiAffinity && req.AffinityGroup == "" {
+		return nil, fail.InvalidParameterError("antiAffinity", "cannot be set without affinityGroup")
+	}
+	if req.AffinityGroup != "" && !handler.service.SupportsFeature(providers.AffinityGroups) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support affinity groups", req.Name),
+		)
+	}
+
+	hostNICs, err := parseNICs(req.NICs, networks)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.FixedIPs) > 0 && !handler.service.SupportsFeature(providers.FixedIPs) {
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot create host '%s': provider doesn't support fixed IP addresses", req.Name),
+		)
+	}
+	hostFixedIPs, err := parseFixedIPs(req.FixedIPs, networks)
+	if err != nil {
+		return nil, err
+	}
+	hostVolumes, err := parseVolumes(req.Volumes)
+	if err != nil {
+		return nil, err
+	}
+	hostTags, err := mergeTags(handler.service.GetMandatoryTags(), req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// A host created from a snapshot restores the OS of the snapshot, no image is needed
+	var img *abstract.Image
+	if req.SourceSnapshot != "" {
+		if !handler.service.SupportsFeature(providers.HostFromSnapshot) {
+			return nil, fail.NotAvailableError(
+				fmt.Sprintf("cannot create host '%s': provider doesn't support host creation from snapshot", req.Name),
+			)
+		}
+	} else {
+		retryErr := retryOnCommunicationFailure(
+			func() error {
+				var innerErr error
+				img, innerErr = handler.service.SearchImage(req.OS)
+				return innerErr
+			},
+			2*temporal.GetDefaultDelay(),
+		)
+		if retryErr != nil {
+			switch retryErr.(type) {
+			case fail.ErrNotFound, fail.ErrTimeout:
+				return nil, retryErr
+			default:
+				return nil, retryErr
+			}
+		}
+	}
+
 	var template *abstract.HostTemplate
 	if sizing != nil {
-		templates, err := handler.service.SelectTemplatesBySize(*sizing, force)
+		if sizing.MinGPU > 0 && !handler.service.SupportsFeature(providers.GPU) {
+			return nil, fail.InvalidRequestError(fmt.Sprintf("cannot create host '%s': provider doesn't support GPU", req.Name))
+		}
+		// The RAM required by the image cannot be circumvented, contrary to the disk size (the system disk is
+		// enlarged by the stacks when needed)
+		imageSizing := *sizing
+		if img != nil && img.MinRAMGB > imageSizing.MinRAMSize {
+			imageSizing.MinRAMSize = img.MinRAMGB
+		}
+		templates, err := handler.service.SelectTemplatesBySize(imageSizing, req.Force)
 		if err != nil {
 			switch err.(type) {
 			case fail.ErrNotFound, fail.ErrTimeout:
@@ -431,7 +751,7 @@ func (handler *HostHandler) Create(
 			}
 		}
 		if len(templates) > 0 {
-			template = templates[0]
+			template = preferTemplatesFittingImage(templates, img)[0]
 			msg := fmt.Sprintf(
 				"Selected host template: '%s' (%d core%s", template.Name, template.Cores, utils.Plural(template.Cores),
 			)
@@ -448,7 +768,7 @@ func (handler *HostHandler) Create(
 			msg += ")"
 			logrus.Infof(msg)
 		} else {
-			return nil, fail.Errorf(fmt.Sprintf("failed to find template corresponding to requested abstract"), nil)
+			return nil, fail.NotFoundError("failed to find a template matching requested sizing")
 		}
 	} else {
 		template, err = handler.service.SelectTemplateByName(templateName)
@@ -460,26 +780,17 @@ func (handler *HostHandler) Create(
 				return nil, err
 			}
 		}
-	}
-
-	var img *abstract.Image
-	retryErr := retryOnCommunicationFailure(
-		func() error {
-			var innerErr error
-			img, innerErr = handler.service.SearchImage(los)
-			return innerErr
-		},
-		2*temporal.GetDefaultDelay(),
-	)
-	if retryErr != nil {
-		switch retryErr.(type) {
-		case fail.ErrNotFound, fail.ErrTimeout:
-			return nil, retryErr
-		default:
-			return nil, retryErr
+		if img != nil && img.MinRAMGB > 0 && template.RAMSize < img.MinRAMGB {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf(
+					"cannot create host '%s': template '%s' has %.01f GB of RAM, image '%s' requires at least %.01f GB",
+					req.Name, template.Name, template.RAMSize, img.Name, img.MinRAMGB,
+				),
+			)
 		}
 	}
 
+	domain := req.Domain
 	if domain == "" {
 		domain = defaultNetwork.Domain
 	}
@@ -488,25 +799,56 @@ func (handler *HostHandler) Create(
 		domain = "." + domain
 	}
 
-	keypair, err := abstract.NewKeyPair(name)
+	keypair, err := abstract.NewKeyPair(req.Name)
 	if err != nil {
 		return nil, err
 	}
+	imageID := ""
+	if img != nil {
+		imageID = img.ID
+	}
 	hostRequest := abstract.HostRequest{
-		ImageID:        img.ID,
-		ResourceName:   name,
-		HostName:       name + domain,
+		ImageID:        imageID,
+		ResourceName:   req.Name,
+		HostName:       req.Name + domain,
 		TemplateID:     template.ID,
-		PublicIP:       public,
+		PublicIP:       req.Public,
 		Networks:       networks,
 		DefaultRouteIP: defaultRouteIP,
 		DefaultGateway: primaryGateway,
 		KeyPair:        keypair,
+
+		SkipDefaultSecurityGroup: req.SkipDefaultSecurityGroup,
+		SourceSnapshotID:         req.SourceSnapshot,
+		ProvisionFromScratch:     req.ProvisionFromScratch,
+		SkipReboots:              req.SkipReboots,
+		Spot:                     req.Spot,
+		MaxPrice:                 req.MaxPrice,
+		ShieldedVM:               req.ShieldedVM,
+		ConfidentialVM:           req.ConfidentialVM,
+		SecurityGroups:           req.SecurityGroups,
+		NICs:                     hostNICs,
+		FixedIPs:                 hostFixedIPs,
+		Volumes:                  hostVolumes,
+		Tags:                     hostTags,
+		SystemDiskSize:           req.SystemDiskSize,
+		DiskType:                 req.DiskType,
+		AffinityGroup:            req.AffinityGroup,
+		AntiAffinity:             req.AntiAffinity,
+	}
+	orderedNetworks, err := hostRequest.OrderedNetworks()
+	if err != nil {
+		return nil, err
+	}
+	err = hostRequest.CheckVolumes()
+	if err != nil {
+		return nil, err
 	}
 
 	host = nil
 	var userData *userdata.Content
-	retryErr = retryOnCommunicationFailure(
+	tracer.WithSpanAttribute("phase", "creation")
+	retryErr := retryOnCommunicationFailure(
 		func() error {
 			var innerErr error
 			host, userData, innerErr = handler.service.CreateHost(hostRequest)
@@ -528,7 +870,7 @@ func (handler *HostHandler) Create(
 
 	defer func() {
 		if err != nil {
-			if keeponfailure {
+			if req.KeepOnFailure {
 				if forensics := os.Getenv("SAFESCALE_FORENSICS"); forensics != "" {
 					return
 				}
@@ -562,6 +904,9 @@ func (handler *HostHandler) Create(
 	if host.Properties == nil {
 		return nil, fail.Errorf(fmt.Sprintf("error populating host properties: host.Properties is nil"), nil)
 	}
+	if img != nil {
+		host.NativeUser = img.LoginUser()
+	}
 
 	// Updates host metadata
 	mh, err := metadata.NewHost(handler.service)
@@ -583,7 +928,7 @@ func (handler *HostHandler) Create(
 	// Starting from here, remove metadata if exiting with error
 	defer func() {
 		if err != nil {
-			if keeponfailure {
+			if req.KeepOnFailure {
 				if forensics := os.Getenv("SAFESCALE_FORENSICS"); forensics != "" {
 					return
 				}
@@ -633,25 +978,35 @@ func (handler *HostHandler) Create(
 	}
 
 	// Sets host extension DescriptionV1
-	creator := ""
-	hostname, _ := os.Hostname()
-	if curUser, err := user.Current(); err == nil {
-		creator = curUser.Username
-		if hostname != "" {
-			creator += "@" + hostname
-		}
-		if curUser.Name != "" {
-			creator += " (" + curUser.Name + ")"
-		}
-	} else {
-		creator = "unknown@" + hostname
-	}
 	err = host.Properties.LockForWrite(hostproperty.DescriptionV1).ThenUse(
 		func(clonable data.Clonable) error {
 			hostDescriptionV1 := clonable.(*propsv1.HostDescription)
 			hostDescriptionV1.Created = time.Now()
-			hostDescriptionV1.Creator = creator
+			hostDescriptionV1.Creator = currentCreator()
 			hostDescriptionV1.Domain = domain
+			hostDescriptionV1.SourceSnapshotID = req.SourceSnapshot
+			hostDescriptionV1.ProvisioningSkipped = !hostRequest.RunsProvisioningPhases()
+			hostDescriptionV1.Spot = req.Spot
+			hostDescriptionV1.ShieldedVM = req.ShieldedVM
+			hostDescriptionV1.ConfidentialVM = req.ConfidentialVM
+			hostDescriptionV1.Tags = hostTags
+			hostDescriptionV1.AffinityGroup = req.AffinityGroup
+			hostDescriptionV1.AntiAffinity = req.AntiAffinity
+			hostDescriptionV1.Region, _ = handler.service.GetRegion()
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sets host extension SecurityGroupsV1
+	err = host.Properties.LockForWrite(hostproperty.SecurityGroupsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostSecurityGroupsV1 := clonable.(*propsv1.HostSecurityGroups)
+			for _, sg := range req.SecurityGroups {
+				hostSecurityGroupsV1.ByName[sg] = &propsv1.HostSecurityGroup{Name: sg, FromNetwork: false}
+			}
 			return nil
 		},
 	)
@@ -659,6 +1014,30 @@ func (handler *HostHandler) Create(
 		return nil, err
 	}
 
+	// Sets host extension NICsV1 when the order of the network interfaces has been requested
+	if len(hostNICs) > 0 {
+		err = host.Properties.LockForWrite(hostproperty.NICsV1).ThenUse(
+			func(clonable data.Clonable) error {
+				hostNICsV1 := clonable.(*propsv1.HostNICs)
+				hostNICsV1.ByIndex = make([]propsv1.HostNIC, 0, len(orderedNetworks))
+				for i, n := range orderedNetworks {
+					hostNICsV1.ByIndex = append(
+						hostNICsV1.ByIndex, propsv1.HostNIC{
+							Index:       i,
+							NetworkID:   n.ID,
+							NetworkName: n.Name,
+							Name:        hostRequest.NICName(n.ID),
+						},
+					)
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Updates host property propsv1.HostNetwork
 	var (
 		defaultNetworkID string
@@ -668,7 +1047,7 @@ func (handler *HostHandler) Create(
 		func(clonable data.Clonable) error {
 			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
 			defaultNetworkID = hostNetworkV1.DefaultNetworkID // set earlier by handler.service.CreateHost()
-			if !public {
+			if !req.Public {
 				if len(networks) > 0 {
 					mgw, err := metadata.LoadGateway(handler.service, defaultNetworkID)
 					if err == nil {
@@ -683,17 +1062,11 @@ func (handler *HostHandler) Create(
 			}
 			hostNetworkV1.DefaultGatewayID = gatewayID
 
-			if net != "" {
-				mn, err := metadata.LoadNetwork(handler.service, net)
-				if err != nil {
-					return err
-				}
-				network, err := mn.Get()
-				if err != nil {
-					return err
+			if defaultNet != "" {
+				for _, network := range networks {
+					hostNetworkV1.NetworksByID[network.ID] = network.Name
+					hostNetworkV1.NetworksByName[network.Name] = network.ID
 				}
-				hostNetworkV1.NetworksByID[network.ID] = network.Name
-				hostNetworkV1.NetworksByName[network.Name] = network.ID
 			}
 
 			return nil
@@ -724,11 +1097,17 @@ func (handler *HostHandler) Create(
 		return nil, err
 	}
 
-	_, err = sshCfg.WaitServerReady("phase1", temporal.GetHostCreationTimeout())
+	tracer.WithSpanAttribute("phase", "phase1")
+	_, err = sshCfg.WaitServerReady("phase1", temporal.EffectiveTimeout(ctx, temporal.GetHostCreationTimeout()))
 	if err != nil {
 		derr := err
 		if client.IsTimeoutError(derr) {
-			return nil, fail.Wrap(derr, fmt.Sprintf("timeout waiting host '%s' to become ready", host.Name))
+			msg := fmt.Sprintf("timeout waiting host '%s' to become ready", host.Name)
+			if output := handler.consoleOutputTail(host.ID); output != "" {
+				logrus.Errorf("last lines of console output of host '%s':\n%s", host.Name, output)
+				msg += fmt.Sprintf(", last lines of console output:\n%s", output)
+			}
+			return nil, fail.Wrap(derr, msg)
 		}
 
 		if client.IsProvisioningError(derr) {
@@ -746,6 +1125,7 @@ func (handler *HostHandler) Create(
 	if host == nil {
 		return nil, fail.InconsistentError("host is nil after WaitServerReady('phase1')")
 	}
+	sshHandler.recordHostKey(host, sshCfg)
 
 	// Updates host link with networks
 	for _, i := range networks {
@@ -767,7 +1147,16 @@ func (handler *HostHandler) Create(
 		}
 	}
 
+	// A host restored from a snapshot keeps the state of the snapshot, phase2 would overwrite it
+	if !hostRequest.RunsProvisioningPhases() {
+		logrus.Infof(
+			"Host '%s' restored from snapshot '%s', remaining provisioning phases skipped", host.Name, req.SourceSnapshot,
+		)
+		return handler.createHostVolumes(ctx, host, hostRequest.Volumes, hostRequest.Tags)
+	}
+
 	// Executes userdata phase2 script to finalize host installation
+	tracer.WithSpanAttribute("phase", "phase2")
 	userDataPhase2, err := userData.Generate("phase2")
 	if err != nil {
 		return nil, err
@@ -827,7 +1216,7 @@ func (handler *HostHandler) Create(
 				return nil
 			}
 		},
-		temporal.GetHostTimeout(),
+		temporal.EffectiveTimeout(ctx, temporal.GetHostTimeout()),
 		func(t retry.Try, v verdict.Enum) {
 			if v == verdict.Retry {
 				logrus.Debugf("Remote SSH service on host '%s' isn't ready, retrying...", host.Name)
@@ -859,18 +1248,29 @@ func (handler *HostHandler) Create(
 	// FIXME: AWS Retrieve data anyway
 	retrieveForensicsData(ctx, sshHandler, host)
 
-	// Reboot host
-	command = "sudo systemctl reboot"
-	retcode, _, _, err = sshHandler.Run(ctx, host.Name, command, outputs.COLLECT)
-	if err != nil {
-		return nil, err
-	}
-	if retcode != 0 && retcode != 255 {
-		return nil, fail.Errorf(fmt.Sprintf("reboot command failed: retcode=%d", retcode), nil)
+	rebootStopwatch := temporal.NewStopwatch()
+	rebootStopwatch.Start()
+	if hostRequest.SkipReboots {
+		if handler.rebootRequired(ctx, sshHandler, host) {
+			logrus.Warnf("reboot skipped on host '%s', but the system reports a reboot is required to apply all the changes", host.Name)
+		} else {
+			logrus.Infof("reboot skipped on host '%s'", host.Name)
+		}
+	} else {
+		// Reboot host
+		command = "sudo systemctl reboot"
+		retcode, _, _, err = sshHandler.Run(ctx, host.Name, command, outputs.COLLECT)
+		if err != nil {
+			return nil, err
+		}
+		if retcode != 0 && retcode != 255 {
+			return nil, fail.Errorf(fmt.Sprintf("reboot command failed: retcode=%d", retcode), nil)
+		}
 	}
 
 	// Wait like 2 min for the machine to reboot
-	_, err = sshCfg.WaitServerReady("ready", temporal.GetConnectSSHTimeout())
+	tracer.WithSpanAttribute("phase", "ready")
+	_, err = sshCfg.WaitServerReady("ready", temporal.EffectiveTimeout(ctx, temporal.GetConnectSSHTimeout()))
 	if err != nil {
 		if client.IsTimeoutError(err) {
 			return nil, err
@@ -887,7 +1287,12 @@ func (handler *HostHandler) Create(
 
 		return nil, err
 	}
-	logrus.Infof("SSH service started on host '%s'.", host.Name)
+	rebootStopwatch.Stop()
+	if hostRequest.SkipReboots {
+		logrus.Infof("SSH service ready on host '%s', %s spent instead of a reboot.", host.Name, temporal.FormatDuration(rebootStopwatch.Duration()))
+	} else {
+		logrus.Infof("SSH service started on host '%s', reboot took %s.", host.Name, temporal.FormatDuration(rebootStopwatch.Duration()))
+	}
 
 	select {
 	case <-ctx.Done():
@@ -897,33 +1302,219 @@ func (handler *HostHandler) Create(
 	default:
 	}
 
-	return host, nil
+	return handler.createHostVolumes(ctx, host, hostRequest.Volumes, hostRequest.Tags)
 }
 
-func getPhaseWarningsAndErrors(ctx context.Context, sshHandler *SSHHandler, host *abstract.Host) ([]string, []string) {
-	if sshHandler == nil || host == nil {
-		return []string{}, []string{}
+// createHostVolumes creates the volumes 'specs' with the tags of the host, then attaches, formats and mounts them on the new host 'host', and
+// returns the host with its updated properties
+// On failure, the volumes already created are detached and deleted, the caller deleting the host
+func (handler *HostHandler) createHostVolumes(
+	ctx context.Context, host *abstract.Host, specs []abstract.VolumeAttachmentSpec, tags map[string]string,
+) (_ *abstract.Host, err error) {
+	if len(specs) == 0 {
+		return host, nil
 	}
 
-	recoverCode, recoverStdOut, _, recoverErr := sshHandler.Run(
-		ctx, host.Name, fmt.Sprintf("cat %s/user_data.phase2.log; exit $?", utils.LogFolder), outputs.COLLECT,
-	)
-	var warnings []string
-	var errs []string
-
-	if recoverCode == 0 && recoverErr == nil {
-		lines := strings.Split(recoverStdOut, "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "An error occurred in line") {
-				warnings = append(warnings, line)
+	volHandler := NewVolumeHandler(handler.service)
+	var created, attached []string
+	defer func() {
+		if err != nil {
+			for _, name := range attached {
+				if derr := volHandler.Detach(context.Background(), name, host.Name); derr != nil {
+					logrus.Errorf("cleaning up on failure, failed to detach volume '%s': %v", name, derr)
+					err = fail.AddConsequence(err, derr)
+				}
 			}
-			if strings.HasPrefix(line, "PROVISIONING_ERROR:") {
-				errs = append(errs, line)
+			for _, name := range created {
+				if derr := volHandler.Delete(context.Background(), name); derr != nil {
+					logrus.Errorf("cleaning up on failure, failed to delete volume '%s': %v", name, derr)
+					err = fail.AddConsequence(err, derr)
+				}
 			}
 		}
-	}
+	}()
 
-	return warnings, errs
+	for i, spec := range specs {
+		name := fmt.Sprintf("%s-volume-%d", host.Name, i+1)
+		_, err = volHandler.(*VolumeHandler).createWithTags(ctx, name, spec.Size, spec.Speed, tags)
+		if err != nil {
+			return nil, fail.Wrap(err, fmt.Sprintf("failed to create volume '%s' of host '%s'", name, host.Name))
+		}
+		created = append(created, name)
+
+		_, err = volHandler.Attach(ctx, name, host.Name, spec.MountPoint, spec.Format, false)
+		if err != nil {
+			return nil, fail.Wrap(
+				err, fmt.Sprintf("failed to mount volume '%s' on '%s' of host '%s'", name, spec.MountPoint, host.Name),
+			)
+		}
+		attached = append(attached, name)
+	}
+
+	// Attach recorded the volumes and the mounts in the metadata of the host
+	return handler.loadHostMetadata(host.ID)
+}
+
+// provisioningLogLines is the number of lines of provisioning logs reported when the provisioning failed
+const provisioningLogLines = 50
+
+// StreamProvisioningLogs copies to w the logs of the provisioning phases of the host as they are written, until the
+// last phase ends; if the provisioning failed, the returned error contains the last lines of the logs
+// A host restored from a snapshot only runs phase1; an adopted host has no provisioning logs
+func (handler *HostHandler) StreamProvisioningLogs(ctx context.Context, ref string, w io.Writer) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if w == nil {
+		return fail.InvalidParameterError("w", "cannot be nil")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return err
+	}
+
+	var adopted, provisioningSkipped bool
+	err = host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostDescriptionV1 := clonable.(*propsv1.HostDescription)
+			adopted = hostDescriptionV1.Adopted
+			provisioningSkipped = hostDescriptionV1.ProvisioningSkipped
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if adopted {
+		return fail.NotAvailableError(fmt.Sprintf("host '%s' has been adopted, it has no provisioning logs", host.Name))
+	}
+	phases := []string{"phase1", "phase2"}
+	if provisioningSkipped {
+		phases = phases[:1]
+	}
+
+	sshCfg, err := NewSSHHandler(handler.service).GetConfig(ctx, host.ID)
+	if err != nil {
+		return err
+	}
+	cmd, err := sshCfg.CommandContext(ctx, provisioningLogScript(phases))
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	tail := newLineTail(provisioningLogLines)
+	_, copyErr := io.Copy(io.MultiWriter(w, tail), stdout)
+	err = cmd.Wait()
+	if err != nil {
+		return fail.Errorf(
+			fmt.Sprintf("provisioning of host '%s' failed or cannot be followed, last lines of logs:\n%s", host.Name, tail.String()), err,
+		)
+	}
+	if copyErr != nil {
+		return fail.Errorf(fmt.Sprintf("failed to copy provisioning logs of host '%s'", host.Name), copyErr)
+	}
+	return nil
+}
+
+// provisioningLogScript returns the script following the logs of the provisioning phases until the last one is done;
+// the script exits with the status of the last phase
+func provisioningLogScript(phases []string) string {
+	logs := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		logs = append(logs, fmt.Sprintf("%s/user_data.%s.log", utils.LogFolder, phase))
+	}
+	done := fmt.Sprintf("%s/user_data.%s.done", utils.StateFolder, phases[len(phases)-1])
+	return fmt.Sprintf(
+		"sudo tail -n +1 -F %s 2>/dev/null & TAIL_PID=$!; while ! sudo test -f %s; do sleep 2; done; sleep 2; sudo kill $TAIL_PID; exit $(sudo cut -d, -f1 %s)",
+		strings.Join(logs, " "), done, done,
+	)
+}
+
+// lineTail is an io.Writer keeping the last lines written to it
+type lineTail struct {
+	max     int
+	lines   []string
+	partial string
+}
+
+func newLineTail(max int) *lineTail {
+	return &lineTail{max: max}
+}
+
+// Write satisfies io.Writer
+func (lt *lineTail) Write(p []byte) (int, error) {
+	parts := strings.Split(lt.partial+string(p), "\n")
+	lt.partial = parts[len(parts)-1]
+	lt.lines = append(lt.lines, parts[:len(parts)-1]...)
+	if len(lt.lines) > lt.max {
+		lt.lines = lt.lines[len(lt.lines)-lt.max:]
+	}
+	return len(p), nil
+}
+
+// String returns the lines kept, including the last one even if incomplete
+func (lt *lineTail) String() string {
+	lines := lt.lines
+	if lt.partial != "" {
+		lines = append(lines[:len(lines):len(lines)], lt.partial)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rebootRequiredCommand exits with 0 if the system reports a reboot is needed (Debian/Ubuntu flag file, or
+// needs-restarting on RedHat/CentOS)
+const rebootRequiredCommand = "test -f /var/run/reboot-required || (command -v needs-restarting >/dev/null && ! sudo needs-restarting -r >/dev/null)"
+
+// rebootRequired tells if the system of the host reports a reboot is needed; false if it cannot be determined
+func (handler *HostHandler) rebootRequired(ctx context.Context, sshHandler *SSHHandler, host *abstract.Host) bool {
+	retcode, _, _, err := sshHandler.Run(ctx, host.Name, rebootRequiredCommand, outputs.COLLECT)
+	if err != nil {
+		logrus.Debugf("failed to check if host '%s' needs a reboot: %v", host.Name, err)
+		return false
+	}
+	return retcode == 0
+}
+
+func getPhaseWarningsAndErrors(ctx context.Context, sshHandler *SSHHandler, host *abstract.Host) ([]string, []string) {
+	if sshHandler == nil || host == nil {
+		return []string{}, []string{}
+	}
+
+	recoverCode, recoverStdOut, _, recoverErr := sshHandler.Run(
+		ctx, host.Name, fmt.Sprintf("cat %s/user_data.phase2.log; exit $?", utils.LogFolder), outputs.COLLECT,
+	)
+	var warnings []string
+	var errs []string
+
+	if recoverCode == 0 && recoverErr == nil {
+		lines := strings.Split(recoverStdOut, "\n")
+		for _, line := range lines {
+			if strings.HasPrefix(line, "An error occurred in line") {
+				warnings = append(warnings, line)
+			}
+			if strings.HasPrefix(line, "PROVISIONING_ERROR:") {
+				errs = append(errs, line)
+			}
+		}
+	}
+
+	return warnings, errs
 }
 
 func retrieveForensicsData(ctx context.Context, sshHandler *SSHHandler, host *abstract.Host) {
@@ -976,6 +1567,201 @@ func retrieveForensicsData(ctx context.Context, sshHandler *SSHHandler, host *ab
 	}
 }
 
+// currentCreator returns a description of the user running safescaled, used as creator of hosts
+func currentCreator() string {
+	creator := ""
+	hostname, _ := os.Hostname()
+	if curUser, err := user.Current(); err == nil {
+		creator = curUser.Username
+		if hostname != "" {
+			creator += "@" + hostname
+		}
+		if curUser.Name != "" {
+			creator += " (" + curUser.Name + ")"
+		}
+	} else {
+		creator = "unknown@" + hostname
+	}
+	return creator
+}
+
+// Adopt imports in SafeScale a host created outside of it, identified by providerRef (ID or name at the provider),
+// and binds it to the network networkRef, to which it must already be attached at the provider
+// The provisioning phases are not run on the host; privateKey, if not empty, is the key used to connect to it (if
+// empty, SSH access relies on the ssh-agent set by SAFESCALE_SSH_AGENT_SOCKET)
+func (handler *HostHandler) Adopt(ctx context.Context, providerRef string, networkRef string, privateKey string) (host *abstract.Host, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if providerRef == "" {
+		return nil, fail.InvalidParameterError("providerRef", "cannot be empty string")
+	}
+	if networkRef == "" {
+		return nil, fail.InvalidParameterError("networkRef", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", providerRef, networkRef), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err = handler.service.InspectHost(providerRef)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			host, err = handler.service.GetHostByName(providerRef)
+		}
+		if err != nil {
+			if _, ok := err.(fail.ErrNotFound); ok {
+				return nil, abstract.ResourceNotFoundError("host", providerRef)
+			}
+			return nil, err
+		}
+	}
+	if host.Properties == nil {
+		return nil, fail.InconsistentError(fmt.Sprintf("properties of host '%s' are missing", host.Name))
+	}
+
+	// Validates the host is not already managed by SafeScale
+	for _, ref := range []string{host.ID, host.Name} {
+		_, err = metadata.LoadHost(handler.service, ref)
+		if err == nil {
+			return nil, fail.DuplicateError(fmt.Sprintf("host '%s' is already managed by SafeScale", host.Name))
+		}
+		if _, ok := err.(fail.ErrNotFound); !ok {
+			return nil, err
+		}
+	}
+
+	mn, err := metadata.LoadNetwork(handler.service, networkRef)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil, abstract.ResourceNotFoundError("network", networkRef)
+		}
+		return nil, err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validates the host is attached to the network, and gets its address in it
+	var ipv4, ipv6 string
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			var innerErr error
+			ipv4, ipv6, innerErr = adoptedHostAddresses(host.Name, clonable.(*propsv1.HostNetwork), network)
+			return innerErr
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	host.PrivateKey = privateKey
+	err = host.Properties.LockForWrite(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostDescriptionV1 := clonable.(*propsv1.HostDescription)
+			hostDescriptionV1.Created = time.Now()
+			hostDescriptionV1.Creator = currentCreator()
+			hostDescriptionV1.ProvisioningSkipped = true
+			hostDescriptionV1.Adopted = true
+			hostDescriptionV1.Region, _ = handler.service.GetRegion()
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = host.Properties.LockForWrite(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			hostNetworkV1.DefaultNetworkID = network.ID
+			if host.ID != network.GatewayID && host.ID != network.SecondaryGatewayID {
+				hostNetworkV1.DefaultGatewayID = network.GatewayID
+			}
+			hostNetworkV1.NetworksByID[network.ID] = network.Name
+			hostNetworkV1.NetworksByName[network.Name] = network.ID
+			if ipv4 != "" {
+				hostNetworkV1.IPv4Addresses[network.ID] = ipv4
+			}
+			if ipv6 != "" {
+				hostNetworkV1.IPv6Addresses[network.ID] = ipv6
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mh, err := metadata.NewHost(handler.service)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := mh.Carry(host)
+	if err != nil {
+		return nil, err
+	}
+	err = ch.Write()
+	if err != nil {
+		return nil, err
+	}
+
+	// Starting from here, remove metadata if exiting with error
+	defer func() {
+		if err != nil {
+			derr := mh.Delete()
+			if derr != nil {
+				logrus.Errorf("failed to remove metadata of host '%s' after adoption failure", host.Name)
+				err = fail.AddConsequence(err, derr)
+			}
+		}
+	}()
+
+	err = network.Properties.LockForWrite(networkproperty.HostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			networkHostsV1 := clonable.(*propsv1.NetworkHosts)
+			networkHostsV1.ByName[host.Name] = host.ID
+			networkHostsV1.ByID[host.ID] = host.Name
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = metadata.SaveNetwork(handler.service, network)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Host '%s' adopted in network '%s'", host.Name, network.Name)
+	return host, nil
+}
+
+// adoptedHostAddresses returns the addresses of the host in the network, as reported by the provider
+// When the provider does not index them by network ID, the IPv4 address belonging to the CIDR of the network is used
+// Returns fail.ErrInvalidRequest if the host has no address in the network, ie it is not attached to it
+func adoptedHostAddresses(hostName string, hostNetwork *propsv1.HostNetwork, network *abstract.Network) (ipv4, ipv6 string, err error) {
+	ipv4, ipv6 = hostNetwork.IPv4Addresses[network.ID], hostNetwork.IPv6Addresses[network.ID]
+	if ipv4 == "" && ipv6 == "" && network.CIDR != "" {
+		_, cidr, innerErr := net.ParseCIDR(network.CIDR)
+		if innerErr != nil {
+			return "", "", fail.InconsistentError(fmt.Sprintf("invalid CIDR '%s' of network '%s'", network.CIDR, network.Name))
+		}
+		for _, ip := range hostNetwork.IPv4Addresses {
+			if parsed := net.ParseIP(ip); parsed != nil && cidr.Contains(parsed) {
+				ipv4 = ip
+				break
+			}
+		}
+	}
+	if ipv4 == "" && ipv6 == "" {
+		return "", "", fail.InvalidRequestError(
+			fmt.Sprintf("host '%s' is not attached to network '%s'", hostName, network.Name),
+		)
+	}
+	return ipv4, ipv6, nil
+}
+
 // getOrCreateDefaultNetwork gets network abstract.SingleHostNetworkName or create it if necessary
 // We don't want metadata on this network, so we use directly provider api instead of services
 func (handler *HostHandler) getOrCreateDefaultNetwork() (network *abstract.Network, err error) {
@@ -1035,16 +1821,17 @@ func (handler *HostHandler) List(ctx context.Context, all bool) (hosts []*abstra
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
+	svc := handler.service.ReadOnlyView()
 	if all {
-		return handler.service.ListHosts()
+		return svc.ListHosts()
 	}
 
-	m, err := metadata.NewHost(handler.service)
+	m, err := metadata.NewHost(svc)
 	if err != nil {
 		return nil, err
 	}
-	err = m.Browse(
-		func(host *abstract.Host) error {
+	_, err = m.BrowsePage(
+		ctx, "", 0, func(host *abstract.Host) error {
 			hosts = append(hosts, host)
 			return nil
 		},
@@ -1055,27 +1842,244 @@ func (handler *HostHandler) List(ctx context.Context, all bool) (hosts []*abstra
 	return hosts, nil
 }
 
-// Force 	 ...
-// If not found, return (nil, err)
-func (handler *HostHandler) ForceInspect(ctx context.Context, ref string) (host *abstract.Host, err error) {
-	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+// ListPage returns at most 'limit' hosts created by SafeScale (all of them if 0), in order of IDs, starting after the
+// host whose ID is 'marker' (from the first one if empty)
+// Also returns the marker to use to get the next page, empty if there is no more host to list.
+func (handler *HostHandler) ListPage(ctx context.Context, marker string, limit int) (hosts []*abstract.Host, next string, err error) {
+	if handler == nil {
+		return nil, "", fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, "", fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if limit < 0 {
+		return nil, "", fail.InvalidParameterError("limit", "cannot be negative")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %d)", marker, limit), true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
-	host, err = handler.Inspect(ctx, ref)
+	m, err := metadata.NewHost(handler.service.ReadOnlyView())
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	hosts = []*abstract.Host{}
+	next, err = m.BrowsePage(
+		ctx, marker, limit, func(host *abstract.Host) error {
+			hosts = append(hosts, host)
+			return nil
+		},
+	)
+	if err != nil {
+		return hosts, next, err
 	}
+	return hosts, next, nil
+}
 
-	return host, nil
+// HostFilter selects the hosts returned by ListFiltered; an empty field matches any host
+type HostFilter struct {
+	// Networks contains IDs or names of networks; a host matches if it is attached to one of them
+	Networks []string
+	// States contains the accepted states of the host, as recorded in metadata
+	States []hoststate.Enum
+	// Region contains the region where the host runs; the hosts without recorded region are in DefaultRegion
+	Region        string
+	DefaultRegion string
 }
 
-// Inspect returns the host identified by ref, ref can be the name or the id
-// If not found, returns (nil, *fail.ErrNotFound)
-// On any other error, returns (host, error)
-func (handler *HostHandler) Inspect(ctx context.Context, ref string) (host *abstract.Host, err error) {
-	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
-	defer tracer.OnExitTrace()()
+// Match tells if host satisfies the filter, reading only the properties needed
+func (f HostFilter) Match(host *abstract.Host) (bool, error) {
+	if host == nil {
+		return false, fail.InvalidParameterError("host", "cannot be nil")
+	}
+
+	if len(f.States) > 0 {
+		found := false
+		for _, state := range f.States {
+			if host.LastState == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if len(f.Networks) == 0 && f.Region == "" {
+		return true, nil
+	}
+	if host.Properties == nil {
+		return false, fail.InconsistentError("host properties are missing")
+	}
+
+	if f.Region != "" {
+		region, _, err := hostRegion(host)
+		if err != nil {
+			return false, err
+		}
+		if region == "" {
+			region = f.DefaultRegion
+		}
+		if region != f.Region {
+			return false, nil
+		}
+		if len(f.Networks) == 0 {
+			return true, nil
+		}
+	}
+
+	found := false
+	err := host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			for _, ref := range f.Networks {
+				if _, ok := hostNetworkV1.NetworksByID[ref]; ok {
+					found = true
+					return nil
+				}
+				if _, ok := hostNetworkV1.NetworksByName[ref]; ok {
+					found = true
+					return nil
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// ListFiltered returns the hosts registered in metadata matching filter, and the number of hosts scanned
+// Hosts whose metadata cannot be read are logged and skipped
+func (handler *HostHandler) ListFiltered(ctx context.Context, filter HostFilter) (hosts []*abstract.Host, scanned int, err error) {
+	if handler == nil {
+		return nil, 0, fail.InvalidInstanceError()
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("(%v)", filter), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	m, err := metadata.NewHost(handler.service.ReadOnlyView())
+	if err != nil {
+		return nil, 0, err
+	}
+	hosts = []*abstract.Host{}
+	_, err = m.BrowsePage(
+		ctx, "", 0, func(host *abstract.Host) error {
+			scanned++
+			ok, merr := filter.Match(host)
+			if merr != nil {
+				logrus.Warnf("skipping host '%s' with unreadable metadata: %v", host.Name, merr)
+				return nil
+			}
+			if ok {
+				hosts = append(hosts, host)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return hosts, scanned, err
+	}
+	return hosts, scanned, nil
+}
+
+// HostAudit reports the discrepancies between the hosts recorded in metadata and the hosts of the provider
+type HostAudit struct {
+	// Missing contains the names of the hosts recorded in metadata that do not exist anymore on the provider
+	Missing []string
+	// Unmanaged contains the names of the hosts of the provider that are not recorded in metadata
+	Unmanaged []string
+}
+
+// Audit compares the hosts recorded in metadata with the hosts of the provider, using a read-only view of the service
+func (handler *HostHandler) Audit(ctx context.Context) (audit *HostAudit, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+
+	tracer := debug.NewTracer(nil, "", true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	svc := handler.service.ReadOnlyView()
+	existing, err := svc.ListHosts()
+	if err != nil {
+		return nil, err
+	}
+	m, err := metadata.NewHost(svc)
+	if err != nil {
+		return nil, err
+	}
+	var managed []*abstract.Host
+	err = m.Browse(
+		func(host *abstract.Host) error {
+			managed = append(managed, host)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return auditHosts(managed, existing), nil
+}
+
+// auditHosts compares the hosts recorded in metadata ('managed') with the hosts of the provider ('existing');
+// terminated hosts of the provider are ignored
+func auditHosts(managed, existing []*abstract.Host) *HostAudit {
+	audit := &HostAudit{Missing: []string{}, Unmanaged: []string{}}
+	existingIDs := map[string]bool{}
+	for _, host := range existing {
+		if host.LastState != hoststate.TERMINATED {
+			existingIDs[host.ID] = true
+		}
+	}
+	managedIDs := map[string]bool{}
+	for _, host := range managed {
+		managedIDs[host.ID] = true
+		if !existingIDs[host.ID] {
+			audit.Missing = append(audit.Missing, host.Name)
+		}
+	}
+	for _, host := range existing {
+		if host.LastState != hoststate.TERMINATED && !managedIDs[host.ID] {
+			audit.Unmanaged = append(audit.Unmanaged, host.Name)
+		}
+	}
+	sort.Strings(audit.Missing)
+	sort.Strings(audit.Unmanaged)
+	return audit
+}
+
+// Force 	 ...
+// If not found, return (nil, err)
+func (handler *HostHandler) ForceInspect(ctx context.Context, ref string) (host *abstract.Host, err error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err = handler.Inspect(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+// Inspect returns the host identified by ref, ref can be the name or the id
+// If not found, returns (nil, *fail.ErrNotFound)
+// On any other error, returns (host, error)
+func (handler *HostHandler) Inspect(ctx context.Context, ref string) (host *abstract.Host, err error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
 	mh, err := metadata.LoadHost(handler.service, ref)
@@ -1090,6 +2094,11 @@ func (handler *HostHandler) Inspect(ctx context.Context, ref string) (host *abst
 	if err != nil {
 		return nil, err
 	}
+	knownState := host.LastState
+	knownZone, err := hostAvailabilityZone(host)
+	if err != nil {
+		return nil, err
+	}
 
 	retryErr := retryOnCommunicationFailure(
 		func() error {
@@ -1105,332 +2114,3623 @@ func (handler *HostHandler) Inspect(ctx context.Context, ref string) (host *abst
 	if host == nil {
 		return nil, fail.Errorf(fmt.Sprintf("failure inspecting host [%s]", ref), nil)
 	}
+	// A read-only view of the service is used by inspection code paths, that must neither write metadata nor publish
+	// events
+	if handler.service.IsReadOnly() {
+		return host, nil
+	}
+	handler.notifyHostState(host, knownState, host.LastState)
+
+	// Records the availability zone of the hosts created before it was recorded
+	if knownZone == "" {
+		zone, err := hostAvailabilityZone(host)
+		if err == nil && zone != "" {
+			if _, err = mh.Carry(host); err == nil {
+				err = mh.Write()
+			}
+		}
+		if err != nil {
+			logrus.Warnf("failed to record the availability zone of host '%s' in metadata: %v", host.Name, err)
+		}
+	}
 
 	return host, nil
 }
 
-// retryOnCommunicationFailure executes fn inside a retry loop with tolerance for communication errors (relative to net package)
-func retryOnCommunicationFailure(fn func() error, duration time.Duration) error {
-	// default duration is 10 seconds
-	if duration <= 0 {
-		duration = 10 * time.Second
-	}
+// hostAvailabilityZone returns the availability zone of the host recorded in its description
+func hostAvailabilityZone(host *abstract.Host) (zone string, err error) {
+	err = host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			zone = clonable.(*propsv1.HostDescription).AvailabilityZone
+			return nil
+		},
+	)
+	return zone, err
+}
 
-	err := retry.WhileUnsuccessfulDelay1Second(
-		func() error {
-			return normalizeError(fn())
+// hostRegion returns the region and the availability zone of the host recorded in its description
+func hostRegion(host *abstract.Host) (region, zone string, err error) {
+	err = host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostDescriptionV1 := clonable.(*propsv1.HostDescription)
+			region, zone = hostDescriptionV1.Region, hostDescriptionV1.AvailabilityZone
+			return nil
 		},
-		duration,
 	)
-	switch realErr := err.(type) {
-	case retry.ErrAborted:
-		err = realErr.Cause()
-	}
-	return err
+	return region, zone, err
 }
 
-// normalizeError analyzes the error passed as parameter and rewrite it to be more explicit
-// If the error is not a communication error, do not let a chance to retry by returning a *retry.ErrAborted error
-// containing the causing error in it
-func normalizeError(in error) (err error) {
-	// VPL: see if we could replace this defer with retry notification ability in retryOnCommunicationFailure
-	defer func() {
-		if err != nil {
-			switch err.(type) {
-			case fail.ErrInvalidRequest:
-				logrus.Warning(err.Error())
-			}
+// HostRegion returns the region and the availability zone recorded in the metadata of the host identified by ref,
+// without asking the provider; the region is empty for the hosts created before it was recorded
+func HostRegion(svc iaas.Service, ref string) (region, zone string, err error) {
+	if svc == nil {
+		return "", "", fail.InvalidParameterError("svc", "cannot be nil")
+	}
+	mh, err := metadata.LoadHost(svc, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return "", "", abstract.ResourceNotFoundError("host", ref)
 		}
-	}()
+		return "", "", err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return "", "", err
+	}
+	return hostRegion(host)
+}
 
-	if in != nil {
-		switch realErr := in.(type) {
-		case *url.Error:
-			switch commErr := realErr.Err.(type) {
-			case *net.DNSError:
-				return fail.InvalidRequestError(fmt.Sprintf("failed to resolve by DNS: %v", commErr))
-			default:
-				return fail.InvalidRequestError(
-					fmt.Sprintf(
-						"failed to communicate (error type: %s): %v", reflect.TypeOf(realErr).String(), realErr.Error(),
-					),
-				)
-			}
-		default:
-			// In any other case, the error should explain the potential retry has to stop
-			return fail.AbortedError("", in)
-		}
+// GetAvailabilityZone returns the availability zone (the zone on GCP) where the host identified by ref runs, or an
+// empty string if the provider does not tell it
+func (handler *HostHandler) GetAvailabilityZone(ctx context.Context, ref string) (zone string, err error) {
+	if handler == nil {
+		return "", fail.InvalidInstanceError()
 	}
-	return nil
+	if ref == "" {
+		return "", fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.Inspect(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return hostAvailabilityZone(host)
 }
 
-// Delete deletes host referenced by ref
-func (handler *HostHandler) Delete(ctx context.Context, ref string) (err error) {
+// InspectFull returns the host identified by ref with all its properties, read from metadata at once
+// The properties are refreshed from the provider as done by Inspect.
+func (handler *HostHandler) InspectFull(ctx context.Context, ref string) (details *abstract.HostDetails, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
 	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
-	mh, err := metadata.LoadHost(handler.service, ref)
+	host, err := handler.readOnly().ForceInspect(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return abstract.NewHostDetails(host)
+}
+
+// readOnly returns a handler using a read-only view of the service, for inspection code paths
+func (handler *HostHandler) readOnly() *HostHandler {
+	return &HostHandler{service: handler.service.ReadOnlyView()}
+}
+
+// GetAttachedVolume returns how the volume identified by volumeRef is attached and mounted on the host
+// A volume attached but not mounted is returned with empty mount point and filesystem.
+// Returns fail.ErrNotFound if the volume is not attached to the host.
+func (handler *HostHandler) GetAttachedVolume(ctx context.Context, ref string, volumeRef string) (av *abstract.AttachedVolume, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if volumeRef == "" {
+		return nil, fail.InvalidParameterError("volumeRef", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, volumeRef), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+	mv, err := metadata.LoadVolume(handler.service, volumeRef)
 	if err != nil {
 		if _, ok := err.(fail.ErrNotFound); ok {
-			return abstract.ResourceNotFoundError("host", ref)
+			return nil, abstract.ResourceNotFoundError("volume", volumeRef)
 		}
-		return err
+		return nil, err
+	}
+	volume, err := mv.Get()
+	if err != nil {
+		return nil, err
 	}
 
-	host, err := mh.Get()
+	return getAttachedVolume(host, volume)
+}
+
+// ListAttachedVolumes returns the volumes attached to the host, with their mount details
+func (handler *HostHandler) ListAttachedVolumes(ctx context.Context, ref string) (list []*abstract.AttachedVolume, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Don't remove a host having shares that are currently remotely mounted
-	var shares map[string]*propsv1.HostShare
-	err = host.Properties.LockForRead(hostproperty.SharesV1).ThenUse(
+
+	var volumeIDs []string
+	err = host.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
 		func(clonable data.Clonable) error {
-			shares = clonable.(*propsv1.HostShares).ByID
-			for _, share := range shares {
-				count := len(share.ClientsByID)
-				if count > 0 {
-					count = len(shares)
-					return fail.Errorf(
-						fmt.Sprintf(
-							"cannot delete host, exports %d share%s where at least one is used", count,
-							utils.Plural(count),
-						), nil,
-					)
-				}
+			for id := range clonable.(*propsv1.HostVolumes).VolumesByID {
+				volumeIDs = append(volumeIDs, id)
 			}
 			return nil
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Don't remove a host with volumes attached
-	err = host.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
-		func(clonable data.Clonable) error {
-			nAttached := len(clonable.(*propsv1.HostVolumes).VolumesByID)
-			if nAttached > 0 {
-				return fail.Errorf(
-					fmt.Sprintf("host has %d volume%s attached", nAttached, utils.Plural(nAttached)), nil,
-				)
-			}
-			return nil
-		},
-	)
+	list = []*abstract.AttachedVolume{}
+	for _, id := range volumeIDs {
+		mv, err := metadata.LoadVolume(handler.service, id)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := mv.Get()
+		if err != nil {
+			return nil, err
+		}
+		av, err := getAttachedVolume(host, volume)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, av)
+	}
+	return list, nil
+}
+
+// DetachAllVolumes unmounts and detaches all the volumes attached to the host, and returns the IDs of the detached volumes
+// Volumes already detached or deleted out-of-band are only removed from host metadata
+func (handler *HostHandler) DetachAllVolumes(ctx context.Context, ref string) (detached []string, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return nil, err
 	}
 
-	// Don't remove a host that is a gateway
-	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+	var volumeIDs []string
+	err = host.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
 		func(clonable data.Clonable) error {
-			if clonable.(*propsv1.HostNetwork).IsGateway {
-				return fail.Errorf(
-					fmt.Sprintf("cannot delete host, it's a gateway that can only be deleted through its network"), nil,
-				)
+			for id := range clonable.(*propsv1.HostVolumes).VolumesByID {
+				volumeIDs = append(volumeIDs, id)
 			}
 			return nil
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(volumeIDs)
+
+	detached = []string{}
+	volumeHandler := NewVolumeHandler(handler.service)
+	for _, id := range volumeIDs {
+		err = volumeHandler.Detach(ctx, id, host.ID)
+		if err != nil {
+			if _, ok := err.(fail.ErrNotFound); !ok {
+				return detached, err
+			}
+			logrus.Warnf("volume '%s' not found, removing it from metadata of host '%s'", id, host.Name)
+			err = handler.forgetVolume(host.ID, id)
+			if err != nil {
+				return detached, err
+			}
+		}
+		detached = append(detached, id)
+	}
+	return detached, nil
+}
+
+// forgetVolume removes from host metadata the references to a volume that does not exist anymore
+func (handler *HostHandler) forgetVolume(hostID, volumeID string) error {
+	mh, err := metadata.LoadHost(handler.service, hostID)
+	if err != nil {
+		return err
+	}
+	host, err := mh.Get()
 	if err != nil {
 		return err
 	}
 
-	// If host mounted shares, unmounts them before anything else
-	shareHandler := NewShareHandler(handler.service)
-	var mounts []*propsv1.HostShare
-	err = host.Properties.LockForRead(hostproperty.MountsV1).ThenUse(
+	err = host.Properties.LockForWrite(hostproperty.VolumesV1).ThenUse(
 		func(clonable data.Clonable) error {
-			hostMountsV1 := clonable.(*propsv1.HostMounts)
-			for _, i := range hostMountsV1.RemoteMountsByPath {
-				// Gets share data
-				_, share, _, err := shareHandler.Inspect(ctx, i.ShareID)
-				if err != nil {
-					return err
-				}
-				if share == nil {
-					return abstract.ResourceNotFoundError("share", i.ShareID)
-				}
-				mounts = append(mounts, share)
-			}
-			return nil
+			hostVolumesV1 := clonable.(*propsv1.HostVolumes)
+			return host.Properties.LockForWrite(hostproperty.MountsV1).ThenUse(
+				func(clonable data.Clonable) error {
+					hostMountsV1 := clonable.(*propsv1.HostMounts)
+					device, ok := hostVolumesV1.DevicesByID[volumeID]
+					if ok {
+						if path, ok := hostMountsV1.LocalMountsByDevice[device]; ok {
+							delete(hostMountsV1.LocalMountsByPath, path)
+						}
+						delete(hostMountsV1.LocalMountsByDevice, device)
+						delete(hostVolumesV1.VolumesByDevice, device)
+					}
+					delete(hostVolumesV1.VolumesByID, volumeID)
+					delete(hostVolumesV1.DevicesByID, volumeID)
+					for name, id := range hostVolumesV1.VolumesByName {
+						if id == volumeID {
+							delete(hostVolumesV1.VolumesByName, name)
+						}
+					}
+					return nil
+				},
+			)
 		},
 	)
 	if err != nil {
 		return err
 	}
+	return mh.Write()
+}
 
-	// Unmounts tier shares mounted on host (done outside the previous host.Properties.Reading() section, because
-	// Unmount() have to lock for write, and won't succeed while host.Properties.Reading() is running,
-	// leading to a deadlock)
-	for _, share := range mounts {
-		err = shareHandler.Unmount(ctx, share.Name, host.Name)
-		if err != nil {
-			return err
+// AddFeature installs the feature named featureName on the host and records it in host property FeaturesV1
+// If the installation fails, even partially, or if the metadata cannot be updated afterwards, the feature is removed
+func (handler *HostHandler) AddFeature(ctx context.Context, ref string, featureName string, vars install.Variables, settings install.Settings) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if featureName == "" {
+		return fail.InvalidParameterError("featureName", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, featureName), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return abstract.ResourceNotFoundError("host", ref)
 		}
+		return err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return err
+	}
+	pbHost, err := srvutils.ToPBHost(host)
+	if err != nil {
+		return err
 	}
 
-	// if host exports shares, delete them
-	for _, share := range shares {
-		err = shareHandler.Delete(ctx, share.Name)
+	// Waits here cloud-init is done, the installation would compete with it for the package manager locks
+	if settings.WaitCloudInit {
+		err = handler.WaitForCloudInitDone(ctx, host.ID, 0)
 		if err != nil {
 			return err
 		}
+		settings.WaitCloudInit = false
 	}
 
-	// Update networks property prosv1.NetworkHosts to remove the reference to the host
-	netHandler := NewNetworkHandler(handler.service)
-	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
-		func(clonable data.Clonable) error {
-			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
-			for k := range hostNetworkV1.NetworksByID {
-				network, err := netHandler.Inspect(ctx, k)
-				if err != nil {
-					logrus.Errorf(err.Error())
-					continue
-				}
-				err = network.Properties.LockForWrite(networkproperty.HostsV1).ThenUse(
-					func(clonable data.Clonable) error {
-						networkHostsV1 := clonable.(*propsv1.NetworkHosts)
-						delete(networkHostsV1.ByID, host.ID)
-						delete(networkHostsV1.ByName, host.Name)
-						return nil
-					},
-				)
-				if err != nil {
-					logrus.Errorf(err.Error())
-				}
-				_, err = metadata.SaveNetwork(handler.service, network)
-				if err != nil {
-					logrus.Errorf(err.Error())
-				}
-			}
-			return nil
-		},
-	)
+	task, err := concurrency.NewTaskWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	feature, err := install.NewFeature(task, featureName)
+	if err != nil {
+		return err
+	}
+	if feature == nil {
+		return abstract.ResourceNotFoundError("feature", featureName)
+	}
+	target, err := install.NewHostTarget(pbHost)
 	if err != nil {
 		return err
 	}
 
-	// Conditions are met, delete host
-	var (
-		deleteMetadataOnly bool
-		moreTimeNeeded     bool
-	)
-	retryErr := retryOnCommunicationFailure(
+	return addFeatureTransactionally(
 		func() error {
-			if host != nil {
-				return handler.service.DeleteHost(host.ID)
+			results, innerErr := feature.Add(target, vars, settings)
+			if innerErr != nil {
+				return innerErr
+			}
+			if !results.Successful() {
+				return fail.Errorf(fmt.Sprintf("failed to add feature '%s' on host '%s': %s", featureName, host.Name, results.Report()), nil)
 			}
 			return nil
 		},
-		0,
+		func() error {
+			results, innerErr := feature.Remove(target, vars, settings)
+			if innerErr != nil {
+				return innerErr
+			}
+			if !results.Successful() {
+				return fail.Errorf(fmt.Sprintf("failed to remove feature '%s' from host '%s': %s", featureName, host.Name, results.Report()), nil)
+			}
+			return nil
+		},
+		func() error {
+			innerErr := host.Properties.LockForWrite(hostproperty.FeaturesV1).ThenUse(
+				func(clonable data.Clonable) error {
+					hostFeaturesV1 := clonable.(*propsv1.HostFeatures)
+					installed := propsv1.NewHostInstalledFeature()
+					installed.HostContext = true
+					installed.Version = feature.Version()
+					hostFeaturesV1.Installed[featureName] = installed
+					return nil
+				},
+			)
+			if innerErr != nil {
+				return innerErr
+			}
+			return mh.Write()
+		},
 	)
-	if retryErr != nil {
-		switch retryErr.(type) {
-		case fail.ErrNotFound:
-			deleteMetadataOnly = true
-		case fail.ErrTimeout:
-			moreTimeNeeded = true
-		default:
-			return retryErr
-		}
+}
+
+// RemoveFeature uninstalls the feature named featureName from the host and forgets it in host property FeaturesV1
+func (handler *HostHandler) RemoveFeature(ctx context.Context, ref string, featureName string, vars install.Variables, settings install.Settings) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if featureName == "" {
+		return fail.InvalidParameterError("featureName", "cannot be empty string")
 	}
 
-	// FIXME: Add GetHostState verification
-	if moreTimeNeeded {
-		if state, ok := handler.service.GetHostState(host.ID); ok == nil { // FIXME: Unhandled timeout
-			logrus.Warnf("While deleting the status was [%s]", state)
-			if state != hoststate.ERROR {
-				deleteMetadataOnly = true
-			} else {
-				return err
-			}
-		} else {
-			return err
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, featureName), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return abstract.ResourceNotFoundError("host", ref)
 		}
+		return err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return err
+	}
+	pbHost, err := srvutils.ToPBHost(host)
+	if err != nil {
+		return err
 	}
 
-	err = mh.Delete()
+	task, err := concurrency.NewTaskWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	feature, err := install.NewFeature(task, featureName)
+	if err != nil {
+		return err
+	}
+	if feature == nil {
+		return abstract.ResourceNotFoundError("feature", featureName)
+	}
+	target, err := install.NewHostTarget(pbHost)
 	if err != nil {
 		return err
 	}
 
-	if deleteMetadataOnly {
-		return fail.Errorf(
-			fmt.Sprintf("unable to find the host even if it is described by metadata. Dirty metadata have been deleted"),
-			nil,
-		)
+	results, err := feature.Remove(target, vars, settings)
+	if err != nil {
+		return err
+	}
+	if !results.Successful() {
+		return fail.Errorf(fmt.Sprintf("failed to remove feature '%s' from host '%s': %s", featureName, host.Name, results.Report()), nil)
 	}
 
-	select {
-	case <-ctx.Done():
-		logrus.Warnf("Host delete cancelled by safescale")
-		var hostBis *abstract.Host
-		err2 := host.Properties.LockForRead(hostproperty.SizingV1).ThenUse(
-			func(clonable data.Clonable) error {
-				hostSizingV1 := clonable.(*propsv1.HostSizing)
-				return host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
-					func(clonable data.Clonable) error {
-						hostDescriptionV1 := clonable.(*propsv1.HostDescription)
-						return host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
-							func(clonable data.Clonable) error {
-								hostNetworkV1 := clonable.(*propsv1.HostNetwork)
-								// FIXME: host's os name is not stored in metadata so we used ubuntu 18.04 by default
-								var err3 error
-								sizing := abstract.SizingRequirements{
-									MinCores:    hostSizingV1.AllocatedSize.Cores,
-									MaxCores:    hostSizingV1.AllocatedSize.Cores,
-									MinFreq:     hostSizingV1.AllocatedSize.CPUFreq,
-									MinGPU:      hostSizingV1.AllocatedSize.GPUNumber,
-									MinRAMSize:  hostSizingV1.AllocatedSize.RAMSize,
-									MaxRAMSize:  hostSizingV1.AllocatedSize.RAMSize,
-									MinDiskSize: hostSizingV1.AllocatedSize.DiskSize,
-								}
-								hostBis, err3 = handler.Create(
-									context.Background(), host.Name, hostNetworkV1.DefaultNetworkID, "ubuntu 18.04",
-									(len(hostNetworkV1.PublicIPv4)+len(hostNetworkV1.PublicIPv6)) != 0, &sizing, true,
-									hostDescriptionV1.Domain, false,
-								)
-								if err3 != nil {
-									return fail.Errorf(
-										fmt.Sprintf("failed to stop host deletion : %s", err3.Error()), err3,
-									)
-								}
-								return nil
-							},
-						)
-					},
+	err = host.Properties.LockForWrite(hostproperty.FeaturesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			delete(clonable.(*propsv1.HostFeatures).Installed, featureName)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return mh.Write()
+}
+
+// FeatureEnsureResult tells what EnsureFeature had to do to have the feature installed
+type FeatureEnsureResult string
+
+const (
+	// FeatureAlreadyPresent means the feature was already installed, in the wanted version
+	FeatureAlreadyPresent FeatureEnsureResult = "already-present"
+	// FeatureInstalled means the feature was not installed and has been installed
+	FeatureInstalled FeatureEnsureResult = "installed"
+	// FeatureUpgraded means the feature was installed in another version and has been installed again
+	FeatureUpgraded FeatureEnsureResult = "upgraded"
+)
+
+// EnsureFeature installs the feature named featureName on the host only if it is not already installed, or if the
+// version recorded in host property FeaturesV1 differs from the one of the feature specification
+// Running it again once it succeeded does nothing, so it can be used by provisioning scripts meant to be re-run.
+func (handler *HostHandler) EnsureFeature(ctx context.Context, ref string, featureName string, vars install.Variables, settings install.Settings) (result FeatureEnsureResult, err error) {
+	if handler == nil {
+		return "", fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return "", fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if featureName == "" {
+		return "", fail.InvalidParameterError("featureName", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, featureName), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return "", abstract.ResourceNotFoundError("host", ref)
+		}
+		return "", err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return "", err
+	}
+	pbHost, err := srvutils.ToPBHost(host)
+	if err != nil {
+		return "", err
+	}
+
+	task, err := concurrency.NewTaskWithContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	feature, err := install.NewFeature(task, featureName)
+	if err != nil {
+		return "", err
+	}
+	if feature == nil {
+		return "", abstract.ResourceNotFoundError("feature", featureName)
+	}
+	target, err := install.NewHostTarget(pbHost)
+	if err != nil {
+		return "", err
+	}
+
+	var recorded *propsv1.HostInstalledFeature
+	err = host.Properties.LockForRead(hostproperty.FeaturesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			if installed, ok := clonable.(*propsv1.HostFeatures).Installed[featureName]; ok {
+				recorded = installed.Clone().(*propsv1.HostInstalledFeature)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	results, err := feature.Check(target, vars, settings)
+	if err != nil {
+		return "", err
+	}
+
+	result = decideFeatureEnsure(results.Successful(), recorded, feature.Version())
+	if result != FeatureAlreadyPresent {
+		if err = checkNotFrozen(ctx, host); err != nil {
+			return "", err
+		}
+	}
+	switch result {
+	case FeatureAlreadyPresent:
+		if recorded != nil {
+			return result, nil
+		}
+		// installed outside of SafeScale, records it so the next runs know its version
+		err = host.Properties.LockForWrite(hostproperty.FeaturesV1).ThenUse(
+			func(clonable data.Clonable) error {
+				installed := propsv1.NewHostInstalledFeature()
+				installed.HostContext = true
+				installed.Version = feature.Version()
+				clonable.(*propsv1.HostFeatures).Installed[featureName] = installed
+				return nil
+			},
+		)
+		if err != nil {
+			return "", err
+		}
+		return result, mh.Write()
+	default:
+		// the check has been done here, AddFeature must not skip the installation of an outdated version
+		settings.AddUnconditionally = true
+		err = handler.AddFeature(ctx, host.ID, featureName, vars, settings)
+		if err != nil {
+			return "", err
+		}
+		return result, nil
+	}
+}
+
+// decideFeatureEnsure tells what EnsureFeature has to do, given the result of the check of the feature on the host,
+// the installation recorded in host metadata (nil if none) and the version of the feature specification
+func decideFeatureEnsure(checked bool, recorded *propsv1.HostInstalledFeature, version string) FeatureEnsureResult {
+	if !checked {
+		return FeatureInstalled
+	}
+	if recorded != nil && version != "" && recorded.Version != version {
+		return FeatureUpgraded
+	}
+	return FeatureAlreadyPresent
+}
+
+// addFeatureTransactionally runs add then record; if any of them fails, remove is called to undo what add may
+// have done, and its failure, if any, is added as a consequence of the original error
+func addFeatureTransactionally(add, remove, record func() error) (err error) {
+	defer func() {
+		if err != nil {
+			if derr := remove(); derr != nil {
+				logrus.Errorf("failed to roll back feature installation: %v", derr)
+				err = fail.AddConsequence(err, derr)
+			}
+		}
+	}()
+
+	err = add()
+	if err != nil {
+		return err
+	}
+	return record()
+}
+
+// GetGateways returns the primary and secondary gateways of the default network of the host
+// secondary is nil, without error, if the network has no secondary gateway; a gateway is its own primary gateway
+func (handler *HostHandler) GetGateways(ctx context.Context, ref string) (primary, secondary *abstract.Host, err error) {
+	if handler == nil {
+		return nil, nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		isGateway        bool
+		defaultNetworkID string
+	)
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			isGateway = hostNetworkV1.IsGateway
+			defaultNetworkID = hostNetworkV1.DefaultNetworkID
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isGateway {
+		return host, nil, nil
+	}
+	if defaultNetworkID == "" {
+		return nil, nil, fail.NotFoundError(fmt.Sprintf("host '%s' has no default network", host.Name))
+	}
+
+	mn, err := metadata.LoadNetwork(handler.service, defaultNetworkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return nil, nil, err
+	}
+	if network.GatewayID == "" {
+		return nil, nil, fail.NotFoundError(fmt.Sprintf("network '%s' of host '%s' has no gateway", network.Name, host.Name))
+	}
+
+	primary, err = handler.loadHostMetadata(network.GatewayID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if network.SecondaryGatewayID == "" {
+		return primary, nil, nil
+	}
+	secondary, err = handler.loadHostMetadata(network.SecondaryGatewayID)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); !ok {
+			return nil, nil, err
+		}
+		logrus.Warnf("secondary gateway '%s' of network '%s' not found", network.SecondaryGatewayID, network.Name)
+		return primary, nil, nil
+	}
+	return primary, secondary, nil
+}
+
+// loadHostMetadata returns the host identified by ref as stored in metadata
+func (handler *HostHandler) loadHostMetadata(ref string) (*abstract.Host, error) {
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil, abstract.ResourceNotFoundError("host", ref)
+		}
+		return nil, err
+	}
+	return mh.Get()
+}
+
+// getAttachedVolume cross-references host properties VolumesV1 and MountsV1 to describe how volume is used by host
+func getAttachedVolume(host *abstract.Host, volume *abstract.Volume) (*abstract.AttachedVolume, error) {
+	av := &abstract.AttachedVolume{
+		ID:   volume.ID,
+		Name: volume.Name,
+		Size: volume.Size,
+	}
+
+	var mountKey string
+	err := host.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostVolumesV1 := clonable.(*propsv1.HostVolumes)
+			attachment, found := hostVolumesV1.VolumesByID[volume.ID]
+			if !found {
+				return fail.NotFoundError(fmt.Sprintf("volume '%s' is not attached to host '%s'", volume.Name, host.Name))
+			}
+			av.Device = attachment.Device
+			if mountKey, found = hostVolumesV1.DevicesByID[volume.ID]; !found {
+				mountKey = attachment.Device
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = host.Properties.LockForRead(hostproperty.MountsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostMountsV1 := clonable.(*propsv1.HostMounts)
+			if path, ok := hostMountsV1.LocalMountsByDevice[mountKey]; ok {
+				if mount, ok := hostMountsV1.LocalMountsByPath[path]; ok {
+					av.MountPoint = mount.Path
+					av.FileSystem = mount.FileSystem
+				}
+			}
+			// Not mounted (yet), keep mount information empty
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return av, nil
+}
+
+// splitNetworkList splits a comma-separated list of networks, ignoring empty items
+func splitNetworkList(list string) []string {
+	var out []string
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// checkNetworksCompatibility checks the networks of a host belong to the same provider network as the first one,
+// the default network, so the host doesn't get unroutable interfaces; returns nil if allowCrossNetwork is set.
+// Only the networks whose provider network is known (as reported by stacks listing subnets, AWS for instance) are
+// checked: networks loaded from metadata don't carry it and can't be told apart
+func checkNetworksCompatibility(networks []*abstract.Network, allowCrossNetwork bool) error {
+	if allowCrossNetwork || len(networks) < 2 {
+		return nil
+	}
+	expected, ok := providerNetworkID(networks[0])
+	if !ok {
+		return nil
+	}
+	var offending []string
+	for _, n := range networks[1:] {
+		if id, ok := providerNetworkID(n); ok && id != expected {
+			offending = append(offending, n.Name)
+		}
+	}
+	if len(offending) > 0 {
+		return fail.InvalidRequestError(
+			fmt.Sprintf(
+				"networks '%s' don't belong to the same network as default network '%s'",
+				strings.Join(offending, "', '"), networks[0].Name,
+			),
+		)
+	}
+	return nil
+}
+
+// providerNetworkID returns the ID of the provider network holding the subnet 'n', and false if it is unknown
+func providerNetworkID(n *abstract.Network) (string, bool) {
+	if n.Subnet && n.Parent != "" {
+		return n.Parent, true
+	}
+	return "", false
+}
+
+// parseNICs converts the items "<network>:<index>[:<name>]" of 'nics' in network interfaces on 'networks', the
+// network being referenced by name or ID
+func parseNICs(nics []string, networks []*abstract.Network) ([]abstract.HostNIC, error) {
+	var out []abstract.HostNIC
+	for _, v := range nics {
+		parts := strings.SplitN(strings.TrimSpace(v), ":", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("invalid network interface '%s': expected '<network>:<index>[:<name>]'", v),
+			)
+		}
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fail.InvalidRequestError(fmt.Sprintf("invalid index '%s' for network interface '%s'", parts[1], v))
+		}
+		var network *abstract.Network
+		for _, n := range networks {
+			if n.Name == parts[0] || n.ID == parts[0] {
+				network = n
+				break
+			}
+		}
+		if network == nil {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("network interface '%s' is on network '%s' the host is not attached to", v, parts[0]),
+			)
+		}
+		nic := abstract.HostNIC{NetworkID: network.ID, Index: index}
+		if len(parts) == 3 {
+			nic.Name = parts[2]
+		}
+		out = append(out, nic)
+	}
+	return out, nil
+}
+
+// parseFixedIPs converts the items "<network>:<ip>" of 'fixedIPs' in IP addresses indexed by the ID of their network
+// in 'networks', the network being referenced by name or ID; the stacks check the addresses are usable
+func parseFixedIPs(fixedIPs []string, networks []*abstract.Network) (map[string]string, error) {
+	if len(fixedIPs) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]string, len(fixedIPs))
+	for _, v := range fixedIPs {
+		parts := strings.SplitN(strings.TrimSpace(v), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fail.InvalidRequestError(fmt.Sprintf("invalid fixed IP '%s': expected '<network>:<ip>'", v))
+		}
+		var network *abstract.Network
+		for _, n := range networks {
+			if n.Name == parts[0] || n.ID == parts[0] {
+				network = n
+				break
+			}
+		}
+		if network == nil {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("fixed IP '%s' is on network '%s' the host is not attached to", v, parts[0]),
+			)
+		}
+		if _, ok := out[network.ID]; ok {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("several fixed IPs requested on network '%s'", network.Name),
+			)
+		}
+		out[network.ID] = parts[1]
+	}
+	return out, nil
+}
+
+// parseVolumes converts the items "<size>:<mountpoint>[:<speed>[:<format>]]" of 'volumes' in volumes to create with
+// the host; speed defaults to HDD and format to ext4
+func parseVolumes(volumes []string) ([]abstract.VolumeAttachmentSpec, error) {
+	var out []abstract.VolumeAttachmentSpec
+	for _, v := range volumes {
+		parts := strings.Split(strings.TrimSpace(v), ":")
+		if len(parts) < 2 || len(parts) > 4 {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("invalid volume '%s': expected '<size>:<mountpoint>[:<speed>[:<format>]]'", v),
+			)
+		}
+		size, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fail.InvalidRequestError(fmt.Sprintf("invalid size '%s' for volume '%s'", parts[0], v))
+		}
+		spec := abstract.VolumeAttachmentSpec{
+			Size:       size,
+			Speed:      volumespeed.HDD,
+			MountPoint: parts[1],
+			Format:     "ext4",
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			found := false
+			for _, speed := range []volumespeed.Enum{volumespeed.COLD, volumespeed.HDD, volumespeed.SSD} {
+				if strings.EqualFold(parts[2], speed.String()) {
+					spec.Speed, found = speed, true
+					break
+				}
+			}
+			if !found {
+				return nil, fail.InvalidRequestError(fmt.Sprintf("invalid speed '%s' for volume '%s'", parts[2], v))
+			}
+		}
+		if len(parts) > 3 && parts[3] != "" {
+			spec.Format = parts[3]
+		}
+		out = append(out, spec)
+	}
+	return out, nil
+}
+
+// preferTemplatesFittingImage returns the templates with the ones whose disk is large enough for the image first,
+// keeping the order of selection otherwise (the stacks enlarge the system disk of the others)
+func preferTemplatesFittingImage(templates []*abstract.HostTemplate, img *abstract.Image) []*abstract.HostTemplate {
+	if img == nil || img.MinDiskGB == 0 {
+		return templates
+	}
+	fitting := make([]*abstract.HostTemplate, 0, len(templates))
+	var tooSmall []*abstract.HostTemplate
+	for _, t := range templates {
+		if t.DiskSize > 0 && t.DiskSize < img.MinDiskGB {
+			logrus.Debugf(
+				"template '%s' has a %d GB disk, image '%s' requires at least %d GB", t.Name, t.DiskSize, img.Name,
+				img.MinDiskGB,
+			)
+			tooSmall = append(tooSmall, t)
+			continue
+		}
+		fitting = append(fitting, t)
+	}
+	return append(fitting, tooSmall...)
+}
+
+// retryOnCommunicationFailure executes fn inside a retry loop with tolerance for communication errors (relative to net package)
+func retryOnCommunicationFailure(fn func() error, duration time.Duration) error {
+	// default duration is 10 seconds
+	if duration <= 0 {
+		duration = 10 * time.Second
+	}
+
+	err := retry.WhileUnsuccessfulWithJitter(
+		func() error {
+			return normalizeError(fn())
+		},
+		temporal.GetMinDelay(), temporal.GetDefaultDelay(), duration,
+	)
+	switch realErr := err.(type) {
+	case retry.ErrAborted:
+		err = realErr.Cause()
+	}
+	return err
+}
+
+// normalizeError analyzes the error passed as parameter and rewrite it to be more explicit
+// If the error is not a communication error, do not let a chance to retry by returning a *retry.ErrAborted error
+// containing the causing error in it
+func normalizeError(in error) (err error) {
+	// VPL: see if we could replace this defer with retry notification ability in retryOnCommunicationFailure
+	defer func() {
+		if err != nil {
+			switch err.(type) {
+			case fail.ErrInvalidRequest:
+				logrus.Warning(err.Error())
+			}
+		}
+	}()
+
+	if in != nil {
+		switch realErr := in.(type) {
+		case *url.Error:
+			switch commErr := realErr.Err.(type) {
+			case *net.DNSError:
+				return fail.InvalidRequestError(fmt.Sprintf("failed to resolve by DNS: %v", commErr))
+			default:
+				return fail.InvalidRequestError(
+					fmt.Sprintf(
+						"failed to communicate (error type: %s): %v", reflect.TypeOf(realErr).String(), realErr.Error(),
+					),
+				)
+			}
+		default:
+			// In any other case, the error should explain the potential retry has to stop
+			return fail.AbortedError("", in)
+		}
+	}
+	return nil
+}
+
+// WaitHostDeleted waits until the provider reports the host 'providerRef' (its ID on provider side) as not found,
+// polling its state; the metadata of the host are not used, so it can be called once the host deleted with Delete.
+// Returns a fail.ErrTimeout if the host still exists after 'timeout', a fail.ErrAborted if 'ctx' is cancelled.
+// Deleting several hosts then waiting for all of them is faster than waiting for each deletion in turn.
+func WaitHostDeleted(ctx context.Context, svc iaas.Service, providerRef string, timeout time.Duration) error {
+	return waitHostDeleted(ctx, svc, providerRef, timeout, temporal.GetMinDelay())
+}
+
+// waitHostDeleted is WaitHostDeleted polling every 'delay'
+func waitHostDeleted(ctx context.Context, svc iaas.Service, providerRef string, timeout time.Duration, delay time.Duration) error {
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if svc == nil {
+		return fail.InvalidParameterError("svc", "cannot be nil")
+	}
+	if providerRef == "" {
+		return fail.InvalidParameterError("providerRef", "cannot be empty string")
+	}
+
+	err := retry.WhileUnsuccessful(
+		func() error {
+			if ctx.Err() != nil {
+				return fail.AbortedError("waiting for host deletion cancelled", ctx.Err())
+			}
+			state, inErr := svc.GetHostState(providerRef)
+			if inErr != nil {
+				if _, ok := inErr.(fail.ErrNotFound); ok {
+					return nil
+				}
+				return inErr
+			}
+			if state == hoststate.TERMINATED {
+				return nil
+			}
+			return fail.Errorf(fmt.Sprintf("host '%s' still exists in state '%s'", providerRef, state.String()), nil)
+		},
+		delay,
+		timeout,
+	)
+	if err != nil {
+		switch err.(type) {
+		case retry.ErrTimeout:
+			return fail.TimeoutError(
+				fmt.Sprintf(
+					"host '%s' not deleted after %s", providerRef, temporal.FormatDuration(timeout),
+				), timeout, fail.Cause(err),
+			)
+		case fail.ErrAborted:
+			return err
+		}
+		return fail.Wrap(err, fmt.Sprintf("failed to confirm deletion of host '%s'", providerRef))
+	}
+	return nil
+}
+
+// Delete deletes host referenced by ref
+func (handler *HostHandler) Delete(ctx context.Context, ref string, detachVolumes bool) (err error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, detachVolumes), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return abstract.ResourceNotFoundError("host", ref)
+		}
+		return err
+	}
+
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return err
+	}
+
+	if detachVolumes {
+		_, err = handler.DetachAllVolumes(ctx, host.ID)
+		if err != nil {
+			return err
+		}
+		// Metadata has been updated by the detachments, reload it
+		mh, err = metadata.LoadHost(handler.service, host.ID)
+		if err != nil {
+			return err
+		}
+		host, err = mh.Get()
+		if err != nil {
+			return err
+		}
+	}
+	// Don't remove a host having shares that are currently remotely mounted
+	var shares map[string]*propsv1.HostShare
+	err = host.Properties.LockForRead(hostproperty.SharesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			shares = clonable.(*propsv1.HostShares).ByID
+			for _, share := range shares {
+				count := len(share.ClientsByID)
+				if count > 0 {
+					count = len(shares)
+					return fail.Errorf(
+						fmt.Sprintf(
+							"cannot delete host, exports %d share%s where at least one is used", count,
+							utils.Plural(count),
+						), nil,
+					)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Don't remove a host with volumes attached
+	err = host.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			nAttached := len(clonable.(*propsv1.HostVolumes).VolumesByID)
+			if nAttached > 0 {
+				return fail.Errorf(
+					fmt.Sprintf("host has %d volume%s attached", nAttached, utils.Plural(nAttached)), nil,
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Don't remove a host that is a gateway
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			if clonable.(*propsv1.HostNetwork).IsGateway {
+				return fail.Errorf(
+					fmt.Sprintf("cannot delete host, it's a gateway that can only be deleted through its network"), nil,
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// If host mounted shares, unmounts them before anything else
+	shareHandler := NewShareHandler(handler.service)
+	var mounts []*propsv1.HostShare
+	err = host.Properties.LockForRead(hostproperty.MountsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostMountsV1 := clonable.(*propsv1.HostMounts)
+			for _, i := range hostMountsV1.RemoteMountsByPath {
+				// Gets share data
+				_, share, _, err := shareHandler.Inspect(ctx, i.ShareID)
+				if err != nil {
+					return err
+				}
+				if share == nil {
+					return abstract.ResourceNotFoundError("share", i.ShareID)
+				}
+				mounts = append(mounts, share)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Unmounts tier shares mounted on host (done outside the previous host.Properties.Reading() section, because
+	// Unmount() have to lock for write, and won't succeed while host.Properties.Reading() is running,
+	// leading to a deadlock)
+	for _, share := range mounts {
+		err = shareHandler.Unmount(ctx, share.Name, host.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// if host exports shares, delete them
+	for _, share := range shares {
+		err = shareHandler.Delete(ctx, share.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update networks property prosv1.NetworkHosts to remove the reference to the host
+	netHandler := NewNetworkHandler(handler.service)
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			for k := range hostNetworkV1.NetworksByID {
+				network, err := netHandler.Inspect(ctx, k)
+				if err != nil {
+					logrus.Errorf(err.Error())
+					continue
+				}
+				err = network.Properties.LockForWrite(networkproperty.HostsV1).ThenUse(
+					func(clonable data.Clonable) error {
+						networkHostsV1 := clonable.(*propsv1.NetworkHosts)
+						delete(networkHostsV1.ByID, host.ID)
+						delete(networkHostsV1.ByName, host.Name)
+						return nil
+					},
+				)
+				if err != nil {
+					logrus.Errorf(err.Error())
+				}
+				_, err = metadata.SaveNetwork(handler.service, network)
+				if err != nil {
+					logrus.Errorf(err.Error())
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Conditions are met, delete host
+	var (
+		deleteMetadataOnly bool
+		moreTimeNeeded     bool
+	)
+	retryErr := retryOnCommunicationFailure(
+		func() error {
+			if host != nil {
+				return handler.service.DeleteHost(host.ID)
+			}
+			return nil
+		},
+		0,
+	)
+	if retryErr != nil {
+		switch retryErr.(type) {
+		case fail.ErrNotFound:
+			deleteMetadataOnly = true
+		case fail.ErrTimeout:
+			moreTimeNeeded = true
+		default:
+			return retryErr
+		}
+	}
+
+	// Metadata are removed only once the provider reports the host as gone; this is the only wait done here,
+	// stacks are expected to wait for the host to disappear before returning from DeleteHost
+	if !deleteMetadataOnly {
+		timeout := temporal.GetDefaultDelay()
+		if moreTimeNeeded {
+			timeout = temporal.GetHostCleanupTimeout()
+		}
+		err = WaitHostDeleted(ctx, handler.service, host.ID, timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = mh.Delete()
+	if err != nil {
+		return err
+	}
+	handler.notifyHostState(host, host.LastState, hoststate.TERMINATED)
+
+	if deleteMetadataOnly {
+		return fail.Errorf(
+			fmt.Sprintf("unable to find the host even if it is described by metadata. Dirty metadata have been deleted"),
+			nil,
+		)
+	}
+
+	select {
+	case <-ctx.Done():
+		logrus.Warnf("Host delete cancelled by safescale")
+		var hostBis *abstract.Host
+		err2 := host.Properties.LockForRead(hostproperty.SizingV1).ThenUse(
+			func(clonable data.Clonable) error {
+				hostSizingV1 := clonable.(*propsv1.HostSizing)
+				return host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
+					func(clonable data.Clonable) error {
+						hostDescriptionV1 := clonable.(*propsv1.HostDescription)
+						return host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+							func(clonable data.Clonable) error {
+								hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+								// FIXME: host's os name is not stored in metadata so we used ubuntu 18.04 by default
+								var err3 error
+								sizing := abstract.SizingRequirements{
+									MinCores:    hostSizingV1.AllocatedSize.Cores,
+									MaxCores:    hostSizingV1.AllocatedSize.Cores,
+									MinFreq:     hostSizingV1.AllocatedSize.CPUFreq,
+									MinGPU:      hostSizingV1.AllocatedSize.GPUNumber,
+									MinRAMSize:  hostSizingV1.AllocatedSize.RAMSize,
+									MaxRAMSize:  hostSizingV1.AllocatedSize.RAMSize,
+									MinDiskSize: hostSizingV1.AllocatedSize.DiskSize,
+								}
+								hostBis, err3 = handler.Create(
+									context.Background(), HostCreateRequest{
+										Name:           host.Name,
+										Networks:       hostNetworkV1.DefaultNetworkID,
+										OS:             "ubuntu 18.04",
+										Public:         (len(hostNetworkV1.PublicIPv4) + len(hostNetworkV1.PublicIPv6)) != 0,
+										Sizing:         &sizing,
+										Force:          true,
+										Domain:         hostDescriptionV1.Domain,
+										Spot:           hostDescriptionV1.Spot,
+										ShieldedVM:     hostDescriptionV1.ShieldedVM,
+										ConfidentialVM: hostDescriptionV1.ConfidentialVM,
+										Tags:           hostDescriptionV1.Tags,
+										AffinityGroup:  hostDescriptionV1.AffinityGroup,
+										AntiAffinity:   hostDescriptionV1.AntiAffinity,
+									},
+								)
+								if err3 != nil {
+									return fail.Errorf(
+										fmt.Sprintf("failed to stop host deletion : %s", err3.Error()), err3,
+									)
+								}
+								return nil
+							},
+						)
+					},
+				)
+			},
+		)
+		if err2 != nil {
+			return fail.Errorf(fmt.Sprintf("failed to cancel host deletion : %s", err2.Error()), err2)
+		}
+
+		buf, err2 := hostBis.Serialize()
+		if err2 != nil {
+			return fail.Errorf(fmt.Sprintf("deleted Host recreated by safescale"), err2)
+		}
+		return fail.Errorf(fmt.Sprintf("deleted Host recreated by safescale : %s", buf), nil)
+
+	default:
+	}
+
+	return nil
+}
+
+// SSH returns ssh parameters to access the host referenced by ref
+func (handler *HostHandler) SSH(ctx context.Context, ref string) (sshConfig *system.SSHConfig, err error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	sshHandler := NewSSHHandler(handler.service)
+	sshConfig, err = sshHandler.GetConfig(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return sshConfig, nil
+}
+
+// ChangePassword sets the password of the operator user on the host, and stores it in host metadata
+// If newPassword is empty, a random password is generated.
+func (handler *HostHandler) ChangePassword(ctx context.Context, ref string, newPassword string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', <password>)", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	if newPassword == "" {
+		newPassword, err = utils.GeneratePassword(16)
+		if err != nil {
+			return fail.Wrap(err, "failed to generate password")
+		}
+	} else if err = utils.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		return err
+	}
+	if mh == nil {
+		return abstract.ResourceNotFoundError("host", ref)
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return err
+	}
+
+	sshConfig, err := NewSSHHandler(handler.service).GetConfig(ctx, host)
+	if err != nil {
+		return err
+	}
+	return changeHostPassword(
+		host, newPassword,
+		func(password string) error {
+			return setUserPassword(ctx, sshConfig, password)
+		},
+		func(host *abstract.Host) error {
+			_, innerErr := metadata.SaveHost(handler.service, host)
+			return innerErr
+		},
+	)
+}
+
+// changeHostPassword sets 'password' on the host with 'set', then records it in host metadata with 'save'; the
+// password recorded is left untouched if it cannot be set on the host
+func changeHostPassword(
+	host *abstract.Host, password string, set func(string) error, save func(*abstract.Host) error,
+) error {
+	if err := set(password); err != nil {
+		return fail.Wrap(err, fmt.Sprintf("failed to change password on host '%s'", host.Name))
+	}
+	host.Password = password
+	if err := save(host); err != nil {
+		return fail.Wrap(err, fmt.Sprintf("password changed on host '%s' but failed to update metadata", host.Name))
+	}
+	return nil
+}
+
+// setUserPassword sets the password of the SSH user of the host with chpasswd; the password is sent on the standard
+// input of the command, so it appears neither on a command line nor in the logs
+func setUserPassword(ctx context.Context, sshConfig *system.SSHConfig, password string) error {
+	var (
+		retcode int
+		stderr  string
+	)
+	err := retryOnCommunicationFailure(
+		func() error {
+			sshCmd, innerErr := sshConfig.CommandWithInput(
+				"sudo chpasswd", strings.NewReader(sshConfig.User+":"+password+"\n"),
+			)
+			if innerErr != nil {
+				return innerErr
+			}
+			retcode, _, stderr, innerErr = sshCmd.RunWithTimeout(
+				nil, outputs.COLLECT, temporal.EffectiveTimeout(ctx, temporal.GetExecutionTimeout()),
+			)
+			return innerErr
+		},
+		temporal.EffectiveTimeout(ctx, temporal.GetHostTimeout()),
+	)
+	if err != nil {
+		return err
+	}
+	if retcode != 0 {
+		return fail.Errorf(fmt.Sprintf("chpasswd failed: retcode=%d, %s", retcode, stderr), nil)
+	}
+	return nil
+}
+
+// SetHostname sets the hostname of the host on the system (using hostnamectl), updates /etc/hosts accordingly and
+// records it in host property SystemV1
+// The name of the host in SafeScale does not change.
+func (handler *HostHandler) SetHostname(ctx context.Context, ref string, hostname string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, hostname), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	if err = utils.ValidateHostname(hostname); err != nil {
+		return err
+	}
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		return err
+	}
+	if mh == nil {
+		return abstract.ResourceNotFoundError("host", ref)
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return err
+	}
+
+	// Follows the rules used by userdata to fill /etc/hosts: FQDN first then short hostname, after the private IP
+	entry := hostname
+	if shortHostname := utils.ShortHostname(hostname); shortHostname != hostname {
+		entry += " " + shortHostname
+	}
+	ip := host.GetPrivateIP()
+	cmd := fmt.Sprintf(
+		"sudo hostnamectl set-hostname %s && sudo sed -i -nr '/^127.0.1.1/!p' /etc/hosts && "+
+			"sudo sed -i -nr '/^%s\\s/!p;$a%s\\t%s' /etc/hosts",
+		hostname, strings.ReplaceAll(ip, ".", "\\."), ip, entry,
+	)
+	sshHandler := NewSSHHandler(handler.service)
+	retcode, _, stderr, err := sshHandler.Run(ctx, host.Name, cmd, outputs.COLLECT)
+	if err != nil {
+		return err
+	}
+	if retcode != 0 {
+		return fail.Errorf(
+			fmt.Sprintf("failed to set hostname of host '%s': retcode=%d, %s", host.Name, retcode, stderr), nil,
+		)
+	}
+
+	err = host.Properties.LockForWrite(hostproperty.SystemV1).ThenUse(
+		func(clonable data.Clonable) error {
+			clonable.(*propsv1.HostSystem).HostName = hostname
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = metadata.SaveHost(handler.service, host)
+	return err
+}
+
+// diskUsageCommand reports space then inode usage of the mounted filesystems, separated by a line containing only '%%'
+const diskUsageCommand = "LC_ALL=C df -PTB1 && echo '%%' && LC_ALL=C df -PTi"
+
+// pseudoFilesystems lists the filesystem types not backed by storage, ignored by DiskUsage
+var pseudoFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "cgroup": true, "cgroup2": true, "configfs": true, "debugfs": true,
+	"devpts": true, "devtmpfs": true, "fusectl": true, "hugetlbfs": true, "mqueue": true, "nsfs": true,
+	"overlay": true, "proc": true, "pstore": true, "ramfs": true, "securityfs": true, "squashfs": true,
+	"sysfs": true, "tmpfs": true, "tracefs": true,
+}
+
+// DiskUsage returns the space and inode usage of the filesystems mounted on the host, pseudo-filesystems excluded
+func (handler *HostHandler) DiskUsage(ctx context.Context, ref string) (list []*abstract.FilesystemUsage, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	retcode, stdout, stderr, err := sshHandler.Run(ctx, host.Name, diskUsageCommand, outputs.COLLECT)
+	if err != nil {
+		return nil, err
+	}
+	if retcode != 0 {
+		return nil, fail.Errorf(
+			fmt.Sprintf("failed to get disk usage of host '%s': retcode=%d, %s", host.Name, retcode, stderr), nil,
+		)
+	}
+	list, err = parseDiskUsage(stdout)
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to get disk usage of host '%s'", host.Name), err)
+	}
+	return list, nil
+}
+
+// parseDiskUsage parses the output of diskUsageCommand
+func parseDiskUsage(out string) ([]*abstract.FilesystemUsage, error) {
+	parts := strings.Split(out, "\n%%\n")
+	if len(parts) != 2 {
+		return nil, fail.InconsistentError("unexpected output of df: missing separator between space and inode usages")
+	}
+
+	var list []*abstract.FilesystemUsage
+	byMountPoint := map[string]*abstract.FilesystemUsage{}
+	err := parseDfOutput(parts[0], func(device, fsType, mountPoint string, total, used, free uint64) {
+		fsu := &abstract.FilesystemUsage{
+			Device:     device,
+			Type:       fsType,
+			MountPoint: mountPoint,
+			Size:       total,
+			Used:       used,
+			Available:  free,
+		}
+		list = append(list, fsu)
+		byMountPoint[mountPoint] = fsu
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = parseDfOutput(parts[1], func(_, _, mountPoint string, total, used, free uint64) {
+		if fsu, ok := byMountPoint[mountPoint]; ok {
+			fsu.Inodes, fsu.InodesUsed, fsu.InodesFree = total, used, free
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseDfOutput calls fn for each filesystem listed in the output of 'df -PT', skipping the header and the
+// pseudo-filesystems
+func parseDfOutput(out string, fn func(device, fsType, mountPoint string, total, used, free uint64)) error {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "Filesystem") {
+		return fail.InconsistentError("unexpected output of df: missing header")
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Filesystem Type Total Used Available Capacity Mounted-on (which may contain spaces)
+		if len(fields) < 7 {
+			return fail.InconsistentError(fmt.Sprintf("unexpected output of df: '%s'", line))
+		}
+		if pseudoFilesystems[fields[1]] {
+			continue
+		}
+		var values [3]uint64
+		for i, f := range fields[2:5] {
+			// Filesystems without inodes (vfat, ...) report '-'
+			if f == "-" {
+				continue
+			}
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				return fail.InconsistentError(fmt.Sprintf("unexpected output of df: '%s'", line))
+			}
+			values[i] = v
+		}
+		fn(fields[0], fields[1], strings.Join(fields[6:], " "), values[0], values[1], values[2])
+	}
+	return nil
+}
+
+// metricsCommand reports uptime, load, memory and CPU count of the host, separated by lines containing only '%%'
+// Each part falls back on /proc when the usual command is missing (minimal images)
+const metricsCommand = "cat /proc/uptime; echo '%%'; cat /proc/loadavg; echo '%%'; " +
+	"LC_ALL=C free -b 2>/dev/null || cat /proc/meminfo; echo '%%'; " +
+	"nproc 2>/dev/null || getconf _NPROCESSORS_ONLN 2>/dev/null || grep -c ^processor /proc/cpuinfo"
+
+// GetMetrics returns the uptime, load, memory and CPU count of the host, read through SSH
+// The metrics that cannot be read are listed in the Unavailable field of the result instead of failing the whole call
+func (handler *HostHandler) GetMetrics(ctx context.Context, ref string) (metrics *abstract.HostMetrics, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// The exit code is the one of the last command, the output of each part tells what succeeded
+	sshHandler := NewSSHHandler(handler.service)
+	_, stdout, stderr, err := sshHandler.RunWithTimeout(
+		ctx, host.Name, metricsCommand, outputs.COLLECT, temporal.GetConnectionTimeout(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	metrics = parseHostMetrics(stdout)
+	if len(metrics.Unavailable) == 4 {
+		return nil, fail.Errorf(fmt.Sprintf("failed to get metrics of host '%s': %s", host.Name, stderr), nil)
+	}
+	if len(metrics.Unavailable) > 0 {
+		logrus.Warnf("some metrics of host '%s' are unavailable: %s", host.Name, strings.Join(metrics.Unavailable, ", "))
+	}
+	return metrics, nil
+}
+
+// parseHostMetrics parses the output of metricsCommand; the parts that cannot be parsed are listed in Unavailable
+func parseHostMetrics(out string) *abstract.HostMetrics {
+	var parts [4]string
+	i := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "%%" {
+			i++
+			if i >= len(parts) {
+				break
+			}
+			continue
+		}
+		parts[i] += line + "\n"
+	}
+
+	metrics := &abstract.HostMetrics{}
+	if err := parseUptime(parts[0], metrics); err != nil {
+		metrics.Unavailable = append(metrics.Unavailable, "uptime")
+	}
+	if err := parseLoadAverage(parts[1], metrics); err != nil {
+		metrics.Unavailable = append(metrics.Unavailable, "load")
+	}
+	if err := parseMemory(parts[2], metrics); err != nil {
+		metrics.Unavailable = append(metrics.Unavailable, "memory")
+	}
+	cpus, err := strconv.Atoi(strings.TrimSpace(parts[3]))
+	if err != nil || cpus <= 0 {
+		metrics.Unavailable = append(metrics.Unavailable, "cpu")
+	} else {
+		metrics.CPUCount = cpus
+	}
+	return metrics
+}
+
+// parseUptime parses the content of /proc/uptime ("<seconds since boot> <idle seconds>")
+func parseUptime(out string, metrics *abstract.HostMetrics) error {
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return fail.InconsistentError("empty uptime")
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return err
+	}
+	metrics.Uptime = time.Duration(seconds * float64(time.Second)).Truncate(time.Second)
+	return nil
+}
+
+// parseLoadAverage parses the content of /proc/loadavg ("<1 min> <5 min> <15 min> <running>/<total> <last pid>")
+func parseLoadAverage(out string, metrics *abstract.HostMetrics) error {
+	fields := strings.Fields(out)
+	if len(fields) < 3 {
+		return fail.InconsistentError(fmt.Sprintf("unexpected load average '%s'", strings.TrimSpace(out)))
+	}
+	var loads [3]float64
+	for i := range loads {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return err
+		}
+		loads[i] = v
+	}
+	metrics.Load1, metrics.Load5, metrics.Load15 = loads[0], loads[1], loads[2]
+	return nil
+}
+
+// parseMemory parses the output of 'free -b' or, if free is missing, the content of /proc/meminfo (values in kB)
+func parseMemory(out string, metrics *abstract.HostMetrics) error {
+	meminfo := map[string]uint64{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// free: "Mem: <total> <used> <free> ..." (the other columns depend on the version of procps)
+		if fields[0] == "Mem:" {
+			if len(fields) < 4 {
+				return fail.InconsistentError(fmt.Sprintf("unexpected output of free: '%s'", line))
+			}
+			var values [3]uint64
+			for i, f := range fields[1:4] {
+				v, err := strconv.ParseUint(f, 10, 64)
+				if err != nil {
+					return err
+				}
+				values[i] = v
+			}
+			metrics.MemoryTotal, metrics.MemoryUsed, metrics.MemoryFree = values[0], values[1], values[2]
+			return nil
+		}
+		// meminfo: "<Key>: <value> kB"
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			meminfo[strings.TrimSuffix(fields[0], ":")] = v * 1024
+		}
+	}
+	total, ok := meminfo["MemTotal"]
+	if !ok {
+		return fail.InconsistentError("no memory information found")
+	}
+	free := meminfo["MemFree"]
+	used := total - free
+	if cache := meminfo["Buffers"] + meminfo["Cached"]; cache < used {
+		used -= cache
+	}
+	metrics.MemoryTotal, metrics.MemoryUsed, metrics.MemoryFree = total, used, free
+	return nil
+}
+
+// listeningPortsCommand lists the listening sockets of the host with ss, or with netstat when ss is missing, after a
+// line naming the tool used; the processes of other users are only visible to root, so sudo is tried first
+// Exits with 127 if neither tool is installed
+const listeningPortsCommand = "if command -v ss >/dev/null 2>&1; then echo ss; " +
+	"sudo -n ss -tulpn 2>/dev/null || ss -tulpn; " +
+	"elif command -v netstat >/dev/null 2>&1; then echo netstat; " +
+	"sudo -n netstat -tulpn 2>/dev/null || netstat -tulpn; " +
+	"else exit 127; fi"
+
+// GetListeningPorts returns the TCP and UDP ports on which the host is listening, read through SSH
+// The process owning a port is left empty when it cannot be read (not enough rights on the host).
+func (handler *HostHandler) GetListeningPorts(ctx context.Context, ref string) (ports []abstract.ListeningPort, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	retcode, stdout, stderr, err := sshHandler.Run(ctx, host.Name, listeningPortsCommand, outputs.COLLECT)
+	if err != nil {
+		return nil, err
+	}
+	switch retcode {
+	case 0:
+	case 127:
+		return nil, fail.NotAvailableError(
+			fmt.Sprintf("cannot list listening ports of host '%s': neither ss nor netstat is installed", host.Name),
+		)
+	default:
+		return nil, fail.Errorf(
+			fmt.Sprintf("failed to list listening ports of host '%s': retcode=%d, %s", host.Name, retcode, stderr), nil,
+		)
+	}
+	ports, err = parseListeningPorts(stdout)
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to list listening ports of host '%s'", host.Name), err)
+	}
+	return ports, nil
+}
+
+// ProcessListOptions tells how to sort and cut the list of processes returned by GetProcessList
+type ProcessListOptions struct {
+	SortBy string // "cpu" (default) or "memory", in decreasing order
+	Limit  int    // maximum number of processes returned; 0 returns all of them
+}
+
+// processListCommand lists the processes without truncating the lines nor the user names
+const processListCommand = "ps -ww -eo pid,ppid,user:64,pcpu,pmem,comm"
+
+// GetProcessList returns the processes running on the host, read through SSH with a short timeout, sorted and cut
+// according to opts
+// Lines of the output of ps that cannot be parsed are ignored; an error is returned only if none can be.
+func (handler *HostHandler) GetProcessList(ctx context.Context, ref string, opts ProcessListOptions) (list []abstract.ProcessInfo, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if opts.SortBy != "" && opts.SortBy != "cpu" && opts.SortBy != "memory" {
+		return nil, fail.InvalidParameterError("opts.SortBy", "must be 'cpu' or 'memory'")
+	}
+	if opts.Limit < 0 {
+		return nil, fail.InvalidParameterError("opts.Limit", "cannot be negative")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s', %d)", ref, opts.SortBy, opts.Limit), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	retcode, stdout, stderr, err := sshHandler.RunWithTimeout(
+		ctx, host.Name, processListCommand, outputs.COLLECT, 30*time.Second,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if retcode != 0 {
+		return nil, fail.Errorf(
+			fmt.Sprintf("failed to list processes of host '%s': retcode=%d, %s", host.Name, retcode, stderr), nil,
+		)
+	}
+	list, err = parseProcessList(stdout)
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to list processes of host '%s'", host.Name), err)
+	}
+	return sortProcessList(list, opts), nil
+}
+
+// parseProcessList parses the output of processListCommand; the lines that cannot be parsed are skipped, and an
+// error is returned only if no line can be parsed
+func parseProcessList(out string) ([]abstract.ProcessInfo, error) {
+	var (
+		list    []abstract.ProcessInfo
+		skipped []string
+	)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "PID" {
+			continue
+		}
+		if len(fields) < 6 {
+			skipped = append(skipped, line)
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		cpu, err3 := strconv.ParseFloat(fields[3], 64)
+		mem, err4 := strconv.ParseFloat(fields[4], 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			skipped = append(skipped, line)
+			continue
+		}
+		list = append(list, abstract.ProcessInfo{
+			PID:    pid,
+			PPID:   ppid,
+			User:   fields[2],
+			CPU:    cpu,
+			Memory: mem,
+			// the name of the command may contain spaces
+			Command: strings.Join(fields[5:], " "),
+		})
+	}
+	if len(skipped) > 0 {
+		if len(list) == 0 {
+			return nil, fail.SyntaxError(fmt.Sprintf("unexpected output of ps: '%s'", strings.Join(skipped, "', '")))
+		}
+		logrus.Warnf("ignored %d unexpected lines in the output of ps: '%s'", len(skipped), strings.Join(skipped, "', '"))
+	}
+	return list, nil
+}
+
+// sortProcessList sorts the processes by decreasing CPU or memory usage, then by PID, and keeps the first ones
+// according to opts
+func sortProcessList(list []abstract.ProcessInfo, opts ProcessListOptions) []abstract.ProcessInfo {
+	usage := func(p abstract.ProcessInfo) float64 {
+		if opts.SortBy == "memory" {
+			return p.Memory
+		}
+		return p.CPU
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if usage(list[i]) != usage(list[j]) {
+			return usage(list[i]) > usage(list[j])
+		}
+		return list[i].PID < list[j].PID
+	})
+	if opts.Limit > 0 && len(list) > opts.Limit {
+		list = list[:opts.Limit]
+	}
+	return list
+}
+
+// connectivityCheckTimeout is the time given to each target to answer
+const connectivityCheckTimeout = 5
+
+// connectivityScriptHeader defines the shell functions checking a TCP target (with nc, or with bash /dev/tcp when nc
+// is missing) and an URL (with curl, or with wget when curl is missing); each prints a line
+// "<index> <retcode> <latency in ms> <error message>"
+var connectivityScriptHeader = fmt.Sprintf(`now() { date +%%s%%N; }
+report() { echo "$1 $2 $(( ($(now) - $3) / 1000000 )) $(echo "$4" | tr '\n' ' ')"; }
+check_tcp() {
+    t0=$(now)
+    if command -v nc >/dev/null 2>&1; then
+        out=$(nc -z -w %[1]d "$2" "$3" 2>&1); rc=$?
+    else
+        out=$(timeout %[1]d bash -c "cat </dev/null >/dev/tcp/$2/$3" 2>&1); rc=$?
+    fi
+    report "$1" "$rc" "$t0" "$out"
+}
+check_url() {
+    t0=$(now)
+    if command -v curl >/dev/null 2>&1; then
+        out=$(curl -sS -o /dev/null --max-time %[1]d "$2" 2>&1); rc=$?
+    elif command -v wget >/dev/null 2>&1; then
+        out=$(wget -q -O /dev/null -T %[1]d "$2" 2>&1); rc=$?
+    else
+        out="neither curl nor wget is installed"; rc=127
+    fi
+    report "$1" "$rc" "$t0" "$out"
+}
+`, connectivityCheckTimeout)
+
+// connTargetHostPattern restricts the host part of a TCP target to names and IP addresses
+var connTargetHostPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// ParseConnTarget parses a connectivity target given as "host:port" or as an URL ("http://...", "https://...")
+func ParseConnTarget(in string) (abstract.ConnTarget, error) {
+	in = strings.TrimSpace(in)
+	if strings.Contains(in, "://") {
+		u, err := url.Parse(in)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return abstract.ConnTarget{}, fail.InvalidParameterError("target", fmt.Sprintf("'%s' is not a valid http(s) URL", in))
+		}
+		return abstract.ConnTarget{URL: in}, nil
+	}
+
+	host, port, err := net.SplitHostPort(in)
+	if err != nil {
+		return abstract.ConnTarget{}, fail.InvalidParameterError("target", fmt.Sprintf("'%s' is not in the form host:port", in))
+	}
+	target := abstract.ConnTarget{Host: host}
+	target.Port, err = strconv.Atoi(port)
+	if err != nil || target.Port <= 0 || target.Port > 65535 {
+		return abstract.ConnTarget{}, fail.InvalidParameterError("target", fmt.Sprintf("'%s' is not a valid port", port))
+	}
+	if !connTargetHostPattern.MatchString(host) {
+		return abstract.ConnTarget{}, fail.InvalidParameterError("target", fmt.Sprintf("'%s' is not a valid host", host))
+	}
+	return target, nil
+}
+
+// connectivityCommand returns the script checking all the targets in parallel; 'addresses' contains the address to
+// use for the host of each TCP target
+func connectivityCommand(targets []abstract.ConnTarget, addresses []string) string {
+	var b strings.Builder
+	b.WriteString(connectivityScriptHeader)
+	for i, t := range targets {
+		if t.URL != "" {
+			fmt.Fprintf(&b, "check_url %d %s &\n", i, system.ShellQuote(t.URL))
+		} else {
+			fmt.Fprintf(&b, "check_tcp %d %s %d &\n", i, system.ShellQuote(addresses[i]), t.Port)
+		}
+	}
+	b.WriteString("wait\n")
+	return b.String()
+}
+
+// parseConnectivityResults parses the output of connectivityCommand; targets without result line (the check having
+// been killed for instance) are reported as failed
+func parseConnectivityResults(targets []abstract.ConnTarget, out string) []abstract.ConnResult {
+	results := make([]abstract.ConnResult, len(targets))
+	for i, t := range targets {
+		results[i] = abstract.ConnResult{Target: t, Error: "no result returned by the check"}
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil || index < 0 || index >= len(targets) {
+			continue
+		}
+		retcode, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		latency, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		r := &results[index]
+		r.Latency = time.Duration(latency) * time.Millisecond
+		r.Success = retcode == 0
+		r.Error = ""
+		if !r.Success {
+			if len(fields) == 4 && strings.TrimSpace(fields[3]) != "" {
+				r.Error = strings.TrimSpace(fields[3])
+			} else {
+				r.Error = fmt.Sprintf("connection failed (retcode=%d)", retcode)
+			}
+		}
+	}
+	return results
+}
+
+// CheckConnectivity checks from the host 'ref' the reachability of each target, with nc (TCP) or curl (URL) run
+// through SSH, to diagnose security group or routing issues from the point of view of the host
+// A TCP target whose host is the name of a SafeScale host is checked on the private IP of this host. An URL is
+// reachable as soon as an HTTP response is received, whatever its status.
+// Each target gets its own result, the failure of a check not preventing the other ones.
+func (handler *HostHandler) CheckConnectivity(ctx context.Context, ref string, targets []abstract.ConnTarget) (results []abstract.ConnResult, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if len(targets) == 0 {
+		return nil, fail.InvalidParameterError("targets", "cannot be empty")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, targets), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	addresses := make([]string, len(targets))
+	for i, t := range targets {
+		if t.URL != "" {
+			continue
+		}
+		if t.Port <= 0 || t.Port > 65535 || !connTargetHostPattern.MatchString(t.Host) {
+			return nil, fail.InvalidParameterError("targets", fmt.Sprintf("'%s' is not a valid target", t.String()))
+		}
+		addresses[i] = t.Host
+		if net.ParseIP(t.Host) == nil {
+			if target, err := handler.loadHostMetadata(t.Host); err == nil && target.GetPrivateIP() != "" {
+				addresses[i] = target.GetPrivateIP()
+			}
+		}
+	}
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	_, stdout, _, err := sshHandler.Run(ctx, host.Name, connectivityCommand(targets, addresses), outputs.COLLECT)
+	if err != nil {
+		return nil, err
+	}
+	return parseConnectivityResults(targets, stdout), nil
+}
+
+// parseListeningPorts parses the output of listeningPortsCommand, returning the ports sorted by protocol, port and
+// address, without duplicates (sockets shared by several processes are listed once per process by the tools)
+func parseListeningPorts(out string) ([]abstract.ListeningPort, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var parse func([]string) (abstract.ListeningPort, bool)
+	switch strings.TrimSpace(lines[0]) {
+	case "ss":
+		parse = parseSSListeningPort
+	case "netstat":
+		parse = parseNetstatListeningPort
+	default:
+		return nil, fail.InconsistentError(fmt.Sprintf("unexpected output: '%s'", lines[0]))
+	}
+
+	seen := map[abstract.ListeningPort]bool{}
+	var ports []abstract.ListeningPort
+	for _, line := range lines[1:] {
+		p, ok := parse(strings.Fields(line))
+		if !ok || seen[p] {
+			continue
+		}
+		seen[p] = true
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		if ports[i].Address != ports[j].Address {
+			return ports[i].Address < ports[j].Address
+		}
+		return ports[i].Process < ports[j].Process
+	})
+	return ports, nil
+}
+
+// ssProcessNamePattern extracts the process names of the 'users:(("name",pid=...,fd=...),...)' column of ss
+var ssProcessNamePattern = regexp.MustCompile(`\("([^"]*)",`)
+
+// parseSSListeningPort parses a line of 'ss -tulpn':
+// "<netid> <state> <recv-q> <send-q> <local address:port> <peer address:port> [users:((...))]"
+func parseSSListeningPort(fields []string) (abstract.ListeningPort, bool) {
+	if len(fields) < 6 || (fields[0] != "tcp" && fields[0] != "udp") {
+		return abstract.ListeningPort{}, false
+	}
+	address, port, ok := splitListeningAddress(fields[4])
+	if !ok {
+		return abstract.ListeningPort{}, false
+	}
+	var names []string
+	known := map[string]bool{}
+	for _, m := range ssProcessNamePattern.FindAllStringSubmatch(strings.Join(fields[6:], " "), -1) {
+		if !known[m[1]] {
+			known[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return abstract.ListeningPort{
+		Protocol: fields[0],
+		Address:  address,
+		Port:     port,
+		Process:  strings.Join(names, ","),
+	}, true
+}
+
+// parseNetstatListeningPort parses a line of 'netstat -tulpn':
+// "<proto> <recv-q> <send-q> <local address:port> <foreign address:port> [<state>] <pid/program name|->"
+func parseNetstatListeningPort(fields []string) (abstract.ListeningPort, bool) {
+	if len(fields) < 5 {
+		return abstract.ListeningPort{}, false
+	}
+	protocol := strings.TrimSuffix(fields[0], "6")
+	if protocol != "tcp" && protocol != "udp" {
+		return abstract.ListeningPort{}, false
+	}
+	address, port, ok := splitListeningAddress(fields[3])
+	if !ok {
+		return abstract.ListeningPort{}, false
+	}
+	var process string
+	for i, f := range fields[5:] {
+		if pos := strings.Index(f, "/"); pos > 0 {
+			if _, err := strconv.Atoi(f[:pos]); err == nil {
+				process = strings.Join(append([]string{f[pos+1:]}, fields[5+i+1:]...), " ")
+				break
+			}
+		}
+	}
+	return abstract.ListeningPort{
+		Protocol: protocol,
+		Address:  address,
+		Port:     port,
+		Process:  process,
+	}, true
+}
+
+// splitListeningAddress splits a local socket address as written by ss or netstat ("0.0.0.0:22", "[::]:22", ":::22",
+// "*:22", "127.0.0.53%lo:53") into address, without brackets nor interface, and port
+func splitListeningAddress(in string) (string, int, bool) {
+	pos := strings.LastIndex(in, ":")
+	if pos < 0 {
+		return "", 0, false
+	}
+	port, err := strconv.Atoi(in[pos+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	address := strings.TrimSuffix(strings.TrimPrefix(in[:pos], "["), "]")
+	if i := strings.Index(address, "%"); i >= 0 {
+		address = address[:i]
+	}
+	if address == "" {
+		address = "*"
+	}
+	return address, port, true
+}
+
+// ignoreFrozenEnv is the environment variable allowing an administrator to mutate frozen hosts anyway
+const ignoreFrozenEnv = "SAFESCALE_IGNORE_FROZEN_HOSTS"
+
+// checkNotFrozen returns a fail.ErrNotAvailable if the host is frozen, unless the client asked to ignore it
+// (safescale --ignore-frozen) or the administrator of the daemon allowed it by setting SAFESCALE_IGNORE_FROZEN_HOSTS
+func checkNotFrozen(ctx context.Context, host *abstract.Host) error {
+	var freeze propsv1.HostFreeze
+	err := host.Properties.LockForRead(hostproperty.FreezeV1).ThenUse(
+		func(clonable data.Clonable) error {
+			freeze = *clonable.(*propsv1.HostFreeze)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if !freeze.Frozen {
+		return nil
+	}
+	if srvutils.IgnoreFrozen(ctx) {
+		logrus.Warnf(
+			"host '%s' is frozen (by %s at %s) but %s asked to ignore it, continuing", host.Name, freeze.FrozenBy,
+			freeze.FrozenAt.Format(time.RFC3339), currentCreator(),
+		)
+		return nil
+	}
+	if os.Getenv(ignoreFrozenEnv) != "" {
+		logrus.Warnf(
+			"host '%s' is frozen (by %s at %s) but %s is set, continuing", host.Name, freeze.FrozenBy,
+			freeze.FrozenAt.Format(time.RFC3339), ignoreFrozenEnv,
+		)
+		return nil
+	}
+	return fail.NotAvailableError(
+		fmt.Sprintf(
+			"host '%s' is frozen (by %s at %s), thaw it first", host.Name, freeze.FrozenBy,
+			freeze.FrozenAt.Format(time.RFC3339),
+		),
+	)
+}
+
+// Freeze protects the host against deletion, resize, stop, reboot and any other mutation until Thaw is called
+func (handler *HostHandler) Freeze(ctx context.Context, ref string) (err error) {
+	return handler.setFrozen(ctx, ref, true)
+}
+
+// Thaw removes the protection of the host set by Freeze
+func (handler *HostHandler) Thaw(ctx context.Context, ref string) (err error) {
+	return handler.setFrozen(ctx, ref, false)
+}
+
+// setFrozen records in metadata if the host is frozen, with who changed it and when
+func (handler *HostHandler) setFrozen(ctx context.Context, ref string, frozen bool) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, frozen), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return abstract.ResourceNotFoundError("host", ref)
+		}
+		return err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+
+	who := currentCreator()
+	err = host.Properties.LockForWrite(hostproperty.FreezeV1).ThenUse(
+		func(clonable data.Clonable) error {
+			freeze := clonable.(*propsv1.HostFreeze)
+			freeze.Frozen = frozen
+			if frozen {
+				freeze.FrozenBy, freeze.FrozenAt = who, time.Now()
+			} else {
+				freeze.ThawedBy, freeze.ThawedAt = who, time.Now()
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	err = mh.Write()
+	if err != nil {
+		return err
+	}
+
+	if frozen {
+		logrus.Infof("Host '%s' frozen by %s", host.Name, who)
+	} else {
+		logrus.Infof("Host '%s' thawed by %s", host.Name, who)
+	}
+	return nil
+}
+
+// ipForwardingConf is the sysctl configuration file persisting IP forwarding set by SetIPForwarding
+const ipForwardingConf = "/etc/sysctl.d/99-safescale-ip-forward.conf"
+
+// ipForwardingCommand returns the command enabling or disabling IPv4 forwarding on a host, at runtime and after reboot
+func ipForwardingCommand(enabled bool) string {
+	value := 0
+	if enabled {
+		value = 1
+	}
+	return fmt.Sprintf(
+		"sudo sysctl -w net.ipv4.ip_forward=%d && echo 'net.ipv4.ip_forward = %d' | sudo tee %s >/dev/null",
+		value, value, ipForwardingConf,
+	)
+}
+
+// checkIPForwardingChange returns fail.ErrInvalidRequest if IP forwarding cannot be set to 'enabled' on the host:
+// gateways route the traffic of their network, disabling it on them would cut the hosts of the network off
+func checkIPForwardingChange(host *abstract.Host, enabled bool) error {
+	if enabled {
+		return nil
+	}
+	return host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			if clonable.(*propsv1.HostNetwork).IsGateway {
+				return fail.InvalidRequestError(
+					fmt.Sprintf("cannot disable IP forwarding on host '%s': it is a gateway", host.Name),
 				)
+			}
+			return nil
+		},
+	)
+}
+
+// SetIPForwarding allows or forbids the host to forward traffic not addressed to it (to act as NAT or VPN host for
+// instance), on provider side and in the operating system of the host
+// Returns fail.ErrNotImplemented or fail.ErrNotAvailable if the provider cannot change it after host creation, and
+// fail.ErrInvalidRequest if asked to disable it on a gateway
+func (handler *HostHandler) SetIPForwarding(ctx context.Context, ref string, enabled bool) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, enabled), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return err
+	}
+	err = checkNotFrozen(ctx, host)
+	if err != nil {
+		return err
+	}
+	err = checkIPForwardingChange(host, enabled)
+	if err != nil {
+		return err
+	}
+
+	err = handler.service.SetHostIPForwarding(host, enabled)
+	if err != nil {
+		return err
+	}
+
+	retcode, _, stderr, err := NewSSHHandler(handler.service).Run(ctx, host.Name, ipForwardingCommand(enabled), outputs.COLLECT)
+	if err != nil {
+		return err
+	}
+	if retcode != 0 {
+		return fmt.Errorf("failed to set IP forwarding on host '%s' (retcode=%d): %s", host.Name, retcode, stderr)
+	}
+	return nil
+}
+
+// securityGroupsDiff returns the security groups to bind and the ones to unbind, sorted by name, so that the
+// security groups bound on request to the host are exactly desired
+// The security groups bound because of the network of the host are left as is, as the security group dedicated to
+// the host, which is not recorded in metadata.
+func securityGroupsDiff(current *propsv1.HostSecurityGroups, desired []string) (toBind []string, toUnbind []string) {
+	wanted := map[string]bool{}
+	for _, name := range desired {
+		if name == "" || wanted[name] {
+			continue
+		}
+		wanted[name] = true
+		if _, ok := current.ByName[name]; !ok {
+			toBind = append(toBind, name)
+		}
+	}
+	for name, sg := range current.ByName {
+		if !sg.FromNetwork && !wanted[name] {
+			toUnbind = append(toUnbind, name)
+		}
+	}
+	sort.Strings(toBind)
+	sort.Strings(toUnbind)
+	return toBind, toUnbind
+}
+
+// replaceSecurityGroupsTransactionally binds the security groups toBind, unbinds the ones toUnbind, then records the
+// result; if any of these steps fails, the binds and unbinds already done are undone in reverse order, and the
+// failures to undo them, if any, are added as consequences of the original error
+func replaceSecurityGroupsTransactionally(
+	toBind []string, toUnbind []string, bind func(string) error, unbind func(string) error, record func() error,
+) (err error) {
+	var undo []func() error
+	defer func() {
+		if err != nil {
+			for i := len(undo) - 1; i >= 0; i-- {
+				if derr := undo[i](); derr != nil {
+					logrus.Errorf("failed to roll back security groups replacement: %v", derr)
+					err = fail.AddConsequence(err, derr)
+				}
+			}
+		}
+	}()
+
+	for _, name := range toBind {
+		name := name
+		err = bind(name)
+		if err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return unbind(name) })
+	}
+	for _, name := range toUnbind {
+		name := name
+		err = unbind(name)
+		if err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return bind(name) })
+	}
+	return record()
+}
+
+// ReplaceSecurityGroups makes the security groups bound on request to the host exactly securityGroups (an empty list
+// unbinding them all), binding the missing ones and unbinding the others
+// The security groups bound because of the network of the host and the one dedicated to the host are preserved. If
+// any step fails, the host is left with the security groups it had.
+func (handler *HostHandler) ReplaceSecurityGroups(ctx context.Context, ref string, securityGroups []string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, securityGroups), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	if !handler.service.SupportsFeature(providers.SecurityGroups) {
+		return fail.NotAvailableError("the provider of the tenant does not support security groups")
+	}
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return abstract.ResourceNotFoundError("host", ref)
+		}
+		return err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return err
+	}
+
+	var toBind, toUnbind []string
+	err = host.Properties.LockForRead(hostproperty.SecurityGroupsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			toBind, toUnbind = securityGroupsDiff(clonable.(*propsv1.HostSecurityGroups), securityGroups)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(toBind) == 0 && len(toUnbind) == 0 {
+		logrus.Debugf("security groups of host '%s' already up to date", host.Name)
+		return nil
+	}
+
+	err = replaceSecurityGroupsTransactionally(
+		toBind, toUnbind,
+		func(name string) error { return handler.service.BindSecurityGroupToHost(host.ID, name) },
+		func(name string) error { return handler.service.UnbindSecurityGroupFromHost(host.ID, name) },
+		func() error {
+			innerErr := host.Properties.LockForWrite(hostproperty.SecurityGroupsV1).ThenUse(
+				func(clonable data.Clonable) error {
+					hostSecurityGroupsV1 := clonable.(*propsv1.HostSecurityGroups)
+					for _, name := range toUnbind {
+						delete(hostSecurityGroupsV1.ByName, name)
+					}
+					for _, name := range toBind {
+						hostSecurityGroupsV1.ByName[name] = &propsv1.HostSecurityGroup{Name: name, FromNetwork: false}
+					}
+					return nil
+				},
+			)
+			if innerErr != nil {
+				return innerErr
+			}
+			return mh.Write()
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Security groups of host '%s' replaced: bound %v, unbound %v", host.Name, toBind, toUnbind)
+	return nil
+}
+
+// kernelParametersConf is the sysctl configuration file persisting the parameters set by ApplyKernelParameters
+const kernelParametersConf = "/etc/sysctl.d/90-safescale.conf"
+
+// kernelParameterNameRE validates the names of kernel parameters, in dotted form ('/' standing for a dot in a
+// component, like in interface names)
+var kernelParameterNameRE = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*(\.[a-zA-Z0-9_/-]+)+$`)
+
+// validateKernelParameters checks the names and the values of the kernel parameters
+func validateKernelParameters(params map[string]string) error {
+	if len(params) == 0 {
+		return fail.InvalidParameterError("params", "cannot be empty")
+	}
+	for k, v := range params {
+		if !kernelParameterNameRE.MatchString(k) {
+			return fail.InvalidRequestError(fmt.Sprintf("invalid kernel parameter name '%s'", k))
+		}
+		if strings.TrimSpace(v) == "" || strings.ContainsAny(v, "\n\r") {
+			return fail.InvalidRequestError(fmt.Sprintf("invalid value '%s' for kernel parameter '%s'", v, k))
+		}
+	}
+	return nil
+}
+
+// sortedKernelParameterNames returns the names of the parameters in alphabetical order
+func sortedKernelParameterNames(params map[string]string) []string {
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// kernelParametersFileCommand returns the command writing 'params' in the sysctl configuration file of SafeScale
+func kernelParametersFileCommand(params map[string]string) string {
+	var content strings.Builder
+	content.WriteString("# Kernel parameters applied by SafeScale, do not edit\n")
+	for _, k := range sortedKernelParameterNames(params) {
+		content.WriteString(fmt.Sprintf("%s = %s\n", k, params[k]))
+	}
+	return fmt.Sprintf(
+		"sudo tee %s >/dev/null <<'SAFESCALE_SYSCTL' || exit 1\n%sSAFESCALE_SYSCTL\n", kernelParametersConf,
+		content.String(),
+	)
+}
+
+// applyKernelParametersCommand returns the command printing the current values of the parameters 'names', writing
+// 'params' in the sysctl configuration file, reloading it and printing the values of 'names' again, the two readings
+// being separated by a line '%%'
+func applyKernelParametersCommand(names []string, params map[string]string) string {
+	read := fmt.Sprintf("sudo sysctl -e %s 2>/dev/null\n", strings.Join(names, " "))
+	return read + "echo '%%'\n" + kernelParametersFileCommand(params) + "sudo sysctl --system >/dev/null 2>&1\n" + read
+}
+
+// parseSysctlValues parses the lines "<name> = <value>" printed by sysctl; the blanks inside values are normalized
+func parseSysctlValues(out string) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			continue
+		}
+		values[name] = strings.Join(strings.Fields(parts[1]), " ")
+	}
+	return values
+}
+
+// ApplyKernelParameters sets the kernel parameters (sysctl) 'params' on the host, at runtime and after reboot, and
+// records them in the metadata of the host with the ones applied before
+// Returns the values of the parameters before the change, to allow a rollback; the parameters that cannot be set
+// (read-only or unknown) are reported in the error and not kept, the others stay applied.
+func (handler *HostHandler) ApplyKernelParameters(ctx context.Context, ref string, params map[string]string) (previous map[string]string, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if err = validateKernelParameters(params); err != nil {
+		return nil, err
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, params), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mh, err := metadata.LoadHost(handler.service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil, abstract.ResourceNotFoundError("host", ref)
+		}
+		return nil, err
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return nil, err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return nil, err
+	}
+
+	applied := map[string]string{}
+	err = host.Properties.LockForRead(hostproperty.KernelParametersV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for k, v := range clonable.(*propsv1.HostKernelParameters).ByName {
+				applied[k] = v
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range params {
+		applied[k] = strings.Join(strings.Fields(v), " ")
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	names := sortedKernelParameterNames(params)
+	retcode, stdout, stderr, err := sshHandler.Run(
+		ctx, host.Name, applyKernelParametersCommand(names, applied), outputs.COLLECT,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if retcode != 0 {
+		return nil, fmt.Errorf(
+			"failed to apply kernel parameters on host '%s' (retcode=%d): %s", host.Name, retcode, stderr,
+		)
+	}
+	readings := strings.SplitN(stdout, "%%", 2)
+	previous = parseSysctlValues(readings[0])
+	current := map[string]string{}
+	if len(readings) == 2 {
+		current = parseSysctlValues(readings[1])
+	}
+
+	var failed []string
+	for _, k := range names {
+		if current[k] != applied[k] {
+			failed = append(failed, k)
+			delete(applied, k)
+		}
+	}
+	if len(failed) > 0 {
+		// keeps the configuration file in line with the parameters really applied
+		retcode, _, stderr, err = sshHandler.Run(ctx, host.Name, kernelParametersFileCommand(applied), outputs.COLLECT)
+		if err == nil && retcode != 0 {
+			err = fmt.Errorf("retcode=%d: %s", retcode, stderr)
+		}
+		if err != nil {
+			logrus.Warnf("failed to remove the kernel parameters not applied from host '%s': %v", host.Name, err)
+		}
+	}
+
+	who := currentCreator()
+	err = host.Properties.LockForWrite(hostproperty.KernelParametersV1).ThenUse(
+		func(clonable data.Clonable) error {
+			kernelParametersV1 := clonable.(*propsv1.HostKernelParameters)
+			kernelParametersV1.ByName = applied
+			kernelParametersV1.UpdatedBy, kernelParametersV1.UpdatedAt = who, time.Now()
+			return nil
+		},
+	)
+	if err != nil {
+		return previous, err
+	}
+	err = mh.Write()
+	if err != nil {
+		return previous, err
+	}
+
+	if len(failed) > 0 {
+		return previous, fmt.Errorf(
+			"failed to apply kernel parameters '%s' on host '%s' (read-only or unknown)", strings.Join(failed, "', '"),
+			host.Name,
+		)
+	}
+	return previous, nil
+}
+
+// ListKernelParameters returns the kernel parameters applied on the host by ApplyKernelParameters, as recorded in
+// its metadata
+func (handler *HostHandler) ListKernelParameters(ctx context.Context, ref string) (params map[string]string, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	params = map[string]string{}
+	err = host.Properties.LockForRead(hostproperty.KernelParametersV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for k, v := range clonable.(*propsv1.HostKernelParameters).ByName {
+				params[k] = v
+			}
+			return nil
+		},
+	)
+	return params, err
+}
+
+// WaitForCloudInitDone waits cloud-init has finished on the host; SSH is ready before, while cloud-init may still hold
+// the package manager locks
+// If timeout is 0, temporal.GetHostTimeout() is used; the timeout is capped by the deadline of ctx, if any.
+// Returns immediately if the host does not use cloud-init.
+func (handler *HostHandler) WaitForCloudInitDone(ctx context.Context, ref string, timeout time.Duration) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, timeout), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	if timeout <= 0 {
+		timeout = temporal.GetHostTimeout()
+	}
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return err
+	}
+	sshCfg, err := NewSSHHandler(handler.service).GetConfig(ctx, host.ID)
+	if err != nil {
+		return err
+	}
+	return sshCfg.WaitCloudInitDone(temporal.EffectiveTimeout(ctx, timeout))
+}
+
+// consoleOutputLines is the number of lines of console output reported when a host does not become ready
+const consoleOutputLines = 30
+
+// consoleOutputTail returns the last lines of the console output of the host, or an empty string if the provider
+// cannot give it
+func (handler *HostHandler) consoleOutputTail(hostID string) string {
+	output, err := handler.service.GetHostConsoleOutput(hostID, consoleOutputLines)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotImplemented); !ok {
+			logrus.Warnf("failed to get console output of host '%s': %v", hostID, err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// Console returns the last 'lines' lines (all if 0) of the console output of the host, available even if the host
+// is not reachable with SSH
+func (handler *HostHandler) Console(ctx context.Context, ref string, lines int) (output string, err error) {
+	if handler == nil {
+		return "", fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return "", fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return "", fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if lines < 0 {
+		return "", fail.InvalidParameterError("lines", "cannot be negative")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %d)", ref, lines), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return "", err
+	}
+	return handler.service.GetHostConsoleOutput(host.ID, lines)
+}
+
+// GetNetworkInterfaces returns the network interfaces of the host, the one used by the default route first (or in
+// the order set at creation)
+// The metadata of the host are completed with the details given by the provider (MAC addresses, names); if the
+// provider cannot give them, the interfaces are described from the metadata only.
+func (handler *HostHandler) GetNetworkInterfaces(ctx context.Context, ref string) (nics []abstract.HostNetworkInterface, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	fromProvider, err := handler.service.ListHostNetworkInterfaces(host.ID)
+	if err != nil {
+		switch err.(type) {
+		case fail.ErrNotImplemented:
+			logrus.Debugf("provider cannot list network interfaces of host '%s', using metadata only", host.Name)
+			fromProvider = nil
+		default:
+			return nil, err
+		}
+	}
+
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			return host.Properties.LockForRead(hostproperty.NICsV1).ThenUse(
+				func(clonable data.Clonable) error {
+					nics = describeNetworkInterfaces(hostNetworkV1, clonable.(*propsv1.HostNICs), fromProvider)
+					return nil
+				},
+			)
+		},
+	)
+	return nics, err
+}
+
+// describeNetworkInterfaces completes the network interfaces given by the provider (one per network of the host if
+// there are none) with the metadata of the host
+func describeNetworkInterfaces(
+	hostNetworkV1 *propsv1.HostNetwork, hostNICsV1 *propsv1.HostNICs, fromProvider []abstract.HostNetworkInterface,
+) []abstract.HostNetworkInterface {
+	nics := make([]abstract.HostNetworkInterface, 0, len(fromProvider))
+	if len(fromProvider) > 0 {
+		nics = append(nics, fromProvider...)
+	} else {
+		ids := map[string]bool{}
+		for id := range hostNetworkV1.NetworksByID {
+			ids[id] = true
+		}
+		for id := range hostNetworkV1.IPv4Addresses {
+			ids[id] = true
+		}
+		for id := range hostNetworkV1.IPv6Addresses {
+			ids[id] = true
+		}
+		for id := range ids {
+			nics = append(nics, abstract.HostNetworkInterface{NetworkID: id})
+		}
+	}
+
+	indexes := map[string]int{}
+	names := map[string]string{}
+	for _, v := range hostNICsV1.ByIndex {
+		indexes[v.NetworkID] = v.Index
+		names[v.NetworkID] = v.Name
+	}
+
+	publicIPFound := false
+	for i := range nics {
+		nic := &nics[i]
+		if name, ok := hostNetworkV1.NetworksByID[nic.NetworkID]; ok {
+			nic.NetworkName = name
+		}
+		if nic.Name == "" {
+			nic.Name = names[nic.NetworkID]
+		}
+		if len(nic.PrivateIPs) == 0 {
+			for _, ip := range []string{hostNetworkV1.IPv4Addresses[nic.NetworkID], hostNetworkV1.IPv6Addresses[nic.NetworkID]} {
+				if ip != "" {
+					nic.PrivateIPs = append(nic.PrivateIPs, ip)
+				}
+			}
+		}
+		nic.Default = len(nics) == 1 || nic.NetworkID == hostNetworkV1.DefaultNetworkID
+		if nic.PublicIP != "" {
+			publicIPFound = true
+		}
+	}
+
+	sort.SliceStable(nics, func(i, j int) bool {
+		if len(indexes) > 0 {
+			ii, iok := indexes[nics[i].NetworkID]
+			ij, jok := indexes[nics[j].NetworkID]
+			if iok != jok {
+				return iok
+			}
+			return ii < ij
+		}
+		if nics[i].Default != nics[j].Default {
+			return nics[i].Default
+		}
+		return len(fromProvider) == 0 && nics[i].NetworkID < nics[j].NetworkID
+	})
+
+	// The public IP of the host is reached through the default route
+	if !publicIPFound && hostNetworkV1.PublicIPv4 != "" {
+		for i := range nics {
+			if nics[i].Default {
+				nics[i].PublicIP = hostNetworkV1.PublicIPv4
+				break
+			}
+		}
+	}
+	return nics
+}
+
+// freezeRootFilesystemCommand suspends the writes on the root filesystem; a watchdog unfreezes it after %d seconds in
+// case the explicit unfreeze cannot be done
+const freezeRootFilesystemCommand = "sudo sh -c 'sync && (nohup sh -c \"sleep %d; fsfreeze --unfreeze /\" >/dev/null 2>&1 &) && fsfreeze --freeze /'"
+
+// unfreezeRootFilesystemCommand resumes the writes on the root filesystem
+const unfreezeRootFilesystemCommand = "sudo fsfreeze --unfreeze /"
+
+// rootFilesystemFreezeWatchdog is the delay after which the root filesystem frozen for a snapshot is unfrozen on host
+// side, even if the explicit unfreeze hangs (the SSH session and sudo may need to write on the frozen filesystem)
+const rootFilesystemFreezeWatchdog = 90 * time.Second
+
+// Snapshot creates the snapshot 'name' of the boot disk of the host, usable as source snapshot to create new hosts,
+// and records it in the metadata of the host
+// If quiesce is true, the writes on the root filesystem are suspended during the snapshot to make it consistent.
+// Returns fail.ErrNotImplemented if the provider doesn't support snapshots.
+func (handler *HostHandler) Snapshot(ctx context.Context, ref string, name string, quiesce bool) (snapshotID string, err error) {
+	if handler == nil {
+		return "", fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return "", fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return "", fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if name == "" {
+		return "", fail.InvalidParameterError("name", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s', %v)", ref, name, quiesce), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err := handler.loadHostMetadata(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if quiesce {
+		snapshotID, err = handler.snapshotFrozen(ctx, host, name)
+	} else {
+		snapshotID, err = handler.service.CreateHostSnapshot(host.ID, name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The filesystem is unfrozen at this point, the snapshot may still be uploaded by the provider
+	err = handler.service.WaitHostSnapshotReady(snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	err = host.Properties.LockForWrite(hostproperty.SnapshotsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			snapshotsV1 := clonable.(*propsv1.HostSnapshots)
+			snapshotsV1.List = append(
+				snapshotsV1.List, propsv1.HostSnapshot{
+					ID:        snapshotID,
+					Name:      name,
+					Quiesced:  quiesce,
+					CreatedAt: time.Now(),
+				},
+			)
+			return nil
+		},
+	)
+	if err != nil {
+		return snapshotID, err
+	}
+	_, err = metadata.SaveHost(handler.service, host)
+	return snapshotID, err
+}
+
+// snapshotFrozen creates the snapshot 'name' of the boot disk of the host with its root filesystem frozen, and
+// unfreezes it as soon as the provider has captured the disk
+func (handler *HostHandler) snapshotFrozen(ctx context.Context, host *abstract.Host, name string) (string, error) {
+	sshHandler := NewSSHHandler(handler.service)
+	watchdog := int(rootFilesystemFreezeWatchdog.Seconds())
+	retcode, _, stderr, err := sshHandler.Run(
+		ctx, host.Name, fmt.Sprintf(freezeRootFilesystemCommand, watchdog), outputs.COLLECT,
+	)
+	if err != nil {
+		return "", err
+	}
+	if retcode != 0 {
+		return "", fmt.Errorf("failed to freeze filesystem of host '%s' (retcode=%d): %s", host.Name, retcode, stderr)
+	}
+	frozenAt := time.Now()
+
+	snapshotID, err := handler.service.CreateHostSnapshot(host.ID, name)
+
+	// Whatever the outcome of the snapshot, do not keep the filesystem frozen; the unfreeze may hang if the session
+	// needs to write on the frozen filesystem, so it doesn't wait longer than the watchdog
+	unfreezeCtx, cancel := context.WithTimeout(ctx, rootFilesystemFreezeWatchdog)
+	defer cancel()
+	retcode, _, stderr, uerr := sshHandler.Run(unfreezeCtx, host.Name, unfreezeRootFilesystemCommand, outputs.COLLECT)
+	if uerr == nil && retcode != 0 {
+		uerr = fmt.Errorf("retcode=%d: %s", retcode, stderr)
+	}
+	if uerr != nil {
+		logrus.Warnf("failed to unfreeze filesystem of host '%s', will be done by watchdog: %v", host.Name, uerr)
+	}
+	if err != nil {
+		return "", err
+	}
+	if time.Since(frozenAt) > rootFilesystemFreezeWatchdog {
+		logrus.Warnf(
+			"filesystem of host '%s' unfrozen by watchdog before the disk was captured, snapshot '%s' may be inconsistent",
+			host.Name, name,
+		)
+	}
+	return snapshotID, nil
+}
+
+// Rename renames the host 'ref' to 'newName'
+// The instance is renamed on provider side when the provider supports it (only the SafeScale name changes
+// otherwise), then the metadata of the host and the references to it by name are updated.
+func (handler *HostHandler) Rename(ctx context.Context, ref string, newName string) (host *abstract.Host, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if newName == "" {
+		return nil, fail.InvalidParameterError("newName", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, newName), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	host, err = handler.loadHostMetadata(ref)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkNotFrozen(ctx, host); err != nil {
+		return nil, err
+	}
+	if host.Name == newName {
+		return host, nil
+	}
+
+	// Check the new name is not used in SafeScale scope...
+	_, err = metadata.LoadHost(handler.service, newName)
+	if err != nil {
+		switch err.(type) {
+		case fail.ErrNotFound:
+			// continue
+		default:
+			return nil, err
+		}
+	} else {
+		return nil, fail.DuplicateError(fmt.Sprintf("host '%s' already exists", newName))
+	}
+
+	// ... nor outside SafeScale scope
+	_, err = handler.service.GetHostByName(newName)
+	if err != nil {
+		switch err.(type) {
+		case fail.ErrNotFound:
+			// continue
+		default:
+			return nil, err
+		}
+	} else {
+		return nil, abstract.ResourceDuplicateError("host", newName)
+	}
+
+	oldName := host.Name
+	providerRenamed := true
+	err = handler.service.RenameHost(host.ID, newName)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotImplemented); !ok {
+			return nil, err
+		}
+		logrus.Warnf("provider cannot rename host '%s', only the name in SafeScale is changed", oldName)
+		providerRenamed = false
+	}
+
+	err = metadata.RenameHost(handler.service, host, newName)
+	if err != nil {
+		if providerRenamed {
+			if derr := handler.service.RenameHost(host.ID, oldName); derr != nil {
+				logrus.Errorf("cleaning up on failure, failed to restore name of host '%s': %v", oldName, derr)
+			}
+		}
+		return nil, err
+	}
+
+	err = handler.renameHostReferences(ctx, host, oldName)
+	if err != nil {
+		return host, fail.Wrap(
+			err, fmt.Sprintf("host '%s' renamed to '%s', but some references to it were not updated", oldName, newName),
+		)
+	}
+	return host, nil
+}
+
+// renameHostReferences replaces the name 'oldName' of the host by its current name in the metadata referencing the
+// host by name: the networks of the host, the shares it exports and the shares it mounts
+func (handler *HostHandler) renameHostReferences(ctx context.Context, host *abstract.Host, oldName string) error {
+	var (
+		networkIDs []string
+		shares     []*propsv1.HostShare
+		mountedIDs []string
+	)
+	err := host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for k := range clonable.(*propsv1.HostNetwork).NetworksByID {
+				networkIDs = append(networkIDs, k)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	err = host.Properties.LockForRead(hostproperty.SharesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for _, v := range clonable.(*propsv1.HostShares).ByID {
+				shares = append(shares, v)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	err = host.Properties.LockForRead(hostproperty.MountsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for k := range clonable.(*propsv1.HostMounts).RemoteMountsByShareID {
+				mountedIDs = append(mountedIDs, k)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	var errs []fail.LabeledError
+	netHandler := NewNetworkHandler(handler.service)
+	for _, id := range networkIDs {
+		network, err := netHandler.Inspect(ctx, id)
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "network " + id, Err: err})
+			continue
+		}
+		err = network.Properties.LockForWrite(networkproperty.HostsV1).ThenUse(
+			func(clonable data.Clonable) error {
+				networkHostsV1 := clonable.(*propsv1.NetworkHosts)
+				delete(networkHostsV1.ByName, oldName)
+				networkHostsV1.ByName[host.Name] = host.ID
+				networkHostsV1.ByID[host.ID] = host.Name
+				return nil
 			},
 		)
-		if err2 != nil {
-			return fail.Errorf(fmt.Sprintf("failed to cancel host deletion : %s", err2.Error()), err2)
+		if err == nil {
+			_, err = metadata.SaveNetwork(handler.service, network)
+		}
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "network " + network.Name, Err: err})
 		}
+	}
 
-		buf, err2 := hostBis.Serialize()
-		if err2 != nil {
-			return fail.Errorf(fmt.Sprintf("deleted Host recreated by safescale"), err2)
+	// The metadata of a share records the name of the host exporting it
+	for _, share := range shares {
+		_, err = metadata.SaveShare(handler.service, host.ID, host.Name, share.ID, share.Name)
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "share " + share.Name, Err: err})
 		}
-		return fail.Errorf(fmt.Sprintf("deleted Host recreated by safescale : %s", buf), nil)
+	}
 
+	// The host exporting a share records the names of the hosts mounting it
+	for _, shareID := range mountedIDs {
+		serverName, err := metadata.LoadShare(handler.service, shareID)
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "share " + shareID, Err: err})
+			continue
+		}
+		mh, err := metadata.LoadHost(handler.service, serverName)
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "host " + serverName, Err: err})
+			continue
+		}
+		server, err := mh.Get()
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "host " + serverName, Err: err})
+			continue
+		}
+		err = server.Properties.LockForWrite(hostproperty.SharesV1).ThenUse(
+			func(clonable data.Clonable) error {
+				share, ok := clonable.(*propsv1.HostShares).ByID[shareID]
+				if !ok {
+					return nil
+				}
+				delete(share.ClientsByName, oldName)
+				share.ClientsByName[host.Name] = host.ID
+				share.ClientsByID[host.ID] = host.Name
+				return nil
+			},
+		)
+		if err == nil {
+			_, err = metadata.SaveHost(handler.service, server)
+		}
+		if err != nil {
+			errs = append(errs, fail.LabeledError{Label: "host " + serverName, Err: err})
+		}
+	}
+
+	return fail.ErrListErrorWithLabels(errs)
+}
+
+// HostCloneOverrides contains the settings of a clone that differ from the ones of its source host
+type HostCloneOverrides struct {
+	// Sizing replaces the sizing of the source host, as accepted by Create (*abstract.SizingRequirements or template name)
+	Sizing interface{}
+	// Networks replaces the networks of the source host, as a comma-separated list whose first item is the default one
+	Networks string
+	// WithVolumes creates, formats and mounts on the clone volumes of the same size and speed as the ones of the source
+	WithVolumes bool
+}
+
+// hostCloneSettings contains the parameters of Create used to clone a host
+type hostCloneSettings struct {
+	los            string
+	net            string
+	public         bool
+	sizing         interface{}
+	systemDiskSize int
+	securityGroups []string
+	features       []string
+	description    *propsv1.HostDescription
+}
+
+// cloneHostSettings computes from the properties of a host the parameters of Create giving a host configured the same
+// way, 'overrides' applied; the features returned are the ones explicitly installed on the host, sorted by name (the
+// ones they require are installed with them). The sizing property records the ID of the template, 'templateName'
+// converts it to the name expected by Create
+func cloneHostSettings(
+	sizing *propsv1.HostSizing, network *propsv1.HostNetwork, securityGroups *propsv1.HostSecurityGroups,
+	features *propsv1.HostFeatures, description *propsv1.HostDescription, system *propsv1.HostSystem,
+	overrides HostCloneOverrides, templateName func(id string) (string, error),
+) (*hostCloneSettings, error) {
+	if network.IsGateway {
+		return nil, fail.InvalidRequestError("cannot clone a gateway, gateways are managed with their network")
+	}
+
+	settings := hostCloneSettings{
+		los:         system.Image,
+		public:      network.PublicIPv4 != "" || network.PublicIPv6 != "",
+		description: description,
+	}
+	if settings.los == "" {
+		settings.los = system.Flavor
+	}
+	if settings.los == "" {
+		// FIXME: host's os name may not be stored in metadata so we use ubuntu 18.04 by default, as Delete does
+		settings.los = "ubuntu 18.04"
+	}
+
+	if overrides.Networks != "" {
+		settings.net = overrides.Networks
+	} else {
+		var others []string
+		for id, name := range network.NetworksByID {
+			if id != network.DefaultNetworkID {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(others)
+		if name, ok := network.NetworksByID[network.DefaultNetworkID]; ok {
+			others = append([]string{name}, others...)
+		}
+		settings.net = strings.Join(others, ",")
+	}
+
+	switch {
+	case overrides.Sizing != nil:
+		settings.sizing = overrides.Sizing
+	case sizing.Template != "":
+		name, err := templateName(sizing.Template)
+		if err != nil {
+			return nil, err
+		}
+		settings.sizing = name
+		if sizing.AllocatedSize != nil {
+			settings.systemDiskSize = sizing.AllocatedSize.DiskSize
+		}
 	default:
+		allocated := sizing.AllocatedSize
+		if allocated == nil {
+			allocated = propsv1.NewHostSize()
+		}
+		requirements := &abstract.SizingRequirements{
+			MinCores:    allocated.Cores,
+			MaxCores:    allocated.Cores,
+			MinFreq:     allocated.CPUFreq,
+			MinGPU:      allocated.GPUNumber,
+			MinRAMSize:  allocated.RAMSize,
+			MaxRAMSize:  allocated.RAMSize,
+			MinDiskSize: allocated.DiskSize,
+		}
+		if requirements.MinGPU == 0 {
+			// a GPU count, even 0, restricts the selection to the templates known by the scanner database
+			requirements.MinGPU = -1
+		}
+		settings.sizing = requirements
 	}
 
-	return nil
+	for name, sg := range securityGroups.ByName {
+		if !sg.FromNetwork {
+			settings.securityGroups = append(settings.securityGroups, name)
+		}
+	}
+	sort.Strings(settings.securityGroups)
+
+	for name, feature := range features.Installed {
+		if feature.HostContext {
+			settings.features = append(settings.features, name)
+		}
+	}
+	sort.Strings(settings.features)
+
+	return &settings, nil
 }
 
-// SSH returns ssh parameters to access the host referenced by ref
-func (handler *HostHandler) SSH(ctx context.Context, ref string) (sshConfig *system.SSHConfig, err error) {
-	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+// cloneHostVolumes returns the volumes attached and mounted on the host as items accepted by Create
+// ("<size>:<mountpoint>:<speed>:<format>"), sorted by mount point
+func (handler *HostHandler) cloneHostVolumes(host *abstract.Host) ([]string, error) {
+	var specs []string
+	err := host.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostVolumesV1 := clonable.(*propsv1.HostVolumes)
+			return host.Properties.LockForRead(hostproperty.MountsV1).ThenUse(
+				func(clonable data.Clonable) error {
+					hostMountsV1 := clonable.(*propsv1.HostMounts)
+					for id, device := range hostVolumesV1.DevicesByID {
+						path, ok := hostMountsV1.LocalMountsByDevice[device]
+						if !ok {
+							continue
+						}
+						mount := hostMountsV1.LocalMountsByPath[path]
+						mv, err := metadata.LoadVolume(handler.service, id)
+						if err != nil {
+							return err
+						}
+						volume, err := mv.Get()
+						if err != nil {
+							return err
+						}
+						spec := fmt.Sprintf("%d:%s:%s", volume.Size, path, volume.Speed.String())
+						if mount != nil && mount.FileSystem != "" {
+							spec += ":" + mount.FileSystem
+						}
+						specs = append(specs, spec)
+					}
+					return nil
+				},
+			)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return strings.SplitN(specs[i], ":", 3)[1] < strings.SplitN(specs[j], ":", 3)[1]
+	})
+	return specs, nil
+}
+
+// Clone creates the host 'newName' configured as the host 'sourceRef': same sizing, image, networks, security groups,
+// tags and placement, then installs on it the features explicitly installed on the source host.
+// 'overrides' may change the sizing and the networks of the clone; the volumes of the source are replicated (empty)
+// only if overrides.WithVolumes is set.
+// Only the configuration is cloned: the runtime state and the data of the source host are not, use Snapshot for that.
+// If the installation of a feature fails, the clone is kept and returned with the error.
+func (handler *HostHandler) Clone(ctx context.Context, sourceRef string, newName string, overrides HostCloneOverrides) (newHost *abstract.Host, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if sourceRef == "" {
+		return nil, fail.InvalidParameterError("sourceRef", "cannot be empty string")
+	}
+	if newName == "" {
+		return nil, fail.InvalidParameterError("newName", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", sourceRef, newName), true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
-	sshHandler := NewSSHHandler(handler.service)
-	sshConfig, err = sshHandler.GetConfig(ctx, ref)
+	source, err := handler.loadHostMetadata(sourceRef)
 	if err != nil {
 		return nil, err
 	}
-	return sshConfig, nil
+
+	// Check the new name is not used in SafeScale scope (Create checks it outside SafeScale scope)
+	_, err = metadata.LoadHost(handler.service, newName)
+	if err != nil {
+		switch err.(type) {
+		case fail.ErrNotFound:
+			// continue
+		default:
+			return nil, err
+		}
+	} else {
+		return nil, fail.DuplicateError(fmt.Sprintf("host '%s' already exists", newName))
+	}
+
+	var (
+		sizingV1         *propsv1.HostSizing
+		networkV1        *propsv1.HostNetwork
+		securityGroupsV1 *propsv1.HostSecurityGroups
+		featuresV1       *propsv1.HostFeatures
+		descriptionV1    *propsv1.HostDescription
+		systemV1         *propsv1.HostSystem
+	)
+	for property, read := range map[string]func(data.Clonable){
+		hostproperty.SizingV1:         func(c data.Clonable) { sizingV1 = c.Clone().(*propsv1.HostSizing) },
+		hostproperty.NetworkV1:        func(c data.Clonable) { networkV1 = c.Clone().(*propsv1.HostNetwork) },
+		hostproperty.SecurityGroupsV1: func(c data.Clonable) { securityGroupsV1 = c.Clone().(*propsv1.HostSecurityGroups) },
+		hostproperty.FeaturesV1:       func(c data.Clonable) { featuresV1 = c.Clone().(*propsv1.HostFeatures) },
+		hostproperty.DescriptionV1:    func(c data.Clonable) { descriptionV1 = c.Clone().(*propsv1.HostDescription) },
+		hostproperty.SystemV1:         func(c data.Clonable) { systemV1 = c.Clone().(*propsv1.HostSystem) },
+	} {
+		read := read
+		err = source.Properties.LockForRead(property).ThenUse(
+			func(clonable data.Clonable) error {
+				read(clonable)
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	settings, err := cloneHostSettings(
+		sizingV1, networkV1, securityGroupsV1, featuresV1, descriptionV1, systemV1, overrides,
+		func(id string) (string, error) {
+			template, err := handler.service.GetTemplate(id)
+			if err != nil {
+				return "", err
+			}
+			return template.Name, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes []string
+	if overrides.WithVolumes {
+		volumes, err = handler.cloneHostVolumes(source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	desc := settings.description
+	newHost, err = handler.Create(
+		ctx, HostCreateRequest{
+			Name:           newName,
+			Networks:       settings.net,
+			OS:             settings.los,
+			Public:         settings.public,
+			Sizing:         settings.sizing,
+			Domain:         desc.Domain,
+			Spot:           desc.Spot,
+			SecurityGroups: settings.securityGroups,
+			Volumes:        volumes,
+			ShieldedVM:     desc.ShieldedVM,
+			ConfidentialVM: desc.ConfidentialVM,
+			Tags:           desc.Tags,
+			SystemDiskSize: settings.systemDiskSize,
+			AffinityGroup:  desc.AffinityGroup,
+			AntiAffinity:   desc.AntiAffinity,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := map[string]error{}
+	for _, feature := range settings.features {
+		_, err = handler.EnsureFeature(ctx, newHost.ID, feature, install.Variables{}, install.Settings{})
+		if err != nil {
+			errs["feature "+feature] = err
+		}
+	}
+	if err = fail.ErrListErrorWithContext(errs); err != nil {
+		return newHost, fail.Wrap(
+			err, fmt.Sprintf("host '%s' cloned as '%s', but some features failed to install", source.Name, newName),
+		)
+	}
+	return newHost, nil
 }
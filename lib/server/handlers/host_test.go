@@ -0,0 +1,904 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	grpcmetadata "google.golang.org/grpc/metadata"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/volumespeed"
+	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	"github.com/CS-SI/SafeScale/lib/utils/data"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestPreferTemplatesFittingImage(t *testing.T) {
+	small := &abstract.HostTemplate{Name: "small", DiskSize: 10}
+	large := &abstract.HostTemplate{Name: "large", DiskSize: 40}
+	noDisk := &abstract.HostTemplate{Name: "nodisk"}
+	templates := []*abstract.HostTemplate{small, large, noDisk}
+
+	got := preferTemplatesFittingImage(templates, &abstract.Image{Name: "img", MinDiskGB: 20})
+	assert.Equal(t, []*abstract.HostTemplate{large, noDisk, small}, got)
+
+	got = preferTemplatesFittingImage(templates, &abstract.Image{Name: "img"})
+	assert.Equal(t, templates, got)
+}
+
+func TestHostFilterMatch(t *testing.T) {
+	host := abstract.NewHost()
+	host.Name = "host"
+	host.LastState = hoststate.STARTED
+	err := host.Properties.LockForWrite(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			hostNetworkV1.NetworksByID["net-id"] = "net"
+			hostNetworkV1.NetworksByName["net"] = "net-id"
+			return nil
+		},
+	)
+	assert.Nil(t, err)
+
+	cases := []struct {
+		filter HostFilter
+		match  bool
+	}{
+		{HostFilter{}, true},
+		{HostFilter{States: []hoststate.Enum{hoststate.STOPPED, hoststate.STARTED}}, true},
+		{HostFilter{States: []hoststate.Enum{hoststate.STOPPED}}, false},
+		{HostFilter{Networks: []string{"net-id"}}, true},
+		{HostFilter{Networks: []string{"other", "net"}}, true},
+		{HostFilter{Networks: []string{"other"}}, false},
+		{HostFilter{Networks: []string{"net"}, States: []hoststate.Enum{hoststate.ERROR}}, false},
+	}
+	for _, c := range cases {
+		ok, err := c.filter.Match(host)
+		assert.Nil(t, err)
+		assert.Equal(t, c.match, ok, fmt.Sprintf("%+v", c.filter))
+	}
+
+	_, err = HostFilter{}.Match(nil)
+	assert.NotNil(t, err)
+}
+
+func TestHostFilterMatchRegion(t *testing.T) {
+	legacy := abstract.NewHost()
+	legacy.Name = "legacy"
+	scoped := abstract.NewHost()
+	scoped.Name = "scoped"
+	err := scoped.Properties.LockForWrite(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			clonable.(*propsv1.HostDescription).Region = "SBG5"
+			return nil
+		},
+	)
+	assert.Nil(t, err)
+
+	cases := []struct {
+		filter HostFilter
+		host   *abstract.Host
+		match  bool
+	}{
+		{HostFilter{Region: "SBG5", DefaultRegion: "GRA5"}, scoped, true},
+		{HostFilter{Region: "SBG5", DefaultRegion: "GRA5"}, legacy, false},
+		{HostFilter{Region: "GRA5", DefaultRegion: "GRA5"}, scoped, false},
+		{HostFilter{Region: "GRA5", DefaultRegion: "GRA5"}, legacy, true},
+		{HostFilter{Region: "SBG5", Networks: []string{"net"}}, scoped, false},
+	}
+	for _, c := range cases {
+		ok, err := c.filter.Match(c.host)
+		assert.Nil(t, err)
+		assert.Equal(t, c.match, ok, fmt.Sprintf("%s: %+v", c.host.Name, c.filter))
+	}
+}
+
+func TestAddFeatureTransactionallyRollsBackOnRecordFailure(t *testing.T) {
+	var added, removed bool
+	err := addFeatureTransactionally(
+		func() error { added = true; return nil },
+		func() error { removed = true; return nil },
+		func() error { return fmt.Errorf("metadata write failed") },
+	)
+	assert.NotNil(t, err)
+	assert.True(t, added)
+	assert.True(t, removed)
+}
+
+func TestAddFeatureTransactionallyRollsBackOnPartialInstall(t *testing.T) {
+	var recorded, removed bool
+	err := addFeatureTransactionally(
+		func() error { return fmt.Errorf("step 2 failed") },
+		func() error { removed = true; return fmt.Errorf("removal failed") },
+		func() error { recorded = true; return nil },
+	)
+	assert.NotNil(t, err)
+	assert.False(t, recorded)
+	assert.True(t, removed)
+	assert.Contains(t, err.Error(), "step 2 failed")
+}
+
+func TestAddFeatureTransactionallySucceeds(t *testing.T) {
+	var recorded, removed bool
+	err := addFeatureTransactionally(
+		func() error { return nil },
+		func() error { removed = true; return nil },
+		func() error { recorded = true; return nil },
+	)
+	assert.Nil(t, err)
+	assert.True(t, recorded)
+	assert.False(t, removed)
+}
+
+func newTestHostSecurityGroups() *propsv1.HostSecurityGroups {
+	current := propsv1.NewHostSecurityGroups()
+	current.ByName["web"] = &propsv1.HostSecurityGroup{Name: "web"}
+	current.ByName["net-sg"] = &propsv1.HostSecurityGroup{Name: "net-sg", FromNetwork: true}
+	return current
+}
+
+func TestSecurityGroupsDiff(t *testing.T) {
+	current := newTestHostSecurityGroups()
+
+	toBind, toUnbind := securityGroupsDiff(current, []string{"web", "db", "admin", "db"})
+	assert.Equal(t, []string{"admin", "db"}, toBind)
+	assert.Empty(t, toUnbind)
+
+	// the security groups bound because of the network are preserved
+	toBind, toUnbind = securityGroupsDiff(current, nil)
+	assert.Empty(t, toBind)
+	assert.Equal(t, []string{"web"}, toUnbind)
+
+	toBind, toUnbind = securityGroupsDiff(current, []string{"web", "net-sg"})
+	assert.Empty(t, toBind)
+	assert.Empty(t, toUnbind)
+}
+
+func TestReplaceSecurityGroupsTransactionally(t *testing.T) {
+	bound := map[string]bool{"web": true}
+	bind := func(name string) error { bound[name] = true; return nil }
+	unbind := func(name string) error { delete(bound, name); return nil }
+	recorded := false
+
+	err := replaceSecurityGroupsTransactionally(
+		[]string{"db"}, []string{"web"}, bind, unbind, func() error { recorded = true; return nil },
+	)
+	assert.Nil(t, err)
+	assert.True(t, recorded)
+	assert.Equal(t, map[string]bool{"db": true}, bound)
+}
+
+func TestReplaceSecurityGroupsTransactionallyRollsBackOnProviderError(t *testing.T) {
+	bound := map[string]bool{"web": true, "old": true}
+	bind := func(name string) error {
+		if name == "broken" {
+			return fmt.Errorf("provider refused to bind '%s'", name)
+		}
+		bound[name] = true
+		return nil
+	}
+	unbind := func(name string) error { delete(bound, name); return nil }
+	recorded := false
+
+	err := replaceSecurityGroupsTransactionally(
+		[]string{"admin", "broken", "db"}, []string{"old"}, bind, unbind, func() error { recorded = true; return nil },
+	)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "broken")
+	assert.False(t, recorded)
+	assert.Equal(t, map[string]bool{"web": true, "old": true}, bound)
+
+	// a failure to record in metadata also restores the bindings, unbound ones included
+	err = replaceSecurityGroupsTransactionally(
+		[]string{"db"}, []string{"old"}, bind, unbind, func() error { return fmt.Errorf("metadata write failed") },
+	)
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]bool{"web": true, "old": true}, bound)
+}
+
+func TestLineTail(t *testing.T) {
+	tail := newLineTail(2)
+	_, _ = tail.Write([]byte("line1\nline2\nli"))
+	_, _ = tail.Write([]byte("ne3\nline4"))
+	assert.Equal(t, "line2\nline3\nline4", tail.String())
+	_, _ = tail.Write([]byte("\n"))
+	assert.Equal(t, "line3\nline4", tail.String())
+}
+
+func TestProvisioningLogScript(t *testing.T) {
+	script := provisioningLogScript([]string{"phase1"})
+	assert.Contains(t, script, "/opt/safescale/var/log/user_data.phase1.log")
+	assert.Contains(t, script, "/opt/safescale/var/state/user_data.phase1.done")
+	assert.NotContains(t, script, "phase2")
+}
+
+func TestParseDiskUsage(t *testing.T) {
+	out := `Filesystem     Type     1-blocks       Used  Available Capacity Mounted on
+udev           devtmpfs 4096000000          0 4096000000       0% /dev
+/dev/sda1      ext4     52710469632 8589934592 44120535040      17% /
+tmpfs          tmpfs     819200000     1048576  818151424       1% /run
+/dev/sdb1      xfs      107374182400 1073741824 106300440576       1% /data/my volume
+/dev/sda15     vfat      109422592    5242880  104179712       5% /boot/efi
+%%
+Filesystem     Type      Inodes  IUsed   IFree IUse% Mounted on
+udev           devtmpfs  998543    412  998131    1% /dev
+/dev/sda1      ext4     6451200 123456 6327744    2% /
+tmpfs          tmpfs    1001104    812 1000292    1% /run
+/dev/sdb1      xfs      52428800     10 52428790    1% /data/my volume
+/dev/sda15     vfat           0      0       0     - /boot/efi
+`
+	list, err := parseDiskUsage(out)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(list))
+	assert.Equal(t, &abstract.FilesystemUsage{
+		Device: "/dev/sda1", Type: "ext4", MountPoint: "/", Size: 52710469632, Used: 8589934592,
+		Available: 44120535040, Inodes: 6451200, InodesUsed: 123456, InodesFree: 6327744,
+	}, list[0])
+	assert.Equal(t, "/data/my volume", list[1].MountPoint)
+	assert.Equal(t, uint64(52428800), list[1].Inodes)
+	assert.Equal(t, "vfat", list[2].Type)
+	assert.Equal(t, uint64(0), list[2].Inodes)
+}
+
+func TestParseDiskUsageRejectsUnexpectedOutput(t *testing.T) {
+	_, err := parseDiskUsage("df: unrecognized option\n")
+	assert.NotNil(t, err)
+
+	_, err = parseDiskUsage("Filesystem Type 1-blocks Used Available Capacity Mounted on\n/dev/sda1 ext4 a b c 1% /\n%%\nFilesystem\n")
+	assert.NotNil(t, err)
+}
+
+func TestParseListeningPortsFromSS(t *testing.T) {
+	out := `ss
+Netid State  Recv-Q Send-Q      Local Address:Port Peer Address:Port Process
+udp   UNCONN 0      0       127.0.0.53%lo:53        0.0.0.0:*     users:(("systemd-resolve",pid=600,fd=12))
+tcp   LISTEN 0      4096          0.0.0.0:22        0.0.0.0:*     users:(("sshd",pid=800,fd=3))
+tcp   LISTEN 0      4096          0.0.0.0:22        0.0.0.0:*     users:(("sshd",pid=800,fd=3))
+tcp   LISTEN 0      511                 *:80              *:*     users:(("nginx",pid=901,fd=6),("nginx",pid=900,fd=6))
+tcp   LISTEN 0      128              [::]:22           [::]:*     users:(("sshd",pid=800,fd=4))
+tcp   LISTEN 0      128         127.0.0.1:5432      0.0.0.0:*
+`
+	ports, err := parseListeningPorts(out)
+	assert.Nil(t, err)
+	assert.Equal(t, []abstract.ListeningPort{
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 22, Process: "sshd"},
+		{Protocol: "tcp", Address: "::", Port: 22, Process: "sshd"},
+		{Protocol: "tcp", Address: "*", Port: 80, Process: "nginx"},
+		{Protocol: "tcp", Address: "127.0.0.1", Port: 5432},
+		{Protocol: "udp", Address: "127.0.0.53", Port: 53, Process: "systemd-resolve"},
+	}, ports)
+}
+
+func TestParseListeningPortsFromNetstat(t *testing.T) {
+	out := `netstat
+Active Internet connections (only servers)
+Proto Recv-Q Send-Q Local Address           Foreign Address         State       PID/Program name
+tcp        0      0 0.0.0.0:22              0.0.0.0:*               LISTEN      800/sshd: /usr/sbin
+tcp        0      0 127.0.0.1:5432          0.0.0.0:*               LISTEN      -
+tcp6       0      0 :::22                   :::*                    LISTEN      800/sshd: /usr/sbin
+udp        0      0 127.0.0.53:53           0.0.0.0:*                           600/systemd-resolve
+udp6       0      0 fe80::1%eth0:546        :::*                                -
+`
+	ports, err := parseListeningPorts(out)
+	assert.Nil(t, err)
+	assert.Equal(t, []abstract.ListeningPort{
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 22, Process: "sshd: /usr/sbin"},
+		{Protocol: "tcp", Address: "::", Port: 22, Process: "sshd: /usr/sbin"},
+		{Protocol: "tcp", Address: "127.0.0.1", Port: 5432},
+		{Protocol: "udp", Address: "127.0.0.53", Port: 53, Process: "systemd-resolve"},
+		{Protocol: "udp", Address: "fe80::1", Port: 546},
+	}, ports)
+}
+
+func TestParseListeningPortsRejectsUnexpectedOutput(t *testing.T) {
+	_, err := parseListeningPorts("")
+	assert.NotNil(t, err)
+
+	_, err = parseListeningPorts("lsof\nsshd 800 root 3u IPv4 TCP *:22 (LISTEN)\n")
+	assert.NotNil(t, err)
+}
+
+func TestParseConnTarget(t *testing.T) {
+	target, err := ParseConnTarget("db:5432")
+	assert.Nil(t, err)
+	assert.Equal(t, abstract.ConnTarget{Host: "db", Port: 5432}, target)
+
+	target, err = ParseConnTarget("https://example.com/health")
+	assert.Nil(t, err)
+	assert.Equal(t, abstract.ConnTarget{URL: "https://example.com/health"}, target)
+
+	for _, in := range []string{"db", "db:0", "db:http", "db;reboot:22", "ftp://example.com"} {
+		_, err = ParseConnTarget(in)
+		assert.NotNil(t, err, in)
+	}
+}
+
+func TestConnectivityCommand(t *testing.T) {
+	targets := []abstract.ConnTarget{{Host: "db", Port: 5432}, {URL: "http://example.com/a'b"}}
+	cmd := connectivityCommand(targets, []string{"192.168.0.5", ""})
+	assert.Contains(t, cmd, "check_tcp 0 '192.168.0.5' 5432 &\n")
+	assert.Contains(t, cmd, `check_url 1 'http://example.com/a'"'"'b' &`)
+	assert.True(t, strings.HasSuffix(cmd, "wait\n"))
+}
+
+func TestParseConnectivityResults(t *testing.T) {
+	targets := []abstract.ConnTarget{{Host: "db", Port: 5432}, {Host: "cache", Port: 6379}, {URL: "http://example.com"}}
+	out := "1 1 5003 \n0 0 12 \n"
+	results := parseConnectivityResults(targets, out)
+	assert.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, 12*time.Millisecond, results[0].Latency)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "connection failed (retcode=1)", results[1].Error)
+	assert.False(t, results[2].Success)
+	assert.Equal(t, "no result returned by the check", results[2].Error)
+}
+
+func TestParseHostMetrics(t *testing.T) {
+	out := `350735.47 1234567.89
+%%
+0.52 0.58 0.59 2/345 12345
+%%
+              total        used        free      shared  buff/cache   available
+Mem:     8201306112  1073741824  4294967296    1048576  2832596992  6800000000
+Swap:             0           0           0
+%%
+4
+`
+	metrics := parseHostMetrics(out)
+	assert.Equal(t, &abstract.HostMetrics{
+		Uptime: 350735 * time.Second, Load1: 0.52, Load5: 0.58, Load15: 0.59,
+		MemoryTotal: 8201306112, MemoryUsed: 1073741824, MemoryFree: 4294967296, CPUCount: 4,
+	}, metrics)
+}
+
+func TestParseHostMetricsPartial(t *testing.T) {
+	// free and nproc missing: memory read from /proc/meminfo, CPU count unavailable
+	out := `120.00 100.00
+%%
+%%
+MemTotal:        2048000 kB
+MemFree:          512000 kB
+Buffers:          100000 kB
+Cached:           400000 kB
+%%
+`
+	metrics := parseHostMetrics(out)
+	assert.Equal(t, 2*time.Minute, metrics.Uptime)
+	assert.Equal(t, uint64(2048000*1024), metrics.MemoryTotal)
+	assert.Equal(t, uint64(512000*1024), metrics.MemoryFree)
+	assert.Equal(t, uint64(1036000*1024), metrics.MemoryUsed)
+	assert.Equal(t, []string{"load", "cpu"}, metrics.Unavailable)
+
+	metrics = parseHostMetrics("")
+	assert.Equal(t, []string{"uptime", "load", "memory", "cpu"}, metrics.Unavailable)
+}
+
+func TestIPForwardingCommand(t *testing.T) {
+	assert.Equal(
+		t, "sudo sysctl -w net.ipv4.ip_forward=1 && echo 'net.ipv4.ip_forward = 1' | sudo tee /etc/sysctl.d/99-safescale-ip-forward.conf >/dev/null",
+		ipForwardingCommand(true),
+	)
+	assert.Contains(t, ipForwardingCommand(false), "net.ipv4.ip_forward=0")
+}
+
+func TestCheckIPForwardingChange(t *testing.T) {
+	host := abstract.NewHost()
+	host.Name = "gw-net"
+	assert.Nil(t, checkIPForwardingChange(host, false))
+	assert.Nil(t, checkIPForwardingChange(host, true))
+
+	err := host.Properties.LockForWrite(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			clonable.(*propsv1.HostNetwork).IsGateway = true
+			return nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, checkIPForwardingChange(host, true))
+	err = checkIPForwardingChange(host, false)
+	if assert.NotNil(t, err) {
+		_, ok := err.(fail.ErrInvalidRequest)
+		assert.True(t, ok)
+	}
+}
+
+func TestValidateKernelParameters(t *testing.T) {
+	assert.Nil(t, validateKernelParameters(map[string]string{
+		"vm.swappiness": "10", "net.ipv4.tcp_rmem": "4096 87380 6291456", "net.ipv4.conf.eth0/100.rp_filter": "2",
+	}))
+	assert.NotNil(t, validateKernelParameters(nil))
+	for _, bad := range []map[string]string{
+		{"swappiness": "10"},
+		{"-w.vm.swappiness": "10"},
+		{"vm.swappiness;reboot": "10"},
+		{"vm.swappiness": ""},
+		{"vm.swappiness": "10\nkernel.panic = 1"},
+	} {
+		assert.NotNil(t, validateKernelParameters(bad), fmt.Sprintf("%v", bad))
+	}
+}
+
+func TestApplyKernelParametersCommand(t *testing.T) {
+	cmd := applyKernelParametersCommand(
+		[]string{"vm.swappiness"}, map[string]string{"vm.swappiness": "10", "kernel.pid_max": "65536"},
+	)
+	assert.Equal(t, `sudo sysctl -e vm.swappiness 2>/dev/null
+echo '%%'
+sudo tee /etc/sysctl.d/90-safescale.conf >/dev/null <<'SAFESCALE_SYSCTL' || exit 1
+# Kernel parameters applied by SafeScale, do not edit
+kernel.pid_max = 65536
+vm.swappiness = 10
+SAFESCALE_SYSCTL
+sudo sysctl --system >/dev/null 2>&1
+sudo sysctl -e vm.swappiness 2>/dev/null
+`, cmd)
+}
+
+func TestParseSysctlValues(t *testing.T) {
+	values := parseSysctlValues("vm.swappiness = 60\nnet.ipv4.tcp_rmem = 4096\t87380\t6291456\nkernel.domainname = \n\n")
+	assert.Equal(t, map[string]string{
+		"vm.swappiness": "60", "net.ipv4.tcp_rmem": "4096 87380 6291456", "kernel.domainname": "",
+	}, values)
+}
+
+func TestParseNICs(t *testing.T) {
+	networks := []*abstract.Network{{ID: "id-front", Name: "front"}, {ID: "id-back", Name: "back"}}
+
+	nics, err := parseNICs([]string{"back:0:eth-back", "id-front:1"}, networks)
+	assert.Nil(t, err)
+	assert.Equal(
+		t, []abstract.HostNIC{{NetworkID: "id-back", Index: 0, Name: "eth-back"}, {NetworkID: "id-front", Index: 1}},
+		nics,
+	)
+
+	nics, err = parseNICs(nil, networks)
+	assert.Nil(t, err)
+	assert.Nil(t, nics)
+
+	for _, bad := range []string{"front", "front:x", ":0", "other:0"} {
+		_, err = parseNICs([]string{bad}, networks)
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestParseFixedIPs(t *testing.T) {
+	networks := []*abstract.Network{{ID: "id-front", Name: "front"}, {ID: "id-back", Name: "back"}}
+
+	fixedIPs, err := parseFixedIPs([]string{"back:192.168.2.10", "id-front:192.168.1.10"}, networks)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"id-back": "192.168.2.10", "id-front": "192.168.1.10"}, fixedIPs)
+
+	fixedIPs, err = parseFixedIPs(nil, networks)
+	assert.Nil(t, err)
+	assert.Nil(t, fixedIPs)
+
+	for _, bad := range []string{"front", "front:", ":192.168.1.10", "other:192.168.1.10"} {
+		_, err = parseFixedIPs([]string{bad}, networks)
+		assert.NotNil(t, err, bad)
+	}
+	_, err = parseFixedIPs([]string{"front:192.168.1.10", "id-front:192.168.1.11"}, networks)
+	assert.NotNil(t, err)
+}
+
+func TestCheckNetworksCompatibility(t *testing.T) {
+	// as listed by AWS: subnets carry the ID of their VPC
+	front := &abstract.Network{ID: "subnet-0a1", Name: "front", Subnet: true, Parent: "vpc-0f2"}
+	back := &abstract.Network{ID: "subnet-0b3", Name: "back", Subnet: true, Parent: "vpc-0f2"}
+	other := &abstract.Network{ID: "subnet-0c4", Name: "other", Subnet: true, Parent: "vpc-0e5"}
+	// as loaded from metadata: the provider network is unknown
+	stored := &abstract.Network{ID: "6d1b4f3e-2c2a-4b8e-9a57-3f1e0c9b2d11", Name: "stored"}
+	storedToo := &abstract.Network{ID: "0b6f2f61-88c4-4d2e-b4a0-5e7b9a1c3f42", Name: "stored-too"}
+
+	assert.Nil(t, checkNetworksCompatibility([]*abstract.Network{front}, false))
+	assert.Nil(t, checkNetworksCompatibility([]*abstract.Network{front, back}, false))
+	assert.Nil(t, checkNetworksCompatibility([]*abstract.Network{stored, storedToo}, false))
+	assert.Nil(t, checkNetworksCompatibility([]*abstract.Network{stored, other}, false))
+	assert.Nil(t, checkNetworksCompatibility([]*abstract.Network{front, stored}, false))
+
+	err := checkNetworksCompatibility([]*abstract.Network{front, back, other, stored}, false)
+	assert.NotNil(t, err)
+	assert.IsType(t, fail.ErrInvalidRequest{}, err)
+	assert.Contains(t, err.Error(), "'other'")
+	assert.NotContains(t, err.Error(), "'back'")
+	assert.NotContains(t, err.Error(), "'stored'")
+
+	assert.Nil(t, checkNetworksCompatibility([]*abstract.Network{front, other}, true))
+}
+
+func TestParseVolumes(t *testing.T) {
+	volumes, err := parseVolumes([]string{"100:/data", "20:/logs:ssd:xfs", "5:/tmp/scratch::btrfs"})
+	assert.Nil(t, err)
+	assert.Equal(
+		t, []abstract.VolumeAttachmentSpec{
+			{Size: 100, Speed: volumespeed.HDD, MountPoint: "/data", Format: "ext4"},
+			{Size: 20, Speed: volumespeed.SSD, MountPoint: "/logs", Format: "xfs"},
+			{Size: 5, Speed: volumespeed.HDD, MountPoint: "/tmp/scratch", Format: "btrfs"},
+		}, volumes,
+	)
+
+	volumes, err = parseVolumes(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, volumes)
+
+	for _, bad := range []string{"100", "x:/data", "100:/data:fast", "100:/data:ssd:xfs:more"} {
+		_, err = parseVolumes([]string{bad})
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestDescribeNetworkInterfaces(t *testing.T) {
+	network := propsv1.NewHostNetwork()
+	network.DefaultNetworkID = "lan"
+	network.NetworksByID = map[string]string{"lan": "lan-net", "wan": "wan-net"}
+	network.IPv4Addresses = map[string]string{"lan": "10.0.0.5", "wan": "192.168.0.5"}
+	network.PublicIPv4 = "1.2.3.4"
+
+	// Without details from provider, the default interface comes first
+	nics := describeNetworkInterfaces(network, propsv1.NewHostNICs(), nil)
+	if assert.Equal(t, 2, len(nics)) {
+		assert.Equal(t, abstract.HostNetworkInterface{
+			NetworkID: "lan", NetworkName: "lan-net", PrivateIPs: []string{"10.0.0.5"}, PublicIP: "1.2.3.4", Default: true,
+		}, nics[0])
+		assert.Equal(t, abstract.HostNetworkInterface{
+			NetworkID: "wan", NetworkName: "wan-net", PrivateIPs: []string{"192.168.0.5"},
+		}, nics[1])
+	}
+
+	// The order and the names set at creation prevail; MAC addresses come from provider
+	order := propsv1.NewHostNICs()
+	order.ByIndex = []propsv1.HostNIC{{Index: 0, NetworkID: "wan", Name: "wan0"}, {Index: 1, NetworkID: "lan"}}
+	nics = describeNetworkInterfaces(network, order, []abstract.HostNetworkInterface{
+		{NetworkID: "lan", MACAddress: "fa:16:3e:00:00:01", PrivateIPs: []string{"10.0.0.5"}},
+		{NetworkID: "wan", MACAddress: "fa:16:3e:00:00:02", PrivateIPs: []string{"192.168.0.5"}},
+	})
+	if assert.Equal(t, 2, len(nics)) {
+		assert.Equal(t, "wan0", nics[0].Name)
+		assert.Equal(t, "fa:16:3e:00:00:02", nics[0].MACAddress)
+		assert.False(t, nics[0].Default)
+		assert.Equal(t, "lan", nics[1].NetworkID)
+		assert.True(t, nics[1].Default)
+		assert.Equal(t, "1.2.3.4", nics[1].PublicIP)
+	}
+
+	// A single interface is the default one
+	single := propsv1.NewHostNetwork()
+	single.IPv4Addresses = map[string]string{"lan": "10.0.0.5"}
+	nics = describeNetworkInterfaces(single, propsv1.NewHostNICs(), nil)
+	if assert.Equal(t, 1, len(nics)) {
+		assert.True(t, nics[0].Default)
+	}
+}
+
+func TestDecideFeatureEnsure(t *testing.T) {
+	recorded := propsv1.NewHostInstalledFeature()
+	recorded.Version = "1.0"
+
+	// already installed: nothing to do, whatever the metadata know about it
+	assert.Equal(t, FeatureAlreadyPresent, decideFeatureEnsure(true, recorded, "1.0"))
+	assert.Equal(t, FeatureAlreadyPresent, decideFeatureEnsure(true, nil, "1.0"))
+	assert.Equal(t, FeatureAlreadyPresent, decideFeatureEnsure(true, recorded, ""))
+
+	// not installed: installs
+	assert.Equal(t, FeatureInstalled, decideFeatureEnsure(false, nil, "1.0"))
+	assert.Equal(t, FeatureInstalled, decideFeatureEnsure(false, recorded, "1.0"))
+
+	// installed in another version: upgrades
+	assert.Equal(t, FeatureUpgraded, decideFeatureEnsure(true, recorded, "2.0"))
+}
+
+// fakeHostStateService reports the states of 'states' in turn, then that the host is not found
+type fakeHostStateService struct {
+	iaas.Service
+	states []hoststate.Enum
+	calls  int
+}
+
+func (f *fakeHostStateService) GetHostState(interface{}) (hoststate.Enum, error) {
+	f.calls++
+	if f.calls <= len(f.states) {
+		return f.states[f.calls-1], nil
+	}
+	return hoststate.UNKNOWN, fail.NotFoundError("host not found")
+}
+
+func TestWaitHostDeleted(t *testing.T) {
+	svc := &fakeHostStateService{states: []hoststate.Enum{hoststate.STARTED, hoststate.STOPPING}}
+	err := waitHostDeleted(context.Background(), svc, "host-id", time.Second, 10*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, svc.calls)
+}
+
+func TestWaitHostDeletedTimesOut(t *testing.T) {
+	states := make([]hoststate.Enum, 1000)
+	for i := range states {
+		states[i] = hoststate.STOPPING
+	}
+	svc := &fakeHostStateService{states: states}
+	err := waitHostDeleted(context.Background(), svc, "host-id", 50*time.Millisecond, 10*time.Millisecond)
+	assert.NotNil(t, err)
+	_, ok := err.(fail.ErrTimeout)
+	assert.True(t, ok, "expected fail.ErrTimeout, got %T", err)
+}
+
+func TestWaitHostDeletedStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc := &fakeHostStateService{states: []hoststate.Enum{hoststate.STOPPING}}
+	err := waitHostDeleted(ctx, svc, "host-id", time.Second, 10*time.Millisecond)
+	_, ok := err.(fail.ErrAborted)
+	assert.True(t, ok, "expected fail.ErrAborted, got %T", err)
+	assert.Equal(t, 0, svc.calls)
+}
+
+func TestCloneHostSettings(t *testing.T) {
+	sizing := propsv1.NewHostSizing()
+	// the sizing property records the ID of the template, Create expects its name
+	sizing.Template = "0e8a9c3f-5b27-4f0d-a1c6-2d4e7b9f1a35"
+	templates := map[string]string{sizing.Template: "s3.large.2"}
+	templateName := func(id string) (string, error) {
+		if name, ok := templates[id]; ok {
+			return name, nil
+		}
+		return "", fail.NotFoundError(fmt.Sprintf("template '%s' not found", id))
+	}
+	sizing.AllocatedSize = &propsv1.HostSize{Cores: 2, RAMSize: 4, DiskSize: 40}
+	network := propsv1.NewHostNetwork()
+	network.DefaultNetworkID = "lan"
+	network.NetworksByID = map[string]string{"lan": "lan-net", "wan": "wan-net", "adm": "adm-net"}
+	network.PublicIPv4 = "1.2.3.4"
+	sgs := propsv1.NewHostSecurityGroups()
+	sgs.ByName["web"] = &propsv1.HostSecurityGroup{Name: "web"}
+	sgs.ByName["admin"] = &propsv1.HostSecurityGroup{Name: "admin"}
+	sgs.ByName["net-sg"] = &propsv1.HostSecurityGroup{Name: "net-sg", FromNetwork: true}
+	features := propsv1.NewHostFeatures()
+	features.Installed["docker"] = &propsv1.HostInstalledFeature{HostContext: true}
+	features.Installed["kubernetes"] = &propsv1.HostInstalledFeature{HostContext: true, Requires: []string{"docker"}}
+	features.Installed["proxycache"] = &propsv1.HostInstalledFeature{RequiredBy: []string{"kubernetes"}}
+	description := propsv1.NewHostDescription()
+	description.Spot = true
+	system := propsv1.NewHostSystem()
+	system.Image = "Ubuntu 18.04"
+
+	settings, err := cloneHostSettings(
+		sizing, network, sgs, features, description, system, HostCloneOverrides{}, templateName,
+	)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "s3.large.2", settings.sizing)
+		assert.Equal(t, 40, settings.systemDiskSize)
+		assert.Equal(t, "lan-net,adm-net,wan-net", settings.net)
+		assert.True(t, settings.public)
+		assert.Equal(t, "Ubuntu 18.04", settings.los)
+		assert.Equal(t, []string{"admin", "web"}, settings.securityGroups)
+		assert.Equal(t, []string{"docker", "kubernetes"}, settings.features)
+		assert.True(t, settings.description.Spot)
+	}
+
+	// Without template, the allocated size is required; overrides prevail
+	sizing.Template = ""
+	settings, err = cloneHostSettings(
+		sizing, network, sgs, features, description, system, HostCloneOverrides{}, templateName,
+	)
+	if assert.Nil(t, err) {
+		assert.Equal(t, &abstract.SizingRequirements{
+			MinCores: 2, MaxCores: 2, MinGPU: -1, MinRAMSize: 4, MaxRAMSize: 4, MinDiskSize: 40,
+		}, settings.sizing)
+		assert.Equal(t, 0, settings.systemDiskSize)
+	}
+	sizing.AllocatedSize.GPUNumber = 1
+	settings, err = cloneHostSettings(
+		sizing, network, sgs, features, description, system, HostCloneOverrides{}, templateName,
+	)
+	if assert.Nil(t, err) {
+		assert.Equal(t, 1, settings.sizing.(*abstract.SizingRequirements).MinGPU)
+	}
+	settings, err = cloneHostSettings(
+		sizing, network, sgs, features, description, system,
+		HostCloneOverrides{Sizing: "s3.xlarge.2", Networks: "other-net"}, templateName,
+	)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "s3.xlarge.2", settings.sizing)
+		assert.Equal(t, "other-net", settings.net)
+	}
+
+	// A template that disappeared can't be used
+	sizing.Template = "7c1f0b2e-93d4-4a6b-8e5f-0a2b3c4d5e6f"
+	_, err = cloneHostSettings(sizing, network, sgs, features, description, system, HostCloneOverrides{}, templateName)
+	assert.NotNil(t, err)
+
+	// Gateways are not cloned
+	network.IsGateway = true
+	_, err = cloneHostSettings(sizing, network, sgs, features, description, system, HostCloneOverrides{}, templateName)
+	assert.NotNil(t, err)
+}
+
+func TestParseProcessList(t *testing.T) {
+	out := `    PID    PPID USER                                                             %CPU %MEM COMMAND
+      1       0 root                                                              0.0  0.1 systemd
+    812       1 postgres                                                          2.5 10.2 postgres
+    901     900 a-very-long-user-name-that-ps-would-have-truncated                  12.0  1.5 Web Content
+   1200       1 root                                                              bad  0.3 broken
+`
+	list, err := parseProcessList(out)
+	assert.Nil(t, err)
+	assert.Equal(t, []abstract.ProcessInfo{
+		{PID: 1, PPID: 0, User: "root", CPU: 0, Memory: 0.1, Command: "systemd"},
+		{PID: 812, PPID: 1, User: "postgres", CPU: 2.5, Memory: 10.2, Command: "postgres"},
+		{PID: 901, PPID: 900, User: "a-very-long-user-name-that-ps-would-have-truncated", CPU: 12, Memory: 1.5, Command: "Web Content"},
+	}, list)
+
+	_, err = parseProcessList("PID PPID USER %CPU %MEM COMMAND\ngarbage\n")
+	assert.NotNil(t, err)
+}
+
+func TestSortProcessList(t *testing.T) {
+	list := []abstract.ProcessInfo{
+		{PID: 1, CPU: 0, Memory: 0.1},
+		{PID: 812, CPU: 2.5, Memory: 10.2},
+		{PID: 901, CPU: 12, Memory: 1.5},
+		{PID: 3, CPU: 2.5, Memory: 0.5},
+	}
+	byCPU := sortProcessList(append([]abstract.ProcessInfo{}, list...), ProcessListOptions{})
+	assert.Equal(t, []int{901, 3, 812, 1}, []int{byCPU[0].PID, byCPU[1].PID, byCPU[2].PID, byCPU[3].PID})
+
+	byMemory := sortProcessList(append([]abstract.ProcessInfo{}, list...), ProcessListOptions{SortBy: "memory", Limit: 2})
+	if assert.Equal(t, 2, len(byMemory)) {
+		assert.Equal(t, 812, byMemory[0].PID)
+		assert.Equal(t, 901, byMemory[1].PID)
+	}
+}
+
+func TestChangeHostPasswordUpdatesMetadata(t *testing.T) {
+	host := &abstract.Host{ID: "id-web", Name: "web", Password: "Old-Passw0rd-123"}
+	var set, saved string
+	err := changeHostPassword(
+		host, "New-Passw0rd-456",
+		func(password string) error { set = password; return nil },
+		func(h *abstract.Host) error { saved = h.Password; return nil },
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "New-Passw0rd-456", set)
+	assert.Equal(t, "New-Passw0rd-456", saved)
+}
+
+func TestChangeHostPasswordKeepsMetadataOnFailure(t *testing.T) {
+	host := &abstract.Host{ID: "id-web", Name: "web", Password: "Old-Passw0rd-123"}
+	var saved bool
+	err := changeHostPassword(
+		host, "New-Passw0rd-456",
+		func(string) error { return fmt.Errorf("chpasswd failed: retcode=1") },
+		func(*abstract.Host) error { saved = true; return nil },
+	)
+	assert.NotNil(t, err)
+	assert.False(t, saved)
+	assert.Equal(t, "Old-Passw0rd-123", host.Password)
+	assert.NotContains(t, err.Error(), "New-Passw0rd-456")
+
+	err = changeHostPassword(
+		host, "New-Passw0rd-456",
+		func(string) error { return nil },
+		func(*abstract.Host) error { return fmt.Errorf("metadata write failed") },
+	)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "New-Passw0rd-456")
+}
+
+func TestCheckNotFrozen(t *testing.T) {
+	freeze := func(host *abstract.Host, frozen bool) {
+		err := host.Properties.LockForWrite(hostproperty.FreezeV1).ThenUse(
+			func(clonable data.Clonable) error {
+				clonable.(*propsv1.HostFreeze).Frozen = frozen
+				clonable.(*propsv1.HostFreeze).FrozenBy = "admin"
+				return nil
+			},
+		)
+		assert.Nil(t, err)
+	}
+	host := abstract.NewHost()
+	host.Name = "prod"
+	ctx := context.Background()
+
+	assert.Nil(t, checkNotFrozen(ctx, host))
+
+	freeze(host, true)
+	err := checkNotFrozen(ctx, host)
+	assert.NotNil(t, err)
+	_, ok := err.(fail.ErrNotAvailable)
+	assert.True(t, ok)
+
+	// the client asked to ignore the freeze
+	ignoreCtx := grpcmetadata.NewIncomingContext(ctx, grpcmetadata.Pairs("ignore-frozen", "true"))
+	assert.Nil(t, checkNotFrozen(ignoreCtx, host))
+	notIgnoreCtx := grpcmetadata.NewIncomingContext(ctx, grpcmetadata.Pairs("ignore-frozen", "false"))
+	assert.NotNil(t, checkNotFrozen(notIgnoreCtx, host))
+
+	// the administrator of the daemon allowed it
+	assert.Nil(t, os.Setenv(ignoreFrozenEnv, "1"))
+	assert.Nil(t, checkNotFrozen(ctx, host))
+	assert.Nil(t, os.Unsetenv(ignoreFrozenEnv))
+
+	freeze(host, false)
+	assert.Nil(t, checkNotFrozen(ctx, host))
+}
+
+func TestAdoptedHostAddresses(t *testing.T) {
+	network := &abstract.Network{ID: "net-id", Name: "net", CIDR: "192.168.1.0/24"}
+
+	hostNetwork := propsv1.NewHostNetwork()
+	hostNetwork.IPv4Addresses["net-id"] = "192.168.1.12"
+	hostNetwork.IPv6Addresses["net-id"] = "fd00::12"
+	ipv4, ipv6, err := adoptedHostAddresses("host", hostNetwork, network)
+	assert.Nil(t, err)
+	assert.Equal(t, "192.168.1.12", ipv4)
+	assert.Equal(t, "fd00::12", ipv6)
+
+	// the provider indexes the addresses by something else than the ID of the network
+	hostNetwork = propsv1.NewHostNetwork()
+	hostNetwork.IPv4Addresses["other"] = "10.0.0.5"
+	hostNetwork.IPv4Addresses["net"] = "192.168.1.13"
+	ipv4, ipv6, err = adoptedHostAddresses("host", hostNetwork, network)
+	assert.Nil(t, err)
+	assert.Equal(t, "192.168.1.13", ipv4)
+	assert.Equal(t, "", ipv6)
+}
+
+func TestAdoptedHostAddressesRejectsHostOutsideOfNetwork(t *testing.T) {
+	network := &abstract.Network{ID: "net-id", Name: "net", CIDR: "192.168.1.0/24"}
+
+	hostNetwork := propsv1.NewHostNetwork()
+	hostNetwork.IPv4Addresses["other-id"] = "10.0.0.5"
+	_, _, err := adoptedHostAddresses("host", hostNetwork, network)
+	assert.NotNil(t, err)
+	_, ok := err.(fail.ErrInvalidRequest)
+	assert.True(t, ok)
+	assert.Contains(t, err.Error(), "not attached to network 'net'")
+
+	_, _, err = adoptedHostAddresses("host", propsv1.NewHostNetwork(), network)
+	assert.NotNil(t, err)
+}
+
+func TestAuditHosts(t *testing.T) {
+	managed := []*abstract.Host{
+		{ID: "1", Name: "web"},
+		{ID: "2", Name: "db"},
+		{ID: "3", Name: "gone"},
+	}
+	existing := []*abstract.Host{
+		{ID: "1", Name: "web", LastState: hoststate.STARTED},
+		{ID: "2", Name: "db", LastState: hoststate.STOPPED},
+		{ID: "4", Name: "manual", LastState: hoststate.STARTED},
+		{ID: "5", Name: "deleted", LastState: hoststate.TERMINATED},
+	}
+
+	audit := auditHosts(managed, existing)
+	assert.Equal(t, []string{"gone"}, audit.Missing)
+	assert.Equal(t, []string{"manual"}, audit.Unmanaged)
+
+	audit = auditHosts(managed[:2], existing[:2])
+	assert.Empty(t, audit.Missing)
+	assert.Empty(t, audit.Unmanaged)
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
+)
+
+// HostEvent describes a change of state of a host
+type HostEvent struct {
+	Name      string
+	ID        string
+	OldState  hoststate.Enum
+	NewState  hoststate.Enum
+	Timestamp time.Time
+}
+
+// MarshalJSON encodes the event with the states by name, as sent to webhooks
+func (e HostEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			Name      string    `json:"name"`
+			ID        string    `json:"id"`
+			OldState  string    `json:"old_state"`
+			NewState  string    `json:"new_state"`
+			Timestamp time.Time `json:"timestamp"`
+		}{
+			Name:      e.Name,
+			ID:        e.ID,
+			OldState:  e.OldState.String(),
+			NewState:  e.NewState.String(),
+			Timestamp: e.Timestamp,
+		},
+	)
+}
+
+const (
+	// hostEventsQueueSize is the number of events waiting for delivery beyond which new events are dropped
+	hostEventsQueueSize = 256
+	// hostEventsWebhookTimeout is the time given to a webhook to answer
+	hostEventsWebhookTimeout = 10 * time.Second
+)
+
+type hostEventDelivery struct {
+	event   HostEvent
+	webhook string
+}
+
+// hostEvents dispatches the events to the subscribers and webhooks from a single goroutine, so publishing never
+// waits for them; lastStates keeps the last state published for each host, to publish only the changes
+var hostEvents = struct {
+	lock        sync.Mutex
+	subscribers map[int]func(HostEvent)
+	nextID      int
+	lastStates  map[string]hoststate.Enum
+	queue       chan hostEventDelivery
+	start       sync.Once
+	client      *http.Client
+}{
+	subscribers: map[int]func(HostEvent){},
+	lastStates:  map[string]hoststate.Enum{},
+	queue:       make(chan hostEventDelivery, hostEventsQueueSize),
+	client:      &http.Client{Timeout: hostEventsWebhookTimeout},
+}
+
+// SubscribeHostEvents registers 'fn' to be called on each change of state of a host; returns the function
+// unregistering it
+// The subscribers are called one after the other in the order of the events, from a goroutine dedicated to the
+// delivery; a slow subscriber delays the next deliveries, and events are dropped when too many are waiting.
+func SubscribeHostEvents(fn func(HostEvent)) (unsubscribe func()) {
+	if fn == nil {
+		return func() {}
+	}
+
+	hostEvents.lock.Lock()
+	defer hostEvents.lock.Unlock()
+
+	id := hostEvents.nextID
+	hostEvents.nextID++
+	hostEvents.subscribers[id] = fn
+	return func() {
+		hostEvents.lock.Lock()
+		defer hostEvents.lock.Unlock()
+		delete(hostEvents.subscribers, id)
+	}
+}
+
+// notifyHostState publishes a HostEvent if the state of the host is not 'newState' anymore; 'oldState' is the
+// state known by the caller, superseded by the last state published for the host, if any
+func (handler *HostHandler) notifyHostState(host *abstract.Host, oldState, newState hoststate.Enum) {
+	if host == nil {
+		return
+	}
+	publishHostEvent(host.ID, host.Name, oldState, newState, handler.service.GetHostEventsWebhook())
+}
+
+// publishHostEvent queues the event for delivery without waiting; the event is dropped with a warning if the queue
+// is full
+func publishHostEvent(id, name string, oldState, newState hoststate.Enum, webhook string) {
+	hostEvents.lock.Lock()
+	if last, ok := hostEvents.lastStates[id]; ok {
+		oldState = last
+	}
+	if oldState == newState {
+		hostEvents.lock.Unlock()
+		return
+	}
+	if newState == hoststate.TERMINATED {
+		delete(hostEvents.lastStates, id)
+	} else {
+		hostEvents.lastStates[id] = newState
+	}
+	hostEvents.lock.Unlock()
+
+	hostEvents.start.Do(func() { go dispatchHostEvents() })
+
+	event := HostEvent{Name: name, ID: id, OldState: oldState, NewState: newState, Timestamp: time.Now()}
+	select {
+	case hostEvents.queue <- hostEventDelivery{event: event, webhook: webhook}:
+	default:
+		logrus.Warnf(
+			"too many host events waiting for delivery, event of host '%s' (%s -> %s) dropped", name, oldState, newState,
+		)
+	}
+}
+
+// dispatchHostEvents delivers the queued events to the subscribers and the webhooks
+func dispatchHostEvents() {
+	for delivery := range hostEvents.queue {
+		hostEvents.lock.Lock()
+		subscribers := make([]func(HostEvent), 0, len(hostEvents.subscribers))
+		for _, fn := range hostEvents.subscribers {
+			subscribers = append(subscribers, fn)
+		}
+		hostEvents.lock.Unlock()
+
+		for _, fn := range subscribers {
+			callHostEventSubscriber(fn, delivery.event)
+		}
+		if delivery.webhook != "" {
+			if err := postHostEvent(delivery.webhook, delivery.event); err != nil {
+				logrus.Warnf("failed to send event of host '%s' to webhook: %v", delivery.event.Name, err)
+			}
+		}
+	}
+}
+
+// callHostEventSubscriber calls a subscriber, a panic in it doesn't stop the delivery of the events
+func callHostEventSubscriber(fn func(HostEvent), event HostEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("host event subscriber panicked: %v", r)
+		}
+	}()
+	fn(event)
+}
+
+// postHostEvent sends the event encoded in JSON to the webhook 'url'
+func postHostEvent(url string, event HostEvent) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := hostEvents.client.Post(url, "application/json", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered '%s'", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
+)
+
+func TestHostEvents(t *testing.T) {
+	received := make(chan HostEvent, 10)
+	unsubscribe := SubscribeHostEvents(func(e HostEvent) { received <- e })
+	defer unsubscribe()
+
+	posted := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				content, _ := ioutil.ReadAll(r.Body)
+				payload := map[string]interface{}{}
+				_ = json.Unmarshal(content, &payload)
+				posted <- payload
+			},
+		),
+	)
+	defer server.Close()
+
+	next := func() HostEvent {
+		select {
+		case e := <-received:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no host event received")
+			return HostEvent{}
+		}
+	}
+
+	publishHostEvent("id-1", "host-1", hoststate.UNKNOWN, hoststate.STARTED, "")
+	e := next()
+	assert.Equal(t, "host-1", e.Name)
+	assert.Equal(t, hoststate.UNKNOWN, e.OldState)
+	assert.Equal(t, hoststate.STARTED, e.NewState)
+
+	// the state already published is not published again, even if the caller knows an older one
+	publishHostEvent("id-1", "host-1", hoststate.STOPPED, hoststate.STARTED, "")
+	publishHostEvent("id-1", "host-1", hoststate.STARTED, hoststate.STOPPED, server.URL)
+	e = next()
+	assert.Equal(t, hoststate.STARTED, e.OldState)
+	assert.Equal(t, hoststate.STOPPED, e.NewState)
+
+	select {
+	case payload := <-posted:
+		assert.Equal(t, "host-1", payload["name"])
+		assert.Equal(t, "id-1", payload["id"])
+		assert.Equal(t, "STARTED", payload["old_state"])
+		assert.Equal(t, "STOPPED", payload["new_state"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no host event posted to webhook")
+	}
+
+	unsubscribe()
+	publishHostEvent("id-1", "host-1", hoststate.STOPPED, hoststate.TERMINATED, "")
+	select {
+	case e = <-received:
+		t.Fatalf("unexpected event after unsubscribe: %v", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+	hostEvents.lock.Lock()
+	_, ok := hostEvents.lastStates["id-1"]
+	hostEvents.lock.Unlock()
+	require.False(t, ok)
+}
@@ -19,9 +19,12 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/CS-SI/SafeScale/lib/utils/debug"
 
@@ -39,6 +42,7 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/networkproperty"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/providers"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks/openstack"
 	"github.com/CS-SI/SafeScale/lib/server/install"
 	"github.com/CS-SI/SafeScale/lib/server/metadata"
@@ -58,11 +62,19 @@ import (
 
 // NetworkAPI defines API to manage networks
 type NetworkAPI interface {
-	Create(context.Context, string, string, ipversion.Enum, abstract.SizingRequirements, string, string, bool, string, bool) (*abstract.Network, error)
+	Create(context.Context, string, string, ipversion.Enum, abstract.SizingRequirements, string, string, bool, string, bool, []string) (*abstract.Network, error)
 	List(context.Context, bool) ([]*abstract.Network, error)
 	Inspect(context.Context, string) (*abstract.Network, error)
-	Delete(context.Context, string) error
+	InspectTopology(context.Context, string) (*abstract.NetworkTopology, error)
+	Delete(context.Context, string, time.Duration) error
 	Destroy(context.Context, string) error
+	SetJumpHosts(context.Context, string, []string) error
+	EnableHA(context.Context, string, string) error
+	UpdateDNSServers(context.Context, string, []string, bool) ([]string, error)
+	AllocateSubnetCIDR(context.Context, string, int) (string, error)
+	AddRoute(context.Context, string, string, string) error
+	RemoveRoute(context.Context, string, string) error
+	ListRoutes(context.Context, string) ([]abstract.NetworkRoute, error)
 }
 
 // NetworkHandler an implementation of NetworkAPI
@@ -79,11 +91,13 @@ func NewNetworkHandler(svc iaas.Service) NetworkAPI {
 }
 
 // Create creates a network
+// If dnsServers is empty, the hosts of the network use the DNS servers defined in tenant configuration or the
+// provider defaults
 func (handler *NetworkHandler) Create(
 	ctx context.Context,
 	name string, cidr string, ipVersion ipversion.Enum,
 	sizing abstract.SizingRequirements, theos string, gwname string,
-	failover bool, domain string, keeponfailure bool,
+	failover bool, domain string, keeponfailure bool, dnsServers []string,
 ) (network *abstract.Network, err error) {
 	if handler == nil {
 		return nil, fail.InvalidInstanceError()
@@ -94,6 +108,10 @@ func (handler *NetworkHandler) Create(
 	if failover && gwname != "" {
 		return nil, fail.InvalidParameterError("gwname", "cannot be set if failover is set")
 	}
+	dnsServers, err = utils.NormalizeDNSServers(dnsServers)
+	if err != nil {
+		return nil, err
+	}
 
 	tracer := debug.NewTracer(
 		nil,
@@ -141,14 +159,21 @@ func (handler *NetworkHandler) Create(
 		return nil, fmt.Errorf("cannot create such a network, CIDR must be not routable; please provide an appropriate CIDR (RFC1918)")
 	}
 
+	tags, err := mergeTags(handler.service.GetMandatoryTags(), nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the network
 	logrus.Debugf("Creating network '%s' ...", name)
 	network, err = handler.service.CreateNetwork(
 		abstract.NetworkRequest{
-			Name:      name,
-			IPVersion: ipVersion,
-			CIDR:      cidr,
-			Domain:    domain,
+			Name:       name,
+			IPVersion:  ipVersion,
+			CIDR:       cidr,
+			DNSServers: dnsServers,
+			Domain:     domain,
+			Tags:       tags,
 		},
 	)
 	if err != nil {
@@ -160,6 +185,7 @@ func (handler *NetworkHandler) Create(
 		}
 	}
 	network.Domain = domain
+	network.DNSServers = dnsServers
 
 	newNetwork := network
 	// Starting from here, delete network if exiting with error
@@ -187,10 +213,10 @@ func (handler *NetworkHandler) Create(
 		}
 	}()
 
-	caps := handler.service.GetCapabilities()
-	if failover && caps.PrivateVirtualIP {
+	supportsVIP := handler.service.SupportsFeature(providers.VIP)
+	if failover && supportsVIP {
 		logrus.Infof("Provider support private Virtual IP, honoring the failover setup for gateways.")
-	} else if failover && !caps.PrivateVirtualIP {
+	} else if failover && !supportsVIP {
 		logrus.Warningf("Provider doesn't support private Virtual IP, cannot set up high availability of network default route.")
 		failover = false
 	}
@@ -281,7 +307,7 @@ func (handler *NetworkHandler) Create(
 		msg += ")"
 		logrus.Infof(msg)
 	} else {
-		return nil, fmt.Errorf("error creating network: no host template matching requirements for gateway")
+		return nil, fail.NotFoundError("error creating network: no host template matching requirements for gateway")
 	}
 	img, err := handler.service.SearchImage(theos)
 	if err != nil {
@@ -316,6 +342,11 @@ func (handler *NetworkHandler) Create(
 		TemplateID:        template.ID,
 		CIDR:              network.CIDR,
 	}
+	// Keeps the gateways of a failover pair on distinct physical hosts, to not lose both on a single hardware failure
+	if failover && handler.service.SupportsFeature(providers.AffinityGroups) {
+		gwRequest.AffinityGroup = "gw-" + network.Name
+		gwRequest.AntiAffinity = true
+	}
 
 	var (
 		primaryGateway, secondaryGateway   *abstract.Host
@@ -841,6 +872,7 @@ func (handler *NetworkHandler) waitForInstallPhase1OnGateway(
 	}
 
 	logrus.Infof("SSH service of gateway '%s' started.", gw.Name)
+	sshHandler.recordHostKey(gw, ssh)
 
 	if out != "" {
 		logrus.Infof("received output from phase 1: %s", out)
@@ -996,11 +1028,12 @@ func (handler *NetworkHandler) List(ctx context.Context, all bool) (netList []*a
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
+	svc := handler.service.ReadOnlyView()
 	if all {
-		return handler.service.ListNetworks()
+		return svc.ListNetworks()
 	}
 
-	mn, err := metadata.NewNetwork(handler.service)
+	mn, err := metadata.NewNetwork(svc)
 	if err != nil {
 		return nil, err
 	}
@@ -1020,7 +1053,7 @@ func (handler *NetworkHandler) List(ctx context.Context, all bool) (netList []*a
 
 // Inspect returns the network identified by ref, ref can be the name or the id
 func (handler *NetworkHandler) Inspect(ctx context.Context, ref string) (network *abstract.Network, err error) {
-	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().WithContext(ctx).GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
@@ -1032,12 +1065,235 @@ func (handler *NetworkHandler) Inspect(ctx context.Context, ref string) (network
 	return mn.Get()
 }
 
-// Delete deletes network referenced by ref
-func (handler *NetworkHandler) Delete(ctx context.Context, ref string) (err error) {
+// InspectTopology returns the topology of the network identified by ref: its CIDR, its gateways, the hosts attached
+// to it and its subnets (the networks declared as its subnets and the subnetworks of the provider network), described
+// the same way. Everything comes from metadata, except the public IP of gateways not recorded there.
+// A gateway whose metadata are missing is reported as such, with its ID only.
+// The topology returned shares nothing with metadata.
+func (handler *NetworkHandler) InspectTopology(ctx context.Context, ref string) (topology *abstract.NetworkTopology, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
 	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return nil, err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	topology, err = networkTopology(network, handler.describeGateway)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	err = mn.Browse(
+		func(other *abstract.Network) error {
+			if !other.Subnet || other.Parent != network.ID {
+				return nil
+			}
+			subnet, err := networkTopology(other, handler.describeGateway)
+			if err != nil {
+				return err
+			}
+			topology.Subnets = append(topology.Subnets, subnet)
+			known[other.ID], known[other.CIDR] = true, true
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, sn := range network.Subnetworks {
+		if !known[sn.ID] && !known[sn.CIDR] {
+			topology.Subnets = append(topology.Subnets, &abstract.NetworkTopology{ID: sn.ID, CIDR: sn.CIDR})
+		}
+	}
+	sort.Slice(topology.Subnets, func(i, j int) bool {
+		return topology.Subnets[i].CIDR < topology.Subnets[j].CIDR
+	})
+	return topology, nil
+}
+
+// networkTopology describes network, its subnets excluded; 'gateway' describes the gateway of the network identified
+// by its ID, and returns nil if the metadata of the gateway are missing
+func networkTopology(
+	network *abstract.Network, gateway func(networkID, id string) (*abstract.NetworkTopologyGateway, error),
+) (*abstract.NetworkTopology, error) {
+	topology := &abstract.NetworkTopology{
+		ID:   network.ID,
+		Name: network.Name,
+		CIDR: network.CIDR,
+	}
+
+	gatewayIDs := map[string]bool{}
+	for i, id := range []string{network.GatewayID, network.SecondaryGatewayID} {
+		if id == "" {
+			continue
+		}
+		gatewayIDs[id] = true
+		gw, err := gateway(network.ID, id)
+		if err != nil {
+			return nil, err
+		}
+		if gw == nil {
+			gw = &abstract.NetworkTopologyGateway{ID: id, Missing: true}
+		}
+		gw.Primary = i == 0
+		topology.Gateways = append(topology.Gateways, *gw)
+	}
+
+	err := network.Properties.LockForRead(networkproperty.HostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for id, name := range clonable.(*propsv1.NetworkHosts).ByID {
+				if !gatewayIDs[id] {
+					topology.Hosts = append(topology.Hosts, name)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(topology.Hosts)
+	return topology, nil
+}
+
+// describeGateway describes the gateway identified by id of the network identified by networkID from its metadata,
+// asking the provider for its public IP if not recorded; returns nil if the metadata of the gateway are missing
+func (handler *NetworkHandler) describeGateway(networkID, id string) (*abstract.NetworkTopologyGateway, error) {
+	mh, err := metadata.LoadHost(handler.service, id)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if mh == nil {
+		return nil, nil
+	}
+	host, err := mh.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	gw := &abstract.NetworkTopologyGateway{
+		ID:        host.ID,
+		Name:      host.Name,
+		PrivateIP: host.GetPrivateIP(),
+		PublicIP:  host.GetPublicIP(),
+	}
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			if ip, ok := clonable.(*propsv1.HostNetwork).IPv4Addresses[networkID]; ok {
+				gw.PrivateIP = ip
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if gw.PublicIP == "" {
+		current, err := handler.service.InspectHost(host.ID)
+		if err != nil {
+			logrus.Debugf("failed to get public IP of gateway '%s' from provider: %v", host.Name, err)
+		} else {
+			gw.PublicIP = current.GetPublicIP()
+		}
+	}
+	return gw, nil
+}
+
+// AllocateSubnetCIDR returns the first free CIDR of size 'prefixLen' in the address space of the network identified
+// by ref, considering the subnetworks of the network and the networks declared as its subnets
+// Returns fail.ErrOverflow if the network has no room left for such a CIDR
+func (handler *NetworkHandler) AllocateSubnetCIDR(ctx context.Context, ref string, prefixLen int) (cidr string, err error) {
+	if handler == nil {
+		return "", fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return "", fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %d)", ref, prefixLen), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	network, err := handler.Inspect(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	var used []string
+	for _, sn := range network.Subnetworks {
+		if sn.CIDR != "" {
+			used = append(used, sn.CIDR)
+		}
+	}
+	mn, err := metadata.NewNetwork(handler.service)
+	if err != nil {
+		return "", err
+	}
+	err = mn.Browse(
+		func(other *abstract.Network) error {
+			if other.Subnet && other.Parent == network.ID && other.CIDR != "" {
+				used = append(used, other.CIDR)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.AllocateCIDR(network.CIDR, used, prefixLen)
+}
+
+// listAttachedHosts returns the names of the hosts attached to network according to metadata and still existing
+func (handler *NetworkHandler) listAttachedHosts(network *abstract.Network) ([]string, error) {
+	var list []string
+	err := network.Properties.LockForRead(networkproperty.HostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for k := range clonable.(*propsv1.NetworkHosts).ByName {
+				rechost, err := handler.service.GetHostByName(k)
+				if err == nil {
+					if rechost.LastState != hoststate.TERMINATED {
+						list = append(list, k)
+					}
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// Delete deletes network referenced by ref
+// If hosts are still attached to the network, waits up to waitForHosts for their deletion before failing with an error
+// listing them
+func (handler *NetworkHandler) Delete(ctx context.Context, ref string, waitForHosts time.Duration) (err error) {
+	tracer := debug.NewTracer(
+		nil, fmt.Sprintf("('%s', %s)", ref, temporal.FormatDuration(waitForHosts)), true,
+	).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
 	mn, err := metadata.LoadNetwork(handler.service, ref)
 	if err != nil {
 		if _, ok := err.(fail.ErrNotFound); !ok {
@@ -1063,44 +1319,68 @@ func (handler *NetworkHandler) Delete(ctx context.Context, ref string) (err erro
 		return err
 	}
 
-	// Check if hosts are still attached to network according to metadata
-	var errorMsg string
-	err = network.Properties.LockForRead(networkproperty.HostsV1).ThenUse(
-		func(clonable data.Clonable) error {
-			networkHostsV1 := clonable.(*propsv1.NetworkHosts)
-			hostsLen := len(networkHostsV1.ByName)
-			if hostsLen > 0 {
-				list := make([]string, 0, hostsLen)
-				for k := range networkHostsV1.ByName {
-					rechost, err := handler.service.GetHostByName(k)
-					if err == nil {
-						if rechost.LastState != hoststate.TERMINATED {
-							list = append(list, k)
-						}
-					}
+	// Check if hosts are still attached to network according to metadata, waiting for their deletion if asked to
+	blocking, err := handler.listAttachedHosts(network)
+	if err != nil {
+		return err
+	}
+	if len(blocking) > 0 && waitForHosts > 0 {
+		logrus.Infof(
+			"waiting up to %s for the deletion of the hosts attached to network '%s': %s",
+			temporal.FormatDuration(waitForHosts), network.Name, strings.Join(blocking, ", "),
+		)
+		retryErr := retry.WhileUnsuccessfulDelay5Seconds(
+			func() error {
+				// hosts are removed from network metadata when deleted
+				mn, err = metadata.LoadNetwork(handler.service, network.ID)
+				if err != nil {
+					return retry.AbortedError("", err)
 				}
-				if len(list) == 0 {
-					return nil
+				network, err = mn.Get()
+				if err != nil {
+					return retry.AbortedError("", err)
+				}
+				blocking, err = handler.listAttachedHosts(network)
+				if err != nil {
+					return retry.AbortedError("", err)
 				}
-				verb := "are"
-				if hostsLen == 1 {
-					verb = "is"
+				if len(blocking) > 0 {
+					return fail.NotAvailableError(fmt.Sprintf("%d host(s) still attached", len(blocking)))
 				}
-				errorMsg = fmt.Sprintf(
-					"cannot delete network '%s': %d host%s %s still attached to it: %s",
-					network.Name, hostsLen, utils.Plural(hostsLen), verb, strings.Join(list, ", "),
-				)
-				return abstract.ResourceNotAvailableError("network", network.Name)
+				return nil
+			},
+			temporal.EffectiveTimeout(ctx, waitForHosts),
+		)
+		if retryErr != nil {
+			if _, ok := retryErr.(retry.ErrAborted); ok {
+				return fail.Cause(retryErr)
 			}
-			return nil
-		},
-	)
-	if err != nil {
-		if _, ok := err.(fail.ErrNotAvailable); ok {
-			return fmt.Errorf(errorMsg)
 		}
+	}
+	if len(blocking) > 0 {
+		verb := "are"
+		if len(blocking) == 1 {
+			verb = "is"
+		}
+		return fail.NotAvailableError(
+			fmt.Sprintf(
+				"cannot delete network '%s': %d host%s %s still attached to it: %s", network.Name, len(blocking),
+				utils.Plural(len(blocking)), verb, strings.Join(blocking, ", "),
+			),
+		)
+	}
+
+	// Remove the static routes first, some providers refusing to delete a network whose routes use it
+	routes, err := networkRoutes(network)
+	if err != nil {
 		return err
 	}
+	if len(routes) > 0 {
+		err = handler.service.SetNetworkRoutes(network.ID, nil)
+		if err != nil {
+			logrus.Warnf("failed to remove static routes of network '%s': %v", network.Name, err)
+		}
+	}
 
 	// Delete gateway(s)
 	if network.GatewayID != "" {
@@ -1438,3 +1718,685 @@ func (handler *NetworkHandler) Destroy(ctx context.Context, ref string) (err err
 
 	return nil
 }
+
+// SetJumpHosts defines the ordered list of hosts to cross to reach the gateway of the network referenced by ref
+// The first host of the list is the one directly reachable by the daemon; an empty list removes the jump hosts
+func (handler *NetworkHandler) SetJumpHosts(ctx context.Context, ref string, jumpHosts []string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, jumpHosts), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return err
+	}
+
+	// Jump hosts are stored by ID, to survive renaming
+	hostIDs := make([]string, 0, len(jumpHosts))
+	for _, v := range jumpHosts {
+		mh, err := metadata.LoadHost(handler.service, v)
+		if err != nil {
+			return err
+		}
+		host, err := mh.Get()
+		if err != nil {
+			return err
+		}
+		if host.ID == network.GatewayID || host.ID == network.SecondaryGatewayID {
+			return fail.InvalidRequestError(fmt.Sprintf("host '%s' is a gateway of network '%s' and cannot be a jump host of it", v, network.Name))
+		}
+		hostIDs = append(hostIDs, host.ID)
+	}
+
+	err = network.Properties.LockForWrite(networkproperty.JumpHostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			clonable.(*propsv1.NetworkJumpHosts).Hosts = hostIDs
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return mn.Write()
+}
+
+// AddRoute injects in the network 'ref' a static route sending the traffic for 'destination' (in CIDR notation) to
+// 'nextHop', which must be an IP address inside the network
+// Returns fail.ErrDuplicate if the network already has a route for 'destination', fail.ErrNotImplemented if the
+// provider cannot manage routes
+func (handler *NetworkHandler) AddRoute(ctx context.Context, ref string, destination string, nextHop string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s', '%s')", ref, destination, nextHop), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return err
+	}
+
+	route, err := validateNetworkRoute(network.CIDR, destination, nextHop)
+	if err != nil {
+		return err
+	}
+	routes, err := networkRoutes(network)
+	if err != nil {
+		return err
+	}
+	for _, r := range routes {
+		if r.Destination == route.Destination {
+			return fail.DuplicateError(fmt.Sprintf("network '%s' already has a route to '%s' (through '%s')", network.Name, r.Destination, r.NextHop))
+		}
+	}
+
+	return handler.applyRoutes(mn, network, append(routes, route))
+}
+
+// RemoveRoute removes from the network 'ref' the static route to 'destination'
+// Returns fail.ErrNotFound if the network has no route to 'destination'
+func (handler *NetworkHandler) RemoveRoute(ctx context.Context, ref string, destination string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, destination), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	_, ipnet, err := net.ParseCIDR(destination)
+	if err != nil {
+		return fail.InvalidParameterError("destination", fmt.Sprintf("'%s' is not a valid CIDR", destination))
+	}
+
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return err
+	}
+
+	routes, err := networkRoutes(network)
+	if err != nil {
+		return err
+	}
+	kept := make([]abstract.NetworkRoute, 0, len(routes))
+	for _, r := range routes {
+		if r.Destination != ipnet.String() {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(routes) {
+		return fail.NotFoundError(fmt.Sprintf("network '%s' has no route to '%s'", network.Name, destination))
+	}
+
+	return handler.applyRoutes(mn, network, kept)
+}
+
+// ListRoutes returns the static routes of the network 'ref', as recorded in its metadata
+func (handler *NetworkHandler) ListRoutes(ctx context.Context, ref string) (routes []abstract.NetworkRoute, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return nil, err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return nil, err
+	}
+	return networkRoutes(network)
+}
+
+// applyRoutes sets 'routes' as the whole list of static routes of the network on provider side, then records them
+// in metadata; sending the whole list each time reconciles the provider with the metadata
+func (handler *NetworkHandler) applyRoutes(mn *metadata.Network, network *abstract.Network, routes []abstract.NetworkRoute) error {
+	err := handler.service.SetNetworkRoutes(network.ID, routes)
+	if err != nil {
+		return err
+	}
+
+	err = network.Properties.LockForWrite(networkproperty.RoutesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			networkRoutesV1 := clonable.(*propsv1.NetworkRoutes)
+			networkRoutesV1.Routes = make([]propsv1.NetworkRoute, 0, len(routes))
+			for _, r := range routes {
+				networkRoutesV1.Routes = append(networkRoutesV1.Routes, propsv1.NetworkRoute{Destination: r.Destination, NextHop: r.NextHop})
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return mn.Write()
+}
+
+// networkRoutes returns the static routes of the network recorded in its properties
+func networkRoutes(network *abstract.Network) ([]abstract.NetworkRoute, error) {
+	var routes []abstract.NetworkRoute
+	err := network.Properties.LockForRead(networkproperty.RoutesV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for _, r := range clonable.(*propsv1.NetworkRoutes).Routes {
+				routes = append(routes, abstract.NetworkRoute{Destination: r.Destination, NextHop: r.NextHop})
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// validateNetworkRoute checks that 'destination' is a valid CIDR and 'nextHop' an IP address inside the network 'cidr'
+// The destination is returned normalized (ie "10.1.2.3/16" becomes "10.1.0.0/16")
+func validateNetworkRoute(cidr string, destination string, nextHop string) (abstract.NetworkRoute, error) {
+	_, dst, err := net.ParseCIDR(destination)
+	if err != nil {
+		return abstract.NetworkRoute{}, fail.InvalidParameterError("destination", fmt.Sprintf("'%s' is not a valid CIDR", destination))
+	}
+	ip := net.ParseIP(nextHop)
+	if ip == nil {
+		return abstract.NetworkRoute{}, fail.InvalidParameterError("nextHop", fmt.Sprintf("'%s' is not a valid IP address", nextHop))
+	}
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return abstract.NetworkRoute{}, fail.InconsistentError(fmt.Sprintf("invalid CIDR '%s' of network: %v", cidr, err))
+	}
+	if !subnet.Contains(ip) {
+		return abstract.NetworkRoute{}, fail.InvalidParameterError("nextHop", fmt.Sprintf("'%s' is not inside the network '%s'", nextHop, cidr))
+	}
+	return abstract.NetworkRoute{Destination: dst.String(), NextHop: ip.String()}, nil
+}
+
+// EnableHA adds a secondary gateway to the network 'ref' created with a single gateway; both gateways then share a VIP
+// used as default route by the hosts of the network
+// Returns fail.ErrAlteredNothing if the network already has a secondary gateway, fail.ErrNotAvailable if the provider
+// doesn't support VIP
+func (handler *NetworkHandler) EnableHA(ctx context.Context, ref string, theos string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	if theos == "" {
+		return fail.InvalidParameterError("theos", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, theos), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return err
+	}
+	if network.SecondaryGatewayID != "" {
+		return fail.AlteredNothingError(fmt.Sprintf("network '%s' already has a secondary gateway", network.Name))
+	}
+	if network.GatewayID == "" {
+		return fail.InvalidRequestError(fmt.Sprintf("network '%s' has no gateway", network.Name))
+	}
+	if !handler.service.SupportsFeature(providers.VIP) {
+		return fail.NotAvailableError(
+			fmt.Sprintf("cannot enable HA on network '%s': provider doesn't support private Virtual IP", network.Name),
+		)
+	}
+
+	mgw, err := metadata.LoadHost(handler.service, network.GatewayID)
+	if err != nil {
+		return err
+	}
+	primaryGateway, err := mgw.Get()
+	if err != nil {
+		return err
+	}
+
+	// The secondary gateway uses the same template as the primary one
+	var (
+		templateID string
+		sizing     abstract.SizingRequirements
+	)
+	err = primaryGateway.Properties.LockForRead(hostproperty.SizingV1).ThenUse(
+		func(clonable data.Clonable) error {
+			gwSizingV1 := clonable.(*propsv1.HostSizing)
+			templateID = gwSizingV1.Template
+			if rs := gwSizingV1.RequestedSize; rs != nil {
+				sizing = abstract.SizingRequirements{
+					MinCores:    rs.Cores,
+					MinRAMSize:  rs.RAMSize,
+					MinDiskSize: rs.DiskSize,
+					MinGPU:      rs.GPUNumber,
+					MinFreq:     rs.CPUFreq,
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if templateID == "" {
+		return fail.InconsistentError(fmt.Sprintf("failed to find template of gateway '%s'", primaryGateway.Name))
+	}
+	img, err := handler.service.SearchImage(theos)
+	if err != nil {
+		return err
+	}
+
+	// Creates the VIP and binds the primary gateway to it
+	vip, err := handler.service.CreateVIP(network.ID, fmt.Sprintf("for gateways of network %s", network.Name))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if derr := handler.service.DeleteVIP(vip); derr != nil {
+				logrus.Errorf("Cleaning up on failure, failed to delete VIP: %+v", derr)
+				err = fail.AddConsequence(err, derr)
+			}
+			network.VIP = nil
+		}
+	}()
+	network.VIP = vip
+
+	err = handler.service.BindHostToVIP(vip, primaryGateway.ID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if derr := handler.unbindHostFromVIP(vip, primaryGateway); derr != nil {
+				err = fail.AddConsequence(err, derr)
+			}
+		}
+	}()
+
+	// Creates the secondary gateway
+	domain := strings.Trim(network.Domain, ".")
+	if domain != "" {
+		domain = "." + domain
+	}
+	request := abstract.GatewayRequest{
+		ImageID:           img.ID,
+		Network:           network,
+		OriginalOsRequest: theos,
+		TemplateID:        templateID,
+		CIDR:              network.CIDR,
+		Name:              "gw2-" + network.Name + domain,
+	}
+	request.KeyPair, err = abstract.NewKeyPair(request.Name)
+	if err != nil {
+		return err
+	}
+	task, err := concurrency.NewTaskWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := handler.createGateway(
+		task, data.Map{
+			"request": request,
+			"sizing":  sizing,
+			"primary": false,
+			"nokeep":  true,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	secondaryGateway := result.(data.Map)["host"].(*abstract.Host)
+	secondaryUserdata := result.(data.Map)["userdata"].(*userdata.Content)
+	secondaryMetadata := result.(data.Map)["metadata"].(*metadata.Gateway)
+	if domain != "" {
+		secondaryUserdata.HostName = request.Name
+	}
+	defer func() {
+		if err != nil {
+			if derr := handler.deleteGateway(secondaryGateway); derr != nil {
+				err = fail.AddConsequence(err, derr)
+			}
+			if derr := handler.deleteGatewayMetadata(secondaryMetadata); derr != nil {
+				err = fail.AddConsequence(err, derr)
+			}
+		}
+	}()
+
+	out, err := handler.waitForInstallPhase1OnGateway(task, secondaryGateway)
+	if err != nil {
+		return err
+	}
+	if outCast, ok := out.(string); ok {
+		compareOsWithRequestedOs(outCast, theos)
+	}
+
+	keepalivedPassword, err := utils.GeneratePassword(16)
+	if err != nil {
+		return fmt.Errorf("failed to generate keepalived password: %v", err)
+	}
+	primaryIP := primaryGateway.GetPrivateIP()
+	secondaryUserdata.PrimaryGatewayPrivateIP = primaryIP
+	secondaryUserdata.PrimaryGatewayPublicIP = primaryGateway.GetPublicIP()
+	secondaryUserdata.SecondaryGatewayPrivateIP = secondaryGateway.GetPrivateIP()
+	secondaryUserdata.SecondaryGatewayPublicIP = secondaryGateway.GetPublicIP()
+	secondaryUserdata.GatewayHAKeepalivedPassword = keepalivedPassword
+
+	_, err = handler.installPhase2OnGateway(
+		task, data.Map{
+			"host":     secondaryGateway,
+			"userdata": secondaryUserdata,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// keepalived of the primary gateway has been configured without peer, it has to know the secondary gateway now
+	script := keepalivedPrimaryScript(
+		primaryIP, secondaryGateway.GetPrivateIP(), vip.PrivateIP, network.CIDR, keepalivedPassword,
+	)
+	err = handler.runScriptOnGateway(ctx, primaryGateway, "keepalived_ha.sh", script)
+	if err != nil {
+		return err
+	}
+
+	network.SecondaryGatewayID = secondaryGateway.ID
+	err = mn.Write()
+	if err != nil {
+		return err
+	}
+
+	// From here, the network is HA; failing to update the default route of a host is only reported
+	handler.useVIPAsDefaultRoute(ctx, network, primaryIP, vip.PrivateIP)
+	return nil
+}
+
+// UpdateDNSServers replaces the DNS servers of the network 'ref': the DNS servers distributed by the DHCP of the
+// network are updated on provider side (when supported), then the new DNS servers are pushed on the hosts of the network
+// Hosts not started are skipped with a warning. If 'dryRun' is true, nothing is changed.
+// Returns the names of the hosts updated (or that would be updated in dry-run); failures on hosts don't stop the
+// update of the other ones and are returned together in a fail.ErrList
+func (handler *NetworkHandler) UpdateDNSServers(ctx context.Context, ref string, dnsServers []string, dryRun bool) (hostNames []string, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if ref == "" {
+		return nil, fail.InvalidParameterError("ref", "cannot be empty string")
+	}
+	dnsServers, err = utils.NormalizeDNSServers(dnsServers)
+	if err != nil {
+		return nil, err
+	}
+	if len(dnsServers) == 0 {
+		return nil, fail.InvalidParameterError("dnsServers", "cannot be empty")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v, %v)", ref, dnsServers, dryRun), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	mn, err := metadata.LoadNetwork(handler.service, ref)
+	if err != nil {
+		return nil, err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := map[string]string{}
+	err = network.Properties.LockForRead(networkproperty.HostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for id, name := range clonable.(*propsv1.NetworkHosts).ByID {
+				hosts[id] = name
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only started hosts can be reached; the other ones will get the new DNS servers from DHCP at next start
+	// if the provider supports it
+	errs := map[string]error{}
+	targets := map[string]string{}
+	for id, name := range hosts {
+		state, err := handler.service.GetHostState(id)
+		if err != nil {
+			errs["host "+name] = fmt.Errorf("failed to get state: %v", err)
+			continue
+		}
+		if state != hoststate.STARTED {
+			logrus.Warnf("host '%s' of network '%s' is in state '%s', its DNS servers are left unchanged", name, network.Name, state.String())
+			continue
+		}
+		targets[id] = name
+	}
+
+	if dryRun {
+		for _, name := range targets {
+			hostNames = append(hostNames, name)
+		}
+		sort.Strings(hostNames)
+		return hostNames, fail.ErrListErrorWithContext(errs)
+	}
+
+	err = handler.service.UpdateNetworkDNSServers(network.ID, dnsServers)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotImplemented); !ok {
+			return nil, err
+		}
+		logrus.Warnf("provider cannot update the DNS servers of network '%s', only existing hosts are updated", network.Name)
+	}
+
+	network.DNSServers = dnsServers
+	err = mn.Write()
+	if err != nil {
+		return nil, err
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	cmd := dnsServersCommand(dnsServers)
+	for _, name := range targets {
+		retcode, _, stderr, err := sshHandler.Run(ctx, name, cmd, outputs.COLLECT)
+		if err == nil && retcode != 0 {
+			err = fmt.Errorf("retcode=%d: %s", retcode, stderr)
+		}
+		if err != nil {
+			errs["host "+name] = fmt.Errorf("failed to update DNS servers: %v", err)
+			continue
+		}
+		hostNames = append(hostNames, name)
+	}
+	sort.Strings(hostNames)
+	return hostNames, fail.ErrListErrorWithContext(errs)
+}
+
+// runScriptOnGateway uploads 'script' on the gateway and executes it with sudo
+func (handler *NetworkHandler) runScriptOnGateway(ctx context.Context, gw *abstract.Host, name string, script string) error {
+	pbHost, err := safescaleutils.ToPBHost(gw)
+	if err != nil {
+		return err
+	}
+	path := utils.TempFolder + "/" + name
+	err = install.UploadStringToRemoteFile(script, pbHost, path, "", "", "")
+	if err != nil {
+		return err
+	}
+	retcode, _, stderr, err := NewSSHHandler(handler.service).Run(
+		ctx, gw.Name, fmt.Sprintf("sudo bash %s; rc=$?; sudo rm -f %s; exit $rc", path, path), outputs.COLLECT,
+	)
+	if err != nil {
+		return err
+	}
+	if retcode != 0 {
+		return fmt.Errorf("failed to run '%s' on gateway '%s' (retcode=%d): %s", name, gw.Name, retcode, stderr)
+	}
+	return nil
+}
+
+// useVIPAsDefaultRoute replaces the default route 'oldIP' by 'vip' on the hosts of the network (gateways excluded),
+// at runtime and in the network configuration files
+func (handler *NetworkHandler) useVIPAsDefaultRoute(ctx context.Context, network *abstract.Network, oldIP string, vip string) {
+	var hostNames []string
+	err := network.Properties.LockForRead(networkproperty.HostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			for id, name := range clonable.(*propsv1.NetworkHosts).ByID {
+				if id != network.GatewayID && id != network.SecondaryGatewayID {
+					hostNames = append(hostNames, name)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		logrus.Warnf("failed to list hosts of network '%s', their default route is left unchanged: %v", network.Name, err)
+		return
+	}
+
+	sshHandler := NewSSHHandler(handler.service)
+	cmd := defaultRouteToVIPCommand(oldIP, vip)
+	for _, name := range hostNames {
+		retcode, _, stderr, err := sshHandler.Run(ctx, name, cmd, outputs.COLLECT)
+		if err == nil && retcode != 0 {
+			err = fmt.Errorf("retcode=%d: %s", retcode, stderr)
+		}
+		if err != nil {
+			logrus.Warnf("failed to use VIP '%s' as default route on host '%s': %v", vip, name, err)
+		}
+	}
+}
+
+// keepalivedPrimaryScript returns the script configuring keepalived on the primary gateway to share 'vip' with the
+// secondary gateway (same configuration as the one done by userdata phase2 on gateways created with failover)
+func keepalivedPrimaryScript(primaryIP, secondaryIP, vip, cidr, password string) string {
+	netmask := cidr[strings.Index(cidr, "/")+1:]
+	return fmt.Sprintf(
+		`#!/bin/bash
+IF=$(ip -o -4 addr show | awk '{split($4, a, "/"); if (a[1] == "%s") { print $2; exit }}')
+[ -z "$IF" ] && echo "failed to find interface of IP %s" && exit 1
+
+cat >/etc/keepalived/keepalived.conf <<EOF
+vrrp_instance vrrp_group_gws_internal {
+    state MASTER
+    interface ${IF}
+    virtual_router_id 1
+    priority 151
+    nopreempt
+    advert_int 2
+    authentication {
+        auth_type PASS
+        auth_pass %s
+    }
+    # Unicast specific option, this is the IP of the interface keepalived listens on
+    unicast_src_ip %s
+    # Unicast specific option, this is the IP of the peer instance
+    unicast_peer {
+        %s
+    }
+    virtual_ipaddress {
+        %s/%s
+    }
+}
+EOF
+
+systemctl restart keepalived || service keepalived restart
+`, primaryIP, primaryIP, password, primaryIP, secondaryIP, vip, netmask,
+	)
+}
+
+// defaultRouteToVIPCommand returns the command replacing the default route 'oldIP' by 'vip' on a host; in the network
+// configuration files, only the entries defining the default route are changed (netplan routes to 0.0.0.0/0 and
+// gateway4, ifupdown gateway and 'up route add default', GATEWAY of sysconfig), other occurrences of 'oldIP' are kept
+func defaultRouteToVIPCommand(oldIP string, vip string) string {
+	old := strings.Replace(oldIP, ".", "\\.", -1)
+	exprs := []string{
+		fmt.Sprintf(`/^\s*-?\s*to:\s*(0\.0\.0\.0\/0|default)\s*$/,/^\s*via:/s/^(\s*via:\s*)%s\s*$/\1%s/`, old, vip),
+		fmt.Sprintf(`s/^(\s*gateway4:\s*)%s\s*$/\1%s/`, old, vip),
+		fmt.Sprintf(`s/^(\s*gateway\s+)%s\s*$/\1%s/`, old, vip),
+		fmt.Sprintf(`s/^(\s*(post-)?up\s.*\sdefault\s.*(gw|via)\s+)%s\s*$/\1%s/`, old, vip),
+		fmt.Sprintf(`s/^(GATEWAY="?)%s("?)\s*$/\1%s\2/`, old, vip),
+	}
+	return fmt.Sprintf(
+		"sudo ip route replace default via %s && "+
+			"for f in /etc/netplan/*.yaml /etc/network/interfaces /etc/network/interfaces.d/* /etc/sysconfig/network "+
+			"/etc/sysconfig/network-scripts/ifcfg-*; do [ -f \"$f\" ] && sudo sed -i -r -e '%s' \"$f\"; done; exit 0",
+		vip, strings.Join(exprs, "' -e '"),
+	)
+}
+
+// dnsServersCommand returns the command replacing the DNS servers of a host by 'dnsServers', in systemd-resolved,
+// NetworkManager and dhclient configurations (to survive DHCP lease renewal) and in /etc/resolv.conf when it is not
+// managed by systemd-resolved
+func dnsServersCommand(dnsServers []string) string {
+	return fmt.Sprintf(
+		`sudo bash -c 'set -e
+DNS="%s"
+if systemctl is-active --quiet systemd-resolved; then
+    mkdir -p /etc/systemd/resolved.conf.d
+    printf "[Resolve]\nDNS=%%s\n" "$DNS" >/etc/systemd/resolved.conf.d/99-safescale-dns.conf
+    systemctl restart systemd-resolved
+fi
+if systemctl is-active --quiet NetworkManager; then
+    nmcli -t -f NAME connection show --active | while read -r c; do
+        nmcli connection modify "$c" ipv4.ignore-auto-dns yes ipv4.dns "$DNS"
+    done
+fi
+if [ -f /etc/dhcp/dhclient.conf ]; then
+    sed -i "/^supersede domain-name-servers /d" /etc/dhcp/dhclient.conf
+    echo "supersede domain-name-servers %s;" >>/etc/dhcp/dhclient.conf
+fi
+if [ ! -L /etc/resolv.conf ]; then
+    sed -i "/^nameserver /d" /etc/resolv.conf
+    for ns in $DNS; do echo "nameserver $ns" >>/etc/resolv.conf; done
+fi'`,
+		strings.Join(dnsServers, " "), strings.Join(dnsServers, ", "),
+	)
+}
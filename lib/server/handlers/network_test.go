@@ -16,6 +16,18 @@
 
 package handlers
 
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/networkproperty"
+	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	"github.com/CS-SI/SafeScale/lib/utils/data"
+)
+
 // FIXME: iaas.Service became an interface, so cannot be used as before.
 //       Need to write a service struct satisfying iaas.Service interface
 //       and then initializes an instance of this service struct
@@ -71,3 +83,79 @@ package handlers
 
 // 	assert.Nil(t, result)
 // }
+
+func TestKeepalivedPrimaryScript(t *testing.T) {
+	script := keepalivedPrimaryScript("192.168.0.2", "192.168.0.3", "192.168.0.250", "192.168.0.0/24", "secret")
+	assert.Contains(t, script, "state MASTER")
+	assert.Contains(t, script, "unicast_src_ip 192.168.0.2")
+	assert.Contains(t, script, "unicast_peer {\n        192.168.0.3\n    }")
+	assert.Contains(t, script, "192.168.0.250/24")
+	assert.Contains(t, script, "auth_pass secret")
+	assert.Contains(t, script, `if (a[1] == "192.168.0.2")`)
+}
+
+func TestDefaultRouteToVIPCommand(t *testing.T) {
+	cmd := defaultRouteToVIPCommand("192.168.0.2", "192.168.0.250")
+	assert.True(t, strings.HasPrefix(cmd, "sudo ip route replace default via 192.168.0.250 && "))
+	assert.Contains(t, cmd, `,/^\s*via:/s/^(\s*via:\s*)192\.168\.0\.2\s*$/\1192.168.0.250/`)
+	assert.Contains(t, cmd, `s/^(\s*gateway4:\s*)192\.168\.0\.2\s*$/\1192.168.0.250/`)
+	assert.Contains(t, cmd, `s/^(GATEWAY="?)192\.168\.0\.2("?)\s*$/\1192.168.0.250\2/`)
+	// the old IP is only replaced in default route entries, never globally
+	assert.NotContains(t, cmd, "/g")
+	assert.Equal(t, 10, strings.Count(cmd, "'"))
+}
+
+func TestDNSServersCommand(t *testing.T) {
+	cmd := dnsServersCommand([]string{"10.0.0.2", "1.1.1.1"})
+	assert.True(t, strings.HasPrefix(cmd, "sudo bash -c '"))
+	assert.True(t, strings.HasSuffix(cmd, "'"))
+	assert.Equal(t, 2, strings.Count(cmd, "'"))
+	assert.Contains(t, cmd, `DNS="10.0.0.2 1.1.1.1"`)
+	assert.Contains(t, cmd, "supersede domain-name-servers 10.0.0.2, 1.1.1.1;")
+}
+
+func TestValidateNetworkRoute(t *testing.T) {
+	route, err := validateNetworkRoute("192.168.0.0/24", "10.1.2.3/16", "192.168.0.10")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.1.0.0/16", route.Destination)
+	assert.Equal(t, "192.168.0.10", route.NextHop)
+
+	_, err = validateNetworkRoute("192.168.0.0/24", "10.1.0.0", "192.168.0.10")
+	assert.NotNil(t, err)
+	_, err = validateNetworkRoute("192.168.0.0/24", "10.1.0.0/16", "gateway")
+	assert.NotNil(t, err)
+	_, err = validateNetworkRoute("192.168.0.0/24", "10.1.0.0/16", "192.168.1.10")
+	assert.NotNil(t, err)
+}
+
+func TestNetworkTopology(t *testing.T) {
+	network := abstract.NewNetwork()
+	network.ID, network.Name, network.CIDR = "net-id", "net", "192.168.0.0/24"
+	network.GatewayID, network.SecondaryGatewayID = "gw1-id", "gw2-id"
+	err := network.Properties.LockForWrite(networkproperty.HostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hosts := clonable.(*propsv1.NetworkHosts)
+			hosts.ByID = map[string]string{"h2-id": "h2", "h1-id": "h1", "gw1-id": "gw-net"}
+			return nil
+		},
+	)
+	assert.Nil(t, err)
+
+	// The metadata of the secondary gateway are missing
+	gateway := func(networkID, id string) (*abstract.NetworkTopologyGateway, error) {
+		assert.Equal(t, "net-id", networkID)
+		if id == "gw1-id" {
+			return &abstract.NetworkTopologyGateway{ID: id, Name: "gw-net", PrivateIP: "192.168.0.2", PublicIP: "1.2.3.4"}, nil
+		}
+		return nil, nil
+	}
+	topology, err := networkTopology(network, gateway)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "192.168.0.0/24", topology.CIDR)
+		assert.Equal(t, []string{"h1", "h2"}, topology.Hosts)
+		assert.Equal(t, []abstract.NetworkTopologyGateway{
+			{ID: "gw1-id", Name: "gw-net", PrivateIP: "192.168.0.2", PublicIP: "1.2.3.4", Primary: true},
+			{ID: "gw2-id", Missing: true},
+		}, topology.Gateways)
+	}
+}
@@ -19,6 +19,8 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -29,10 +31,12 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/networkproperty"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/server/metadata"
 	"github.com/CS-SI/SafeScale/lib/system"
 	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
+	"github.com/CS-SI/SafeScale/lib/utils/concurrency"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 	"github.com/CS-SI/SafeScale/lib/utils/retry"
@@ -51,7 +55,9 @@ type SSHAPI interface {
 	// Connect(name string) error
 	Run(ctx context.Context, hostname, cmd string) (int, string, string, error)
 	Copy(ctx context.Context, from string, to string) (int, string, string, error)
+	TransferFile(ctx context.Context, srcHost, srcPath, dstHost, dstPath string) error
 	GetConfig(context.Context, interface{}) (*system.SSHConfig, error)
+	GetNativeConfig(context.Context, interface{}) (*system.SSHConfig, error)
 }
 
 // SSHHandler SSH service
@@ -117,16 +123,23 @@ func (handler *SSHHandler) GetConfig(ctx context.Context, hostParam interface{})
 		}
 	}
 
+	sshOptions := handler.service.GetSSHConnectionOptions()
 	sshConfig = &system.SSHConfig{
-		PrivateKey: host.PrivateKey,
-		Port:       22,
-		Host:       host.GetAccessIP(),
-		User:       user,
+		PrivateKey:           host.PrivateKey,
+		Port:                 22,
+		Host:                 host.GetAccessIP(),
+		User:                 user,
+		HostKey:              pinnedHostKey(host),
+		AgentSocket:          sshAgentSocket(),
+		KeyPath:              sshKeyPath(host),
+		SSHConnectionOptions: sshOptions,
 	}
 
+	var defaultNetworkID string
 	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
 		func(clonable data.Clonable) error {
 			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			defaultNetworkID = hostNetworkV1.DefaultNetworkID
 			if hostNetworkV1.DefaultGatewayID != "" {
 				hostSvc := NewHostHandler(handler.service)
 				gw, err := hostSvc.Inspect(ctx, hostNetworkV1.DefaultGatewayID)
@@ -134,10 +147,14 @@ func (handler *SSHHandler) GetConfig(ctx context.Context, hostParam interface{})
 					return err
 				}
 				GatewayConfig := system.SSHConfig{
-					PrivateKey: gw.PrivateKey,
-					Port:       22,
-					Host:       gw.GetAccessIP(),
-					User:       user,
+					PrivateKey:           gw.PrivateKey,
+					Port:                 22,
+					Host:                 gw.GetAccessIP(),
+					User:                 user,
+					HostKey:              pinnedHostKey(gw),
+					AgentSocket:          sshAgentSocket(),
+					KeyPath:              sshKeyPath(gw),
+					SSHConnectionOptions: sshOptions,
 				}
 				sshConfig.GatewayConfig = &GatewayConfig
 			}
@@ -148,11 +165,179 @@ func (handler *SSHHandler) GetConfig(ctx context.Context, hostParam interface{})
 		return nil, err
 	}
 
+	if defaultNetworkID != "" {
+		err = handler.addJumpHosts(ctx, sshConfig, host, defaultNetworkID, user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	sshConfig.Host = host.GetAccessIP()
 
 	return sshConfig, nil
 }
 
+// GetNativeConfig creates SSHConfig to connect to an host with the login user provided by its image instead of
+// the operator user; it allows to reach a host before provisioning has created the operator user, on providers
+// injecting the keypair for the image user
+func (handler *SSHHandler) GetNativeConfig(ctx context.Context, hostParam interface{}) (*system.SSHConfig, error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+
+	sshConfig, err := handler.GetConfig(ctx, hostParam)
+	if err != nil {
+		return nil, err
+	}
+
+	var host *abstract.Host
+	switch hostParam := hostParam.(type) {
+	case string:
+		mh, err := metadata.LoadHost(handler.service, hostParam)
+		if err != nil {
+			return nil, err
+		}
+		host, err = mh.Get()
+		if err != nil {
+			return nil, err
+		}
+	case *abstract.Host:
+		host = hostParam
+	}
+	if host.NativeUser == "" {
+		return nil, fail.NotAvailableError(fmt.Sprintf("login user provided by the image of host '%s' is unknown", host.Name))
+	}
+	sshConfig.User = host.NativeUser
+	return sshConfig, nil
+}
+
+// addJumpHosts appends to the end of the chain of gateways of sshConfig the jump hosts configured on the network
+func (handler *SSHHandler) addJumpHosts(ctx context.Context, sshConfig *system.SSHConfig, host *abstract.Host, networkID, user string) error {
+	mn, err := metadata.LoadNetwork(handler.service, networkID)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+	network, err := mn.Get()
+	if err != nil {
+		return err
+	}
+
+	var jumpHosts []string
+	err = network.Properties.LockForRead(networkproperty.JumpHostsV1).ThenUse(
+		func(clonable data.Clonable) error {
+			jumpHosts = clonable.(*propsv1.NetworkJumpHosts).Hosts
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(jumpHosts) == 0 {
+		return nil
+	}
+
+	last := sshConfig
+	for last.GatewayConfig != nil {
+		last = last.GatewayConfig
+	}
+
+	// Jump hosts are ordered from the daemon side, so they are chained starting from the end of the list
+	hostSvc := NewHostHandler(handler.service)
+	for i := len(jumpHosts) - 1; i >= 0; i-- {
+		if jumpHosts[i] == host.ID || jumpHosts[i] == host.Name {
+			continue
+		}
+		jh, err := hostSvc.Inspect(ctx, jumpHosts[i])
+		if err != nil {
+			return err
+		}
+		last.GatewayConfig = &system.SSHConfig{
+			PrivateKey:           jh.PrivateKey,
+			Port:                 22,
+			Host:                 jh.GetAccessIP(),
+			User:                 user,
+			HostKey:              pinnedHostKey(jh),
+			AgentSocket:          sshAgentSocket(),
+			KeyPath:              sshKeyPath(jh),
+			SSHConnectionOptions: sshConfig.SSHConnectionOptions,
+		}
+		last = last.GatewayConfig
+	}
+	return nil
+}
+
+// hostKeyPinningDisabled tells if the checking of SSH host keys has been disabled with SAFESCALE_SSH_NO_HOSTKEY_PINNING,
+// for environments where the host behind an address changes legitimately
+func hostKeyPinningDisabled() bool {
+	return os.Getenv("SAFESCALE_SSH_NO_HOSTKEY_PINNING") != ""
+}
+
+// pinnedHostKey returns the SSH host key to check when connecting to host, empty if it must not be checked
+func pinnedHostKey(host *abstract.Host) string {
+	if hostKeyPinningDisabled() {
+		return ""
+	}
+	return host.HostKey
+}
+
+// sshAgentSocket returns the socket of the ssh-agent set with SAFESCALE_SSH_AGENT_SOCKET, holding the keys of the hosts
+// in environments where private keys must not be written on the daemon host; empty if not set
+func sshAgentSocket() string {
+	return os.Getenv("SAFESCALE_SSH_AGENT_SOCKET")
+}
+
+// sshKeyPath returns the path of the file containing the private key of host, '<host name>.pem' in the directory set
+// with SAFESCALE_SSH_KEYS_DIR, for environments where the keys are provisioned on the daemon host; empty if not set or
+// if the file does not exist, the private key recorded in metadata being used instead
+func sshKeyPath(host *abstract.Host) string {
+	dir := os.Getenv("SAFESCALE_SSH_KEYS_DIR")
+	if dir == "" {
+		return ""
+	}
+	path := filepath.Join(dir, host.Name+".pem")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// recordHostKey records in metadata the SSH host key of a host not having one yet, to check it on next connections;
+// hosts created before the pinning of host keys are migrated this way on their next connection.
+// Failures are only logged, the host staying reachable without host key checking
+func (handler *SSHHandler) recordHostKey(host *abstract.Host, ssh *system.SSHConfig) {
+	if hostKeyPinningDisabled() || host == nil || ssh == nil || host.HostKey != "" {
+		return
+	}
+
+	key, err := ssh.ScanHostKey(temporal.GetConnectSSHTimeout())
+	if err != nil {
+		logrus.Warnf("failed to retrieve SSH host key of host '%s': %v", host.Name, err)
+		return
+	}
+	mh, err := metadata.LoadHost(handler.service, host.ID)
+	if err != nil {
+		logrus.Warnf("failed to record SSH host key of host '%s': %v", host.Name, err)
+		return
+	}
+	stored, err := mh.Get()
+	if err != nil {
+		logrus.Warnf("failed to record SSH host key of host '%s': %v", host.Name, err)
+		return
+	}
+	stored.HostKey = key
+	err = mh.Write()
+	if err != nil {
+		logrus.Warnf("failed to record SSH host key of host '%s': %v", host.Name, err)
+		return
+	}
+	host.HostKey = key
+	ssh.HostKey = key
+	logrus.Infof("SSH host key of host '%s' recorded", host.Name)
+}
+
 // WaitServerReady waits for remote SSH server to be ready. After timeout, fails
 func (handler *SSHHandler) WaitServerReady(ctx context.Context, hostParam interface{}, timeout time.Duration) (err error) {
 	if handler == nil {
@@ -172,7 +357,7 @@ func (handler *SSHHandler) WaitServerReady(ctx context.Context, hostParam interf
 	if err != nil {
 		return err
 	}
-	_, waitErr := ssh.WaitServerReady("ready", timeout)
+	_, waitErr := ssh.WaitServerReady("ready", temporal.EffectiveTimeout(ctx, timeout))
 	return waitErr
 }
 
@@ -182,7 +367,8 @@ func (handler *SSHHandler) Run(ctx context.Context, hostName, cmd string, outs o
 		return -1, "", "", fail.InvalidInstanceError()
 	}
 
-	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', <command>)", hostName), true).WithStopwatch().GoingIn()
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', <command>)", hostName), true).
+		WithStopwatch().WithContext(ctx).GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 	tracer.Trace(fmt.Sprintf("<command>=[%s]", cmd))
@@ -209,22 +395,36 @@ func (handler *SSHHandler) Run(ctx context.Context, hostName, cmd string, outs o
 		return 0, "", "", err
 	}
 
+	timeout := temporal.EffectiveTimeout(ctx, temporal.GetHostTimeout())
 	retryErr := retry.WhileUnsuccessfulDelay1SecondWithNotify(
 		func() error {
-			retCode, stdOut, stdErr, err = handler.runWithTimeout(ssh, cmd, outs, temporal.GetHostTimeout())
+			retCode, stdOut, stdErr, err = handler.runWithTimeout(ssh, cmd, outs, timeout)
+			if retCode == system.RetCodeTimeout {
+				// the command itself ran out of time, running it again will not help
+				return retry.AbortedError("", err)
+			}
 			return err
 		},
-		temporal.GetHostTimeout(),
+		timeout,
 		func(t retry.Try, v verdict.Enum) {
-			if v == verdict.Retry {
+			switch v {
+			case verdict.Retry:
 				logrus.Debugf("Remote SSH service on host '%s' isn't ready, retrying...\n", hostName)
+			case verdict.Done:
+				if retCode == system.RetCodeTimeout {
+					logrus.Warnf("command on host '%s' killed after running out of time (%s)", hostName, temporal.FormatDuration(timeout))
+				}
 			}
 		},
 	)
 	if retryErr != nil {
+		if _, ok := retryErr.(retry.ErrAborted); ok {
+			retryErr = fail.Cause(retryErr)
+		}
 		return retCode, stdOut, stdErr, retryErr
 	}
 
+	handler.recordHostKey(host, ssh)
 	return retCode, stdOut, stdErr, err
 }
 
@@ -248,6 +448,7 @@ func (handler *SSHHandler) RunWithTimeout(ctx context.Context, hostName, cmd str
 	if cmd == "" {
 		return 1, "", "", fail.InvalidParameterError("cmd", "cannot be empty")
 	}
+	timeout = temporal.EffectiveTimeout(ctx, timeout)
 
 	hostSvc := NewHostHandler(handler.service)
 	host, err := hostSvc.ForceInspect(ctx, hostName)
@@ -264,22 +465,151 @@ func (handler *SSHHandler) RunWithTimeout(ctx context.Context, hostName, cmd str
 	retryErr := retry.WhileUnsuccessfulDelay1SecondWithNotify(
 		func() error {
 			retCode, stdOut, stdErr, err = handler.runWithTimeout(ssh, cmd, outs, timeout)
+			if retCode == system.RetCodeTimeout {
+				// the command itself ran out of time, running it again will not help
+				return retry.AbortedError("", err)
+			}
 			return err
 		},
 		2*timeout,
 		func(t retry.Try, v verdict.Enum) {
-			if v == verdict.Retry {
+			switch v {
+			case verdict.Retry:
 				logrus.Debugf("Remote SSH service on host '%s' isn't ready, retrying...\n", hostName)
+			case verdict.Done:
+				if retCode == system.RetCodeTimeout {
+					logrus.Warnf("command on host '%s' killed after running out of time (%s)", hostName, temporal.FormatDuration(timeout))
+				}
 			}
 		},
 	)
 	if retryErr != nil {
+		if _, ok := retryErr.(retry.ErrAborted); ok {
+			retryErr = fail.Cause(retryErr)
+		}
 		return retCode, stdOut, stdErr, retryErr
 	}
 
+	handler.recordHostKey(host, ssh)
 	return retCode, stdOut, stdErr, err
 }
 
+// maxRunOnHostsParallelism caps the number of hosts RunOnHosts works on at the same time, each one using SSH tunnels
+// through the gateways
+const maxRunOnHostsParallelism = 20
+
+// CommandResult contains the outcome of a command run on a host by RunOnHosts
+type CommandResult struct {
+	RetCode int
+	Stdout  string
+	Stderr  string
+	// Err contains the error preventing to run the command on the host, if any
+	Err error
+}
+
+// RunOnHosts runs command 'cmd' on each host of 'hostNames', on at most 'parallelism' hosts at the same time
+// (capped to maxRunOnHostsParallelism, 0 meaning the cap), and returns the results indexed by host name.
+// The failure on a host does not stop the run on the other hosts, it is recorded in the result of the host;
+// if ctx is cancelled, the hosts not yet started get a fail.ErrAborted.
+func (handler *SSHHandler) RunOnHosts(ctx context.Context, hostNames []string, cmd string, outs outputs.Enum, parallelism int) (results map[string]CommandResult, err error) {
+	if handler == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return nil, fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if len(hostNames) == 0 {
+		return nil, fail.InvalidParameterError("hostNames", "cannot be empty")
+	}
+	if cmd == "" {
+		return nil, fail.InvalidParameterError("cmd", "cannot be empty")
+	}
+	if parallelism < 0 {
+		return nil, fail.InvalidParameterError("parallelism", "cannot be negative")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("(%d hosts, <command>, %d)", len(hostNames), parallelism), true).
+		WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	results, err = runOnHosts(
+		ctx, hostNames, parallelism, func(ctx context.Context, hostName string) CommandResult {
+			retCode, stdOut, stdErr, err := handler.Run(ctx, hostName, cmd, outs)
+			return CommandResult{RetCode: retCode, Stdout: stdOut, Stderr: stdErr, Err: err}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for hostName, result := range results {
+		if result.Err != nil {
+			logrus.Warnf("failed to run command on host '%s': %v", hostName, result.Err)
+		}
+	}
+	return results, nil
+}
+
+// runOnHosts calls 'run' for each host of 'hostNames' (once per host) in subtasks, at most 'parallelism' at the same
+// time, and returns the results indexed by host name
+func runOnHosts(
+	ctx context.Context, hostNames []string, parallelism int, run func(context.Context, string) CommandResult,
+) (map[string]CommandResult, error) {
+	if parallelism == 0 || parallelism > maxRunOnHostsParallelism {
+		parallelism = maxRunOnHostsParallelism
+	}
+
+	task, err := concurrency.NewTaskWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]CommandResult, len(hostNames))
+	subtasks := map[string]concurrency.Task{}
+	slots := make(chan struct{}, parallelism)
+	for _, hostName := range hostNames {
+		if _, ok := results[hostName]; ok {
+			continue
+		}
+		if _, ok := subtasks[hostName]; ok {
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[hostName] = CommandResult{RetCode: -1, Err: fail.AbortedError("run cancelled", ctx.Err())}
+			continue
+		}
+
+		subtask, err := concurrency.NewTask(task)
+		if err == nil {
+			subtask, err = subtask.Start(
+				func(t concurrency.Task, params concurrency.TaskParameters) (concurrency.TaskResult, error) {
+					defer func() { <-slots }()
+					return run(t.GetContext(), params.(string)), nil
+				}, hostName,
+			)
+		}
+		if err != nil {
+			<-slots
+			results[hostName] = CommandResult{RetCode: -1, Err: err}
+			continue
+		}
+		subtasks[hostName] = subtask
+	}
+
+	for hostName, subtask := range subtasks {
+		result, err := subtask.Wait()
+		if err != nil {
+			results[hostName] = CommandResult{RetCode: -1, Err: err}
+			continue
+		}
+		results[hostName] = result.(CommandResult)
+	}
+	return results, nil
+}
+
 // run executes command on the host
 func (handler *SSHHandler) runWithTimeout(ssh *system.SSHConfig, cmd string, outs outputs.Enum, duration time.Duration) (int, string, string, error) {
 	// Create the command
@@ -358,9 +688,6 @@ func (handler *SSHHandler) Copy(ctx context.Context, from, to string) (retCode i
 	}
 
 	// Host checks
-	if hostFrom != "" && hostTo != "" {
-		return 0, "", "", fmt.Errorf("copy between 2 hosts is not supported yet")
-	}
 	if hostFrom == "" && hostTo == "" {
 		return 0, "", "", fmt.Errorf("no host name specified neither in from nor to")
 	}
@@ -374,6 +701,14 @@ func (handler *SSHHandler) Copy(ctx context.Context, from, to string) (retCode i
 		return 0, "", "", err
 	}
 
+	if hostFrom != "" && hostTo != "" {
+		err = handler.TransferFile(ctx, hostFrom, fromPath, hostTo, toPath)
+		if err != nil {
+			return 1, "", "", err
+		}
+		return 0, "", "", nil
+	}
+
 	if hostFrom != "" {
 		hostName = hostFrom
 		remotePath = fromPath
@@ -398,6 +733,51 @@ func (handler *SSHHandler) Copy(ctx context.Context, from, to string) (retCode i
 		return 0, "", "", err
 	}
 
-	cRc, cStcOut, cStdErr, cErr := ssh.Copy(remotePath, localPath, upload)
-	return cRc, cStcOut, cStdErr, cErr
+	var (
+		cRc              int
+		cStdOut, cStdErr string
+	)
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			var cErr error
+			cRc, cStdOut, cStdErr, cErr = ssh.Copy(remotePath, localPath, upload)
+			if cErr != nil {
+				return retry.AbortedError("", cErr)
+			}
+			return checkCopyResult(cRc, cStdErr)
+		},
+		temporal.GetDefaultDelay(),
+		temporal.GetLongOperationTimeout(),
+		copyMaxAttempts,
+	)
+	if retryErr != nil {
+		if aborted, ok := retryErr.(retry.ErrAborted); ok {
+			return cRc, cStdOut, cStdErr, aborted.Cause()
+		}
+		return cRc, cStdOut, cStdErr, retryErr
+	}
+
+	handler.recordHostKey(host, ssh)
+	return cRc, cStdOut, cStdErr, nil
+}
+
+// copyMaxAttempts is the number of times a copy failing with a transient scp error is tried
+const copyMaxAttempts = 3
+
+// checkCopyResult tells what to do with the scp return code 'retcode':
+// - nil if the copy succeeded
+// - a retry.ErrAborted embedding a fail.ErrInvalidRequest if trying again cannot succeed (missing file, no permission, ...)
+// - an error to retry if the failure is transient (connection lost, timeout, ...)
+func checkCopyResult(retcode int, stderr string) error {
+	if retcode == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("copy failed (retcode=%d: %s)", retcode, system.SCPErrorString(retcode))
+	if stderr != "" {
+		msg += ": " + stderr
+	}
+	if system.IsSCPRetryable(retcode) {
+		return fmt.Errorf("%s", msg)
+	}
+	return retry.AbortedError("", fail.InvalidRequestError(msg))
 }
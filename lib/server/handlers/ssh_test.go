@@ -0,0 +1,178 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+	"github.com/CS-SI/SafeScale/lib/utils/retry"
+)
+
+func TestCheckCopyResult(t *testing.T) {
+	assert.Nil(t, checkCopyResult(0, ""))
+
+	// Transient failures are retried
+	for _, code := range []int{4, 5, 66, 67, 70, 74, 75, 76} {
+		err := checkCopyResult(code, "")
+		if assert.NotNil(t, err, "retcode %d", code) {
+			_, aborted := err.(retry.ErrAborted)
+			assert.False(t, aborted, "retcode %d", code)
+		}
+	}
+
+	// Other failures stop the retries with a readable reason
+	for _, code := range []int{1, 2, 6, 7, 65, 255} {
+		err := checkCopyResult(code, "scp: /tmp/x: Permission denied")
+		if assert.NotNil(t, err, "retcode %d", code) {
+			aborted, ok := err.(retry.ErrAborted)
+			if assert.True(t, ok, "retcode %d", code) {
+				_, ok = aborted.Cause().(fail.ErrInvalidRequest)
+				assert.True(t, ok, "retcode %d", code)
+				assert.Contains(t, aborted.Cause().Error(), "Permission denied")
+			}
+		}
+	}
+	err := checkCopyResult(7, "")
+	assert.Contains(t, err.(retry.ErrAborted).Cause().Error(), "retcode=7: No permission to access file")
+}
+
+func TestParseRemoteFileInfo(t *testing.T) {
+	sum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	info, err := parseRemoteFileInfo(sum + "\n640 root:adm 1234\n")
+	if assert.Nil(t, err) {
+		assert.Equal(t, sum, info.checksum)
+		assert.Equal(t, "640", info.mode)
+		assert.Equal(t, "root:adm", info.owner)
+		assert.Equal(t, int64(1234), info.size)
+	}
+
+	_, err = parseRemoteFileInfo("640 root:adm 1234")
+	assert.NotNil(t, err)
+	_, err = parseRemoteFileInfo("abc\n640 root:adm 1234")
+	assert.NotNil(t, err)
+}
+
+func TestFinalizeTransferCommand(t *testing.T) {
+	info := &remoteFileInfo{checksum: "abc", mode: "755", owner: "safescale:safescale"}
+	cmd := finalizeTransferCommand("/opt/it's.safescale-transfer", "/opt/it's", info)
+
+	assert.Contains(t, cmd, `sudo sha256sum '/opt/it'"'"'s.safescale-transfer'`)
+	assert.Contains(t, cmd, `[ "$sum" != 'abc' ]`)
+	assert.Contains(t, cmd, `sudo chown 'safescale:safescale' '/opt/it'"'"'s.safescale-transfer'`)
+	assert.Contains(t, cmd, `sudo chmod '755' '/opt/it'"'"'s.safescale-transfer'`)
+	assert.Contains(t, cmd, `sudo mv -f '/opt/it'"'"'s.safescale-transfer' '/opt/it'"'"'s'`)
+}
+
+func TestTransferProgress(t *testing.T) {
+	p := &transferProgress{name: "test", total: 200}
+	n, err := p.Write(make([]byte, 15))
+	assert.Nil(t, err)
+	assert.Equal(t, 15, n)
+	assert.Equal(t, int64(0), p.logged)
+
+	_, _ = p.Write(make([]byte, 30))
+	assert.Equal(t, int64(22), p.logged)
+
+	_, _ = p.Write(make([]byte, 155))
+	assert.Equal(t, int64(200), p.written)
+	assert.Equal(t, int64(100), p.logged)
+}
+
+func TestSSHKeyPath(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "gw-net.pem"), []byte("key"), 0400))
+	gw := &abstract.Host{Name: "gw-net"}
+	other := &abstract.Host{Name: "other"}
+
+	require.Nil(t, os.Unsetenv("SAFESCALE_SSH_KEYS_DIR"))
+	assert.Empty(t, sshKeyPath(gw))
+
+	require.Nil(t, os.Setenv("SAFESCALE_SSH_KEYS_DIR", dir))
+	defer func() { _ = os.Unsetenv("SAFESCALE_SSH_KEYS_DIR") }()
+	assert.Equal(t, filepath.Join(dir, "gw-net.pem"), sshKeyPath(gw))
+	assert.Empty(t, sshKeyPath(other))
+}
+
+func TestRunOnHosts(t *testing.T) {
+	var (
+		lock             sync.Mutex
+		running, maxSeen int
+		calls            = map[string]int{}
+	)
+	run := func(ctx context.Context, hostName string) CommandResult {
+		lock.Lock()
+		calls[hostName]++
+		running++
+		if running > maxSeen {
+			maxSeen = running
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		if hostName == "host-3" {
+			return CommandResult{RetCode: -1, Err: fmt.Errorf("unreachable")}
+		}
+		return CommandResult{RetCode: 0, Stdout: "up " + hostName}
+	}
+
+	hostNames := []string{"host-1", "host-2", "host-3", "host-4", "host-5", "host-1"}
+	results, err := runOnHosts(context.Background(), hostNames, 2, run)
+	require.Nil(t, err)
+	assert.Len(t, results, 5)
+	assert.True(t, maxSeen <= 2, "%d hosts run at the same time", maxSeen)
+	for name, count := range calls {
+		assert.Equal(t, 1, count, name)
+	}
+	assert.Equal(t, "up host-1", results["host-1"].Stdout)
+	assert.Equal(t, 0, results["host-5"].RetCode)
+	assert.NotNil(t, results["host-3"].Err)
+}
+
+func TestRunOnHostsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := runOnHosts(
+		ctx, []string{"host-1", "host-2", "host-3"}, 1, func(context.Context, string) CommandResult {
+			return CommandResult{}
+		},
+	)
+	require.Nil(t, err)
+	// every host gets a result, the ones not started reporting the cancellation
+	assert.Len(t, results, 3)
+	for _, result := range results {
+		if result.Err != nil {
+			assert.Equal(t, -1, result.RetCode)
+		}
+	}
+}
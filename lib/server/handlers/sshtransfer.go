@@ -0,0 +1,267 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/CS-SI/SafeScale/lib/system"
+	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
+	"github.com/CS-SI/SafeScale/lib/utils/debug"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// transferSuffix is appended to the destination path while the content is received, the file being moved to its final
+// path only once its checksum has been verified
+const transferSuffix = ".safescale-transfer"
+
+// remoteFileInfo describes a file on a remote host
+type remoteFileInfo struct {
+	checksum string
+	mode     string
+	owner    string
+	size     int64
+}
+
+// remoteFileInfoCommand returns the command printing the sha256 checksum, the mode, the owner and the size of 'path'
+func remoteFileInfoCommand(path string) string {
+	quoted := system.ShellQuote(path)
+	return fmt.Sprintf("sudo sha256sum %s | cut -d' ' -f1 && sudo stat -c '%%a %%U:%%G %%s' %s", quoted, quoted)
+}
+
+// parseRemoteFileInfo parses the output of the command built by remoteFileInfoCommand
+func parseRemoteFileInfo(out string) (*remoteFileInfo, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected file information: '%s'", out)
+	}
+	fields := strings.Fields(lines[1])
+	if len(fields) != 3 || len(strings.TrimSpace(lines[0])) != 64 {
+		return nil, fmt.Errorf("unexpected file information: '%s'", out)
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected file size '%s'", fields[2])
+	}
+	return &remoteFileInfo{
+		checksum: strings.TrimSpace(lines[0]),
+		mode:     fields[0],
+		owner:    fields[1],
+		size:     size,
+	}, nil
+}
+
+// finalizeTransferCommand returns the command checking the checksum of the received file 'tmpPath', then giving it the
+// ownership and the mode of the source file and moving it to 'path'; the received file is removed if anything fails
+func finalizeTransferCommand(tmpPath, path string, info *remoteFileInfo) string {
+	quotedTmp := system.ShellQuote(tmpPath)
+	return fmt.Sprintf(
+		"sum=$(sudo sha256sum %s | cut -d' ' -f1); "+
+			"if [ \"$sum\" != %s ]; then echo \"checksum mismatch ($sum)\" >&2; sudo rm -f %s; exit 1; fi; "+
+			"sudo chown %s %s && sudo chmod %s %s && sudo mv -f %s %s || { sudo rm -f %s; exit 1; }",
+		quotedTmp, system.ShellQuote(info.checksum), quotedTmp,
+		system.ShellQuote(info.owner), quotedTmp, system.ShellQuote(info.mode), quotedTmp, quotedTmp, system.ShellQuote(path), quotedTmp,
+	)
+}
+
+// transferProgress counts the bytes written through it and logs the progress of the transfer every 10%
+type transferProgress struct {
+	name    string
+	total   int64
+	written int64
+	logged  int64
+}
+
+// Write ...
+func (p *transferProgress) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.total > 0 {
+		percent := p.written * 100 / p.total
+		if percent/10 > p.logged/10 {
+			p.logged = percent
+			logrus.Infof("Transfer of %s: %d%% (%d/%d bytes)", p.name, percent, p.written, p.total)
+		}
+	}
+	return len(b), nil
+}
+
+// TransferFile copies the file srcPath of host srcHost to dstPath on host dstHost
+// The content is streamed from one host to the other through their SSH connections (and the gateways they need),
+// without local copy; if streaming fails, the file is pulled locally then pushed to the destination.
+// The destination file gets the ownership and the mode of the source file, and its sha256 checksum is verified
+// before it replaces dstPath.
+func (handler *SSHHandler) TransferFile(ctx context.Context, srcHost, srcPath, dstHost, dstPath string) (err error) {
+	if handler == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ctx == nil {
+		return fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if srcHost == "" {
+		return fail.InvalidParameterError("srcHost", "cannot be empty string")
+	}
+	if srcPath == "" {
+		return fail.InvalidParameterError("srcPath", "cannot be empty string")
+	}
+	if dstHost == "" {
+		return fail.InvalidParameterError("dstHost", "cannot be empty string")
+	}
+	if dstPath == "" {
+		return fail.InvalidParameterError("dstPath", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(
+		nil, fmt.Sprintf("('%s:%s', '%s:%s')", srcHost, srcPath, dstHost, dstPath), true,
+	).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	hostSvc := NewHostHandler(handler.service)
+	src, err := hostSvc.ForceInspect(ctx, srcHost)
+	if err != nil {
+		return err
+	}
+	dst, err := hostSvc.ForceInspect(ctx, dstHost)
+	if err != nil {
+		return err
+	}
+
+	retcode, stdout, stderr, err := handler.Run(ctx, src.Name, remoteFileInfoCommand(srcPath), outputs.COLLECT)
+	if err != nil {
+		return err
+	}
+	if retcode != 0 {
+		return fail.InvalidRequestError(
+			fmt.Sprintf("cannot read '%s' on host '%s': %s", srcPath, src.Name, strings.TrimSpace(stderr)),
+		)
+	}
+	info, err := parseRemoteFileInfo(stdout)
+	if err != nil {
+		return err
+	}
+
+	srcSSH, err := handler.GetConfig(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstSSH, err := handler.GetConfig(ctx, dst)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := dstPath + transferSuffix
+	name := fmt.Sprintf("'%s:%s' to '%s:%s'", src.Name, srcPath, dst.Name, dstPath)
+	err = streamFile(ctx, srcSSH, srcPath, dstSSH, tmpPath, &transferProgress{name: name, total: info.size})
+	if err != nil {
+		logrus.Warnf("Failed to stream %s (%v), falling back to a copy through the local host", name, err)
+		err = relayFile(srcSSH, srcPath, dstSSH, tmpPath)
+		if err != nil {
+			return fail.Wrap(err, fmt.Sprintf("failed to transfer %s", name))
+		}
+	}
+
+	retcode, _, stderr, err = handler.Run(ctx, dst.Name, finalizeTransferCommand(tmpPath, dstPath, info), outputs.COLLECT)
+	if err != nil {
+		return err
+	}
+	if retcode != 0 {
+		return fmt.Errorf("failed to finalize transfer of %s: %s", name, strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// streamFile pipes the output of 'cat srcPath' on the source host into dstPath on the destination host
+func streamFile(
+	ctx context.Context, srcSSH *system.SSHConfig, srcPath string, dstSSH *system.SSHConfig, dstPath string,
+	progress io.Writer,
+) error {
+	srcCmd, err := srcSSH.CommandContext(ctx, "sudo cat "+system.ShellQuote(srcPath))
+	if err != nil {
+		return err
+	}
+	dstCmd, err := dstSSH.CommandContext(ctx, "sudo bash -c "+system.ShellQuote("cat > "+system.ShellQuote(dstPath)))
+	if err != nil {
+		return err
+	}
+	reader, err := srcCmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	writer, err := dstCmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err = dstCmd.Start(); err != nil {
+		return err
+	}
+	if err = srcCmd.Start(); err != nil {
+		_ = writer.Close()
+		_ = dstCmd.Wait()
+		return err
+	}
+
+	_, copyErr := io.Copy(io.MultiWriter(writer, progress), reader)
+	closeErr := writer.Close()
+	srcErr := srcCmd.Wait()
+	dstErr := dstCmd.Wait()
+	for _, e := range []error{copyErr, srcErr, closeErr, dstErr} {
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// relayFile copies srcPath from the source host to dstPath on the destination host through a local temporary file
+func relayFile(srcSSH *system.SSHConfig, srcPath string, dstSSH *system.SSHConfig, dstPath string) error {
+	f, err := ioutil.TempFile("", "safescale-transfer-")
+	if err != nil {
+		return err
+	}
+	localPath := f.Name()
+	_ = f.Close()
+	defer func() {
+		if rerr := os.Remove(localPath); rerr != nil {
+			logrus.Warnf("failed to remove temporary file '%s': %v", localPath, rerr)
+		}
+	}()
+
+	retcode, _, stderr, err := srcSSH.Copy(srcPath, localPath, false)
+	if err == nil {
+		err = checkCopyResult(retcode, stderr)
+	}
+	if err != nil {
+		return fail.Wrap(fail.Cause(err), "failed to pull file")
+	}
+	retcode, _, stderr, err = dstSSH.Copy(dstPath, localPath, true)
+	if err == nil {
+		err = checkCopyResult(retcode, stderr)
+	}
+	if err != nil {
+		return fail.Wrap(fail.Cause(err), "failed to push file")
+	}
+	return nil
+}
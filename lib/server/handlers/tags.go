@@ -0,0 +1,59 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// mergeTags merges the tags of a creation request into the mandatory tags of the tenant: requested values override
+// the default values of the mandatory tags
+// Returns fail.ErrInvalidRequest if a mandatory tag ends without value, listing all of them
+func mergeTags(mandatory, requested map[string]string) (map[string]string, error) {
+	if len(mandatory) == 0 && len(requested) == 0 {
+		return nil, nil
+	}
+
+	merged := make(map[string]string, len(mandatory)+len(requested))
+	for k, v := range mandatory {
+		merged[k] = v
+	}
+	for k, v := range requested {
+		if k == "" {
+			return nil, fail.InvalidRequestError("tag names cannot be empty")
+		}
+		merged[k] = v
+	}
+
+	var missing []string
+	for k := range mandatory {
+		if strings.TrimSpace(merged[k]) == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fail.InvalidRequestError(
+			fmt.Sprintf("mandatory tag(s) without value: %s", strings.Join(missing, ", ")),
+		)
+	}
+	return merged, nil
+}
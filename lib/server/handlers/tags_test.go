@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestMergeTags(t *testing.T) {
+	tags, err := mergeTags(nil, nil)
+	require.Nil(t, err)
+	assert.Nil(t, tags)
+
+	// mandatory tags are always present, requested ones override their default values
+	mandatory := map[string]string{"CostCenter": "", "Project": "safescale"}
+	tags, err = mergeTags(mandatory, map[string]string{"CostCenter": "42", "owner": "ops"})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"CostCenter": "42", "Project": "safescale", "owner": "ops"}, tags)
+
+	tags, err = mergeTags(map[string]string{"Project": "safescale"}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"Project": "safescale"}, tags)
+
+	// the mandatory tags cannot be left or made empty
+	_, err = mergeTags(mandatory, map[string]string{"owner": "ops"})
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrInvalidRequest{}, err)
+	assert.Contains(t, err.Error(), "CostCenter")
+
+	_, err = mergeTags(mandatory, map[string]string{"CostCenter": "42", "Project": " "})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Project")
+}
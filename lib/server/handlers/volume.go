@@ -265,6 +265,11 @@ func (handler *VolumeHandler) Create(ctx context.Context, name string, size int,
 	if handler == nil {
 		return nil, fail.InvalidInstanceError()
 	}
+	return handler.createWithTags(ctx, name, size, speed, nil)
+}
+
+// createWithTags creates a volume carrying the mandatory tags of the tenant merged with 'tags'
+func (handler *VolumeHandler) createWithTags(ctx context.Context, name string, size int, speed volumespeed.Enum, tags map[string]string) (volume *abstract.Volume, err error) {
 	// FIXME: validate parameters
 
 	tracer := debug.NewTracer(
@@ -273,6 +278,11 @@ func (handler *VolumeHandler) Create(ctx context.Context, name string, size int,
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
+	tags, err = mergeTags(handler.service.GetMandatoryTags(), tags)
+	if err != nil {
+		return nil, err
+	}
+
 	_, err = metadata.LoadVolume(handler.service, name)
 	if err != nil {
 		if _, ok := err.(fail.ErrNotFound); !ok {
@@ -287,6 +297,7 @@ func (handler *VolumeHandler) Create(ctx context.Context, name string, size int,
 			Name:  name,
 			Size:  size,
 			Speed: speed,
+			Tags:  tags,
 		},
 	)
 	if err != nil {
@@ -1698,7 +1709,14 @@ func (handler *VolumeHandler) Detach(ctx context.Context, volumeName, hostName s
 							err = handler.service.DeleteVolumeAttachment(host.ID, attachment.AttachID)
 							if err != nil {
 								switch err.(type) {
-								case fail.ErrNotFound, fail.ErrInvalidRequest, fail.ErrTimeout:
+								case fail.ErrNotFound:
+									// Volume already detached out-of-band, only metadata has to be updated
+									logrus.Warnf(
+										"volume '%s' already detached from host '%s' on provider side", volume.Name,
+										host.Name,
+									)
+									err = nil
+								case fail.ErrInvalidRequest, fail.ErrTimeout:
 									return err
 								default:
 									return err
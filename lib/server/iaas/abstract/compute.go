@@ -18,12 +18,17 @@ package abstract
 
 import (
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	uuid "github.com/satori/go.uuid"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	propsv2 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v2"
+	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/crypt"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -117,6 +122,67 @@ type Image struct {
 	Description string `json:"description,omitempty"`
 	StorageType string `json:"storagetype,omitempty"`
 	DiskSize    int64  `json:"disk_size_Gb,omitempty"`
+	// OSFamily contains the OS distribution of the image (ubuntu, centos, debian, ...), empty if unknown
+	OSFamily string `json:"os_family,omitempty"`
+	// MinDiskGB is the minimum size of the system disk required by the image, 0 if unknown
+	MinDiskGB int `json:"min_disk_gb,omitempty"`
+	// MinRAMGB is the minimum RAM required by the image, 0 if unknown
+	MinRAMGB float32 `json:"min_ram_gb,omitempty"`
+	// Architecture contains the CPU architecture of the image (x86_64, arm64, ...), empty if unknown
+	Architecture string `json:"architecture,omitempty"`
+	// DefaultUser contains the login user provided by the image itself (ubuntu, centos, ...), empty if unknown
+	DefaultUser string `json:"default_user,omitempty"`
+	// ConfidentialComputeCapable tells if the image can boot a confidential VM, false if unknown
+	ConfidentialComputeCapable bool `json:"confidential_compute_capable,omitempty"`
+}
+
+// LoginUser returns the login user provided by the image: DefaultUser if set, otherwise the usual user of its
+// OS family; empty if it cannot be determined
+func (i Image) LoginUser() string {
+	if i.DefaultUser != "" {
+		return i.DefaultUser
+	}
+	family := i.OSFamily
+	if family == "" {
+		family = GuessOSFamily(i.Name)
+	}
+	for _, v := range osFamilies {
+		if v.family == family {
+			return v.defaultUser
+		}
+	}
+	return ""
+}
+
+// osFamilies lists the OS families recognized by GuessOSFamily, with the keywords identifying them in an image name
+// and the login user usually provided by the cloud images of the family
+var osFamilies = []struct {
+	family      string
+	keywords    []string
+	defaultUser string
+}{
+	{"ubuntu", []string{"ubuntu"}, "ubuntu"},
+	{"debian", []string{"debian"}, "debian"},
+	{"centos", []string{"centos"}, "centos"},
+	{"rhel", []string{"rhel", "red hat", "redhat"}, "cloud-user"},
+	{"fedora", []string{"fedora"}, "fedora"},
+	{"suse", []string{"suse", "sles"}, ""},
+	{"coreos", []string{"coreos"}, "core"},
+	{"windows", []string{"windows"}, ""},
+}
+
+// GuessOSFamily returns the OS family deduced from the name of an image, or an empty string if it cannot be
+// determined
+func GuessOSFamily(name string) string {
+	lowered := strings.ToLower(name)
+	for _, v := range osFamilies {
+		for _, k := range v.keywords {
+			if strings.Contains(lowered, k) {
+				return v.family
+			}
+		}
+	}
+	return ""
 }
 
 // HostRequest represents requirements to create host
@@ -143,8 +209,183 @@ type HostRequest struct {
 	Password string
 	// DiskSize allows to ask for a specific size for system disk (in GB)
 	DiskSize int
-	// Use spot instance
+	// SystemDiskSize is the exact size of the system disk (in GB), overriding the sizes of DiskSize, of the template
+	// and the size computed from the number of cores; the creation fails if the image does not fit in (0 means unset)
+	SystemDiskSize int
+	// DiskType allows to ask for a specific type of system disk (meaning depends on the provider, for example
+	// 'pd-ssd' on GCP, 'SATA' on huaweicloud); stacks not supporting it ignore it
+	DiskType string
+	// Spot asks for a spot/preemptible instance, cheaper but that the provider can reclaim at any time;
+	// stacks not supporting it create a standard instance
 	Spot bool
+	// MaxPrice is the maximum hourly price accepted for a spot instance, for providers supporting it
+	// (0 means the current market price)
+	MaxPrice float64
+	// ShieldedVM asks for a host with verified boot (secure boot, vTPM and integrity monitoring)
+	ShieldedVM bool
+	// ConfidentialVM asks for a host with its memory encrypted by the CPU; the template and the image must support it
+	ConfidentialVM bool
+	// AffinityGroup is the name of the placement group of the host, created by the stack at first use; the hosts of a
+	// group are placed on distinct physical hosts if AntiAffinity is set, on the same ones otherwise
+	AffinityGroup string
+	// AntiAffinity tells the hosts of AffinityGroup must not share their physical host
+	AntiAffinity bool
+	// SkipDefaultSecurityGroup tells the stack to not create a security group dedicated to the host, reusing only
+	// the security group(s) of the network; stacks not creating such a dedicated security group ignore it.
+	// Beware: rules then cannot be tuned per host, any rule added to the network security group applies to all its hosts
+	SkipDefaultSecurityGroup bool
+	// SecurityGroups lists the names of existing security groups to bind to the host at creation, in addition to
+	// the default one(s); each one must exist in the network of the host
+	SecurityGroups []string
+	// FixedIPs contains the IP addresses wanted by network (indexed by network ID); networks not listed
+	// get an IP address chosen by the provider
+	FixedIPs map[string]string
+	// NICs sets the position (and optionally the name on provider side) of the network interfaces of the host; networks
+	// of Networks without entry take the remaining positions in their order. Networks[0] keeps holding the default
+	// route whatever its position. Stacks not able to honor it fail the creation
+	NICs []HostNIC
+	// SourceSnapshotID contains the ID of a provider snapshot of a host to restore instead of installing ImageID
+	// (instance snapshot on OpenStack, boot disk snapshot on GCP)
+	SourceSnapshotID string
+	// ProvisionFromScratch forces the execution of all the provisioning phases on a host created from
+	// SourceSnapshotID; otherwise, only the phase setting the credentials is run to not overwrite the restored state
+	ProvisionFromScratch bool
+	// SkipReboots tells to not reboot the host at the end of the provisioning, for images able to apply the
+	// configuration live; a warning is logged if the system reports a reboot is nevertheless required
+	SkipReboots bool
+	// Volumes lists the volumes to create, attach, format and mount once the host is provisioned; stacks ignore it
+	Volumes []VolumeAttachmentSpec
+	// Tags contains the tags (metadata, labels) to set on the host on provider side, for example for cost allocation;
+	// stacks not supporting them ignore them
+	Tags map[string]string
+}
+
+// RunsProvisioningPhases tells if the host created from the request has to go through all the provisioning phases
+func (hr HostRequest) RunsProvisioningPhases() bool {
+	return hr.SourceSnapshotID == "" || hr.ProvisionFromScratch
+}
+
+// CheckFixedIPs validates the content of FixedIPs: each entry must reference a network of Networks
+// and contain an usable IP address of this network
+func (hr HostRequest) CheckFixedIPs() error {
+	for networkID, ip := range hr.FixedIPs {
+		var network *Network
+		for _, n := range hr.Networks {
+			if n.ID == networkID {
+				network = n
+				break
+			}
+		}
+		if network == nil {
+			return fail.InvalidRequestError(
+				fmt.Sprintf("fixed IP '%s' requested on network '%s' the host is not attached to", ip, networkID),
+			)
+		}
+		if err := utils.ValidateFixedIP(network.CIDR, ip); err != nil {
+			return fail.InvalidRequestError(
+				fmt.Sprintf("invalid fixed IP requested on network '%s': %s", network.Name, err.Error()),
+			)
+		}
+	}
+	return nil
+}
+
+// CheckVolumes validates the content of Volumes: each volume must have a size and an absolute mount point, not used
+// by another volume
+func (hr HostRequest) CheckVolumes() error {
+	used := map[string]bool{}
+	for _, v := range hr.Volumes {
+		if v.Size <= 0 {
+			return fail.InvalidRequestError(fmt.Sprintf("invalid size %d for volume mounted on '%s'", v.Size, v.MountPoint))
+		}
+		if !path.IsAbs(v.MountPoint) {
+			return fail.InvalidRequestError(fmt.Sprintf("mount point '%s' of volume is not an absolute path", v.MountPoint))
+		}
+		mountPoint := path.Clean(v.MountPoint)
+		if mountPoint == "/" {
+			return fail.InvalidRequestError("cannot mount a volume on '/'")
+		}
+		if used[mountPoint] {
+			return fail.InvalidRequestError(fmt.Sprintf("mount point '%s' used by several volumes", mountPoint))
+		}
+		used[mountPoint] = true
+	}
+	return nil
+}
+
+// HostNIC describes the network interface wanted for a network of a host
+type HostNIC struct {
+	// NetworkID is the ID of the network (one of HostRequest.Networks)
+	NetworkID string
+	// Index is the position of the interface on the host (0 for the first one, eth0 usually)
+	Index int
+	// Name is the name given to the interface on provider side (optional)
+	Name string
+}
+
+// OrderedNetworks returns the networks of the request in the order of the network interfaces described by NICs,
+// after validation of NICs (network of the request, index in range, no index or network used twice)
+func (hr HostRequest) OrderedNetworks() ([]*Network, error) {
+	if len(hr.NICs) == 0 {
+		return hr.Networks, nil
+	}
+
+	ordered := make([]*Network, len(hr.Networks))
+	placed := map[string]bool{}
+	for _, nic := range hr.NICs {
+		var network *Network
+		for _, n := range hr.Networks {
+			if n.ID == nic.NetworkID {
+				network = n
+				break
+			}
+		}
+		if network == nil {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("network interface requested on network '%s' the host is not attached to", nic.NetworkID),
+			)
+		}
+		if nic.Index < 0 || nic.Index >= len(hr.Networks) {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf(
+					"invalid index %d for network interface on network '%s': must be between 0 and %d", nic.Index,
+					network.Name, len(hr.Networks)-1,
+				),
+			)
+		}
+		if ordered[nic.Index] != nil {
+			return nil, fail.InvalidRequestError(fmt.Sprintf("index %d is used by several network interfaces", nic.Index))
+		}
+		if placed[network.ID] {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("several network interfaces requested on network '%s'", network.Name),
+			)
+		}
+		ordered[nic.Index] = network
+		placed[network.ID] = true
+	}
+
+	i := 0
+	for _, n := range hr.Networks {
+		if placed[n.ID] {
+			continue
+		}
+		for ordered[i] != nil {
+			i++
+		}
+		ordered[i] = n
+	}
+	return ordered, nil
+}
+
+// NICName returns the name wanted for the network interface on the network 'networkID', empty if none
+func (hr HostRequest) NICName(networkID string) string {
+	for _, nic := range hr.NICs {
+		if nic.NetworkID == networkID {
+			return nic.Name
+		}
+	}
+	return ""
 }
 
 // HostDefinition ...
@@ -179,6 +420,8 @@ type Host struct {
 	LastState  hoststate.Enum            `json:"state,omitempty"`
 	PrivateKey string                    `json:"private_key,omitempty"`
 	Password   string                    `json:"password,omitempty"`
+	HostKey    string                    `json:"host_key,omitempty"`    // public key of the SSH server, recorded at first connection
+	NativeUser string                    `json:"native_user,omitempty"` // login user provided by the image, existing before provisioning creates the operator user
 	Properties *serialize.JSONProperties `json:"properties,omitempty"`
 }
 
@@ -234,11 +477,7 @@ func (h *Host) GetPublicIP() string {
 	var ip string
 	err := h.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
 		func(clonable data.Clonable) error {
-			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
-			ip = hostNetworkV1.PublicIPv4
-			if ip == "" {
-				ip = hostNetworkV1.PublicIPv6
-			}
+			ip = publicIP(clonable.(*propsv1.HostNetwork))
 			return nil
 		},
 	)
@@ -253,21 +492,7 @@ func (h *Host) GetPrivateIP() string {
 	var ip string
 	err := h.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
 		func(clonable data.Clonable) error {
-			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
-			if len(hostNetworkV1.IPv4Addresses) > 0 {
-				ip = hostNetworkV1.IPv4Addresses[hostNetworkV1.DefaultNetworkID]
-				if ip == "" {
-					ip = hostNetworkV1.IPv6Addresses[hostNetworkV1.DefaultNetworkID]
-				}
-				if ip == "" { // FIXME: AWS Fix for subnetworks
-					for _, value := range hostNetworkV1.IPv4Addresses {
-						if value != "" {
-							ip = value
-							break
-						}
-					}
-				}
-			}
+			ip = privateIP(clonable.(*propsv1.HostNetwork))
 			return nil
 		},
 	)
@@ -277,6 +502,35 @@ func (h *Host) GetPrivateIP() string {
 	return ip
 }
 
+// publicIP returns the public IP found in the property NetworkV1 of a host
+func publicIP(hostNetworkV1 *propsv1.HostNetwork) string {
+	ip := hostNetworkV1.PublicIPv4
+	if ip == "" {
+		ip = hostNetworkV1.PublicIPv6
+	}
+	return ip
+}
+
+// privateIP returns the private IP found in the property NetworkV1 of a host
+func privateIP(hostNetworkV1 *propsv1.HostNetwork) string {
+	var ip string
+	if len(hostNetworkV1.IPv4Addresses) > 0 {
+		ip = hostNetworkV1.IPv4Addresses[hostNetworkV1.DefaultNetworkID]
+		if ip == "" {
+			ip = hostNetworkV1.IPv6Addresses[hostNetworkV1.DefaultNetworkID]
+		}
+		if ip == "" { // FIXME: AWS Fix for subnetworks
+			for _, value := range hostNetworkV1.IPv4Addresses {
+				if value != "" {
+					ip = value
+					break
+				}
+			}
+		}
+	}
+	return ip
+}
+
 // Content ...
 // satisfies interface data.Clonable
 func (h *Host) Content() data.Clonable {
@@ -301,6 +555,159 @@ func (h *Host) Replace(p data.Clonable) data.Clonable {
 	return h
 }
 
+// FilesystemUsage describes the space and inode usage of a filesystem mounted on a host
+type FilesystemUsage struct {
+	Device     string `json:"device,omitempty"`
+	Type       string `json:"type,omitempty"`
+	MountPoint string `json:"mount_point,omitempty"`
+	Size       uint64 `json:"size"`      // in bytes
+	Used       uint64 `json:"used"`      // in bytes
+	Available  uint64 `json:"available"` // in bytes
+	Inodes     uint64 `json:"inodes"`    // 0 if the filesystem does not report inodes
+	InodesUsed uint64 `json:"inodes_used"`
+	InodesFree uint64 `json:"inodes_free"`
+}
+
+// HostMetrics is a snapshot of the uptime, load and memory of a host
+// The metrics that could not be read are left to zero and listed in Unavailable
+type HostMetrics struct {
+	Uptime      time.Duration `json:"uptime"`
+	Load1       float64       `json:"load1"` // average number of runnable processes over 1 minute
+	Load5       float64       `json:"load5"`
+	Load15      float64       `json:"load15"`
+	MemoryTotal uint64        `json:"memory_total"` // in bytes
+	MemoryUsed  uint64        `json:"memory_used"`  // in bytes, buffers and cache excluded when known
+	MemoryFree  uint64        `json:"memory_free"`  // in bytes
+	CPUCount    int           `json:"cpu_count"`
+	Unavailable []string      `json:"unavailable,omitempty"` // among "uptime", "load", "memory" and "cpu"
+}
+
+// HostNetworkInterface describes a network interface of a host
+type HostNetworkInterface struct {
+	Name        string   `json:"name,omitempty"`        // name of the interface on provider side, if any
+	MACAddress  string   `json:"mac_address,omitempty"` // empty if the provider doesn't give it
+	NetworkID   string   `json:"network_id"`
+	NetworkName string   `json:"network_name,omitempty"`
+	PrivateIPs  []string `json:"private_ips,omitempty"`
+	PublicIP    string   `json:"public_ip,omitempty"`
+	Default     bool     `json:"default,omitempty"` // tells if the default route of the host goes through the interface
+}
+
+// ListeningPort describes a TCP or UDP port on which a host is listening
+type ListeningPort struct {
+	Protocol string `json:"protocol"` // "tcp" or "udp"
+	Address  string `json:"address"`  // local address; "0.0.0.0", "::" or "*" meaning all the addresses
+	Port     int    `json:"port"`
+	Process  string `json:"process,omitempty"` // name(s) of the listening process(es), empty if not readable
+}
+
+// ProcessInfo describes a process running on a host
+type ProcessInfo struct {
+	PID     int     `json:"pid"`
+	PPID    int     `json:"ppid"`
+	User    string  `json:"user"`
+	CPU     float64 `json:"cpu"`     // percentage of CPU used
+	Memory  float64 `json:"memory"`  // percentage of physical memory used
+	Command string  `json:"command"` // name of the command, truncated to 15 characters by the kernel
+}
+
+// ConnTarget is an endpoint whose reachability is checked from a host: either Host and Port (TCP), or URL
+type ConnTarget struct {
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// String returns the target as "host:port" or as its URL
+func (t ConnTarget) String() string {
+	if t.URL != "" {
+		return t.URL
+	}
+	return fmt.Sprintf("%s:%d", t.Host, t.Port)
+}
+
+// ConnResult is the result of the reachability check of a ConnTarget
+type ConnResult struct {
+	Target  ConnTarget    `json:"target"`
+	Success bool          `json:"success"`
+	Latency time.Duration `json:"latency"`         // time taken by the check, successful or not
+	Error   string        `json:"error,omitempty"` // reason of the failure
+}
+
+// HostDetails gathers the information about a host and all its properties, read at once
+// Properties are clones: modifying them does not change the host.
+type HostDetails struct {
+	ID             string
+	Name           string
+	LastState      hoststate.Enum
+	PrivateKey     string
+	Password       string
+	Description    *propsv1.HostDescription
+	Network        *propsv1.HostNetwork
+	Sizing         *propsv1.HostSizing
+	SizingV2       *propsv2.HostSizing
+	Volumes        *propsv1.HostVolumes
+	Mounts         *propsv1.HostMounts
+	Shares         *propsv1.HostShares
+	Features       *propsv1.HostFeatures
+	System         *propsv1.HostSystem
+	Freeze         *propsv1.HostFreeze
+	SecurityGroups *propsv1.HostSecurityGroups
+	NICs           *propsv1.HostNICs
+}
+
+// NewHostDetails returns the details of the host 'h'
+func NewHostDetails(h *Host) (*HostDetails, error) {
+	if h == nil {
+		return nil, fail.InvalidParameterError("h", "cannot be nil")
+	}
+
+	hd := &HostDetails{
+		ID:         h.ID,
+		Name:       h.Name,
+		LastState:  h.LastState,
+		PrivateKey: h.PrivateKey,
+		Password:   h.Password,
+	}
+	properties := map[string]func(data.Clonable){
+		hostproperty.DescriptionV1:    func(c data.Clonable) { hd.Description = c.(*propsv1.HostDescription) },
+		hostproperty.NetworkV1:        func(c data.Clonable) { hd.Network = c.(*propsv1.HostNetwork) },
+		hostproperty.SizingV1:         func(c data.Clonable) { hd.Sizing = c.(*propsv1.HostSizing) },
+		hostproperty.SizingV2:         func(c data.Clonable) { hd.SizingV2 = c.(*propsv2.HostSizing) },
+		hostproperty.VolumesV1:        func(c data.Clonable) { hd.Volumes = c.(*propsv1.HostVolumes) },
+		hostproperty.MountsV1:         func(c data.Clonable) { hd.Mounts = c.(*propsv1.HostMounts) },
+		hostproperty.SharesV1:         func(c data.Clonable) { hd.Shares = c.(*propsv1.HostShares) },
+		hostproperty.FeaturesV1:       func(c data.Clonable) { hd.Features = c.(*propsv1.HostFeatures) },
+		hostproperty.SystemV1:         func(c data.Clonable) { hd.System = c.(*propsv1.HostSystem) },
+		hostproperty.FreezeV1:         func(c data.Clonable) { hd.Freeze = c.(*propsv1.HostFreeze) },
+		hostproperty.SecurityGroupsV1: func(c data.Clonable) { hd.SecurityGroups = c.(*propsv1.HostSecurityGroups) },
+		hostproperty.NICsV1:           func(c data.Clonable) { hd.NICs = c.(*propsv1.HostNICs) },
+	}
+	for key, set := range properties {
+		set := set
+		err := h.Properties.LockForRead(key).ThenUse(
+			func(clonable data.Clonable) error {
+				set(clonable)
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hd, nil
+}
+
+// GetPublicIP returns the public IP of the host
+func (hd *HostDetails) GetPublicIP() string {
+	return publicIP(hd.Network)
+}
+
+// GetPrivateIP returns the private IP of the host
+func (hd *HostDetails) GetPrivateIP() string {
+	return privateIP(hd.Network)
+}
+
 // Serialize serializes Host instance into bytes (output json code)
 func (h *Host) Serialize() ([]byte, error) {
 	return serialize.ToJSON(h)
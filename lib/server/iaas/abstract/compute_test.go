@@ -0,0 +1,204 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abstract
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
+	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	"github.com/CS-SI/SafeScale/lib/utils/data"
+)
+
+func TestNewHostDetails(t *testing.T) {
+	host := NewHost()
+	host.ID = "id"
+	host.Name = "host"
+	err := host.Properties.LockForWrite(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+			hostNetworkV1.DefaultNetworkID = "net-id"
+			hostNetworkV1.IPv4Addresses["net-id"] = "192.168.0.10"
+			hostNetworkV1.NetworksByName["net"] = "net-id"
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := NewHostDetails(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Name != "host" || details.GetPrivateIP() != "192.168.0.10" {
+		t.Errorf("unexpected details: %+v", details)
+	}
+	if details.Sizing == nil || details.Volumes == nil || details.Mounts == nil || details.Shares == nil ||
+		details.Features == nil || details.System == nil || details.Description == nil {
+		t.Error("missing properties in details")
+	}
+
+	// details must not share state with the host
+	details.Network.NetworksByName["other"] = "other-id"
+	err = host.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
+		func(clonable data.Clonable) error {
+			if _, ok := clonable.(*propsv1.HostNetwork).NetworksByName["other"]; ok {
+				t.Error("details share state with host")
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHostRequestCheckFixedIPs(t *testing.T) {
+	networks := []*Network{{ID: "net-id", Name: "net", CIDR: "192.168.0.0/24"}}
+
+	tests := []struct {
+		name     string
+		fixedIPs map[string]string
+		wantErr  bool
+	}{
+		{"none", nil, false},
+		{"valid", map[string]string{"net-id": "192.168.0.10"}, false},
+		{"unknown network", map[string]string{"other-id": "192.168.0.10"}, true},
+		{"outside cidr", map[string]string{"net-id": "192.168.1.10"}, true},
+		{"invalid ip", map[string]string{"net-id": "192.168.0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hr := HostRequest{Networks: networks, FixedIPs: tt.fixedIPs}
+			err := hr.CheckFixedIPs()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckFixedIPs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostRequestCheckVolumes(t *testing.T) {
+	tests := []struct {
+		name    string
+		volumes []VolumeAttachmentSpec
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", []VolumeAttachmentSpec{{Size: 10, MountPoint: "/data"}, {Size: 20, MountPoint: "/logs/"}}, false},
+		{"relative", []VolumeAttachmentSpec{{Size: 10, MountPoint: "data"}}, true},
+		{"root", []VolumeAttachmentSpec{{Size: 10, MountPoint: "/"}}, true},
+		{"no size", []VolumeAttachmentSpec{{MountPoint: "/data"}}, true},
+		{"duplicate", []VolumeAttachmentSpec{{Size: 10, MountPoint: "/data"}, {Size: 20, MountPoint: "/data/"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hr := HostRequest{Volumes: tt.volumes}
+			err := hr.CheckVolumes()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckVolumes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostRequestOrderedNetworks(t *testing.T) {
+	wan := &Network{ID: "wan-id", Name: "wan"}
+	lan := &Network{ID: "lan-id", Name: "lan"}
+	dmz := &Network{ID: "dmz-id", Name: "dmz"}
+	networks := []*Network{lan, wan, dmz}
+
+	tests := []struct {
+		name    string
+		nics    []HostNIC
+		want    []*Network
+		wantErr bool
+	}{
+		{"none", nil, []*Network{lan, wan, dmz}, false},
+		{"first", []HostNIC{{NetworkID: "wan-id", Index: 0}}, []*Network{wan, lan, dmz}, false},
+		{"last", []HostNIC{{NetworkID: "lan-id", Index: 2}}, []*Network{wan, dmz, lan}, false},
+		{"all", []HostNIC{{NetworkID: "dmz-id", Index: 0}, {NetworkID: "lan-id", Index: 1}, {NetworkID: "wan-id", Index: 2}}, []*Network{dmz, lan, wan}, false},
+		{"duplicate index", []HostNIC{{NetworkID: "wan-id", Index: 0}, {NetworkID: "lan-id", Index: 0}}, nil, true},
+		{"duplicate network", []HostNIC{{NetworkID: "wan-id", Index: 0}, {NetworkID: "wan-id", Index: 1}}, nil, true},
+		{"out of range", []HostNIC{{NetworkID: "wan-id", Index: 3}}, nil, true},
+		{"negative", []HostNIC{{NetworkID: "wan-id", Index: -1}}, nil, true},
+		{"unknown network", []HostNIC{{NetworkID: "other-id", Index: 0}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hr := HostRequest{Networks: networks, NICs: tt.nics}
+			got, err := hr.OrderedNetworks()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OrderedNetworks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderedNetworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuessOSFamily(t *testing.T) {
+	tests := map[string]string{
+		"Ubuntu 18.04":                 "ubuntu",
+		"ubuntu-1804-bionic-v20200923": "ubuntu",
+		"CentOS 7.3":                   "centos",
+		"debian-10-buster-v20200910":   "debian",
+		"rhel-8-v20200910":             "rhel",
+		"Red Hat Enterprise Linux 7":   "rhel",
+		"sles-15-sp1-v20200415":        "suse",
+		"Windows Server 2019":          "windows",
+		"my-custom-image":              "",
+	}
+	for name, want := range tests {
+		if got := GuessOSFamily(name); got != want {
+			t.Errorf("GuessOSFamily(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestHostRequestRunsProvisioningPhases(t *testing.T) {
+	if !(HostRequest{}).RunsProvisioningPhases() {
+		t.Error("host installed from image must run the provisioning phases")
+	}
+	if (HostRequest{SourceSnapshotID: "snap"}).RunsProvisioningPhases() {
+		t.Error("host restored from snapshot must not run the provisioning phases")
+	}
+	if !(HostRequest{SourceSnapshotID: "snap", ProvisionFromScratch: true}).RunsProvisioningPhases() {
+		t.Error("host restored from snapshot with ProvisionFromScratch must run the provisioning phases")
+	}
+}
+
+func TestImageLoginUser(t *testing.T) {
+	tests := []struct {
+		image Image
+		want  string
+	}{
+		{Image{Name: "Ubuntu 18.04"}, "ubuntu"},
+		{Image{Name: "my-image", OSFamily: "centos"}, "centos"},
+		{Image{Name: "rhel-8-v20200910"}, "cloud-user"},
+		{Image{Name: "Ubuntu 18.04", DefaultUser: "admin"}, "admin"},
+		{Image{Name: "my-custom-image"}, ""},
+	}
+	for _, test := range tests {
+		if got := test.image.LoginUser(); got != test.want {
+			t.Errorf("LoginUser() of %q = %q, want %q", test.image.Name, got, test.want)
+		}
+	}
+}
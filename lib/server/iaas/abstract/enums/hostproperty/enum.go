@@ -31,4 +31,18 @@ const (
 	SharesV1 = "6"
 	// MountsV1 contains optional additional info about mounted devices (locally attached or remote filesystem)
 	MountsV1 = "7"
+	// SystemV1 contains optional additional info about the operating system of the host
+	SystemV1 = "8"
+	// FreezeV1 contains optional additional info about the protection of the host against mutations
+	FreezeV1 = "9"
+	// SecurityGroupsV1 contains optional additional info about the security groups bound to the host
+	SecurityGroupsV1 = "10"
+	// NICsV1 contains optional additional info about the order and the names of the network interfaces of the host
+	NICsV1 = "11"
+	// SnapshotsV1 contains optional additional info about the snapshots taken of the host
+	SnapshotsV1 = "12"
+	// KernelParametersV1 contains optional additional info about the kernel parameters applied on the host
+	KernelParametersV1 = "13"
+	// SizingV2 contains additional info about the sizing of the host completing SizingV1 (type of the boot disk)
+	SizingV2 = "14"
 )
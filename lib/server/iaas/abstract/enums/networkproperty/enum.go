@@ -21,4 +21,8 @@ const (
 	DescriptionV1 = "1"
 	// HostsV1 contains list of hosts attached to the network
 	HostsV1 = "2"
+	// JumpHostsV1 contains the ordered list of jump hosts to cross before reaching the gateway of the network
+	JumpHostsV1 = "3"
+	// RoutesV1 contains the static routes injected in the network
+	RoutesV1 = "4"
 )
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package securitygroupruledirection defines an enum to represent the direction of the traffic a security group rule applies to
+package securitygroupruledirection
+
+//go:generate stringer -type=Enum
+
+// Enum represents the direction of the traffic a security group rule applies to
+type Enum int
+
+const (
+	// UNKNOWN is the zero value, not a valid direction
+	UNKNOWN Enum = iota
+	// INGRESS applies the rule to the traffic coming into the host
+	INGRESS
+	// EGRESS applies the rule to the traffic going out of the host
+	EGRESS
+)
@@ -17,10 +17,15 @@
 package abstract
 
 import (
+	"fmt"
+	"net"
+	"strings"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
 	"github.com/CS-SI/SafeScale/lib/utils/serialize"
 )
 
@@ -38,6 +43,10 @@ type GatewayRequest struct {
 
 	// OriginalOsRequest is the original os requested
 	OriginalOsRequest string
+
+	// AffinityGroup and AntiAffinity are passed to the host request of the gateway (see HostRequest)
+	AffinityGroup string
+	AntiAffinity  bool
 }
 
 // NetworkRequest represents network requirements to create a subnet where Mask is defined in CIDR notation
@@ -54,6 +63,8 @@ type NetworkRequest struct {
 	Domain string
 	// HA tells if 2 gateways and a VIP needs to be created; the VIP IP address will be used as gateway
 	HA bool
+	// Tags contains the tags to set on the network on provider side; stacks not supporting them ignore them
+	Tags map[string]string
 }
 
 type SubNetwork struct {
@@ -61,6 +72,32 @@ type SubNetwork struct {
 	ID   string `json:"subnetid,omitempty"`
 }
 
+// NetworkRoute represents a static route injected in a network
+type NetworkRoute struct {
+	Destination string `json:"destination"` // destination of the route, in CIDR notation
+	NextHop     string `json:"next_hop"`    // IP address, inside the network, of the host routing the traffic
+}
+
+// NetworkTopology describes a network with its gateways, the hosts attached to it and its subnets, described the same way
+type NetworkTopology struct {
+	ID       string                   `json:"id,omitempty"`
+	Name     string                   `json:"name,omitempty"`
+	CIDR     string                   `json:"cidr,omitempty"`
+	Gateways []NetworkTopologyGateway `json:"gateways,omitempty"`
+	Hosts    []string                 `json:"hosts,omitempty"`   // names of the hosts attached, gateways excluded
+	Subnets  []*NetworkTopology       `json:"subnets,omitempty"` // subnets of the network, sorted by CIDR
+}
+
+// NetworkTopologyGateway describes a gateway of a network in a NetworkTopology
+type NetworkTopologyGateway struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	PrivateIP string `json:"private_ip,omitempty"`
+	PublicIP  string `json:"public_ip,omitempty"`
+	Primary   bool   `json:"primary,omitempty"`
+	Missing   bool   `json:"missing,omitempty"` // tells the metadata of the gateway are missing, only its ID is known
+}
+
 // Network represents a virtual network
 type Network struct {
 	ID                 string                    `json:"id,omitempty"`                   // ID for the network (from provider)
@@ -71,6 +108,7 @@ type Network struct {
 	SecondaryGatewayID string                    `json:"secondary_gateway_id,omitempty"` // contains the id of the host acting as secondary gateway for the network
 	VIP                *VirtualIP                `json:"vip,omitempty"`                  // contains the VIP of the network if created with HA
 	IPVersion          ipversion.Enum            `json:"ip_version,omitempty"`           // IPVersion is IPv4 or IPv6 (see IPVersion)
+	DNSServers         []string                  `json:"dns_servers,omitempty"`          // contains the DNS servers used by hosts of the network; empty means provider defaults
 	Properties         *serialize.JSONProperties `json:"properties,omitempty"`           // contains optional supplemental information
 
 	Subnetworks []SubNetwork `json:"subnetworks,omitempty"` // FIXME: comment!
@@ -114,6 +152,56 @@ func (n *Network) OK() bool {
 	return result
 }
 
+// GetCIDR returns the CIDR of the network, normalized to its network address (IPv4 or IPv6)
+func (n *Network) GetCIDR() (string, fail.Error) {
+	ipnet, err := n.ipNet()
+	if err != nil {
+		return "", err
+	}
+	return ipnet.String(), nil
+}
+
+// Contains tells if ip belongs to the CIDR of the network, network and broadcast addresses included
+func (n *Network) Contains(ip string) (bool, fail.Error) {
+	ipnet, err := n.ipNet()
+	if err != nil {
+		return false, err
+	}
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil {
+		return false, fail.InvalidParameterError("ip", fmt.Sprintf("'%s' is not a valid IP address", ip))
+	}
+	return ipnet.Contains(addr), nil
+}
+
+// Overlaps tells if the CIDR of the network and cidr have addresses in common
+func (n *Network) Overlaps(cidr string) (bool, fail.Error) {
+	ipnet, err := n.ipNet()
+	if err != nil {
+		return false, err
+	}
+	_, other, perr := net.ParseCIDR(strings.TrimSpace(cidr))
+	if perr != nil {
+		return false, fail.InvalidParameterError("cidr", fmt.Sprintf("'%s' is not a valid CIDR", cidr))
+	}
+	return ipnet.Contains(other.IP) || other.Contains(ipnet.IP), nil
+}
+
+// ipNet parses the CIDR of the network
+func (n *Network) ipNet() (*net.IPNet, fail.Error) {
+	if n == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+	if n.CIDR == "" {
+		return nil, fail.InvalidInstanceContentError("n.CIDR", "cannot be empty string")
+	}
+	_, ipnet, err := net.ParseCIDR(n.CIDR)
+	if err != nil {
+		return nil, fail.InvalidInstanceContentError("n.CIDR", fmt.Sprintf("'%s' is not a valid CIDR", n.CIDR))
+	}
+	return ipnet, nil
+}
+
 // Serialize serializes Host instance into bytes (output json code)
 func (n *Network) Serialize() ([]byte, error) {
 	return serialize.ToJSON(n)
@@ -29,3 +29,77 @@ func TestVirtualIP_Clone(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestNetworkContains(t *testing.T) {
+	network := NewNetwork()
+	network.CIDR = "192.168.1.0/24"
+	cases := map[string]bool{
+		"192.168.1.0":   true,
+		"192.168.1.1":   true,
+		"192.168.1.255": true,
+		"192.168.0.255": false,
+		"192.168.2.0":   false,
+		"fd00::1":       false,
+	}
+	for ip, expected := range cases {
+		got, err := network.Contains(ip)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %v", ip, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("Contains('%s'): expected %v, got %v", ip, expected, got)
+		}
+	}
+
+	if _, err := network.Contains("not-an-ip"); err == nil {
+		t.Error("Contains() should fail on invalid IP")
+	}
+}
+
+func TestNetworkContainsIPv6(t *testing.T) {
+	network := NewNetwork()
+	network.CIDR = "fd00:10::/64"
+	cases := map[string]bool{
+		"fd00:10::":                    true,
+		"fd00:10::ffff:ffff:ffff:ffff": true,
+		"fd00:10:0:1::":                false,
+		"10.0.0.1":                     false,
+	}
+	for ip, expected := range cases {
+		got, err := network.Contains(ip)
+		if err != nil {
+			t.Errorf("unexpected error for '%s': %v", ip, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("Contains('%s'): expected %v, got %v", ip, expected, got)
+		}
+	}
+}
+
+func TestNetworkGetCIDRAndOverlaps(t *testing.T) {
+	network := NewNetwork()
+	if _, err := network.GetCIDR(); err == nil {
+		t.Error("GetCIDR() should fail when CIDR is not set")
+	}
+
+	network.CIDR = "10.0.1.12/22"
+	cidr, err := network.GetCIDR()
+	if err != nil || cidr != "10.0.0.0/22" {
+		t.Errorf("GetCIDR(): expected '10.0.0.0/22', got '%s' (%v)", cidr, err)
+	}
+
+	overlaps, err := network.Overlaps("10.0.3.0/24")
+	if err != nil || !overlaps {
+		t.Errorf("Overlaps('10.0.3.0/24'): expected true, got %v (%v)", overlaps, err)
+	}
+	overlaps, err = network.Overlaps("10.0.4.0/24")
+	if err != nil || overlaps {
+		t.Errorf("Overlaps('10.0.4.0/24'): expected false, got %v (%v)", overlaps, err)
+	}
+	overlaps, err = network.Overlaps("10.0.0.0/8")
+	if err != nil || !overlaps {
+		t.Errorf("Overlaps('10.0.0.0/8'): expected true, got %v (%v)", overlaps, err)
+	}
+}
@@ -36,6 +36,32 @@ type HostDescription struct {
 	Purpose string    `json:"purpose,omitempty"`  // contains a description of the use of a host
 	Tenant  string    `json:"tenant"`             // contains the tenant name used to create the host
 	Domain  string    `json:"domain,omitempty"`   // contains the domain used to define host FQDN
+	// SourceSnapshotID contains the ID of the snapshot the host has been restored from, if any
+	SourceSnapshotID string `json:"source_snapshot_id,omitempty"`
+	// ProvisioningSkipped tells the provisioning phases following the setup of credentials have not been run on the
+	// host (restored from a snapshot), so the system and the features installed are the ones of the snapshot
+	ProvisioningSkipped bool `json:"provisioning_skipped,omitempty"`
+	// Adopted tells the host has been created outside of SafeScale and imported afterwards
+	Adopted bool `json:"adopted,omitempty"`
+	// Spot tells the host is a spot/preemptible instance, that the provider can reclaim at any time
+	Spot bool `json:"spot,omitempty"`
+	// Preempted tells the spot host has been stopped by the provider to reclaim its resources
+	Preempted bool `json:"preempted,omitempty"`
+	// ShieldedVM tells the host has been created with verified boot (secure boot, vTPM and integrity monitoring)
+	ShieldedVM bool `json:"shielded_vm,omitempty"`
+	// ConfidentialVM tells the memory of the host is encrypted by the CPU
+	ConfidentialVM bool `json:"confidential_vm,omitempty"`
+	// Tags contains the tags set on the host on provider side, mandatory tags of the tenant included
+	Tags map[string]string `json:"tags,omitempty"`
+	// Region contains the region where the host runs; empty for the hosts created in the region of the tenant before
+	// it was recorded
+	Region string `json:"region,omitempty"`
+	// AvailabilityZone contains the availability zone (the zone on GCP) where the host runs
+	AvailabilityZone string `json:"availability_zone,omitempty"`
+	// AffinityGroup contains the name of the placement group of the host, if any
+	AffinityGroup string `json:"affinity_group,omitempty"`
+	// AntiAffinity tells the hosts of AffinityGroup are placed on distinct physical hosts
+	AntiAffinity bool `json:"anti_affinity,omitempty"`
 }
 
 // NewHostDescription ...
@@ -63,7 +89,14 @@ func (hd *HostDescription) Clone() data.Clonable {
 // Replace ...
 // satisfies interface data.Clonable
 func (hd *HostDescription) Replace(p data.Clonable) data.Clonable {
-	*hd = *p.(*HostDescription)
+	src := p.(*HostDescription)
+	*hd = *src
+	if src.Tags != nil {
+		hd.Tags = make(map[string]string, len(src.Tags))
+		for k, v := range src.Tags {
+			hd.Tags[k] = v
+		}
+	}
 	return hd
 }
 
@@ -259,6 +292,276 @@ func (p *HostSystem) Reset() {
 	*p = HostSystem{}
 }
 
+// Content ...
+// satisfies interface data.Clonable
+func (p *HostSystem) Content() data.Clonable {
+	return p
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (p *HostSystem) Clone() data.Clonable {
+	return NewHostSystem().Replace(p)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (p *HostSystem) Replace(v data.Clonable) data.Clonable {
+	*p = *v.(*HostSystem)
+	return p
+}
+
+// HostFreeze contains information about the protection of the host against mutations (deletion, resize, ...)
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostFreeze struct {
+	Frozen   bool      `json:"frozen,omitempty"`    // tells if the mutations of the host are forbidden
+	FrozenBy string    `json:"frozen_by,omitempty"` // contains information (forged) about who froze the host the last time
+	FrozenAt time.Time `json:"frozen_at,omitempty"` // tells when the host has been frozen the last time
+	ThawedBy string    `json:"thawed_by,omitempty"` // contains information (forged) about who thawed the host the last time
+	ThawedAt time.Time `json:"thawed_at,omitempty"` // tells when the host has been thawed the last time
+}
+
+// NewHostFreeze ...
+func NewHostFreeze() *HostFreeze {
+	return &HostFreeze{}
+}
+
+// Reset ...
+func (p *HostFreeze) Reset() {
+	*p = HostFreeze{}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (p *HostFreeze) Content() data.Clonable {
+	return p
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (p *HostFreeze) Clone() data.Clonable {
+	return NewHostFreeze().Replace(p)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (p *HostFreeze) Replace(v data.Clonable) data.Clonable {
+	*p = *v.(*HostFreeze)
+	return p
+}
+
+// HostSecurityGroup contains information about a security group bound to the host
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostSecurityGroup struct {
+	Name        string `json:"name"`                   // name of the security group
+	FromNetwork bool   `json:"from_network,omitempty"` // tells if the security group is bound because of the network of the host
+}
+
+// HostSecurityGroups contains information about the security groups bound to the host
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostSecurityGroups struct {
+	ByName map[string]*HostSecurityGroup `json:"by_name"`
+}
+
+// NewHostSecurityGroups ...
+func NewHostSecurityGroups() *HostSecurityGroups {
+	return &HostSecurityGroups{
+		ByName: map[string]*HostSecurityGroup{},
+	}
+}
+
+// Reset ...
+func (p *HostSecurityGroups) Reset() {
+	*p = HostSecurityGroups{
+		ByName: map[string]*HostSecurityGroup{},
+	}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (p *HostSecurityGroups) Content() data.Clonable {
+	return p
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (p *HostSecurityGroups) Clone() data.Clonable {
+	return NewHostSecurityGroups().Replace(p)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (p *HostSecurityGroups) Replace(v data.Clonable) data.Clonable {
+	src := v.(*HostSecurityGroups)
+	p.ByName = make(map[string]*HostSecurityGroup, len(src.ByName))
+	for k, v := range src.ByName {
+		sg := *v
+		p.ByName[k] = &sg
+	}
+	return p
+}
+
+// HostNIC contains information about a network interface of the host
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostNIC struct {
+	Index       int    `json:"index"`          // position of the interface on the host
+	NetworkID   string `json:"network_id"`     // ID of the network of the interface
+	NetworkName string `json:"network_name"`   // name of the network of the interface
+	Name        string `json:"name,omitempty"` // name of the interface on provider side
+}
+
+// HostNICs contains information about the order of the network interfaces of the host, when set at creation
+// (complements HostNetwork, frozen)
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostNICs struct {
+	ByIndex []HostNIC `json:"by_index,omitempty"` // interfaces sorted by index
+}
+
+// NewHostNICs ...
+func NewHostNICs() *HostNICs {
+	return &HostNICs{}
+}
+
+// Reset ...
+func (p *HostNICs) Reset() {
+	*p = HostNICs{}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (p *HostNICs) Content() data.Clonable {
+	return p
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (p *HostNICs) Clone() data.Clonable {
+	return NewHostNICs().Replace(p)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (p *HostNICs) Replace(v data.Clonable) data.Clonable {
+	src := v.(*HostNICs)
+	p.ByIndex = nil
+	if src.ByIndex != nil {
+		p.ByIndex = make([]HostNIC, len(src.ByIndex))
+		copy(p.ByIndex, src.ByIndex)
+	}
+	return p
+}
+
+// HostSnapshot contains information about a snapshot of the host
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostSnapshot struct {
+	ID        string    `json:"id"`                 // ID of the snapshot at the provider
+	Name      string    `json:"name"`               // name of the snapshot
+	Quiesced  bool      `json:"quiesced,omitempty"` // tells if the filesystem was frozen while the snapshot was taken
+	CreatedAt time.Time `json:"created_at"`         // tells when the snapshot has been taken
+}
+
+// HostSnapshots contains information about the snapshots taken of the host
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostSnapshots struct {
+	List []HostSnapshot `json:"list,omitempty"` // snapshots sorted by creation date
+}
+
+// NewHostSnapshots ...
+func NewHostSnapshots() *HostSnapshots {
+	return &HostSnapshots{}
+}
+
+// Reset ...
+func (p *HostSnapshots) Reset() {
+	*p = HostSnapshots{}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (p *HostSnapshots) Content() data.Clonable {
+	return p
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (p *HostSnapshots) Clone() data.Clonable {
+	return NewHostSnapshots().Replace(p)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (p *HostSnapshots) Replace(v data.Clonable) data.Clonable {
+	src := v.(*HostSnapshots)
+	p.List = nil
+	if src.List != nil {
+		p.List = make([]HostSnapshot, len(src.List))
+		copy(p.List, src.List)
+	}
+	return p
+}
+
+// HostKernelParameters contains information about the kernel parameters (sysctl) applied on the host by SafeScale
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostKernelParameters struct {
+	ByName    map[string]string `json:"by_name"`              // values of the parameters, indexed by name
+	UpdatedBy string            `json:"updated_by,omitempty"` // contains information (forged) about who changed the parameters the last time
+	UpdatedAt time.Time         `json:"updated_at,omitempty"` // tells when the parameters have been changed the last time
+}
+
+// NewHostKernelParameters ...
+func NewHostKernelParameters() *HostKernelParameters {
+	return &HostKernelParameters{
+		ByName: map[string]string{},
+	}
+}
+
+// Reset ...
+func (p *HostKernelParameters) Reset() {
+	*p = HostKernelParameters{
+		ByName: map[string]string{},
+	}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (p *HostKernelParameters) Content() data.Clonable {
+	return p
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (p *HostKernelParameters) Clone() data.Clonable {
+	return NewHostKernelParameters().Replace(p)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (p *HostKernelParameters) Replace(v data.Clonable) data.Clonable {
+	src := v.(*HostKernelParameters)
+	*p = *src
+	p.ByName = make(map[string]string, len(src.ByName))
+	for k, v := range src.ByName {
+		p.ByName[k] = v
+	}
+	return p
+}
+
 // HostVolume contains information about attached volume
 // !!! FROZEN !!!
 // Note: if tagged as FROZEN, must not be changed ever.
@@ -627,6 +930,7 @@ type HostInstalledFeature struct {
 	HostContext bool     `json:"host_context,omitempty"` // tells if the feature has been explicitly installed for host (opposed to for cluster)
 	RequiredBy  []string `json:"required_by,omitempty"`  // tells what feature(s) needs this one
 	Requires    []string `json:"requires,omitempty"`
+	Version     string   `json:"version,omitempty"` // version of the feature installed, if the feature declares one
 }
 
 // NewHostInstalledFeature ...
@@ -661,6 +965,8 @@ func (hif *HostInstalledFeature) Clone() data.Clonable {
 // satisfies interface data.Clonable
 func (hif *HostInstalledFeature) Replace(p data.Clonable) data.Clonable {
 	src := p.(*HostInstalledFeature)
+	hif.HostContext = src.HostContext
+	hif.Version = src.Version
 	hif.RequiredBy = make([]string, len(src.RequiredBy))
 	copy(hif.RequiredBy, src.RequiredBy)
 	hif.Requires = make([]string, len(src.Requires))
@@ -718,4 +1024,10 @@ func init() {
 	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.VolumesV1, NewHostVolumes())
 	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.MountsV1, NewHostMounts())
 	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.FeaturesV1, NewHostFeatures())
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.SystemV1, NewHostSystem())
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.FreezeV1, NewHostFreeze())
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.SecurityGroupsV1, NewHostSecurityGroups())
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.NICsV1, NewHostNICs())
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.SnapshotsV1, NewHostSnapshots())
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.KernelParametersV1, NewHostKernelParameters())
 }
@@ -26,6 +26,60 @@ func TestHostDescription_Clone(t *testing.T) {
 	}
 }
 
+func TestHostDescription_CloneTags(t *testing.T) {
+	ct := NewHostDescription()
+	ct.Tags = map[string]string{"CostCenter": "42"}
+
+	clonedCt, ok := ct.Clone().(*HostDescription)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.Tags["CostCenter"] = "43"
+	assert.Equal(t, "42", ct.Tags["CostCenter"])
+}
+
+func TestHostSystem_Clone(t *testing.T) {
+	ct := NewHostSystem()
+	ct.HostName = "node1.example.com"
+
+	clonedCt, ok := ct.Clone().(*HostSystem)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.HostName = "node2.example.com"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
+
+func TestHostFreeze_Clone(t *testing.T) {
+	ct := NewHostFreeze()
+	ct.Frozen = true
+	ct.FrozenBy = "Someone"
+
+	clonedCt, ok := ct.Clone().(*HostFreeze)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.Frozen = false
+	clonedCt.ThawedBy = "Other"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
+
 func TestHostNetwork_Clone(t *testing.T) {
 	ct := NewHostNetwork()
 	ct.IPv4Addresses["something"] = "else"
@@ -152,6 +206,8 @@ func TestHostShares_Clone(t *testing.T) {
 func TestHostInstalledFeature_Clone(t *testing.T) {
 	ct := NewHostInstalledFeature()
 	ct.Requires = append(ct.Requires, "DarkestRoads")
+	ct.HostContext = true
+	ct.Version = "1.2"
 
 	clonedCt, ok := ct.Clone().(*HostInstalledFeature)
 	if !ok {
@@ -188,3 +244,79 @@ func TestHostFeatures_Clone(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestHostSecurityGroups_Clone(t *testing.T) {
+	ct := NewHostSecurityGroups()
+	ct.ByName["web"] = &HostSecurityGroup{Name: "web"}
+
+	clonedCt, ok := ct.Clone().(*HostSecurityGroups)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.ByName["web"].FromNetwork = true
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
+
+func TestHostNICs_Clone(t *testing.T) {
+	ct := NewHostNICs()
+	ct.ByIndex = append(ct.ByIndex, HostNIC{Index: 0, NetworkID: "id", NetworkName: "wan", Name: "wan0"})
+
+	clonedCt, ok := ct.Clone().(*HostNICs)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.ByIndex[0].Name = "lan0"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
+
+func TestHostKernelParameters_Clone(t *testing.T) {
+	ct := NewHostKernelParameters()
+	ct.ByName["vm.swappiness"] = "10"
+
+	clonedCt, ok := ct.Clone().(*HostKernelParameters)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.ByName["vm.swappiness"] = "60"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
+
+func TestHostSnapshots_Clone(t *testing.T) {
+	ct := NewHostSnapshots()
+	ct.List = append(ct.List, HostSnapshot{ID: "id", Name: "golden", Quiesced: true})
+
+	clonedCt, ok := ct.Clone().(*HostSnapshots)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.List[0].Name = "backup"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
@@ -111,7 +111,106 @@ func (nh *NetworkHosts) Replace(p data.Clonable) data.Clonable {
 	return nh
 }
 
+// NetworkJumpHosts contains the ordered list of hosts to cross (ProxyJump-style) to reach the gateway of the network
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with needed supplemental/overriding fields
+type NetworkJumpHosts struct {
+	// Hosts contains the IDs of the jump hosts, the first one being the one directly reachable from the daemon
+	// and the last one being the one directly reaching the gateway of the network
+	Hosts []string `json:"hosts,omitempty"`
+}
+
+// NewNetworkJumpHosts ...
+func NewNetworkJumpHosts() *NetworkJumpHosts {
+	return &NetworkJumpHosts{
+		Hosts: []string{},
+	}
+}
+
+// Reset resets the content of the property
+func (njh *NetworkJumpHosts) Reset() {
+	*njh = NetworkJumpHosts{
+		Hosts: []string{},
+	}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (njh *NetworkJumpHosts) Content() data.Clonable {
+	return njh
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (njh *NetworkJumpHosts) Clone() data.Clonable {
+	return NewNetworkJumpHosts().Replace(njh)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (njh *NetworkJumpHosts) Replace(p data.Clonable) data.Clonable {
+	src := p.(*NetworkJumpHosts)
+	njh.Hosts = make([]string, len(src.Hosts))
+	copy(njh.Hosts, src.Hosts)
+	return njh
+}
+
+// NetworkRoute describes a static route of the network
+// !!! FROZEN !!!
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with needed supplemental/overriding fields
+type NetworkRoute struct {
+	Destination string `json:"destination"` // destination of the route, in CIDR notation
+	NextHop     string `json:"next_hop"`    // IP address of the host routing the traffic
+}
+
+// NetworkRoutes contains the static routes injected in the network
+// not FROZEN yet
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with needed supplemental/overriding fields
+type NetworkRoutes struct {
+	Routes []NetworkRoute `json:"routes,omitempty"`
+}
+
+// NewNetworkRoutes ...
+func NewNetworkRoutes() *NetworkRoutes {
+	return &NetworkRoutes{
+		Routes: []NetworkRoute{},
+	}
+}
+
+// Reset resets the content of the property
+func (nr *NetworkRoutes) Reset() {
+	*nr = NetworkRoutes{
+		Routes: []NetworkRoute{},
+	}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (nr *NetworkRoutes) Content() data.Clonable {
+	return nr
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (nr *NetworkRoutes) Clone() data.Clonable {
+	return NewNetworkRoutes().Replace(nr)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (nr *NetworkRoutes) Replace(p data.Clonable) data.Clonable {
+	src := p.(*NetworkRoutes)
+	nr.Routes = make([]NetworkRoute, len(src.Routes))
+	copy(nr.Routes, src.Routes)
+	return nr
+}
+
 func init() {
 	serialize.PropertyTypeRegistry.Register("abstract.network", networkproperty.HostsV1, NewNetworkHosts())
 	serialize.PropertyTypeRegistry.Register("abstract.network", networkproperty.DescriptionV1, NewNetworkDescription())
+	serialize.PropertyTypeRegistry.Register("abstract.network", networkproperty.JumpHostsV1, NewNetworkJumpHosts())
+	serialize.PropertyTypeRegistry.Register("abstract.network", networkproperty.RoutesV1, NewNetworkRoutes())
 }
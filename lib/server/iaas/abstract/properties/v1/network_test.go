@@ -44,3 +44,41 @@ func TestNetworkHosts_Clone(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestNetworkJumpHosts_Clone(t *testing.T) {
+	ct := NewNetworkJumpHosts()
+	ct.Hosts = append(ct.Hosts, "bastion", "relay")
+
+	clonedCt, ok := ct.Clone().(*NetworkJumpHosts)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.Hosts[0] = "other"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
+
+func TestNetworkRoutes_Clone(t *testing.T) {
+	ct := NewNetworkRoutes()
+	ct.Routes = append(ct.Routes, NetworkRoute{Destination: "10.1.0.0/16", NextHop: "192.168.0.10"})
+
+	clonedCt, ok := ct.Clone().(*NetworkRoutes)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, ct, clonedCt)
+	clonedCt.Routes[0].NextHop = "192.168.0.11"
+
+	areEqual := reflect.DeepEqual(ct, clonedCt)
+	if areEqual {
+		t.Error("It's a shallow clone !")
+		t.Fail()
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package propertiesv2
+
+import (
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
+	"github.com/CS-SI/SafeScale/lib/utils/data"
+	"github.com/CS-SI/SafeScale/lib/utils/serialize"
+)
+
+// HostSizing contains the sizing information about the host completing propertiesv1.HostSizing
+// !!! FROZEN !!!
+// Note: if tagged as FROZEN, must not be changed ever.
+//       Create a new version instead with updated/additional fields
+type HostSizing struct {
+	BootDiskType string `json:"boot_disk_type,omitempty"` // type of the boot disk, if chosen at creation
+}
+
+// NewHostSizing ...
+func NewHostSizing() *HostSizing {
+	return &HostSizing{}
+}
+
+// Reset ...
+func (hs *HostSizing) Reset() {
+	*hs = HostSizing{}
+}
+
+// Content ...
+// satisfies interface data.Clonable
+func (hs *HostSizing) Content() data.Clonable {
+	return hs
+}
+
+// Clone ...
+// satisfies interface data.Clonable
+func (hs *HostSizing) Clone() data.Clonable {
+	return NewHostSizing().Replace(hs)
+}
+
+// Replace ...
+// satisfies interface data.Clonable
+func (hs *HostSizing) Replace(p data.Clonable) data.Clonable {
+	*hs = *p.(*HostSizing)
+	return hs
+}
+
+func init() {
+	serialize.PropertyTypeRegistry.Register("abstract.host", hostproperty.SizingV2, NewHostSizing())
+}
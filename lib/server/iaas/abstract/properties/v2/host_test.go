@@ -0,0 +1,37 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package propertiesv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostSizing_Clone(t *testing.T) {
+	hs := NewHostSizing()
+	hs.BootDiskType = "SSD"
+
+	cloned, ok := hs.Clone().(*HostSizing)
+	if !ok {
+		t.Fail()
+	}
+
+	assert.Equal(t, hs, cloned)
+	cloned.BootDiskType = "SATA"
+	assert.Equal(t, "SSD", hs.BootDiskType)
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abstract
+
+// UnknownQuota is the value used for a limit or an usage the provider did not report
+const UnknownQuota = -1
+
+// Quota contains the limit and the current usage of a kind of resource
+type Quota struct {
+	Limit     int  `json:"limit"`
+	Used      int  `json:"used"`
+	Unlimited bool `json:"unlimited,omitempty"` // if true, Limit is meaningless
+}
+
+// Known tells if both the limit and the usage have been reported by the provider
+func (q Quota) Known() bool {
+	return q.Limit != UnknownQuota && q.Used != UnknownQuota
+}
+
+// Remaining returns the number of resources still available, UnknownQuota if it cannot be determined
+// or if the resource is unlimited
+func (q Quota) Remaining() int {
+	if q.Unlimited || !q.Known() {
+		return UnknownQuota
+	}
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// Quotas contains the limits and usages of the resources of a tenant
+// Fields the provider cannot report are left to UnknownQuota
+type Quotas struct {
+	Instances      Quota `json:"instances"`
+	VCPUs          Quota `json:"vcpus"`
+	RAM            Quota `json:"ram"` // in MB
+	FloatingIPs    Quota `json:"floating_ips"`
+	Volumes        Quota `json:"volumes"`
+	SecurityGroups Quota `json:"security_groups"`
+}
+
+// NewQuotas returns Quotas with every value set to UnknownQuota
+func NewQuotas() *Quotas {
+	unknown := Quota{Limit: UnknownQuota, Used: UnknownQuota}
+	return &Quotas{
+		Instances:      unknown,
+		VCPUs:          unknown,
+		RAM:            unknown,
+		FloatingIPs:    unknown,
+		Volumes:        unknown,
+		SecurityGroups: unknown,
+	}
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abstract
+
+import (
+	"testing"
+)
+
+func TestQuotaRemaining(t *testing.T) {
+	cases := []struct {
+		quota    Quota
+		expected int
+	}{
+		{Quota{Limit: 10, Used: 3}, 7},
+		{Quota{Limit: 10, Used: 12}, 0},
+		{Quota{Limit: UnknownQuota, Used: 3}, UnknownQuota},
+		{Quota{Limit: 10, Used: UnknownQuota}, UnknownQuota},
+		{Quota{Limit: 0, Used: 3, Unlimited: true}, UnknownQuota},
+	}
+	for _, c := range cases {
+		if got := c.quota.Remaining(); got != c.expected {
+			t.Errorf("Remaining() of %v: expected %d, got %d", c.quota, c.expected, got)
+		}
+	}
+
+	quotas := NewQuotas()
+	if quotas.Instances.Known() || quotas.SecurityGroups.Known() {
+		t.Error("NewQuotas() should return only unknown values")
+	}
+}
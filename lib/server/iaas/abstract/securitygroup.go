@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abstract
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// SecurityGroupRule describes a rule of a security group, allowing a kind of traffic
+type SecurityGroupRule struct {
+	Description string                          `json:"description,omitempty"`
+	Direction   securitygroupruledirection.Enum `json:"direction"`
+	EtherType   ipversion.Enum                  `json:"ether_type"`
+	Protocol    string                          `json:"protocol,omitempty"`  // tcp, udp, icmp or empty for any protocol
+	PortFrom    int                             `json:"port_from,omitempty"` // only for tcp and udp; 0 for any port
+	PortTo      int                             `json:"port_to,omitempty"`   // only for tcp and udp; 0 means PortFrom
+	IPRanges    []string                        `json:"ip_ranges,omitempty"` // CIDRs of the remote side; empty for any address
+	// Stateless tells the replies to the traffic allowed are not automatically allowed; rules are stateful by default
+	Stateless bool `json:"stateless,omitempty"`
+}
+
+// Validate checks the rule is consistent, regardless of the provider it will be applied on
+func (r SecurityGroupRule) Validate() fail.Error {
+	switch r.Direction {
+	case securitygroupruledirection.INGRESS, securitygroupruledirection.EGRESS:
+	default:
+		return fail.InvalidRequestError(fmt.Sprintf("invalid security group rule direction '%d'", r.Direction))
+	}
+	switch r.EtherType {
+	case ipversion.IPv4, ipversion.IPv6:
+	default:
+		return fail.InvalidRequestError(fmt.Sprintf("invalid security group rule ether type '%d'", r.EtherType))
+	}
+
+	switch r.Protocol {
+	case "tcp", "udp":
+		invalid := r.PortFrom < 0 || r.PortFrom > 65535 || r.PortTo < 0 || r.PortTo > 65535
+		invalid = invalid || (r.PortTo != 0 && (r.PortFrom == 0 || r.PortTo < r.PortFrom))
+		if invalid {
+			return fail.InvalidRequestError(
+				fmt.Sprintf("invalid security group rule port range %d-%d", r.PortFrom, r.PortTo),
+			)
+		}
+	case "icmp", "":
+		if r.PortFrom != 0 || r.PortTo != 0 {
+			return fail.InvalidRequestError("security group rule ports can only be set for tcp or udp protocols")
+		}
+	default:
+		return fail.InvalidRequestError(fmt.Sprintf("unsupported security group rule protocol '%s'", r.Protocol))
+	}
+
+	for _, cidr := range r.IPRanges {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fail.InvalidRequestError(fmt.Sprintf("invalid security group rule IP range '%s'", cidr))
+		}
+		if (ip.To4() != nil) != (r.EtherType == ipversion.IPv4) {
+			return fail.InvalidRequestError(
+				fmt.Sprintf("security group rule IP range '%s' does not match ether type IPv%d", cidr, r.EtherType),
+			)
+		}
+	}
+	return nil
+}
+
+// Ports returns the first and last ports of the range allowed by the rule, 0 and 0 meaning any port
+func (r SecurityGroupRule) Ports() (int, int) {
+	if r.PortFrom == 0 {
+		return 0, 0
+	}
+	if r.PortTo == 0 {
+		return r.PortFrom, r.PortFrom
+	}
+	return r.PortFrom, r.PortTo
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abstract
+
+import (
+	"testing"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
+)
+
+func TestSecurityGroupRuleValidate(t *testing.T) {
+	valid := []SecurityGroupRule{
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4},
+		{Direction: securitygroupruledirection.EGRESS, EtherType: ipversion.IPv6, Protocol: "udp", PortFrom: 53},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "tcp", PortFrom: 8000, PortTo: 8080, IPRanges: []string{"10.0.0.0/8"}},
+		{Direction: securitygroupruledirection.EGRESS, EtherType: ipversion.IPv4, Protocol: "icmp", Stateless: true},
+	}
+	for _, rule := range valid {
+		if err := rule.Validate(); err != nil {
+			t.Errorf("rule %+v should be valid: %v", rule, err)
+		}
+	}
+
+	invalid := []SecurityGroupRule{
+		{EtherType: ipversion.IPv4},
+		{Direction: securitygroupruledirection.INGRESS},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "sctp"},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "tcp", PortFrom: 8080, PortTo: 80},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "tcp", PortTo: 80},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "tcp", PortFrom: 70000},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, PortFrom: 22},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv6, IPRanges: []string{"10.0.0.0/8"}},
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, IPRanges: []string{"10.0.0.0"}},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("rule %+v should be invalid", rule)
+		}
+	}
+}
+
+func TestSecurityGroupRulePorts(t *testing.T) {
+	cases := []struct {
+		rule     SecurityGroupRule
+		from, to int
+	}{
+		{SecurityGroupRule{}, 0, 0},
+		{SecurityGroupRule{PortFrom: 22}, 22, 22},
+		{SecurityGroupRule{PortFrom: 8000, PortTo: 8080}, 8000, 8080},
+	}
+	for _, c := range cases {
+		from, to := c.rule.Ports()
+		if from != c.from || to != c.to {
+			t.Errorf("Ports() of %+v = %d-%d, want %d-%d", c.rule, from, to, c.from, c.to)
+		}
+	}
+}
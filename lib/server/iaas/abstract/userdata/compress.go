@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package userdata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// Size limits of the user data of the providers, in bytes of script (before any base64 encoding)
+const (
+	// OpenStackMaxSize is the limit on OpenStack, where the user data cannot exceed 65535 bytes once base64-encoded
+	OpenStackMaxSize = 65535 / 4 * 3
+	// AWSMaxSize is the limit on AWS, where the user data cannot exceed 16KB before base64 encoding
+	AWSMaxSize = 16 * 1024
+	// GCPMaxSize is the limit on GCP, where a metadata value cannot exceed 256KB
+	GCPMaxSize = 256 * 1024
+)
+
+// compressedMarker delimits the compressed script in the decompression shim
+const compressedMarker = "SAFESCALE_COMPRESSED_USERDATA"
+
+// compressedShim is the script decompressing the original script and running it in place of the shim
+const compressedShim = `#!/bin/bash
+# user data compressed by SafeScale to fit in the size limit of the provider
+base64 -d <<'%[1]s' | gunzip -c >/tmp/safescale.userdata.sh && exec /bin/bash /tmp/safescale.userdata.sh
+%[2]s
+%[1]s
+`
+
+// Fit returns the script unchanged if its size doesn't exceed limit; otherwise the script is compressed with gzip and
+// returned base64-encoded behind a shim decompressing and running it.
+// Fails with an overflow error if the compressed script still exceeds the limit.
+func Fit(script []byte, limit int) ([]byte, error) {
+	if limit <= 0 || len(script) <= limit {
+		return script, nil
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = zw.Write(script); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	var lines []string
+	for len(encoded) > 76 {
+		lines = append(lines, encoded[:76])
+		encoded = encoded[76:]
+	}
+	lines = append(lines, encoded)
+
+	result := []byte(fmt.Sprintf(compressedShim, compressedMarker, strings.Join(lines, "\n")))
+	if len(result) > limit {
+		return nil, fail.OverflowError(
+			fmt.Sprintf("user data too large: %d bytes, still %d bytes once compressed", len(script), len(result)),
+			uint(limit), nil,
+		)
+	}
+	logrus.Debugf("user data compressed from %d to %d bytes to fit in the limit of %d bytes", len(script), len(result), limit)
+	return result, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package userdata
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestFitKeepsSmallScript(t *testing.T) {
+	script := []byte("#!/bin/bash\necho phase1\n")
+	result, err := Fit(script, 1024)
+	require.Nil(t, err)
+	assert.Equal(t, script, result)
+}
+
+func TestFitCompressesOversizedScript(t *testing.T) {
+	script := []byte("#!/bin/bash\n" + strings.Repeat("echo 'installing a lot of things'\n", 4096))
+	result, err := Fit(script, OpenStackMaxSize)
+	require.Nil(t, err)
+	assert.True(t, len(result) <= OpenStackMaxSize)
+	assert.True(t, strings.HasPrefix(string(result), "#!/bin/bash\n# user data compressed by SafeScale"))
+
+	// The shim carries the original script
+	lines := strings.Split(strings.TrimSpace(string(result)), "\n")
+	require.True(t, len(lines) > 4)
+	assert.Equal(t, compressedMarker, lines[len(lines)-1])
+	encoded := strings.Join(lines[3:len(lines)-1], "")
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	require.Nil(t, err)
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.Nil(t, err)
+	decompressed, err := ioutil.ReadAll(zr)
+	require.Nil(t, err)
+	assert.Equal(t, script, decompressed)
+}
+
+func TestFitFailsWhenCompressedScriptIsStillTooLarge(t *testing.T) {
+	random := make([]byte, 4*AWSMaxSize)
+	rand.New(rand.NewSource(1)).Read(random)
+	script := []byte("#!/bin/bash\n: " + base64.StdEncoding.EncodeToString(random) + "\n")
+	_, err := Fit(script, AWSMaxSize)
+	require.NotNil(t, err)
+	_, ok := err.(fail.ErrOverflow)
+	assert.True(t, ok)
+}
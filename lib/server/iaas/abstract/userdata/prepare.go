@@ -150,12 +150,16 @@ func (ud *Content) Prepare(
 		operatorUsername    string
 		useNATService       bool
 	)
+	// A password supplied by the request must be strong enough: the console of the host is reachable by anyone
+	// having access to the tenant
 	if request.Password == "" {
 		password, err := utils.GeneratePassword(16)
 		if err != nil {
 			return fmt.Errorf("failed to generate password: %s", err.Error())
 		}
 		request.Password = password
+	} else if err = utils.ValidatePassword(request.Password); err != nil {
+		return err
 	}
 
 	// Determine default route IP
@@ -169,6 +173,10 @@ func (ud *Content) Prepare(
 	useNATService = options.UseNATService
 	operatorUsername = options.OperatorUsername
 	dnsList = options.DNSList
+	// DNS servers defined on the default network of the host take precedence over the ones of the tenant
+	if len(request.Networks) > 0 && request.Networks[0] != nil && len(request.Networks[0].DNSServers) > 0 {
+		dnsList = request.Networks[0].DNSServers
+	}
 
 	bashLibrary, err := system.GetBashLibrary()
 	if err != nil {
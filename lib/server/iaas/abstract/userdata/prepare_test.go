@@ -0,0 +1,61 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package userdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
+	"github.com/CS-SI/SafeScale/lib/utils"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func newTestRequest(password string) abstract.HostRequest {
+	return abstract.HostRequest{ResourceName: "host", KeyPair: &abstract.KeyPair{}, Password: password}
+}
+
+func TestPrepareGeneratesPassword(t *testing.T) {
+	ud := NewContent()
+	err := ud.Prepare(stacks.ConfigurationOptions{}, newTestRequest(""), "192.168.0.0/24", "")
+	require.Nil(t, err)
+	assert.NotEqual(t, "safescale", ud.Password)
+	assert.Nil(t, utils.ValidatePassword(ud.Password))
+
+	other := NewContent()
+	err = other.Prepare(stacks.ConfigurationOptions{}, newTestRequest(""), "192.168.0.0/24", "")
+	require.Nil(t, err)
+	assert.NotEqual(t, ud.Password, other.Password)
+}
+
+func TestPrepareKeepsRequestedPassword(t *testing.T) {
+	ud := NewContent()
+	err := ud.Prepare(stacks.ConfigurationOptions{}, newTestRequest("Sup3r-Secret-1"), "192.168.0.0/24", "")
+	require.Nil(t, err)
+	assert.Equal(t, "Sup3r-Secret-1", ud.Password)
+}
+
+func TestPrepareRefusesWeakPassword(t *testing.T) {
+	ud := NewContent()
+	err := ud.Prepare(stacks.ConfigurationOptions{}, newTestRequest("safescale"), "192.168.0.0/24", "")
+	require.NotNil(t, err)
+	_, ok := err.(fail.ErrInvalidParameter)
+	assert.True(t, ok)
+}
@@ -29,6 +29,16 @@ type VolumeRequest struct {
 	Speed  volumespeed.Enum `json:"speed,omitempty"`
 	InLVM  bool             `json:"lvm,omitempty"`
 	SizeVU int              `json:"sizevu,omitempty"`
+	// Tags contains the tags to set on the volume on provider side; stacks not supporting them ignore them
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// VolumeAttachmentSpec describes a volume to create and attach to a host at its creation
+type VolumeAttachmentSpec struct {
+	Size       int              // Size of the volume in GB
+	Speed      volumespeed.Enum // Speed (type) of the volume
+	MountPoint string           // MountPoint is the absolute path where the volume is mounted
+	Format     string           // Format is the filesystem of the volume
 }
 
 // Volume represents a block volume
@@ -51,6 +61,16 @@ type Volume struct {
 	Properties *serialize.JSONProperties `json:"properties,omitempty"`
 }
 
+// AttachedVolume describes how a volume is attached and mounted on a host
+type AttachedVolume struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Size       int    `json:"size,omitempty"`
+	Device     string `json:"device,omitempty"`      // device of the attachment on the host
+	MountPoint string `json:"mount_point,omitempty"` // empty if the volume is not mounted
+	FileSystem string `json:"file_system,omitempty"` // empty if the volume is not mounted
+}
+
 // NewVolume ...
 func NewVolume() *Volume {
 	return &Volume{
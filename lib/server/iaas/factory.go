@@ -19,6 +19,7 @@ package iaas
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas/providers"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/providers/api"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
+	"github.com/CS-SI/SafeScale/lib/system"
 	"github.com/CS-SI/SafeScale/lib/utils/crypt"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 )
@@ -185,8 +187,10 @@ func UseService(tenantName string) (newService Service, err error) {
 
 		// Initializes Metadata Object Storage (may be different than the Object Storage)
 		var (
-			metadataBucket   objectstorage.Bucket
-			metadataCryptKey *crypt.Key
+			metadataBucket    objectstorage.Bucket
+			metadataCryptKey  *crypt.Key
+			metadataEncrypter crypt.Encrypter
+			metadataPrefix    string
 		)
 		if tenantMetadataFound || tenantObjectStorageFound {
 			// FIXME: This requires tuning too
@@ -226,12 +230,19 @@ func UseService(tenantName string) (newService Service, err error) {
 				}
 			}
 			if metadataConfig, ok := tenant["metadata"].(map[string]interface{}); ok {
+				if prefix, ok := metadataConfig["Prefix"].(string); ok {
+					metadataPrefix = strings.Trim(prefix, "/")
+				}
 				if key, ok := metadataConfig["CryptKey"]; ok {
 					ek, err := crypt.NewEncryptionKey([]byte(key.(string)))
 					if err != nil {
 						return nil, err
 					}
 					metadataCryptKey = ek
+					metadataEncrypter, err = initMetadataEncrypter(metadataConfig, ek)
+					if err != nil {
+						return nil, err
+					}
 				}
 			}
 		} else {
@@ -243,12 +254,37 @@ func UseService(tenantName string) (newService Service, err error) {
 			)
 		}
 
+		sshOptions, err := initSSHConnectionOptions(tenant)
+		if err != nil {
+			return nil, fail.Errorf(fmt.Sprintf("invalid SSH options for tenant '%s': %s", tenantName, err.Error()), err)
+		}
+		hostEventsWebhook, err := initHostEventsWebhook(tenant)
+		if err != nil {
+			return nil, fail.Errorf(
+				fmt.Sprintf("invalid host events webhook for tenant '%s': %s", tenantName, err.Error()), err,
+			)
+		}
+		mandatoryTags, err := initMandatoryTags(tenant)
+		if err != nil {
+			return nil, fail.Errorf(
+				fmt.Sprintf("invalid mandatory tags for tenant '%s': %s", tenantName, err.Error()), err,
+			)
+		}
+
 		// Service is ready
 		newS := &service{
-			Provider:       providerInstance,
-			Location:       objectStorageLocation,
-			metadataBucket: metadataBucket,
-			metadataKey:    metadataCryptKey,
+			Provider:          providerInstance,
+			Location:          objectStorageLocation,
+			metadataBucket:    metadataBucket,
+			metadataKey:       metadataCryptKey,
+			metadataEncrypter: metadataEncrypter,
+			metadataPrefix:    metadataPrefix,
+			sshOptions:        sshOptions,
+			hostEventsWebhook: hostEventsWebhook,
+			mandatoryTags:     mandatoryTags,
+			tenant:            tenant,
+			builder:           svc,
+			regions:           newRegionCache(),
 		}
 		return newS, validateRegexps(newS /*tenantClient*/, tenant)
 	}
@@ -317,6 +353,100 @@ func validateRegexps(svc *service, tenant map[string]interface{}) error {
 	return nil
 }
 
+// initSSHConnectionOptions reads the options 'SSHServerAliveInterval', 'SSHServerAliveCountMax' and
+// 'SSHConnectTimeout' (in seconds) of the section 'compute' of the tenant
+func initSSHConnectionOptions(tenant map[string]interface{}) (system.SSHConnectionOptions, error) {
+	var opts system.SSHConnectionOptions
+	compute, ok := tenant["compute"].(map[string]interface{})
+	if !ok {
+		return opts, nil
+	}
+	for key, field := range map[string]*int{
+		"SSHServerAliveInterval": &opts.ServerAliveInterval,
+		"SSHServerAliveCountMax": &opts.ServerAliveCountMax,
+		"SSHConnectTimeout":      &opts.ConnectTimeout,
+	} {
+		value, ok := compute[key]
+		if !ok {
+			continue
+		}
+		var err error
+		switch v := value.(type) {
+		case int:
+			*field = v
+		case int64:
+			*field = int(v)
+		case float64:
+			*field = int(v)
+			if float64(*field) != v {
+				err = fmt.Errorf("not an integer")
+			}
+		case string:
+			*field, err = strconv.Atoi(v)
+		default:
+			err = fmt.Errorf("not a number")
+		}
+		if err != nil {
+			return opts, fail.InvalidParameterError(key, fmt.Sprintf("invalid value '%v': %s", value, err.Error()))
+		}
+	}
+	return opts, opts.Validate()
+}
+
+// initHostEventsWebhook reads the option 'HostEventsWebhook' of the section 'compute' of the tenant, the http(s) URL
+// receiving the changes of state of the hosts
+func initHostEventsWebhook(tenant map[string]interface{}) (string, error) {
+	compute, ok := tenant["compute"].(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+	value, ok := compute["HostEventsWebhook"]
+	if !ok {
+		return "", nil
+	}
+	webhook, ok := value.(string)
+	if !ok {
+		return "", fail.InvalidParameterError("HostEventsWebhook", "must be a string")
+	}
+	if webhook == "" {
+		return "", nil
+	}
+	u, err := url.Parse(webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fail.InvalidParameterError("HostEventsWebhook", fmt.Sprintf("'%s' is not a http(s) URL", webhook))
+	}
+	return webhook, nil
+}
+
+// initMandatoryTags reads the option 'MandatoryTags' of the section 'compute' of the tenant, the tags every host, volume
+// and network created must carry; an empty value means the value has to be given by each creation request
+func initMandatoryTags(tenant map[string]interface{}) (map[string]string, error) {
+	compute, ok := tenant["compute"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	value, ok := compute["MandatoryTags"]
+	if !ok {
+		return nil, nil
+	}
+	anon, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fail.InvalidParameterError("MandatoryTags", "must be a table of tag names and values")
+	}
+	tags := make(map[string]string, len(anon))
+	for k, v := range anon {
+		if k == "" {
+			return nil, fail.InvalidParameterError("MandatoryTags", "tag names cannot be empty")
+		}
+		str, ok := v.(string)
+		if !ok {
+			return nil, fail.InvalidParameterError("MandatoryTags", fmt.Sprintf("value of tag '%s' must be a string", k))
+		}
+		tags[k] = str
+	}
+	return tags, nil
+}
+
 // initObjectStorageLocationConfig initializes objectstorage.Config struct with map
 func initObjectStorageLocationConfig(authOpts providers.Config, tenant map[string]interface{}) (objectstorage.Config, error) {
 	var (
@@ -685,3 +815,41 @@ func getTenantsFromCfg() ([]interface{}, error) {
 
 	return tenantsCfg, nil
 }
+
+// initMetadataEncrypter builds the Encrypter of metadata from the 'metadata' section of tenant configuration
+// 'CryptKeyID' identifies the current key (default: empty); 'PreviousCryptKeys' may contain the keys used before
+// a rotation, indexed by their id, to still be able to read metadata written with them
+func initMetadataEncrypter(metadataConfig map[string]interface{}, key *crypt.Key) (crypt.Encrypter, error) {
+	keyID := ""
+	if anon, ok := metadataConfig["CryptKeyID"]; ok {
+		if keyID, ok = anon.(string); !ok {
+			return nil, fail.InvalidParameterError("metadata.CryptKeyID", "must be a string")
+		}
+	}
+	encrypter, err := crypt.NewAESEncrypter(keyID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if anon, ok := metadataConfig["PreviousCryptKeys"]; ok {
+		previous, ok := anon.(map[string]interface{})
+		if !ok {
+			return nil, fail.InvalidParameterError("metadata.PreviousCryptKeys", "must be a map of key ids to keys")
+		}
+		for id, v := range previous {
+			text, ok := v.(string)
+			if !ok {
+				return nil, fail.InvalidParameterError("metadata.PreviousCryptKeys", fmt.Sprintf("key '%s' must be a string", id))
+			}
+			ek, err := crypt.NewEncryptionKey([]byte(text))
+			if err != nil {
+				return nil, err
+			}
+			err = encrypter.AddDecryptionKey(id, ek)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return encrypter, nil
+}
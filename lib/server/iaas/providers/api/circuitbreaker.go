@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/CS-SI/SafeScale/lib/utils"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets the requests go to the provider
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects the requests without reaching the provider
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request go to the provider to check if it is back
+	CircuitHalfOpen
+)
+
+// String returns a printable representation of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// CircuitBreakerOptions contains the thresholds of a CircuitBreaker
+type CircuitBreakerOptions struct {
+	// Threshold is the number of consecutive outage errors opening the circuit
+	Threshold uint
+	// Window is the duration in which the consecutive outage errors must occur to open the circuit
+	Window time.Duration
+	// Cooldown is the duration during which the requests are rejected before a probe request is allowed
+	Cooldown time.Duration
+}
+
+// DefaultCircuitBreakerOptions returns the thresholds used by NewRetryProvider
+func DefaultCircuitBreakerOptions() CircuitBreakerOptions {
+	return CircuitBreakerOptions{
+		Threshold: 5,
+		Window:    time.Minute,
+		Cooldown:  30 * time.Second,
+	}
+}
+
+// CircuitBreaker stops sending requests to a provider after consecutive network errors or timeouts, to fail fast
+// during an outage instead of retrying each request until its timeout
+type CircuitBreaker struct {
+	lock         sync.Mutex
+	options      CircuitBreakerOptions
+	state        CircuitState
+	failures     uint
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+	now          func() time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker; zero values in options are replaced by the default ones
+func NewCircuitBreaker(options CircuitBreakerOptions) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerOptions()
+	if options.Threshold == 0 {
+		options.Threshold = defaults.Threshold
+	}
+	if options.Window == 0 {
+		options.Window = defaults.Window
+	}
+	if options.Cooldown == 0 {
+		options.Cooldown = defaults.Cooldown
+	}
+	return &CircuitBreaker{options: options, now: time.Now}
+}
+
+// State returns the current state of the circuit
+func (cb *CircuitBreaker) State() CircuitState {
+	if cb == nil {
+		return CircuitClosed
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.options.Cooldown {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+// Allow tells if a request can be sent to the provider
+// Returns fail.ErrNotAvailable if the circuit is open, or half-open with a probe request already in progress
+func (cb *CircuitBreaker) Allow() fail.Error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		remaining := cb.options.Cooldown - cb.now().Sub(cb.openedAt)
+		if remaining > 0 {
+			return fail.NotAvailableError(
+				fmt.Sprintf("provider unavailable, requests suspended for %s", remaining.Round(time.Second)),
+			)
+		}
+		cb.state = CircuitHalfOpen
+		cb.probing = true
+		return nil
+	case CircuitHalfOpen:
+		if cb.probing {
+			return fail.NotAvailableError("provider unavailable, waiting for the result of a probe request")
+		}
+		cb.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record updates the circuit with the result of a request allowed by Allow
+func (cb *CircuitBreaker) Record(err error) {
+	if cb == nil {
+		return
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	now := cb.now()
+	if !isOutageError(err) {
+		if cb.state != CircuitClosed {
+			logrus.Infof("provider is reachable again, closing circuit")
+		}
+		cb.state = CircuitClosed
+		cb.failures = 0
+		cb.probing = false
+		return
+	}
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		logrus.Warnf("provider still unavailable, suspending requests for %s", cb.options.Cooldown)
+		cb.open(now)
+	case CircuitClosed:
+		if cb.failures == 0 || now.Sub(cb.firstFailure) > cb.options.Window {
+			cb.failures = 0
+			cb.firstFailure = now
+		}
+		cb.failures++
+		if cb.failures >= cb.options.Threshold {
+			logrus.Warnf(
+				"provider seems unavailable after %d consecutive error%s, suspending requests for %s", cb.failures,
+				utils.Plural(int(cb.failures)), cb.options.Cooldown,
+			)
+			cb.open(now)
+		}
+	}
+}
+
+// open opens the circuit; cb.lock must be held by caller
+func (cb *CircuitBreaker) open(now time.Time) {
+	cb.state = CircuitOpen
+	cb.openedAt = now
+	cb.probing = false
+}
+
+// isOutageError tells if err denotes a provider unreachable or not responding
+func isOutageError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, e := range []error{err, fail.Cause(err)} {
+		switch e.(type) {
+		case fail.ErrTimeout:
+			return true
+		case net.Error:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	cb := NewCircuitBreaker(CircuitBreakerOptions{Threshold: 3, Window: time.Minute, Cooldown: 30 * time.Second})
+	cb.now = func() time.Time { return now }
+
+	outage := fail.TimeoutError("no answer", time.Second, nil)
+
+	// Errors not denoting an outage and successes keep the circuit closed
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, cb.Allow())
+		cb.Record(fail.NotFoundError("not found"))
+	}
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	// Consecutive outage errors outside the window do not open the circuit
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, cb.Allow())
+		cb.Record(outage)
+	}
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, cb.Allow())
+	cb.Record(&net.DNSError{Err: "no such host", Name: "provider"})
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	// Threshold reached inside the window opens the circuit
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, cb.Allow())
+		cb.Record(fail.Errorf("failed", outage))
+	}
+	assert.Equal(t, CircuitOpen, cb.State())
+	xerr := cb.Allow()
+	assert.NotNil(t, xerr)
+	_, ok := xerr.(fail.ErrNotAvailable)
+	assert.True(t, ok, fmt.Sprintf("unexpected error type %T", xerr))
+
+	// After cooldown, a single probe is allowed; its failure opens the circuit again
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.Nil(t, cb.Allow())
+	assert.NotNil(t, cb.Allow())
+	cb.Record(outage)
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.NotNil(t, cb.Allow())
+
+	// A successful probe closes the circuit
+	now = now.Add(30 * time.Second)
+	assert.Nil(t, cb.Allow())
+	cb.Record(nil)
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.Nil(t, cb.Allow())
+}
+
+func TestNilCircuitBreaker(t *testing.T) {
+	var cb *CircuitBreaker
+	assert.Nil(t, cb.Allow())
+	cb.Record(fail.TimeoutError("no answer", time.Second, nil))
+	assert.Equal(t, CircuitClosed, cb.State())
+}
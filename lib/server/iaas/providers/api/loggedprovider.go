@@ -36,6 +36,12 @@ func (w LoggedProvider) ListTemplates(all bool) ([]abstract.HostTemplate, fail.E
 	return w.InnerProvider.ListTemplates(all)
 }
 
+// RefreshCatalog ...
+func (w LoggedProvider) RefreshCatalog() {
+	defer w.prepare(w.trace("RefreshCatalog"))
+	w.InnerProvider.RefreshCatalog()
+}
+
 // GetAuthenticationOptions ...
 func (w LoggedProvider) GetAuthenticationOptions() (providers.Config, fail.Error) {
 	defer w.prepare(w.trace("GetAuthenticationOptions"))
@@ -90,6 +96,12 @@ func (w LoggedProvider) ListRegions() ([]string, fail.Error) {
 	return w.InnerProvider.ListRegions()
 }
 
+// GetQuotas ...
+func (w LoggedProvider) GetQuotas() (*abstract.Quotas, fail.Error) {
+	defer w.prepare(w.trace("GetQuotas"))
+	return w.InnerProvider.GetQuotas()
+}
+
 // GetImage ...
 func (w LoggedProvider) GetImage(id string) (*abstract.Image, fail.Error) {
 	defer w.prepare(w.trace("GetImage"))
@@ -156,6 +168,18 @@ func (w LoggedProvider) DeleteNetwork(id string) error {
 	return w.InnerProvider.DeleteNetwork(id)
 }
 
+// UpdateNetworkDNSServers ...
+func (w LoggedProvider) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	defer w.prepare(w.trace("UpdateNetworkDNSServers"))
+	return w.InnerProvider.UpdateNetworkDNSServers(id, dnsServers)
+}
+
+// SetNetworkRoutes ...
+func (w LoggedProvider) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	defer w.prepare(w.trace("SetNetworkRoutes"))
+	return w.InnerProvider.SetNetworkRoutes(id, routes)
+}
+
 // CreateGateway ...
 func (w LoggedProvider) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (*abstract.Host, *userdata.Content, fail.Error) {
 	defer w.prepare(w.trace("CreateGateway"))
@@ -234,6 +258,60 @@ func (w LoggedProvider) DeleteHost(id string) error {
 	return w.InnerProvider.DeleteHost(id)
 }
 
+// SetHostIPForwarding ...
+func (w LoggedProvider) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	defer w.prepare(w.trace("SetHostIPForwarding"))
+	return w.InnerProvider.SetHostIPForwarding(host, enabled)
+}
+
+// BindSecurityGroupToHost ...
+func (w LoggedProvider) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	defer w.prepare(w.trace("BindSecurityGroupToHost"))
+	return w.InnerProvider.BindSecurityGroupToHost(id, sgName)
+}
+
+// UnbindSecurityGroupFromHost ...
+func (w LoggedProvider) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	defer w.prepare(w.trace("UnbindSecurityGroupFromHost"))
+	return w.InnerProvider.UnbindSecurityGroupFromHost(id, sgName)
+}
+
+// AddRulesToSecurityGroup ...
+func (w LoggedProvider) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	defer w.prepare(w.trace("AddRulesToSecurityGroup"))
+	return w.InnerProvider.AddRulesToSecurityGroup(sgName, rules)
+}
+
+// GetHostConsoleOutput ...
+func (w LoggedProvider) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	defer w.prepare(w.trace("GetHostConsoleOutput"))
+	return w.InnerProvider.GetHostConsoleOutput(id, lines)
+}
+
+// ListHostNetworkInterfaces ...
+func (w LoggedProvider) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	defer w.prepare(w.trace("ListHostNetworkInterfaces"))
+	return w.InnerProvider.ListHostNetworkInterfaces(id)
+}
+
+// CreateHostSnapshot ...
+func (w LoggedProvider) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	defer w.prepare(w.trace("CreateHostSnapshot"))
+	return w.InnerProvider.CreateHostSnapshot(id, name)
+}
+
+// WaitHostSnapshotReady ...
+func (w LoggedProvider) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	defer w.prepare(w.trace("WaitHostSnapshotReady"))
+	return w.InnerProvider.WaitHostSnapshotReady(snapshotID)
+}
+
+// RenameHost ...
+func (w LoggedProvider) RenameHost(id string, newName string) fail.Error {
+	defer w.prepare(w.trace("RenameHost"))
+	return w.InnerProvider.RenameHost(id, newName)
+}
+
 // StopHost ...
 func (w LoggedProvider) StopHost(id string) error {
 	defer w.prepare(w.trace("StopHost"))
@@ -311,3 +389,12 @@ func (w LoggedProvider) GetCapabilities() providers.Capabilities {
 	defer w.prepare(w.trace("Getcapabilities"))
 	return w.InnerProvider.GetCapabilities()
 }
+
+// Reauthenticate renews the authentication token of the provider, if it knows how to do it
+func (w LoggedProvider) Reauthenticate() fail.Error {
+	defer w.prepare(w.trace("Reauthenticate"))
+	if ra, ok := w.InnerProvider.(Reauthenticator); ok {
+		return ra.Reauthenticate()
+	}
+	return fail.NotImplementedError("Reauthenticate() not implemented by provider")
+}
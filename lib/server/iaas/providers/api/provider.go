@@ -39,6 +39,9 @@ type Provider interface {
 	// Host templates are sorted using Dominant Resource Fairness Algorithm
 	ListTemplates(all bool) ([]abstract.HostTemplate, fail.Error)
 
+	// RefreshCatalog forgets the images and templates kept in cache, if any; the next listings query the provider
+	RefreshCatalog()
+
 	// GetAuthenticationOptions returns authentication options as a Config
 	GetAuthenticationOptions() (providers.Config, fail.Error)
 
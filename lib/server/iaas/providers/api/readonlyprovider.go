@@ -0,0 +1,354 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/providers"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// ReadOnlyProvider is a view of a provider forbidding the calls creating, modifying or deleting resources
+// Those calls fail with fail.ErrForbidden without reaching the inner provider.
+type ReadOnlyProvider WrappedProvider
+
+// forbidden returns the error of a mutating call
+func (w ReadOnlyProvider) forbidden(call string) fail.Error {
+	return fail.ForbiddenError(fmt.Sprintf("%s() is forbidden on a read-only view of provider '%s'", call, w.Name))
+}
+
+// Provider specific functions
+
+// Build ...
+func (w ReadOnlyProvider) Build(something map[string]interface{}) (Provider, fail.Error) {
+	p, err := w.InnerProvider.Build(something)
+	if err != nil {
+		return nil, err
+	}
+	return NewReadOnlyProvider(p, w.Name), nil
+}
+
+// ListImages ...
+func (w ReadOnlyProvider) ListImages(all bool) ([]abstract.Image, fail.Error) {
+	return w.InnerProvider.ListImages(all)
+}
+
+// ListTemplates ...
+func (w ReadOnlyProvider) ListTemplates(all bool) ([]abstract.HostTemplate, fail.Error) {
+	return w.InnerProvider.ListTemplates(all)
+}
+
+// RefreshCatalog ...
+func (w ReadOnlyProvider) RefreshCatalog() {
+	w.InnerProvider.RefreshCatalog()
+}
+
+// GetAuthenticationOptions ...
+func (w ReadOnlyProvider) GetAuthenticationOptions() (providers.Config, fail.Error) {
+	return w.InnerProvider.GetAuthenticationOptions()
+}
+
+// GetConfigurationOptions ...
+func (w ReadOnlyProvider) GetConfigurationOptions() (providers.Config, fail.Error) {
+	return w.InnerProvider.GetConfigurationOptions()
+}
+
+// GetName ...
+func (w ReadOnlyProvider) GetName() string {
+	return w.InnerProvider.GetName()
+}
+
+// GetCapabilities ...
+func (w ReadOnlyProvider) GetCapabilities() providers.Capabilities {
+	return w.InnerProvider.GetCapabilities()
+}
+
+// GetTenantParameters ...
+func (w ReadOnlyProvider) GetTenantParameters() map[string]interface{} {
+	return w.InnerProvider.GetTenantParameters()
+}
+
+// Reauthenticate renews the authentication of the inner provider, which does not modify any resource
+func (w ReadOnlyProvider) Reauthenticate() fail.Error {
+	if ra, ok := w.InnerProvider.(Reauthenticator); ok {
+		return ra.Reauthenticate()
+	}
+	return fail.NotImplementedError("Reauthenticate() not implemented by provider")
+}
+
+// Stack specific functions, allowed
+
+// ListAvailabilityZones ...
+func (w ReadOnlyProvider) ListAvailabilityZones() (map[string]bool, fail.Error) {
+	return w.InnerProvider.ListAvailabilityZones()
+}
+
+// ListRegions ...
+func (w ReadOnlyProvider) ListRegions() ([]string, fail.Error) {
+	return w.InnerProvider.ListRegions()
+}
+
+// GetQuotas ...
+func (w ReadOnlyProvider) GetQuotas() (*abstract.Quotas, fail.Error) {
+	return w.InnerProvider.GetQuotas()
+}
+
+// GetImage ...
+func (w ReadOnlyProvider) GetImage(id string) (*abstract.Image, fail.Error) {
+	return w.InnerProvider.GetImage(id)
+}
+
+// GetTemplate ...
+func (w ReadOnlyProvider) GetTemplate(id string) (*abstract.HostTemplate, fail.Error) {
+	return w.InnerProvider.GetTemplate(id)
+}
+
+// GetKeyPair ...
+func (w ReadOnlyProvider) GetKeyPair(id string) (*abstract.KeyPair, fail.Error) {
+	return w.InnerProvider.GetKeyPair(id)
+}
+
+// ListKeyPairs ...
+func (w ReadOnlyProvider) ListKeyPairs() ([]abstract.KeyPair, fail.Error) {
+	return w.InnerProvider.ListKeyPairs()
+}
+
+// GetNetwork ...
+func (w ReadOnlyProvider) GetNetwork(id string) (*abstract.Network, fail.Error) {
+	return w.InnerProvider.GetNetwork(id)
+}
+
+// GetNetworkByName ...
+func (w ReadOnlyProvider) GetNetworkByName(name string) (*abstract.Network, fail.Error) {
+	return w.InnerProvider.GetNetworkByName(name)
+}
+
+// ListNetworks ...
+func (w ReadOnlyProvider) ListNetworks() ([]*abstract.Network, fail.Error) {
+	return w.InnerProvider.ListNetworks()
+}
+
+// InspectHost ...
+func (w ReadOnlyProvider) InspectHost(something interface{}) (*abstract.Host, fail.Error) {
+	return w.InnerProvider.InspectHost(something)
+}
+
+// GetHostByName ...
+func (w ReadOnlyProvider) GetHostByName(name string) (*abstract.Host, fail.Error) {
+	return w.InnerProvider.GetHostByName(name)
+}
+
+// GetHostState ...
+func (w ReadOnlyProvider) GetHostState(something interface{}) (hoststate.Enum, fail.Error) {
+	return w.InnerProvider.GetHostState(something)
+}
+
+// SetHostIPForwarding ...
+func (w ReadOnlyProvider) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	return w.forbidden("SetHostIPForwarding")
+}
+
+// BindSecurityGroupToHost is forbidden
+func (w ReadOnlyProvider) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	return w.forbidden("BindSecurityGroupToHost")
+}
+
+// UnbindSecurityGroupFromHost is forbidden
+func (w ReadOnlyProvider) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	return w.forbidden("UnbindSecurityGroupFromHost")
+}
+
+// AddRulesToSecurityGroup is forbidden
+func (w ReadOnlyProvider) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	return w.forbidden("AddRulesToSecurityGroup")
+}
+
+// GetHostConsoleOutput ...
+func (w ReadOnlyProvider) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	return w.InnerProvider.GetHostConsoleOutput(id, lines)
+}
+
+// ListHostNetworkInterfaces ...
+func (w ReadOnlyProvider) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	return w.InnerProvider.ListHostNetworkInterfaces(id)
+}
+
+// ListHosts ...
+func (w ReadOnlyProvider) ListHosts() ([]*abstract.Host, fail.Error) {
+	return w.InnerProvider.ListHosts()
+}
+
+// GetVolume ...
+func (w ReadOnlyProvider) GetVolume(id string) (*abstract.Volume, fail.Error) {
+	return w.InnerProvider.GetVolume(id)
+}
+
+// ListVolumes ...
+func (w ReadOnlyProvider) ListVolumes() ([]abstract.Volume, fail.Error) {
+	return w.InnerProvider.ListVolumes()
+}
+
+// GetVolumeAttachment ...
+func (w ReadOnlyProvider) GetVolumeAttachment(serverID, id string) (*abstract.VolumeAttachment, fail.Error) {
+	return w.InnerProvider.GetVolumeAttachment(serverID, id)
+}
+
+// ListVolumeAttachments ...
+func (w ReadOnlyProvider) ListVolumeAttachments(serverID string) ([]abstract.VolumeAttachment, fail.Error) {
+	return w.InnerProvider.ListVolumeAttachments(serverID)
+}
+
+// Stack specific functions, forbidden
+
+// CreateKeyPair is forbidden
+func (w ReadOnlyProvider) CreateKeyPair(name string) (*abstract.KeyPair, fail.Error) {
+	return nil, w.forbidden("CreateKeyPair")
+}
+
+// DeleteKeyPair is forbidden
+func (w ReadOnlyProvider) DeleteKeyPair(id string) fail.Error {
+	return w.forbidden("DeleteKeyPair")
+}
+
+// CreateNetwork is forbidden
+func (w ReadOnlyProvider) CreateNetwork(req abstract.NetworkRequest) (*abstract.Network, fail.Error) {
+	return nil, w.forbidden("CreateNetwork")
+}
+
+// DeleteNetwork is forbidden
+func (w ReadOnlyProvider) DeleteNetwork(id string) fail.Error {
+	return w.forbidden("DeleteNetwork")
+}
+
+// UpdateNetworkDNSServers ...
+func (w ReadOnlyProvider) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	return w.forbidden("UpdateNetworkDNSServers")
+}
+
+// SetNetworkRoutes is forbidden
+func (w ReadOnlyProvider) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	return w.forbidden("SetNetworkRoutes")
+}
+
+// CreateGateway is forbidden
+func (w ReadOnlyProvider) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (*abstract.Host, *userdata.Content, fail.Error) {
+	return nil, nil, w.forbidden("CreateGateway")
+}
+
+// DeleteGateway is forbidden
+func (w ReadOnlyProvider) DeleteGateway(networkID string) fail.Error {
+	return w.forbidden("DeleteGateway")
+}
+
+// CreateVIP is forbidden
+func (w ReadOnlyProvider) CreateVIP(first string, second string) (*abstract.VirtualIP, fail.Error) {
+	return nil, w.forbidden("CreateVIP")
+}
+
+// AddPublicIPToVIP is forbidden
+func (w ReadOnlyProvider) AddPublicIPToVIP(vip *abstract.VirtualIP) fail.Error {
+	return w.forbidden("AddPublicIPToVIP")
+}
+
+// BindHostToVIP is forbidden
+func (w ReadOnlyProvider) BindHostToVIP(vip *abstract.VirtualIP, hostID string) fail.Error {
+	return w.forbidden("BindHostToVIP")
+}
+
+// UnbindHostFromVIP is forbidden
+func (w ReadOnlyProvider) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) fail.Error {
+	return w.forbidden("UnbindHostFromVIP")
+}
+
+// DeleteVIP is forbidden
+func (w ReadOnlyProvider) DeleteVIP(vip *abstract.VirtualIP) fail.Error {
+	return w.forbidden("DeleteVIP")
+}
+
+// CreateHost is forbidden
+func (w ReadOnlyProvider) CreateHost(request abstract.HostRequest) (*abstract.Host, *userdata.Content, fail.Error) {
+	return nil, nil, w.forbidden("CreateHost")
+}
+
+// DeleteHost is forbidden
+func (w ReadOnlyProvider) DeleteHost(id string) fail.Error {
+	return w.forbidden("DeleteHost")
+}
+
+// StopHost is forbidden
+func (w ReadOnlyProvider) StopHost(id string) fail.Error {
+	return w.forbidden("StopHost")
+}
+
+// StartHost is forbidden
+func (w ReadOnlyProvider) StartHost(id string) fail.Error {
+	return w.forbidden("StartHost")
+}
+
+// RebootHost is forbidden
+func (w ReadOnlyProvider) RebootHost(id string) fail.Error {
+	return w.forbidden("RebootHost")
+}
+
+// CreateHostSnapshot is forbidden
+func (w ReadOnlyProvider) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	return "", w.forbidden("CreateHostSnapshot")
+}
+
+// WaitHostSnapshotReady ...
+func (w ReadOnlyProvider) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	return w.InnerProvider.WaitHostSnapshotReady(snapshotID)
+}
+
+// RenameHost ...
+func (w ReadOnlyProvider) RenameHost(id string, newName string) fail.Error {
+	return w.forbidden("RenameHost")
+}
+
+// ResizeHost is forbidden
+func (w ReadOnlyProvider) ResizeHost(id string, request abstract.SizingRequirements) (*abstract.Host, fail.Error) {
+	return nil, w.forbidden("ResizeHost")
+}
+
+// CreateVolume is forbidden
+func (w ReadOnlyProvider) CreateVolume(request abstract.VolumeRequest) (*abstract.Volume, fail.Error) {
+	return nil, w.forbidden("CreateVolume")
+}
+
+// DeleteVolume is forbidden
+func (w ReadOnlyProvider) DeleteVolume(id string) fail.Error {
+	return w.forbidden("DeleteVolume")
+}
+
+// CreateVolumeAttachment is forbidden
+func (w ReadOnlyProvider) CreateVolumeAttachment(request abstract.VolumeAttachmentRequest) (string, fail.Error) {
+	return "", w.forbidden("CreateVolumeAttachment")
+}
+
+// DeleteVolumeAttachment is forbidden
+func (w ReadOnlyProvider) DeleteVolumeAttachment(serverID, id string) fail.Error {
+	return w.forbidden("DeleteVolumeAttachment")
+}
+
+// NewReadOnlyProvider ...
+func NewReadOnlyProvider(innerProvider Provider, name string) *ReadOnlyProvider {
+	return &ReadOnlyProvider{InnerProvider: innerProvider, Name: name}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// inspectOnlyProvider implements only the calls used by the tests; others panic
+type inspectOnlyProvider struct {
+	Provider
+	inspected int
+}
+
+func (p *inspectOnlyProvider) InspectHost(interface{}) (*abstract.Host, fail.Error) {
+	p.inspected++
+	return abstract.NewHost(), nil
+}
+
+func TestReadOnlyProviderAllowsInspection(t *testing.T) {
+	inner := &inspectOnlyProvider{}
+	ro := NewReadOnlyProvider(inner, "test")
+
+	host, err := ro.InspectHost("id")
+	assert.Nil(t, err)
+	assert.NotNil(t, host)
+	assert.Equal(t, 1, inner.inspected)
+}
+
+func TestReadOnlyProviderForbidsMutations(t *testing.T) {
+	ro := NewReadOnlyProvider(&inspectOnlyProvider{}, "test")
+
+	calls := map[string]func() fail.Error{
+		"CreateHost": func() fail.Error {
+			_, _, err := ro.CreateHost(abstract.HostRequest{})
+			return err
+		},
+		"DeleteHost":    func() fail.Error { return ro.DeleteHost("id") },
+		"StopHost":      func() fail.Error { return ro.StopHost("id") },
+		"DeleteNetwork": func() fail.Error { return ro.DeleteNetwork("id") },
+		"CreateVolume": func() fail.Error {
+			_, err := ro.CreateVolume(abstract.VolumeRequest{})
+			return err
+		},
+		"DeleteVolumeAttachment": func() fail.Error { return ro.DeleteVolumeAttachment("server", "id") },
+	}
+	for name, call := range calls {
+		err := call()
+		_, ok := err.(fail.ErrForbidden)
+		assert.True(t, ok, "%s() should be forbidden", name)
+	}
+}
@@ -12,29 +12,67 @@ import (
 	"github.com/CS-SI/SafeScale/lib/utils/temporal"
 )
 
+// maxAttempts is the maximum number of calls to the inner provider for one call to a RetryProvider, so a provider
+// failing quickly is not called again and again until the timeout
+const maxAttempts = 10
+
 // RetryProvider ...
-type RetryProvider WrappedProvider
+type RetryProvider struct {
+	WrappedProvider
+	breaker *CircuitBreaker
+}
 
-func (w RetryProvider) CreateVIP(first string, second string) (res *abstract.VirtualIP, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
-		func() error {
-			res, xerr = w.InnerProvider.CreateVIP(first, second)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
+// Reauthenticator is implemented by providers able to renew their authentication token
+type Reauthenticator interface {
+	Reauthenticate() fail.Error
+}
+
+// classify records the result of a call to the inner provider in the circuit breaker, and returns the error to use
+// to continue the retries, or nil to stop them
+// On authentication failure, the inner provider gets one chance to renew its token before giving up
+func (w RetryProvider) classify(xerr error, reauthenticated *bool) error {
+	w.breaker.Record(xerr)
+	if xerr == nil {
+		return nil
+	}
+
+	if fail.IsAuthenticationError(xerr) {
+		if _, ok := fail.Cause(xerr).(fail.ErrUnauthorized); ok && !*reauthenticated {
+			if ra, ok := w.InnerProvider.(Reauthenticator); ok {
+				*reauthenticated = true
+				if rerr := ra.Reauthenticate(); rerr == nil {
 					return xerr
-				default:
-					return nil
 				}
 			}
-			return nil
+		}
+		return nil
+	}
+
+	switch xerr.(type) {
+	case fail.ErrTimeout:
+		return xerr
+	case *net.DNSError:
+		return xerr
+	case fail.ErrInvalidRequest:
+		return xerr
+	default:
+		return nil
+	}
+}
+
+func (w RetryProvider) CreateVIP(first string, second string) (res *abstract.VirtualIP, xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			res, xerr = w.InnerProvider.CreateVIP(first, second)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -44,25 +82,18 @@ func (w RetryProvider) CreateVIP(first string, second string) (res *abstract.Vir
 }
 
 func (w RetryProvider) AddPublicIPToVIP(res *abstract.VirtualIP) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.AddPublicIPToVIP(res)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.AddPublicIPToVIP(res)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -72,25 +103,18 @@ func (w RetryProvider) AddPublicIPToVIP(res *abstract.VirtualIP) (xerr fail.Erro
 }
 
 func (w RetryProvider) BindHostToVIP(vip *abstract.VirtualIP, hostID string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.BindHostToVIP(vip, hostID)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.BindHostToVIP(vip, hostID)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -100,25 +124,18 @@ func (w RetryProvider) BindHostToVIP(vip *abstract.VirtualIP, hostID string) (xe
 }
 
 func (w RetryProvider) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.UnbindHostFromVIP(vip, hostID)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.UnbindHostFromVIP(vip, hostID)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -128,25 +145,18 @@ func (w RetryProvider) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string)
 }
 
 func (w RetryProvider) DeleteVIP(vip *abstract.VirtualIP) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.DeleteVIP(vip)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.DeleteVIP(vip)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -166,25 +176,18 @@ func (w RetryProvider) GetTenantParameters() map[string]interface{} {
 // Provider specific functions
 
 func (w RetryProvider) Build(something map[string]interface{}) (p Provider, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			p, xerr = w.InnerProvider.Build(something)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			p, xerr = w.InnerProvider.Build(something)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return p, retryErr
@@ -194,25 +197,18 @@ func (w RetryProvider) Build(something map[string]interface{}) (p Provider, xerr
 }
 
 func (w RetryProvider) ListImages(all bool) (res []abstract.Image, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListImages(all)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListImages(all)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -222,25 +218,18 @@ func (w RetryProvider) ListImages(all bool) (res []abstract.Image, xerr fail.Err
 }
 
 func (w RetryProvider) ListTemplates(all bool) (res []abstract.HostTemplate, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListTemplates(all)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListTemplates(all)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -249,6 +238,11 @@ func (w RetryProvider) ListTemplates(all bool) (res []abstract.HostTemplate, xer
 	return res, xerr
 }
 
+// RefreshCatalog ...
+func (w RetryProvider) RefreshCatalog() {
+	w.InnerProvider.RefreshCatalog()
+}
+
 func (w RetryProvider) GetAuthenticationOptions() (providers.Config, fail.Error) {
 	return w.InnerProvider.GetAuthenticationOptions()
 }
@@ -265,30 +259,37 @@ func (w RetryProvider) GetName() string {
 
 // NewRetryProvider ...
 func NewRetryProvider(InnerProvider Provider, name string) *RetryProvider {
-	return &RetryProvider{InnerProvider: InnerProvider, Name: name}
+	return NewRetryProviderWithCircuitBreaker(InnerProvider, name, DefaultCircuitBreakerOptions())
+}
+
+// NewRetryProviderWithCircuitBreaker creates a RetryProvider whose calls are suspended according to 'options' when
+// the provider seems unavailable
+func NewRetryProviderWithCircuitBreaker(InnerProvider Provider, name string, options CircuitBreakerOptions) *RetryProvider {
+	return &RetryProvider{
+		WrappedProvider: WrappedProvider{InnerProvider: InnerProvider, Name: name},
+		breaker:         NewCircuitBreaker(options),
+	}
+}
+
+// CircuitState returns the state of the circuit breaker shared by the calls to the provider
+func (w RetryProvider) CircuitState() CircuitState {
+	return w.breaker.State()
 }
 
 // ListAvailabilityZones ...
 func (w RetryProvider) ListAvailabilityZones() (res map[string]bool, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListAvailabilityZones()
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListAvailabilityZones()
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -299,25 +300,40 @@ func (w RetryProvider) ListAvailabilityZones() (res map[string]bool, xerr fail.E
 
 // ListRegions ...
 func (w RetryProvider) ListRegions() (res []string, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
 			res, xerr = w.InnerProvider.ListRegions()
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return res, retryErr
+	}
+
+	return res, xerr
+}
+
+// GetQuotas ...
+func (w RetryProvider) GetQuotas() (res *abstract.Quotas, xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetQuotas()
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -328,25 +344,18 @@ func (w RetryProvider) ListRegions() (res []string, xerr fail.Error) {
 
 // GetImage ...
 func (w RetryProvider) GetImage(id string) (res *abstract.Image, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetImage(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetImage(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -357,25 +366,18 @@ func (w RetryProvider) GetImage(id string) (res *abstract.Image, xerr fail.Error
 
 // GetTemplate ...
 func (w RetryProvider) GetTemplate(id string) (res *abstract.HostTemplate, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetTemplate(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetTemplate(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -386,25 +388,18 @@ func (w RetryProvider) GetTemplate(id string) (res *abstract.HostTemplate, xerr
 
 // CreateKeyPair ...
 func (w RetryProvider) CreateKeyPair(name string) (kp *abstract.KeyPair, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			kp, xerr = w.InnerProvider.CreateKeyPair(name)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			kp, xerr = w.InnerProvider.CreateKeyPair(name)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return kp, retryErr
@@ -415,25 +410,18 @@ func (w RetryProvider) CreateKeyPair(name string) (kp *abstract.KeyPair, xerr fa
 
 // GetKeyPair ...
 func (w RetryProvider) GetKeyPair(id string) (kp *abstract.KeyPair, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			kp, xerr = w.InnerProvider.GetKeyPair(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			kp, xerr = w.InnerProvider.GetKeyPair(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return kp, retryErr
@@ -444,25 +432,18 @@ func (w RetryProvider) GetKeyPair(id string) (kp *abstract.KeyPair, xerr fail.Er
 
 // ListKeyPairs ...
 func (w RetryProvider) ListKeyPairs() (res []abstract.KeyPair, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListKeyPairs()
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListKeyPairs()
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -473,25 +454,18 @@ func (w RetryProvider) ListKeyPairs() (res []abstract.KeyPair, xerr fail.Error)
 
 // DeleteKeyPair ...
 func (w RetryProvider) DeleteKeyPair(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.DeleteKeyPair(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.DeleteKeyPair(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -502,25 +476,18 @@ func (w RetryProvider) DeleteKeyPair(id string) (xerr fail.Error) {
 
 // CreateNetwork ...
 func (w RetryProvider) CreateNetwork(req abstract.NetworkRequest) (res *abstract.Network, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.CreateNetwork(req)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.CreateNetwork(req)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -531,25 +498,18 @@ func (w RetryProvider) CreateNetwork(req abstract.NetworkRequest) (res *abstract
 
 // GetNetwork ...
 func (w RetryProvider) GetNetwork(id string) (res *abstract.Network, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetNetwork(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetNetwork(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -560,25 +520,18 @@ func (w RetryProvider) GetNetwork(id string) (res *abstract.Network, xerr fail.E
 
 // GetNetworkByName ...
 func (w RetryProvider) GetNetworkByName(name string) (res *abstract.Network, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetNetworkByName(name)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetNetworkByName(name)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -589,25 +542,18 @@ func (w RetryProvider) GetNetworkByName(name string) (res *abstract.Network, xer
 
 // ListNetworks ...
 func (w RetryProvider) ListNetworks() (res []*abstract.Network, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListNetworks()
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListNetworks()
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -618,25 +564,62 @@ func (w RetryProvider) ListNetworks() (res []*abstract.Network, xerr fail.Error)
 
 // DeleteNetwork ...
 func (w RetryProvider) DeleteNetwork(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
 			xerr = w.InnerProvider.DeleteNetwork(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// UpdateNetworkDNSServers ...
+func (w RetryProvider) UpdateNetworkDNSServers(id string, dnsServers []string) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			xerr = w.InnerProvider.UpdateNetworkDNSServers(id, dnsServers)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// SetNetworkRoutes ...
+func (w RetryProvider) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.SetNetworkRoutes(id, routes)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -647,25 +630,18 @@ func (w RetryProvider) DeleteNetwork(id string) (xerr fail.Error) {
 
 // CreateGateway ...
 func (w RetryProvider) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (res *abstract.Host, data *userdata.Content, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, data, xerr = w.InnerProvider.CreateGateway(req, sizing)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, data, xerr = w.InnerProvider.CreateGateway(req, sizing)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, data, retryErr
@@ -676,25 +652,18 @@ func (w RetryProvider) CreateGateway(req abstract.GatewayRequest, sizing *abstra
 
 // DeleteGateway ...
 func (w RetryProvider) DeleteGateway(networkID string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.DeleteGateway(networkID)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.DeleteGateway(networkID)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -705,25 +674,18 @@ func (w RetryProvider) DeleteGateway(networkID string) (xerr fail.Error) {
 
 // CreateHost ...
 func (w RetryProvider) CreateHost(request abstract.HostRequest) (res *abstract.Host, data *userdata.Content, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, data, xerr = w.InnerProvider.CreateHost(request)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, data, xerr = w.InnerProvider.CreateHost(request)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, data, retryErr
@@ -734,25 +696,18 @@ func (w RetryProvider) CreateHost(request abstract.HostRequest) (res *abstract.H
 
 // InspectHost ...
 func (w RetryProvider) InspectHost(something interface{}) (res *abstract.Host, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.InspectHost(something)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.InspectHost(something)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -763,25 +718,18 @@ func (w RetryProvider) InspectHost(something interface{}) (res *abstract.Host, x
 
 // GetHostByName ...
 func (w RetryProvider) GetHostByName(name string) (res *abstract.Host, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetHostByName(name)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetHostByName(name)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -792,25 +740,18 @@ func (w RetryProvider) GetHostByName(name string) (res *abstract.Host, xerr fail
 
 // GetHostState ...
 func (w RetryProvider) GetHostState(something interface{}) (res hoststate.Enum, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetHostState(something)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetHostState(something)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -821,25 +762,18 @@ func (w RetryProvider) GetHostState(something interface{}) (res hoststate.Enum,
 
 // ListHosts ...
 func (w RetryProvider) ListHosts() (res []*abstract.Host, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListHosts()
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListHosts()
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -850,25 +784,18 @@ func (w RetryProvider) ListHosts() (res []*abstract.Host, xerr fail.Error) {
 
 // DeleteHost ...
 func (w RetryProvider) DeleteHost(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.DeleteHost(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.DeleteHost(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -879,25 +806,18 @@ func (w RetryProvider) DeleteHost(id string) (xerr fail.Error) {
 
 // StopHost ...
 func (w RetryProvider) StopHost(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.StopHost(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.StopHost(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -908,25 +828,216 @@ func (w RetryProvider) StopHost(id string) (xerr fail.Error) {
 
 // StartHost ...
 func (w RetryProvider) StartHost(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
 			xerr = w.InnerProvider.StartHost(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// SetHostIPForwarding ...
+func (w RetryProvider) SetHostIPForwarding(host *abstract.Host, enabled bool) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			xerr = w.InnerProvider.SetHostIPForwarding(host, enabled)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// BindSecurityGroupToHost ...
+func (w RetryProvider) BindSecurityGroupToHost(id string, sgName string) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			xerr = w.InnerProvider.BindSecurityGroupToHost(id, sgName)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// UnbindSecurityGroupFromHost ...
+func (w RetryProvider) UnbindSecurityGroupFromHost(id string, sgName string) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			xerr = w.InnerProvider.UnbindSecurityGroupFromHost(id, sgName)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// AddRulesToSecurityGroup ...
+func (w RetryProvider) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			xerr = w.InnerProvider.AddRulesToSecurityGroup(sgName, rules)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// GetHostConsoleOutput ...
+func (w RetryProvider) GetHostConsoleOutput(id string, lines int) (res string, xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			res, xerr = w.InnerProvider.GetHostConsoleOutput(id, lines)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return res, retryErr
+	}
+
+	return res, xerr
+}
+
+// ListHostNetworkInterfaces ...
+func (w RetryProvider) ListHostNetworkInterfaces(id string) (res []abstract.HostNetworkInterface, xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			res, xerr = w.InnerProvider.ListHostNetworkInterfaces(id)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return res, retryErr
+	}
+
+	return res, xerr
+}
+
+// CreateHostSnapshot ...
+func (w RetryProvider) CreateHostSnapshot(id string, name string) (res string, xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			res, xerr = w.InnerProvider.CreateHostSnapshot(id, name)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return res, retryErr
+	}
+
+	return res, xerr
+}
+
+// WaitHostSnapshotReady ...
+func (w RetryProvider) WaitHostSnapshotReady(snapshotID string) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
+			}
+			xerr = w.InnerProvider.WaitHostSnapshotReady(snapshotID)
+			return w.classify(xerr, &reauthenticated)
+		},
+		0,
+		temporal.GetContextTimeout(),
+		maxAttempts,
+	)
+	if retryErr != nil {
+		return retryErr
+	}
+
+	return xerr
+}
+
+// RenameHost ...
+func (w RetryProvider) RenameHost(id string, newName string) (xerr fail.Error) {
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
+		func() error {
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.RenameHost(id, newName)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -937,25 +1048,18 @@ func (w RetryProvider) StartHost(id string) (xerr fail.Error) {
 
 // RebootHost ...
 func (w RetryProvider) RebootHost(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.RebootHost(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.RebootHost(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -966,25 +1070,18 @@ func (w RetryProvider) RebootHost(id string) (xerr fail.Error) {
 
 // ResizeHost ...
 func (w RetryProvider) ResizeHost(id string, request abstract.SizingRequirements) (res *abstract.Host, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ResizeHost(id, request)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ResizeHost(id, request)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -995,25 +1092,18 @@ func (w RetryProvider) ResizeHost(id string, request abstract.SizingRequirements
 
 // CreateVolume ...
 func (w RetryProvider) CreateVolume(request abstract.VolumeRequest) (res *abstract.Volume, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.CreateVolume(request)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.CreateVolume(request)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -1024,25 +1114,18 @@ func (w RetryProvider) CreateVolume(request abstract.VolumeRequest) (res *abstra
 
 // GetVolume ...
 func (w RetryProvider) GetVolume(id string) (res *abstract.Volume, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetVolume(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetVolume(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -1053,25 +1136,18 @@ func (w RetryProvider) GetVolume(id string) (res *abstract.Volume, xerr fail.Err
 
 // ListVolumes ...
 func (w RetryProvider) ListVolumes() (res []abstract.Volume, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListVolumes()
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListVolumes()
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -1082,25 +1158,18 @@ func (w RetryProvider) ListVolumes() (res []abstract.Volume, xerr fail.Error) {
 
 // DeleteVolume ...
 func (w RetryProvider) DeleteVolume(id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.DeleteVolume(id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.DeleteVolume(id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -1111,25 +1180,18 @@ func (w RetryProvider) DeleteVolume(id string) (xerr fail.Error) {
 
 // CreateVolumeAttachment ...
 func (w RetryProvider) CreateVolumeAttachment(request abstract.VolumeAttachmentRequest) (res string, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.CreateVolumeAttachment(request)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.CreateVolumeAttachment(request)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -1140,25 +1202,18 @@ func (w RetryProvider) CreateVolumeAttachment(request abstract.VolumeAttachmentR
 
 // GetVolumeAttachment ...
 func (w RetryProvider) GetVolumeAttachment(serverID, id string) (res *abstract.VolumeAttachment, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.GetVolumeAttachment(serverID, id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.GetVolumeAttachment(serverID, id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -1169,25 +1224,18 @@ func (w RetryProvider) GetVolumeAttachment(serverID, id string) (res *abstract.V
 
 // ListVolumeAttachments ...
 func (w RetryProvider) ListVolumeAttachments(serverID string) (res []abstract.VolumeAttachment, xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			res, xerr = w.InnerProvider.ListVolumeAttachments(serverID)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			res, xerr = w.InnerProvider.ListVolumeAttachments(serverID)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return res, retryErr
@@ -1198,25 +1246,18 @@ func (w RetryProvider) ListVolumeAttachments(serverID string) (res []abstract.Vo
 
 // DeleteVolumeAttachment ...
 func (w RetryProvider) DeleteVolumeAttachment(serverID, id string) (xerr fail.Error) {
-	retryErr := retry.WhileUnsuccessful(
+	reauthenticated := false
+	retryErr := retry.WhileUnsuccessfulWithLimit(
 		func() error {
-			xerr = w.InnerProvider.DeleteVolumeAttachment(serverID, id)
-			if xerr != nil {
-				switch xerr.(type) {
-				case fail.ErrTimeout:
-					return xerr
-				case *net.DNSError:
-					return xerr
-				case fail.ErrInvalidRequest:
-					return xerr
-				default:
-					return nil
-				}
+			if xerr = w.breaker.Allow(); xerr != nil {
+				return nil
 			}
-			return nil
+			xerr = w.InnerProvider.DeleteVolumeAttachment(serverID, id)
+			return w.classify(xerr, &reauthenticated)
 		},
 		0,
 		temporal.GetContextTimeout(),
+		maxAttempts,
 	)
 	if retryErr != nil {
 		return retryErr
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// expiringTokenProvider fails with ErrUnauthorized until Reauthenticate is called, or always if 'revoked'
+type expiringTokenProvider struct {
+	Provider
+	revoked         bool
+	authenticated   bool
+	calls           int
+	reauthenticated int
+}
+
+func (p *expiringTokenProvider) ListImages(bool) ([]abstract.Image, fail.Error) {
+	p.calls++
+	if !p.authenticated {
+		return nil, fail.UnauthorizedError("token expired")
+	}
+	return []abstract.Image{{Name: "image"}}, nil
+}
+
+func (p *expiringTokenProvider) Reauthenticate() fail.Error {
+	p.reauthenticated++
+	p.authenticated = !p.revoked
+	return nil
+}
+
+func TestRetryProviderRetriesAfterReauthentication(t *testing.T) {
+	inner := &expiringTokenProvider{}
+	images, err := NewRetryProvider(inner, "test").ListImages(false)
+	assert.Nil(t, err)
+	assert.Len(t, images, 1)
+	assert.Equal(t, 1, inner.reauthenticated)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestRetryProviderGivesUpWhenReauthenticationDoesNotHelp(t *testing.T) {
+	inner := &expiringTokenProvider{revoked: true}
+	_, err := NewRetryProvider(inner, "test").ListImages(false)
+	_, ok := err.(fail.ErrUnauthorized)
+	assert.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, 1, inner.reauthenticated)
+	assert.Equal(t, 2, inner.calls)
+}
@@ -47,6 +47,11 @@ func (w ErrorTraceProvider) ListTemplates(all bool) (templates []abstract.HostTe
 	return w.InnerProvider.ListTemplates(all)
 }
 
+// RefreshCatalog ...
+func (w ErrorTraceProvider) RefreshCatalog() {
+	w.InnerProvider.RefreshCatalog()
+}
+
 // GetAuthenticationOptions ...
 func (w ErrorTraceProvider) GetAuthenticationOptions() (cfg providers.Config, xerr fail.Error) {
 	defer func(prefix string) {
@@ -105,6 +110,16 @@ func (w ErrorTraceProvider) ListRegions() (regions []string, xerr fail.Error) {
 	return w.InnerProvider.ListRegions()
 }
 
+// GetQuotas ...
+func (w ErrorTraceProvider) GetQuotas() (quotas *abstract.Quotas, xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:GetQuotas", w.Name))
+	return w.InnerProvider.GetQuotas()
+}
+
 // GetImage ...
 func (w ErrorTraceProvider) GetImage(id string) (images *abstract.Image, xerr fail.Error) {
 	defer func(prefix string) {
@@ -215,6 +230,26 @@ func (w ErrorTraceProvider) DeleteNetwork(id string) (xerr fail.Error) {
 	return w.InnerProvider.DeleteNetwork(id)
 }
 
+// UpdateNetworkDNSServers ...
+func (w ErrorTraceProvider) UpdateNetworkDNSServers(id string, dnsServers []string) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:UpdateNetworkDNSServers", w.Name))
+	return w.InnerProvider.UpdateNetworkDNSServers(id, dnsServers)
+}
+
+// SetNetworkRoutes ...
+func (w ErrorTraceProvider) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:SetNetworkRoutes", w.Name))
+	return w.InnerProvider.SetNetworkRoutes(id, routes)
+}
+
 // CreateGateway ...
 func (w ErrorTraceProvider) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (host *abstract.Host, content *userdata.Content, xerr fail.Error) {
 	defer func(prefix string) {
@@ -335,6 +370,96 @@ func (w ErrorTraceProvider) ListHosts() (_ []*abstract.Host, xerr fail.Error) {
 	return w.InnerProvider.ListHosts()
 }
 
+// SetHostIPForwarding ...
+func (w ErrorTraceProvider) SetHostIPForwarding(host *abstract.Host, enabled bool) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:SetHostIPForwarding", w.Name))
+	return w.InnerProvider.SetHostIPForwarding(host, enabled)
+}
+
+// BindSecurityGroupToHost ...
+func (w ErrorTraceProvider) BindSecurityGroupToHost(id string, sgName string) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:BindSecurityGroupToHost", w.Name))
+	return w.InnerProvider.BindSecurityGroupToHost(id, sgName)
+}
+
+// UnbindSecurityGroupFromHost ...
+func (w ErrorTraceProvider) UnbindSecurityGroupFromHost(id string, sgName string) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:UnbindSecurityGroupFromHost", w.Name))
+	return w.InnerProvider.UnbindSecurityGroupFromHost(id, sgName)
+}
+
+// AddRulesToSecurityGroup ...
+func (w ErrorTraceProvider) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:AddRulesToSecurityGroup", w.Name))
+	return w.InnerProvider.AddRulesToSecurityGroup(sgName, rules)
+}
+
+// GetHostConsoleOutput ...
+func (w ErrorTraceProvider) GetHostConsoleOutput(id string, lines int) (_ string, xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:GetHostConsoleOutput", w.Name))
+	return w.InnerProvider.GetHostConsoleOutput(id, lines)
+}
+
+// ListHostNetworkInterfaces ...
+func (w ErrorTraceProvider) ListHostNetworkInterfaces(id string) (_ []abstract.HostNetworkInterface, xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:ListHostNetworkInterfaces", w.Name))
+	return w.InnerProvider.ListHostNetworkInterfaces(id)
+}
+
+// CreateHostSnapshot ...
+func (w ErrorTraceProvider) CreateHostSnapshot(id string, name string) (_ string, xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:CreateHostSnapshot", w.Name))
+	return w.InnerProvider.CreateHostSnapshot(id, name)
+}
+
+// WaitHostSnapshotReady ...
+func (w ErrorTraceProvider) WaitHostSnapshotReady(snapshotID string) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:WaitHostSnapshotReady", w.Name))
+	return w.InnerProvider.WaitHostSnapshotReady(snapshotID)
+}
+
+// RenameHost ...
+func (w ErrorTraceProvider) RenameHost(id string, newName string) (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:RenameHost", w.Name))
+	return w.InnerProvider.RenameHost(id, newName)
+}
+
 // DeleteHost ...
 func (w ErrorTraceProvider) DeleteHost(id string) (xerr fail.Error) {
 	defer func(prefix string) {
@@ -469,3 +594,16 @@ func (w ErrorTraceProvider) DeleteVolumeAttachment(serverID, id string) (xerr fa
 func (w ErrorTraceProvider) GetCapabilities() providers.Capabilities {
 	return w.InnerProvider.GetCapabilities()
 }
+
+// Reauthenticate ...
+func (w ErrorTraceProvider) Reauthenticate() (xerr fail.Error) {
+	defer func(prefix string) {
+		if xerr != nil {
+			logrus.Debugf("%s : Intercepted error: %v", prefix, xerr)
+		}
+	}(fmt.Sprintf("%s:Reauthenticate", w.Name))
+	if ra, ok := w.InnerProvider.(Reauthenticator); ok {
+		return ra.Reauthenticate()
+	}
+	return fail.NotImplementedError("Reauthenticate() not implemented by provider")
+}
@@ -65,6 +65,15 @@ func (w ValidatedProvider) GetCapabilities() providers.Capabilities {
 	return w.InnerProvider.GetCapabilities()
 }
 
+func (w ValidatedProvider) Reauthenticate() (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if ra, ok := w.InnerProvider.(Reauthenticator); ok {
+		return ra.Reauthenticate()
+	}
+	return fail.NotImplementedError("Reauthenticate() not implemented by provider")
+}
+
 func (w ValidatedProvider) GetTenantParameters() map[string]interface{} {
 	return w.InnerProvider.GetTenantParameters()
 }
@@ -105,6 +114,11 @@ func (w ValidatedProvider) ListTemplates(all bool) (res []abstract.HostTemplate,
 	return res, xerr
 }
 
+// RefreshCatalog ...
+func (w ValidatedProvider) RefreshCatalog() {
+	w.InnerProvider.RefreshCatalog()
+}
+
 func (w ValidatedProvider) GetAuthenticationOptions() (_ providers.Config, xerr fail.Error) {
 	defer fail.OnPanic(&xerr)()
 
@@ -142,6 +156,13 @@ func (w ValidatedProvider) ListRegions() (_ []string, xerr fail.Error) {
 	return w.InnerProvider.ListRegions()
 }
 
+// GetQuotas ...
+func (w ValidatedProvider) GetQuotas() (_ *abstract.Quotas, xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	return w.InnerProvider.GetQuotas()
+}
+
 // GetImage ...
 func (w ValidatedProvider) GetImage(id string) (res *abstract.Image, xerr fail.Error) {
 	defer fail.OnPanic(&xerr)()
@@ -310,6 +331,31 @@ func (w ValidatedProvider) DeleteNetwork(id string) (xerr fail.Error) {
 	return w.InnerProvider.DeleteNetwork(id)
 }
 
+// UpdateNetworkDNSServers ...
+func (w ValidatedProvider) UpdateNetworkDNSServers(id string, dnsServers []string) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return fail.InvalidParameterError("id", "cannot be empty string")
+	}
+	if len(dnsServers) == 0 {
+		return fail.InvalidParameterError("dnsServers", "cannot be empty")
+	}
+
+	return w.InnerProvider.UpdateNetworkDNSServers(id, dnsServers)
+}
+
+// SetNetworkRoutes ...
+func (w ValidatedProvider) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return fail.InvalidParameterError("id", "cannot be empty string")
+	}
+
+	return w.InnerProvider.SetNetworkRoutes(id, routes)
+}
+
 // CreateGateway ...
 func (w ValidatedProvider) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (res *abstract.Host, data *userdata.Content, xerr fail.Error) {
 	defer fail.OnPanic(&xerr)()
@@ -464,6 +510,128 @@ func (w ValidatedProvider) StartHost(id string) (xerr fail.Error) {
 	return w.InnerProvider.StartHost(id)
 }
 
+// SetHostIPForwarding ...
+func (w ValidatedProvider) SetHostIPForwarding(host *abstract.Host, enabled bool) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if host == nil {
+		return fail.InvalidParameterError("host", "cannot be nil")
+	}
+	if host.ID == "" {
+		return fail.InvalidParameterError("host.ID", "cannot be empty string")
+	}
+
+	return w.InnerProvider.SetHostIPForwarding(host, enabled)
+}
+
+// BindSecurityGroupToHost ...
+func (w ValidatedProvider) BindSecurityGroupToHost(id string, sgName string) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return fail.InvalidParameterError("id", "cannot be empty string")
+	}
+	if sgName == "" {
+		return fail.InvalidParameterError("sgName", "cannot be empty string")
+	}
+
+	return w.InnerProvider.BindSecurityGroupToHost(id, sgName)
+}
+
+// UnbindSecurityGroupFromHost ...
+func (w ValidatedProvider) UnbindSecurityGroupFromHost(id string, sgName string) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return fail.InvalidParameterError("id", "cannot be empty string")
+	}
+	if sgName == "" {
+		return fail.InvalidParameterError("sgName", "cannot be empty string")
+	}
+
+	return w.InnerProvider.UnbindSecurityGroupFromHost(id, sgName)
+}
+
+// AddRulesToSecurityGroup ...
+func (w ValidatedProvider) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if sgName == "" {
+		return fail.InvalidParameterError("sgName", "cannot be empty string")
+	}
+	for _, rule := range rules {
+		if xerr = rule.Validate(); xerr != nil {
+			return xerr
+		}
+	}
+
+	return w.InnerProvider.AddRulesToSecurityGroup(sgName, rules)
+}
+
+// GetHostConsoleOutput ...
+func (w ValidatedProvider) GetHostConsoleOutput(id string, lines int) (_ string, xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return "", fail.InvalidParameterError("id", "cannot be empty string")
+	}
+	if lines < 0 {
+		return "", fail.InvalidParameterError("lines", "cannot be negative")
+	}
+
+	return w.InnerProvider.GetHostConsoleOutput(id, lines)
+}
+
+// ListHostNetworkInterfaces ...
+func (w ValidatedProvider) ListHostNetworkInterfaces(id string) (_ []abstract.HostNetworkInterface, xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return nil, fail.InvalidParameterError("id", "cannot be empty string")
+	}
+
+	return w.InnerProvider.ListHostNetworkInterfaces(id)
+}
+
+// CreateHostSnapshot ...
+func (w ValidatedProvider) CreateHostSnapshot(id string, name string) (_ string, xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return "", fail.InvalidParameterError("id", "cannot be empty string")
+	}
+	if name == "" {
+		return "", fail.InvalidParameterError("name", "cannot be empty string")
+	}
+
+	return w.InnerProvider.CreateHostSnapshot(id, name)
+}
+
+// WaitHostSnapshotReady ...
+func (w ValidatedProvider) WaitHostSnapshotReady(snapshotID string) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if snapshotID == "" {
+		return fail.InvalidParameterError("snapshotID", "cannot be empty string")
+	}
+
+	return w.InnerProvider.WaitHostSnapshotReady(snapshotID)
+}
+
+// RenameHost ...
+func (w ValidatedProvider) RenameHost(id string, newName string) (xerr fail.Error) {
+	defer fail.OnPanic(&xerr)()
+
+	if id == "" {
+		return fail.InvalidParameterError("id", "cannot be empty string")
+	}
+	if newName == "" {
+		return fail.InvalidParameterError("newName", "cannot be empty string")
+	}
+
+	return w.InnerProvider.RenameHost(id, newName)
+}
+
 // RebootHost ...
 func (w ValidatedProvider) RebootHost(id string) (xerr fail.Error) {
 	defer fail.OnPanic(&xerr)()
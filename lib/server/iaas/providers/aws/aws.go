@@ -179,10 +179,23 @@ func (p *provider) Build(params map[string]interface{}) (apiprovider.Provider, e
 		}
 	}
 
+	keepProviderDefaultSecurityGroup, _ := computeCfg["KeepProviderDefaultSecurityGroup"].(bool)
+	catalogCacheTTL, err := stacks.ParseCatalogCacheTTL(computeCfg["CatalogCacheTTL"])
+	if err != nil {
+		return nil, err
+	}
+	systemDiskSizes, err := stacks.ParseSystemDiskSizes(computeCfg["SystemDiskSizes"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfgOptions := stacks.ConfigurationOptions{
-		DNSList:                   []string{},
-		UseFloatingIP:             true,
-		AutoHostNetworkInterfaces: false,
+		KeepProviderDefaultSecurityGroup: keepProviderDefaultSecurityGroup,
+		CatalogCacheTTL:                  catalogCacheTTL,
+		SystemDiskSizes:                  systemDiskSizes,
+		DNSList:                          []string{},
+		UseFloatingIP:                    true,
+		AutoHostNetworkInterfaces:        false,
 		VolumeSpeeds: map[string]volumespeed.Enum{
 			"standard":   volumespeed.COLD,
 			"performant": volumespeed.HDD,
@@ -252,8 +265,16 @@ func (p *provider) ListTemplates(all bool) ([]abstract.HostTemplate, error) {
 
 // GetCapabilities returns the capabilities of the provider
 func (p *provider) GetCapabilities() providers.Capabilities {
+	opts := p.Stack.GetConfigurationOptions()
 	return providers.Capabilities{
 		PrivateVirtualIP: false,
+		Layer3Networking: opts.UseLayer3Networking,
+		FloatingIP:       opts.UseFloatingIP,
+		SecurityGroups:   true,
+		GPU:              true,
+		BootFromVolume:   true,
+		SpotInstances:    true,
+		SpotMaxPrice:     true,
 	}
 }
 
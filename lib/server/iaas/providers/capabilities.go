@@ -16,6 +16,38 @@
 
 package providers
 
+// ProviderCapability identifies a feature that a provider may or may not support
+type ProviderCapability int
+
+const (
+	// FloatingIP tells if the provider uses floating IPs to give public access to hosts
+	FloatingIP ProviderCapability = iota
+	// Layer3 tells if the provider uses Layer3 networking (routers)
+	Layer3
+	// SecurityGroups tells if the provider supports security groups
+	SecurityGroups
+	// VIP tells if the provider is able to provide a private Virtual IP
+	VIP
+	// GPU tells if the provider proposes templates with GPU
+	GPU
+	// BootFromVolume tells if the provider is able to boot hosts from a volume
+	BootFromVolume
+	// HostFromSnapshot tells if the provider is able to create a host from the snapshot of another host
+	HostFromSnapshot
+	// ShieldedVM tells if the provider is able to create hosts with verified boot (secure boot, vTPM, ...)
+	ShieldedVM
+	// ConfidentialVM tells if the provider is able to create hosts with memory encrypted by the CPU
+	ConfidentialVM
+	// AffinityGroups tells if the provider is able to place hosts on distinct (or the same) physical hosts
+	AffinityGroups
+	// SpotInstances tells if the provider is able to create spot (preemptible) hosts
+	SpotInstances
+	// SpotMaxPrice tells if the provider is able to limit the hourly price of a spot host
+	SpotMaxPrice
+	// FixedIPs tells if the provider is able to give the IP addresses chosen by the user to a new host
+	FixedIPs
+)
+
 // Capabilities represents key/value configuration.
 type Capabilities struct {
 	// PublicVirtualIP indicates if the provider has the capability to provide a Virtual IP with public IP address
@@ -24,4 +56,60 @@ type Capabilities struct {
 	PrivateVirtualIP bool
 	// Layer3Networking indicates if the provider uses Layer3 networking
 	Layer3Networking bool
+	// FloatingIP indicates if the provider uses floating IPs to give public access to hosts
+	FloatingIP bool
+	// SecurityGroups indicates if the provider supports security groups
+	SecurityGroups bool
+	// GPU indicates if the provider proposes templates with GPU
+	GPU bool
+	// BootFromVolume indicates if the provider is able to boot hosts from a volume
+	BootFromVolume bool
+	// HostFromSnapshot indicates if the provider is able to create a host from the snapshot of another host
+	HostFromSnapshot bool
+	// ShieldedVM indicates if the provider is able to create hosts with verified boot (secure boot, vTPM, ...)
+	ShieldedVM bool
+	// ConfidentialVM indicates if the provider is able to create hosts with memory encrypted by the CPU
+	ConfidentialVM bool
+	// AffinityGroups indicates if the provider is able to place hosts on distinct (or the same) physical hosts
+	AffinityGroups bool
+	// SpotInstances indicates if the provider is able to create spot (preemptible) hosts
+	SpotInstances bool
+	// SpotMaxPrice indicates if the provider is able to limit the hourly price of a spot host
+	SpotMaxPrice bool
+	// FixedIPs indicates if the provider is able to give the IP addresses chosen by the user to a new host
+	FixedIPs bool
+}
+
+// Supports tells if the capability 'cap' is part of the capabilities
+func (c Capabilities) Supports(cap ProviderCapability) bool {
+	switch cap {
+	case FloatingIP:
+		return c.FloatingIP
+	case Layer3:
+		return c.Layer3Networking
+	case SecurityGroups:
+		return c.SecurityGroups
+	case VIP:
+		return c.PrivateVirtualIP
+	case GPU:
+		return c.GPU
+	case BootFromVolume:
+		return c.BootFromVolume
+	case HostFromSnapshot:
+		return c.HostFromSnapshot
+	case ShieldedVM:
+		return c.ShieldedVM
+	case ConfidentialVM:
+		return c.ConfidentialVM
+	case AffinityGroups:
+		return c.AffinityGroups
+	case SpotInstances:
+		return c.SpotInstances
+	case SpotMaxPrice:
+		return c.SpotMaxPrice
+	case FixedIPs:
+		return c.FixedIPs
+	default:
+		return false
+	}
 }
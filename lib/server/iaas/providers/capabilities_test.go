@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities_Supports(t *testing.T) {
+	caps := Capabilities{
+		PrivateVirtualIP: true,
+		FloatingIP:       true,
+		GPU:              true,
+		ShieldedVM:       true,
+	}
+
+	assert.True(t, caps.Supports(VIP))
+	assert.True(t, caps.Supports(FloatingIP))
+	assert.True(t, caps.Supports(GPU))
+	assert.False(t, caps.Supports(Layer3))
+	assert.False(t, caps.Supports(SecurityGroups))
+	assert.False(t, caps.Supports(BootFromVolume))
+	assert.False(t, caps.Supports(HostFromSnapshot))
+	assert.True(t, caps.Supports(ShieldedVM))
+	assert.False(t, caps.Supports(ConfidentialVM))
+	assert.False(t, caps.Supports(SpotInstances))
+	assert.True(t, Capabilities{SpotInstances: true}.Supports(SpotInstances))
+	assert.False(t, Capabilities{SpotInstances: true}.Supports(SpotMaxPrice))
+	assert.False(t, caps.Supports(FixedIPs))
+	assert.True(t, Capabilities{FixedIPs: true}.Supports(FixedIPs))
+	assert.False(t, caps.Supports(ProviderCapability(-1)))
+
+	assert.False(t, Capabilities{PublicVirtualIP: true}.Supports(VIP))
+}
@@ -152,7 +152,11 @@ func (p *provider) GetTenantParameters() map[string]interface{} {
 
 // GetCapabilities returns the capabilities of the provider
 func (p *provider) GetCapabilities() providers.Capabilities {
-	return providers.Capabilities{}
+	opts := p.StackEbrc.GetConfigurationOptions()
+	return providers.Capabilities{
+		Layer3Networking: opts.UseLayer3Networking,
+		FloatingIP:       opts.UseFloatingIP,
+	}
 }
 
 func init() {
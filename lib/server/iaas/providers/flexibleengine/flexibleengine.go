@@ -139,10 +139,23 @@ func (p *provider) Build(params map[string]interface{}) (apiprovider.Provider, e
 		}
 	}
 
+	keepProviderDefaultSecurityGroup, _ := compute["KeepProviderDefaultSecurityGroup"].(bool)
+	catalogCacheTTL, err := stacks.ParseCatalogCacheTTL(compute["CatalogCacheTTL"])
+	if err != nil {
+		return nil, err
+	}
+	systemDiskSizes, err := stacks.ParseSystemDiskSizes(compute["SystemDiskSizes"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfgOptions := stacks.ConfigurationOptions{
-		DNSList:             []string{"100.125.0.41", "100.126.0.41"},
-		UseFloatingIP:       true,
-		UseLayer3Networking: false,
+		KeepProviderDefaultSecurityGroup: keepProviderDefaultSecurityGroup,
+		CatalogCacheTTL:                  catalogCacheTTL,
+		SystemDiskSizes:                  systemDiskSizes,
+		DNSList:                          []string{"100.125.0.41", "100.126.0.41"},
+		UseFloatingIP:                    true,
+		UseLayer3Networking:              false,
 		VolumeSpeeds: map[string]volumespeed.Enum{
 			"SATA": volumespeed.COLD,
 			"SSD":  volumespeed.SSD,
@@ -367,8 +380,16 @@ func (p *provider) GetTenantParameters() map[string]interface{} {
 
 // GetCapabilities returns the capabilities of the provider
 func (p *provider) GetCapabilities() providers.Capabilities {
+	opts := p.Stack.GetConfigurationOptions()
 	return providers.Capabilities{
 		PrivateVirtualIP: true,
+		Layer3Networking: opts.UseLayer3Networking,
+		FloatingIP:       opts.UseFloatingIP,
+		SecurityGroups:   true,
+		GPU:              true,
+		BootFromVolume:   true,
+		HostFromSnapshot: true,
+		FixedIPs:         true,
 	}
 }
 
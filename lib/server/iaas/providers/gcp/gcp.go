@@ -131,6 +131,15 @@ func (p *provider) Build(params map[string]interface{}) (apiprovider.Provider, e
 		}
 	}
 
+	catalogCacheTTL, err := stacks.ParseCatalogCacheTTL(computeCfg["CatalogCacheTTL"])
+	if err != nil {
+		return nil, err
+	}
+	systemDiskSizes, err := stacks.ParseSystemDiskSizes(computeCfg["SystemDiskSizes"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfgOptions := stacks.ConfigurationOptions{
 		DNSList:                   []string{"8.8.8.8", "1.1.1.1"},
 		UseFloatingIP:             true,
@@ -144,6 +153,8 @@ func (p *provider) Build(params map[string]interface{}) (apiprovider.Provider, e
 		OperatorUsername: operatorUsername,
 		UseNATService:    true,
 		ProviderName:     providerName,
+		CatalogCacheTTL:  catalogCacheTTL,
+		SystemDiskSizes:  systemDiskSizes,
 	}
 
 	stack, err := gcp.New(authOptions, gcpConf, cfgOptions)
@@ -206,7 +217,19 @@ func (p *provider) GetTenantParameters() map[string]interface{} {
 
 // GetCapabilities returns the capabilities of the provider
 func (p *provider) GetCapabilities() providers.Capabilities {
-	return providers.Capabilities{}
+	opts := p.Stack.GetConfigurationOptions()
+	return providers.Capabilities{
+		Layer3Networking: opts.UseLayer3Networking,
+		FloatingIP:       opts.UseFloatingIP,
+		PrivateVirtualIP: true,
+		GPU:              true,
+		HostFromSnapshot: true,
+		ShieldedVM:       true,
+		ConfidentialVM:   true,
+		AffinityGroups:   true,
+		SpotInstances:    true,
+		FixedIPs:         true,
+	}
 }
 
 func init() {
@@ -64,6 +64,11 @@ func (provider *provider) ListRegions() ([]string, error) {
 	return nil, fmt.Errorf(errorStr)
 }
 
+// GetQuotas returns the limits and usages of the tenant resources
+func (provider *provider) GetQuotas() (*abstract.Quotas, error) {
+	return nil, fmt.Errorf(errorStr)
+}
+
 func (provider *provider) ListImages(all bool) ([]abstract.Image, error) {
 	return nil, fmt.Errorf(errorStr)
 }
@@ -77,6 +82,7 @@ func (provider *provider) GetTemplate(id string) (*abstract.HostTemplate, error)
 func (provider *provider) ListTemplates(all bool) ([]abstract.HostTemplate, error) {
 	return nil, fmt.Errorf(errorStr)
 }
+func (provider *provider) RefreshCatalog() {}
 
 func (provider *provider) CreateKeyPair(name string) (*abstract.KeyPair, error) {
 	return nil, fmt.Errorf(errorStr)
@@ -106,6 +112,12 @@ func (provider *provider) ListNetworks() ([]*abstract.Network, error) {
 func (provider *provider) DeleteNetwork(id string) error {
 	return fmt.Errorf(errorStr)
 }
+func (provider *provider) UpdateNetworkDNSServers(id string, dnsServers []string) error {
+	return fmt.Errorf(errorStr)
+}
+func (provider *provider) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) error {
+	return fmt.Errorf(errorStr)
+}
 func (provider *provider) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (*abstract.Host, *userdata.Content, error) {
 	return nil, nil, fmt.Errorf(errorStr)
 }
@@ -158,6 +170,33 @@ func (provider *provider) StopHost(id string) error {
 func (provider *provider) RebootHost(id string) error {
 	return fmt.Errorf(errorStr)
 }
+func (provider *provider) SetHostIPForwarding(host *abstract.Host, enabled bool) error {
+	return fmt.Errorf(errorStr)
+}
+func (provider *provider) BindSecurityGroupToHost(id string, sgName string) error {
+	return fmt.Errorf(errorStr)
+}
+func (provider *provider) UnbindSecurityGroupFromHost(id string, sgName string) error {
+	return fmt.Errorf(errorStr)
+}
+func (provider *provider) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) error {
+	return fmt.Errorf(errorStr)
+}
+func (provider *provider) GetHostConsoleOutput(id string, lines int) (string, error) {
+	return "", fmt.Errorf(errorStr)
+}
+func (provider *provider) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, error) {
+	return nil, fmt.Errorf(errorStr)
+}
+func (provider *provider) CreateHostSnapshot(id string, name string) (string, error) {
+	return "", fmt.Errorf(errorStr)
+}
+func (provider *provider) WaitHostSnapshotReady(snapshotID string) error {
+	return fmt.Errorf(errorStr)
+}
+func (provider *provider) RenameHost(id string, newName string) error {
+	return fmt.Errorf(errorStr)
+}
 
 func (provider *provider) CreateVolume(request abstract.VolumeRequest) (*abstract.Volume, error) {
 	return nil, fmt.Errorf(errorStr)
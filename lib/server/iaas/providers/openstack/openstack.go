@@ -109,11 +109,24 @@ func (p *provider) Build(params map[string]interface{}) (apiprovider.Provider, e
 		}
 	}
 
+	keepProviderDefaultSecurityGroup, _ := compute["KeepProviderDefaultSecurityGroup"].(bool)
+	catalogCacheTTL, err := stacks.ParseCatalogCacheTTL(compute["CatalogCacheTTL"])
+	if err != nil {
+		return nil, err
+	}
+	systemDiskSizes, err := stacks.ParseSystemDiskSizes(compute["SystemDiskSizes"])
+	if err != nil {
+		return nil, err
+	}
+
 	cfgOptions := stacks.ConfigurationOptions{
-		ProviderNetwork:           providerNetwork,
-		UseFloatingIP:             true,
-		UseLayer3Networking:       true,
-		AutoHostNetworkInterfaces: true,
+		KeepProviderDefaultSecurityGroup: keepProviderDefaultSecurityGroup,
+		CatalogCacheTTL:                  catalogCacheTTL,
+		SystemDiskSizes:                  systemDiskSizes,
+		ProviderNetwork:                  providerNetwork,
+		UseFloatingIP:                    true,
+		UseLayer3Networking:              true,
+		AutoHostNetworkInterfaces:        true,
 		VolumeSpeeds: map[string]volumespeed.Enum{
 			"standard":   volumespeed.COLD,
 			"performant": volumespeed.HDD,
@@ -252,8 +265,17 @@ func (p *provider) GetTenantParameters() map[string]interface{} {
 
 // GetCapabilities returns the capabilities of the provider
 func (p *provider) GetCapabilities() providers.Capabilities {
+	opts := p.Stack.GetConfigurationOptions()
 	return providers.Capabilities{
 		PrivateVirtualIP: true,
+		Layer3Networking: opts.UseLayer3Networking,
+		FloatingIP:       opts.UseFloatingIP,
+		SecurityGroups:   true,
+		GPU:              true,
+		BootFromVolume:   true,
+		HostFromSnapshot: true,
+		AffinityGroups:   true,
+		FixedIPs:         true,
 	}
 }
 
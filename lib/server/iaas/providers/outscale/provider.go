@@ -223,6 +223,10 @@ func (p *provider) GetCapabilities() providers.Capabilities {
 		PublicVirtualIP:  false,
 		PrivateVirtualIP: true,
 		Layer3Networking: false,
+		FloatingIP:       true,
+		SecurityGroups:   true,
+		GPU:              true,
+		BootFromVolume:   true,
 	}
 }
 
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iaas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	providers "github.com/CS-SI/SafeScale/lib/server/iaas/providers/api"
+)
+
+// namedProvider implements only the calls used by the tests; others panic
+type namedProvider struct {
+	providers.Provider
+}
+
+func (namedProvider) GetName() string {
+	return "test"
+}
+
+func TestReadOnlyView(t *testing.T) {
+	svc := &service{Provider: namedProvider{}}
+	assert.False(t, svc.IsReadOnly())
+
+	view := svc.ReadOnlyView()
+	assert.True(t, view.IsReadOnly())
+	assert.False(t, svc.IsReadOnly())
+	assert.True(t, view.ReadOnlyView() == view)
+}
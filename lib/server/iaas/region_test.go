@@ -0,0 +1,109 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iaas
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestRegionScopedTenantWithAvailabilityZone(t *testing.T) {
+	tenant := map[string]interface{}{
+		"name":    "ovh",
+		"compute": map[string]interface{}{"Region": "GRA5", "AvailabilityZone": "nova", "ProjectName": "p"},
+	}
+
+	params, err := regionScopedTenant(tenant, "SBG5", "")
+	require.Nil(t, err)
+	compute := params["compute"].(map[string]interface{})
+	assert.Equal(t, "SBG5", compute["Region"])
+	assert.Equal(t, "p", compute["ProjectName"])
+	_, ok := compute["AvailabilityZone"]
+	assert.False(t, ok)
+	assert.Equal(t, "ovh", params["name"])
+	// the tenant is left untouched
+	assert.Equal(t, "GRA5", tenant["compute"].(map[string]interface{})["Region"])
+
+	params, err = regionScopedTenant(tenant, "", "nova2")
+	require.Nil(t, err)
+	compute = params["compute"].(map[string]interface{})
+	assert.Equal(t, "GRA5", compute["Region"])
+	assert.Equal(t, "nova2", compute["AvailabilityZone"])
+}
+
+func TestRegionScopedTenantWithZone(t *testing.T) {
+	tenant := map[string]interface{}{
+		"compute": map[string]interface{}{"Region": "europe-west1", "Zone": "europe-west1-b"},
+	}
+
+	_, err := regionScopedTenant(tenant, "us-east1", "")
+	assert.IsType(t, fail.ErrInvalidRequest{}, err)
+
+	params, err := regionScopedTenant(tenant, "us-east1", "us-east1-c")
+	require.Nil(t, err)
+	compute := params["compute"].(map[string]interface{})
+	assert.Equal(t, "us-east1", compute["Region"])
+	assert.Equal(t, "us-east1-c", compute["Zone"])
+	_, ok := compute["AvailabilityZone"]
+	assert.False(t, ok)
+
+	// same region, zone kept
+	params, err = regionScopedTenant(tenant, "europe-west1", "")
+	require.Nil(t, err)
+	assert.Equal(t, "europe-west1-b", params["compute"].(map[string]interface{})["Zone"])
+}
+
+func TestServiceGetRegion(t *testing.T) {
+	svc := &service{tenant: map[string]interface{}{
+		"compute": map[string]interface{}{"Region": "GRA5", "AvailabilityZone": "nova"},
+	}}
+	region, zone := svc.GetRegion()
+	assert.Equal(t, "GRA5", region)
+	assert.Equal(t, "nova", zone)
+
+	svc = &service{tenant: map[string]interface{}{
+		"compute": map[string]interface{}{"Region": "europe-west1", "Zone": "europe-west1-b"},
+	}}
+	region, zone = svc.GetRegion()
+	assert.Equal(t, "europe-west1", region)
+	assert.Equal(t, "europe-west1-b", zone)
+
+	region, zone = (&service{}).GetRegion()
+	assert.Empty(t, region)
+	assert.Empty(t, zone)
+}
+
+func TestServiceInCurrentRegion(t *testing.T) {
+	// no provider nor builder: the service must be returned without building anything
+	svc := &service{tenant: map[string]interface{}{
+		"compute": map[string]interface{}{"Region": "europe-west1", "Zone": "europe-west1-b"},
+	}}
+
+	for _, c := range [][2]string{{"", ""}, {"europe-west1", ""}, {"europe-west1", "europe-west1-b"}, {"", "europe-west1-b"}} {
+		scoped, err := svc.InRegion(c[0], c[1])
+		require.Nil(t, err)
+		assert.True(t, scoped == Service(svc), fmt.Sprintf("%v", c))
+	}
+
+	_, err := svc.InRegion("us-east1", "us-east1-c")
+	assert.IsType(t, fail.ErrNotAvailable{}, err)
+}
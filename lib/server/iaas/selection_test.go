@@ -0,0 +1,121 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iaas
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestSelectTemplatesBySizingEmptyList(t *testing.T) {
+	sizing := abstract.SizingRequirements{MinCores: 4, MinRAMSize: 15.5, MinGPU: 1}
+
+	tpls, err := selectTemplatesBySizing(nil, sizing, nil)
+	require.NotNil(t, err)
+	assert.Empty(t, tpls)
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	assert.Contains(t, err.Error(), "at least 4 cores")
+	assert.Contains(t, err.Error(), "at least 15.5 GB RAM")
+	assert.Contains(t, err.Error(), "at least 1 GPU")
+	assert.Contains(t, err.Error(), "among 0 template")
+	assert.NotContains(t, err.Error(), "scanner")
+}
+
+func TestSelectTemplatesBySizingStaleScanner(t *testing.T) {
+	allTpls := []abstract.HostTemplate{
+		{Cores: 4, RAMSize: 16, DiskSize: 50, ID: "tpl-1", Name: "s1-16"},
+	}
+	sizing := abstract.SizingRequirements{MinCores: 2, MinGPU: 0}
+
+	_, err := selectTemplatesBySizing(allTpls, sizing, map[string]bool{})
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	assert.Contains(t, err.Error(), "scanner database")
+
+	tpls, err := selectTemplatesBySizing(allTpls, sizing, map[string]bool{"tpl-1": true})
+	require.Nil(t, err)
+	require.Len(t, tpls, 1)
+	assert.Equal(t, "s1-16", tpls[0].Name)
+
+	tpls, err = selectTemplatesBySizing(allTpls, sizing, nil)
+	require.Nil(t, err)
+	require.Len(t, tpls, 1)
+}
+
+func TestScannerTemplates(t *testing.T) {
+	records := []string{
+		`{"template_id":"tpl-gpu","gpu":2,"cpu_frequency_Ghz":2.4}`,
+		`{"template_id":"tpl-cpu","gpu":0,"cpu_frequency_Ghz":3.2}`,
+		`not json`,
+	}
+
+	tpls, err := scannerTemplates(records, nil, abstract.SizingRequirements{MinGPU: 1}, true)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]bool{"tpl-gpu": true}, tpls)
+
+	tpls, err = scannerTemplates(records, nil, abstract.SizingRequirements{MinGPU: 0, MinFreq: 3}, true)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]bool{"tpl-cpu": true}, tpls)
+}
+
+func TestScannerTemplatesStrict(t *testing.T) {
+	sizing := abstract.SizingRequirements{MinGPU: 1}
+
+	_, err := scannerTemplates(nil, nil, sizing, true)
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	assert.Contains(t, err.Error(), "scanner database required for GPU/CPU frequency constraints")
+
+	_, err = scannerTemplates(nil, fmt.Errorf("no such directory"), sizing, true)
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	assert.Contains(t, err.Error(), "no such directory")
+
+	_, err = scannerTemplates([]string{`{"template_id":"tpl-cpu","gpu":0}`}, nil, sizing, true)
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	assert.Contains(t, err.Error(), "no images matching requirements")
+}
+
+func TestScannerTemplatesFallback(t *testing.T) {
+	sizing := abstract.SizingRequirements{MinGPU: 1}
+
+	// nil does not restrict the templates: the GPU and CPU frequency requirements are ignored
+	tpls, err := scannerTemplates(nil, nil, sizing, false)
+	require.Nil(t, err)
+	assert.Nil(t, tpls)
+
+	tpls, err = scannerTemplates(nil, fmt.Errorf("no such directory"), sizing, false)
+	require.Nil(t, err)
+	assert.Nil(t, tpls)
+
+	tpls, err = scannerTemplates([]string{`{"template_id":"tpl-cpu","gpu":0}`}, nil, sizing, false)
+	require.Nil(t, err)
+	assert.Nil(t, tpls)
+
+	selected, err := selectTemplatesBySizing(
+		[]abstract.HostTemplate{{Cores: 4, RAMSize: 16, ID: "tpl-1", Name: "s1-16"}}, sizing, tpls,
+	)
+	require.Nil(t, err)
+	require.Len(t, selected, 1)
+}
@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -45,7 +46,9 @@ import (
 	templatefilters "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/filters/templates"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/objectstorage"
+	iaasproviders "github.com/CS-SI/SafeScale/lib/server/iaas/providers"
 	providers "github.com/CS-SI/SafeScale/lib/server/iaas/providers/api"
+	"github.com/CS-SI/SafeScale/lib/system"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/crypt"
 )
@@ -60,13 +63,23 @@ type Service interface {
 	CreateHostWithKeyPair(abstract.HostRequest) (*abstract.Host, *userdata.Content, *abstract.KeyPair, error)
 	FilterImages(string) ([]abstract.Image, error)
 	GetMetadataKey() *crypt.Key
+	GetMetadataEncrypter() crypt.Encrypter
 	GetMetadataBucket() objectstorage.Bucket
+	GetMetadataPrefix() string
+	GetSSHConnectionOptions() system.SSHConnectionOptions
+	GetHostEventsWebhook() string
+	GetMandatoryTags() map[string]string
 	ListHostsByName() (map[string]*abstract.Host, error)
 	SearchImage(string) (*abstract.Image, error)
 	SelectTemplatesBySize(abstract.SizingRequirements, bool) ([]*abstract.HostTemplate, error)
 	SelectTemplateByName(string) (*abstract.HostTemplate, error)
+	SupportsFeature(iaasproviders.ProviderCapability) bool
 	WaitHostState(string, hoststate.Enum, time.Duration) error
 	WaitVolumeState(string, volumestate.Enum, time.Duration) (*abstract.Volume, error)
+	ReadOnlyView() Service
+	IsReadOnly() bool
+	InRegion(region, zone string) (Service, error)
+	GetRegion() (region, zone string)
 
 	// --- from interface iaas.Providers ---
 	providers.Provider
@@ -82,11 +95,39 @@ type service struct {
 	objectstorage.Location
 	metadataBucket objectstorage.Bucket
 	metadataKey    *crypt.Key
+	// metadataEncrypter is used to encrypt metadata before writing them in Object Storage; nil means no encryption
+	metadataEncrypter crypt.Encrypter
+	// metadataPrefix is prepended to the path of all the metadata, to share a bucket between several deployments
+	metadataPrefix string
+	// sshOptions tunes the SSH connections to the hosts of the tenant
+	sshOptions system.SSHConnectionOptions
+	// hostEventsWebhook is the URL receiving the changes of state of the hosts of the tenant, empty if none
+	hostEventsWebhook string
+	// mandatoryTags contains the tags every resource created must carry, indexed by name; an empty value must be
+	// given by the creation request
+	mandatoryTags map[string]string
 
 	whitelistTemplateRE *regexp.Regexp
 	blacklistTemplateRE *regexp.Regexp
 	whitelistImageRE    *regexp.Regexp
 	blacklistImageRE    *regexp.Regexp
+
+	// tenant contains the parameters of the tenant and builder the provider used to build Provider from them; they
+	// allow to build the provider again in another region
+	tenant  map[string]interface{}
+	builder providers.Provider
+	// regions caches the services built by InRegion, shared by all the views of the service
+	regions *regionCache
+}
+
+// regionCache contains the services working in another region, indexed by "<region>/<zone>"
+type regionCache struct {
+	lock     sync.Mutex
+	services map[string]Service
+}
+
+func newRegionCache() *regionCache {
+	return &regionCache{services: map[string]Service{}}
 }
 
 const (
@@ -137,15 +178,197 @@ func (svc *service) GetMetadataBucket() objectstorage.Bucket {
 	return svc.metadataBucket
 }
 
+// GetMetadataPrefix returns the path prepended to all the metadata in the bucket, empty if there is none
+func (svc *service) GetMetadataPrefix() string {
+	return svc.metadataPrefix
+}
+
+// GetSSHConnectionOptions returns the keepalive and timeout options of the SSH connections to the hosts
+func (svc *service) GetSSHConnectionOptions() system.SSHConnectionOptions {
+	return svc.sshOptions
+}
+
+// GetHostEventsWebhook returns the URL to notify of the changes of state of the hosts, empty if none
+func (svc *service) GetHostEventsWebhook() string {
+	return svc.hostEventsWebhook
+}
+
+// GetMandatoryTags returns a copy of the tags every host, volume and network created must carry
+func (svc *service) GetMandatoryTags() map[string]string {
+	tags := make(map[string]string, len(svc.mandatoryTags))
+	for k, v := range svc.mandatoryTags {
+		tags[k] = v
+	}
+	return tags
+}
+
 func (svc *service) GetMetadataKey() *crypt.Key {
 	return svc.metadataKey
 }
 
+// GetMetadataEncrypter returns the Encrypter to use on metadata content
+func (svc *service) GetMetadataEncrypter() crypt.Encrypter {
+	if svc.metadataEncrypter == nil {
+		return crypt.NoopEncrypter{}
+	}
+	return svc.metadataEncrypter
+}
+
 // SetProvider allows to change provider interface of service object (mainly for test purposes)
 func (svc *service) SetProvider(provider providers.Provider) {
 	svc.Provider = provider
 }
 
+// ReadOnlyView returns a view of the service whose provider calls creating, modifying or deleting resources
+// fail with fail.ErrForbidden; it is meant to be used by inspection code paths
+func (svc *service) ReadOnlyView() Service {
+	if svc.IsReadOnly() {
+		return svc
+	}
+	view := *svc
+	view.Provider = providers.NewReadOnlyProvider(svc.Provider, svc.Provider.GetName())
+	return &view
+}
+
+// IsReadOnly tells if the service is a read-only view (see ReadOnlyView)
+func (svc *service) IsReadOnly() bool {
+	_, ok := svc.Provider.(*providers.ReadOnlyProvider)
+	return ok
+}
+
+// InRegion returns a view of the service whose provider works in the region 'region' (and the zone 'zone', if set)
+// instead of the ones of the configuration of the tenant; object storage and metadata are shared with the service
+// Returns fail.ErrInvalidRequest if the region or the zone is not available
+func (svc *service) InRegion(region, zone string) (Service, error) {
+	if region == "" && zone == "" {
+		return svc, nil
+	}
+	currentRegion, currentZone := svc.GetRegion()
+	if (region == "" || region == currentRegion) && (zone == "" || zone == currentZone) {
+		return svc, nil
+	}
+	if svc.tenant == nil || svc.builder == nil {
+		return nil, fail.NotAvailableError("service cannot be used in another region")
+	}
+
+	key := region + "/" + zone
+	if svc.regions != nil {
+		svc.regions.lock.Lock()
+		defer svc.regions.lock.Unlock()
+		if scoped, ok := svc.regions.services[key]; ok {
+			return scoped, nil
+		}
+	}
+
+	if region != "" {
+		regions, err := svc.ListRegions()
+		if err != nil {
+			if _, ok := err.(fail.ErrNotImplemented); ok {
+				return nil, fail.NotAvailableError(
+					fmt.Sprintf("provider '%s' cannot list its regions, cannot use another region", svc.GetName()),
+				)
+			}
+			return nil, err
+		}
+		found := false
+		for _, r := range regions {
+			if r == region {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("region '%s' is not available (available: %s)", region, strings.Join(regions, ", ")),
+			)
+		}
+	}
+
+	params, err := regionScopedTenant(svc.tenant, region, zone)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := svc.builder.Build(params)
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to use region '%s': %s", region, err.Error()), err)
+	}
+
+	if zone != "" {
+		zones, err := provider.ListAvailabilityZones()
+		if err != nil {
+			if _, ok := err.(fail.ErrNotImplemented); !ok {
+				return nil, err
+			}
+		} else if !zones[zone] {
+			return nil, fail.InvalidRequestError(fmt.Sprintf("zone '%s' is not available", zone))
+		}
+	}
+
+	scoped := *svc
+	scoped.Provider = provider
+	scoped.tenant = params
+	if svc.regions != nil {
+		svc.regions.services[key] = &scoped
+	}
+	return &scoped, nil
+}
+
+// GetRegion returns the region and the zone the provider of the service works in, as configured in the tenant
+// Returns empty strings if the service has not been built from a tenant
+func (svc *service) GetRegion() (region, zone string) {
+	compute, _ := svc.tenant["compute"].(map[string]interface{})
+	region, _ = compute["Region"].(string)
+	if zone, _ = compute["Zone"].(string); zone == "" {
+		zone, _ = compute["AvailabilityZone"].(string)
+	}
+	return region, zone
+}
+
+// regionScopedTenant returns a copy of the tenant parameters 'tenant' whose section 'compute' uses the region 'region'
+// and the zone 'zone' (when not empty)
+// The zone is named 'Zone' by some providers (required with a region), 'AvailabilityZone' by the others (chosen by
+// the provider if not set)
+func regionScopedTenant(tenant map[string]interface{}, region, zone string) (map[string]interface{}, error) {
+	compute, _ := tenant["compute"].(map[string]interface{})
+	scopedCompute := make(map[string]interface{}, len(compute)+1)
+	for k, v := range compute {
+		scopedCompute[k] = v
+	}
+	_, zoneKey := compute["Zone"]
+
+	if current, _ := compute["Region"].(string); region != "" && region != current {
+		scopedCompute["Region"] = region
+		if zone == "" {
+			if zoneKey {
+				return nil, fail.InvalidRequestError(fmt.Sprintf("a zone is required to use region '%s'", region))
+			}
+			delete(scopedCompute, "AvailabilityZone")
+		}
+	}
+	if zone != "" {
+		if zoneKey {
+			scopedCompute["Zone"] = zone
+		} else {
+			scopedCompute["AvailabilityZone"] = zone
+		}
+	}
+
+	out := make(map[string]interface{}, len(tenant))
+	for k, v := range tenant {
+		out[k] = v
+	}
+	out["compute"] = scopedCompute
+	return out, nil
+}
+
+// SupportsFeature tells if the provider of the service supports the capability 'cap'
+func (svc *service) SupportsFeature(cap iaasproviders.ProviderCapability) bool {
+	if svc == nil {
+		return false
+	}
+	return svc.GetCapabilities().Supports(cap)
+}
+
 // WaitHostState waits an host achieve state
 // If host in error state, returns utils.ErrNotAvailable
 // If timeout is reached, returns utils.ErrTimeout
@@ -160,13 +383,23 @@ func (svc *service) WaitHostState(hostID string, state hoststate.Enum, timeout t
 	host := abstract.NewHost()
 	host.ID = hostID
 
-	c := make(chan error)
+	// c is buffered and done stops the polling, so the goroutine ends even if the timeout is reached first
+	c := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
 
 	go func() {
 		defer close(c)
 		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
 			host, err = svc.InspectHost(host)
 			if err != nil {
+				time.Sleep(1 * time.Second)
 				continue
 			}
 			if host.LastState == state {
@@ -294,6 +527,8 @@ func filterTemplatesByRegex(re *regexp.Regexp) templatefilters.Predicate {
 
 // SelectTemplatesBySize select templates satisfying sizing requirements
 // returned list is ordered by size fitting
+// GPU and CPU frequency requirements are checked with the scanner database; if force is set, they are ignored when
+// the database cannot satisfy them, instead of failing.
 func (svc *service) SelectTemplatesBySize(sizing abstract.SizingRequirements, force bool) (selectedTpls []*abstract.HostTemplate, err error) {
 	tracer := debug.NewTracer(nil, "", true).GoingIn()
 	defer tracer.OnExitTrace()()
@@ -308,113 +543,15 @@ func (svc *service) SelectTemplatesBySize(sizing abstract.SizingRequirements, fo
 		return nil, err
 	}
 
-	scannerTpls := map[string]bool{}
+	var scannerTpls map[string]bool
 
 	// FIXME: Prevent GPUs when user sends a 0
 	askedForSpecificScannerInfo := sizing.MinGPU >= 0 || sizing.MinFreq != 0
 	if askedForSpecificScannerInfo {
-		_ = os.MkdirAll(utils.AbsPathify("$HOME/.safescale/scanner"), 0777)
-		db, err := scribble.New(utils.AbsPathify("$HOME/.safescale/scanner/db"), nil)
+		records, err := svc.readScannerDB()
+		scannerTpls, err = scannerTemplates(records, err, sizing, !force)
 		if err != nil {
-			if force {
-				log.Warnf(
-					"Problem creating / accessing Scanner database, ignoring GPU and Freq parameters for now...: %v",
-					err,
-				)
-			} else {
-				var noHostError string
-				if sizing.MinFreq <= 0 {
-					noHostError = fmt.Sprintf(
-						"Unable to create a host with '%d' GPUs, problem accessing Scanner database: %v", sizing.MinGPU,
-						err,
-					)
-				} else {
-					noHostError = fmt.Sprintf(
-						"Unable to create a host with '%d' GPUs and '%.01f' MHz clock frequency, problem accessing Scanner database: %v",
-						sizing.MinGPU, sizing.MinFreq, err,
-					)
-				}
-				log.Error(noHostError)
-				return nil, fmt.Errorf(noHostError)
-			}
-		} else {
-			authOpts, err := svc.GetAuthenticationOptions()
-			if err != nil {
-				return nil, err
-			}
-			region, ok := authOpts.Get("Region")
-			if !ok {
-				return nil, fmt.Errorf("region value unset")
-			}
-			folder := fmt.Sprintf("images/%s/%s", svc.GetName(), region)
-
-			imageList, err := db.ReadAll(folder)
-			if err != nil {
-				if force {
-					log.Warnf(
-						"Problem creating / accessing Scanner database, ignoring GPU and Freq parameters for now...: %v",
-						err,
-					)
-				} else {
-					var noHostError string
-					if sizing.MinFreq <= 0 {
-						noHostError = fmt.Sprintf(
-							"Unable to create a host with '%d' GPUs, problem accessing Scanner database: %v",
-							sizing.MinGPU, err,
-						)
-					} else {
-						noHostError = fmt.Sprintf(
-							"Unable to create a host with '%d' GPUs and '%.01f' MHz clock frequency, problem accessing Scanner database: %v",
-							sizing.MinGPU, sizing.MinFreq, err,
-						)
-					}
-					log.Error(noHostError)
-					return nil, fmt.Errorf(noHostError)
-				}
-			} else {
-				var images []abstract.StoredCPUInfo
-				for _, f := range imageList {
-					imageFound := abstract.StoredCPUInfo{}
-					if err := json.Unmarshal([]byte(f), &imageFound); err != nil {
-						log.Error(fmt.Sprintf("error unmarsalling image %s : %v", f, err))
-					}
-
-					// if the user asked explicitly no gpu
-					if sizing.MinGPU == 0 && imageFound.GPU != 0 {
-						continue
-					}
-
-					if imageFound.GPU < sizing.MinGPU {
-						continue
-					}
-
-					if imageFound.CPUFrequency < float64(sizing.MinFreq) {
-						continue
-					}
-
-					images = append(images, imageFound)
-				}
-
-				if !force && (len(images) == 0) {
-					var noHostError string
-					if sizing.MinFreq <= 0 {
-						noHostError = fmt.Sprintf(
-							"Unable to create a host with '%d' GPUs, no images matching requirements", sizing.MinGPU,
-						)
-					} else {
-						noHostError = fmt.Sprintf(
-							"Unable to create a host with '%d' GPUs and a CPU clock frequencyof '%.01f MHz', no images matching requirements",
-							sizing.MinGPU, sizing.MinFreq,
-						)
-					}
-					log.Error(noHostError)
-					return nil, fmt.Errorf(noHostError)
-				}
-
-				for _, image := range images {
-					scannerTpls[image.TemplateID] = true
-				}
-			}
+			return nil, err
 		}
 	}
 
@@ -439,7 +576,7 @@ func (svc *service) SelectTemplatesBySize(sizing abstract.SizingRequirements, fo
 				ramMsg = fmt.Sprintf("at least %.01f", sizing.MinRAMSize)
 			}
 		} else {
-			coreMsg = fmt.Sprintf("at most %.01f", sizing.MaxRAMSize)
+			ramMsg = fmt.Sprintf("at most %.01f", sizing.MaxRAMSize)
 		}
 		diskMsg := ""
 		if sizing.MinDiskSize > 0 {
@@ -449,6 +586,100 @@ func (svc *service) SelectTemplatesBySize(sizing abstract.SizingRequirements, fo
 		log.Debugf(fmt.Sprintf("Looking for a host template with: %s cores, %s RAM%s", coreMsg, ramMsg, diskMsg))
 	}
 
+	return selectTemplatesBySizing(allTpls, sizing, scannerTpls)
+}
+
+// readScannerDB returns the records of the scanner database describing the templates of the region of the tenant
+func (svc *service) readScannerDB() ([]string, error) {
+	_ = os.MkdirAll(utils.AbsPathify("$HOME/.safescale/scanner"), 0777)
+	db, err := scribble.New(utils.AbsPathify("$HOME/.safescale/scanner/db"), nil)
+	if err != nil {
+		return nil, err
+	}
+	authOpts, err := svc.GetAuthenticationOptions()
+	if err != nil {
+		return nil, err
+	}
+	region, ok := authOpts.Get("Region")
+	if !ok {
+		return nil, fmt.Errorf("region value unset")
+	}
+	return db.ReadAll(fmt.Sprintf("images/%s/%s", svc.GetName(), region))
+}
+
+// scannerTemplates returns the IDs of the templates that the records of the scanner database (read with error dbErr)
+// tell to satisfy the GPU and CPU frequency requirements of 'sizing'
+// If the database is unavailable or empty, or no template satisfies the requirements, returns a fail.ErrNotFound if
+// strict is set; otherwise, the GPU and CPU frequency requirements are ignored with a warning and nil is returned, not
+// restricting the templates.
+func scannerTemplates(records []string, dbErr error, sizing abstract.SizingRequirements, strict bool) (map[string]bool, error) {
+	if dbErr == nil && len(records) == 0 {
+		dbErr = fmt.Errorf("no template scanned for the region")
+	}
+	if dbErr != nil {
+		if strict {
+			return nil, fail.NotFoundError(
+				fmt.Sprintf(
+					"scanner database required for GPU/CPU frequency constraints is unavailable or empty (%v); run the scanner on the tenant",
+					dbErr,
+				),
+			)
+		}
+		log.Warnf(
+			"Scanner database unavailable or empty (%v), IGNORING the GPU (%d) and CPU frequency (%.01f MHz) requirements",
+			dbErr, sizing.MinGPU, sizing.MinFreq,
+		)
+		return nil, nil
+	}
+
+	templates := map[string]bool{}
+	for _, r := range records {
+		imageFound := abstract.StoredCPUInfo{}
+		if err := json.Unmarshal([]byte(r), &imageFound); err != nil {
+			log.Error(fmt.Sprintf("error unmarsalling image %s : %v", r, err))
+			continue
+		}
+
+		// if the user asked explicitly no gpu
+		if sizing.MinGPU == 0 && imageFound.GPU != 0 {
+			continue
+		}
+		if imageFound.GPU < sizing.MinGPU {
+			continue
+		}
+		if imageFound.CPUFrequency < float64(sizing.MinFreq) {
+			continue
+		}
+		templates[imageFound.TemplateID] = true
+	}
+
+	if len(templates) == 0 {
+		var noHostError string
+		if sizing.MinFreq <= 0 {
+			noHostError = fmt.Sprintf(
+				"Unable to create a host with '%d' GPUs, no images matching requirements", sizing.MinGPU,
+			)
+		} else {
+			noHostError = fmt.Sprintf(
+				"Unable to create a host with '%d' GPUs and a CPU clock frequency of '%.01f MHz', no images matching requirements",
+				sizing.MinGPU, sizing.MinFreq,
+			)
+		}
+		if strict {
+			log.Error(noHostError)
+			return nil, fail.NotFoundError(noHostError)
+		}
+		log.Warnf("%s, IGNORING the GPU and CPU frequency requirements", noHostError)
+		return nil, nil
+	}
+	return templates, nil
+}
+
+// selectTemplatesBySizing returns the templates of 'allTpls' satisfying 'sizing', ordered by size fitting
+// If 'scannerTpls' is not nil, only the templates it contains (as known by the scanner database) are kept
+// Returns fail.ErrNotFound describing the requirements if no template matches
+func selectTemplatesBySizing(allTpls []abstract.HostTemplate, sizing abstract.SizingRequirements, scannerTpls map[string]bool) ([]*abstract.HostTemplate, error) {
+	var selectedTpls []*abstract.HostTemplate
 	for _, t := range allTpls {
 		msg := fmt.Sprintf(
 			"Discard machine template '%s' with : %d cores, %.01f GB of RAM, and %d GB of Disk:", t.Name, t.Cores,
@@ -456,38 +687,80 @@ func (svc *service) SelectTemplatesBySize(sizing abstract.SizingRequirements, fo
 		)
 		msg += " %s"
 		if sizing.MinCores > 0 && t.Cores < sizing.MinCores {
-			tracer.Trace(msg, "not enough cores")
+			log.Tracef(msg, "not enough cores")
 			continue
 		}
 		if sizing.MaxCores > 0 && t.Cores > sizing.MaxCores {
-			tracer.Trace(msg, "too many cores")
+			log.Tracef(msg, "too many cores")
 			continue
 		}
 		if sizing.MinRAMSize > 0.0 && t.RAMSize < sizing.MinRAMSize {
-			tracer.Trace(msg, "not enough RAM")
+			log.Tracef(msg, "not enough RAM")
 			continue
 		}
 		if sizing.MaxRAMSize > 0.0 && t.RAMSize > sizing.MaxRAMSize {
-			tracer.Trace(msg, "too many RAM")
+			log.Tracef(msg, "too many RAM")
 			continue
 		}
 		if t.DiskSize > 0 && sizing.MinDiskSize > 0 && t.DiskSize < sizing.MinDiskSize {
-			tracer.Trace(msg, "not enough disk")
+			log.Tracef(msg, "not enough disk")
 			continue
 		}
 
 		if t.ID != "" {
-			if _, ok := scannerTpls[t.ID]; ok || !askedForSpecificScannerInfo {
+			if _, ok := scannerTpls[t.ID]; ok || scannerTpls == nil {
 				newT := t
 				selectedTpls = append(selectedTpls, &newT)
 			}
 		}
 	}
 
+	if len(selectedTpls) == 0 {
+		return nil, noTemplateMatchingError(sizing, len(allTpls), scannerTpls != nil)
+	}
+
 	sort.Sort(ByRankDRF(selectedTpls))
 	return selectedTpls, nil
 }
 
+// noTemplateMatchingError builds the error returned when no template satisfies 'sizing'
+func noTemplateMatchingError(sizing abstract.SizingRequirements, count int, scannerUsed bool) error {
+	var constraints []string
+	if sizing.MinCores > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %d core%s", sizing.MinCores, utils.Plural(sizing.MinCores)))
+	}
+	if sizing.MaxCores > 0 {
+		constraints = append(constraints, fmt.Sprintf("at most %d core%s", sizing.MaxCores, utils.Plural(sizing.MaxCores)))
+	}
+	if sizing.MinRAMSize > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %.01f GB RAM", sizing.MinRAMSize))
+	}
+	if sizing.MaxRAMSize > 0 {
+		constraints = append(constraints, fmt.Sprintf("at most %.01f GB RAM", sizing.MaxRAMSize))
+	}
+	if sizing.MinDiskSize > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %d GB disk", sizing.MinDiskSize))
+	}
+	if sizing.MinGPU > 0 {
+		constraints = append(constraints, fmt.Sprintf("at least %d GPU%s", sizing.MinGPU, utils.Plural(sizing.MinGPU)))
+	}
+	if sizing.MinFreq > 0 {
+		constraints = append(constraints, fmt.Sprintf("a CPU frequency of at least %.01f GHz", sizing.MinFreq))
+	}
+	if len(constraints) == 0 {
+		constraints = append(constraints, "no constraint")
+	}
+
+	msg := fmt.Sprintf(
+		"failed to find a template matching requirements (%s) among %d template%s", strings.Join(constraints, ", "),
+		count, utils.Plural(count),
+	)
+	if scannerUsed {
+		msg += "; templates are restricted to those known by the scanner database, which may be empty or stale (run the scanner to refresh it)"
+	}
+	return fail.NotFoundError(msg)
+}
+
 type scoredImage struct {
 	abstract.Image
 	score float64
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iaas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/system"
+)
+
+func TestInitSSHConnectionOptions(t *testing.T) {
+	opts, err := initSSHConnectionOptions(map[string]interface{}{})
+	require.Nil(t, err)
+	assert.Equal(t, system.SSHConnectionOptions{}, opts)
+
+	opts, err = initSSHConnectionOptions(
+		map[string]interface{}{
+			"compute": map[string]interface{}{
+				"SSHServerAliveInterval": int64(15),
+				"SSHServerAliveCountMax": float64(8),
+				"SSHConnectTimeout":      "20",
+			},
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, system.SSHConnectionOptions{ServerAliveInterval: 15, ServerAliveCountMax: 8, ConnectTimeout: 20}, opts)
+
+	for _, v := range []interface{}{"soon", 1.5, -1, int64(7200), true} {
+		_, err = initSSHConnectionOptions(
+			map[string]interface{}{"compute": map[string]interface{}{"SSHServerAliveInterval": v}},
+		)
+		assert.NotNil(t, err, v)
+	}
+}
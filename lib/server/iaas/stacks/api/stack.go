@@ -34,6 +34,9 @@ type Stack interface {
 	// ListRegions returns a list with the regions available
 	ListRegions() ([]string, fail.Error)
 
+	// GetQuotas returns the limits and the current usage of the tenant resources
+	GetQuotas() (*abstract.Quotas, fail.Error)
+
 	// GetImage returns the Image referenced by id
 	GetImage(id string) (*abstract.Image, fail.Error)
 
@@ -59,6 +62,10 @@ type Stack interface {
 	ListNetworks() ([]*abstract.Network, fail.Error)
 	// DeleteNetwork deletes the network identified by id
 	DeleteNetwork(id string) fail.Error
+	// UpdateNetworkDNSServers replaces the DNS servers distributed by DHCP in the network identified by id
+	UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error
+	// SetNetworkRoutes replaces the static routes of the network identified by id with routes
+	SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error
 	// CreateGateway creates a public Gateway for a private network
 	CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (*abstract.Host, *userdata.Content, fail.Error)
 	// DeleteGateway delete the public gateway of a private network
@@ -95,6 +102,26 @@ type Stack interface {
 	RebootHost(id string) fail.Error
 	// Resize host
 	ResizeHost(id string, request abstract.SizingRequirements) (*abstract.Host, fail.Error)
+	// GetHostConsoleOutput returns the last 'lines' lines (all if 0) of the console output of the host identified by id
+	GetHostConsoleOutput(id string, lines int) (string, fail.Error)
+	// ListHostNetworkInterfaces returns the network interfaces of the host identified by id, as seen by the provider
+	ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error)
+	// CreateHostSnapshot creates the snapshot 'name' of the boot disk of the host identified by id, usable as source
+	// snapshot of a new host, and returns its ID as soon as the disk is captured (the snapshot may not be ready yet)
+	CreateHostSnapshot(id string, name string) (string, fail.Error)
+	// WaitHostSnapshotReady waits until the snapshot identified by snapshotID is ready to be used
+	WaitHostSnapshotReady(snapshotID string) fail.Error
+	// RenameHost renames the host identified by id on provider side
+	RenameHost(id string, newName string) fail.Error
+	// SetHostIPForwarding allows or forbids the host to forward traffic not addressed to it (router mode)
+	SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error
+	// BindSecurityGroupToHost binds the security group named sgName to the host identified by id
+	BindSecurityGroupToHost(id string, sgName string) fail.Error
+	// UnbindSecurityGroupFromHost unbinds the security group named sgName from the host identified by id
+	UnbindSecurityGroupFromHost(id string, sgName string) fail.Error
+	// AddRulesToSecurityGroup adds the rules to the security group named sgName; returns fail.ErrInvalidRequest if a
+	// rule cannot be honored by the provider (direction, ether type, stateless, ...)
+	AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error
 
 	// CreateVolume creates a block volume
 	CreateVolume(request abstract.VolumeRequest) (*abstract.Volume, fail.Error)
@@ -31,6 +31,11 @@ func (sp StackProxy) ListRegions() ([]string, fail.Error) {
 	return rv, errorTranslator(err)
 }
 
+func (sp StackProxy) GetQuotas() (*abstract.Quotas, fail.Error) {
+	rv, err := sp.InnerStack.GetQuotas()
+	return rv, errorTranslator(err)
+}
+
 func (sp StackProxy) GetImage(id string) (*abstract.Image, fail.Error) {
 	rv, err := sp.InnerStack.GetImage(id)
 	return rv, errorTranslator(err)
@@ -86,6 +91,16 @@ func (sp StackProxy) DeleteNetwork(id string) error {
 	return errorTranslator(err)
 }
 
+func (sp StackProxy) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	err := sp.InnerStack.UpdateNetworkDNSServers(id, dnsServers)
+	return errorTranslator(err)
+}
+
+func (sp StackProxy) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	err := sp.InnerStack.SetNetworkRoutes(id, routes)
+	return errorTranslator(err)
+}
+
 func (sp StackProxy) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (*abstract.Host, *userdata.Content, fail.Error) {
 	rv, rv2, err := sp.InnerStack.CreateGateway(req, sizing)
 	return rv, rv2, errorTranslator(err)
@@ -156,6 +171,51 @@ func (sp StackProxy) StopHost(id string) error {
 	return errorTranslator(err)
 }
 
+func (sp StackProxy) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	err := sp.InnerStack.SetHostIPForwarding(host, enabled)
+	return errorTranslator(err)
+}
+
+func (sp StackProxy) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	err := sp.InnerStack.BindSecurityGroupToHost(id, sgName)
+	return errorTranslator(err)
+}
+
+func (sp StackProxy) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	err := sp.InnerStack.UnbindSecurityGroupFromHost(id, sgName)
+	return errorTranslator(err)
+}
+
+func (sp StackProxy) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	err := sp.InnerStack.AddRulesToSecurityGroup(sgName, rules)
+	return errorTranslator(err)
+}
+
+func (sp StackProxy) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	rv, err := sp.InnerStack.GetHostConsoleOutput(id, lines)
+	return rv, errorTranslator(err)
+}
+
+func (sp StackProxy) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	rv, err := sp.InnerStack.ListHostNetworkInterfaces(id)
+	return rv, errorTranslator(err)
+}
+
+func (sp StackProxy) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	rv, err := sp.InnerStack.CreateHostSnapshot(id, name)
+	return rv, errorTranslator(err)
+}
+
+func (sp StackProxy) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	err := sp.InnerStack.WaitHostSnapshotReady(snapshotID)
+	return errorTranslator(err)
+}
+
+func (sp StackProxy) RenameHost(id string, newName string) fail.Error {
+	err := sp.InnerStack.RenameHost(id, newName)
+	return errorTranslator(err)
+}
+
 func (sp StackProxy) StartHost(id string) error {
 	err := sp.InnerStack.StartHost(id)
 	return errorTranslator(err)
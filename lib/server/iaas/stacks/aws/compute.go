@@ -20,6 +20,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,14 +31,18 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/pricing"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hoststate"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties"
 	propertiesv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -175,6 +181,11 @@ func (s *Stack) ListRegions() ([]string, fail.Error) {
 	return regions, nil
 }
 
+// GetQuotas returns the limits and usages of the tenant resources
+func (s *Stack) GetQuotas() (*abstract.Quotas, fail.Error) {
+	return nil, fail.NotImplementedError("GetQuotas() not implemented yet") // FIXME: Technical debt
+}
+
 func (s *Stack) GetImage(id string) (*abstract.Image, fail.Error) {
 	imagesList, err := s.ListImages()
 	if err != nil {
@@ -290,7 +301,17 @@ func createFilters() []*ec2.Filter {
 	return filters
 }
 
+// ListImages lists available OS images; the list is kept for the TTL of the catalog cache
 func (s *Stack) ListImages() ([]abstract.Image, fail.Error) {
+	return s.catalog.Images(s.listImages)
+}
+
+// RefreshCatalog forgets the images and templates kept in cache
+func (s *Stack) RefreshCatalog() {
+	s.catalog.Invalidate()
+}
+
+func (s *Stack) listImages() ([]abstract.Image, fail.Error) {
 	var images []abstract.Image
 
 	filters := []*ec2.Filter{
@@ -323,17 +344,24 @@ func (s *Stack) ListImages() ([]abstract.Image, fail.Error) {
 				}
 
 				nextImage := abstract.Image{
-					ID:          aws.StringValue(image.ImageId),
-					Name:        aws.StringValue(image.Name),
-					Description: aws.StringValue(image.Description),
-					StorageType: aws.StringValue(image.RootDeviceType),
-					DiskSize:    0,
+					ID:           aws.StringValue(image.ImageId),
+					Name:         aws.StringValue(image.Name),
+					Description:  aws.StringValue(image.Description),
+					StorageType:  aws.StringValue(image.RootDeviceType),
+					DiskSize:     0,
+					OSFamily:     abstract.GuessOSFamily(aws.StringValue(image.Name)),
+					Architecture: aws.StringValue(image.Architecture),
+				}
+				if nextImage.OSFamily == "" {
+					nextImage.OSFamily = abstract.GuessOSFamily(aws.StringValue(image.Description))
 				}
 
 				if len(image.BlockDeviceMappings) > 0 {
 					if image.BlockDeviceMappings[0].Ebs != nil {
 						if image.BlockDeviceMappings[0].Ebs.VolumeSize != nil {
 							nextImage.DiskSize = aws.Int64Value(image.BlockDeviceMappings[0].Ebs.VolumeSize)
+							// the volume created from the snapshot of the image cannot be smaller than the snapshot
+							nextImage.MinDiskGB = int(nextImage.DiskSize)
 						}
 					}
 				}
@@ -346,7 +374,12 @@ func (s *Stack) ListImages() ([]abstract.Image, fail.Error) {
 	return images, nil
 }
 
+// ListTemplates lists available host templates; the list is kept for the TTL of the catalog cache
 func (s *Stack) ListTemplates() ([]abstract.HostTemplate, fail.Error) {
+	return s.catalog.Templates(s.listTemplates)
+}
+
+func (s *Stack) listTemplates() ([]abstract.HostTemplate, fail.Error) {
 	var templates []abstract.HostTemplate
 
 	prods, err := s.PricingService.GetProducts(
@@ -473,6 +506,10 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	hostMustHavePublicIP := request.PublicIP
 	keyPairName := request.KeyPair.Name
 
+	if len(request.NICs) > 0 {
+		return nil, userData, fail.NotImplementedError("ordering or naming network interfaces at host creation is not implemented yet")
+	}
+
 	if networks == nil || len(networks) == 0 {
 		return nil, userData, fail.Errorf(
 			fmt.Sprintf(
@@ -561,24 +598,11 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		return nil, nil, err
 	}
 
-	if request.DiskSize > template.DiskSize {
-		template.DiskSize = request.DiskSize
-	}
-
-	if int(rim.DiskSize) > template.DiskSize {
-		template.DiskSize = int(rim.DiskSize)
-	}
-
-	if template.DiskSize == 0 {
-		// Determines appropriate disk size
-		if template.Cores < 16 { // nolint
-			template.DiskSize = 100
-		} else if template.Cores < 32 {
-			template.DiskSize = 200
-		} else {
-			template.DiskSize = 400
-		}
+	diskSize, err := stacks.SystemDiskSize(request, *template, rim.DiskSize, s.Config.SystemDiskSizes)
+	if err != nil {
+		return nil, userData, err
 	}
+	template.DiskSize = diskSize
 
 	logrus.Debugf("Selected template: '%s', '%s'", template.ID, template.Name)
 
@@ -635,6 +659,10 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	if err != nil {
 		return nil, userData, err
 	}
+	userDataPhase1, err = userdata.Fit(userDataPhase1, userdata.AWSMaxSize)
+	if err != nil {
+		return nil, userData, err
+	}
 
 	vpcnet, err := s.GetNetworkByName(s.AwsConfig.NetworkName)
 	if err != nil {
@@ -646,14 +674,29 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	logrus.Debugf("requesting host resource creation...")
 	var desistError error
 
+	// As long as no instance uses it, nothing else deletes the security group dedicated to the host: looks for it on
+	// failure, a partial failure (or a previous attempt) may have left it behind
+	defer func() {
+		if xerr != nil && !request.SkipDefaultSecurityGroup {
+			derr := deleteDedicatedSecurityGroup(s.EC2Service, vpcnet.ID, request.ResourceName)
+			if derr != nil {
+				logrus.Warnf("cleaning up on failure, failed to delete security group '%s': %v", request.ResourceName, derr)
+			}
+		}
+	}()
+
 	// Retry creation until success, for 10 minutes
 	err = retry.WhileUnsuccessfulDelay5Seconds(
 		func() error {
-
-			if ok, err := hasSecurityGroup(s.EC2Service, vpcnet.ID, request.ResourceName); err == nil {
+			// Without dedicated security group, the host only gets the default security group of the VPC,
+			// which always exists
+			sgName := request.ResourceName
+			if request.SkipDefaultSecurityGroup {
+				sgName = defaultVPCSecurityGroupName
+			} else if ok, err := hasSecurityGroup(s.EC2Service, vpcnet.ID, sgName); err == nil {
 				if !ok {
 					logrus.Debug("Security group not found")
-					err = createSecurityGroup(s.EC2Service, vpcnet.ID, request.ResourceName)
+					err = createSecurityGroup(s.EC2Service, vpcnet.ID, sgName)
 					if err != nil {
 						desistError = err
 						return nil
@@ -665,11 +708,32 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 				return nil
 			}
 
-			sgID, err := getSecurityGroupID(s.EC2Service, vpcnet.ID, request.ResourceName)
+			sgID, err := getSecurityGroupID(s.EC2Service, vpcnet.ID, sgName)
 			if err != nil {
 				desistError = err
 				return nil
 			}
+			// Security groups are bound to a VPC, looking for them in the VPC of the host checks they are usable
+			sgIDs := []string{sgID}
+			if s.Config.KeepProviderDefaultSecurityGroup {
+				id, err := getSecurityGroupID(s.EC2Service, vpcnet.ID, providerDefaultSecurityGroupName)
+				if err != nil {
+					desistError = err
+					return nil
+				}
+				sgIDs = append(sgIDs, id)
+			}
+			for _, name := range request.SecurityGroups {
+				if name == sgName {
+					continue
+				}
+				id, err := getSecurityGroupID(s.EC2Service, vpcnet.ID, name)
+				if err != nil {
+					desistError = err
+					return nil
+				}
+				sgIDs = append(sgIDs, id)
+			}
 
 			var server *abstract.Host
 
@@ -687,7 +751,7 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 
 				server, err = buildAwsSpotMachine(
 					s.EC2Service, keyPairName, request.ResourceName, rim.ID, s.AwsConfig.Zone, netID,
-					string(userDataPhase1), isGateway, template, sgID,
+					string(userDataPhase1), isGateway, template, sgIDs, request.MaxPrice,
 				)
 			} else {
 				netID := defaultNetwork.ID
@@ -701,7 +765,7 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 
 				server, err = buildAwsMachine(
 					s.EC2Service, keyPairName, request.ResourceName, rim.ID, s.AwsConfig.Zone, netID,
-					string(userDataPhase1), isGateway, template, sgID,
+					string(userDataPhase1), isGateway, template, sgIDs,
 				)
 			}
 			if err != nil {
@@ -799,6 +863,38 @@ func isAWSErr(err error) bool {
 	return false
 }
 
+// dedicatedSecurityGroupID returns the ID of the security group created for the instance (named after it), or an
+// empty string if the instance has none: the default security group of the VPC and the security groups bound at
+// creation are shared and must not be deleted with the instance.
+// Instances without Name tag (spot instances) fall back to their first security group.
+func dedicatedSecurityGroupID(inst *ec2.Instance) string {
+	if len(inst.SecurityGroups) == 0 {
+		return ""
+	}
+	name := ""
+	for _, tag := range inst.Tags {
+		if aws.StringValue(tag.Key) == "Name" {
+			name = aws.StringValue(tag.Value)
+		}
+	}
+	if name == "" {
+		name = aws.StringValue(inst.SecurityGroups[0].GroupName)
+	}
+	// The default security group of the VPC is shared and cannot be deleted
+	if name == defaultVPCSecurityGroupName {
+		return ""
+	}
+	for _, sg := range inst.SecurityGroups {
+		if aws.StringValue(sg.GroupName) == name {
+			return aws.StringValue(sg.GroupId)
+		}
+	}
+	return ""
+}
+
+// defaultVPCSecurityGroupName is the name of the security group AWS creates with each VPC
+const defaultVPCSecurityGroupName = "default"
+
 func hasSecurityGroup(EC2Service *ec2.EC2, vpcID string, name string) (bool, fail.Error) {
 	dgo, err := EC2Service.DescribeSecurityGroups(
 		&ec2.DescribeSecurityGroupsInput{
@@ -847,7 +943,41 @@ func getSecurityGroupID(EC2Service *ec2.EC2, vpcID string, name string) (string,
 	return "", fail.NotFoundError(fmt.Sprintf("Security group %s not found", name))
 }
 
-func createSecurityGroup(EC2Service *ec2.EC2, vpcID string, name string) error {
+// providerDefaultSecurityGroupName is the name of the security group created by AWS in each VPC, bound to the
+// instances launched without security group
+const providerDefaultSecurityGroupName = "default"
+
+// deleteDedicatedSecurityGroup deletes the security groups named 'name' in the VPC 'vpcID', if any
+func deleteDedicatedSecurityGroup(EC2Service ec2iface.EC2API, vpcID string, name string) error {
+	dgo, err := EC2Service.DescribeSecurityGroups(
+		&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{
+				&ec2.Filter{
+					Name:   aws.String("group-name"),
+					Values: []*string{aws.String(name)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	errs := map[string]error{}
+	for _, sg := range dgo.SecurityGroups {
+		if aws.StringValue(sg.VpcId) != vpcID {
+			continue
+		}
+		logrus.Debugf("deleting security group '%s' (%s)", name, aws.StringValue(sg.GroupId))
+		_, err = EC2Service.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: sg.GroupId})
+		if err != nil {
+			errs[fmt.Sprintf("security group %s (%s)", name, aws.StringValue(sg.GroupId))] = err
+		}
+	}
+	return fail.ErrListErrorWithContext(errs)
+}
+
+func createSecurityGroup(EC2Service ec2iface.EC2API, vpcID string, name string) (err error) {
 	logrus.Warnf("Creating security group for vpc %s with name %s", vpcID, name)
 
 	// Create the security group with the VPC, name and description.
@@ -874,6 +1004,17 @@ func createSecurityGroup(EC2Service *ec2.EC2, vpcID string, name string) error {
 		aws.StringValue(createRes.GroupId), vpcID,
 	)
 
+	// Starting from here, delete the security group if exiting with error, it would be left orphaned otherwise
+	defer func() {
+		if err != nil {
+			_, derr := EC2Service.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: createRes.GroupId})
+			if derr != nil {
+				logrus.Warnf("cleaning up on failure, failed to delete security group '%s': %v", name, derr)
+				err = fail.AddConsequence(err, derr)
+			}
+		}
+	}()
+
 	var ports []portDef
 
 	// Add common ports
@@ -909,43 +1050,37 @@ func createSecurityGroup(EC2Service *ec2.EC2, vpcID string, name string) error {
 	ports = append(ports, portDef{"udp", 4789, 4789})
 
 	// ping
-	ports = append(ports, portDef{"icmp", -1, -1})
+	ports = append(ports, portDef{"icmp", 0, 0})
 
-	var permissions []*ec2.IpPermission
+	rules := make([]abstract.SecurityGroupRule, 0, len(ports))
 	for _, item := range ports {
-		permissions = append(
-			permissions, (&ec2.IpPermission{}).
-				SetIpProtocol(item.protocol).
-				SetFromPort(item.fromPort).
-				SetToPort(item.toPort).
-				SetIpRanges(
-					[]*ec2.IpRange{
-						{CidrIp: aws.String("0.0.0.0/0")},
-					},
-				),
+		rules = append(
+			rules, abstract.SecurityGroupRule{
+				Direction: securitygroupruledirection.INGRESS,
+				EtherType: ipversion.IPv4,
+				Protocol:  item.protocol,
+				PortFrom:  int(item.fromPort),
+				PortTo:    int(item.toPort),
+				IPRanges:  []string{"0.0.0.0/0"},
+			},
 		)
 	}
 
 	// Add permissions to the security group
-	_, err = EC2Service.AuthorizeSecurityGroupIngress(
-		&ec2.AuthorizeSecurityGroupIngressInput{
-			GroupId:       createRes.GroupId,
-			IpPermissions: permissions,
-		},
-	)
+	err = authorizeSecurityGroupRules(EC2Service, aws.StringValue(createRes.GroupId), rules)
 	if err != nil {
-		return fail.Errorf(fmt.Sprintf("unable to set security group %q ingress, %v", name, err), err)
+		return fail.Errorf(fmt.Sprintf("unable to set security group %q rules, %v", name, err), err)
 	}
 
 	return nil
 }
 
-func buildAwsSpotMachine(EC2Service *ec2.EC2, keypairName string, name string, imageId string, zone string, netID string, data string, isGateway bool, template *abstract.HostTemplate, sgID string) (*abstract.Host, fail.Error) {
+func buildAwsSpotMachine(EC2Service *ec2.EC2, keypairName string, name string, imageId string, zone string, netID string, data string, isGateway bool, template *abstract.HostTemplate, sgIDs []string, maxPrice float64) (*abstract.Host, fail.Error) {
 	ni := &ec2.InstanceNetworkInterfaceSpecification{
 		DeviceIndex:              aws.Int64(int64(0)),
 		SubnetId:                 aws.String(netID),
 		AssociatePublicIpAddress: aws.Bool(isGateway),
-		Groups:                   []*string{aws.String(sgID)},
+		Groups:                   aws.StringSlice(sgIDs),
 	}
 
 	dspho, err := EC2Service.DescribeSpotPriceHistory(
@@ -961,6 +1096,10 @@ func buildAwsSpotMachine(EC2Service *ec2.EC2, keypairName string, name string, i
 
 	lastPrice := dspho.SpotPriceHistory[len(dspho.SpotPriceHistory)-1]
 	logrus.Warnf("Last price detected %s", aws.StringValue(lastPrice.SpotPrice))
+	spotPrice := lastPrice.SpotPrice // FIXME: Round up
+	if maxPrice > 0 {
+		spotPrice = aws.String(strconv.FormatFloat(maxPrice, 'f', -1, 64))
+	}
 
 	input := &ec2.RequestSpotInstancesInput{
 		InstanceCount: aws.Int64(1),
@@ -984,7 +1123,7 @@ func buildAwsSpotMachine(EC2Service *ec2.EC2, keypairName string, name string, i
 			},
 			UserData: aws.String(base64.StdEncoding.EncodeToString([]byte(data))),
 		},
-		SpotPrice: lastPrice.SpotPrice, // FIXME: Round up
+		SpotPrice: spotPrice,
 		Type:      aws.String("one-time"),
 	}
 
@@ -1013,14 +1152,14 @@ func buildAwsSpotMachine(EC2Service *ec2.EC2, keypairName string, name string, i
 	return &host, nil
 }
 
-func buildAwsMachine(EC2Service *ec2.EC2, keypairName string, name string, imageId string, zone string, netID string, data string, isGateway bool, template *abstract.HostTemplate, sgID string) (*abstract.Host, fail.Error) {
-	logrus.Warnf("Using %s as subnetwork, looking for group %s", netID, sgID)
+func buildAwsMachine(EC2Service *ec2.EC2, keypairName string, name string, imageId string, zone string, netID string, data string, isGateway bool, template *abstract.HostTemplate, sgIDs []string) (*abstract.Host, fail.Error) {
+	logrus.Warnf("Using %s as subnetwork, looking for groups %v", netID, sgIDs)
 
 	ni := &ec2.InstanceNetworkInterfaceSpecification{
 		DeviceIndex:              aws.Int64(int64(0)),
 		SubnetId:                 aws.String(netID),
 		AssociatePublicIpAddress: aws.Bool(isGateway),
-		Groups:                   []*string{aws.String(sgID)},
+		Groups:                   aws.StringSlice(sgIDs),
 	}
 
 	// Run instance
@@ -1142,6 +1281,7 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 
 	instanceName := ""
 	instanceType := ""
+	availabilityZone := ""
 
 	for _, r := range awsHost.Reservations {
 		for _, i := range r.Instances {
@@ -1149,6 +1289,9 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 			if err != nil {
 				return nil, err
 			}
+			if i.Placement != nil {
+				availabilityZone = aws.StringValue(i.Placement.AvailabilityZone)
+			}
 
 			for _, tag := range i.Tags {
 				if tag != nil {
@@ -1239,6 +1382,16 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 		return nil, fail.Errorf(fmt.Sprintf("failed to update hostproperty.SizingV1 : %s", err.Error()), err)
 	}
 
+	err = host.Properties.LockForWrite(hostproperty.DescriptionV1).ThenUse(
+		func(v data.Clonable) error {
+			v.(*propertiesv1.HostDescription).AvailabilityZone = availabilityZone
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to update hostproperty.DescriptionV1 : %s", err.Error()), err)
+	}
+
 	host.Name = instanceName
 
 	if !host.OK() {
@@ -1416,10 +1569,7 @@ func (s *Stack) DeleteHost(id string) error {
 
 				if inst != nil {
 					keyPairName = aws.StringValue(inst.KeyName)
-					if len(inst.SecurityGroups) > 0 {
-						sg := inst.SecurityGroups[0]
-						secGroupId = aws.StringValue(sg.GroupId)
-					}
+					secGroupId = dedicatedSecurityGroupID(inst)
 				}
 			}
 		}
@@ -1488,7 +1638,7 @@ func (s *Stack) DeleteHost(id string) error {
 			return fail.Wrap(err, "error deleting security group")
 		}
 	} else {
-		logrus.Warnf("security group %s for host %s not found", secGroupId, id)
+		logrus.Debugf("no dedicated security group to delete for host %s", id)
 	}
 
 	// Delete keypair
@@ -1519,7 +1669,7 @@ func (s *Stack) StopHost(id string) error {
 		return err
 	}
 
-	retryErr := retry.WhileUnsuccessful(
+	retryErr := retry.WhileUnsuccessfulWithJitter(
 		func() error {
 			hostTmp, err := s.InspectHost(id)
 			if err != nil {
@@ -1535,7 +1685,7 @@ func (s *Stack) StopHost(id string) error {
 			}
 			return nil
 		},
-		temporal.GetDefaultDelay(),
+		temporal.GetMinDelay(), temporal.GetDefaultDelay(),
 		temporal.GetHostCleanupTimeout(),
 	)
 	if retryErr != nil {
@@ -1562,7 +1712,7 @@ func (s *Stack) StartHost(id string) error {
 		return err
 	}
 
-	retryErr := retry.WhileUnsuccessful(
+	retryErr := retry.WhileUnsuccessfulWithJitter(
 		func() error {
 			hostTmp, err := s.InspectHost(id)
 			if err != nil {
@@ -1576,7 +1726,7 @@ func (s *Stack) StartHost(id string) error {
 			}
 			return nil
 		},
-		temporal.GetDefaultDelay(),
+		temporal.GetMinDelay(), temporal.GetDefaultDelay(),
 		temporal.GetHostCleanupTimeout(),
 	)
 	if retryErr != nil {
@@ -1593,6 +1743,127 @@ func (s *Stack) StartHost(id string) error {
 	return err
 }
 
+// SetHostIPForwarding disables (or enables back) the source/destination check of the host
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	_, err := s.EC2Service.ModifyInstanceAttribute(
+		&ec2.ModifyInstanceAttributeInput{
+			InstanceId:      aws.String(host.ID),
+			SourceDestCheck: &ec2.AttributeBooleanValue{Value: aws.Bool(!enabled)},
+		},
+	)
+	return err
+}
+
+// BindSecurityGroupToHost adds the security group named sgName to the ones of the instance identified by id
+func (s *Stack) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	return s.updateInstanceSecurityGroups(id, sgName, true)
+}
+
+// UnbindSecurityGroupFromHost removes the security group named sgName from the ones of the instance identified by id
+func (s *Stack) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	return s.updateInstanceSecurityGroups(id, sgName, false)
+}
+
+// updateInstanceSecurityGroups adds (or removes) the security group named sgName, looked up in the VPC of the
+// instance, to (or from) the security groups of the instance
+// AWS only allows to replace the whole list of the security groups of an instance.
+func (s *Stack) updateInstanceSecurityGroups(id string, sgName string, bind bool) fail.Error {
+	resp, err := s.EC2Service.DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(id)}})
+	if err != nil {
+		return err
+	}
+	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
+		return abstract.ResourceNotFoundError("host", id)
+	}
+	inst := resp.Reservations[0].Instances[0]
+
+	sgID, err := getSecurityGroupID(s.EC2Service, aws.StringValue(inst.VpcId), sgName)
+	if err != nil {
+		return err
+	}
+	var groups []*string
+	for _, sg := range inst.SecurityGroups {
+		if aws.StringValue(sg.GroupId) != sgID {
+			groups = append(groups, sg.GroupId)
+		}
+	}
+	if bind {
+		groups = append(groups, aws.String(sgID))
+	}
+	if len(groups) == 0 {
+		return fail.InvalidRequestError(
+			fmt.Sprintf("cannot unbind security group '%s' from host '%s': it is its last one", sgName, id),
+		)
+	}
+
+	_, err = s.EC2Service.ModifyInstanceAttribute(
+		&ec2.ModifyInstanceAttributeInput{
+			InstanceId: aws.String(id),
+			Groups:     groups,
+		},
+	)
+	return err
+}
+
+// ListHostNetworkInterfaces returns the network interfaces of the host identified by id, in the order of their
+// attachment
+func (s *Stack) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	resp, err := s.EC2Service.DescribeInstances(&ec2.DescribeInstancesInput{InstanceIds: []*string{aws.String(id)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []abstract.HostNetworkInterface
+	indexes := map[string]int64{}
+	for _, r := range resp.Reservations {
+		for _, i := range r.Instances {
+			for _, ni := range i.NetworkInterfaces {
+				nic := abstract.HostNetworkInterface{
+					Name:       aws.StringValue(ni.NetworkInterfaceId),
+					MACAddress: aws.StringValue(ni.MacAddress),
+					NetworkID:  aws.StringValue(ni.SubnetId),
+				}
+				for _, ip := range ni.PrivateIpAddresses {
+					nic.PrivateIPs = append(nic.PrivateIPs, aws.StringValue(ip.PrivateIpAddress))
+				}
+				if ni.Association != nil {
+					nic.PublicIP = aws.StringValue(ni.Association.PublicIp)
+				}
+				if ni.Attachment != nil {
+					indexes[nic.Name] = aws.Int64Value(ni.Attachment.DeviceIndex)
+				}
+				out = append(out, nic)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, abstract.ResourceNotFoundError("host", id)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return indexes[out[i].Name] < indexes[out[j].Name] })
+	return out, nil
+}
+
+// CreateHostSnapshot is not implemented for aws
+func (s *Stack) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	return "", fail.NotImplementedError("CreateHostSnapshot() not implemented for aws")
+}
+
+// WaitHostSnapshotReady is not implemented for aws
+func (s *Stack) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	return fail.NotImplementedError("WaitHostSnapshotReady() not implemented for aws")
+}
+
+// RenameHost renames the host identified by id
+// The security group dedicated to the host is named after it, and AWS does not allow to rename a security group
+func (s *Stack) RenameHost(id string, newName string) fail.Error {
+	return fail.NotImplementedError("RenameHost() not implemented for aws")
+}
+
+// GetHostConsoleOutput is not implemented for aws
+func (s *Stack) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	return "", fail.NotImplementedError("GetHostConsoleOutput() not implemented for aws")
+}
+
 func (s *Stack) RebootHost(id string) error {
 	_, err := s.EC2Service.RebootInstances(
 		&ec2.RebootInstancesInput{
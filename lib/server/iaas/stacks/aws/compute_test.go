@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// fakeEC2 keeps the security groups in memory; the calls not overridden panic
+type fakeEC2 struct {
+	ec2iface.EC2API
+	groups       []*ec2.SecurityGroup
+	failIngress  bool
+	deletedCount int
+	ingress      []*ec2.IpPermission
+	egress       []*ec2.IpPermission
+}
+
+func (f *fakeEC2) CreateSecurityGroup(in *ec2.CreateSecurityGroupInput) (*ec2.CreateSecurityGroupOutput, error) {
+	id := fmt.Sprintf("sg-%d", len(f.groups)+1)
+	f.groups = append(f.groups, &ec2.SecurityGroup{GroupId: aws.String(id), GroupName: in.GroupName, VpcId: in.VpcId})
+	return &ec2.CreateSecurityGroupOutput{GroupId: aws.String(id)}, nil
+}
+
+func (f *fakeEC2) AuthorizeSecurityGroupIngress(in *ec2.AuthorizeSecurityGroupIngressInput) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	if f.failIngress {
+		return nil, fmt.Errorf("injected failure")
+	}
+	f.ingress = append(f.ingress, in.IpPermissions...)
+	return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+}
+
+func (f *fakeEC2) AuthorizeSecurityGroupEgress(in *ec2.AuthorizeSecurityGroupEgressInput) (*ec2.AuthorizeSecurityGroupEgressOutput, error) {
+	f.egress = append(f.egress, in.IpPermissions...)
+	return &ec2.AuthorizeSecurityGroupEgressOutput{}, nil
+}
+
+func (f *fakeEC2) DescribeSecurityGroups(in *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	out := &ec2.DescribeSecurityGroupsOutput{}
+	name := aws.StringValue(in.Filters[0].Values[0])
+	for _, sg := range f.groups {
+		if aws.StringValue(sg.GroupName) == name {
+			out.SecurityGroups = append(out.SecurityGroups, sg)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeEC2) DeleteSecurityGroup(in *ec2.DeleteSecurityGroupInput) (*ec2.DeleteSecurityGroupOutput, error) {
+	for i, sg := range f.groups {
+		if aws.StringValue(sg.GroupId) == aws.StringValue(in.GroupId) {
+			f.groups = append(f.groups[:i], f.groups[i+1:]...)
+			f.deletedCount++
+			return &ec2.DeleteSecurityGroupOutput{}, nil
+		}
+	}
+	return nil, fmt.Errorf("security group %s not found", aws.StringValue(in.GroupId))
+}
+
+func TestCreateSecurityGroupLeavesNoOrphanOnFailure(t *testing.T) {
+	svc := &fakeEC2{failIngress: true}
+	err := createSecurityGroup(svc, "vpc-1", "host")
+	assert.NotNil(t, err)
+	assert.Empty(t, svc.groups)
+	assert.Equal(t, 1, svc.deletedCount)
+
+	svc = &fakeEC2{}
+	err = createSecurityGroup(svc, "vpc-1", "host")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(svc.groups))
+}
+
+func TestToIPPermission(t *testing.T) {
+	permission, err := toIPPermission(
+		abstract.SecurityGroupRule{
+			Direction: securitygroupruledirection.EGRESS,
+			EtherType: ipversion.IPv6,
+			Protocol:  "tcp",
+			PortFrom:  443,
+			IPRanges:  []string{"2001:db8::/32"},
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "tcp", aws.StringValue(permission.IpProtocol))
+	assert.Equal(t, int64(443), aws.Int64Value(permission.FromPort))
+	assert.Equal(t, int64(443), aws.Int64Value(permission.ToPort))
+	assert.Empty(t, permission.IpRanges)
+	assert.Equal(t, "2001:db8::/32", aws.StringValue(permission.Ipv6Ranges[0].CidrIpv6))
+
+	permission, err = toIPPermission(
+		abstract.SecurityGroupRule{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "-1", aws.StringValue(permission.IpProtocol))
+	assert.Equal(t, "0.0.0.0/0", aws.StringValue(permission.IpRanges[0].CidrIp))
+
+	_, err = toIPPermission(
+		abstract.SecurityGroupRule{
+			Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "udp", Stateless: true,
+		},
+	)
+	_, ok := err.(fail.ErrInvalidRequest)
+	assert.True(t, ok)
+}
+
+func TestAuthorizeSecurityGroupRulesSplitsDirections(t *testing.T) {
+	svc := &fakeEC2{}
+	rules := []abstract.SecurityGroupRule{
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "tcp", PortFrom: 22},
+		{Direction: securitygroupruledirection.EGRESS, EtherType: ipversion.IPv4, Protocol: "udp", PortFrom: 53},
+		{Direction: securitygroupruledirection.EGRESS, EtherType: ipversion.IPv4, Protocol: "icmp"},
+	}
+	err := authorizeSecurityGroupRules(svc, "sg-1", rules)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(svc.ingress))
+	assert.Equal(t, 2, len(svc.egress))
+	assert.Equal(t, int64(-1), aws.Int64Value(svc.egress[1].FromPort))
+}
+
+func TestDeleteDedicatedSecurityGroup(t *testing.T) {
+	svc := &fakeEC2{}
+	_, _ = svc.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{GroupName: aws.String("host"), VpcId: aws.String("vpc-1")})
+	_, _ = svc.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{GroupName: aws.String("host"), VpcId: aws.String("vpc-2")})
+	_, _ = svc.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{GroupName: aws.String("other"), VpcId: aws.String("vpc-1")})
+
+	err := deleteDedicatedSecurityGroup(svc, "vpc-1", "host")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(svc.groups))
+	for _, sg := range svc.groups {
+		assert.False(t, aws.StringValue(sg.GroupName) == "host" && aws.StringValue(sg.VpcId) == "vpc-1")
+	}
+
+	// Nothing left to delete is not an error
+	err = deleteDedicatedSecurityGroup(svc, "vpc-1", "host")
+	assert.Nil(t, err)
+}
@@ -771,3 +771,13 @@ func (s *Stack) CreateGateway(req abstract.GatewayRequest, sizing *abstract.Sizi
 func (s *Stack) DeleteGateway(ref string) error {
 	return s.DeleteHost(ref)
 }
+
+// UpdateNetworkDNSServers is not implemented for aws
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	return fail.NotImplementedError("UpdateNetworkDNSServers() not implemented for aws")
+}
+
+// SetNetworkRoutes is not implemented for aws
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	return fail.NotImplementedError("SetNetworkRoutes() not implemented for aws")
+}
@@ -16,8 +16,134 @@
 
 package aws
 
-import "github.com/CS-SI/SafeScale/lib/utils/fail"
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
 
 func (s *Stack) createSecurityGroup(vpcID string, name string) (string, fail.Error) {
 	return "", fail.NotImplementedError("createSecurityGroup() not implemented yet") // FIXME: Technical debt
 }
+
+// toIPPermission converts an abstract.SecurityGroupRule to the AWS permission allowing the same traffic
+// AWS security groups are stateful, stateless rules cannot be honored.
+func toIPPermission(rule abstract.SecurityGroupRule) (*ec2.IpPermission, fail.Error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if rule.Stateless {
+		return nil, fail.InvalidRequestError("stateless security group rules are not supported by AWS")
+	}
+
+	permission := &ec2.IpPermission{}
+	switch rule.Protocol {
+	case "":
+		permission.SetIpProtocol("-1")
+	case "icmp":
+		permission.SetIpProtocol("icmp").SetFromPort(-1).SetToPort(-1)
+	default:
+		from, to := rule.Ports()
+		if from == 0 {
+			from, to = 0, 65535
+		}
+		permission.SetIpProtocol(rule.Protocol).SetFromPort(int64(from)).SetToPort(int64(to))
+	}
+
+	ranges := rule.IPRanges
+	if rule.EtherType == ipversion.IPv6 {
+		if len(ranges) == 0 {
+			ranges = []string{"::/0"}
+		}
+		for _, cidr := range ranges {
+			permission.Ipv6Ranges = append(
+				permission.Ipv6Ranges, &ec2.Ipv6Range{CidrIpv6: aws.String(cidr), Description: descriptionOf(rule)},
+			)
+		}
+		return permission, nil
+	}
+	if len(ranges) == 0 {
+		ranges = []string{"0.0.0.0/0"}
+	}
+	for _, cidr := range ranges {
+		permission.IpRanges = append(
+			permission.IpRanges, &ec2.IpRange{CidrIp: aws.String(cidr), Description: descriptionOf(rule)},
+		)
+	}
+	return permission, nil
+}
+
+// descriptionOf returns the description of the rule, nil if it has none (AWS refuses empty descriptions)
+func descriptionOf(rule abstract.SecurityGroupRule) *string {
+	if rule.Description == "" {
+		return nil
+	}
+	return aws.String(rule.Description)
+}
+
+// authorizeSecurityGroupRules adds the rules to the security group identified by groupID
+func authorizeSecurityGroupRules(EC2Service ec2iface.EC2API, groupID string, rules []abstract.SecurityGroupRule) error {
+	var ingress, egress []*ec2.IpPermission
+	for _, rule := range rules {
+		permission, err := toIPPermission(rule)
+		if err != nil {
+			return err
+		}
+		if rule.Direction == securitygroupruledirection.EGRESS {
+			egress = append(egress, permission)
+		} else {
+			ingress = append(ingress, permission)
+		}
+	}
+
+	if len(ingress) > 0 {
+		_, err := EC2Service.AuthorizeSecurityGroupIngress(
+			&ec2.AuthorizeSecurityGroupIngressInput{GroupId: aws.String(groupID), IpPermissions: ingress},
+		)
+		if err != nil {
+			return fail.Errorf(fmt.Sprintf("unable to set security group '%s' ingress: %v", groupID, err), err)
+		}
+	}
+	if len(egress) > 0 {
+		_, err := EC2Service.AuthorizeSecurityGroupEgress(
+			&ec2.AuthorizeSecurityGroupEgressInput{GroupId: aws.String(groupID), IpPermissions: egress},
+		)
+		if err != nil {
+			return fail.Errorf(fmt.Sprintf("unable to set security group '%s' egress: %v", groupID, err), err)
+		}
+	}
+	return nil
+}
+
+// AddRulesToSecurityGroup adds the rules to the security group named sgName
+// The name has to identify a single security group, whatever its VPC.
+func (s *Stack) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	dgo, err := s.EC2Service.DescribeSecurityGroups(
+		&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("group-name"),
+					Values: []*string{aws.String(sgName)},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	switch len(dgo.SecurityGroups) {
+	case 0:
+		return abstract.ResourceNotFoundError("security group", sgName)
+	case 1:
+		return authorizeSecurityGroupRules(s.EC2Service, aws.StringValue(dgo.SecurityGroups[0].GroupId), rules)
+	default:
+		return fail.InvalidRequestError(fmt.Sprintf("several security groups named '%s' found", sgName))
+	}
+}
@@ -39,6 +39,8 @@ type Stack struct {
 	EC2Service     *ec2.EC2
 	SSMService     *ssm.SSM
 	PricingService *pricing.Pricing
+
+	catalog *stacks.CatalogCache
 }
 
 func (s *Stack) GetConfigurationOptions() stacks.ConfigurationOptions {
@@ -55,6 +57,7 @@ func New(auth stacks.AuthenticationOptions, localCfg stacks.AWSConfiguration, cf
 		Config:      &cfg,
 		AuthOptions: &auth,
 		AwsConfig:   &localCfg,
+		catalog:     stacks.NewCatalogCache(cfg.CatalogCacheTTL),
 	}
 
 	accessKeyID := auth.AccessKeyID
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stacks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// DefaultCatalogCacheTTL is the time the images and templates listed by a stack are kept, when not configured
+const DefaultCatalogCacheTTL = 5 * time.Minute
+
+// ParseCatalogCacheTTL reads the value of the option 'CatalogCacheTTL' of the tenant (a duration like "10m"); a nil
+// value gives DefaultCatalogCacheTTL, "0" disables the cache
+func ParseCatalogCacheTTL(value interface{}) (time.Duration, fail.Error) {
+	if value == nil {
+		return DefaultCatalogCacheTTL, nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return 0, fail.InvalidParameterError("CatalogCacheTTL", "must be a duration string (like '10m')")
+	}
+	ttl, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fail.InvalidParameterError("CatalogCacheTTL", fmt.Sprintf("invalid duration '%s': %v", str, err))
+	}
+	if ttl < 0 {
+		return 0, fail.InvalidParameterError("CatalogCacheTTL", "cannot be negative")
+	}
+	return ttl, nil
+}
+
+// CatalogCache keeps the images and the templates listed by a stack for a while, so the bulk creation of hosts
+// doesn't list them again and again
+// The concurrent listings of the same kind wait for the one in progress instead of querying the provider too.
+// A nil *CatalogCache is valid and caches nothing
+type CatalogCache struct {
+	ttl       time.Duration
+	images    catalogEntry
+	templates catalogEntry
+}
+
+type catalogEntry struct {
+	lock    sync.Mutex
+	content interface{}
+	expiry  time.Time
+}
+
+// NewCatalogCache creates a CatalogCache keeping the lists for 'ttl'; returns nil (no cache) if ttl is 0
+func NewCatalogCache(ttl time.Duration) *CatalogCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &CatalogCache{ttl: ttl}
+}
+
+// get returns the content of the entry, refreshed with 'load' if expired
+// Errors and empty lists are not kept, the next call will call 'load' again
+func (c *CatalogCache) get(e *catalogEntry, load func() (interface{}, int, fail.Error)) (interface{}, fail.Error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.content != nil && time.Now().Before(e.expiry) {
+		return e.content, nil
+	}
+	content, count, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		e.content = content
+		e.expiry = time.Now().Add(c.ttl)
+	}
+	return content, nil
+}
+
+// Images returns the images listed by 'load' at most TTL ago
+func (c *CatalogCache) Images(load func() ([]abstract.Image, fail.Error)) ([]abstract.Image, fail.Error) {
+	if c == nil {
+		return load()
+	}
+	content, err := c.get(
+		&c.images, func() (interface{}, int, fail.Error) {
+			list, err := load()
+			return list, len(list), err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	// Returns a copy, the callers may change the content of the list
+	list := content.([]abstract.Image)
+	return append(make([]abstract.Image, 0, len(list)), list...), nil
+}
+
+// Templates returns the templates listed by 'load' at most TTL ago
+func (c *CatalogCache) Templates(load func() ([]abstract.HostTemplate, fail.Error)) ([]abstract.HostTemplate, fail.Error) {
+	if c == nil {
+		return load()
+	}
+	content, err := c.get(
+		&c.templates, func() (interface{}, int, fail.Error) {
+			list, err := load()
+			return list, len(list), err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	list := content.([]abstract.HostTemplate)
+	return append(make([]abstract.HostTemplate, 0, len(list)), list...), nil
+}
+
+// Invalidate forgets the lists, the next calls query the provider
+func (c *CatalogCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	for _, e := range []*catalogEntry{&c.images, &c.templates} {
+		e.lock.Lock()
+		e.content = nil
+		e.lock.Unlock()
+	}
+}
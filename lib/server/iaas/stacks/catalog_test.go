@@ -0,0 +1,124 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stacks
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestCatalogCacheTemplates(t *testing.T) {
+	var loads int32
+	failing := true
+	load := func() ([]abstract.HostTemplate, fail.Error) {
+		atomic.AddInt32(&loads, 1)
+		if failing {
+			return nil, fmt.Errorf("injected failure")
+		}
+		time.Sleep(10 * time.Millisecond)
+		return []abstract.HostTemplate{{ID: "1", Name: "small"}}, nil
+	}
+	cache := NewCatalogCache(50 * time.Millisecond)
+
+	// Errors are not kept
+	_, err := cache.Templates(load)
+	assert.NotNil(t, err)
+	failing = false
+
+	// Concurrent listings wait for the one in progress
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			list, err := cache.Templates(load)
+			assert.Nil(t, err)
+			assert.Len(t, list, 1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	// The callers get a copy
+	list, err := cache.Templates(load)
+	require.Nil(t, err)
+	list[0].Name = "changed"
+	list, _ = cache.Templates(load)
+	assert.Equal(t, "small", list[0].Name)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	time.Sleep(60 * time.Millisecond)
+	_, _ = cache.Templates(load)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&loads))
+
+	cache.Invalidate()
+	_, _ = cache.Templates(load)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&loads))
+}
+
+func TestCatalogCacheImages(t *testing.T) {
+	loads := 0
+	var images []abstract.Image
+	load := func() ([]abstract.Image, fail.Error) {
+		loads++
+		return images, nil
+	}
+
+	// Without cache, each listing queries the provider
+	var disabled *CatalogCache
+	_, _ = disabled.Images(load)
+	_, _ = disabled.Images(load)
+	disabled.Invalidate()
+	assert.Equal(t, 2, loads)
+
+	// Empty lists are not kept
+	cache := NewCatalogCache(time.Hour)
+	_, _ = cache.Images(load)
+	images = []abstract.Image{{ID: "1", Name: "ubuntu"}}
+	list, _ := cache.Images(load)
+	assert.Len(t, list, 1)
+	_, _ = cache.Images(load)
+	assert.Equal(t, 4, loads)
+}
+
+func TestParseCatalogCacheTTL(t *testing.T) {
+	ttl, err := ParseCatalogCacheTTL(nil)
+	require.Nil(t, err)
+	assert.Equal(t, DefaultCatalogCacheTTL, ttl)
+
+	ttl, err = ParseCatalogCacheTTL("10m")
+	require.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, ttl)
+
+	ttl, err = ParseCatalogCacheTTL("0")
+	require.Nil(t, err)
+	assert.Nil(t, NewCatalogCache(ttl))
+
+	for _, v := range []interface{}{"soon", "-1m", 10} {
+		_, err = ParseCatalogCacheTTL(v)
+		assert.NotNil(t, err, v)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stacks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// SystemDiskSizeTier gives the size of the system disk of the hosts having at least MinCores cores
+type SystemDiskSizeTier struct {
+	MinCores int
+	Size     int // in GB
+}
+
+// DefaultSystemDiskSizes are the sizes of the system disk used when not configured: 100 GB under 16 cores, 200 GB
+// under 32 cores, 400 GB beyond
+var DefaultSystemDiskSizes = []SystemDiskSizeTier{
+	{MinCores: 0, Size: 100},
+	{MinCores: 16, Size: 200},
+	{MinCores: 32, Size: 400},
+}
+
+// ParseSystemDiskSizes reads the value of the option 'SystemDiskSizes' of the tenant, a table giving the size in GB of
+// the system disk by minimum number of cores (like { 0 = 50, 8 = 100 }); a nil value gives nil, meaning
+// DefaultSystemDiskSizes
+func ParseSystemDiskSizes(value interface{}) ([]SystemDiskSizeTier, fail.Error) {
+	if value == nil {
+		return nil, nil
+	}
+	table, ok := value.(map[string]interface{})
+	if !ok || len(table) == 0 {
+		return nil, fail.InvalidParameterError(
+			"SystemDiskSizes", "must be a table of disk sizes in GB by minimum number of cores",
+		)
+	}
+	tiers := make([]SystemDiskSizeTier, 0, len(table))
+	for k, v := range table {
+		cores, err := strconv.Atoi(k)
+		if err != nil || cores < 0 {
+			return nil, fail.InvalidParameterError("SystemDiskSizes", fmt.Sprintf("invalid number of cores '%s'", k))
+		}
+		var size int
+		switch v := v.(type) {
+		case int:
+			size = v
+		case int64:
+			size = int(v)
+		case float64:
+			size = int(v)
+		default:
+			return nil, fail.InvalidParameterError(
+				"SystemDiskSizes", fmt.Sprintf("size for %d cores must be a number of GB", cores),
+			)
+		}
+		if size <= 0 {
+			return nil, fail.InvalidParameterError(
+				"SystemDiskSizes", fmt.Sprintf("size for %d cores must be positive", cores),
+			)
+		}
+		tiers = append(tiers, SystemDiskSizeTier{MinCores: cores, Size: size})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinCores < tiers[j].MinCores })
+	return tiers, nil
+}
+
+// SystemDiskSize returns the size in GB of the system disk of the host created by 'request' with 'template', from an
+// image (or a snapshot) of 'imageDiskSize' GB:
+// - request.SystemDiskSize if set, that has to hold the image;
+// - otherwise the largest of request.DiskSize, template.DiskSize and imageDiskSize;
+// - if none is known, the size given by 'tiers' (DefaultSystemDiskSizes if empty) for the cores of the template,
+// the tier with the lowest number of cores applying also to the hosts having less cores.
+func SystemDiskSize(request abstract.HostRequest, template abstract.HostTemplate, imageDiskSize int64, tiers []SystemDiskSizeTier) (int, fail.Error) {
+	if request.SystemDiskSize > 0 {
+		if int64(request.SystemDiskSize) < imageDiskSize {
+			return 0, fail.InvalidRequestError(
+				fmt.Sprintf(
+					"system disk of %d GB requested, but the image needs at least %d GB",
+					request.SystemDiskSize, imageDiskSize,
+				),
+			)
+		}
+		return request.SystemDiskSize, nil
+	}
+
+	size := template.DiskSize
+	if request.DiskSize > size {
+		size = request.DiskSize
+	}
+	if int(imageDiskSize) > size {
+		size = int(imageDiskSize)
+	}
+	if size > 0 {
+		return size, nil
+	}
+
+	if len(tiers) == 0 {
+		tiers = DefaultSystemDiskSizes
+	}
+	size = tiers[0].Size
+	for _, t := range tiers[1:] {
+		if template.Cores < t.MinCores {
+			break
+		}
+		size = t.Size
+	}
+	return size, nil
+}
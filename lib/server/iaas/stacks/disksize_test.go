@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package stacks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestSystemDiskSizeDefaultTiers(t *testing.T) {
+	for cores, expected := range map[int]int{1: 100, 15: 100, 16: 200, 31: 200, 32: 400, 96: 400} {
+		size, err := SystemDiskSize(abstract.HostRequest{}, abstract.HostTemplate{Cores: cores}, 0, nil)
+		require.Nil(t, err)
+		assert.Equal(t, expected, size, "%d cores", cores)
+	}
+}
+
+func TestSystemDiskSizeConfiguredTiers(t *testing.T) {
+	tiers := []SystemDiskSizeTier{{MinCores: 4, Size: 30}, {MinCores: 8, Size: 60}}
+	for cores, expected := range map[int]int{1: 30, 4: 30, 7: 30, 8: 60, 64: 60} {
+		size, err := SystemDiskSize(abstract.HostRequest{}, abstract.HostTemplate{Cores: cores}, 0, tiers)
+		require.Nil(t, err)
+		assert.Equal(t, expected, size, "%d cores", cores)
+	}
+}
+
+func TestSystemDiskSizeKnownSizes(t *testing.T) {
+	template := abstract.HostTemplate{Cores: 64, DiskSize: 20}
+
+	// The largest of template, request and image wins over the tiers
+	size, err := SystemDiskSize(abstract.HostRequest{}, template, 10, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 20, size)
+
+	size, err = SystemDiskSize(abstract.HostRequest{DiskSize: 50}, template, 10, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 50, size)
+
+	size, err = SystemDiskSize(abstract.HostRequest{DiskSize: 50}, template, 80, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 80, size)
+}
+
+func TestSystemDiskSizeOverride(t *testing.T) {
+	template := abstract.HostTemplate{Cores: 64, DiskSize: 200}
+
+	// The explicit size wins, even smaller than the template and the requested minimum
+	size, err := SystemDiskSize(abstract.HostRequest{SystemDiskSize: 30, DiskSize: 100}, template, 10, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 30, size)
+
+	size, err = SystemDiskSize(abstract.HostRequest{SystemDiskSize: 30}, abstract.HostTemplate{Cores: 64}, 0, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 30, size)
+
+	// ... but has to hold the image
+	_, err = SystemDiskSize(abstract.HostRequest{SystemDiskSize: 8}, template, 10, nil)
+	require.NotNil(t, err)
+	_, ok := err.(fail.ErrInvalidRequest)
+	assert.True(t, ok)
+}
+
+func TestParseSystemDiskSizes(t *testing.T) {
+	tiers, err := ParseSystemDiskSizes(nil)
+	require.Nil(t, err)
+	assert.Nil(t, tiers)
+
+	tiers, err = ParseSystemDiskSizes(map[string]interface{}{"8": int64(60), "0": int64(30), "32": 120.0})
+	require.Nil(t, err)
+	assert.Equal(t, []SystemDiskSizeTier{{MinCores: 0, Size: 30}, {MinCores: 8, Size: 60}, {MinCores: 32, Size: 120}}, tiers)
+
+	invalid := []interface{}{
+		"100",
+		map[string]interface{}{},
+		map[string]interface{}{"many": int64(10)},
+		map[string]interface{}{"-1": int64(10)},
+		map[string]interface{}{"0": "10"},
+		map[string]interface{}{"0": int64(0)},
+	}
+	for _, v := range invalid {
+		_, err = ParseSystemDiskSizes(v)
+		assert.NotNil(t, err, "%v", v)
+	}
+}
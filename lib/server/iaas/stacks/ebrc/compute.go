@@ -75,7 +75,7 @@ func (s *StackEbrc) ListImages(all bool) ([]abstract.Image, fail.Error) {
 			}
 			for _, item := range cat.Catalog.CatalogItems {
 				for _, deepItem := range item.CatalogItem {
-					empty = append(empty, abstract.Image{ID: deepItem.ID, Name: deepItem.Name})
+					empty = append(empty, abstract.Image{ID: deepItem.ID, Name: deepItem.Name, OSFamily: abstract.GuessOSFamily(deepItem.Name)})
 				}
 			}
 		}
@@ -112,6 +112,9 @@ func (s *StackEbrc) ListTemplates(all bool) ([]abstract.HostTemplate, fail.Error
 	return empty, nil
 }
 
+// RefreshCatalog does nothing, the templates and images are not kept in cache
+func (s *StackEbrc) RefreshCatalog() {}
+
 // ListTemplates overload OpenStackEbrc ListTemplate method to filter wind and flex instance and add GPU configuration
 func (s *StackEbrc) ListTemplatesSpecial(all bool) ([]abstract.HostTemplate, fail.Error) {
 	logrus.Debug(">>> stacks.ebrc::ListTemplates()")
@@ -240,6 +243,10 @@ func (s *StackEbrc) CreateHost(request abstract.HostRequest) (host *abstract.Hos
 	defaultGateway := request.DefaultGateway
 	keyPair := request.KeyPair
 
+	if len(request.NICs) > 0 {
+		return nil, userData, fail.NotImplementedError("ordering or naming network interfaces at host creation is not implemented yet")
+	}
+
 	if networks == nil || len(networks) == 0 {
 		return nil, userData, fail.Errorf(
 			fmt.Sprintf(
@@ -877,6 +884,31 @@ func (s *StackEbrc) StartHost(id string) error {
 	return err
 }
 
+// ListHostNetworkInterfaces is not implemented for ebrc
+func (s *StackEbrc) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	return nil, fail.NotImplementedError("ListHostNetworkInterfaces() not implemented for ebrc")
+}
+
+// CreateHostSnapshot is not implemented for ebrc
+func (s *StackEbrc) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	return "", fail.NotImplementedError("CreateHostSnapshot() not implemented for ebrc")
+}
+
+// WaitHostSnapshotReady is not implemented for ebrc
+func (s *StackEbrc) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	return fail.NotImplementedError("WaitHostSnapshotReady() not implemented for ebrc")
+}
+
+// RenameHost renames the host identified by id
+func (s *StackEbrc) RenameHost(id string, newName string) fail.Error {
+	return fail.NotImplementedError("RenameHost() not implemented for ebrc")
+}
+
+// GetHostConsoleOutput is not implemented for ebrc
+func (s *StackEbrc) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	return "", fail.NotImplementedError("GetHostConsoleOutput() not implemented for ebrc")
+}
+
 // RebootHost reboot the host identified by id
 func (s *StackEbrc) RebootHost(id string) error {
 	logrus.Debug("ebrc.Client.RebootHost() called")
@@ -925,6 +957,10 @@ func (s *StackEbrc) ListRegions() ([]string, fail.Error) {
 	return nil, fail.NotImplementedError("ListRegions() not implemented yet") // FIXME: Technical debt
 }
 
+func (s *StackEbrc) GetQuotas() (*abstract.Quotas, fail.Error) {
+	return nil, fail.NotImplementedError("GetQuotas() not implemented yet") // FIXME: Technical debt
+}
+
 func (s *StackEbrc) CreateVIP(s1 string, s2 string) (*abstract.VirtualIP, fail.Error) {
 	return nil, fail.NotImplementedError("CreateVIP() not implemented yet") // FIXME: Technical debt
 }
@@ -944,3 +980,23 @@ func (s *StackEbrc) UnbindHostFromVIP(ip *abstract.VirtualIP, s2 string) error {
 func (s *StackEbrc) DeleteVIP(ip *abstract.VirtualIP) error {
 	return fail.NotImplementedError("DeleteVIP() not implemented yet") // FIXME: Technical debt
 }
+
+// SetHostIPForwarding is not implemented for ebrc
+func (s *StackEbrc) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	return fail.NotImplementedError("SetHostIPForwarding() not implemented for ebrc")
+}
+
+// BindSecurityGroupToHost is not implemented for ebrc
+func (s *StackEbrc) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("BindSecurityGroupToHost() not implemented for ebrc")
+}
+
+// UnbindSecurityGroupFromHost is not implemented for ebrc
+func (s *StackEbrc) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("UnbindSecurityGroupFromHost() not implemented for ebrc")
+}
+
+// AddRulesToSecurityGroup is not implemented for ebrc
+func (s *StackEbrc) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	return fail.NotImplementedError("AddRulesToSecurityGroup() not implemented for ebrc")
+}
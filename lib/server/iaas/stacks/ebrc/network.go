@@ -781,3 +781,13 @@ func (s *StackEbrc) CreateGateway(req abstract.GatewayRequest, sizing *abstract.
 func (s *StackEbrc) DeleteGateway(ref string) error {
 	return s.DeleteHost(ref)
 }
+
+// UpdateNetworkDNSServers is not implemented for ebrc
+func (s *StackEbrc) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	return fail.NotImplementedError("UpdateNetworkDNSServers() not implemented for ebrc")
+}
+
+// SetNetworkRoutes is not implemented for ebrc
+func (s *StackEbrc) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	return fail.NotImplementedError("SetNetworkRoutes() not implemented for ebrc")
+}
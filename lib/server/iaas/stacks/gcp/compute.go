@@ -18,7 +18,10 @@ package gcp
 
 import (
 	"fmt"
+	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/CS-SI/SafeScale/lib/utils/debug"
@@ -34,6 +37,7 @@ import (
 	converters "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -41,10 +45,42 @@ import (
 	"github.com/CS-SI/SafeScale/lib/utils/temporal"
 )
 
+// maxListPages is the maximum number of pages read by a paginated listing
+const maxListPages = 1000
+
+// nextPageToken returns the token of the page to read after page, and false if pagination has to stop:
+// when there is no next page, when the service returns the same token again or after maxListPages pages
+func nextPageToken(current, next string, page int) (string, bool) {
+	if next == "" {
+		return "", false
+	}
+	if next == current {
+		logrus.Warnf("pagination stopped: page token '%s' returned twice", next)
+		return "", false
+	}
+	if page >= maxListPages {
+		logrus.Warnf("pagination stopped after %d pages", page)
+		return "", false
+	}
+	return next, true
+}
+
 // -------------IMAGES---------------------------------------------------------------------------------------------------
 
-// ListImages lists available OS images
-func (s *Stack) ListImages() (images []abstract.Image, xerr fail.Error) {
+// ListImages lists available OS images; the list is kept for the TTL of the catalog cache
+func (s *Stack) ListImages() ([]abstract.Image, fail.Error) {
+	return s.catalog.Images(s.listImages)
+}
+
+// RefreshCatalog forgets the images and templates kept in cache
+func (s *Stack) RefreshCatalog() {
+	s.catalog.Invalidate()
+}
+
+// listImages queries the images of the public projects
+// A project whose images cannot be listed is skipped; an error is returned only if the images of none of the projects
+// can be listed, an empty list without error meaning there is no image.
+func (s *Stack) listImages() (images []abstract.Image, xerr fail.Error) {
 	compuService := s.ComputeService
 
 	images = []abstract.Image{}
@@ -54,32 +90,56 @@ func (s *Stack) ListImages() (images []abstract.Image, xerr fail.Error) {
 		"suse-sap-cloud",
 	}
 
+	errs := map[string]error{}
 	for _, family := range families {
 		token := ""
-		for paginate := true; paginate; {
+		for page, paginate := 1, true; paginate; page++ {
 			resp, err := compuService.Images.List(family).Filter("deprecated.replacement ne .*images.*").PageToken(token).Do()
 			if err != nil {
-				logrus.Warnf("Can't list public images for project %q", family)
+				logrus.Warnf("Can't list public images for project %q: %v", family, err)
+				errs["project "+family] = err
 				break
 			}
 
 			for _, image := range resp.Items {
+				osFamily := abstract.GuessOSFamily(image.Family)
+				if osFamily == "" {
+					osFamily = abstract.GuessOSFamily(image.Name)
+				}
 				images = append(
-					images, abstract.Image{Name: image.Name, URL: image.SelfLink, ID: strconv.FormatUint(image.Id, 10), DiskSize: image.DiskSizeGb},
+					images, abstract.Image{
+						Name:      image.Name,
+						URL:       image.SelfLink,
+						ID:        strconv.FormatUint(image.Id, 10),
+						DiskSize:  image.DiskSizeGb,
+						OSFamily:  osFamily,
+						MinDiskGB: int(image.DiskSizeGb),
+
+						ConfidentialComputeCapable: hasGuestOSFeature(image, "SEV_CAPABLE"),
+					},
 				)
 			}
-			token := resp.NextPageToken
-			paginate = token != ""
+			token, paginate = nextPageToken(token, resp.NextPageToken, page)
 		}
 	}
 
-	if len(images) == 0 {
-		return images, xerr
+	if len(errs) == len(families) {
+		return nil, fail.Wrap(fail.ErrListErrorWithContext(errs), "failed to list the images of all the public projects")
 	}
 
 	return images, nil
 }
 
+// hasGuestOSFeature tells if the image declares the guest OS feature 'feature' (UEFI_COMPATIBLE, SEV_CAPABLE, ...)
+func hasGuestOSFeature(image *compute.Image, feature string) bool {
+	for _, f := range image.GuestOsFeatures {
+		if f != nil && f.Type == feature {
+			return true
+		}
+	}
+	return false
+}
+
 // GetImage returns the Image referenced by id
 func (s *Stack) GetImage(id string) (*abstract.Image, fail.Error) {
 	images, err := s.ListImages()
@@ -99,13 +159,19 @@ func (s *Stack) GetImage(id string) (*abstract.Image, fail.Error) {
 // -------------TEMPLATES------------------------------------------------------------------------------------------------
 
 // ListTemplates overload OpenStackGcp ListTemplate method to filter wind and flex instance and add GPU configuration
-func (s *Stack) ListTemplates(all bool) (templates []abstract.HostTemplate, xerr fail.Error) {
+// The list is kept for the TTL of the catalog cache
+func (s *Stack) ListTemplates(all bool) ([]abstract.HostTemplate, fail.Error) {
+	return s.catalog.Templates(s.listTemplates)
+}
+
+// listTemplates queries the machine types of the zone
+func (s *Stack) listTemplates() (templates []abstract.HostTemplate, xerr fail.Error) {
 	compuService := s.ComputeService
 
 	templates = []abstract.HostTemplate{}
 
 	token := ""
-	for paginate := true; paginate; {
+	for page, paginate := 1, true; paginate; page++ {
 		resp, err := compuService.MachineTypes.List(s.GcpConfig.ProjectID, s.GcpConfig.Zone).PageToken(token).Do()
 		if err != nil {
 			logrus.Warnf("Can't list public types...: %s", err)
@@ -123,8 +189,7 @@ func (s *Stack) ListTemplates(all bool) (templates []abstract.HostTemplate, xerr
 				templates = append(templates, ht)
 			}
 		}
-		token := resp.NextPageToken
-		paginate = token != ""
+		token, paginate = nextPageToken(token, resp.NextPageToken, page)
 	}
 
 	if len(templates) == 0 {
@@ -153,6 +218,7 @@ func (s *Stack) GetTemplate(id string) (*abstract.HostTemplate, fail.Error) {
 // -------------SSH KEYS-------------------------------------------------------------------------------------------------
 
 // CreateKeyPair creates a key pair (no import)
+// GCP has no key pair resource: the public key is registered in the metadata of the instances created with it
 func (s *Stack) CreateKeyPair(name string) (*abstract.KeyPair, fail.Error) {
 	return abstract.NewKeyPair(name)
 }
@@ -167,11 +233,33 @@ func (s *Stack) ListKeyPairs() ([]abstract.KeyPair, fail.Error) {
 	return nil, fail.NotImplementedError("ListKeyPairs() not implemented yet") // FIXME: Technical debt
 }
 
+// sshKeysMetadataKey is the key of the instance metadata entry containing the ssh public keys
+const sshKeysMetadataKey = "ssh-keys"
+
+// blockProjectSSHKeysMetadataKey is the key of the instance metadata entry preventing the ssh public keys of the
+// project metadata from giving access to the instance
+const blockProjectSSHKeysMetadataKey = "block-project-ssh-keys"
+
 // DeleteKeyPair deletes the key pair identified by id
+// The public key of a key pair is only registered in the metadata of the instances created with it, and removed with
+// them, so there is nothing to delete
 func (s *Stack) DeleteKeyPair(id string) error {
 	return nil
 }
 
+// sshKeyEntry returns the line of 'ssh-keys' metadata registering the public key of kp for the user safescale, with
+// the name of kp as comment; an empty string if there is no key pair
+func sshKeyEntry(kp *abstract.KeyPair) string {
+	if kp == nil || kp.PublicKey == "" {
+		return ""
+	}
+	fields := strings.Fields(kp.PublicKey)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	return abstract.DefaultUser + ":" + strings.Join(append(fields, kp.Name), " ")
+}
+
 // CreateHost creates an host satisfying request
 func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, userData *userdata.Content, xerr fail.Error) {
 	userData = userdata.NewContent()
@@ -217,6 +305,31 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		}
 	}
 
+	if xerr = request.CheckFixedIPs(); xerr != nil {
+		return nil, userData, xerr
+	}
+	fixedIP := request.FixedIPs[defaultNetworkID]
+	if len(request.FixedIPs) > 1 || (len(request.FixedIPs) == 1 && fixedIP == "") {
+		return nil, userData, fail.InvalidRequestError(
+			"on GCP, a host is attached only to its default network; a fixed IP can be requested only on this network",
+		)
+	}
+	if _, xerr = request.OrderedNetworks(); xerr != nil {
+		return nil, userData, xerr
+	}
+	for _, nic := range request.NICs {
+		if nic.Name != "" {
+			return nil, userData, fail.InvalidRequestError(
+				"on GCP, the names of the network interfaces are chosen by the provider (nic0, nic1...)",
+			)
+		}
+	}
+	if fixedIP != "" {
+		if xerr = s.checkFixedIPAvailable(defaultNetwork.Name, fixedIP); xerr != nil {
+			return nil, userData, xerr
+		}
+	}
+
 	if defaultGateway == nil && !hostMustHavePublicIP {
 		return nil, userData, fail.Errorf(
 			fmt.Sprintf("the host %s must have a gateway or be public", resourceName), nil,
@@ -239,29 +352,43 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		return nil, userData, fail.Errorf(fmt.Sprintf("failed to get image: %s", err), err)
 	}
 
-	rim, err := s.GetImage(request.ImageID)
-	if err != nil {
-		return nil, nil, err
+	// The boot disk is created either from the image or from the snapshot of the boot disk of another host
+	var (
+		bootImageURL, bootSnapshotURL string
+		bootDiskSize                  int64
+		bootImage                     *abstract.Image
+	)
+	if request.SourceSnapshotID != "" {
+		snapshot, err := s.getSnapshot(request.SourceSnapshotID)
+		if err != nil {
+			return nil, nil, err
+		}
+		bootSnapshotURL = snapshot.SelfLink
+		bootDiskSize = snapshot.DiskSizeGb
+	} else {
+		rim, err := s.GetImage(request.ImageID)
+		if err != nil {
+			return nil, nil, err
+		}
+		bootImageURL = rim.URL
+		bootDiskSize = rim.DiskSize
+		bootImage = rim
 	}
 
-	// select disk size
+	// select disk size and type
 
-	if request.DiskSize > template.DiskSize {
-		template.DiskSize = request.DiskSize
+	diskSize, err := stacks.SystemDiskSize(request, *template, bootDiskSize, s.Config.SystemDiskSizes)
+	if err != nil {
+		return nil, userData, err
 	}
+	template.DiskSize = diskSize
 
-	if int(rim.DiskSize) > template.DiskSize {
-		template.DiskSize = int(rim.DiskSize)
+	if err = validateDiskType(request.DiskType); err != nil {
+		return nil, userData, err
 	}
-
-	if template.DiskSize == 0 {
-		// Determines appropriate disk size
-		if template.Cores < 16 { // nolint
-			template.DiskSize = 100
-		} else if template.Cores < 32 {
-			template.DiskSize = 200
-		} else {
-			template.DiskSize = 400
+	if request.ConfidentialVM {
+		if err = validateConfidentialVM(template, bootImage); err != nil {
+			return nil, userData, err
 		}
 	}
 
@@ -286,6 +413,10 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	if err != nil {
 		return nil, userData, err
 	}
+	userDataPhase1, err = userdata.Fit(userDataPhase1, userdata.GCPMaxSize)
+	if err != nil {
+		return nil, userData, err
+	}
 
 	// --- Initializes abstract.Host ---
 
@@ -321,6 +452,14 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		return nil, userData, err
 	}
 
+	resourcePolicy := ""
+	if request.AffinityGroup != "" {
+		resourcePolicy, err = s.placementPolicy(request.AffinityGroup, request.AntiAffinity)
+		if err != nil {
+			return nil, userData, err
+		}
+	}
+
 	// --- query provider for host creation ---
 
 	logrus.Debugf("requesting host resource creation...")
@@ -330,9 +469,10 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	retryErr := retry.WhileUnsuccessfulDelay5Seconds(
 		func() error {
 			server, err := buildGcpMachine(
-				s.ComputeService, s.GcpConfig.ProjectID, request.ResourceName, rim.URL, s.GcpConfig.Region,
-				s.GcpConfig.Zone, s.GcpConfig.NetworkName, defaultNetwork.Name, string(userDataPhase1), isGateway,
-				template,
+				s.ComputeService, s.GcpConfig.ProjectID, request.ResourceName, bootImageURL, bootSnapshotURL,
+				s.GcpConfig.Region, s.GcpConfig.Zone, s.GcpConfig.NetworkName, defaultNetwork.Name, fixedIP,
+				string(userDataPhase1), sshKeyEntry(request.KeyPair), isGateway, template, request.DiskType, request.Spot,
+				request.ShieldedVM, request.ConfidentialVM, request.Tags, resourcePolicy,
 			)
 			if err != nil {
 				if server != nil {
@@ -393,6 +533,15 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		return nil, userData, retryErr
 	}
 	if desistError != nil {
+		if gerr, ok := desistError.(*googleapi.Error); ok && fixedIP != "" &&
+			(gerr.Code == http.StatusBadRequest || gerr.Code == http.StatusConflict) {
+			return nil, userData, fail.InvalidRequestError(
+				fmt.Sprintf(
+					"provider rejected the fixed IP '%s' requested for host '%s': %s", fixedIP, request.ResourceName,
+					gerr.Error(),
+				),
+			)
+		}
 		return nil, userData, abstract.ResourceForbiddenError(
 			request.ResourceName, fmt.Sprintf("error creating host: %s", desistError.Error()),
 		)
@@ -429,10 +578,23 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		return nil, nil, fail.Errorf(fmt.Sprintf("unexpected nil host"), nil)
 	}
 
+	if fixedIP != "" {
+		err = host.Properties.LockForWrite(hostproperty.NetworkV1).ThenUse(
+			func(clonable data.Clonable) error {
+				clonable.(*propsv1.HostNetwork).IPv4Addresses[defaultNetworkID] = fixedIP
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, userData, err
+		}
+	}
+
 	if !host.OK() {
 		logrus.Warnf("Missing data in host: %s", spew.Sdump(host))
 	}
 
+	s.hostNames.set(host.Name, host.ID)
 	return host, userData, nil
 }
 
@@ -497,11 +659,154 @@ func publicAccess(isPublic bool) []*compute.AccessConfig {
 	return []*compute.AccessConfig{}
 }
 
+// diskTypes contains the types of disk allowed for a boot disk
+var diskTypes = map[string]bool{
+	"pd-standard": true,
+	"pd-balanced": true,
+	"pd-ssd":      true,
+}
+
+// validateDiskType checks that diskType is a type of disk usable as boot disk (empty meaning default type)
+func validateDiskType(diskType string) error {
+	if diskType != "" && !diskTypes[diskType] {
+		return fail.InvalidParameterError(
+			"DiskType", fmt.Sprintf("'%s' is not a valid disk type (allowed: pd-standard, pd-balanced, pd-ssd)", diskType),
+		)
+	}
+	return nil
+}
+
+// diskTypeURL returns the URL of the zonal resource corresponding to diskType, or an empty string if diskType is empty
+func diskTypeURL(projectID string, zone string, diskType string) string {
+	if diskType == "" {
+		return ""
+	}
+	return "https://www.googleapis.com/compute/v1/projects/" + projectID + "/zones/" + zone + "/diskTypes/" + diskType
+}
+
+// getSnapshot returns the disk snapshot 'name', ready to be used
+func (s *Stack) getSnapshot(name string) (*compute.Snapshot, fail.Error) {
+	snapshot, err := s.ComputeService.Snapshots.Get(s.GcpConfig.ProjectID, name).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, abstract.ResourceNotFoundError("snapshot", name)
+		}
+		return nil, fail.Wrap(err, fmt.Sprintf("failed to get snapshot '%s'", name))
+	}
+	if snapshot.Status != "READY" {
+		return nil, fail.InvalidRequestError(
+			fmt.Sprintf("snapshot '%s' is not ready to be used (status '%s')", name, snapshot.Status),
+		)
+	}
+	return snapshot, nil
+}
+
+// confidentialMachineFamilies lists the prefixes of the machine types able to run confidential VMs (AMD SEV)
+var confidentialMachineFamilies = []string{"n2d-", "c2d-"}
+
+// validateConfidentialVM checks the machine type and the image (nil if the host is restored from a snapshot) allow
+// to create a confidential VM
+func validateConfidentialVM(template *abstract.HostTemplate, image *abstract.Image) error {
+	compatible := false
+	for _, prefix := range confidentialMachineFamilies {
+		if strings.HasPrefix(template.Name, prefix) {
+			compatible = true
+			break
+		}
+	}
+	if !compatible {
+		return fail.InvalidRequestError(
+			fmt.Sprintf(
+				"machine type '%s' cannot run a confidential VM (allowed: %s* machine types)", template.Name,
+				strings.Join(confidentialMachineFamilies, "*, "),
+			),
+		)
+	}
+	if image != nil && !image.ConfidentialComputeCapable {
+		return fail.InvalidRequestError(
+			fmt.Sprintf("image '%s' cannot boot a confidential VM (image without SEV_CAPABLE guest OS feature)", image.Name),
+		)
+	}
+	return nil
+}
+
+// shieldedInstanceConfig returns the Shielded VM options of an instance: nil (the defaults of GCP) for a standard
+// instance, secure boot, vTPM and integrity monitoring enabled for a shielded instance
+func shieldedInstanceConfig(shielded bool) *compute.ShieldedInstanceConfig {
+	if !shielded {
+		return nil
+	}
+	return &compute.ShieldedInstanceConfig{
+		EnableSecureBoot:          true,
+		EnableVtpm:                true,
+		EnableIntegrityMonitoring: true,
+	}
+}
+
+// confidentialInstanceConfig returns the Confidential VM options of an instance, nil for a standard instance
+func confidentialInstanceConfig(confidential bool) *compute.ConfidentialInstanceConfig {
+	if !confidential {
+		return nil
+	}
+	return &compute.ConfidentialInstanceConfig{EnableConfidentialCompute: true}
+}
+
+// gcpLabels converts tags to GCP labels, whose names and values may only contain lowercase letters, digits, '_' and
+// '-', up to 63 characters; other characters are replaced by '_'
+func gcpLabels(tags map[string]string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(tags))
+	for k, v := range tags {
+		labels[gcpLabelPart(k)] = gcpLabelPart(v)
+	}
+	return labels
+}
+
+// gcpLabelPart normalizes a label name or value
+func gcpLabelPart(in string) string {
+	out := []rune(strings.ToLower(in))
+	for i, r := range out {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' && r != '-' {
+			out[i] = '_'
+		}
+	}
+	if len(out) > 63 {
+		out = out[:63]
+	}
+	return string(out)
+}
+
+// instanceMetadata returns the metadata of an instance running 'userdata' at startup; the ssh keys are registered on
+// the instance only, and the ones of the project metadata are blocked, so a key pair gives access to its hosts only
+func instanceMetadata(userdata string, sshKeys string) *compute.Metadata {
+	blocked := "true"
+	metadata := &compute.Metadata{
+		Items: []*compute.MetadataItems{
+			{Key: "startup-script", Value: &userdata},
+			{Key: blockProjectSSHKeysMetadataKey, Value: &blocked},
+		},
+	}
+	if sshKeys != "" {
+		metadata.Items = append(metadata.Items, &compute.MetadataItems{Key: sshKeysMetadataKey, Value: &sshKeys})
+	}
+	return metadata
+}
+
 // buildGcpMachine ...
-func buildGcpMachine(service *compute.Service, projectID string, instanceName string, imageID string, region string, zone string, network string, subnetwork string, userdata string, isPublic bool, template *abstract.HostTemplate) (*abstract.Host, fail.Error) {
+// The boot disk is created from the snapshot 'snapshotURL' if set, from the image 'imageID' otherwise.
+// If diskType is empty, the boot disk uses the default type of disk (pd-standard).
+// If shielded is set, the instance is a Shielded VM; if confidential is set, the instance is a Confidential VM.
+// 'tags' are set as labels of the instance.
+// 'resourcePolicy' is the URL of the placement policy of the instance, if any (see placementPolicy).
+func buildGcpMachine(service *compute.Service, projectID string, instanceName string, imageID string, snapshotURL string, region string, zone string, network string, subnetwork string, networkIP string, userdata string, sshKeys string, isPublic bool, template *abstract.HostTemplate, diskType string, spot bool, shielded bool, confidential bool, tags map[string]string, resourcePolicy string) (*abstract.Host, fail.Error) {
 	prefix := "https://www.googleapis.com/compute/v1/projects/" + projectID
 
 	imageURL := imageID
+	if snapshotURL != "" {
+		imageURL = ""
+	}
 
 	tag := "nat"
 	if !isPublic {
@@ -524,9 +829,11 @@ func buildGcpMachine(service *compute.Service, projectID string, instanceName st
 				Boot:       true,
 				Type:       "PERSISTENT",
 				InitializeParams: &compute.AttachedDiskInitializeParams{
-					DiskName:    fmt.Sprintf("%s-disk", instanceName),
-					SourceImage: imageURL,
-					DiskSizeGb:  int64(template.DiskSize),
+					DiskName:       fmt.Sprintf("%s-disk", instanceName),
+					SourceImage:    imageURL,
+					SourceSnapshot: snapshotURL,
+					DiskSizeGb:     int64(template.DiskSize),
+					DiskType:       diskTypeURL(projectID, zone, diskType),
 				},
 			},
 		},
@@ -535,6 +842,7 @@ func buildGcpMachine(service *compute.Service, projectID string, instanceName st
 				AccessConfigs: publicAccess(isPublic),
 				Network:       prefix + "/global/networks/" + network,
 				Subnetwork:    prefix + "/regions/" + region + "/subnetworks/" + subnetwork,
+				NetworkIP:     networkIP,
 			},
 		},
 		ServiceAccounts: []*compute.ServiceAccount{
@@ -546,14 +854,14 @@ func buildGcpMachine(service *compute.Service, projectID string, instanceName st
 				},
 			},
 		},
-		Metadata: &compute.Metadata{
-			Items: []*compute.MetadataItems{
-				{
-					Key:   "startup-script",
-					Value: &userdata,
-				},
-			},
-		},
+		Metadata:                   instanceMetadata(userdata, sshKeys),
+		Scheduling:                 scheduling(spot, confidential),
+		Labels:                     gcpLabels(tags),
+		ShieldedInstanceConfig:     shieldedInstanceConfig(shielded),
+		ConfidentialInstanceConfig: confidentialInstanceConfig(confidential),
+	}
+	if resourcePolicy != "" {
+		instance.ResourcePolicies = []string{resourcePolicy}
 	}
 
 	op, err := service.Instances.Insert(projectID, zone, instance).Do()
@@ -592,6 +900,175 @@ func buildGcpMachine(service *compute.Service, projectID string, instanceName st
 	return host, nil
 }
 
+// gcpSpreadAvailabilityDomains is the number of availability domains (distinct racks and power sources) over which
+// the instances of an anti-affinity group are spread, the maximum allowed by GCP
+const gcpSpreadAvailabilityDomains = 8
+
+// invalidPolicyNameChars matches the characters not allowed in the name of a GCP resource
+var invalidPolicyNameChars = regexp.MustCompile(`[^a-z0-9-]`)
+
+// placementPolicyName returns the name of the resource policy of the affinity group 'group'
+func placementPolicyName(group string) string {
+	name := "safescale-" + invalidPolicyNameChars.ReplaceAllString(strings.ToLower(group), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// groupPlacementPolicy returns the placement policy spreading the instances over distinct availability domains if
+// antiAffinity is set, collocating them otherwise
+func groupPlacementPolicy(antiAffinity bool) *compute.ResourcePolicyGroupPlacementPolicy {
+	if antiAffinity {
+		return &compute.ResourcePolicyGroupPlacementPolicy{AvailabilityDomainCount: gcpSpreadAvailabilityDomains}
+	}
+	return &compute.ResourcePolicyGroupPlacementPolicy{Collocation: "COLLOCATED"}
+}
+
+// placementPolicy returns the URL of the placement policy of the affinity group 'group', creating it if it doesn't
+// exist yet
+// An existing policy of the other kind is refused, the instance could not satisfy both.
+func (s *Stack) placementPolicy(group string, antiAffinity bool) (string, fail.Error) {
+	name := placementPolicyName(group)
+	policyURL := fmt.Sprintf(
+		"https://www.googleapis.com/compute/v1/projects/%s/regions/%s/resourcePolicies/%s", s.GcpConfig.ProjectID,
+		s.GcpConfig.Region, name,
+	)
+
+	policy, err := s.ComputeService.ResourcePolicies.Get(s.GcpConfig.ProjectID, s.GcpConfig.Region, name).Do()
+	if err == nil {
+		gpp := policy.GroupPlacementPolicy
+		if gpp == nil || (gpp.Collocation == "COLLOCATED") == antiAffinity {
+			return "", fail.InvalidRequestError(
+				fmt.Sprintf("resource policy '%s' of affinity group '%s' already exists with another placement", name, group),
+			)
+		}
+		return policyURL, nil
+	}
+	if gerr, ok := err.(*googleapi.Error); !ok || gerr.Code != 404 {
+		return "", err
+	}
+
+	policy = &compute.ResourcePolicy{
+		Name:                 name,
+		Description:          fmt.Sprintf("placement of the hosts of affinity group '%s'", group),
+		Region:               s.GcpConfig.Region,
+		GroupPlacementPolicy: groupPlacementPolicy(antiAffinity),
+	}
+	op, err := s.ComputeService.ResourcePolicies.Insert(s.GcpConfig.ProjectID, s.GcpConfig.Region, policy).Do()
+	if err != nil {
+		return "", err
+	}
+	oco := OpContext{
+		Operation:    op,
+		ProjectID:    s.GcpConfig.ProjectID,
+		Service:      s.ComputeService,
+		DesiredState: "DONE",
+	}
+	err = waitUntilOperationIsSuccessfulOrTimeout(oco, temporal.GetMinDelay(), temporal.GetContextTimeout())
+	if err != nil {
+		return "", err
+	}
+	logrus.Debugf("resource policy '%s' created for affinity group '%s'", name, group)
+	return policyURL, nil
+}
+
+// scheduling returns the scheduling options of an instance: nil (the defaults of GCP) for a standard instance,
+// a preemptible instance for a spot one; a reclaimed preemptible instance is stopped (not deleted), so it can be
+// started again
+// A confidential instance cannot be live-migrated, it is stopped during the maintenances of its physical host.
+func scheduling(spot bool, confidential bool) *compute.Scheduling {
+	if !spot {
+		if confidential {
+			return &compute.Scheduling{OnHostMaintenance: "TERMINATE"}
+		}
+		return nil
+	}
+	automaticRestart := false
+	return &compute.Scheduling{
+		Preemptible:       true,
+		AutomaticRestart:  &automaticRestart,
+		OnHostMaintenance: "TERMINATE",
+	}
+}
+
+// isSpot tells if the scheduling options are the ones of a spot (preemptible) instance
+func isSpot(scheduling *compute.Scheduling) bool {
+	return scheduling != nil && scheduling.Preemptible
+}
+
+// wasPreempted tells if the spot instance has been stopped by GCP to reclaim its resources since its last start
+func (s *Stack) wasPreempted(instance *compute.Instance) (bool, fail.Error) {
+	zone := s.GcpConfig.Zone
+	if instance.Zone != "" {
+		zone = getResourceNameFromSelfLink(genURL(instance.Zone))
+	}
+	filter := fmt.Sprintf(`operationType="compute.instances.preempted" AND targetId=%d`, instance.Id)
+	resp, err := s.ComputeService.ZoneOperations.List(s.GcpConfig.ProjectID, zone).Filter(filter).Do()
+	if err != nil {
+		return false, err
+	}
+	return preemptedSince(resp.Items, instance.LastStartTimestamp), nil
+}
+
+// preemptedSince tells if one of the preemption operations occurred after lastStart (RFC3339 timestamp, empty
+// if the instance has never been started)
+func preemptedSince(ops []*compute.Operation, lastStart string) bool {
+	start, err := time.Parse(time.RFC3339, lastStart)
+	if err != nil {
+		return len(ops) > 0
+	}
+	for _, op := range ops {
+		at, err := time.Parse(time.RFC3339, op.InsertTime)
+		if err != nil || !at.Before(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFixedIPAvailable returns fail.ErrDuplicate if 'ip' is already used in the subnetwork named 'subnetwork',
+// either by an instance or by a reserved internal address (a VIP for example)
+func (s *Stack) checkFixedIPAvailable(subnetwork string, ip string) fail.Error {
+	suffix := "/subnetworks/" + subnetwork
+	duplicate := fail.DuplicateError(fmt.Sprintf("IP address '%s' is already used in network '%s'", ip, subnetwork))
+
+	token := ""
+	for paginate := true; paginate; {
+		resp, err := s.ComputeService.Instances.AggregatedList(s.GcpConfig.ProjectID).PageToken(token).Do()
+		if err != nil {
+			return fail.Errorf(fmt.Sprintf("cannot list hosts: %v", err), err)
+		}
+		for _, scoped := range resp.Items {
+			for _, instance := range scoped.Instances {
+				for _, nic := range instance.NetworkInterfaces {
+					if nic.NetworkIP == ip && strings.HasSuffix(nic.Subnetwork, suffix) {
+						return duplicate
+					}
+				}
+			}
+		}
+		token = resp.NextPageToken
+		paginate = token != ""
+	}
+
+	token = ""
+	for paginate := true; paginate; {
+		resp, err := s.ComputeService.Addresses.List(s.GcpConfig.ProjectID, s.GcpConfig.Region).PageToken(token).Do()
+		if err != nil {
+			return fail.Errorf(fmt.Sprintf("cannot list addresses: %v", err), err)
+		}
+		for _, address := range resp.Items {
+			if address.Address == ip && strings.HasSuffix(address.Subnetwork, suffix) {
+				return duplicate
+			}
+		}
+		token = resp.NextPageToken
+		paginate = token != ""
+	}
+	return nil
+}
+
 // InspectHost returns the host identified by ref (name or id) or by a *abstract.Host containing an id
 func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fail.Error) {
 	switch hostParam := hostParam.(type) {
@@ -621,6 +1098,9 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 
 	gcpHost, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, hostRef).Do()
 	if err != nil {
+		if isNotFound(err) {
+			return nil, abstract.ResourceNotFoundError("host", hostRef)
+		}
 		return nil, err
 	}
 
@@ -631,6 +1111,31 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 
 	host.Name = gcpHost.Name
 
+	spot := isSpot(gcpHost.Scheduling)
+	preempted := false
+	if spot && gcpHost.Status == "TERMINATED" {
+		preempted, err = s.wasPreempted(gcpHost)
+		if err != nil {
+			logrus.Warnf("failed to check if spot host '%s' has been preempted: %v", gcpHost.Name, err)
+		}
+	}
+	err = host.Properties.LockForWrite(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			hostDescriptionV1 := clonable.(*propsv1.HostDescription)
+			hostDescriptionV1.Spot = spot
+			hostDescriptionV1.Preempted = preempted
+			hostDescriptionV1.ShieldedVM = gcpHost.ShieldedInstanceConfig != nil &&
+				gcpHost.ShieldedInstanceConfig.EnableSecureBoot
+			hostDescriptionV1.ConfidentialVM = gcpHost.ConfidentialInstanceConfig != nil &&
+				gcpHost.ConfidentialInstanceConfig.EnableConfidentialCompute
+			hostDescriptionV1.AvailabilityZone = getResourceNameFromSelfLink(genURL(gcpHost.Zone))
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to update hostproperty.DescriptionV1 : %s", err.Error()), err)
+	}
+
 	var subnets []IPInSubnet
 
 	for _, nit := range gcpHost.NetworkInterfaces {
@@ -766,7 +1271,35 @@ func stateConvert(gcpHostStatus string) (hoststate.Enum, fail.Error) {
 }
 
 // GetHostByName returns the host identified by ref (name or id)
+// Names are resolved with the index of the hosts populated by the first ListHosts; a stale entry (host deleted or
+// renamed outside of SafeScale) triggers a fresh listing of the hosts, and a name not indexed is looked up directly
 func (s *Stack) GetHostByName(name string) (*abstract.Host, fail.Error) {
+	id, found, populated := s.hostNames.get(name)
+	if found {
+		instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, id).Do()
+		if err == nil && instance.Name == name {
+			return hostFromInstance(instance), nil
+		}
+		if gerr, ok := err.(*googleapi.Error); err != nil && (!ok || gerr.Code != 404) {
+			return nil, err
+		}
+		logrus.Debugf("host '%s' changed outside of SafeScale, listing hosts", name)
+		populated = false
+	}
+
+	if populated {
+		instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, name).Do()
+		if err != nil {
+			if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+				return nil, abstract.ResourceNotFoundError("host", name)
+			}
+			return nil, err
+		}
+		host := hostFromInstance(instance)
+		s.hostNames.set(host.Name, host.ID)
+		return host, nil
+	}
+
 	hosts, err := s.ListHosts()
 	if err != nil {
 		return nil, err
@@ -782,19 +1315,29 @@ func (s *Stack) GetHostByName(name string) (*abstract.Host, fail.Error) {
 }
 
 // DeleteHost deletes the host identified by id
+// The host is considered deleted only once GCP answers 404 for it; a host already absent is reported as not found.
 func (s *Stack) DeleteHost(id string) (err error) {
 	service := s.ComputeService
 	projectID := s.GcpConfig.ProjectID
 	zone := s.GcpConfig.Zone
 	instanceName := id
 
+	// Whatever the outcome, the cached name -> id association cannot be trusted anymore
+	defer s.hostNames.remove(instanceName)
+
 	_, err = service.Instances.Get(projectID, zone, instanceName).Do()
 	if err != nil {
+		if isNotFound(err) {
+			return abstract.ResourceNotFoundError("host", instanceName)
+		}
 		return err
 	}
 
 	op, err := service.Instances.Delete(projectID, zone, instanceName).Do()
 	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
 		return err
 	}
 
@@ -805,27 +1348,32 @@ func (s *Stack) DeleteHost(id string) (err error) {
 		DesiredState: "DONE",
 	}
 
-	err = waitUntilOperationIsSuccessfulOrTimeout(oco, temporal.GetMinDelay(), temporal.GetHostCleanupTimeout())
+	opErr := waitUntilOperationIsSuccessfulOrTimeout(oco, temporal.GetMinDelay(), temporal.GetHostCleanupTimeout())
 
-	waitErr := retry.WhileUnsuccessfulDelay5Seconds(
+	// The operation may report an error (or time out) while the instance is nevertheless gone; only the 404
+	// answered by GCP tells the instance has really been deleted
+	waitErr := retry.WhileUnsuccessfulWithJitter(
 		func() error {
 			_, recErr := service.Instances.Get(projectID, zone, instanceName).Do()
-			if gerr, ok := recErr.(*googleapi.Error); ok {
-				if gerr.Code == 404 {
-					return nil
-				}
+			if isNotFound(recErr) {
+				return nil
 			}
 			return fail.Errorf(
 				fmt.Sprintf("error waiting for instance [%s] to disappear: [%v]", instanceName, recErr), recErr,
 			)
-		}, temporal.GetContextTimeout(),
+		}, temporal.GetMinDelay(), temporal.GetDefaultDelay(), temporal.GetHostCleanupTimeout(),
 	)
-
 	if waitErr != nil {
-		logrus.Error(fail.Cause(waitErr))
+		if opErr != nil {
+			logrus.Warnf("deletion operation of instance [%s] failed: %v", instanceName, opErr)
+		}
+		return fail.Wrap(fail.Cause(waitErr), fmt.Sprintf("failed to delete instance [%s]", instanceName))
+	}
+	if opErr != nil {
+		logrus.Debugf("deletion operation of instance [%s] reported '%v', but the instance is gone", instanceName, opErr)
 	}
 
-	return err
+	return nil
 }
 
 // ResizeHost change the template used by an host
@@ -840,26 +1388,30 @@ func (s *Stack) ListHosts() ([]*abstract.Host, fail.Error) {
 	var hostList []*abstract.Host
 
 	token := ""
-	for paginate := true; paginate; {
+	for page, paginate := 1, true; paginate; page++ {
 		resp, err := compuService.Instances.List(s.GcpConfig.ProjectID, s.GcpConfig.Zone).PageToken(token).Do()
 		if err != nil {
 			return hostList, fail.Errorf(fmt.Sprintf("cannot list hosts: %v", err), err)
 		}
 		for _, instance := range resp.Items {
-			nhost := abstract.NewHost()
-			nhost.ID = strconv.FormatUint(instance.Id, 10)
-			nhost.Name = instance.Name
-			nhost.LastState, _ = stateConvert(instance.Status)
-
-			hostList = append(hostList, nhost)
+			hostList = append(hostList, hostFromInstance(instance))
 		}
-		token := resp.NextPageToken
-		paginate = token != ""
+		token, paginate = nextPageToken(token, resp.NextPageToken, page)
 	}
 
+	s.hostNames.reset(hostList)
 	return hostList, nil
 }
 
+// hostFromInstance returns the host corresponding to 'instance', with only ID, name and state set
+func hostFromInstance(instance *compute.Instance) *abstract.Host {
+	host := abstract.NewHost()
+	host.ID = strconv.FormatUint(instance.Id, 10)
+	host.Name = instance.Name
+	host.LastState, _ = stateConvert(instance.Status)
+	return host
+}
+
 // StopHost stops the host identified by id
 func (s *Stack) StopHost(id string) error {
 	service := s.ComputeService
@@ -900,6 +1452,192 @@ func (s *Stack) StartHost(id string) error {
 	return err
 }
 
+// SetHostIPForwarding cannot change IP forwarding on GCP, where it can only be set at host creation (it is for public
+// hosts, including gateways); returns fail.ErrNotAvailable if the host isn't already in the requested mode
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, host.ID).Do()
+	if err != nil {
+		return err
+	}
+	if instance.CanIpForward == enabled {
+		return nil
+	}
+	return fail.NotAvailableError(
+		fmt.Sprintf(
+			"IP forwarding of host '%s' can only be set at creation on GCP (currently %v)", host.Name,
+			instance.CanIpForward,
+		),
+	)
+}
+
+// BindSecurityGroupToHost is not implemented for gcp
+func (s *Stack) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("BindSecurityGroupToHost() not implemented for gcp")
+}
+
+// UnbindSecurityGroupFromHost is not implemented for gcp
+func (s *Stack) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("UnbindSecurityGroupFromHost() not implemented for gcp")
+}
+
+// AddRulesToSecurityGroup is not implemented for gcp
+func (s *Stack) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	return fail.NotImplementedError("AddRulesToSecurityGroup() not implemented for gcp")
+}
+
+// GetHostConsoleOutput returns the last 'lines' lines (all if 0) of the output of the first serial port of the host
+// identified by id
+func (s *Stack) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	resp, err := s.ComputeService.Instances.GetSerialPortOutput(s.GcpConfig.ProjectID, s.GcpConfig.Zone, id).Do()
+	if err != nil {
+		return "", fail.Wrap(err, fmt.Sprintf("failed to get serial port output of host '%s'", id))
+	}
+	return lastLines(resp.Contents, lines), nil
+}
+
+// ListHostNetworkInterfaces returns the network interfaces of the host identified by id; GCP doesn't give their MAC
+// address
+func (s *Stack) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, id).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, abstract.ResourceNotFoundError("host", id)
+		}
+		return nil, fail.Wrap(err, fmt.Sprintf("failed to get host '%s'", id))
+	}
+
+	out := make([]abstract.HostNetworkInterface, 0, len(instance.NetworkInterfaces))
+	for _, nit := range instance.NetworkInterfaces {
+		nic := abstract.HostNetworkInterface{Name: nit.Name}
+		if nit.NetworkIP != "" {
+			nic.PrivateIPs = []string{nit.NetworkIP}
+		}
+		for _, aco := range nit.AccessConfigs {
+			if aco != nil && aco.NatIP != "" {
+				nic.PublicIP = aco.NatIP
+			}
+		}
+		// Networks are identified by the ID of their subnetwork
+		snet := genURL(nit.Subnetwork)
+		region, err := getRegionFromSelfLink(snet)
+		if err != nil {
+			return nil, err
+		}
+		subnet, err := s.ComputeService.Subnetworks.Get(
+			s.GcpConfig.ProjectID, region, getResourceNameFromSelfLink(snet),
+		).Do()
+		if err != nil {
+			return nil, fail.Wrap(err, fmt.Sprintf("failed to get subnetwork of interface '%s'", nit.Name))
+		}
+		nic.NetworkID = strconv.FormatUint(subnet.Id, 10)
+		out = append(out, nic)
+	}
+	return out, nil
+}
+
+// CreateHostSnapshot creates the snapshot 'name' of the boot disk of the host identified by id and returns as soon
+// as the disk is captured (status UPLOADING); on GCP, the snapshot is referenced by its name
+func (s *Stack) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, id).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return "", abstract.ResourceNotFoundError("host", id)
+		}
+		return "", fail.Wrap(err, fmt.Sprintf("failed to get host '%s'", id))
+	}
+	bootDisk := ""
+	for _, disk := range instance.Disks {
+		if disk.Boot {
+			bootDisk = disk.Source[strings.LastIndex(disk.Source, "/")+1:]
+			break
+		}
+	}
+	if bootDisk == "" {
+		return "", fail.NotFoundError(fmt.Sprintf("failed to find the boot disk of host '%s'", id))
+	}
+
+	_, err = s.ComputeService.Disks.CreateSnapshot(
+		s.GcpConfig.ProjectID, s.GcpConfig.Zone, bootDisk, &compute.Snapshot{Name: name},
+	).Do()
+	if err != nil {
+		return "", fail.Wrap(err, fmt.Sprintf("failed to create snapshot of host '%s'", id))
+	}
+
+	// The disk is captured once the snapshot leaves the CREATING state; the upload goes on without the instance
+	err = s.waitSnapshotStatus(name, temporal.GetHostTimeout(), "UPLOADING", "READY")
+	if err != nil {
+		return "", fail.Wrap(err, fmt.Sprintf("failed to capture the boot disk of host '%s'", id))
+	}
+	return name, nil
+}
+
+// WaitHostSnapshotReady waits until the snapshot identified by snapshotID is uploaded and usable
+func (s *Stack) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	err := s.waitSnapshotStatus(snapshotID, temporal.GetLongOperationTimeout(), "READY")
+	if err != nil {
+		return fail.Wrap(err, fmt.Sprintf("snapshot '%s' is not ready", snapshotID))
+	}
+	return nil
+}
+
+// waitSnapshotStatus waits until the snapshot 'name' reaches one of the wanted statuses; gives up at once if the
+// snapshot failed or is being deleted
+func (s *Stack) waitSnapshotStatus(name string, timeout time.Duration, wanted ...string) error {
+	retryErr := retry.WhileUnsuccessfulDelay1Second(
+		func() error {
+			snapshot, err := s.ComputeService.Snapshots.Get(s.GcpConfig.ProjectID, name).Do()
+			if err != nil {
+				if isNotFound(err) {
+					return abstract.ResourceNotFoundError("snapshot", name)
+				}
+				return err
+			}
+			for _, status := range wanted {
+				if snapshot.Status == status {
+					return nil
+				}
+			}
+			if snapshot.Status == "FAILED" || snapshot.Status == "DELETING" {
+				return retry.AbortedError(
+					"", fail.Errorf(fmt.Sprintf("snapshot '%s' failed (status '%s')", name, snapshot.Status), nil),
+				)
+			}
+			return fmt.Errorf("snapshot '%s' not ready yet (status '%s')", name, snapshot.Status)
+		},
+		timeout,
+	)
+	if retryErr != nil {
+		if aborted, ok := retryErr.(retry.ErrAborted); ok {
+			return aborted.Cause()
+		}
+		return retryErr
+	}
+	return nil
+}
+
+// RenameHost renames the host identified by id
+// GCP does not allow to rename a running instance, and the instance name is used as host ID
+func (s *Stack) RenameHost(id string, newName string) fail.Error {
+	return fail.NotImplementedError("RenameHost() not implemented for gcp")
+}
+
+// lastLines returns the last 'n' lines of text (all if n is 0)
+func lastLines(text string, n int) string {
+	if n <= 0 {
+		return text
+	}
+	trimmed := strings.TrimRight(text, "\n")
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		if trimmed[i] == '\n' {
+			n--
+			if n == 0 {
+				return text[i+1:]
+			}
+		}
+	}
+	return text
+}
+
 // RebootHost reboot the host identified by id
 func (s *Stack) RebootHost(id string) error {
 	service := s.ComputeService
@@ -980,3 +1718,45 @@ func (s *Stack) ListRegions() ([]string, fail.Error) {
 
 	return regions, nil
 }
+
+// GetQuotas returns the limits and usages of the tenant resources
+// Instances, vCPUs and addresses are read from the quotas of the region, security groups from the
+// firewall quota of the project; GCP has no quota on RAM nor on the number of disks
+func (s *Stack) GetQuotas() (*abstract.Quotas, fail.Error) {
+	quotas := abstract.NewQuotas()
+
+	region, err := s.ComputeService.Regions.Get(s.GcpConfig.ProjectID, s.GcpConfig.Region).Do()
+	if err != nil {
+		return nil, err
+	}
+	applyGcpQuotas(quotas, region.Quotas)
+
+	project, err := s.ComputeService.Projects.Get(s.GcpConfig.ProjectID).Do()
+	if err != nil {
+		logrus.Warnf("failed to read quotas of project '%s': %v", s.GcpConfig.ProjectID, err)
+		return quotas, nil
+	}
+	applyGcpQuotas(quotas, project.Quotas)
+
+	return quotas, nil
+}
+
+// applyGcpQuotas fills quotas with the GCP quotas having a known metric
+func applyGcpQuotas(quotas *abstract.Quotas, items []*compute.Quota) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		value := abstract.Quota{Limit: int(item.Limit), Used: int(item.Usage)}
+		switch item.Metric {
+		case "INSTANCES":
+			quotas.Instances = value
+		case "CPUS":
+			quotas.VCPUs = value
+		case "IN_USE_ADDRESSES":
+			quotas.FloatingIPs = value
+		case "FIREWALLS":
+			quotas.SecurityGroups = value
+		}
+	}
+}
@@ -0,0 +1,668 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
+	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
+	"github.com/CS-SI/SafeScale/lib/utils/data"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// fakeProjectService emulates the part of the GCP compute API used by the stack; the project metadata can be read but
+// not modified, the ssh keys of the hosts having to be registered in the metadata of their instance
+type fakeProjectService struct {
+	lock     sync.Mutex
+	metadata compute.Metadata
+	// setRequests counts the (rejected) requests modifying the project metadata
+	setRequests int
+	snapshots   map[string]*compute.Snapshot
+	// machineTypes contains the pages returned when listing machine types, indexed by page token
+	machineTypes map[string]*compute.MachineTypeList
+	// machineTypeRequests counts the requests listing machine types
+	machineTypeRequests int
+	// instances contains the instances inserted, indexed by name
+	instances map[string]*compute.Instance
+	// resourcePolicies contains the resource policies inserted, indexed by name
+	resourcePolicies map[string]*compute.ResourcePolicy
+	// images contains the images of the public projects, indexed by project; listing the images of other projects fails
+	images map[string]*compute.ImageList
+	// instancePages, diskPages, networkPages and subnetworkPages contain the pages returned when listing instances,
+	// disks, networks and subnetworks, indexed by page token
+	instancePages   map[string]*compute.InstanceList
+	diskPages       map[string]*compute.DiskList
+	networkPages    map[string]*compute.NetworkList
+	subnetworkPages map[string]*compute.SubnetworkList
+	// listRequests counts the requests listing instances, disks, networks or subnetworks
+	listRequests int
+	// operationZones contains the zones whose operations have been listed
+	operationZones []string
+}
+
+// writePage writes the page requested, or a not found error if there is no such page
+func writePage(w http.ResponseWriter, page interface{}, ok bool) {
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (f *fakeProjectService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/projects/test-project"):
+		_ = json.NewEncoder(w).Encode(&compute.Project{Name: "test-project", CommonInstanceMetadata: &f.metadata})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/setCommonInstanceMetadata"):
+		f.setRequests++
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":403,"message":"project metadata must not be modified"}}`))
+	case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/global/operations/"):
+		_ = json.NewEncoder(w).Encode(&compute.Operation{Name: "op-1", Status: "DONE"})
+	case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/global/snapshots/"):
+		snapshot, ok := f.snapshots[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(snapshot)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/machineTypes"):
+		f.machineTypeRequests++
+		list, ok := f.machineTypes[r.URL.Query().Get("pageToken")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/instances"):
+		var instance compute.Instance
+		if err := json.NewDecoder(r.Body).Decode(&instance); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if f.instances == nil {
+			f.instances = map[string]*compute.Instance{}
+		}
+		instance.Id = uint64(len(f.instances) + 1)
+		f.instances[instance.Name] = &instance
+		_ = json.NewEncoder(w).Encode(&compute.Operation{Name: "op-1", Status: "DONE"})
+	case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/instances/"):
+		instance, ok := f.instances[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(instance)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/resourcePolicies"):
+		var policy compute.ResourcePolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if f.resourcePolicies == nil {
+			f.resourcePolicies = map[string]*compute.ResourcePolicy{}
+		}
+		f.resourcePolicies[policy.Name] = &policy
+		_ = json.NewEncoder(w).Encode(&compute.Operation{Name: "op-1", Status: "DONE"})
+	case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/resourcePolicies/"):
+		policy, ok := f.resourcePolicies[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(policy)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/global/images"):
+		parts := strings.Split(r.URL.Path, "/")
+		list, ok := f.images[parts[len(parts)-3]]
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"access denied"}}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/instances/"):
+		name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if _, ok := f.instances[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.instances, name)
+		_ = json.NewEncoder(w).Encode(&compute.Operation{Name: "op-1", Status: "DONE"})
+	case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/zones/") && strings.HasSuffix(r.URL.Path, "/operations"):
+		parts := strings.Split(r.URL.Path, "/")
+		f.operationZones = append(f.operationZones, parts[len(parts)-2])
+		_ = json.NewEncoder(w).Encode(&compute.OperationList{})
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/instances"):
+		f.listRequests++
+		page, ok := f.instancePages[r.URL.Query().Get("pageToken")]
+		writePage(w, page, ok)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/disks"):
+		f.listRequests++
+		page, ok := f.diskPages[r.URL.Query().Get("pageToken")]
+		writePage(w, page, ok)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/global/networks"):
+		f.listRequests++
+		page, ok := f.networkPages[r.URL.Query().Get("pageToken")]
+		writePage(w, page, ok)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/subnetworks"):
+		f.listRequests++
+		page, ok := f.subnetworkPages[r.URL.Query().Get("pageToken")]
+		writePage(w, page, ok)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newFakeStack(t *testing.T, sshKeys string) (*Stack, *fakeProjectService) {
+	fake := &fakeProjectService{}
+	fake.metadata.Items = []*compute.MetadataItems{
+		{Key: "startup-script", Value: stringPtr("#!/bin/bash")},
+		{Key: sshKeysMetadataKey, Value: stringPtr(sshKeys)},
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	svc, err := compute.NewService(
+		context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()),
+	)
+	require.Nil(t, err)
+
+	return &Stack{GcpConfig: &stacks.GCPConfiguration{ProjectID: "test-project"}, ComputeService: svc}, fake
+}
+
+func stringPtr(in string) *string {
+	return &in
+}
+
+func TestKeyPairsDoNotModifyProjectMetadata(t *testing.T) {
+	stack, fake := newFakeStack(t, "alice:ssh-rsa AAAAalice alice")
+
+	kp, err := stack.CreateKeyPair("kp_bob")
+	require.Nil(t, err)
+	require.NotNil(t, kp)
+	assert.Nil(t, stack.DeleteKeyPair(kp.Name))
+
+	assert.Equal(t, 0, fake.setRequests)
+	assert.Equal(t, "alice:ssh-rsa AAAAalice alice", *fake.metadata.Items[1].Value)
+}
+
+func TestBuildGcpMachineRegistersSSHKeysOnInstance(t *testing.T) {
+	stack, fake := newFakeStack(t, "alice:ssh-rsa AAAAalice alice")
+	template := &abstract.HostTemplate{Name: "n1-standard-2", DiskSize: 20}
+	kp, err := abstract.NewKeyPair("kp_bob")
+	require.Nil(t, err)
+
+	_, xerr := buildGcpMachine(
+		stack.ComputeService, "test-project", "bob", "image-url", "", "europe-west1", "europe-west1-b", "net",
+		"subnet", "", "#!/bin/bash", sshKeyEntry(kp), true, template, "", false, false, false, nil, "",
+	)
+	require.Nil(t, xerr)
+
+	instance := fake.instances["bob"]
+	require.NotNil(t, instance)
+	require.NotNil(t, instance.Metadata)
+	items := map[string]string{}
+	for _, item := range instance.Metadata.Items {
+		items[item.Key] = *item.Value
+	}
+	assert.Equal(t, "#!/bin/bash", items["startup-script"])
+	assert.Equal(t, "true", items[blockProjectSSHKeysMetadataKey])
+	assert.Equal(t, sshKeyEntry(kp), items[sshKeysMetadataKey])
+	assert.True(t, strings.HasPrefix(items[sshKeysMetadataKey], abstract.DefaultUser+":ssh-rsa "))
+	assert.True(t, strings.HasSuffix(items[sshKeysMetadataKey], " "+kp.Name))
+
+	// the project metadata is left untouched
+	assert.Equal(t, 0, fake.setRequests)
+	assert.Equal(t, "alice:ssh-rsa AAAAalice alice", *fake.metadata.Items[1].Value)
+}
+
+func TestInstanceMetadataWithoutKeyPair(t *testing.T) {
+	metadata := instanceMetadata("#!/bin/bash", sshKeyEntry(nil))
+	require.Len(t, metadata.Items, 2)
+	for _, item := range metadata.Items {
+		assert.NotEqual(t, sshKeysMetadataKey, item.Key)
+	}
+}
+
+func TestValidateDiskType(t *testing.T) {
+	for _, dt := range []string{"", "pd-standard", "pd-balanced", "pd-ssd"} {
+		assert.Nil(t, validateDiskType(dt), dt)
+	}
+	err := validateDiskType("local-ssd")
+	require.NotNil(t, err)
+	_, ok := err.(fail.ErrInvalidParameter)
+	assert.True(t, ok)
+}
+
+func TestDiskTypeURL(t *testing.T) {
+	assert.Equal(t, "", diskTypeURL("test-project", "europe-west1-b", ""))
+	assert.Equal(
+		t, "https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west1-b/diskTypes/pd-ssd",
+		diskTypeURL("test-project", "europe-west1-b", "pd-ssd"),
+	)
+}
+
+func TestGetSnapshot(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	fake.snapshots = map[string]*compute.Snapshot{
+		"golden":  {Name: "golden", Status: "READY", DiskSizeGb: 20, SelfLink: "link/golden"},
+		"pending": {Name: "pending", Status: "CREATING"},
+	}
+
+	snapshot, xerr := stack.getSnapshot("golden")
+	require.Nil(t, xerr)
+	assert.Equal(t, int64(20), snapshot.DiskSizeGb)
+	assert.Equal(t, "link/golden", snapshot.SelfLink)
+
+	_, xerr = stack.getSnapshot("pending")
+	_, ok := xerr.(fail.ErrInvalidRequest)
+	assert.True(t, ok)
+
+	_, xerr = stack.getSnapshot("unknown")
+	_, ok = xerr.(fail.ErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestWaitSnapshotStatus(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	fake.snapshots = map[string]*compute.Snapshot{
+		"uploading": {Name: "uploading", Status: "UPLOADING"},
+		"broken":    {Name: "broken", Status: "FAILED"},
+	}
+
+	// the disk is captured once the snapshot is uploading, no need to wait for the upload
+	assert.Nil(t, stack.waitSnapshotStatus("uploading", time.Second, "UPLOADING", "READY"))
+
+	// a failed snapshot is reported at once, without waiting for the timeout
+	start := time.Now()
+	err := stack.waitSnapshotStatus("broken", time.Minute, "READY")
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second)
+
+	fake.snapshots["uploading"].Status = "READY"
+	assert.Nil(t, stack.WaitHostSnapshotReady("uploading"))
+}
+
+func TestApplyGcpQuotas(t *testing.T) {
+	quotas := abstract.NewQuotas()
+	applyGcpQuotas(quotas, []*compute.Quota{
+		{Metric: "INSTANCES", Limit: 24, Usage: 3},
+		{Metric: "CPUS", Limit: 72, Usage: 8},
+		nil,
+		{Metric: "SSD_TOTAL_GB", Limit: 500, Usage: 100},
+	})
+	assert.Equal(t, abstract.Quota{Limit: 24, Used: 3}, quotas.Instances)
+	assert.Equal(t, abstract.Quota{Limit: 72, Used: 8}, quotas.VCPUs)
+	assert.False(t, quotas.RAM.Known())
+	assert.False(t, quotas.Volumes.Known())
+}
+
+func TestListTemplatesReadsAllPages(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Zone = "europe-west1-b"
+	fake.machineTypes = map[string]*compute.MachineTypeList{
+		"":      {Items: []*compute.MachineType{{Id: 1, Name: "n1-standard-1"}}, NextPageToken: "page2"},
+		"page2": {Items: []*compute.MachineType{{Id: 2, Name: "n1-standard-2"}}},
+	}
+
+	templates, xerr := stack.ListTemplates(true)
+	require.Nil(t, xerr)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "n1-standard-1", templates[0].Name)
+	assert.Equal(t, "n1-standard-2", templates[1].Name)
+}
+
+func TestListTemplatesStopsOnRepeatedToken(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Zone = "europe-west1-b"
+	fake.machineTypes = map[string]*compute.MachineTypeList{
+		"":     {Items: []*compute.MachineType{{Id: 1, Name: "n1-standard-1"}}, NextPageToken: "loop"},
+		"loop": {Items: []*compute.MachineType{{Id: 2, Name: "n1-standard-2"}}, NextPageToken: "loop"},
+	}
+
+	templates, xerr := stack.ListTemplates(true)
+	require.Nil(t, xerr)
+	assert.Len(t, templates, 2)
+}
+
+func TestListHostsReadsAllPages(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Zone = "europe-west1-b"
+	fake.instancePages = map[string]*compute.InstanceList{
+		"":      {Items: []*compute.Instance{{Id: 1, Name: "host1"}}, NextPageToken: "page2"},
+		"page2": {Items: []*compute.Instance{{Id: 2, Name: "host2"}}},
+	}
+
+	hosts, xerr := stack.ListHosts()
+	require.Nil(t, xerr)
+	require.Len(t, hosts, 2)
+	assert.Equal(t, "host1", hosts[0].Name)
+	assert.Equal(t, "host2", hosts[1].Name)
+	assert.Equal(t, 2, fake.listRequests)
+}
+
+func TestListVolumesReadsAllPages(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Zone = "europe-west1-b"
+	fake.diskPages = map[string]*compute.DiskList{
+		"":      {Items: []*compute.Disk{{Id: 1, Name: "disk1", Status: "READY"}}, NextPageToken: "page2"},
+		"page2": {Items: []*compute.Disk{{Id: 2, Name: "disk2", Status: "READY"}}},
+	}
+
+	volumes, xerr := stack.ListVolumes()
+	require.Nil(t, xerr)
+	require.Len(t, volumes, 2)
+	assert.Equal(t, "disk1", volumes[0].Name)
+	assert.Equal(t, "disk2", volumes[1].Name)
+	assert.Equal(t, 2, fake.listRequests)
+}
+
+func TestListNetworksReadsAllPages(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Region = "europe-west1"
+	fake.networkPages = map[string]*compute.NetworkList{
+		"":      {Items: []*compute.Network{{Id: 1, Name: "net1"}}, NextPageToken: "page2"},
+		"page2": {Items: []*compute.Network{{Id: 2, Name: "net2"}}},
+	}
+	fake.subnetworkPages = map[string]*compute.SubnetworkList{
+		"":      {Items: []*compute.Subnetwork{{Id: 3, Name: "subnet1"}}, NextPageToken: "page2"},
+		"page2": {Items: []*compute.Subnetwork{{Id: 4, Name: "subnet2"}}},
+	}
+
+	networks, xerr := stack.ListNetworks()
+	require.Nil(t, xerr)
+	require.Len(t, networks, 4)
+	assert.Equal(t, "net2", networks[1].Name)
+	assert.Equal(t, "subnet2", networks[3].Name)
+	assert.Equal(t, 4, fake.listRequests)
+}
+
+func TestGetTemplateUsesCatalogCache(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Zone = "europe-west1-b"
+	stack.catalog = stacks.NewCatalogCache(time.Hour)
+	fake.machineTypes = map[string]*compute.MachineTypeList{
+		"": {Items: []*compute.MachineType{{Id: 1, Name: "n1-standard-1"}, {Id: 2, Name: "n1-standard-2"}}},
+	}
+
+	template, xerr := stack.GetTemplate("1")
+	require.Nil(t, xerr)
+	assert.Equal(t, "n1-standard-1", template.Name)
+	assert.Equal(t, 1, fake.machineTypeRequests)
+
+	// Within the TTL, the templates are not listed again
+	template, xerr = stack.GetTemplate("2")
+	require.Nil(t, xerr)
+	assert.Equal(t, "n1-standard-2", template.Name)
+	_, xerr = stack.ListTemplates(true)
+	require.Nil(t, xerr)
+	assert.Equal(t, 1, fake.machineTypeRequests)
+
+	stack.RefreshCatalog()
+	_, xerr = stack.GetTemplate("1")
+	require.Nil(t, xerr)
+	assert.Equal(t, 2, fake.machineTypeRequests)
+}
+
+func TestScheduling(t *testing.T) {
+	assert.Nil(t, scheduling(false, false))
+	assert.False(t, isSpot(scheduling(false, false)))
+
+	sched := scheduling(true, false)
+	require.NotNil(t, sched)
+	assert.True(t, sched.Preemptible)
+	assert.Equal(t, "TERMINATE", sched.OnHostMaintenance)
+	if assert.NotNil(t, sched.AutomaticRestart) {
+		assert.False(t, *sched.AutomaticRestart)
+	}
+	assert.True(t, isSpot(sched))
+
+	sched = scheduling(false, true)
+	require.NotNil(t, sched)
+	assert.Equal(t, "TERMINATE", sched.OnHostMaintenance)
+	assert.False(t, isSpot(sched))
+}
+
+func TestWasPreemptedLooksInInstanceZone(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Zone = "europe-west1-b"
+
+	instance := &compute.Instance{
+		Id:   1,
+		Name: "spot",
+		Zone: "https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west1-c",
+	}
+	preempted, xerr := stack.wasPreempted(instance)
+	require.Nil(t, xerr)
+	assert.False(t, preempted)
+	assert.Equal(t, []string{"europe-west1-c"}, fake.operationZones)
+}
+
+func TestValidateConfidentialVM(t *testing.T) {
+	capable := &abstract.Image{Name: "ubuntu-2004", ConfidentialComputeCapable: true}
+	n2d := &abstract.HostTemplate{Name: "n2d-standard-2"}
+
+	assert.Nil(t, validateConfidentialVM(n2d, capable))
+	assert.Nil(t, validateConfidentialVM(n2d, nil))
+
+	err := validateConfidentialVM(&abstract.HostTemplate{Name: "n1-standard-2"}, capable)
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrInvalidRequest{}, err)
+	assert.Contains(t, err.Error(), "n1-standard-2")
+
+	err = validateConfidentialVM(n2d, &abstract.Image{Name: "centos-6"})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "centos-6")
+}
+
+func TestBuildGcpMachineShieldedAndConfidential(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	template := &abstract.HostTemplate{Name: "n2d-standard-2", DiskSize: 20}
+
+	_, xerr := buildGcpMachine(
+		stack.ComputeService, "test-project", "standard", "image-url", "", "europe-west1", "europe-west1-b", "net",
+		"subnet", "", "#!/bin/bash", "", true, template, "", false, false, false, nil, "",
+	)
+	require.Nil(t, xerr)
+	_, xerr = buildGcpMachine(
+		stack.ComputeService, "test-project", "confidential", "image-url", "", "europe-west1", "europe-west1-b", "net",
+		"subnet", "", "#!/bin/bash", "", true, template, "", false, true, true, nil, "",
+	)
+	require.Nil(t, xerr)
+
+	standard := fake.instances["standard"]
+	require.NotNil(t, standard)
+	assert.Nil(t, standard.ShieldedInstanceConfig)
+	assert.Nil(t, standard.ConfidentialInstanceConfig)
+	assert.Nil(t, standard.Scheduling)
+
+	confidential := fake.instances["confidential"]
+	require.NotNil(t, confidential)
+	require.NotNil(t, confidential.ShieldedInstanceConfig)
+	assert.True(t, confidential.ShieldedInstanceConfig.EnableSecureBoot)
+	assert.True(t, confidential.ShieldedInstanceConfig.EnableVtpm)
+	assert.True(t, confidential.ShieldedInstanceConfig.EnableIntegrityMonitoring)
+	require.NotNil(t, confidential.ConfidentialInstanceConfig)
+	assert.True(t, confidential.ConfidentialInstanceConfig.EnableConfidentialCompute)
+	require.NotNil(t, confidential.Scheduling)
+	assert.Equal(t, "TERMINATE", confidential.Scheduling.OnHostMaintenance)
+}
+
+func TestBuildGcpMachineSetsTagsAsLabels(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	template := &abstract.HostTemplate{Name: "n1-standard-2", DiskSize: 20}
+
+	_, xerr := buildGcpMachine(
+		stack.ComputeService, "test-project", "tagged", "image-url", "", "europe-west1", "europe-west1-b", "net",
+		"subnet", "", "#!/bin/bash", "", true, template, "", false, false, false,
+		map[string]string{"CostCenter": "R&D 42", "owner": "ops"}, "",
+	)
+	require.Nil(t, xerr)
+
+	tagged := fake.instances["tagged"]
+	require.NotNil(t, tagged)
+	assert.Equal(t, map[string]string{"costcenter": "r_d_42", "owner": "ops"}, tagged.Labels)
+}
+
+func TestBuildGcpMachineSetsPlacementPolicy(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.GcpConfig.Region = "europe-west1"
+	template := &abstract.HostTemplate{Name: "n1-standard-2", DiskSize: 20}
+
+	policy, xerr := stack.placementPolicy("gw-net1", true)
+	require.Nil(t, xerr)
+	assert.Equal(t, "https://www.googleapis.com/compute/v1/projects/test-project/regions/europe-west1/resourcePolicies/safescale-gw-net1", policy)
+	require.Contains(t, fake.resourcePolicies, "safescale-gw-net1")
+	assert.Equal(t, int64(gcpSpreadAvailabilityDomains), fake.resourcePolicies["safescale-gw-net1"].GroupPlacementPolicy.AvailabilityDomainCount)
+
+	// the existing policy is reused, but not with the other placement
+	again, xerr := stack.placementPolicy("gw-net1", true)
+	require.Nil(t, xerr)
+	assert.Equal(t, policy, again)
+	_, xerr = stack.placementPolicy("gw-net1", false)
+	assert.IsType(t, fail.ErrInvalidRequest{}, xerr)
+
+	_, xerr = buildGcpMachine(
+		stack.ComputeService, "test-project", "gw-net1", "image-url", "", "europe-west1", "europe-west1-b", "net",
+		"subnet", "", "#!/bin/bash", "", true, template, "", false, false, false, nil, policy,
+	)
+	require.Nil(t, xerr)
+	require.NotNil(t, fake.instances["gw-net1"])
+	assert.Equal(t, []string{policy}, fake.instances["gw-net1"].ResourcePolicies)
+}
+
+func TestPlacementPolicyName(t *testing.T) {
+	assert.Equal(t, "safescale-db-ha", placementPolicyName("DB_HA"))
+	assert.Len(t, placementPolicyName(strings.Repeat("a", 80)), 63)
+}
+
+func TestInspectHostRecordsZone(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	fake.instances = map[string]*compute.Instance{
+		"web": {
+			Name:   "web",
+			Status: "RUNNING",
+			Zone:   "https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west1-b",
+		},
+	}
+
+	host, xerr := stack.InspectHost("web")
+	require.Nil(t, xerr)
+	xerr = host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			assert.Equal(t, "europe-west1-b", clonable.(*propsv1.HostDescription).AvailabilityZone)
+			return nil
+		},
+	)
+	require.Nil(t, xerr)
+}
+
+func TestPreemptedSince(t *testing.T) {
+	ops := []*compute.Operation{{InsertTime: "2020-05-04T10:00:00.000-07:00"}}
+	assert.True(t, preemptedSince(ops, "2020-05-04T08:00:00.000-07:00"))
+	assert.False(t, preemptedSince(ops, "2020-05-04T11:00:00.000-07:00"))
+	assert.False(t, preemptedSince(nil, "2020-05-04T08:00:00.000-07:00"))
+	assert.True(t, preemptedSince(ops, ""))
+}
+
+func TestLastLines(t *testing.T) {
+	text := "one\ntwo\nthree\n"
+	assert.Equal(t, text, lastLines(text, 0))
+	assert.Equal(t, "three\n", lastLines(text, 1))
+	assert.Equal(t, "two\nthree\n", lastLines(text, 2))
+	assert.Equal(t, text, lastLines(text, 5))
+	assert.Equal(t, "three", lastLines("one\ntwo\nthree", 1))
+}
+
+func TestDeleteHostSucceedsWhenInstanceIsNotFoundAnymore(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	stack.hostNames = newHostNameIndex()
+	fake.instances = map[string]*compute.Instance{"host1": {Id: 1, Name: "host1"}}
+	stack.hostNames.set("host1", "1")
+
+	err := stack.DeleteHost("host1")
+	require.Nil(t, err)
+
+	assert.Empty(t, fake.instances)
+	_, found, _ := stack.hostNames.get("host1")
+	assert.False(t, found)
+}
+
+func TestDeleteHostReportsAlreadyDeletedHostAsNotFound(t *testing.T) {
+	stack, _ := newFakeStack(t, "")
+	stack.hostNames = newHostNameIndex()
+	stack.hostNames.set("host1", "1")
+
+	err := stack.DeleteHost("host1")
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrNotFound{}, err)
+
+	_, found, _ := stack.hostNames.get("host1")
+	assert.False(t, found)
+}
+
+func TestListImagesFailsWhenAllProjectsFail(t *testing.T) {
+	stack, _ := newFakeStack(t, "")
+
+	images, xerr := stack.listImages()
+	assert.NotNil(t, xerr)
+	assert.Empty(t, images)
+}
+
+func TestListImagesSkipsFailingProjects(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	fake.images = map[string]*compute.ImageList{
+		"debian-cloud":    {Items: []*compute.Image{{Id: 1, Name: "debian-10-buster-v20200910", Family: "debian-10"}}},
+		"ubuntu-os-cloud": {Items: []*compute.Image{{Id: 2, Name: "ubuntu-1804-bionic-v20200923", Family: "ubuntu-1804-lts"}}},
+	}
+
+	images, xerr := stack.listImages()
+	require.Nil(t, xerr)
+	if assert.Len(t, images, 2) {
+		assert.Equal(t, "debian-10-buster-v20200910", images[0].Name)
+		assert.Equal(t, "ubuntu-1804-bionic-v20200923", images[1].Name)
+	}
+}
+
+func TestListImagesReturnsEmptyListWithoutImages(t *testing.T) {
+	stack, fake := newFakeStack(t, "")
+	fake.images = map[string]*compute.ImageList{"debian-cloud": {}}
+
+	images, xerr := stack.listImages()
+	assert.Nil(t, xerr)
+	assert.NotNil(t, images)
+	assert.Empty(t, images)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"sync"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+)
+
+// hostNameIndex indexes the IDs of the hosts of the project by name, to resolve names without listing all the hosts
+// It is populated by ListHosts and maintained by CreateHost and DeleteHost; changes made outside of SafeScale are
+// detected when an entry is used
+// A nil *hostNameIndex is valid and never populated
+type hostNameIndex struct {
+	lock      sync.RWMutex
+	populated bool
+	byName    map[string]string
+}
+
+func newHostNameIndex() *hostNameIndex {
+	return &hostNameIndex{byName: map[string]string{}}
+}
+
+// get returns the ID of the host named 'name'; 'populated' tells if the index has been populated by a listing of the hosts
+func (i *hostNameIndex) get(name string) (id string, found bool, populated bool) {
+	if i == nil {
+		return "", false, false
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	id, found = i.byName[name]
+	return id, found, i.populated
+}
+
+// reset replaces the content of the index with 'hosts'
+func (i *hostNameIndex) reset(hosts []*abstract.Host) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.byName = make(map[string]string, len(hosts))
+	for _, h := range hosts {
+		i.byName[h.Name] = h.ID
+	}
+	i.populated = true
+}
+
+// set records the host 'name' with ID 'id'
+func (i *hostNameIndex) set(name, id string) {
+	if i == nil || name == "" {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.byName[name] = id
+}
+
+// remove removes the host identified by 'ref', which can be a name or an ID
+func (i *hostNameIndex) remove(ref string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	delete(i.byName, ref)
+	for name, id := range i.byName {
+		if id == ref {
+			delete(i.byName, name)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// fakeInstancesService emulates the listing and the retrieval (by name or ID) of instances
+type fakeInstancesService struct {
+	lock      sync.Mutex
+	instances map[string]*compute.Instance
+	lists     int
+}
+
+func (f *fakeInstancesService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	if strings.HasSuffix(path, "/instances") {
+		f.lists++
+		list := &compute.InstanceList{}
+		for _, i := range f.instances {
+			list.Items = append(list.Items, i)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+		return
+	}
+	ref := path[strings.LastIndex(path, "/")+1:]
+	for _, i := range f.instances {
+		if i.Name == ref || strconv.FormatUint(i.Id, 10) == ref {
+			_ = json.NewEncoder(w).Encode(i)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+}
+
+func (f *fakeInstancesService) put(id uint64, name string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.instances[name] = &compute.Instance{Id: id, Name: name, Status: "RUNNING"}
+}
+
+func (f *fakeInstancesService) drop(name string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	delete(f.instances, name)
+}
+
+func TestGetHostByName(t *testing.T) {
+	fake := &fakeInstancesService{instances: map[string]*compute.Instance{}}
+	fake.put(1, "host1")
+	fake.put(2, "host2")
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	svc, err := compute.NewService(
+		context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()),
+	)
+	require.Nil(t, err)
+	stack := &Stack{
+		GcpConfig:      &stacks.GCPConfiguration{ProjectID: fakeProject, Zone: fakeZone},
+		ComputeService: svc,
+		hostNames:      newHostNameIndex(),
+	}
+
+	// First lookup populates the index
+	host, err := stack.GetHostByName("host1")
+	require.Nil(t, err)
+	assert.Equal(t, "1", host.ID)
+	assert.Equal(t, 1, fake.lists)
+
+	// Following lookups, found or not, don't list the hosts anymore
+	host, err = stack.GetHostByName("host2")
+	require.Nil(t, err)
+	assert.Equal(t, "2", host.ID)
+	_, err = stack.GetHostByName("host3")
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	fake.put(3, "host3")
+	host, err = stack.GetHostByName("host3")
+	require.Nil(t, err)
+	assert.Equal(t, "3", host.ID)
+	assert.Equal(t, 1, fake.lists)
+
+	// Host deleted then recreated with the same name outside of SafeScale
+	fake.drop("host1")
+	fake.put(4, "host1")
+	host, err = stack.GetHostByName("host1")
+	require.Nil(t, err)
+	assert.Equal(t, "4", host.ID)
+	assert.Equal(t, 2, fake.lists)
+
+	// Host deleted outside of SafeScale
+	fake.drop("host2")
+	_, err = stack.GetHostByName("host2")
+	assert.IsType(t, fail.ErrNotFound{}, err)
+	id, found, _ := stack.hostNames.get("host2")
+	assert.False(t, found, id)
+}
+
+func TestHostNameIndexRemove(t *testing.T) {
+	index := newHostNameIndex()
+	index.set("host1", "1")
+	index.set("host2", "2")
+
+	index.remove("1")
+	index.remove("host2")
+	_, found, populated := index.get("host1")
+	assert.False(t, found)
+	assert.False(t, populated)
+	_, found, _ = index.get("host2")
+	assert.False(t, found)
+
+	var nilIndex *hostNameIndex
+	nilIndex.set("host1", "1")
+	_, found, _ = nilIndex.get("host1")
+	assert.False(t, found)
+}
@@ -19,6 +19,7 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"strconv"
 	"strings"
 
@@ -272,7 +273,7 @@ func (s *Stack) ListNetworks() ([]*abstract.Network, fail.Error) {
 	compuService := s.ComputeService
 
 	token := ""
-	for paginate := true; paginate; {
+	for page, paginate := 1, true; paginate; page++ {
 		resp, err := compuService.Networks.List(s.GcpConfig.ProjectID).PageToken(token).Do()
 		if err != nil {
 			return networks, fail.Errorf(fmt.Sprintf("cannot list networks ...: %s", err), err)
@@ -286,12 +287,11 @@ func (s *Stack) ListNetworks() ([]*abstract.Network, fail.Error) {
 
 			networks = append(networks, newNet)
 		}
-		token := resp.NextPageToken
-		paginate = token != ""
+		token, paginate = nextPageToken(token, resp.NextPageToken, page)
 	}
 
 	token = ""
-	for paginate := true; paginate; {
+	for page, paginate := 1, true; paginate; page++ {
 		resp, err := compuService.Subnetworks.List(s.GcpConfig.ProjectID, s.GcpConfig.Region).PageToken(token).Do()
 		if err != nil {
 			return networks, fail.Errorf(fmt.Sprintf("cannot list subnetworks ...: %s", err), err)
@@ -305,8 +305,7 @@ func (s *Stack) ListNetworks() ([]*abstract.Network, fail.Error) {
 
 			networks = append(networks, newNet)
 		}
-		token := resp.NextPageToken
-		paginate = token != ""
+		token, paginate = nextPageToken(token, resp.NextPageToken, page)
 	}
 
 	return networks, nil
@@ -424,13 +423,15 @@ func (s *Stack) CreateGateway(req abstract.GatewayRequest, sizing *abstract.Sizi
 	}
 
 	hostReq := abstract.HostRequest{
-		ImageID:      req.ImageID,
-		KeyPair:      req.KeyPair,
-		HostName:     req.Name,
-		ResourceName: gwname,
-		TemplateID:   req.TemplateID,
-		Networks:     []*abstract.Network{req.Network},
-		PublicIP:     true,
+		ImageID:       req.ImageID,
+		KeyPair:       req.KeyPair,
+		HostName:      req.Name,
+		ResourceName:  gwname,
+		TemplateID:    req.TemplateID,
+		Networks:      []*abstract.Network{req.Network},
+		PublicIP:      true,
+		AffinityGroup: req.AffinityGroup,
+		AntiAffinity:  req.AntiAffinity,
 	}
 
 	if sizing != nil && sizing.MinDiskSize > 0 {
@@ -467,28 +468,100 @@ func (s *Stack) DeleteGateway(ref string) error {
 	return s.DeleteHost(ref)
 }
 
-// CreateVIP creates a private virtual IP
-// If public is set to true,
-func (s *Stack) CreateVIP(networkID string, description string) (*abstract.VirtualIP, fail.Error) {
-	return nil, fail.NotImplementedError("CreateVIP() not implemented yet") // FIXME: Technical debt
+// customRoutePrefix returns the prefix of the names of the custom routes injected in the subnetwork 'subnetName'
+func customRoutePrefix(networkName string, subnetName string) string {
+	return fmt.Sprintf("%s-%s-rt-", networkName, subnetName)
 }
 
-// AddPublicIPToVIP adds a public IP to VIP
-func (s *Stack) AddPublicIPToVIP(vip *abstract.VirtualIP) error {
-	return fail.NotImplementedError("AddPublicIPToVIP() not implemented yet") // FIXME: Technical debt
+// customRouteName returns the name of the custom route 'route' of the subnetwork; the name changes with the
+// destination or the next hop, so a modified route is replaced instead of updated (GCP routes are immutable)
+func customRouteName(prefix string, route abstract.NetworkRoute) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(route.Destination + "/" + route.NextHop))
+	return fmt.Sprintf("%s%08x", prefix, h.Sum32())
 }
 
-// BindHostToVIP makes the host passed as parameter an allowed "target" of the VIP
-func (s *Stack) BindHostToVIP(vip *abstract.VirtualIP, hostID string) error {
-	return fail.NotImplementedError("BindHostToVIP() not implemented yet") // FIXME: Technical debt
-}
+// SetNetworkRoutes replaces the custom routes of the subnetwork identified by id
+// The routes apply to the instances of the subnetwork without public IP, as does the NAT route through the gateway
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	theNetwork, err := s.GetNetwork(id)
+	if err != nil {
+		return err
+	}
+
+	compuService := s.ComputeService
+	prefix := customRoutePrefix(s.GcpConfig.NetworkName, theNetwork.Name)
+	wanted := map[string]abstract.NetworkRoute{}
+	for _, r := range routes {
+		wanted[customRouteName(prefix, r)] = r
+	}
 
-// UnbindHostFromVIP removes the bind between the VIP and a host
-func (s *Stack) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) error {
-	return fail.NotImplementedError("UnbindHostFromVIP() not implemented yet") // FIXME: Technical debt
+	existing := map[string]bool{}
+	token := ""
+	for paginate := true; paginate; {
+		resp, err := compuService.Routes.List(s.GcpConfig.ProjectID).Filter(fmt.Sprintf("name eq %s.*", prefix)).PageToken(token).Do()
+		if err != nil {
+			return err
+		}
+		for _, r := range resp.Items {
+			existing[r.Name] = true
+		}
+		token = resp.NextPageToken
+		paginate = token != ""
+	}
+
+	wait := func(opp *compute.Operation) error {
+		oco := OpContext{
+			Operation:    opp,
+			ProjectID:    s.GcpConfig.ProjectID,
+			Service:      compuService,
+			DesiredState: "DONE",
+		}
+		return waitUntilOperationIsSuccessfulOrTimeout(oco, temporal.GetMinDelay(), 2*temporal.GetContextTimeout())
+	}
+
+	for name := range existing {
+		if _, ok := wanted[name]; ok {
+			continue
+		}
+		opp, err := compuService.Routes.Delete(s.GcpConfig.ProjectID, name).Do()
+		if err != nil {
+			return err
+		}
+		err = wait(opp)
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, r := range wanted {
+		if existing[name] {
+			continue
+		}
+		route := &compute.Route{
+			DestRange: r.Destination,
+			Name:      name,
+			Network: fmt.Sprintf(
+				"https://www.googleapis.com/compute/v1/projects/%s/global/networks/%s", s.GcpConfig.ProjectID,
+				s.GcpConfig.NetworkName,
+			),
+			NextHopIp: r.NextHop,
+			Priority:  900,
+			Tags:      []string{fmt.Sprintf("no-ip-%s", theNetwork.Name)},
+		}
+		opp, err := compuService.Routes.Insert(s.GcpConfig.ProjectID, route).Do()
+		if err != nil {
+			return err
+		}
+		err = wait(opp)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// DeleteVIP deletes the port corresponding to the VIP
-func (s *Stack) DeleteVIP(vip *abstract.VirtualIP) error {
-	return fail.NotImplementedError("DeleteVIP() not implemented yet") // FIXME: Technical debt
+// UpdateNetworkDNSServers is not implemented for gcp
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	return fail.NotImplementedError("UpdateNetworkDNSServers() not implemented for gcp")
 }
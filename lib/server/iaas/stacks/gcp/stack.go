@@ -20,6 +20,9 @@ type Stack struct {
 	GcpConfig   *stacks.GCPConfiguration
 
 	ComputeService *compute.Service
+
+	hostNames *hostNameIndex
+	catalog   *stacks.CatalogCache
 }
 
 // GetConfigurationOptions ...
@@ -38,6 +41,8 @@ func New(auth stacks.AuthenticationOptions, localCfg stacks.GCPConfiguration, cf
 		Config:      &cfg,
 		AuthOptions: &auth,
 		GcpConfig:   &localCfg,
+		hostNames:   newHostNameIndex(),
+		catalog:     stacks.NewCatalogCache(cfg.CatalogCacheTTL),
 	}
 
 	d1, err := json.MarshalIndent(localCfg, "", "  ")
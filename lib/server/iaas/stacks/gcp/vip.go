@@ -0,0 +1,458 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+	"github.com/CS-SI/SafeScale/lib/utils/temporal"
+)
+
+// On GCP, a VIP is implemented this way:
+// - the private IP is an internal static address reserved in the subnetwork, and bound to a host as an alias IP
+//   range (/32) of its network interface (GCP equivalent of OpenStack allowed address pairs)
+// - the public IP is an external static address, forwarded to the host holding the VIP by forwarding rules (one per
+//   protocol carried) targeting a target instance (protocol forwarding)
+// An alias IP range can be used by only one instance at a time: failover of the VIP to another host needs to
+// unbind it from the current host before binding it to the new one.
+
+// vipAddressName returns the name of the internal address reserved for the VIP 'name' of the network; a network may
+// hold several VIPs (gateways, control plane of a cluster, ...), the name of the VIP being hashed to fit in the
+// naming constraints of GCP
+func vipAddressName(networkID string, name string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return fmt.Sprintf("vip-%s-%08x", networkID, h.Sum32())
+}
+
+// vipPublicAddressName returns the name of the external address reserved for the VIP
+func vipPublicAddressName(vip *abstract.VirtualIP) string {
+	return vip.Name + "-public"
+}
+
+// vipForwardedProtocols lists the protocols of the traffic to the public IP of the VIP forwarded to the bound host
+var vipForwardedProtocols = []string{"TCP", "UDP", "ICMP"}
+
+// vipForwardingRuleName returns the name of the forwarding rule sending the 'protocol' traffic to the public IP of the
+// VIP to the bound host
+func vipForwardingRuleName(vip *abstract.VirtualIP, protocol string) string {
+	return vip.Name + "-fr-" + strings.ToLower(protocol)
+}
+
+// vipTargetInstanceName returns the name of the target instance corresponding to the host bound to the VIP
+func vipTargetInstanceName(vip *abstract.VirtualIP, instanceName string) string {
+	return vip.Name + "-" + instanceName
+}
+
+// isNotFound tells if err is a 404 returned by GCP
+func isNotFound(err error) bool {
+	if gerr, ok := err.(*googleapi.Error); ok {
+		return gerr.Code == 404
+	}
+	return false
+}
+
+// waitOperation waits until the operation is done
+func (s *Stack) waitOperation(op *compute.Operation) error {
+	oco := OpContext{
+		Operation:    op,
+		ProjectID:    s.GcpConfig.ProjectID,
+		Service:      s.ComputeService,
+		DesiredState: "DONE",
+	}
+	return waitUntilOperationIsSuccessfulOrTimeout(oco, temporal.GetMinDelay(), 2*temporal.GetContextTimeout())
+}
+
+// getSubnetwork returns the subnetwork identified by ref (id or name)
+func (s *Stack) getSubnetwork(ref string) (*compute.Subnetwork, error) {
+	token := ""
+	for paginate := true; paginate; {
+		resp, err := s.ComputeService.Subnetworks.List(s.GcpConfig.ProjectID, s.GcpConfig.Region).PageToken(token).Do()
+		if err != nil {
+			return nil, fail.Errorf(fmt.Sprintf("cannot list subnetworks ...: %s", err), err)
+		}
+		for _, subnet := range resp.Items {
+			if strconv.FormatUint(subnet.Id, 10) == ref || subnet.Name == ref {
+				return subnet, nil
+			}
+		}
+		token = resp.NextPageToken
+		paginate = token != ""
+	}
+	return nil, abstract.ResourceNotFoundError("network", ref)
+}
+
+// CreateVIP creates a private virtual IP
+func (s *Stack) CreateVIP(networkID string, description string) (*abstract.VirtualIP, fail.Error) {
+	if networkID == "" {
+		return nil, fail.InvalidParameterError("networkID", "cannot be empty string")
+	}
+
+	subnet, err := s.getSubnetwork(networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := vipAddressName(networkID, description)
+	op, err := s.ComputeService.Addresses.Insert(
+		s.GcpConfig.ProjectID, s.GcpConfig.Region, &compute.Address{
+			Name:        name,
+			Description: description,
+			AddressType: "INTERNAL",
+			Subnetwork:  subnet.SelfLink,
+		},
+	).Do()
+	if err != nil {
+		return nil, fail.Wrap(err, "error creating VIP")
+	}
+	if err = s.waitOperation(op); err != nil {
+		return nil, fail.Wrap(err, "error creating VIP")
+	}
+
+	address, err := s.ComputeService.Addresses.Get(s.GcpConfig.ProjectID, s.GcpConfig.Region, name).Do()
+	if err != nil {
+		return nil, fail.Wrap(err, "error creating VIP")
+	}
+
+	vip := abstract.NewVirtualIP()
+	vip.ID = name
+	vip.Name = name
+	vip.NetworkID = networkID
+	vip.PrivateIP = address.Address
+	return vip, nil
+}
+
+// AddPublicIPToVIP adds a public IP to VIP
+// The public IP is forwarded to the host bound to the VIP (see BindHostToVIP).
+func (s *Stack) AddPublicIPToVIP(vip *abstract.VirtualIP) error {
+	if vip == nil {
+		return fail.InvalidParameterError("vip", "cannot be nil")
+	}
+	if vip.PublicIPID != "" {
+		return nil
+	}
+
+	name := vipPublicAddressName(vip)
+	op, err := s.ComputeService.Addresses.Insert(
+		s.GcpConfig.ProjectID, s.GcpConfig.Region, &compute.Address{
+			Name:        name,
+			AddressType: "EXTERNAL",
+		},
+	).Do()
+	if err != nil {
+		return fail.Wrap(err, "error adding public IP to VIP")
+	}
+	if err = s.waitOperation(op); err != nil {
+		return fail.Wrap(err, "error adding public IP to VIP")
+	}
+
+	address, err := s.ComputeService.Addresses.Get(s.GcpConfig.ProjectID, s.GcpConfig.Region, name).Do()
+	if err != nil {
+		return fail.Wrap(err, "error adding public IP to VIP")
+	}
+	vip.PublicIP = address.Address
+	vip.PublicIPID = name
+
+	// If a host already holds the VIP, forwards the public IP to it
+	if len(vip.Hosts) > 0 {
+		instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, vip.Hosts[0]).Do()
+		if err != nil {
+			return err
+		}
+		return s.forwardPublicIPToInstance(vip, instance)
+	}
+	return nil
+}
+
+// BindHostToVIP makes the host passed as parameter an allowed "target" of the VIP
+// The private IP of the VIP is added as alias IP range to the network interface of the host in the network of the VIP.
+func (s *Stack) BindHostToVIP(vip *abstract.VirtualIP, hostID string) error {
+	if vip == nil {
+		return fail.InvalidParameterError("vip", "cannot be nil")
+	}
+	if hostID == "" {
+		return fail.InvalidParameterError("hostID", "cannot be empty string")
+	}
+
+	instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, hostID).Do()
+	if err != nil {
+		return err
+	}
+	nic, err := s.findVIPNetworkInterface(vip, instance)
+	if err != nil {
+		return err
+	}
+
+	aliasRange := vip.PrivateIP + "/32"
+	found := false
+	for _, r := range nic.AliasIpRanges {
+		if r.IpCidrRange == aliasRange {
+			found = true
+			break
+		}
+	}
+	if !found {
+		ranges := append(nic.AliasIpRanges, &compute.AliasIpRange{IpCidrRange: aliasRange})
+		if err = s.updateAliasIPRanges(instance, nic, ranges); err != nil {
+			return err
+		}
+	}
+
+	if vip.PublicIPID != "" {
+		if err = s.forwardPublicIPToInstance(vip, instance); err != nil {
+			return err
+		}
+	}
+
+	for _, h := range vip.Hosts {
+		if h == hostID {
+			return nil
+		}
+	}
+	vip.Hosts = append(vip.Hosts, hostID)
+	return nil
+}
+
+// UnbindHostFromVIP removes the bind between the VIP and a host
+func (s *Stack) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) error {
+	if vip == nil {
+		return fail.InvalidParameterError("vip", "cannot be nil")
+	}
+	if hostID == "" {
+		return fail.InvalidParameterError("hostID", "cannot be empty string")
+	}
+
+	instance, err := s.ComputeService.Instances.Get(s.GcpConfig.ProjectID, s.GcpConfig.Zone, hostID).Do()
+	if err != nil {
+		return err
+	}
+
+	if vip.PublicIPID != "" {
+		if err = s.deleteVIPForwarding(vip, instance.Name); err != nil {
+			return err
+		}
+	}
+
+	nic, err := s.findVIPNetworkInterface(vip, instance)
+	if err != nil {
+		return err
+	}
+	aliasRange := vip.PrivateIP + "/32"
+	var ranges []*compute.AliasIpRange
+	for _, r := range nic.AliasIpRanges {
+		if r.IpCidrRange != aliasRange {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) != len(nic.AliasIpRanges) {
+		if err = s.updateAliasIPRanges(instance, nic, ranges); err != nil {
+			return err
+		}
+	}
+
+	var hosts []string
+	for _, h := range vip.Hosts {
+		if h != hostID {
+			hosts = append(hosts, h)
+		}
+	}
+	vip.Hosts = hosts
+	return nil
+}
+
+// DeleteVIP deletes the addresses corresponding to the VIP, after having unbound the hosts
+func (s *Stack) DeleteVIP(vip *abstract.VirtualIP) error {
+	if vip == nil {
+		return fail.InvalidParameterError("vip", "cannot be nil")
+	}
+
+	for _, h := range append([]string{}, vip.Hosts...) {
+		err := s.UnbindHostFromVIP(vip, h)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	if vip.PublicIPID != "" {
+		if err := s.deleteAddress(vip.PublicIPID); err != nil {
+			return err
+		}
+		vip.PublicIP = ""
+		vip.PublicIPID = ""
+	}
+	return s.deleteAddress(vip.ID)
+}
+
+// findVIPNetworkInterface returns the network interface of the instance connected to the network of the VIP
+func (s *Stack) findVIPNetworkInterface(vip *abstract.VirtualIP, instance *compute.Instance) (*compute.NetworkInterface, error) {
+	if len(instance.NetworkInterfaces) == 0 {
+		return nil, fail.NotFoundError(fmt.Sprintf("host '%s' has no network interface", instance.Name))
+	}
+	if len(instance.NetworkInterfaces) == 1 {
+		return instance.NetworkInterfaces[0], nil
+	}
+
+	subnet, err := s.getSubnetwork(vip.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	for _, nic := range instance.NetworkInterfaces {
+		if nic.Subnetwork == subnet.SelfLink {
+			return nic, nil
+		}
+	}
+	return nil, fail.NotFoundError(
+		fmt.Sprintf("host '%s' has no network interface in network '%s'", instance.Name, subnet.Name),
+	)
+}
+
+// updateAliasIPRanges replaces the alias IP ranges of the network interface of the instance
+func (s *Stack) updateAliasIPRanges(instance *compute.Instance, nic *compute.NetworkInterface, ranges []*compute.AliasIpRange) error {
+	update := &compute.NetworkInterface{
+		AliasIpRanges:   ranges,
+		Fingerprint:     nic.Fingerprint,
+		ForceSendFields: []string{"AliasIpRanges"},
+	}
+	op, err := s.ComputeService.Instances.UpdateNetworkInterface(
+		s.GcpConfig.ProjectID, s.GcpConfig.Zone, instance.Name, nic.Name, update,
+	).Do()
+	if err != nil {
+		return fail.Wrap(err, fmt.Sprintf("failed to update alias IP ranges of host '%s'", instance.Name))
+	}
+	return s.waitOperation(op)
+}
+
+// forwardPublicIPToInstance makes the forwarding rule of the public IP of the VIP target the instance
+func (s *Stack) forwardPublicIPToInstance(vip *abstract.VirtualIP, instance *compute.Instance) error {
+	projectID, zone := s.GcpConfig.ProjectID, s.GcpConfig.Zone
+
+	targetName := vipTargetInstanceName(vip, instance.Name)
+	target, err := s.ComputeService.TargetInstances.Get(projectID, zone, targetName).Do()
+	if err != nil {
+		if !isNotFound(err) {
+			return err
+		}
+		op, err := s.ComputeService.TargetInstances.Insert(
+			projectID, zone, &compute.TargetInstance{
+				Name:      targetName,
+				Instance:  instance.SelfLink,
+				NatPolicy: "NO_NAT",
+			},
+		).Do()
+		if err != nil {
+			return fail.Wrap(err, "failed to create target instance")
+		}
+		if err = s.waitOperation(op); err != nil {
+			return err
+		}
+		target, err = s.ComputeService.TargetInstances.Get(projectID, zone, targetName).Do()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, protocol := range vipForwardedProtocols {
+		if err = s.forwardPublicIPProtocol(vip, protocol, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forwardPublicIPProtocol makes the forwarding rule of the 'protocol' traffic to the public IP of the VIP target the
+// target instance
+func (s *Stack) forwardPublicIPProtocol(vip *abstract.VirtualIP, protocol string, target *compute.TargetInstance) error {
+	projectID, region := s.GcpConfig.ProjectID, s.GcpConfig.Region
+
+	ruleName := vipForwardingRuleName(vip, protocol)
+	rule, err := s.ComputeService.ForwardingRules.Get(projectID, region, ruleName).Do()
+	if err != nil {
+		if !isNotFound(err) {
+			return err
+		}
+		op, err := s.ComputeService.ForwardingRules.Insert(
+			projectID, region, &compute.ForwardingRule{
+				Name:       ruleName,
+				IPAddress:  vip.PublicIP,
+				IPProtocol: protocol,
+				Target:     target.SelfLink,
+			},
+		).Do()
+		if err != nil {
+			return fail.Wrap(err, fmt.Sprintf("failed to create %s forwarding rule", protocol))
+		}
+		return s.waitOperation(op)
+	}
+	if rule.Target == target.SelfLink {
+		return nil
+	}
+	op, err := s.ComputeService.ForwardingRules.SetTarget(
+		projectID, region, ruleName, &compute.TargetReference{Target: target.SelfLink},
+	).Do()
+	if err != nil {
+		return fail.Wrap(err, fmt.Sprintf("failed to update %s forwarding rule", protocol))
+	}
+	return s.waitOperation(op)
+}
+
+// deleteVIPForwarding removes the forwarding rules of the public IP of the VIP and the target instance of the instance
+func (s *Stack) deleteVIPForwarding(vip *abstract.VirtualIP, instanceName string) error {
+	projectID, region, zone := s.GcpConfig.ProjectID, s.GcpConfig.Region, s.GcpConfig.Zone
+
+	for _, protocol := range vipForwardedProtocols {
+		op, err := s.ComputeService.ForwardingRules.Delete(projectID, region, vipForwardingRuleName(vip, protocol)).Do()
+		if err != nil && !isNotFound(err) {
+			return fail.Wrap(err, fmt.Sprintf("failed to delete %s forwarding rule", protocol))
+		}
+		if err == nil {
+			if err = s.waitOperation(op); err != nil {
+				return err
+			}
+		}
+	}
+
+	op, err := s.ComputeService.TargetInstances.Delete(projectID, zone, vipTargetInstanceName(vip, instanceName)).Do()
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fail.Wrap(err, "failed to delete target instance")
+	}
+	return s.waitOperation(op)
+}
+
+// deleteAddress releases the static address named name
+func (s *Stack) deleteAddress(name string) error {
+	op, err := s.ComputeService.Addresses.Delete(s.GcpConfig.ProjectID, s.GcpConfig.Region, name).Do()
+	if err != nil {
+		if isNotFound(err) {
+			logrus.Debugf("address '%s' already deleted", name)
+			return nil
+		}
+		return fail.Wrap(err, fmt.Sprintf("failed to delete address '%s'", name))
+	}
+	return s.waitOperation(op)
+}
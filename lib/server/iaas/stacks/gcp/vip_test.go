@@ -0,0 +1,277 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+const (
+	fakeProject = "test-project"
+	fakeRegion  = "europe-west1"
+	fakeZone    = "europe-west1-b"
+	fakePrefix  = "https://www.googleapis.com/compute/v1/projects/" + fakeProject
+)
+
+// fakeVIPService emulates the part of the GCP compute API used to manage VIPs
+type fakeVIPService struct {
+	lock            sync.Mutex
+	addresses       map[string]*compute.Address
+	instance        *compute.Instance
+	targetInstances map[string]*compute.TargetInstance
+	forwardingRules map[string]*compute.ForwardingRule
+}
+
+func newFakeVIPService() *fakeVIPService {
+	return &fakeVIPService{
+		addresses: map[string]*compute.Address{},
+		instance: &compute.Instance{
+			Id:       1234,
+			Name:     "gw-net1",
+			SelfLink: fakePrefix + "/zones/" + fakeZone + "/instances/gw-net1",
+			NetworkInterfaces: []*compute.NetworkInterface{
+				{
+					Name:        "nic0",
+					NetworkIP:   "192.168.1.2",
+					Subnetwork:  fakePrefix + "/regions/" + fakeRegion + "/subnetworks/net1",
+					Fingerprint: "fp",
+				},
+			},
+		},
+		targetInstances: map[string]*compute.TargetInstance{},
+		forwardingRules: map[string]*compute.ForwardingRule{},
+	}
+}
+
+func (f *fakeVIPService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	last := parts[len(parts)-1]
+	done := func() {
+		_ = json.NewEncoder(w).Encode(&compute.Operation{Name: "op-1", Status: "DONE"})
+	}
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+	}
+
+	path := r.URL.Path
+	switch {
+	case strings.Contains(path, "/global/operations/"):
+		done()
+	case strings.HasSuffix(path, "/subnetworks") && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(
+			&compute.SubnetworkList{
+				Items: []*compute.Subnetwork{
+					{Id: 42, Name: "net1", SelfLink: fakePrefix + "/regions/" + fakeRegion + "/subnetworks/net1"},
+				},
+			},
+		)
+	case strings.HasSuffix(path, "/aggregated/instances"):
+		_ = json.NewEncoder(w).Encode(
+			&compute.InstanceAggregatedList{
+				Items: map[string]compute.InstancesScopedList{
+					"zones/" + fakeZone: {Instances: []*compute.Instance{f.instance}},
+				},
+			},
+		)
+	case strings.HasSuffix(path, "/addresses") && r.Method == http.MethodGet:
+		list := &compute.AddressList{}
+		for _, a := range f.addresses {
+			list.Items = append(list.Items, a)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case strings.HasSuffix(path, "/addresses") && r.Method == http.MethodPost:
+		var a compute.Address
+		_ = json.NewDecoder(r.Body).Decode(&a)
+		if a.AddressType == "INTERNAL" {
+			a.Address = "192.168.1.250"
+		} else {
+			a.Address = "35.1.2.3"
+		}
+		f.addresses[a.Name] = &a
+		done()
+	case strings.Contains(path, "/addresses/"):
+		a, ok := f.addresses[last]
+		if !ok {
+			notFound()
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.addresses, last)
+			done()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(a)
+	case strings.HasSuffix(path, "/updateNetworkInterface"):
+		var nic compute.NetworkInterface
+		_ = json.NewDecoder(r.Body).Decode(&nic)
+		f.instance.NetworkInterfaces[0].AliasIpRanges = nic.AliasIpRanges
+		done()
+	case strings.Contains(path, "/instances/"):
+		_ = json.NewEncoder(w).Encode(f.instance)
+	case strings.HasSuffix(path, "/targetInstances") && r.Method == http.MethodPost:
+		var ti compute.TargetInstance
+		_ = json.NewDecoder(r.Body).Decode(&ti)
+		ti.SelfLink = fakePrefix + "/zones/" + fakeZone + "/targetInstances/" + ti.Name
+		f.targetInstances[ti.Name] = &ti
+		done()
+	case strings.Contains(path, "/targetInstances/"):
+		ti, ok := f.targetInstances[last]
+		if !ok {
+			notFound()
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.targetInstances, last)
+			done()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ti)
+	case strings.HasSuffix(path, "/forwardingRules") && r.Method == http.MethodPost:
+		var fr compute.ForwardingRule
+		_ = json.NewDecoder(r.Body).Decode(&fr)
+		f.forwardingRules[fr.Name] = &fr
+		done()
+	case strings.Contains(path, "/forwardingRules/"):
+		fr, ok := f.forwardingRules[last]
+		if !ok {
+			notFound()
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.forwardingRules, last)
+			done()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(fr)
+	default:
+		notFound()
+	}
+}
+
+func newFakeVIPStack(t *testing.T) (*Stack, *fakeVIPService) {
+	fake := newFakeVIPService()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	svc, err := compute.NewService(
+		context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithHTTPClient(srv.Client()),
+	)
+	require.Nil(t, err)
+
+	return &Stack{
+		GcpConfig:      &stacks.GCPConfiguration{ProjectID: fakeProject, Region: fakeRegion, Zone: fakeZone},
+		ComputeService: svc,
+	}, fake
+}
+
+func TestVIPLifecycle(t *testing.T) {
+	stack, fake := newFakeVIPStack(t)
+
+	vip, err := stack.CreateVIP("42", "for gateways of network net1")
+	require.Nil(t, err)
+	assert.Equal(t, vipAddressName("42", "for gateways of network net1"), vip.ID)
+	assert.Equal(t, "192.168.1.250", vip.PrivateIP)
+	require.Contains(t, fake.addresses, vip.Name)
+	assert.Equal(t, "INTERNAL", fake.addresses[vip.Name].AddressType)
+	assert.Equal(t, fakePrefix+"/regions/"+fakeRegion+"/subnetworks/net1", fake.addresses[vip.Name].Subnetwork)
+
+	err = stack.BindHostToVIP(vip, "1234")
+	require.Nil(t, err)
+	require.Len(t, fake.instance.NetworkInterfaces[0].AliasIpRanges, 1)
+	assert.Equal(t, "192.168.1.250/32", fake.instance.NetworkInterfaces[0].AliasIpRanges[0].IpCidrRange)
+	assert.Equal(t, []string{"1234"}, vip.Hosts)
+
+	// Public IP added after bind is forwarded to the bound host, whatever the protocol
+	err = stack.AddPublicIPToVIP(vip)
+	require.Nil(t, err)
+	assert.Equal(t, "35.1.2.3", vip.PublicIP)
+	target := fake.targetInstances[vip.Name+"-gw-net1"]
+	require.NotNil(t, target)
+	assert.Equal(t, fake.instance.SelfLink, target.Instance)
+	require.Len(t, fake.forwardingRules, len(vipForwardedProtocols))
+	for _, protocol := range []string{"TCP", "UDP", "ICMP"} {
+		rule := fake.forwardingRules[vipForwardingRuleName(vip, protocol)]
+		require.NotNil(t, rule, protocol)
+		assert.Equal(t, protocol, rule.IPProtocol)
+		assert.Equal(t, "35.1.2.3", rule.IPAddress)
+		assert.Equal(t, target.SelfLink, rule.Target)
+	}
+
+	err = stack.DeleteVIP(vip)
+	require.Nil(t, err)
+	assert.Empty(t, fake.instance.NetworkInterfaces[0].AliasIpRanges)
+	assert.Empty(t, fake.forwardingRules)
+	assert.Empty(t, fake.targetInstances)
+	assert.Empty(t, fake.addresses)
+	assert.Empty(t, vip.Hosts)
+}
+
+func TestVIPsOfSameNetworkHaveDistinctNames(t *testing.T) {
+	gateways := vipAddressName("42", "for gateways of network net1")
+	controlPlane := vipAddressName("42", "cluster-ControlPlaneVIP")
+	assert.NotEqual(t, gateways, controlPlane)
+	assert.Equal(t, gateways, vipAddressName("42", "for gateways of network net1"))
+	assert.Regexp(t, "^vip-42-[0-9a-f]{8}$", gateways)
+}
+
+func TestBindHostToVIPIsIdempotent(t *testing.T) {
+	stack, fake := newFakeVIPStack(t)
+
+	vip, err := stack.CreateVIP("net1", "")
+	require.Nil(t, err)
+	require.Nil(t, stack.BindHostToVIP(vip, "1234"))
+	require.Nil(t, stack.BindHostToVIP(vip, "1234"))
+	assert.Len(t, fake.instance.NetworkInterfaces[0].AliasIpRanges, 1)
+	assert.Equal(t, []string{"1234"}, vip.Hosts)
+}
+
+func TestCreateVIPUnknownNetwork(t *testing.T) {
+	stack, _ := newFakeVIPStack(t)
+
+	_, err := stack.CreateVIP("43", "")
+	require.NotNil(t, err)
+}
+
+func TestCheckFixedIPAvailable(t *testing.T) {
+	stack, _ := newFakeVIPStack(t)
+
+	_, err := stack.CreateVIP("42", "")
+	require.Nil(t, err)
+
+	assert.Nil(t, stack.checkFixedIPAvailable("net1", "192.168.1.10"))
+	assert.IsType(t, fail.ErrDuplicate{}, stack.checkFixedIPAvailable("net1", "192.168.1.2"))
+	assert.IsType(t, fail.ErrDuplicate{}, stack.checkFixedIPAvailable("net1", "192.168.1.250"))
+	assert.Nil(t, stack.checkFixedIPAvailable("net2", "192.168.1.2"))
+}
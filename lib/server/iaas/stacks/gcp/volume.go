@@ -48,6 +48,7 @@ func (s *Stack) CreateVolume(request abstract.VolumeRequest) (*abstract.Volume,
 		SizeGb: int64(request.Size),
 		Type:   selectedType,
 		Zone:   s.GcpConfig.Zone,
+		Labels: gcpLabels(request.Tags),
 	}
 
 	service := s.ComputeService
@@ -139,7 +140,7 @@ func (s *Stack) ListVolumes() ([]abstract.Volume, fail.Error) {
 	compuService := s.ComputeService
 
 	token := ""
-	for paginate := true; paginate; {
+	for page, paginate := 1, true; paginate; page++ {
 		resp, err := compuService.Disks.List(s.GcpConfig.ProjectID, s.GcpConfig.Zone).PageToken(token).Do()
 		if err != nil {
 			return volumes, fail.Errorf(fmt.Sprintf("cannot list volumes: %v", err), err)
@@ -157,8 +158,7 @@ func (s *Stack) ListVolumes() ([]abstract.Volume, fail.Error) {
 			}
 			volumes = append(volumes, *nvolume)
 		}
-		token := resp.NextPageToken
-		paginate = token != ""
+		token, paginate = nextPageToken(token, resp.NextPageToken, page)
 	}
 
 	return volumes, nil
@@ -18,9 +18,12 @@ package huaweicloud
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/CS-SI/SafeScale/lib/utils"
@@ -49,7 +52,9 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
 	converters "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
+	propsv2 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v2"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks/openstack"
 	"github.com/CS-SI/SafeScale/lib/utils/retry"
 )
@@ -91,6 +96,43 @@ type bootdiskCreateOptsExt struct {
 	BlockDevice []blockDevice `json:"block_device_mapping_v2,omitempty"`
 }
 
+// defaultBootDiskType is the type of volume used as boot disk if none is requested
+const defaultBootDiskType = "SSD"
+
+// bootDiskTypes lists the types of volume usable as boot disk
+var bootDiskTypes = []string{"SATA", "SAS", "SSD"}
+
+// validateBootDiskType checks that diskType is a type of volume usable as boot disk and returns the type to use
+// (empty meaning default type)
+func validateBootDiskType(diskType string) (string, fail.Error) {
+	if diskType == "" {
+		return defaultBootDiskType, nil
+	}
+	for _, v := range bootDiskTypes {
+		if v == diskType {
+			return diskType, nil
+		}
+	}
+	return "", fail.InvalidParameterError(
+		"DiskType", fmt.Sprintf(
+			"'%s' is not a valid disk type (allowed: %s)", diskType, strings.Join(bootDiskTypes, ", "),
+		),
+	)
+}
+
+// newBootDisk returns the definition of a boot disk of 'size' GB and type 'volumeType', created from image 'imageID'
+func newBootDisk(imageID string, size int, volumeType string) blockDevice {
+	return blockDevice{
+		SourceType:          exbfv.SourceImage,
+		DestinationType:     exbfv.DestinationVolume,
+		BootIndex:           "0",
+		DeleteOnTermination: true,
+		UUID:                imageID,
+		VolumeType:          volumeType,
+		VolumeSize:          size,
+	}
+}
+
 // ToServerCreateMap adds the block device mapping option to the base server
 // creation options.
 func (opts bootdiskCreateOptsExt) ToServerCreateMap() (map[string]interface{}, fail.Error) {
@@ -302,14 +344,33 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		}
 	}
 
+	if xerr = request.CheckFixedIPs(); xerr != nil {
+		return nil, userData, xerr
+	}
+	for networkID, ip := range request.FixedIPs {
+		if xerr = s.checkFixedIPAvailable(networkID, ip); xerr != nil {
+			return nil, userData, xerr
+		}
+	}
+
+	orderedNetworks, xerr := request.OrderedNetworks()
+	if xerr != nil {
+		return nil, userData, xerr
+	}
+
 	var nets []servers.Network
-	// Add private networks
-	for _, n := range request.Networks {
+	portNames := map[string]string{}
+	// Add private networks, in the order of the network interfaces wanted
+	for _, n := range orderedNetworks {
 		nets = append(
 			nets, servers.Network{
-				UUID: n.ID,
+				UUID:    n.ID,
+				FixedIP: request.FixedIPs[n.ID],
 			},
 		)
+		if name := request.NICName(n.ID); name != "" {
+			portNames[n.ID] = name
+		}
 	}
 
 	if request.Password == "" {
@@ -338,28 +399,20 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		)
 	}
 
-	rim, err := s.GetImage(request.ImageID)
+	rim, err := s.ResolveBootImage(request)
 	if err != nil {
 		return nil, userData, err
 	}
 
-	if request.DiskSize > template.DiskSize {
-		template.DiskSize = request.DiskSize
-	}
-
-	if int(rim.DiskSize) > template.DiskSize {
-		template.DiskSize = int(rim.DiskSize)
+	diskSize, err := stacks.SystemDiskSize(request, *template, rim.DiskSize, s.cfgOpts.SystemDiskSizes)
+	if err != nil {
+		return nil, userData, err
 	}
+	template.DiskSize = diskSize
 
-	if template.DiskSize == 0 {
-		// Determines appropriate disk size
-		if template.Cores < 16 { // nolint
-			template.DiskSize = 100
-		} else if template.Cores < 32 {
-			template.DiskSize = 200
-		} else {
-			template.DiskSize = 400
-		}
+	bootDiskType, xerr := validateBootDiskType(request.DiskType)
+	if xerr != nil {
+		return nil, userData, xerr
 	}
 
 	// Select usable availability zone
@@ -368,28 +421,30 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		return nil, userData, err
 	}
 
-	// Defines boot disk
-	bootdiskOpts := blockDevice{
-		SourceType:          exbfv.SourceImage,
-		DestinationType:     exbfv.DestinationVolume,
-		BootIndex:           "0",
-		DeleteOnTermination: true,
-		UUID:                request.ImageID,
-		VolumeType:          "SSD",
-		VolumeSize:          template.DiskSize,
+	securityGroups, err := s.Stack.SecurityGroupsForHost(request)
+	if err != nil {
+		return nil, userData, err
 	}
+
+	// Defines boot disk
+	bootdiskOpts := newBootDisk(rim.ID, template.DiskSize, bootDiskType)
 	// Defines server
 	userDataPhase1, err := userData.Generate("phase1")
 	if err != nil {
 		return nil, userData, err
 	}
+	userDataPhase1, err = userdata.Fit(userDataPhase1, userdata.OpenStackMaxSize)
+	if err != nil {
+		return nil, userData, err
+	}
 	srvOpts := serverCreateOpts{
 		Name:             request.ResourceName,
-		SecurityGroups:   []string{s.SecurityGroup.Name},
+		SecurityGroups:   securityGroups,
 		Networks:         nets,
 		FlavorRef:        request.TemplateID,
 		UserData:         userDataPhase1,
 		AvailabilityZone: az,
+		Metadata:         request.Tags,
 	}
 	// Defines host "Extension bootfromvolume" options
 	bdOpts := bootdiskCreateOptsExt{
@@ -440,6 +495,15 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	if err != nil {
 		return nil, userData, err
 	}
+	err = host.Properties.LockForWrite(hostproperty.SizingV2).ThenUse(
+		func(clonable data.Clonable) error {
+			clonable.(*propsv2.HostSizing).BootDiskType = bootDiskType
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, userData, err
+	}
 
 	// --- query provider for host creation ---
 
@@ -465,6 +529,18 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 				if httpResp != nil {
 					codeStr = fmt.Sprintf(" (HTTP return code: %d)", httpResp.StatusCode)
 				}
+				if len(request.FixedIPs) > 0 && httpResp != nil &&
+					(httpResp.StatusCode == http.StatusBadRequest || httpResp.StatusCode == http.StatusConflict) {
+					// Retrying will not make the provider accept the requested fixed IP(s)
+					return retry.AbortedError(
+						"", fail.InvalidRequestError(
+							fmt.Sprintf(
+								"provider rejected the fixed IP(s) requested for host '%s': %s%s",
+								request.ResourceName, openstack.ProviderErrorToString(ierr), codeStr,
+							),
+						),
+					)
+				}
 				return fail.Errorf(
 					fmt.Sprintf(
 						"query to create host '%s' failed: %s%s",
@@ -506,12 +582,22 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 			if ierr = s.complementHost(host, srv); ierr != nil {
 				return fail.Errorf(fmt.Sprintf(openstack.ProviderErrorToString(ierr)), ierr)
 			}
+			if ierr = s.ComplementHostAvailabilityZone(host); ierr != nil {
+				return fail.Errorf(fmt.Sprintf(openstack.ProviderErrorToString(ierr)), ierr)
+			}
+
+			if ierr = s.NameHostPorts(host.ID, portNames); ierr != nil {
+				return fail.Errorf(fmt.Sprintf(openstack.ProviderErrorToString(ierr)), ierr)
+			}
 
 			return nil
 		},
 		temporal.GetLongOperationTimeout(),
 	)
 	if retryErr != nil {
+		if _, ok := retryErr.(retry.ErrAborted); ok {
+			retryErr = fail.Cause(retryErr)
+		}
 		err = retryErr
 		return nil, userData, err
 	}
@@ -543,6 +629,21 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		}
 	}()
 
+	if len(request.FixedIPs) > 0 {
+		err = host.Properties.LockForWrite(hostproperty.NetworkV1).ThenUse(
+			func(clonable data.Clonable) error {
+				hostNetworkV1 := clonable.(*propsv1.HostNetwork)
+				for networkID, ip := range request.FixedIPs {
+					hostNetworkV1.IPv4Addresses[networkID] = ip
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, userData, err
+		}
+	}
+
 	if request.PublicIP {
 		var fip *FloatingIP
 		fip, err = s.attachFloatingIP(host)
@@ -662,6 +763,10 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 		if err != nil {
 			return nil, err
 		}
+		err = s.ComplementHostAvailabilityZone(host)
+		if err != nil {
+			return nil, err
+		}
 
 		if !host.OK() {
 			logrus.Warnf("[TRACE] Unexpected host status: %s", spew.Sdump(host))
@@ -902,24 +1007,27 @@ func (s *Stack) DeleteHost(id string) error {
 		}
 	}
 
-	// Try to remove host for 3 minutes
-	retryErr := retry.WhileUnsuccessful(
+	// Try to remove host, waiting longer and longer between tries, until the cleanup timeout
+	retryErr := retry.WhileUnsuccessfulWithJitter(
 		func() error {
 			resourcePresent := true
 			// 1st, send delete host order
 			innerErr := servers.Delete(s.Stack.ComputeClient, id).ExtractErr()
 			if innerErr != nil {
+				if conflictErr := openstack.DeleteHostConflictError(id, innerErr, s.Stack.AttachedVolumeIDs); conflictErr != nil {
+					return conflictErr
+				}
 				return openstack.ReinterpretGophercloudErrorCode(
 					innerErr, []int64{404}, []int64{408, 429, 500, 503}, []int64{409}, func(ferr error) error {
 						return fail.AbortedError("", ferr)
 					},
 				)
 			}
-			// 2nd, check host status every 5 seconds until check failed.
+			// 2nd, check host status periodically until check failed.
 			// If check succeeds but state is Error, retry the deletion.
 			// If check fails and error isn't 'resource not found', retry
 			var host *servers.Server
-			innerRetryErr := retry.WhileUnsuccessfulDelay5Seconds(
+			innerRetryErr := retry.WhileUnsuccessfulWithJitter(
 				func() error {
 					host, innerErr = servers.Get(s.Stack.ComputeClient, id).Extract()
 					if innerErr == nil {
@@ -943,7 +1051,7 @@ func (s *Stack) DeleteHost(id string) error {
 
 					return innerErr
 				},
-				temporal.GetContextTimeout(),
+				temporal.GetMinDelay(), temporal.GetDefaultDelay(), temporal.GetContextTimeout(),
 			)
 			if innerRetryErr != nil {
 				if _, ok := innerRetryErr.(retry.ErrTimeout); ok {
@@ -961,8 +1069,7 @@ func (s *Stack) DeleteHost(id string) error {
 			}
 			return fail.Errorf(fmt.Sprintf("host '%s' in state 'ERROR', retrying to delete", id), nil)
 		},
-		5*time.Second,
-		temporal.GetHostCleanupTimeout(),
+		temporal.GetMinDelay(), temporal.GetBigDelay(), temporal.GetHostCleanupTimeout(),
 	)
 	if retryErr != nil {
 		logrus.Errorf("failed to remove host '%s': %s", id, retryErr.Error())
@@ -1035,6 +1142,17 @@ func (s *Stack) attachFloatingIP(host *abstract.Host) (*FloatingIP, fail.Error)
 	return fip, nil
 }
 
+// SetHostIPForwarding enables or disables the router mode of the host
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	if enabled {
+		return s.enableHostRouterMode(host)
+	}
+	return s.disableHostRouterMode(host)
+}
+
+// routerModeAddressPair is the allowed address pair letting any traffic go through a port
+const routerModeAddressPair = "1.1.1.1/0"
+
 // EnableHostRouterMode enables the host to act as a router/gateway.
 func (s *Stack) enableHostRouterMode(host *abstract.Host) error {
 	var (
@@ -1062,7 +1180,7 @@ func (s *Stack) enableHostRouterMode(host *abstract.Host) error {
 
 	pairs := []ports.AddressPair{
 		{
-			IPAddress: "1.1.1.1/0",
+			IPAddress: routerModeAddressPair,
 		},
 	}
 	opts := ports.UpdateOpts{AllowedAddressPairs: &pairs}
@@ -1095,7 +1213,22 @@ func (s *Stack) disableHostRouterMode(host *abstract.Host) error {
 		)
 	}
 
-	opts := ports.UpdateOpts{AllowedAddressPairs: nil}
+	// Keeps the other address pairs (VIP for instance); a nil AllowedAddressPairs would leave the port unchanged
+	port, err := ports.Get(s.Stack.NetworkClient, *portID).Extract()
+	if err != nil {
+		return fail.Errorf(
+			fmt.Sprintf(
+				"failed to disable Router Mode on host '%s': %s", host.Name, openstack.ProviderErrorToString(err),
+			), err,
+		)
+	}
+	pairs := []ports.AddressPair{}
+	for _, v := range port.AllowedAddressPairs {
+		if v.IPAddress != routerModeAddressPair {
+			pairs = append(pairs, v)
+		}
+	}
+	opts := ports.UpdateOpts{AllowedAddressPairs: &pairs}
 	_, err = ports.Update(s.Stack.NetworkClient, *portID, opts).Extract()
 	if err != nil {
 		return fail.Errorf(
@@ -1156,21 +1289,69 @@ func (s *Stack) getOpenstackPortID(host *abstract.Host) (*string, fail.Error) {
 }
 
 // toHostSize converts flavor attributes returned by OpenStack driver into abstract.hostproperty.v1.HostSize
+// If the flavor carries an ID, the sizing of the corresponding template is used; otherwise (or if the template cannot
+// be read) the sizing is built from the attributes of the flavor
 func (s *Stack) toHostSize(flavor map[string]interface{}) *propsv1.HostSize {
-	if i, ok := flavor["id"]; ok {
-		fid := i.(string)
-		tpl, _ := s.GetTemplate(fid)
-		return converters.ModelHostTemplateToPropertyHostSize(tpl)
+	if fid, ok := flavor["id"].(string); ok && fid != "" {
+		tpl, err := s.GetTemplate(fid)
+		if err == nil && tpl != nil {
+			return converters.ModelHostTemplateToPropertyHostSize(tpl)
+		}
+		logrus.Warnf("failed to get template '%s', using flavor attributes: %v", fid, err)
 	}
+	return flavorToHostSize(flavor)
+}
+
+// flavorToHostSize builds a HostSize from the attributes of a flavor; OpenStack gives the RAM in MB and the disk in GB
+// A missing attribute leaves the corresponding field empty, with a warning
+func flavorToHostSize(flavor map[string]interface{}) *propsv1.HostSize {
 	hostSize := propsv1.NewHostSize()
-	if _, ok := flavor["vcpus"]; ok {
-		hostSize.Cores = flavor["vcpus"].(int)
-		hostSize.DiskSize = flavor["disk"].(int)
-		hostSize.RAMSize = flavor["ram"].(float32) / 1000.0
+	var missing []string
+	if v, ok := flavorNumber(flavor, "vcpus"); ok {
+		hostSize.Cores = int(v)
+	} else {
+		missing = append(missing, "vcpus")
+	}
+	if v, ok := flavorNumber(flavor, "ram"); ok {
+		hostSize.RAMSize = float32(v / 1024.0)
+	} else {
+		missing = append(missing, "ram")
+	}
+	if v, ok := flavorNumber(flavor, "disk"); ok {
+		hostSize.DiskSize = int(v)
+	} else {
+		missing = append(missing, "disk")
+	}
+	if len(missing) > 0 {
+		logrus.Warnf("flavor has no valid value for %s, host size is incomplete", strings.Join(missing, ", "))
 	}
 	return hostSize
 }
 
+// flavorNumber returns the value of the numeric attribute 'key' of 'flavor', whatever the type it has been decoded to
+func flavorNumber(flavor map[string]interface{}, key string) (float64, bool) {
+	switch v := flavor[key].(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
 // toHostState converts host status returned by FlexibleEngine driver into HostState enum
 func toHostState(status string) hoststate.Enum {
 	switch status {
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package huaweicloud
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBootDiskType(t *testing.T) {
+	diskType, err := validateBootDiskType("")
+	assert.Nil(t, err)
+	assert.Equal(t, "SSD", diskType)
+
+	diskType, err = validateBootDiskType("SATA")
+	assert.Nil(t, err)
+	assert.Equal(t, "SATA", diskType)
+
+	_, err = validateBootDiskType("pd-ssd")
+	assert.NotNil(t, err)
+}
+
+func TestBootDiskTypeInServerCreateMap(t *testing.T) {
+	opts := bootdiskCreateOptsExt{
+		CreateOptsBuilder: serverCreateOpts{Name: "host", FlavorRef: "flavor-id"},
+		BlockDevice:       []blockDevice{newBootDisk("image-id", 100, "SAS")},
+	}
+	m, err := opts.ToServerCreateMap()
+	require.Nil(t, err)
+
+	devices, ok := m["server"].(map[string]interface{})["block_device_mapping_v2"].([]map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, 1, len(devices))
+	assert.Equal(t, "SAS", devices[0]["volume_type"])
+	assert.Equal(t, "image-id", devices[0]["uuid"])
+	assert.EqualValues(t, 100, devices[0]["volume_size"])
+}
+
+func TestFlavorToHostSize(t *testing.T) {
+	// as built by hand
+	size := flavorToHostSize(map[string]interface{}{"vcpus": 4, "ram": float32(8192), "disk": 40})
+	assert.Equal(t, 4, size.Cores)
+	assert.Equal(t, float32(8), size.RAMSize)
+	assert.Equal(t, 40, size.DiskSize)
+
+	// as decoded from JSON, numbers being float64
+	var decoded map[string]interface{}
+	require.Nil(t, json.Unmarshal([]byte(`{"vcpus": 2, "ram": 4096, "disk": 20}`), &decoded))
+	size = flavorToHostSize(decoded)
+	assert.Equal(t, 2, size.Cores)
+	assert.Equal(t, float32(4), size.RAMSize)
+	assert.Equal(t, 20, size.DiskSize)
+
+	// as decoded from JSON with UseNumber, numbers being json.Number
+	decoder := json.NewDecoder(strings.NewReader(`{"vcpus": 8, "ram": 1536, "disk": 100}`))
+	decoder.UseNumber()
+	decoded = nil
+	require.Nil(t, decoder.Decode(&decoded))
+	size = flavorToHostSize(decoded)
+	assert.Equal(t, 8, size.Cores)
+	assert.Equal(t, float32(1.5), size.RAMSize)
+	assert.Equal(t, 100, size.DiskSize)
+
+	// missing or invalid attributes leave fields empty
+	size = flavorToHostSize(map[string]interface{}{"vcpus": "2", "ram": true})
+	assert.Equal(t, 2, size.Cores)
+	assert.Equal(t, float32(0), size.RAMSize)
+	assert.Equal(t, 0, size.DiskSize)
+}
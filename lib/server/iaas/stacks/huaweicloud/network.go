@@ -695,6 +695,62 @@ func (s *Stack) deleteSubnet(id string) error {
 	return nil
 }
 
+type subnetUpdateRequest struct {
+	Name         string   `json:"name"`
+	PrimaryDNS   string   `json:"primary_dns,omitempty"`
+	SecondaryDNS string   `json:"secondary_dns,omitempty"`
+	DNSList      []string `json:"dnsList,omitempty"`
+}
+
+// UpdateNetworkDNSServers replaces the DNS servers distributed by DHCP in the subnet identified by id
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	// Name is mandatory in update request, even if unchanged
+	subnet, err := s.getSubnet(id)
+	if err != nil {
+		return err
+	}
+
+	req := subnetUpdateRequest{
+		Name:    subnet.Name,
+		DNSList: dnsServers,
+	}
+	if len(dnsServers) >= 1 {
+		req.PrimaryDNS = dnsServers[0]
+	}
+	if len(dnsServers) >= 2 {
+		req.SecondaryDNS = dnsServers[1]
+	}
+	b, err := gophercloud.BuildRequestBody(req, "subnet")
+	if err != nil {
+		return fail.Errorf(
+			fmt.Sprintf(
+				"error preparing update of subnet '%s': %s", subnet.Name, openstack.ProviderErrorToString(err),
+			), err,
+		)
+	}
+
+	url := s.Stack.NetworkClient.Endpoint + "v1/" + s.authOpts.ProjectID + "/vpcs/" + s.vpc.ID + "/subnets/" + id
+	opts := gophercloud.RequestOpts{
+		JSONBody: b,
+		OkCodes:  []int{200},
+	}
+	_, err = s.Stack.Driver.Request("PUT", url, &opts)
+	if err != nil {
+		return fail.Errorf(
+			fmt.Sprintf(
+				"error updating DNS servers of subnet '%s': %s", subnet.Name, openstack.ProviderErrorToString(err),
+			), err,
+		)
+	}
+	return nil
+}
+
+// SetNetworkRoutes is not implemented for huaweicloud
+// The routers of the openstack stack are not used with VPCs, so the implementation of the embedded stack cannot apply
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	return fail.NotImplementedError("SetNetworkRoutes() not implemented for huaweicloud")
+}
+
 // findSubnetByName returns a subnets.Subnet if subnet named as 'name' exists
 func (s *Stack) findSubnetByName(name string) (*subnets.Subnet, fail.Error) {
 	subnetList, err := s.listSubnets()
@@ -773,6 +829,39 @@ func (s *Stack) DeleteGateway(id string) error {
 	return s.DeleteHost(id)
 }
 
+// checkFixedIPAvailable returns fail.ErrDuplicate if 'ip' is already used by a port of the network identified by 'networkID'
+func (s *Stack) checkFixedIPAvailable(networkID string, ip string) fail.Error {
+	used := false
+	err := ports.List(s.NetworkClient, ports.ListOpts{NetworkID: networkID}).EachPage(
+		func(page pagination.Page) (bool, error) {
+			list, err := ports.ExtractPorts(page)
+			if err != nil {
+				return false, err
+			}
+			for _, port := range list {
+				for _, fixedIP := range port.FixedIPs {
+					if fixedIP.IPAddress == ip {
+						used = true
+						return false, nil
+					}
+				}
+			}
+			return true, nil
+		},
+	)
+	if err != nil {
+		return fail.Errorf(
+			fmt.Sprintf(
+				"failed to list ports of network '%s': %s", networkID, openstack.ProviderErrorToString(err),
+			), err,
+		)
+	}
+	if used {
+		return fail.DuplicateError(fmt.Sprintf("IP address '%s' is already used in network '%s'", ip, networkID))
+	}
+	return nil
+}
+
 // CreateVIP creates a private virtual IP
 // If public is set to true,
 func (s *Stack) CreateVIP(networkID string, name string) (*abstract.VirtualIP, fail.Error) {
@@ -141,6 +141,7 @@ func (s *Stack) ListImages() (images []abstract.Image, xerr fail.Error) {
 			ID:   imageJSON.(map[string]interface{})["imageID"].(string),
 			Name: imageJSON.(map[string]interface{})["imageName"].(string),
 		}
+		image.OSFamily = abstract.GuessOSFamily(image.Name)
 		images = append(images, image)
 	}
 
@@ -181,14 +182,16 @@ func (s *Stack) GetImage(id string) (image *abstract.Image, xerr fail.Error) {
 	for _, imageJSON := range imagesJSON {
 		if imageID, ok := imageJSON.(map[string]interface{})["imageID"]; ok && imageID == id {
 			return &abstract.Image{
-				ID:   imageJSON.(map[string]interface{})["imageID"].(string),
-				Name: imageJSON.(map[string]interface{})["imageName"].(string),
+				ID:       imageJSON.(map[string]interface{})["imageID"].(string),
+				Name:     imageJSON.(map[string]interface{})["imageName"].(string),
+				OSFamily: abstract.GuessOSFamily(imageJSON.(map[string]interface{})["imageName"].(string)),
 			}, nil
 		}
 		if imageName, ok := imageJSON.(map[string]interface{})["imageName"]; ok && imageName == id {
 			return &abstract.Image{
-				ID:   imageJSON.(map[string]interface{})["imageID"].(string),
-				Name: imageJSON.(map[string]interface{})["imageName"].(string),
+				ID:       imageJSON.(map[string]interface{})["imageID"].(string),
+				Name:     imageJSON.(map[string]interface{})["imageName"].(string),
+				OSFamily: abstract.GuessOSFamily(imageJSON.(map[string]interface{})["imageName"].(string)),
 			}, nil
 		}
 	}
@@ -246,6 +249,9 @@ func (s *Stack) ListTemplates() (templates []abstract.HostTemplate, xerr fail.Er
 	return templates, nil
 }
 
+// RefreshCatalog does nothing, the templates and images are not kept in cache
+func (s *Stack) RefreshCatalog() {}
+
 // GetTemplate overload OpenStack GetTemplate method to add GPU configuration
 func (s *Stack) GetTemplate(id string) (template *abstract.HostTemplate, xerr fail.Error) {
 	jsonFile, err := os.Open(s.LibvirtConfig.TemplatesJSONPath)
@@ -860,6 +866,9 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	userData = userdata.NewContent()
 
 	// ----Check Inputs----
+	if len(request.NICs) > 0 {
+		return nil, userData, fail.NotImplementedError("ordering or naming network interfaces at host creation is not implemented yet")
+	}
 	if resourceName == "" {
 		return nil, nil, fail.Errorf(fmt.Sprintf("The ResourceName is mandatory "), xerr)
 	}
@@ -1300,6 +1309,31 @@ func (s *Stack) StartHost(id string) error {
 	return nil
 }
 
+// ListHostNetworkInterfaces is not implemented for libvirt
+func (s *Stack) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	return nil, fail.NotImplementedError("ListHostNetworkInterfaces() not implemented for libvirt")
+}
+
+// CreateHostSnapshot is not implemented for libvirt
+func (s *Stack) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	return "", fail.NotImplementedError("CreateHostSnapshot() not implemented for libvirt")
+}
+
+// WaitHostSnapshotReady is not implemented for libvirt
+func (s *Stack) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	return fail.NotImplementedError("WaitHostSnapshotReady() not implemented for libvirt")
+}
+
+// RenameHost renames the host identified by id
+func (s *Stack) RenameHost(id string, newName string) fail.Error {
+	return fail.NotImplementedError("RenameHost() not implemented for libvirt")
+}
+
+// GetHostConsoleOutput is not implemented for libvirt
+func (s *Stack) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	return "", fail.NotImplementedError("GetHostConsoleOutput() not implemented for libvirt")
+}
+
 // RebootHost reboot the host identified by id
 func (s *Stack) RebootHost(id string) error {
 	_, domain, err := s.getHostAndDomainFromRef(id)
@@ -1334,3 +1368,28 @@ func (s *Stack) ListAvailabilityZones() (map[string]bool, fail.Error) {
 func (s *Stack) ListRegions() ([]string, fail.Error) {
 	return []string{"local"}, nil
 }
+
+// GetQuotas is not supported by libvirt, there is no tenant limit to inspect
+func (s *Stack) GetQuotas() (*abstract.Quotas, fail.Error) {
+	return nil, fail.NotImplementedError("GetQuotas() not implemented for libvirt")
+}
+
+// SetHostIPForwarding is not implemented for libvirt
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	return fail.NotImplementedError("SetHostIPForwarding() not implemented for libvirt")
+}
+
+// BindSecurityGroupToHost is not implemented for libvirt
+func (s *Stack) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("BindSecurityGroupToHost() not implemented for libvirt")
+}
+
+// UnbindSecurityGroupFromHost is not implemented for libvirt
+func (s *Stack) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("UnbindSecurityGroupFromHost() not implemented for libvirt")
+}
+
+// AddRulesToSecurityGroup is not implemented for libvirt
+func (s *Stack) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	return fail.NotImplementedError("AddRulesToSecurityGroup() not implemented for libvirt")
+}
@@ -378,3 +378,13 @@ func (s *Stack) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) error
 func (s *Stack) DeleteVIP(vip *abstract.VirtualIP) error {
 	return fail.NotImplementedError("DeleteVIP() not implemented yet") // FIXME: Technical debt
 }
+
+// UpdateNetworkDNSServers is not implemented for libvirt
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	return fail.NotImplementedError("UpdateNetworkDNSServers() not implemented for libvirt")
+}
+
+// SetNetworkRoutes is not implemented for libvirt
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	return fail.NotImplementedError("SetNetworkRoutes() not implemented for libvirt")
+}
@@ -46,6 +46,11 @@ func (s *Stack) ListRegions() ([]string, fail.Error) {
 	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
 }
 
+// GetQuotas stub
+func (s *Stack) GetQuotas() (*abstract.Quotas, fail.Error) {
+	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
 // ListImages stub
 func (s *Stack) ListImages(all bool) ([]abstract.Image, fail.Error) {
 	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
@@ -66,6 +71,9 @@ func (s *Stack) ListTemplates(all bool) ([]abstract.HostTemplate, fail.Error) {
 	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
 }
 
+// RefreshCatalog stub
+func (s *Stack) RefreshCatalog() {}
+
 // CreateKeyPair stub
 func (s *Stack) CreateKeyPair(name string) (*abstract.KeyPair, fail.Error) {
 	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
@@ -111,6 +119,16 @@ func (s *Stack) DeleteNetwork(id string) error {
 	return fail.Errorf(fmt.Sprintf(errorStr), nil)
 }
 
+// UpdateNetworkDNSServers stub
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// SetNetworkRoutes stub
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
 // CreateGateway stub
 func (s *Stack) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (*abstract.Host, *userdata.Content, fail.Error) {
 	return nil, nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
@@ -196,6 +214,51 @@ func (s *Stack) RebootHost(id string) error {
 	return fail.Errorf(fmt.Sprintf(errorStr), nil)
 }
 
+// SetHostIPForwarding stub
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// BindSecurityGroupToHost stub
+func (s *Stack) BindSecurityGroupToHost(id string, sgName string) error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// UnbindSecurityGroupFromHost stub
+func (s *Stack) UnbindSecurityGroupFromHost(id string, sgName string) error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// AddRulesToSecurityGroup stub
+func (s *Stack) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// GetHostConsoleOutput stub
+func (s *Stack) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	return "", fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// ListHostNetworkInterfaces stub
+func (s *Stack) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// CreateHostSnapshot stub
+func (s *Stack) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	return "", fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// WaitHostSnapshotReady stub
+func (s *Stack) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
+// RenameHost stub
+func (s *Stack) RenameHost(id string, newName string) fail.Error {
+	return fail.Errorf(fmt.Sprintf(errorStr), nil)
+}
+
 // CreateVolume stub
 func (s *Stack) CreateVolume(request abstract.VolumeRequest) (*abstract.Volume, fail.Error) {
 	return nil, fail.Errorf(fmt.Sprintf(errorStr), nil)
@@ -29,9 +29,13 @@ import (
 	"github.com/sirupsen/logrus"
 
 	gc "github.com/gophercloud/gophercloud"
+	volumequotas "github.com/gophercloud/gophercloud/openstack/blockstorage/extensions/quotasets"
 	az "github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/floatingips"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/limits"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/schedulerhints"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/servergroups"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/startstop"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
@@ -46,6 +50,7 @@ import (
 	converters "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/userdata"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -79,6 +84,53 @@ func (s *Stack) ListRegions() ([]string, fail.Error) {
 	return results, nil
 }
 
+// GetQuotas returns the limits and usages of the tenant resources
+// Compute limits come from nova; volume usage comes from cinder when the project is known, and is left
+// unknown if cinder cannot provide it
+func (s *Stack) GetQuotas() (quotas *abstract.Quotas, xerr fail.Error) {
+	tracer := debug.NewTracer(nil, "", true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &xerr)()
+
+	result, err := limits.Get(s.ComputeClient, nil).Extract()
+	if err != nil {
+		return nil, fail.Errorf(fmt.Sprintf("failed to get compute limits: %s", ProviderErrorToString(err)), err)
+	}
+
+	quotas = abstract.NewQuotas()
+	absolute := result.Absolute
+	quotas.Instances = toQuota(absolute.MaxTotalInstances, absolute.TotalInstancesUsed)
+	quotas.VCPUs = toQuota(absolute.MaxTotalCores, absolute.TotalCoresUsed)
+	quotas.RAM = toQuota(absolute.MaxTotalRAMSize, absolute.TotalRAMUsed)
+	quotas.FloatingIPs = toQuota(absolute.MaxTotalFloatingIps, absolute.TotalFloatingIpsUsed)
+	quotas.SecurityGroups = toQuota(absolute.MaxSecurityGroups, absolute.TotalSecurityGroupsUsed)
+
+	projectID := s.authOpts.ProjectID
+	if projectID == "" {
+		projectID = s.authOpts.TenantID
+	}
+	if projectID == "" || s.VolumeClient == nil {
+		logrus.Debugf("project ID unknown, volume quotas not available")
+		return quotas, nil
+	}
+	usage, err := volumequotas.GetUsage(s.VolumeClient, projectID).Extract()
+	if err != nil {
+		logrus.Warnf("failed to get volume quotas: %s", ProviderErrorToString(err))
+		return quotas, nil
+	}
+	quotas.Volumes = toQuota(usage.Volumes.Limit, usage.Volumes.InUse)
+
+	return quotas, nil
+}
+
+// toQuota converts an OpenStack limit and usage to abstract.Quota; OpenStack uses -1 for unlimited
+func toQuota(limit, used int) abstract.Quota {
+	if limit < 0 {
+		return abstract.Quota{Limit: abstract.UnknownQuota, Used: used, Unlimited: true}
+	}
+	return abstract.Quota{Limit: limit, Used: used}
+}
+
 // ListAvailabilityZones lists the usable AvailabilityZones
 func (s *Stack) ListAvailabilityZones() (list map[string]bool, xerr fail.Error) {
 	tracer := debug.NewTracer(nil, "", true).GoingIn()
@@ -109,8 +161,18 @@ func (s *Stack) ListAvailabilityZones() (list map[string]bool, xerr fail.Error)
 	return azList, nil
 }
 
-// ListImages lists available OS images
-func (s *Stack) ListImages() (imgList []abstract.Image, xerr fail.Error) {
+// ListImages lists available OS images; the list is kept for the TTL of the catalog cache
+func (s *Stack) ListImages() ([]abstract.Image, fail.Error) {
+	return s.catalog.Images(s.listImages)
+}
+
+// RefreshCatalog forgets the images and templates kept in cache
+func (s *Stack) RefreshCatalog() {
+	s.catalog.Invalidate()
+}
+
+// listImages queries the images
+func (s *Stack) listImages() (imgList []abstract.Image, xerr fail.Error) {
 	tracer := debug.NewTracer(nil, "", true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &xerr)()
@@ -129,7 +191,7 @@ func (s *Stack) ListImages() (imgList []abstract.Image, xerr fail.Error) {
 			}
 
 			for _, img := range imageList {
-				imgList = append(imgList, abstract.Image{ID: img.ID, Name: img.Name, DiskSize: int64(img.MinDiskGigabytes)})
+				imgList = append(imgList, toAbstractImage(img))
 
 			}
 			return true, nil
@@ -169,7 +231,67 @@ func (s *Stack) GetImage(id string) (image *abstract.Image, xerr fail.Error) {
 		return nil, fail.Wrap(xerr, fmt.Sprintf("error getting image: %s", ProviderErrorToString(xerr)))
 	}
 
-	return &abstract.Image{ID: img.ID, Name: img.Name, DiskSize: int64(img.MinDiskGigabytes)}, nil
+	out := toAbstractImage(*img)
+	return &out, nil
+}
+
+// ResolveBootImage returns the image to boot the host of 'request' from: the instance snapshot SourceSnapshotID if
+// set, the image ImageID otherwise
+// On OpenStack, an instance snapshot is an image with the property 'image_type' set to 'snapshot'
+func (s *Stack) ResolveBootImage(request abstract.HostRequest) (*abstract.Image, fail.Error) {
+	if request.SourceSnapshotID == "" {
+		return s.GetImage(request.ImageID)
+	}
+
+	img, err := images.Get(s.ComputeClient, request.SourceSnapshotID).Extract()
+	if err != nil {
+		switch err.(type) {
+		case gc.ErrDefault404, *gc.ErrDefault404:
+			return nil, abstract.ResourceNotFoundError("snapshot", request.SourceSnapshotID)
+		default:
+			return nil, fail.Wrap(
+				err, fmt.Sprintf(
+					"failed to get snapshot '%s': %s", request.SourceSnapshotID, ProviderErrorToString(err),
+				),
+			)
+		}
+	}
+	if imageType, ok := img.Properties["image_type"].(string); !ok || imageType != "snapshot" {
+		return nil, fail.InvalidRequestError(
+			fmt.Sprintf("image '%s' is not an instance snapshot", request.SourceSnapshotID),
+		)
+	}
+	out := toAbstractImage(*img)
+	return &out, nil
+}
+
+// toAbstractImage converts a glance image to an abstract.Image, using the standard image properties 'os_distro',
+// 'architecture' and 'os_admin_user' when they are set
+func toAbstractImage(img images.Image) abstract.Image {
+	out := abstract.Image{
+		ID:        img.ID,
+		Name:      img.Name,
+		DiskSize:  int64(img.MinDiskGigabytes),
+		MinDiskGB: img.MinDiskGigabytes,
+		MinRAMGB:  float32(img.MinRAMMegabytes) / 1024.0,
+	}
+	if distro, ok := img.Properties["os_distro"].(string); ok {
+		out.OSFamily = abstract.GuessOSFamily(distro)
+	}
+	if out.OSFamily == "" {
+		out.OSFamily = abstract.GuessOSFamily(img.Name)
+	}
+	if arch, ok := img.Properties["architecture"].(string); ok {
+		out.Architecture = arch
+	}
+	// 'image_original_user' is set by OVH instead of 'os_admin_user'
+	for _, key := range []string{"os_admin_user", "image_original_user"} {
+		if user, ok := img.Properties[key].(string); ok && user != "" {
+			out.DefaultUser = user
+			break
+		}
+	}
+	return out
 }
 
 // GetTemplate returns the Template referenced by id
@@ -202,7 +324,13 @@ func (s *Stack) GetTemplate(id string) (template *abstract.HostTemplate, xerr fa
 
 // ListTemplates lists available Host templates
 // Host templates are sorted using Dominant Resource Fairness Algorithm
+// The list is kept for the TTL of the catalog cache
 func (s *Stack) ListTemplates() ([]abstract.HostTemplate, fail.Error) {
+	return s.catalog.Templates(s.listTemplates)
+}
+
+// listTemplates queries the flavors
+func (s *Stack) listTemplates() ([]abstract.HostTemplate, fail.Error) {
 	tracer := debug.NewTracer(nil, "", true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 
@@ -325,12 +453,18 @@ func (s *Stack) ListKeyPairs() ([]abstract.KeyPair, fail.Error) {
 }
 
 // DeleteKeyPair deletes the key pair identified by id
+// Returns fail.ErrNotFound if the key pair doesn't exist
 func (s *Stack) DeleteKeyPair(id string) error {
 	defer debug.NewTracer(nil, fmt.Sprintf("(%s)", id), true).WithStopwatch().GoingIn().OnExitTrace()()
 
 	err := keypairs.Delete(s.ComputeClient, id).ExtractErr()
 	if err != nil {
-		return fail.Wrap(err, fmt.Sprintf("error deleting key pair: %s", ProviderErrorToString(err)))
+		switch err.(type) {
+		case gc.ErrDefault404, *gc.ErrDefault404:
+			return fail.NotFoundError(fmt.Sprintf("failed to find key pair '%s'", id))
+		default:
+			return fail.Wrap(err, fmt.Sprintf("error deleting key pair: %s", ProviderErrorToString(err)))
+		}
 	}
 	return nil
 }
@@ -412,6 +546,10 @@ func (s *Stack) InspectHost(hostParam interface{}) (host *abstract.Host, xerr fa
 		if err != nil {
 			return nil, err
 		}
+		err = s.ComplementHostAvailabilityZone(host)
+		if err != nil {
+			return nil, err
+		}
 
 		if !host.OK() {
 			logrus.Warnf("[TRACE] Unexpected host status: %s", spew.Sdump(host))
@@ -634,6 +772,69 @@ func (s *Stack) GetHostByName(name string) (*abstract.Host, fail.Error) {
 	return nil, abstract.ResourceNotFoundError("host", name)
 }
 
+// serverGroupPolicy returns the policy of the server groups of affinity groups
+func serverGroupPolicy(antiAffinity bool) string {
+	if antiAffinity {
+		return "anti-affinity"
+	}
+	return "affinity"
+}
+
+// serverGroupID returns the ID of the server group named 'name', creating it if it doesn't exist yet
+// An existing server group with another policy is refused, the host could not satisfy both.
+func (s *Stack) serverGroupID(name string, antiAffinity bool) (string, fail.Error) {
+	policy := serverGroupPolicy(antiAffinity)
+
+	allPages, err := servergroups.List(s.ComputeClient).AllPages()
+	if err != nil {
+		return "", fail.Wrap(err, fmt.Sprintf("failed to list server groups: %s", ProviderErrorToString(err)))
+	}
+	groups, err := servergroups.ExtractServerGroups(allPages)
+	if err != nil {
+		return "", fail.Wrap(err, fmt.Sprintf("failed to list server groups: %s", ProviderErrorToString(err)))
+	}
+	for _, g := range groups {
+		if g.Name != name {
+			continue
+		}
+		for _, p := range g.Policies {
+			if p == policy {
+				return g.ID, nil
+			}
+		}
+		return "", fail.InvalidRequestError(
+			fmt.Sprintf("server group '%s' already exists with policies %v instead of '%s'", name, g.Policies, policy),
+		)
+	}
+
+	g, err := servergroups.Create(
+		s.ComputeClient, servergroups.CreateOpts{
+			Name:     name,
+			Policies: []string{policy},
+		},
+	).Extract()
+	if err != nil {
+		return "", fail.Wrap(
+			err, fmt.Sprintf("failed to create server group '%s': %s", name, ProviderErrorToString(err)),
+		)
+	}
+	logrus.Debugf("server group '%s' (%s) created with policy '%s'", g.Name, g.ID, policy)
+	return g.ID, nil
+}
+
+// withServerGroup adds to 'opts' the scheduler hint placing the server in the server group 'groupID', if set
+func withServerGroup(opts servers.CreateOptsBuilder, groupID string) servers.CreateOptsBuilder {
+	if groupID == "" {
+		return opts
+	}
+	return schedulerhints.CreateOptsExt{
+		CreateOptsBuilder: opts,
+		SchedulerHints: schedulerhints.SchedulerHints{
+			Group: groupID,
+		},
+	}
+}
+
 // CreateHost creates an host satisfying request
 func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, userData *userdata.Content, xerr fail.Error) {
 	defer debug.NewTracer(
@@ -672,13 +873,25 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 			},
 		)
 	}
-	// Add private networks
-	for _, n := range request.Networks {
+	if err := request.CheckFixedIPs(); err != nil {
+		return nil, userData, err
+	}
+	// Add private networks, in the order of the network interfaces wanted
+	orderedNetworks, err := request.OrderedNetworks()
+	if err != nil {
+		return nil, userData, err
+	}
+	portNames := map[string]string{}
+	for _, n := range orderedNetworks {
 		nets = append(
 			nets, servers.Network{
-				UUID: n.ID,
+				UUID:    n.ID,
+				FixedIP: request.FixedIPs[n.ID],
 			},
 		)
+		if name := request.NICName(n.ID); name != "" {
+			portNames[n.ID] = name
+		}
 	}
 
 	if request.Password == "" {
@@ -717,17 +930,40 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 	if err != nil {
 		return nil, userData, err
 	}
+	userDataPhase1, err = userdata.Fit(userDataPhase1, userdata.OpenStackMaxSize)
+	if err != nil {
+		return nil, userData, err
+	}
 
 	// FIXME: Change volume size
 
+	rim, err := s.ResolveBootImage(request)
+	if err != nil {
+		return nil, userData, err
+	}
+
+	securityGroups, err := s.SecurityGroupsForHost(request)
+	if err != nil {
+		return nil, userData, err
+	}
+
 	srvOpts := servers.CreateOpts{
 		Name:             request.ResourceName,
-		SecurityGroups:   []string{s.SecurityGroup.Name},
+		SecurityGroups:   securityGroups,
 		Networks:         nets,
 		FlavorRef:        request.TemplateID,
-		ImageRef:         request.ImageID,
+		ImageRef:         rim.ID,
 		UserData:         userDataPhase1,
 		AvailabilityZone: azone,
+		Metadata:         request.Tags,
+	}
+
+	serverGroupID := ""
+	if request.AffinityGroup != "" {
+		serverGroupID, err = s.serverGroupID(request.AffinityGroup, request.AntiAffinity)
+		if err != nil {
+			return nil, userData, err
+		}
 	}
 
 	// --- Initializes abstract.Host ---
@@ -766,29 +1002,11 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 
 	// FIXME: Template resize
 
-	rim, err := s.GetImage(request.ImageID)
+	diskSize, err := stacks.SystemDiskSize(request, *template, rim.DiskSize, s.cfgOpts.SystemDiskSizes)
 	if err != nil {
 		return nil, userData, err
 	}
-
-	if request.DiskSize > template.DiskSize {
-		template.DiskSize = request.DiskSize
-	}
-
-	if int(rim.DiskSize) > template.DiskSize {
-		template.DiskSize = int(rim.DiskSize)
-	}
-
-	if template.DiskSize == 0 {
-		// Determines appropriate disk size
-		if template.Cores < 16 { // nolint
-			template.DiskSize = 100
-		} else if template.Cores < 32 {
-			template.DiskSize = 200
-		} else {
-			template.DiskSize = 400
-		}
-	}
+	template.DiskSize = diskSize
 
 	// --- query provider for host creation ---
 
@@ -805,13 +1023,16 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 				},
 			}
 			server, ierr := bootfromvolume.Create(s.ComputeClient, bootfromvolume.CreateOptsExt{
-				CreateOptsBuilder: srvOpts,
+				CreateOptsBuilder: withServerGroup(srvOpts, serverGroupID),
 				BlockDevice:       bd,
 			}).Extract()
 			if ierr != nil {
 				if server != nil {
 					servers.Delete(s.ComputeClient, server.ID)
 				}
+				if rerr := fixedIPsRejectedError(request.ResourceName, request.FixedIPs, ierr); rerr != nil {
+					return rerr
+				}
 				msg := ProviderErrorToString(ierr)
 				return fail.Errorf(msg, ierr)
 			}
@@ -855,6 +1076,15 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 				logrus.Debugf("failure complementing host data")
 				return fail.Errorf(ProviderErrorToString(ierr), ierr)
 			}
+			if ierr = s.ComplementHostAvailabilityZone(host); ierr != nil {
+				logrus.Debugf("failure complementing host data")
+				return fail.Errorf(ProviderErrorToString(ierr), ierr)
+			}
+
+			if ierr = s.NameHostPorts(host.ID, portNames); ierr != nil {
+				logrus.Debugf("failure naming host ports")
+				return fail.Errorf(ProviderErrorToString(ierr), ierr)
+			}
 
 			ierr = nil
 			return nil
@@ -862,6 +1092,9 @@ func (s *Stack) CreateHost(request abstract.HostRequest) (host *abstract.Host, u
 		temporal.GetLongOperationTimeout(),
 	)
 	if retryErr != nil {
+		if _, ok := retryErr.(retry.ErrAborted); ok {
+			return nil, userData, fail.Cause(retryErr)
+		}
 		return nil, userData, fail.Wrap(retryErr, "error creating host")
 	}
 	if host == nil {
@@ -966,22 +1199,45 @@ func (s *Stack) GetAvailabilityZoneOfServer(serverID string) (string, fail.Error
 		servers.Server
 		az.ServerAvailabilityZoneExt
 	}
-	var allServers []ServerWithAZ
-	allPages, err := servers.List(s.ComputeClient, nil).AllPages()
+	var server ServerWithAZ
+	err := servers.Get(s.ComputeClient, serverID).ExtractInto(&server)
 	if err != nil {
-		return "", fail.Errorf(fmt.Sprintf("unable to retrieve servers: %s", err), err)
+		return "", fail.Errorf(fmt.Sprintf("unable to retrieve server: %s", ProviderErrorToString(err)), err)
 	}
-	err = servers.ExtractServersInto(allPages, &allServers)
-	if err != nil {
-		return "", fail.Errorf(fmt.Sprintf("unable to extract servers: %s", err), err)
+	if server.AvailabilityZone == "" {
+		return "", fail.Errorf(
+			fmt.Sprintf("unable to find availability zone information for server [%s]", serverID), nil,
+		)
 	}
-	for _, server := range allServers {
-		if server.ID == serverID {
-			return server.AvailabilityZone, nil
-		}
+	return server.AvailabilityZone, nil
+}
+
+// ComplementHostAvailabilityZone records the availability zone of the host in its description if it is not known
+// yet, which completes the hosts created before it was recorded
+// Failing to get the availability zone is only logged, the host being usable without it.
+func (s *Stack) ComplementHostAvailabilityZone(host *abstract.Host) error {
+	known := false
+	err := host.Properties.LockForRead(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			known = clonable.(*propsv1.HostDescription).AvailabilityZone != ""
+			return nil
+		},
+	)
+	if err != nil || known {
+		return err
 	}
 
-	return "", fail.Errorf(fmt.Sprintf("unable to find availability zone information for server [%s]", serverID), nil)
+	zone, err := s.GetAvailabilityZoneOfServer(host.ID)
+	if err != nil {
+		logrus.Debugf("failed to get the availability zone of host '%s': %v", host.ID, err)
+		return nil
+	}
+	return host.Properties.LockForWrite(hostproperty.DescriptionV1).ThenUse(
+		func(clonable data.Clonable) error {
+			clonable.(*propsv1.HostDescription).AvailabilityZone = zone
+			return nil
+		},
+	)
 }
 
 // SelectedAvailabilityZone returns the selected availability zone
@@ -1237,13 +1493,16 @@ func (s *Stack) DeleteHost(id string) error {
 		}
 	}
 
-	// Try to remove host for 3 minutes
-	outerRetryErr := retry.WhileUnsuccessful(
+	// Try to remove host, waiting longer and longer between tries, until the cleanup timeout
+	outerRetryErr := retry.WhileUnsuccessfulWithJitter(
 		func() error {
 			resourcePresent := true
 			// 1st, send delete host order
 			err := servers.Delete(s.ComputeClient, id).ExtractErr()
 			if err != nil {
+				if conflictErr := DeleteHostConflictError(id, err, s.AttachedVolumeIDs); conflictErr != nil {
+					return conflictErr
+				}
 				return ReinterpretGophercloudErrorCode(
 					err, []int64{404}, []int64{408, 429, 500, 503}, []int64{409}, func(ferr error) error {
 						return fail.Errorf(
@@ -1255,10 +1514,10 @@ func (s *Stack) DeleteHost(id string) error {
 				)
 			}
 
-			// 2nd, check host status every 5 seconds until check failed.
+			// 2nd, check host status periodically until check failed.
 			// If check succeeds but state is Error, retry the deletion.
 			// If check fails and error isn't 'resource not found', retry
-			innerRetryErr := retry.WhileUnsuccessfulDelay5Seconds(
+			innerRetryErr := retry.WhileUnsuccessfulWithJitter(
 				func() error {
 					host, err := servers.Get(s.ComputeClient, id).Extract()
 					if err == nil {
@@ -1282,7 +1541,7 @@ func (s *Stack) DeleteHost(id string) error {
 					}
 					return rei
 				},
-				temporal.GetContextTimeout(),
+				temporal.GetMinDelay(), temporal.GetDefaultDelay(), temporal.GetContextTimeout(),
 			)
 			if innerRetryErr != nil {
 				if _, ok := innerRetryErr.(retry.ErrTimeout); ok {
@@ -1302,8 +1561,7 @@ func (s *Stack) DeleteHost(id string) error {
 			}
 			return fail.Errorf(fmt.Sprintf("host '%s' in state 'ERROR', retrying to delete", id), err)
 		},
-		0,
-		temporal.GetHostCleanupTimeout(),
+		temporal.GetMinDelay(), temporal.GetBigDelay(), temporal.GetHostCleanupTimeout(),
 	)
 	if outerRetryErr != nil {
 		return fail.Wrap(outerRetryErr, "error deleting host: retry error")
@@ -1311,6 +1569,57 @@ func (s *Stack) DeleteHost(id string) error {
 	return nil
 }
 
+// DeleteHostConflictError returns the error stopping the retries of the deletion of host 'id' when the provider refused
+// it with a conflict (409), the host being still used by a dependency that will not go away by itself; returns nil if
+// 'err' is not a conflict.
+// The error names the volumes still attached to the host, as listed by 'attachedVolumes' (may be nil).
+func DeleteHostConflictError(id string, err error, attachedVolumes func(string) []string) error {
+	if code, cerr := GetUnexpectedGophercloudErrorCode(err); cerr != nil || code != 409 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("host '%s' cannot be deleted while in use", id)
+	if attachedVolumes != nil {
+		if volumes := attachedVolumes(id); len(volumes) > 0 {
+			msg += fmt.Sprintf(" (attached volume(s): %s)", strings.Join(volumes, ", "))
+		}
+	}
+	return fail.AbortedError("", fail.NotAvailableError(fmt.Sprintf("%s: %s", msg, ProviderErrorToString(err))))
+}
+
+// fixedIPsRejectedError returns the error stopping the retries of the creation of host 'name' when the provider
+// refused the fixed IP(s) requested (400 or 409, the IP being out of the subnet or already used); returns nil if no
+// fixed IP was requested or if 'err' is another error
+func fixedIPsRejectedError(name string, fixedIPs map[string]string, err error) error {
+	if len(fixedIPs) == 0 {
+		return nil
+	}
+	if code, cerr := GetUnexpectedGophercloudErrorCode(err); cerr != nil || (code != 400 && code != 409) {
+		return nil
+	}
+	return fail.AbortedError(
+		"", fail.InvalidRequestError(
+			fmt.Sprintf(
+				"provider rejected the fixed IP(s) requested for host '%s': %s", name, ProviderErrorToString(err),
+			),
+		),
+	)
+}
+
+// AttachedVolumeIDs returns the IDs of the volumes attached to the host 'hostID', or nil if they cannot be listed
+func (s *Stack) AttachedVolumeIDs(hostID string) []string {
+	attachments, err := s.ListVolumeAttachments(hostID)
+	if err != nil {
+		logrus.Debugf("failed to list the volumes attached to host '%s': %v", hostID, err)
+		return nil
+	}
+	ids := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		ids = append(ids, a.VolumeID)
+	}
+	return ids
+}
+
 // StopHost stops the host identified by id
 func (s *Stack) StopHost(id string) error {
 	defer debug.NewTracer(nil, fmt.Sprintf("(%s)", id), true).WithStopwatch().GoingIn().OnExitTrace()()
@@ -1322,6 +1631,80 @@ func (s *Stack) StopHost(id string) error {
 	return nil
 }
 
+// GetHostConsoleOutput returns the last 'lines' lines (all if 0) of the console output of the host identified by id
+func (s *Stack) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	output, err := servers.ShowConsoleOutput(s.ComputeClient, id, servers.ShowConsoleOutputOpts{Length: lines}).Extract()
+	if err != nil {
+		return "", fail.Wrap(
+			err, fmt.Sprintf("failed to get console output of host '%s': %s", id, ProviderErrorToString(err)),
+		)
+	}
+	return output, nil
+}
+
+// CreateHostSnapshot creates the instance snapshot 'name' of the host identified by id and returns its image ID without
+// waiting for the image to be active
+func (s *Stack) CreateHostSnapshot(id string, name string) (_ string, xerr fail.Error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("(%s, %s)", id, name), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &xerr)()
+
+	imageID, err := servers.CreateImage(s.ComputeClient, id, servers.CreateImageOpts{Name: name}).ExtractImageID()
+	if err != nil {
+		return "", fail.Wrap(
+			err, fmt.Sprintf("failed to create snapshot of host '%s': %s", id, ProviderErrorToString(err)),
+		)
+	}
+	return imageID, nil
+}
+
+// WaitHostSnapshotReady waits until the snapshot identified by snapshotID is active
+func (s *Stack) WaitHostSnapshotReady(snapshotID string) (xerr fail.Error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("(%s)", snapshotID), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &xerr)()
+
+	retryErr := retry.WhileUnsuccessfulDelay5Seconds(
+		func() error {
+			img, innerErr := images.Get(s.ComputeClient, snapshotID).Extract()
+			if innerErr != nil {
+				return innerErr
+			}
+			switch img.Status {
+			case images.ImageStatusActive:
+				return nil
+			case images.ImageStatusKilled, images.ImageStatusDeleted, images.ImageStatusPendingDelete:
+				return retry.AbortedError(
+					"", fail.Errorf(fmt.Sprintf("snapshot '%s' failed (status '%s')", snapshotID, img.Status), nil),
+				)
+			default:
+				return fmt.Errorf("snapshot '%s' not ready yet (status '%s')", snapshotID, img.Status)
+			}
+		},
+		temporal.GetLongOperationTimeout(),
+	)
+	if retryErr != nil {
+		if aborted, ok := retryErr.(retry.ErrAborted); ok {
+			return aborted.Cause()
+		}
+		return fail.Wrap(retryErr, fmt.Sprintf("failed to wait for snapshot '%s'", snapshotID))
+	}
+	return nil
+}
+
+// RenameHost changes the name of the server identified by id; the hostname inside the server is not changed
+func (s *Stack) RenameHost(id string, newName string) (xerr fail.Error) {
+	tracer := debug.NewTracer(nil, fmt.Sprintf("(%s, %s)", id, newName), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &xerr)()
+
+	_, err := servers.Update(s.ComputeClient, id, servers.UpdateOpts{Name: newName}).Extract()
+	if err != nil {
+		return fail.Wrap(err, fmt.Sprintf("failed to rename host '%s': %s", id, ProviderErrorToString(err)))
+	}
+	return nil
+}
+
 // RebootHost reboots unconditionally the host identified by id
 func (s *Stack) RebootHost(id string) error {
 	defer debug.NewTracer(nil, fmt.Sprintf("(%s)", id), true).WithStopwatch().GoingIn().OnExitTrace()()
@@ -1362,3 +1745,8 @@ func (s *Stack) ResizeHost(id string, request abstract.SizingRequirements) (*abs
 
 	return nil, fail.NotImplementedError("ResizeHost() not implemented yet") // FIXME: Technical debt
 }
+
+// SetHostIPForwarding is not implemented for openstack
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	return fail.NotImplementedError("SetHostIPForwarding() not implemented for openstack")
+}
@@ -0,0 +1,174 @@
+package openstack
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+	"github.com/CS-SI/SafeScale/lib/utils/retry"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/bootfromvolume"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+)
+
+func conflictResponse() error {
+	return gophercloud.ErrDefault409{
+		ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{
+			Method: "DELETE",
+			Actual: 409,
+			Body:   []byte(`{"conflictingRequest": {"message": "volume in use", "code": 409}}`),
+		},
+	}
+}
+
+func TestDeleteHostConflictErrorAbortsOn409(t *testing.T) {
+	attached := func(id string) []string {
+		if id != "host-1" {
+			t.Errorf("volumes listed for '%s', expected 'host-1'", id)
+		}
+		return []string{"vol-1", "vol-2"}
+	}
+
+	err := DeleteHostConflictError("host-1", conflictResponse(), attached)
+	if err == nil {
+		t.Fatal("expected an error for a 409")
+	}
+	if _, ok := err.(fail.ErrAborted); !ok {
+		t.Fatalf("expected fail.ErrAborted, got %T", err)
+	}
+	if _, ok := fail.Cause(err).(fail.ErrNotAvailable); !ok {
+		t.Errorf("expected cause fail.ErrNotAvailable, got %T", fail.Cause(err))
+	}
+	if !strings.Contains(err.Error(), "vol-1, vol-2") {
+		t.Errorf("expected the attached volumes in '%s'", err.Error())
+	}
+
+	err = DeleteHostConflictError("host-1", conflictResponse(), nil)
+	if _, ok := err.(fail.ErrAborted); !ok {
+		t.Fatalf("expected fail.ErrAborted without volume lister, got %T", err)
+	}
+}
+
+func TestDeleteHostConflictErrorIgnoresOtherErrors(t *testing.T) {
+	listed := false
+	attached := func(string) []string {
+		listed = true
+		return nil
+	}
+
+	errs := []error{
+		gophercloud.ErrDefault500{ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{Actual: 500}},
+		gophercloud.ErrDefault404{ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{Actual: 404}},
+		fail.Errorf("connection reset", nil),
+	}
+	for _, e := range errs {
+		if err := DeleteHostConflictError("host-1", e, attached); err != nil {
+			t.Errorf("unexpected error for '%v': %v", e, err)
+		}
+	}
+	if listed {
+		t.Error("volumes listed whereas there was no conflict")
+	}
+}
+
+func TestFixedIPsRejectedError(t *testing.T) {
+	fixedIPs := map[string]string{"net-1": "192.168.0.10"}
+	badRequest := gophercloud.ErrDefault400{ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{Actual: 400}}
+
+	err := fixedIPsRejectedError("host-1", fixedIPs, badRequest)
+	if _, ok := err.(fail.ErrAborted); !ok {
+		t.Fatalf("expected fail.ErrAborted, got %T", err)
+	}
+	if _, ok := fail.Cause(err).(fail.ErrInvalidRequest); !ok {
+		t.Errorf("expected cause fail.ErrInvalidRequest, got %T", fail.Cause(err))
+	}
+	if err = fixedIPsRejectedError("host-1", fixedIPs, conflictResponse()); err == nil {
+		t.Error("expected an error for a 409")
+	}
+
+	if err = fixedIPsRejectedError("host-1", nil, badRequest); err != nil {
+		t.Errorf("unexpected error without fixed IP: %v", err)
+	}
+	serverError := gophercloud.ErrDefault500{ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{Actual: 500}}
+	if err = fixedIPsRejectedError("host-1", fixedIPs, serverError); err != nil {
+		t.Errorf("unexpected error for a 500: %v", err)
+	}
+}
+
+func TestDeleteHostConflictErrorStopsRetries(t *testing.T) {
+	tries := 0
+	err := retry.WhileUnsuccessfulWithJitter(
+		func() error {
+			tries++
+			return DeleteHostConflictError("host-1", conflictResponse(), nil)
+		},
+		10*time.Millisecond, 50*time.Millisecond, 5*time.Second,
+	)
+	if err == nil {
+		t.Fatal("expected the conflict to be returned")
+	}
+	if tries != 1 {
+		t.Errorf("deletion tried %d times on conflict, expected 1", tries)
+	}
+}
+
+func TestWithServerGroupAddsSchedulerHint(t *testing.T) {
+	opts := bootfromvolume.CreateOptsExt{
+		CreateOptsBuilder: withServerGroup(servers.CreateOpts{Name: "gw-net1", FlavorRef: "f1", ImageRef: "i1"}, "sg-1"),
+		BlockDevice: []bootfromvolume.BlockDevice{
+			{UUID: "i1", SourceType: bootfromvolume.SourceImage, VolumeSize: 20},
+		},
+	}
+	m, err := opts.ToServerCreateMap()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hints, ok := m["os:scheduler_hints"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected scheduler hints in create request, got %v", m)
+	}
+	if hints["group"] != "sg-1" {
+		t.Errorf("expected server group 'sg-1' in scheduler hints, got %v", hints["group"])
+	}
+}
+
+func TestWithServerGroupWithoutGroup(t *testing.T) {
+	m, err := withServerGroup(servers.CreateOpts{Name: "host1", FlavorRef: "f1", ImageRef: "i1"}, "").ToServerCreateMap()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := m["os:scheduler_hints"]; ok {
+		t.Errorf("expected no scheduler hints without server group, got %v", m)
+	}
+}
+
+func TestServerGroupPolicy(t *testing.T) {
+	if serverGroupPolicy(true) != "anti-affinity" || serverGroupPolicy(false) != "affinity" {
+		t.Errorf("unexpected server group policies")
+	}
+}
+
+func TestToAbstractImageDefaultUser(t *testing.T) {
+	tests := []struct {
+		properties map[string]interface{}
+		want       string
+	}{
+		{map[string]interface{}{"os_admin_user": "admin"}, "admin"},
+		{map[string]interface{}{"image_original_user": "debian"}, "debian"},
+		{map[string]interface{}{"os_admin_user": "admin", "image_original_user": "debian"}, "admin"},
+		{map[string]interface{}{"os_admin_user": ""}, ""},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		img := toAbstractImage(images.Image{ID: "id", Name: "Debian 10", Properties: test.properties})
+		if img.DefaultUser != test.want {
+			t.Errorf("DefaultUser of image with properties %v = %q, want %q", test.properties, img.DefaultUser, test.want)
+		}
+		if img.LoginUser() == "" {
+			t.Errorf("LoginUser of image with properties %v is empty", test.properties)
+		}
+	}
+}
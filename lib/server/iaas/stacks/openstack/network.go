@@ -93,6 +93,9 @@ func (s *Stack) CreateNetwork(req abstract.NetworkRequest) (newNet *abstract.Net
 	// Execute the operation and get back a networks.NetworkClient struct
 	network, err := networks.Create(s.NetworkClient, opts).Extract()
 	if err != nil {
+		if terr, ok := TranslateNeutronError(err); ok {
+			return nil, terr
+		}
 		return nil, fail.Errorf(
 			fmt.Sprintf("error creating network '%s': %s", req.Name, ProviderErrorToString(err)), err,
 		)
@@ -111,6 +114,10 @@ func (s *Stack) CreateNetwork(req abstract.NetworkRequest) (newNet *abstract.Net
 
 	subnet, err := s.createSubnet(req.Name, network.ID, req.CIDR, req.IPVersion, req.DNSServers)
 	if err != nil {
+		switch err.(type) {
+		case fail.ErrOverflow, fail.ErrNotAvailable, fail.ErrNotFound:
+			return nil, err
+		}
 		return nil, fail.Errorf(
 			fmt.Sprintf("error creating network '%s': %s", req.Name, ProviderErrorToString(err)), err,
 		)
@@ -313,6 +320,9 @@ func (s *Stack) DeleteNetwork(id string) error {
 	}
 	err = networks.Delete(s.NetworkClient, id).ExtractErr()
 	if err != nil {
+		if terr, ok := TranslateNeutronError(err); ok {
+			err = terr
+		}
 		switch err.(type) {
 		case fail.ErrNotAvailable:
 			return err
@@ -325,6 +335,115 @@ func (s *Stack) DeleteNetwork(id string) error {
 	return nil
 }
 
+// UpdateNetworkDNSServers replaces the DNS servers distributed by DHCP in the subnet of the network identified by id
+// Hosts already started keep their DNS servers until their DHCP lease is renewed
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	defer debug.NewTracer(nil, fmt.Sprintf("(%s, %v)", id, dnsServers), true).WithStopwatch().GoingIn().OnExitTrace()()
+
+	sns, err := s.listSubnets(id)
+	if err != nil {
+		return err
+	}
+	if len(sns) != 1 {
+		return fail.Errorf(fmt.Sprintf("bad configuration, each network should have exactly one subnet"), nil)
+	}
+
+	opts := subnets.UpdateOpts{
+		DNSNameservers: &dnsServers,
+	}
+	retryErr := retry.WhileUnsuccessfulDelay1Second(
+		func() error {
+			_, innerErr := subnets.Update(s.NetworkClient, sns[0].ID, opts).Extract()
+			if innerErr != nil {
+				return ReinterpretGophercloudErrorCode(
+					innerErr, nil, []int64{408, 409, 425, 429, 500, 503, 504}, nil, func(ferr error) error {
+						return fail.AbortedError(
+							"", fail.Errorf(
+								fmt.Sprintf("error updating DNS servers of subnet: %s", ProviderErrorToString(ferr)), ferr,
+							),
+						)
+					},
+				)
+			}
+			return nil
+		},
+		temporal.GetContextTimeout(),
+	)
+	if retryErr != nil {
+		if aborted, ok := retryErr.(retry.ErrAborted); ok {
+			return aborted.Cause()
+		}
+		return retryErr
+	}
+	return nil
+}
+
+// routerRoutesUpdateOpts sets the whole list of routes of a router, an empty list removing all the routes
+type routerRoutesUpdateOpts struct {
+	Routes []routers.Route `json:"routes"`
+}
+
+// ToRouterUpdateMap satisfies interface routers.UpdateOptsBuilder
+func (opts routerRoutesUpdateOpts) ToRouterUpdateMap() (map[string]interface{}, error) {
+	return gophercloud.BuildRequestBody(opts, "router")
+}
+
+// SetNetworkRoutes replaces the static routes of the router connecting the subnet of the network identified by id
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	defer debug.NewTracer(nil, fmt.Sprintf("(%s, %v)", id, routes), true).WithStopwatch().GoingIn().OnExitTrace()()
+
+	if !s.cfgOpts.UseLayer3Networking {
+		return fail.NotImplementedError("SetNetworkRoutes() not implemented for openstack without layer 3 networking")
+	}
+
+	sns, err := s.listSubnets(id)
+	if err != nil {
+		return err
+	}
+	if len(sns) != 1 {
+		return fail.Errorf(fmt.Sprintf("bad configuration, each network should have exactly one subnet"), nil)
+	}
+
+	// the router of a network is named after its subnet (see createSubnet)
+	list, err := s.ListRouters()
+	if err != nil {
+		return err
+	}
+	var router *Router
+	for i := range list {
+		if list[i].Name == sns[0].ID {
+			router = &list[i]
+			break
+		}
+	}
+	if router == nil {
+		return fail.NotFoundError(fmt.Sprintf("failed to find router of network '%s'", id))
+	}
+
+	opts := routerRoutesUpdateOpts{
+		Routes: make([]routers.Route, 0, len(routes)),
+	}
+	for _, r := range routes {
+		opts.Routes = append(opts.Routes, routers.Route{DestinationCIDR: r.Destination, NextHop: r.NextHop})
+	}
+	return retry.WhileUnsuccessfulDelay1Second(
+		func() error {
+			_, innerErr := routers.Update(s.NetworkClient, router.ID, opts).Extract()
+			if innerErr != nil {
+				return ReinterpretGophercloudErrorCode(
+					innerErr, nil, []int64{408, 409, 425, 429, 500, 503, 504}, nil, func(ferr error) error {
+						return fail.AbortedError(
+							fmt.Sprintf("error updating routes of router: %s", ProviderErrorToString(innerErr)), ferr,
+						)
+					},
+				)
+			}
+			return nil
+		},
+		temporal.GetContextTimeout(),
+	)
+}
+
 // CreateGateway creates a public Gateway for a private network
 func (s *Stack) CreateGateway(req abstract.GatewayRequest, sizing *abstract.SizingRequirements) (host *abstract.Host, userData *userdata.Content, xerr fail.Error) {
 	defer debug.NewTracer(nil, fmt.Sprintf("(%s)", req.Name), true).WithStopwatch().GoingIn().OnExitTrace()()
@@ -353,6 +472,9 @@ func (s *Stack) CreateGateway(req abstract.GatewayRequest, sizing *abstract.Sizi
 		Networks:     []*abstract.Network{req.Network},
 		PublicIP:     true,
 		Password:     password,
+
+		AffinityGroup: req.AffinityGroup,
+		AntiAffinity:  req.AntiAffinity,
 	}
 	if sizing != nil && sizing.MinDiskSize > 0 {
 		hostReq.DiskSize = sizing.MinDiskSize
@@ -475,6 +597,9 @@ func (s *Stack) createSubnet(name string, networkID string, cidr string, ipVersi
 			r := subnets.Create(s.NetworkClient, opts)
 			subnet, xerr = r.Extract()
 			if xerr != nil {
+				if terr, ok := TranslateNeutronError(xerr); ok {
+					return fail.AbortedError("", terr)
+				}
 				return ReinterpretGophercloudErrorCode(
 					xerr, nil, []int64{408, 409, 425, 429, 500, 503, 504}, nil, func(ferr error) error {
 						return fail.AbortedError(
@@ -490,6 +615,10 @@ func (s *Stack) createSubnet(name string, networkID string, cidr string, ipVersi
 	)
 
 	if createErr != nil {
+		switch cause := fail.Cause(createErr).(type) {
+		case fail.ErrOverflow, fail.ErrNotAvailable, fail.ErrNotFound:
+			return nil, cause
+		}
 		return nil, createErr
 	}
 
@@ -624,16 +753,19 @@ func (s *Stack) deleteSubnet(id string) error {
 		func() error {
 			r := subnets.Delete(s.NetworkClient, id)
 			err = r.ExtractErr()
-			if _, ok := err.(gophercloud.ErrUnexpectedResponseCode); ok {
-				neutronError := ParseNeutronError(err.Error())
-				switch neutronError["type"] {
-				case "SubnetInUse":
+			if isNotFoundError(err) {
+				// the subnet is already gone, which is what was asked
+				log.Debugf("subnet '%s' not found, considered as deleted", id)
+				err = nil
+				return nil
+			}
+			if terr, ok := TranslateNeutronError(err); ok {
+				if _, ok := terr.(fail.ErrNotAvailable); ok {
 					msg := "hosts or services are still attached"
 					log.Warnf(utils.Capitalize(msg))
 					return abstract.ResourceNotAvailableError("subnet", id)
-				default:
-					log.Debugf("NeutronError: type = %s", neutronError["type"])
 				}
+				return terr
 			} else if err != nil {
 				msg := fmt.Sprintf("failed to delete subnet '%s': %s", id, ProviderErrorToString(err))
 				log.Errorf(utils.Capitalize(msg))
@@ -664,6 +796,19 @@ func (s *Stack) deleteSubnet(id string) error {
 	return nil
 }
 
+// isNotFoundError tells if err, returned by Neutron, means the resource does not exist
+func isNotFoundError(err error) bool {
+	switch err.(type) {
+	case gophercloud.ErrDefault404, *gophercloud.ErrDefault404:
+		return true
+	}
+	if terr, ok := TranslateNeutronError(err); ok {
+		_, ok = terr.(fail.ErrNotFound)
+		return ok
+	}
+	return false
+}
+
 // createRouter creates a router satisfying req
 func (s *Stack) createRouter(req RouterRequest) (*Router, fail.Error) {
 	// Create a router to connect external Provider network
@@ -777,6 +922,50 @@ func (s *Stack) listPorts(options ports.ListOpts) ([]ports.Port, fail.Error) {
 	return ports.ExtractPorts(allPages)
 }
 
+// NameHostPorts gives to the ports of the host 'hostID' the names wanted, indexed by network ID
+// Ports on networks without name wanted are left unchanged
+func (s *Stack) NameHostPorts(hostID string, names map[string]string) fail.Error {
+	if len(names) == 0 {
+		return nil
+	}
+	hostPorts, err := s.listPorts(ports.ListOpts{DeviceID: hostID})
+	if err != nil {
+		return normalizeNeutronError(err)
+	}
+	for _, p := range hostPorts {
+		name, ok := names[p.NetworkID]
+		if !ok || name == "" || name == p.Name {
+			continue
+		}
+		_, err = ports.Update(s.NetworkClient, p.ID, ports.UpdateOpts{Name: &name}).Extract()
+		if err != nil {
+			return normalizeNeutronError(err)
+		}
+	}
+	return nil
+}
+
+// ListHostNetworkInterfaces returns the network interfaces of the host identified by id, from its ports
+func (s *Stack) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	hostPorts, err := s.listPorts(ports.ListOpts{DeviceID: id})
+	if err != nil {
+		return nil, normalizeNeutronError(err)
+	}
+	out := make([]abstract.HostNetworkInterface, 0, len(hostPorts))
+	for _, p := range hostPorts {
+		nic := abstract.HostNetworkInterface{
+			Name:       p.Name,
+			MACAddress: p.MACAddress,
+			NetworkID:  p.NetworkID,
+		}
+		for _, ip := range p.FixedIPs {
+			nic.PrivateIPs = append(nic.PrivateIPs, ip.IPAddress)
+		}
+		out = append(out, nic)
+	}
+	return out, nil
+}
+
 // CreateVIP creates a private virtual IP
 // If public is set to true,
 func (s *Stack) CreateVIP(networkID string, name string) (*abstract.VirtualIP, fail.Error) {
@@ -790,7 +979,10 @@ func (s *Stack) CreateVIP(networkID string, name string) (*abstract.VirtualIP, f
 	}
 	port, err := ports.Create(s.NetworkClient, options).Extract()
 	if err != nil {
-		return nil, fail.Wrap(err, "error crating VIP")
+		if terr, ok := TranslateNeutronError(err); ok {
+			return nil, terr
+		}
+		return nil, fail.Wrap(err, "error creating VIP")
 	}
 	vip := abstract.VirtualIP{
 		ID:        port.ID,
@@ -808,7 +1000,7 @@ func (s *Stack) AddPublicIPToVIP(vip *abstract.VirtualIP) error {
 func (s *Stack) BindHostToVIP(vip *abstract.VirtualIP, hostID string) error {
 	vipPort, err := ports.Get(s.NetworkClient, vip.ID).Extract()
 	if err != nil {
-		return err
+		return normalizeNeutronError(err)
 	}
 	hostPorts, err := s.listPorts(
 		ports.ListOpts{
@@ -829,7 +1021,7 @@ func (s *Stack) BindHostToVIP(vip *abstract.VirtualIP, hostID string) error {
 			s.NetworkClient, p.ID, ports.UpdateOpts{AllowedAddressPairs: &p.AllowedAddressPairs},
 		).Extract()
 		if err != nil {
-			return err
+			return normalizeNeutronError(err)
 		}
 	}
 	return nil
@@ -839,7 +1031,7 @@ func (s *Stack) BindHostToVIP(vip *abstract.VirtualIP, hostID string) error {
 func (s *Stack) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) error {
 	vipPort, err := ports.Get(s.NetworkClient, vip.ID).Extract()
 	if err != nil {
-		return err
+		return normalizeNeutronError(err)
 	}
 	hostPorts, err := s.listPorts(
 		ports.ListOpts{
@@ -861,7 +1053,7 @@ func (s *Stack) UnbindHostFromVIP(vip *abstract.VirtualIP, hostID string) error
 			s.NetworkClient, p.ID, ports.UpdateOpts{AllowedAddressPairs: &newAllowedAddressPairs},
 		).Extract()
 		if err != nil {
-			return err
+			return normalizeNeutronError(err)
 		}
 	}
 	return nil
@@ -875,5 +1067,5 @@ func (s *Stack) DeleteVIP(vip *abstract.VirtualIP) error {
 			return err
 		}
 	}
-	return ports.Delete(s.NetworkClient, vip.ID).ExtractErr()
+	return normalizeNeutronError(ports.Delete(s.NetworkClient, vip.ID).ExtractErr())
 }
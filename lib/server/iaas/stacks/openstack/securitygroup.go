@@ -21,10 +21,14 @@ import (
 
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 
+	computesecgroups "github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/secgroups"
 	secgroups "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
 	secrules "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
 	"github.com/gophercloud/gophercloud/pagination"
 
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
 )
 
@@ -32,7 +36,7 @@ import (
 func (s *Stack) GetSecurityGroup(name string) (*secgroups.SecGroup, fail.Error) {
 	var sgList []secgroups.SecGroup
 	opts := secgroups.ListOpts{
-		Name: s.DefaultSecurityGroupName,
+		Name: name,
 	}
 	err := secgroups.List(s.NetworkClient, opts).EachPage(
 		func(page pagination.Page) (bool, fail.Error) {
@@ -58,177 +62,176 @@ func (s *Stack) GetSecurityGroup(name string) (*secgroups.SecGroup, fail.Error)
 	return &sgList[0], nil
 }
 
-func (s *Stack) getDefaultSecurityGroup() (*secgroups.SecGroup, fail.Error) {
-	sg, err := s.GetSecurityGroup(s.DefaultSecurityGroupName)
-	if err != nil {
-		return nil, fail.Errorf(fmt.Sprintf("error listing routers: %s", ProviderErrorToString(err)), err)
-	}
+// providerDefaultSecurityGroupName is the name of the security group OpenStack binds to the ports created without
+// security group
+const providerDefaultSecurityGroupName = "default"
 
-	return sg, nil
+// SecurityGroupsForHost returns the names of the security groups to bind to the host created by 'request':
+// the default security group and the ones requested, which must exist
+// The default security group of the provider is added if the option KeepProviderDefaultSecurityGroup is set.
+func (s *Stack) SecurityGroupsForHost(request abstract.HostRequest) ([]string, fail.Error) {
+	names := []string{s.SecurityGroup.Name}
+	bound := map[string]bool{s.SecurityGroup.Name: true}
+	if s.cfgOpts.KeepProviderDefaultSecurityGroup && !bound[providerDefaultSecurityGroupName] {
+		names = append(names, providerDefaultSecurityGroupName)
+		bound[providerDefaultSecurityGroupName] = true
+	}
+	for _, name := range request.SecurityGroups {
+		if bound[name] {
+			continue
+		}
+		sg, err := s.GetSecurityGroup(name)
+		if err != nil {
+			return nil, err
+		}
+		if sg == nil {
+			return nil, abstract.ResourceNotFoundError("security group", name)
+		}
+		names = append(names, name)
+		bound[name] = true
+	}
+	return names, nil
 }
 
-// createTCPRules creates TCP rules to configure the default security group
-func (s *Stack) createTCPRules(groupID string) error {
-	// Open TCP Ports
-	ruleOpts := secrules.CreateOpts{
-		Direction:      secrules.DirIngress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType4,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolTCP,
-		RemoteIPPrefix: "0.0.0.0/0",
-	}
-
-	_, err := secrules.Create(s.NetworkClient, ruleOpts).Extract()
+// BindSecurityGroupToHost binds the security group named sgName to all the ports of the host identified by id
+func (s *Stack) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	err := computesecgroups.AddServer(s.ComputeClient, id, sgName).ExtractErr()
 	if err != nil {
-		return err
-	}
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirIngress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType6,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolTCP,
-		RemoteIPPrefix: "::/0",
-	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	if err != nil {
-		return err
+		return fail.Errorf(
+			fmt.Sprintf(
+				"failed to bind security group '%s' to host '%s': %s", sgName, id, ProviderErrorToString(err),
+			), err,
+		)
 	}
+	return nil
+}
 
-	// Outbound = egress == going to Outside
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirEgress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType4,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolTCP,
-		RemoteIPPrefix: "0.0.0.0/0",
-	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
+// UnbindSecurityGroupFromHost unbinds the security group named sgName from all the ports of the host identified by id
+func (s *Stack) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	err := computesecgroups.RemoveServer(s.ComputeClient, id, sgName).ExtractErr()
 	if err != nil {
-		return err
-	}
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirEgress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType6,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolTCP,
-		RemoteIPPrefix: "::/0",
+		return fail.Errorf(
+			fmt.Sprintf(
+				"failed to unbind security group '%s' from host '%s': %s", sgName, id, ProviderErrorToString(err),
+			), err,
+		)
 	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	return err
+	return nil
 }
 
-// createUDPRules creates UDP rules to configure the default security group
-func (s *Stack) createUDPRules(groupID string) error {
-	// Inbound == ingress == coming from Outside
-	ruleOpts := secrules.CreateOpts{
-		Direction:      secrules.DirIngress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType4,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolUDP,
-		RemoteIPPrefix: "0.0.0.0/0",
-	}
-	_, err := secrules.Create(s.NetworkClient, ruleOpts).Extract()
+// AddRulesToSecurityGroup adds the rules to the security group named sgName
+func (s *Stack) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	sg, err := s.GetSecurityGroup(sgName)
 	if err != nil {
-		return err
+		return fail.Errorf(
+			fmt.Sprintf("failed to get security group '%s': %s", sgName, ProviderErrorToString(err)), err,
+		)
 	}
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirIngress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType6,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolUDP,
-		RemoteIPPrefix: "::/0",
+	if sg == nil {
+		return abstract.ResourceNotFoundError("security group", sgName)
 	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
+	err = s.addRulesToSecurityGroup(sg.ID, rules)
 	if err != nil {
-		return err
+		if _, ok := err.(fail.ErrInvalidRequest); ok {
+			return err
+		}
+		return fail.Errorf(
+			fmt.Sprintf("failed to add rules to security group '%s': %s", sgName, ProviderErrorToString(err)), err,
+		)
 	}
+	return nil
+}
 
-	// Outbound = egress == going to Outside
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirEgress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType4,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolUDP,
-		RemoteIPPrefix: "0.0.0.0/0",
-	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
+func (s *Stack) getDefaultSecurityGroup() (*secgroups.SecGroup, fail.Error) {
+	sg, err := s.GetSecurityGroup(s.DefaultSecurityGroupName)
 	if err != nil {
-		return err
-	}
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirEgress,
-		PortRangeMin:   1,
-		PortRangeMax:   65535,
-		EtherType:      secrules.EtherType6,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolUDP,
-		RemoteIPPrefix: "::/0",
+		return nil, fail.Errorf(fmt.Sprintf("error listing routers: %s", ProviderErrorToString(err)), err)
 	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	return err
+
+	return sg, nil
 }
 
-// createICMPRules creates ICMP rules inside the default security group
-func (s *Stack) createICMPRules(groupID string) error {
-	// Inbound == ingress == coming from Outside
-	ruleOpts := secrules.CreateOpts{
-		Direction:      secrules.DirIngress,
-		EtherType:      secrules.EtherType4,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolICMP,
-		RemoteIPPrefix: "0.0.0.0/0",
-	}
-	_, err := secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	if err != nil {
-		return err
+// defaultSecurityGroupRules returns the rules of the default security group, opening all TCP, UDP and ICMP traffic
+// in both directions, for IPv4 and IPv6
+func defaultSecurityGroupRules() []abstract.SecurityGroupRule {
+	var rules []abstract.SecurityGroupRule
+	for _, protocol := range []string{"tcp", "udp", "icmp"} {
+		for _, direction := range []securitygroupruledirection.Enum{securitygroupruledirection.INGRESS, securitygroupruledirection.EGRESS} {
+			for _, etherType := range []ipversion.Enum{ipversion.IPv4, ipversion.IPv6} {
+				rule := abstract.SecurityGroupRule{
+					Direction: direction,
+					EtherType: etherType,
+					Protocol:  protocol,
+					IPRanges:  []string{"0.0.0.0/0"},
+				}
+				if etherType == ipversion.IPv6 {
+					rule.IPRanges = []string{"::/0"}
+				}
+				if protocol != "icmp" {
+					rule.PortFrom, rule.PortTo = 1, 65535
+				}
+				rules = append(rules, rule)
+			}
+		}
 	}
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirIngress,
-		EtherType:      secrules.EtherType6,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolICMP,
-		RemoteIPPrefix: "::/0",
+	return rules
+}
+
+// toSecRuleCreateOpts converts an abstract.SecurityGroupRule to the options creating it in the security group
+// 'groupID', one per IP range of the rule
+func toSecRuleCreateOpts(groupID string, rule abstract.SecurityGroupRule) ([]secrules.CreateOpts, fail.Error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
 	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	if err != nil {
-		return err
+	if rule.Stateless {
+		return nil, fail.InvalidRequestError("stateless security group rules are not supported by OpenStack")
 	}
 
-	// Outbound = egress == going to Outside
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirEgress,
-		EtherType:      secrules.EtherType4,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolICMP,
-		RemoteIPPrefix: "0.0.0.0/0",
+	opts := secrules.CreateOpts{
+		Description: rule.Description,
+		SecGroupID:  groupID,
+		Protocol:    secrules.RuleProtocol(rule.Protocol),
+	}
+	switch rule.Direction {
+	case securitygroupruledirection.INGRESS:
+		opts.Direction = secrules.DirIngress
+	case securitygroupruledirection.EGRESS:
+		opts.Direction = secrules.DirEgress
+	}
+	switch rule.EtherType {
+	case ipversion.IPv4:
+		opts.EtherType = secrules.EtherType4
+	case ipversion.IPv6:
+		opts.EtherType = secrules.EtherType6
+	}
+	opts.PortRangeMin, opts.PortRangeMax = rule.Ports()
+
+	if len(rule.IPRanges) == 0 {
+		return []secrules.CreateOpts{opts}, nil
 	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	if err != nil {
-		return err
+	list := make([]secrules.CreateOpts, 0, len(rule.IPRanges))
+	for _, cidr := range rule.IPRanges {
+		opts.RemoteIPPrefix = cidr
+		list = append(list, opts)
 	}
-	ruleOpts = secrules.CreateOpts{
-		Direction:      secrules.DirEgress,
-		EtherType:      secrules.EtherType6,
-		SecGroupID:     groupID,
-		Protocol:       secrules.ProtocolICMP,
-		RemoteIPPrefix: "::/0",
+	return list, nil
+}
+
+// addRulesToSecurityGroup creates the rules in the security group 'groupID'
+func (s *Stack) addRulesToSecurityGroup(groupID string, rules []abstract.SecurityGroupRule) error {
+	for _, rule := range rules {
+		list, err := toSecRuleCreateOpts(groupID, rule)
+		if err != nil {
+			return err
+		}
+		for _, opts := range list {
+			_, err := secrules.Create(s.NetworkClient, opts).Extract()
+			if err != nil {
+				return err
+			}
+		}
 	}
-	_, err = secrules.Create(s.NetworkClient, ruleOpts).Extract()
-	return err
+	return nil
 }
 
 // InitDefaultSecurityGroup create an open Security Group
@@ -259,19 +262,7 @@ func (s *Stack) InitDefaultSecurityGroup() error {
 		return err
 	}
 
-	err = s.createTCPRules(group.ID)
-	if err != nil {
-		secgroups.Delete(s.NetworkClient, group.ID)
-		return err
-	}
-
-	err = s.createUDPRules(group.ID)
-	if err != nil {
-		secgroups.Delete(s.NetworkClient, group.ID)
-		return err
-	}
-
-	err = s.createICMPRules(group.ID)
+	err = s.addRulesToSecurityGroup(group.ID, defaultSecurityGroupRules())
 	if err != nil {
 		secgroups.Delete(s.NetworkClient, group.ID)
 		return err
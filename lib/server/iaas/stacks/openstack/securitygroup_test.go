@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openstack
+
+import (
+	"testing"
+
+	secgroups "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	secrules "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestToSecRuleCreateOpts(t *testing.T) {
+	rule := abstract.SecurityGroupRule{
+		Direction: securitygroupruledirection.EGRESS,
+		EtherType: ipversion.IPv6,
+		Protocol:  "tcp",
+		PortFrom:  443,
+		IPRanges:  []string{"2001:db8::/32", "2001:db9::/32"},
+	}
+	list, err := toSecRuleCreateOpts("sg-id", rule)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, secrules.CreateOpts{
+		Direction:      secrules.DirEgress,
+		EtherType:      secrules.EtherType6,
+		SecGroupID:     "sg-id",
+		Protocol:       secrules.ProtocolTCP,
+		PortRangeMin:   443,
+		PortRangeMax:   443,
+		RemoteIPPrefix: "2001:db8::/32",
+	}, list[0])
+	assert.Equal(t, "2001:db9::/32", list[1].RemoteIPPrefix)
+
+	rule = abstract.SecurityGroupRule{
+		Direction: securitygroupruledirection.INGRESS,
+		EtherType: ipversion.IPv4,
+	}
+	list, err = toSecRuleCreateOpts("sg-id", rule)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, secrules.DirIngress, list[0].Direction)
+	assert.Equal(t, "", list[0].RemoteIPPrefix)
+}
+
+func TestToSecRuleCreateOptsRejectsUnsupportedRules(t *testing.T) {
+	rules := []abstract.SecurityGroupRule{
+		// Stateless rules are not supported by OpenStack
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Stateless: true},
+		// No direction
+		{EtherType: ipversion.IPv4, Protocol: "tcp"},
+		// IP range not matching ether type
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, IPRanges: []string{"::/0"}},
+		// Ports on ICMP
+		{Direction: securitygroupruledirection.INGRESS, EtherType: ipversion.IPv4, Protocol: "icmp", PortFrom: 22},
+	}
+	for _, rule := range rules {
+		_, err := toSecRuleCreateOpts("sg-id", rule)
+		assert.NotNil(t, err)
+		_, ok := err.(fail.ErrInvalidRequest)
+		assert.True(t, ok)
+	}
+}
+
+func TestDefaultSecurityGroupRules(t *testing.T) {
+	rules := defaultSecurityGroupRules()
+	assert.Equal(t, 12, len(rules))
+	for _, rule := range rules {
+		assert.Nil(t, rule.Validate())
+	}
+}
+
+func TestSecurityGroupsForHostKeepsProviderDefault(t *testing.T) {
+	s := &Stack{SecurityGroup: &secgroups.SecGroup{Name: stacks.DefaultSecurityGroupName}}
+	names, err := s.SecurityGroupsForHost(abstract.HostRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{stacks.DefaultSecurityGroupName}, names)
+
+	s.cfgOpts.KeepProviderDefaultSecurityGroup = true
+	names, err = s.SecurityGroupsForHost(abstract.HostRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{stacks.DefaultSecurityGroupName, "default"}, names)
+
+	// Requested explicitly, it is not bound twice
+	names, err = s.SecurityGroupsForHost(abstract.HostRequest{SecurityGroups: []string{"default"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{stacks.DefaultSecurityGroupName, "default"}, names)
+}
@@ -104,6 +104,9 @@ func TranslateError(err error) error {
 // ParseNeutronError parses neutron json error and returns fields
 func ParseNeutronError(neutronError string) map[string]string {
 	startIdx := strings.Index(neutronError, "{\"NeutronError\":")
+	if startIdx < 0 {
+		return nil
+	}
 	jsonError := strings.Trim(neutronError[startIdx:], " ")
 	unjsoned := map[string]map[string]interface{}{}
 	err := json.Unmarshal([]byte(jsonError), &unjsoned)
@@ -136,6 +139,90 @@ func ParseNeutronError(neutronError string) map[string]string {
 	return nil
 }
 
+// errorTypeTranslators maps the types of Neutron errors, and the kinds of Nova errors, to the function converting them
+// to SafeScale errors; supporting a new error type only requires to add an entry here
+var errorTypeTranslators = map[string]func(msg string) error{
+	// Neutron
+	"NetworkInUse":               func(msg string) error { return fail.NotAvailableError(msg) },
+	"SubnetInUse":                func(msg string) error { return fail.NotAvailableError(msg) },
+	"PortInUse":                  func(msg string) error { return fail.NotAvailableError(msg) },
+	"SecurityGroupInUse":         func(msg string) error { return fail.NotAvailableError(msg) },
+	"IpAddressGenerationFailure": func(msg string) error { return fail.OverflowError(msg, 0, nil) },
+	"OverQuota":                  func(msg string) error { return fail.OverflowError(msg, 0, nil) },
+	"NetworkNotFound":            func(msg string) error { return fail.NotFoundError(msg) },
+	"SubnetNotFound":             func(msg string) error { return fail.NotFoundError(msg) },
+	"PortNotFound":               func(msg string) error { return fail.NotFoundError(msg) },
+	"SecurityGroupNotFound":      func(msg string) error { return fail.NotFoundError(msg) },
+	// Nova
+	"overLimit":    func(msg string) error { return fail.OverflowError(msg, 0, nil) },
+	"itemNotFound": func(msg string) error { return fail.NotFoundError(msg) },
+}
+
+// TranslateNeutronError converts an error returned by Neutron (or Nova) to a SafeScale typed error, if the type
+// of the error is known (see errorTypeTranslators)
+// Returns the translated error and true, or nil and false if the error is not recognized
+func TranslateNeutronError(err error) (error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	err = fail.Cause(err)
+	body := providerErrorBody(err)
+
+	if content := ParseNeutronError(body); content != nil {
+		if translator, ok := errorTypeTranslators[content["type"]]; ok {
+			return translator(content["message"]), true
+		}
+		return nil, false
+	}
+
+	if kind, msg := parseNovaError(body); kind != "" {
+		if translator, ok := errorTypeTranslators[kind]; ok {
+			return translator(msg), true
+		}
+	}
+	return nil, false
+}
+
+// normalizeNeutronError returns the typed error corresponding to err if it is recognized, err otherwise
+func normalizeNeutronError(err error) error {
+	if terr, ok := TranslateNeutronError(err); ok {
+		return terr
+	}
+	return err
+}
+
+// providerErrorBody returns the body of the response carried by a gophercloud error, or the error message
+func providerErrorBody(err error) string {
+	v := reflect.Indirect(reflect.ValueOf(err))
+	if v.Kind() == reflect.Struct {
+		if field := v.FieldByName("Body"); field.IsValid() && field.Kind() == reflect.Slice && field.Len() > 0 {
+			if body, ok := field.Interface().([]byte); ok {
+				return string(body)
+			}
+		}
+	}
+	return err.Error()
+}
+
+// parseNovaError parses nova json error (like '{"overLimit": {"code": 413, "message": "..."}}') and returns
+// its kind and message
+func parseNovaError(novaError string) (string, string) {
+	startIdx := strings.Index(novaError, "{")
+	if startIdx < 0 {
+		return "", ""
+	}
+	unjsoned := map[string]map[string]interface{}{}
+	err := json.Unmarshal([]byte(strings.TrimSpace(novaError[startIdx:])), &unjsoned)
+	if err != nil || len(unjsoned) != 1 {
+		return "", ""
+	}
+	for kind, content := range unjsoned {
+		msg, _ := content["message"].(string)
+		return kind, msg
+	}
+	return "", ""
+}
+
 // Stack contains the needs to operate on stack OpenStack
 type Stack struct {
 	ComputeClient *gophercloud.ServiceClient
@@ -159,6 +246,9 @@ type Stack struct {
 
 	// selectedAvailabilityZone contains the last selected availability zone chosen
 	selectedAvailabilityZone string
+
+	// catalog keeps the lists of images and templates
+	catalog *stacks.CatalogCache
 }
 
 // New authenticates and returns a Stack pointer
@@ -189,6 +279,7 @@ func New(
 	s := Stack{
 		authOpts: auth,
 		cfgOpts:  cfg,
+		catalog:  stacks.NewCatalogCache(cfg.CatalogCacheTTL),
 	}
 
 	s.versions = map[string]string{
@@ -279,3 +370,20 @@ func New(
 
 	return &s, nil
 }
+
+// Reauthenticate asks for a new authentication token, used when a request failed with 401
+// Does nothing if reauthentication is not allowed by the authentication options
+func (s *Stack) Reauthenticate() fail.Error {
+	if s == nil {
+		return fail.InvalidInstanceError()
+	}
+	if s.Driver == nil {
+		return fail.InvalidInstanceContentError("s.Driver", "cannot be nil")
+	}
+
+	err := s.Driver.Reauthenticate(s.Driver.TokenID)
+	if err != nil {
+		return TranslateError(err)
+	}
+	return nil
+}
@@ -58,6 +58,14 @@ func ReinterpretGophercloudErrorCode(gopherErr error, success []int64, transpare
 			}
 		}
 
+		// Authentication failures are not expected to disappear by retrying, whatever the caller asked for
+		switch code {
+		case 401:
+			return fail.AbortedError("", fail.UnauthorizedError(ProviderErrorToString(gopherErr)))
+		case 403:
+			return fail.AbortedError("", fail.ForbiddenError(ProviderErrorToString(gopherErr)))
+		}
+
 		for _, tcode := range abort {
 			if tcode == code {
 				return fail.AbortedError("", gopherErr)
@@ -2,6 +2,8 @@ package openstack
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -52,3 +54,110 @@ func TestNotGophercloudErrorCodes(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func neutronErrorResponse(code int, body string) error {
+	return gophercloud.ErrUnexpectedResponseCode{
+		Method: "POST",
+		Actual: code,
+		Body:   []byte(body),
+	}
+}
+
+func TestTranslateNeutronError(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected error
+	}{
+		{
+			`{"NeutronError": {"type": "NetworkInUse", "message": "Unable to complete operation on network 42. There are one or more ports still in use on the network.", "detail": ""}}`,
+			fail.ErrNotAvailable{},
+		},
+		{
+			`{"NeutronError": {"type": "IpAddressGenerationFailure", "message": "No more IP addresses available on network 42.", "detail": ""}}`,
+			fail.ErrOverflow{},
+		},
+		{
+			`{"NeutronError": {"type": "OverQuota", "message": "Quota exceeded for resources: ['port'].", "detail": ""}}`,
+			fail.ErrOverflow{},
+		},
+		{
+			`{"NeutronError": {"type": "SecurityGroupInUse", "message": "Security Group 42 in use.", "detail": ""}}`,
+			fail.ErrNotAvailable{},
+		},
+		{
+			`{"NeutronError": {"type": "NetworkNotFound", "message": "Network 42 could not be found.", "detail": ""}}`,
+			fail.ErrNotFound{},
+		},
+		{
+			`{"overLimit": {"code": 413, "message": "Maximum number of ports exceeded"}}`,
+			fail.ErrOverflow{},
+		},
+	}
+	for _, c := range cases {
+		terr, ok := TranslateNeutronError(neutronErrorResponse(409, c.body))
+		if !ok {
+			t.Errorf("error not recognized: %s", c.body)
+			continue
+		}
+		if reflect.TypeOf(terr) != reflect.TypeOf(c.expected) {
+			t.Errorf("expected '%s', got '%s' for %s", reflect.TypeOf(c.expected), reflect.TypeOf(terr), c.body)
+		}
+	}
+
+	// message of the Neutron error is kept
+	terr, _ := TranslateNeutronError(neutronErrorResponse(409, cases[1].body))
+	if !strings.Contains(terr.Error(), "No more IP addresses available") {
+		t.Errorf("unexpected message: %s", terr.Error())
+	}
+}
+
+func TestTranslateUnknownNeutronError(t *testing.T) {
+	unknown := []error{
+		neutronErrorResponse(
+			409, `{"NeutronError": {"type": "SomethingNew", "message": "something went wrong", "detail": ""}}`,
+		),
+		neutronErrorResponse(500, "Internal Server Error"),
+		gophercloud.ErrDefault409{},
+		fail.Errorf(fmt.Sprintf("something else"), nil),
+		nil,
+	}
+	for _, err := range unknown {
+		if _, ok := TranslateNeutronError(err); ok {
+			t.Errorf("error '%v' should not be recognized", err)
+		}
+	}
+}
+
+func TestParseNeutronErrorWithoutNeutronError(t *testing.T) {
+	if ParseNeutronError("Internal Server Error") != nil {
+		t.FailNow()
+	}
+}
+
+func TestIsNotFoundError(t *testing.T) {
+	notFound := []error{
+		gophercloud.ErrDefault404{ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{Actual: 404}},
+		&gophercloud.ErrDefault404{ErrUnexpectedResponseCode: gophercloud.ErrUnexpectedResponseCode{Actual: 404}},
+		neutronErrorResponse(
+			404, `{"NeutronError": {"type": "SubnetNotFound", "message": "Subnet 42 could not be found.", "detail": ""}}`,
+		),
+	}
+	for _, err := range notFound {
+		if !isNotFoundError(err) {
+			t.Errorf("error '%v' should mean not found", err)
+		}
+	}
+
+	others := []error{
+		neutronErrorResponse(
+			409, `{"NeutronError": {"type": "SubnetInUse", "message": "Subnet 42 in use.", "detail": ""}}`,
+		),
+		gophercloud.ErrDefault500{},
+		nil,
+	}
+	for _, err := range others {
+		if isNotFoundError(err) {
+			t.Errorf("error '%v' should not mean not found", err)
+		}
+	}
+}
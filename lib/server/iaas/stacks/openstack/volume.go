@@ -116,6 +116,7 @@ func (s *Stack) CreateVolume(request abstract.VolumeRequest) (volume *abstract.V
 				Name:             request.Name,
 				Size:             request.Size,
 				VolumeType:       s.getVolumeType(request.Speed),
+				Metadata:         request.Tags,
 			},
 		).Extract()
 		if err != nil {
@@ -142,6 +143,7 @@ func (s *Stack) CreateVolume(request abstract.VolumeRequest) (volume *abstract.V
 				Name:             request.Name,
 				Size:             request.Size,
 				VolumeType:       s.getVolumeType(request.Speed),
+				Metadata:         request.Tags,
 			},
 		).Extract()
 		if err != nil {
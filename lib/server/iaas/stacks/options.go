@@ -18,6 +18,7 @@ package stacks
 
 import (
 	"regexp"
+	"time"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/volumespeed"
 )
@@ -111,6 +112,17 @@ type ConfigurationOptions struct {
 	ProviderName     string
 	BuildSubnetworks bool
 
+	// KeepProviderDefaultSecurityGroup tells to bind the default security group of the provider (usually named
+	// 'default') to the hosts, in addition to the ones managed by SafeScale; by default, it is not bound
+	KeepProviderDefaultSecurityGroup bool
+
+	// CatalogCacheTTL is the time the lists of images and templates are kept by the stack; 0 disables the cache
+	CatalogCacheTTL time.Duration
+
+	// SystemDiskSizes gives the size of the system disk of the hosts by number of cores, when neither the request, the
+	// template nor the image tell it; nil means DefaultSystemDiskSizes
+	SystemDiskSizes []SystemDiskSizeTier
+
 	// AutoHostNetworkInterfaces indicates if network interfaces are configured automatically by the provider or needs a post configuration
 	AutoHostNetworkInterfaces bool
 
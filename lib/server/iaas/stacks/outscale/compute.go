@@ -77,6 +77,7 @@ func (s *Stack) ListImages(bool) ([]abstract.Image, fail.Error) {
 				Name:        normalizeImageName(omi.ImageName),
 				URL:         omi.FileLocation,
 				StorageType: omi.RootDeviceType,
+				OSFamily:    abstract.GuessOSFamily(omi.ImageName),
 			},
 		)
 	}
@@ -313,6 +314,9 @@ func (s *Stack) ListTemplates(bool) ([]abstract.HostTemplate, fail.Error) {
 	return templates, nil
 }
 
+// RefreshCatalog does nothing, the templates and images are not kept in cache
+func (s *Stack) RefreshCatalog() {}
+
 // GetImage returns the Image referenced by id
 func (s *Stack) GetImage(id string) (_ *abstract.Image, xerr fail.Error) {
 	defer func() {
@@ -346,6 +350,7 @@ func (s *Stack) GetImage(id string) (_ *abstract.Image, xerr fail.Error) {
 		Name:        img.ImageName,
 		StorageType: img.RootDeviceType,
 		URL:         img.FileLocation,
+		OSFamily:    abstract.GuessOSFamily(img.ImageName),
 	}, nil
 }
 
@@ -706,6 +711,7 @@ func (s *Stack) setHostProperties(host *abstract.Host, networks []*abstract.Netw
 			hpDescriptionV1 := clonable.(*propertiesv1.HostDescription)
 			hpDescriptionV1.Created = time.Now()
 			hpDescriptionV1.Updated = time.Now()
+			hpDescriptionV1.AvailabilityZone = vm.Placement.SubregionName
 			return nil
 		},
 	)
@@ -856,6 +862,12 @@ func (s *Stack) addPublicIPs(primaryNIC *osc.Nic, otherNICs []osc.Nic) (*osc.Pub
 // CreateHost creates an host that fulfills the request
 func (s *Stack) CreateHost(request abstract.HostRequest) (_ *abstract.Host, _ *userdata.Content, xerr fail.Error) {
 	userData := userdata.NewContent()
+	if len(request.SecurityGroups) > 0 {
+		return nil, userData, fail.NotImplementedError("binding security groups at host creation is not implemented yet")
+	}
+	if len(request.NICs) > 0 {
+		return nil, userData, fail.NotImplementedError("ordering or naming network interfaces at host creation is not implemented yet")
+	}
 	if request.DefaultGateway == nil && !request.PublicIP {
 		return nil, userData, abstract.ResourceInvalidRequestError(
 			"host creation", "cannot create a host without public IP or without attached network",
@@ -1297,6 +1309,31 @@ func (s *Stack) StartHost(id string) error {
 	return normalizeError(err)
 }
 
+// ListHostNetworkInterfaces is not implemented for outscale
+func (s *Stack) ListHostNetworkInterfaces(id string) ([]abstract.HostNetworkInterface, fail.Error) {
+	return nil, fail.NotImplementedError("ListHostNetworkInterfaces() not implemented for outscale")
+}
+
+// CreateHostSnapshot is not implemented for outscale
+func (s *Stack) CreateHostSnapshot(id string, name string) (string, fail.Error) {
+	return "", fail.NotImplementedError("CreateHostSnapshot() not implemented for outscale")
+}
+
+// WaitHostSnapshotReady is not implemented for outscale
+func (s *Stack) WaitHostSnapshotReady(snapshotID string) fail.Error {
+	return fail.NotImplementedError("WaitHostSnapshotReady() not implemented for outscale")
+}
+
+// RenameHost renames the host identified by id
+func (s *Stack) RenameHost(id string, newName string) fail.Error {
+	return fail.NotImplementedError("RenameHost() not implemented for outscale")
+}
+
+// GetHostConsoleOutput is not implemented for outscale
+func (s *Stack) GetHostConsoleOutput(id string, lines int) (string, fail.Error) {
+	return "", fail.NotImplementedError("GetHostConsoleOutput() not implemented for outscale")
+}
+
 // RebootHost Reboot host
 func (s *Stack) RebootHost(id string) error {
 	rebootVmsRequest := osc.RebootVmsRequest{
@@ -1344,3 +1381,18 @@ func (s *Stack) ResizeHost(id string, request abstract.SizingRequirements) (*abs
 
 	return s.InspectHost(id)
 }
+
+// SetHostIPForwarding is not implemented for outscale
+func (s *Stack) SetHostIPForwarding(host *abstract.Host, enabled bool) fail.Error {
+	return fail.NotImplementedError("SetHostIPForwarding() not implemented for outscale")
+}
+
+// BindSecurityGroupToHost is not implemented for outscale
+func (s *Stack) BindSecurityGroupToHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("BindSecurityGroupToHost() not implemented for outscale")
+}
+
+// UnbindSecurityGroupFromHost is not implemented for outscale
+func (s *Stack) UnbindSecurityGroupFromHost(id string, sgName string) fail.Error {
+	return fail.NotImplementedError("UnbindSecurityGroupFromHost() not implemented for outscale")
+}
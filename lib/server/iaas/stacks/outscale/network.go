@@ -356,3 +356,13 @@ func (s *Stack) DeleteNetwork(id string) error {
 
 	return s.deleteSubnet(id)
 }
+
+// UpdateNetworkDNSServers is not implemented for outscale
+func (s *Stack) UpdateNetworkDNSServers(id string, dnsServers []string) fail.Error {
+	return fail.NotImplementedError("UpdateNetworkDNSServers() not implemented for outscale")
+}
+
+// SetNetworkRoutes is not implemented for outscale
+func (s *Stack) SetNetworkRoutes(id string, routes []abstract.NetworkRoute) fail.Error {
+	return fail.NotImplementedError("SetNetworkRoutes() not implemented for outscale")
+}
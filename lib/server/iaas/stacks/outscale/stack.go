@@ -7,6 +7,7 @@ import (
 
 	"github.com/outscale-dev/osc-sdk-go/osc"
 
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/volumespeed"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/stacks"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -167,6 +168,11 @@ func (s *Stack) ListRegions() ([]string, fail.Error) {
 	}, nil
 }
 
+// GetQuotas returns the limits and usages of the tenant resources
+func (s *Stack) GetQuotas() (*abstract.Quotas, fail.Error) {
+	return nil, fail.NotImplementedError("GetQuotas() not implemented yet") // FIXME: Technical debt
+}
+
 // ListAvailabilityZones returns availability zone in a set
 func (s *Stack) ListAvailabilityZones() (map[string]bool, fail.Error) {
 	resp, _, err := s.client.SubregionApi.ReadSubregions(s.auth, nil)
@@ -25,6 +25,8 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/securitygroupruledirection"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 )
 
@@ -177,41 +179,123 @@ func (s *Stack) createInternetService(req abstract.NetworkRequest, onet *osc.Net
 	return s.updateRouteTable(onet, &isResp.InternetService)
 }
 
-// open all ports, ingress is controlled by the vm firewall
-func (s *Stack) createTCPPermissions() []osc.SecurityGroupRule {
-	rule := osc.SecurityGroupRule{
-		FromPortRange: 1,
-		ToPortRange:   65535,
-		IpRanges:      []string{"0.0.0.0/0"},
-		IpProtocol:    "tcp",
+// defaultSecurityGroupRules returns the rules of the default security group, opening all TCP, UDP and ICMP traffic
+// in both directions; the traffic is controlled by the firewall of the hosts
+func defaultSecurityGroupRules() []abstract.SecurityGroupRule {
+	var rules []abstract.SecurityGroupRule
+	for _, direction := range []securitygroupruledirection.Enum{securitygroupruledirection.INGRESS, securitygroupruledirection.EGRESS} {
+		for _, protocol := range []string{"tcp", "udp", "icmp"} {
+			rule := abstract.SecurityGroupRule{
+				Direction: direction,
+				EtherType: ipversion.IPv4,
+				Protocol:  protocol,
+				IPRanges:  []string{"0.0.0.0/0"},
+			}
+			if protocol != "icmp" {
+				rule.PortFrom, rule.PortTo = 1, 65535
+			}
+			rules = append(rules, rule)
+		}
 	}
-	return []osc.SecurityGroupRule{rule}
+	return rules
 }
 
-// open all ports, ingress is controlled by the vm firewall
-func (s *Stack) createUDPPermissions() []osc.SecurityGroupRule {
-	rule := osc.SecurityGroupRule{
-		FromPortRange: 1,
-		ToPortRange:   65535,
-		IpRanges:      []string{"0.0.0.0/0"},
-		IpProtocol:    "udp",
+// toOscSecurityGroupRule converts an abstract.SecurityGroupRule to the Outscale rule allowing the same traffic, and
+// returns the flow (Inbound or Outbound) of the rule
+// Outscale security groups are stateful and IPv4 only.
+func toOscSecurityGroupRule(rule abstract.SecurityGroupRule) (string, osc.SecurityGroupRule, fail.Error) {
+	if err := rule.Validate(); err != nil {
+		return "", osc.SecurityGroupRule{}, err
+	}
+	if rule.Stateless {
+		return "", osc.SecurityGroupRule{}, fail.InvalidRequestError(
+			"stateless security group rules are not supported by Outscale",
+		)
+	}
+	if rule.EtherType != ipversion.IPv4 {
+		return "", osc.SecurityGroupRule{}, fail.InvalidRequestError(
+			"IPv6 security group rules are not supported by Outscale",
+		)
 	}
-	return []osc.SecurityGroupRule{rule}
+
+	flow := "Inbound"
+	if rule.Direction == securitygroupruledirection.EGRESS {
+		flow = "Outbound"
+	}
+	out := osc.SecurityGroupRule{
+		IpProtocol: rule.Protocol,
+		IpRanges:   rule.IPRanges,
+	}
+	if len(out.IpRanges) == 0 {
+		out.IpRanges = []string{"0.0.0.0/0"}
+	}
+	switch rule.Protocol {
+	case "":
+		out.IpProtocol = "-1"
+	case "icmp":
+		out.FromPortRange, out.ToPortRange = -1, -1
+	default:
+		from, to := rule.Ports()
+		if from == 0 {
+			from, to = 1, 65535
+		}
+		out.FromPortRange, out.ToPortRange = int32(from), int32(to)
+	}
+	return flow, out, nil
+}
+
+// addSecurityGroupRules creates the rules in the security group identified by groupID
+func (s *Stack) addSecurityGroupRules(groupID string, rules []abstract.SecurityGroupRule) error {
+	byFlow := map[string][]osc.SecurityGroupRule{}
+	for _, rule := range rules {
+		flow, oscRule, err := toOscSecurityGroupRule(rule)
+		if err != nil {
+			return err
+		}
+		byFlow[flow] = append(byFlow[flow], oscRule)
+	}
+	for _, flow := range []string{"Inbound", "Outbound"} {
+		if len(byFlow[flow]) == 0 {
+			continue
+		}
+		createSecurityGroupRuleRequest := osc.CreateSecurityGroupRuleRequest{
+			SecurityGroupId: groupID,
+			Rules:           byFlow[flow],
+			Flow:            flow,
+		}
+		_, _, err := s.client.SecurityGroupRuleApi.CreateSecurityGroupRule(
+			s.auth, &osc.CreateSecurityGroupRuleOpts{
+				CreateSecurityGroupRuleRequest: optional.NewInterface(createSecurityGroupRuleRequest),
+			},
+		)
+		if err != nil {
+			return normalizeError(err)
+		}
+	}
+	return nil
 }
 
-// ingress is controlled by the vm firewall
-func (s *Stack) createICMPPermissions() []osc.SecurityGroupRule {
-	var rules []osc.SecurityGroupRule
-	// Echo reply
-	rules = append(
-		rules, osc.SecurityGroupRule{
-			FromPortRange: -1,
-			ToPortRange:   -1,
-			IpRanges:      []string{"0.0.0.0/0"},
-			IpProtocol:    "icmp",
+// AddRulesToSecurityGroup adds the rules to the security group named sgName of the network of the tenant
+func (s *Stack) AddRulesToSecurityGroup(sgName string, rules []abstract.SecurityGroupRule) fail.Error {
+	readSecurityGroupsRequest := osc.ReadSecurityGroupsRequest{
+		Filters: osc.FiltersSecurityGroup{
+			SecurityGroupNames: []string{sgName},
+		},
+	}
+	res, _, err := s.client.SecurityGroupApi.ReadSecurityGroups(
+		s.auth, &osc.ReadSecurityGroupsOpts{
+			ReadSecurityGroupsRequest: optional.NewInterface(readSecurityGroupsRequest),
 		},
 	)
-	return rules
+	if err != nil {
+		return normalizeError(err)
+	}
+	for _, sg := range res.SecurityGroups {
+		if sg.NetId == s.Options.Network.VPCID {
+			return s.addSecurityGroupRules(sg.SecurityGroupId, rules)
+		}
+	}
+	return abstract.ResourceNotFoundError("security group", sgName)
 }
 
 func (s *Stack) removeDefaultSecurityRules(sg *osc.SecurityGroup) error {
@@ -242,32 +326,7 @@ func (s *Stack) removeDefaultSecurityRules(sg *osc.SecurityGroup) error {
 }
 
 func (s *Stack) updateDefaultSecurityRules(sg *osc.SecurityGroup) error {
-	rules := append(s.createTCPPermissions(), s.createUDPPermissions()...)
-	rules = append(rules, s.createICMPPermissions()...)
-	createSecurityGroupRuleRequest := osc.CreateSecurityGroupRuleRequest{
-		SecurityGroupId: sg.SecurityGroupId,
-		Rules:           rules,
-		Flow:            "Inbound",
-	}
-	_, _, err := s.client.SecurityGroupRuleApi.CreateSecurityGroupRule(
-		s.auth, &osc.CreateSecurityGroupRuleOpts{
-			CreateSecurityGroupRuleRequest: optional.NewInterface(createSecurityGroupRuleRequest),
-		},
-	)
-	if err != nil {
-		return normalizeError(err)
-	}
-	createSecurityGroupRuleRequest = osc.CreateSecurityGroupRuleRequest{
-		SecurityGroupId: sg.SecurityGroupId,
-		Rules:           rules,
-		Flow:            "Outbound",
-	}
-	_, _, err = s.client.SecurityGroupRuleApi.CreateSecurityGroupRule(
-		s.auth, &osc.CreateSecurityGroupRuleOpts{
-			CreateSecurityGroupRuleRequest: optional.NewInterface(createSecurityGroupRuleRequest),
-		},
-	)
-	return normalizeError(err)
+	return s.addSecurityGroupRules(sg.SecurityGroupId, defaultSecurityGroupRules())
 }
 
 func (s *Stack) getNetworkSecurityGroup(netID string) (*osc.SecurityGroup, fail.Error) {
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iaas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitMandatoryTags(t *testing.T) {
+	tags, err := initMandatoryTags(map[string]interface{}{})
+	require.Nil(t, err)
+	assert.Nil(t, tags)
+
+	tags, err = initMandatoryTags(
+		map[string]interface{}{
+			"compute": map[string]interface{}{
+				"MandatoryTags": map[string]interface{}{"CostCenter": "", "Project": "safescale"},
+			},
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"CostCenter": "", "Project": "safescale"}, tags)
+
+	for _, v := range []interface{}{"CostCenter", map[string]interface{}{"CostCenter": 42}, map[string]interface{}{"": "x"}} {
+		_, err = initMandatoryTags(map[string]interface{}{"compute": map[string]interface{}{"MandatoryTags": v}})
+		assert.NotNil(t, err, v)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resultstatus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Enum represents the status of the execution of a feature step (or of a set of steps)
+type Enum uint8
+
+const (
+	_ Enum = iota
+
+	// Succeeded means the script ran to completion successfully
+	Succeeded
+	// Failed means the script ran to completion but returned a non-zero retcode
+	Failed
+	// Aborted means the script could not be run to completion (upload failure, SSH error, ...)
+	Aborted
+
+	// NextEnum marks the next value (or the max, depending the use)
+	NextEnum
+)
+
+var (
+	stringMap = map[string]Enum{
+		"succeeded": Succeeded,
+		"failed":    Failed,
+		"aborted":   Aborted,
+	}
+
+	enumMap = map[Enum]string{
+		Succeeded: "Succeeded",
+		Failed:    "Failed",
+		Aborted:   "Aborted",
+	}
+)
+
+// Parse returns a Enum corresponding to the string parameter
+// If the string doesn't correspond to any Enum, returns an error (nil otherwise)
+// This function is intended to be used to parse user input.
+func Parse(v string) (Enum, error) {
+	var (
+		e  Enum
+		ok bool
+	)
+	lowered := strings.ToLower(v)
+	if e, ok = stringMap[lowered]; !ok {
+		return e, fmt.Errorf("failed to find a ResultStatus.Enum corresponding to '%s'", v)
+	}
+	return e, nil
+
+}
+
+// String returns a string representation of an Enum
+func (e Enum) String() string {
+	if str, found := enumMap[e]; found {
+		return str
+	}
+	panic(fmt.Sprintf("failed to find a ResultStatus.Enum string corresponding to value '%d'!", e))
+}
+
+// MarshalText encodes the Enum as its string representation (used by JSON encoding)
+func (e Enum) MarshalText() ([]byte, error) {
+	if str, found := enumMap[e]; found {
+		return []byte(str), nil
+	}
+	return nil, fmt.Errorf("failed to find a ResultStatus.Enum string corresponding to value '%d'", e)
+}
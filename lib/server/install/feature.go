@@ -68,6 +68,13 @@ type Settings struct {
 	SkipSizingRequirements bool
 	// AddUnconditionally tells to not check before addition (no effect for check or removal)
 	AddUnconditionally bool
+	// WaitCloudInit tells to wait cloud-init has finished on the host before addition, to not compete with it for
+	// the package manager locks (no effect on cluster targets)
+	WaitCloudInit bool
+	// ForceMethod, if set, is the installation method to use instead of the one preferred for the target (for
+	// example method.Bash when a package is not available in the repositories of the distribution); it applies to the
+	// feature only, not to its requirements
+	ForceMethod method.Enum
 }
 
 // Feature contains the information about an installable feature
@@ -256,11 +263,60 @@ func (f *Feature) installerOfMethod(m method.Enum) Installer {
 	return installer
 }
 
+// selectInstaller returns the installer to use for 'action' on the target: the one of s.ForceMethod if set, the one
+// of the method of highest priority on the target that the feature supports otherwise
+func (f *Feature) selectInstaller(t Target, s Settings, action string) (Installer, error) {
+	methods := t.Methods()
+	if s.ForceMethod != 0 {
+		available := false
+		for _, meth := range methods {
+			if meth == s.ForceMethod {
+				available = true
+				break
+			}
+		}
+		if !available {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("method '%s' is not available on %s '%s'", s.ForceMethod.String(), t.Type(), t.Name()),
+			)
+		}
+		if !f.specs.IsSet(fmt.Sprintf("feature.install.%s", strings.ToLower(s.ForceMethod.String()))) {
+			return nil, fail.InvalidRequestError(
+				fmt.Sprintf("feature '%s' cannot be handled with method '%s'", f.DisplayName(), s.ForceMethod.String()),
+			)
+		}
+		installer := f.installerOfMethod(s.ForceMethod)
+		if installer == nil {
+			return nil, fmt.Errorf(
+				"failed to find a way to %s '%s' using method '%s'", action, f.DisplayName(), s.ForceMethod.String(),
+			)
+		}
+		return installer, nil
+	}
+
+	var i uint8
+	for i = 1; i <= uint8(len(methods)); i++ {
+		meth := methods[i]
+		if f.specs.IsSet(fmt.Sprintf("feature.install.%s", strings.ToLower(meth.String()))) {
+			installer := f.installerOfMethod(meth)
+			if installer != nil {
+				return installer, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("failed to find a way to %s '%s'", action, f.DisplayName())
+}
+
 // DisplayName returns the name of the feature
 func (f *Feature) DisplayName() string {
 	return f.displayName
 }
 
+// Version returns the version of the feature declared in its specification (key 'feature.version'), empty if none
+func (f *Feature) Version() string {
+	return f.specs.GetString("feature.version")
+}
+
 // Filename returns the name of the feature
 func (f *Feature) Filename() string {
 	return f.displayName
@@ -312,18 +368,9 @@ func (f *Feature) Check(t Target, v Variables, s Settings) (_ Results, err error
 	// 	return anon.(Results), nil
 	// }
 
-	methods := t.Methods()
-	var installer Installer
-	for _, meth := range methods {
-		if f.specs.IsSet(fmt.Sprintf("feature.install.%s", strings.ToLower(meth.String()))) {
-			installer = f.installerOfMethod(meth)
-			if installer != nil {
-				break
-			}
-		}
-	}
-	if installer == nil {
-		return nil, fmt.Errorf("failed to find a way to check '%s'", f.DisplayName())
+	installer, err := f.selectInstaller(t, s, "check")
+	if err != nil {
+		return nil, err
 	}
 
 	logrus.Debugf("Checking if feature '%s' is installed on %s '%s'...\n", f.DisplayName(), t.Type(), t.Name())
@@ -364,22 +411,9 @@ func (f *Feature) Add(t Target, v Variables, s Settings) (_ Results, err error)
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
-	methods := t.Methods()
-	var (
-		installer Installer
-		i         uint8
-	)
-	for i = 1; i <= uint8(len(methods)); i++ {
-		meth := methods[i]
-		if f.specs.IsSet(fmt.Sprintf("feature.install.%s", strings.ToLower(meth.String()))) {
-			installer = f.installerOfMethod(meth)
-			if installer != nil {
-				break
-			}
-		}
-	}
-	if installer == nil {
-		return nil, fmt.Errorf("failed to find a way to install '%s'", f.DisplayName())
+	installer, err := f.selectInstaller(t, s, "install")
+	if err != nil {
+		return nil, err
 	}
 
 	defer temporal.NewStopwatch().OnExitLogWithLevel(
@@ -406,6 +440,13 @@ func (f *Feature) Add(t Target, v Variables, s Settings) (_ Results, err error)
 		return nil, err
 	}
 
+	if s.WaitCloudInit {
+		err = waitCloudInitDone(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if !s.AddUnconditionally {
 		results, err := f.Check(t, v, s)
 		if err != nil {
@@ -418,7 +459,9 @@ func (f *Feature) Add(t Target, v Variables, s Settings) (_ Results, err error)
 	}
 
 	if !s.SkipFeatureRequirements {
-		err := f.installRequirements(t, v, s)
+		rs := s
+		rs.ForceMethod = 0
+		err := f.installRequirements(t, v, rs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to install requirements: %s", err.Error())
 		}
@@ -444,21 +487,10 @@ func (f *Feature) Remove(t Target, v Variables, s Settings) (_ Results, err erro
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
-	var (
-		results   Results
-		installer Installer
-	)
-	methods := t.Methods()
-	for _, meth := range methods {
-		if f.specs.IsSet(fmt.Sprintf("feature.install.%s", strings.ToLower(meth.String()))) {
-			installer = f.installerOfMethod(meth)
-			if installer != nil {
-				break
-			}
-		}
-	}
-	if installer == nil {
-		return nil, fmt.Errorf("failed to find a way to uninstall '%s'", f.DisplayName())
+	var results Results
+	installer, err := f.selectInstaller(t, s, "uninstall")
+	if err != nil {
+		return nil, err
 	}
 
 	defer temporal.NewStopwatch().OnExitLogInfo(
@@ -609,12 +641,17 @@ func (f *Feature) setImplicitParameters(t Target, v Variables) error {
 			return fail.InvalidParameterError("t", "must be a HostTarget or NodeTarget")
 		}
 
-		// FIXME: host may be on a network with 2 gateways + missing variables like DefaultRouteIP, ...
-		gw := gatewayFromHost(host)
+		// FIXME: missing variables like DefaultRouteIP, ...
+		gw, secondaryGW := gatewaysFromHost(host)
 		if gw != nil {
 			v["GatewayIP"] = gw.PrivateIp // legacy
 			v["PrimaryGatewayIP"] = gw.PrivateIp
 			v["PublicIP"] = gw.PublicIp
+			v["NetworkUsesVIP"] = secondaryGW != nil
+			if secondaryGW != nil {
+				v["SecondaryGatewayIP"] = secondaryGW.PrivateIp
+				v["SecondaryPublicIP"] = secondaryGW.PublicIp
+			}
 		} else {
 			v["PublicIP"] = host.PublicIp
 		}
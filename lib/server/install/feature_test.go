@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/install/enums/method"
+)
+
+type fakeTarget struct {
+	methods map[uint8]method.Enum
+}
+
+func (t *fakeTarget) Name() string                   { return "fake" }
+func (t *fakeTarget) Type() string                   { return "host" }
+func (t *fakeTarget) Methods() map[uint8]method.Enum { return t.methods }
+func (t *fakeTarget) Installed() []string            { return nil }
+
+func newFakeFeature(methods ...string) *Feature {
+	specs := viper.New()
+	for _, m := range methods {
+		specs.Set("feature.install."+m+".check", "true")
+	}
+	return &Feature{displayName: "fake", specs: specs}
+}
+
+func TestSelectInstallerUsesPreferredMethod(t *testing.T) {
+	target := &fakeTarget{methods: map[uint8]method.Enum{1: method.Apt, 2: method.Bash}}
+
+	installer, err := newFakeFeature("apt", "bash").selectInstaller(target, Settings{}, "install")
+	require.Nil(t, err)
+	assert.IsType(t, &aptInstaller{}, installer)
+}
+
+func TestSelectInstallerUsesForcedMethod(t *testing.T) {
+	target := &fakeTarget{methods: map[uint8]method.Enum{1: method.Apt, 2: method.Bash}}
+
+	installer, err := newFakeFeature("apt", "bash").selectInstaller(target, Settings{ForceMethod: method.Bash}, "install")
+	require.Nil(t, err)
+	assert.IsType(t, &bashInstaller{}, installer)
+}
+
+func TestSelectInstallerRejectsUnavailableForcedMethod(t *testing.T) {
+	target := &fakeTarget{methods: map[uint8]method.Enum{1: method.Apt, 2: method.Bash}}
+
+	_, err := newFakeFeature("apt", "yum", "bash").selectInstaller(target, Settings{ForceMethod: method.Yum}, "install")
+	assert.NotNil(t, err)
+
+	_, err = newFakeFeature("apt").selectInstaller(target, Settings{ForceMethod: method.Bash}, "check")
+	assert.NotNil(t, err)
+}
@@ -17,7 +17,12 @@
 package install
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/CS-SI/SafeScale/lib/server/install/enums/resultstatus"
 )
 
 // Results ...
@@ -98,3 +103,87 @@ func (r Results) Keys() []string {
 	}
 	return keys
 }
+
+// StepDetail describes the execution of a step on a target
+type StepDetail struct {
+	Step     string            `json:"step"`
+	Target   string            `json:"target"`
+	Status   resultstatus.Enum `json:"status"`
+	Retcode  int               `json:"retcode"`
+	Stdout   string            `json:"stdout,omitempty"`
+	Stderr   string            `json:"stderr,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Duration time.Duration     `json:"duration"`
+}
+
+// Details returns the detail of the execution of every step on every target, sorted by step then target
+func (r Results) Details() []StepDetail {
+	var details []StepDetail
+	for step, results := range r {
+		for target, sr := range results {
+			name := sr.Step()
+			if name == "" {
+				name = step
+			}
+			details = append(
+				details, StepDetail{
+					Step:     name,
+					Target:   target,
+					Status:   sr.Status(),
+					Retcode:  sr.Retcode(),
+					Stdout:   sr.Stdout(),
+					Stderr:   sr.Stderr(),
+					Error:    sr.ErrorMessage(),
+					Duration: sr.Duration(),
+				},
+			)
+		}
+	}
+	sort.Slice(
+		details, func(i, j int) bool {
+			if details[i].Step != details[j].Step {
+				return details[i].Step < details[j].Step
+			}
+			return details[i].Target < details[j].Target
+		},
+	)
+	return details
+}
+
+// Status returns the overall status: Aborted if a step could not be run to completion on a target, Failed if a step
+// failed on a target, Succeeded otherwise
+func (r Results) Status() resultstatus.Enum {
+	status := resultstatus.Succeeded
+	for _, results := range r {
+		for _, sr := range results {
+			switch sr.Status() {
+			case resultstatus.Aborted:
+				return resultstatus.Aborted
+			case resultstatus.Failed:
+				status = resultstatus.Failed
+			}
+		}
+	}
+	return status
+}
+
+// Report returns a human readable report of the steps not succeeded, with their error output
+func (r Results) Report() string {
+	output := ""
+	for _, d := range r.Details() {
+		if d.Status == resultstatus.Succeeded {
+			continue
+		}
+		output += fmt.Sprintf("step '%s' on '%s': %s", d.Step, d.Target, d.Status.String())
+		if d.Status == resultstatus.Aborted {
+			output += fmt.Sprintf(" (%s)", d.Error)
+		} else {
+			output += fmt.Sprintf(" (retcode=%d)", d.Retcode)
+		}
+		output += fmt.Sprintf(" after %s\n", d.Duration.Round(time.Millisecond))
+		if stderr := strings.TrimSpace(d.Stderr); stderr != "" {
+			output += "  " + strings.Replace(stderr, "\n", "\n  ", -1) + "\n"
+		}
+	}
+	return output
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/server/install/enums/resultstatus"
+)
+
+func testResults() Results {
+	return Results{
+		"install": StepResults{
+			"host1": stepResult{
+				completed: true, success: true, step: "install", retcode: 0, stdout: "done", duration: 2 * time.Second,
+			},
+			"host2": stepResult{
+				completed: true, step: "install", retcode: 12, stderr: "E: package not found\nE: abort",
+				err: fmt.Errorf("failure: retcode=12"), duration: time.Second,
+			},
+		},
+		"config": StepResults{
+			"host1": stepResult{completed: true, success: true, step: "config"},
+		},
+	}
+}
+
+func TestResultsDetails(t *testing.T) {
+	details := testResults().Details()
+	require.Len(t, details, 3)
+
+	assert.Equal(t, "config", details[0].Step)
+	assert.Equal(t, "install", details[1].Step)
+	assert.Equal(t, "host1", details[1].Target)
+	assert.Equal(t, "done", details[1].Stdout)
+	assert.Equal(t, 2*time.Second, details[1].Duration)
+
+	failed := details[2]
+	assert.Equal(t, "host2", failed.Target)
+	assert.Equal(t, resultstatus.Failed, failed.Status)
+	assert.Equal(t, 12, failed.Retcode)
+	assert.Equal(t, "E: package not found\nE: abort", failed.Stderr)
+	assert.Equal(t, "failure: retcode=12", failed.Error)
+
+	// Details survive JSON encoding, with status as string
+	jsoned, err := json.Marshal(failed)
+	require.Nil(t, err)
+	assert.Contains(t, string(jsoned), `"status":"Failed"`)
+	assert.Contains(t, string(jsoned), `"retcode":12`)
+}
+
+func TestResultsStatus(t *testing.T) {
+	r := testResults()
+	assert.Equal(t, resultstatus.Failed, r.Status())
+	assert.False(t, r.Successful())
+
+	r["install"]["host3"] = stepResult{err: fmt.Errorf("failed to upload script")}
+	assert.Equal(t, resultstatus.Aborted, r.Status())
+
+	assert.Equal(t, resultstatus.Succeeded, Results{"config": testResults()["config"]}.Status())
+}
+
+func TestResultsReport(t *testing.T) {
+	report := testResults().Report()
+	assert.Equal(t, "step 'install' on 'host2': Failed (retcode=12) after 1s\n  E: package not found\n  E: abort\n", report)
+	// Compatibility with former messages
+	assert.Equal(t, "host2: failure: retcode=12\n", testResults().AllErrorMessages())
+}
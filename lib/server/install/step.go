@@ -29,6 +29,7 @@ import (
 	pb "github.com/CS-SI/SafeScale/lib"
 	"github.com/CS-SI/SafeScale/lib/client"
 	"github.com/CS-SI/SafeScale/lib/server/install/enums/action"
+	"github.com/CS-SI/SafeScale/lib/server/install/enums/resultstatus"
 	"github.com/CS-SI/SafeScale/lib/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
 	"github.com/CS-SI/SafeScale/lib/utils/concurrency"
@@ -45,9 +46,14 @@ const (
 )
 
 type stepResult struct {
-	completed bool  // if true, the script has been run to completion
-	success   bool  // if true, the script has been run successfully and the result is a success
-	err       error // if an error occured, contains the err
+	completed bool          // if true, the script has been run to completion
+	success   bool          // if true, the script has been run successfully and the result is a success
+	err       error         // if an error occured, contains the err
+	step      string        // name of the step
+	retcode   int           // retcode of the script, meaningful only if completed
+	stdout    string        // output of the script
+	stderr    string        // error output of the script
+	duration  time.Duration // time spent running the step on the target
 }
 
 func (sr stepResult) Successful() bool {
@@ -69,6 +75,43 @@ func (sr stepResult) ErrorMessage() string {
 	return ""
 }
 
+// Step returns the name of the step
+func (sr stepResult) Step() string {
+	return sr.step
+}
+
+// Retcode returns the retcode of the script of the step, meaningful only if Completed() is true
+func (sr stepResult) Retcode() int {
+	return sr.retcode
+}
+
+// Stdout returns the output of the script of the step
+func (sr stepResult) Stdout() string {
+	return sr.stdout
+}
+
+// Stderr returns the error output of the script of the step
+func (sr stepResult) Stderr() string {
+	return sr.stderr
+}
+
+// Duration returns the time spent running the step
+func (sr stepResult) Duration() time.Duration {
+	return sr.duration
+}
+
+// Status returns the status of the step
+func (sr stepResult) Status() resultstatus.Enum {
+	switch {
+	case !sr.completed:
+		return resultstatus.Aborted
+	case sr.success:
+		return resultstatus.Succeeded
+	default:
+		return resultstatus.Failed
+	}
+}
+
 // StepResults contains the errors of the step for each host target
 type StepResults map[string]stepResult
 
@@ -296,9 +339,9 @@ func (is *step) Run(hosts []*pb.Host, v Variables, s Settings) (results StepResu
 			// if h.Domain != "" {
 			// 	cloneV["Hostname"] = h.Name+"."+h.Domain
 			// } else {
-			cloneV["Hostname"] = h.Name
+			cloneV["Hostname"] = systemHostname(h)
 			// }
-			cloneV["ShortHostname"] = h.Name
+			cloneV["ShortHostname"] = utils.ShortHostname(systemHostname(h))
 			cloneV, err = realizeVariables(cloneV)
 			if err != nil {
 				return nil, err
@@ -343,9 +386,9 @@ func (is *step) Run(hosts []*pb.Host, v Variables, s Settings) (results StepResu
 			// if h.Domain != "" {
 			// 	cloneV["Hostname"] = h.Name+"."+h.Domain
 			// } else {
-			cloneV["Hostname"] = h.Name
+			cloneV["Hostname"] = systemHostname(h)
 			// }
-			cloneV["ShortHostname"] = h.Name
+			cloneV["ShortHostname"] = utils.ShortHostname(systemHostname(h))
 			cloneV, err = realizeVariables(cloneV)
 			if err != nil {
 				return nil, err
@@ -427,6 +470,14 @@ func (is *step) taskRunOnHost(t concurrency.Task, params concurrency.TaskParamet
 	host := p["host"].(*pb.Host)
 	variables := p["variables"].(Variables)
 
+	start := time.Now()
+	defer func() {
+		if sr, ok := result.(stepResult); ok {
+			sr.step, sr.duration = is.Name, time.Since(start)
+			result = sr
+		}
+	}()
+
 	// FIXME: Time and again
 	variables["TemplateOperationDelay"] = uint(math.Ceil(2 * temporal.GetDefaultDelay().Seconds()))
 	variables["TemplateOperationTimeout"] = strings.Replace(
@@ -473,7 +524,7 @@ func (is *step) taskRunOnHost(t concurrency.Task, params concurrency.TaskParamet
 	command = fmt.Sprintf("sudo bash %s; rc=$?; exit $rc", filename)
 
 	// Executes the script on the remote host
-	retcode, _, _, err := client.New().SSH.Run(
+	retcode, stdout, stderr, err := client.New().SSH.Run(
 		host.Name, command, outputs.COLLECT, temporal.GetConnectionTimeout(), is.WallTime,
 	)
 	if err != nil {
@@ -484,5 +535,13 @@ func (is *step) taskRunOnHost(t concurrency.Task, params concurrency.TaskParamet
 	if !ok {
 		err = fmt.Errorf("failure: retcode=%d", retcode)
 	}
-	return stepResult{success: ok, completed: true, err: err}, nil
+	return stepResult{success: ok, completed: true, err: err, retcode: retcode, stdout: stdout, stderr: stderr}, nil
+}
+
+// systemHostname returns the hostname of the host on the system, defaulting to the name of the host
+func systemHostname(h *pb.Host) string {
+	if h.Hostname != "" {
+		return h.Hostname
+	}
+	return h.Name
 }
@@ -416,6 +416,18 @@ func replaceVariablesInString(text string, v Variables) (string, error) {
 // 	//}
 // }
 
+// waitCloudInitDone waits cloud-init has finished on the host of the target (if the target is a host or a node)
+func waitCloudInitDone(t Target) error {
+	hT, _, nT := determineContext(t)
+	if hT == nil && nT != nil {
+		hT = nT.HostTarget
+	}
+	if hT == nil {
+		return nil
+	}
+	return client.New().SSH.WaitCloudInitDone(hT.host.Id, temporal.GetHostTimeout())
+}
+
 // determineContext ...
 func determineContext(t Target) (hT *HostTarget, cT *ClusterTarget, nT *NodeTarget) {
 	hT = nil
@@ -451,15 +463,24 @@ func checkParameters(f *Feature, v Variables) error {
 	return nil
 }
 
-func gatewayFromHost(host *pb.Host) *pb.Host {
-	gwID := host.GetGatewayId()
+// gatewaysFromHost returns the primary and secondary gateways of host, as resolved by HostHandler.GetGateways
+// A host without gateway is its own primary gateway; secondary is nil if the network has only one gateway, and both
+// are nil if the gateways cannot be determined
+func gatewaysFromHost(host *pb.Host) (primary, secondary *pb.Host) {
 	// If host has no gateway, host is gateway
-	if gwID == "" {
-		return host
+	if host.GetGatewayId() == "" {
+		return host, nil
 	}
-	gw, err := client.New().Host.Inspect(gwID, temporal.GetExecutionTimeout())
+	gws, err := client.New().Host.GetGateways(host.GetId(), temporal.GetExecutionTimeout())
 	if err != nil {
-		return nil
+		logrus.Warnf("failed to get the gateways of host '%s': %v", host.GetName(), err)
+		return nil, nil
 	}
-	return gw
+	return gws.GetPrimary(), gws.GetSecondary()
+}
+
+// gatewayFromHost returns the primary gateway of host, nil if it cannot be determined
+func gatewayFromHost(host *pb.Host) *pb.Host {
+	primary, _ := gatewaysFromHost(host)
+	return primary
 }
@@ -342,8 +342,11 @@ func (w *worker) identifyConcernedGateways() ([]*pb.Host, error) {
 	var hosts []*pb.Host
 
 	if w.host != nil {
-		host := gatewayFromHost(w.host)
-		hosts = []*pb.Host{host}
+		primary, secondary := gatewaysFromHost(w.host)
+		hosts = []*pb.Host{primary}
+		if secondary != nil {
+			hosts = append(hosts, secondary)
+		}
 	} else if w.cluster != nil {
 		var err error
 		hosts, err = w.identifyAllGateways()
@@ -873,9 +876,9 @@ func (w *worker) setReverseProxy() (err error) {
 			// if h.Domain != "" {
 			// 	primaryGatewayVariables["Hostname"] = h.Name+"."+h.Domain
 			// } else {
-			primaryGatewayVariables["Hostname"] = h.Name
+			primaryGatewayVariables["Hostname"] = systemHostname(h)
 			// }
-			primaryGatewayVariables["ShortHostname"] = h.Name
+			primaryGatewayVariables["ShortHostname"] = utils.ShortHostname(systemHostname(h))
 
 			_, _ = tP.Start(
 				taskApplyProxyRule, data.Map{
@@ -893,9 +896,9 @@ func (w *worker) setReverseProxy() (err error) {
 				// if h.Domain != "" {
 				// 	secondaryGatewayVariables["Hostname"] = h.Name+"."+h.Domain
 				// } else {
-				secondaryGatewayVariables["Hostname"] = h.Name
+				secondaryGatewayVariables["Hostname"] = systemHostname(h)
 				// }
-				secondaryGatewayVariables["ShortHostname"] = h.Name
+				secondaryGatewayVariables["ShortHostname"] = utils.ShortHostname(systemHostname(h))
 				_, _ = tS.Start(
 					taskApplyProxyRule, data.Map{
 						// FIXME: Later
@@ -19,6 +19,7 @@ package listeners
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/CS-SI/SafeScale/lib/utils/debug"
 
@@ -32,6 +33,8 @@ import (
 	pb "github.com/CS-SI/SafeScale/lib"
 	"github.com/CS-SI/SafeScale/lib/server/handlers"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
+	"github.com/CS-SI/SafeScale/lib/server/install"
+	"github.com/CS-SI/SafeScale/lib/server/install/enums/method"
 	srvutils "github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 )
@@ -95,7 +98,11 @@ func (s *HostListener) Start(ctx context.Context, in *pb.Reference) (empty *goog
 		return empty, status.Errorf(codes.FailedPrecondition, "cannot start host: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return empty, err
+	}
+	handler := HostHandler(svc)
 	err = handler.Start(ctx, ref)
 	if err != nil {
 		return empty, status.Errorf(codes.Internal, getUserMessage(err))
@@ -133,7 +140,11 @@ func (s *HostListener) Stop(ctx context.Context, in *pb.Reference) (empty *googl
 		return empty, status.Errorf(codes.FailedPrecondition, "cannot stop host: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return empty, err
+	}
+	handler := HostHandler(svc)
 	err = handler.Stop(ctx, ref)
 	if err != nil {
 		return empty, status.Errorf(codes.Internal, getUserMessage(err))
@@ -172,7 +183,11 @@ func (s *HostListener) Reboot(ctx context.Context, in *pb.Reference) (empty *goo
 		return empty, status.Errorf(codes.FailedPrecondition, "cannot reboot host: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return empty, err
+	}
+	handler := HostHandler(svc)
 	err = handler.Reboot(ctx, ref)
 	if err != nil {
 		return empty, status.Errorf(codes.Internal, getUserMessage(err))
@@ -182,6 +197,316 @@ func (s *HostListener) Reboot(ctx context.Context, in *pb.Reference) (empty *goo
 	return empty, nil
 }
 
+// Freeze protects an host against deletion, resize, stop, reboot and any other mutation
+func (s *HostListener) Freeze(ctx context.Context, in *pb.Reference) (empty *googleprotobuf.Empty, err error) {
+	return s.setFrozen(ctx, in, true)
+}
+
+// Thaw removes the protection of an host set by Freeze
+func (s *HostListener) Thaw(ctx context.Context, in *pb.Reference) (empty *googleprotobuf.Empty, err error) {
+	return s.setFrozen(ctx, in, false)
+}
+
+func (s *HostListener) setFrozen(ctx context.Context, in *pb.Reference, frozen bool) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	if s == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	action, jobName := "freeze", "Freeze Host "
+	if !frozen {
+		action, jobName = "thaw", "Thaw Host "
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return empty, status.Errorf(
+			codes.FailedPrecondition, fail.InvalidParameterError("ref", "cannot be empty string").Message(),
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, frozen), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, jobName+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Infof("Can't %s host: no tenant set", action)
+		return empty, status.Errorf(codes.FailedPrecondition, "cannot %s host: no tenant set", action)
+	}
+
+	handler := HostHandler(tenant.Service)
+	if frozen {
+		err = handler.Freeze(ctx, ref)
+	} else {
+		err = handler.Thaw(ctx, ref)
+	}
+	if err != nil {
+		return empty, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return empty, nil
+}
+
+// SetIPForwarding allows or forbids an host to forward traffic not addressed to it
+func (s *HostListener) SetIPForwarding(ctx context.Context, in *pb.HostIPForwardingRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	if s == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return empty, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return empty, status.Errorf(
+			codes.FailedPrecondition, fail.InvalidParameterError("ref", "cannot be empty string").Message(),
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, in.GetEnabled()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Set IP forwarding of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't set IP forwarding of host: no tenant set")
+		return empty, status.Errorf(codes.FailedPrecondition, "cannot set IP forwarding of host: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	err = handler.SetIPForwarding(ctx, ref, in.GetEnabled())
+	if err != nil {
+		if _, ok := err.(fail.ErrInvalidRequest); ok {
+			return empty, status.Errorf(codes.InvalidArgument, getUserMessage(err))
+		}
+		return empty, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return empty, nil
+}
+
+// ReplaceSecurityGroups makes the security groups bound on request to an host exactly the ones requested
+func (s *HostListener) ReplaceSecurityGroups(ctx context.Context, in *pb.HostSecurityGroupsRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	if s == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return empty, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return empty, status.Errorf(
+			codes.FailedPrecondition, fail.InvalidParameterError("ref", "cannot be empty string").Message(),
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, in.GetSecurityGroups()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Replace security groups of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't replace security groups of host: no tenant set")
+		return empty, status.Errorf(codes.FailedPrecondition, "cannot replace security groups of host: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	err = handler.ReplaceSecurityGroups(ctx, ref, in.GetSecurityGroups())
+	if err != nil {
+		return empty, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return empty, nil
+}
+
+// ApplyKernelParameters sets kernel parameters (sysctl) on an host, returning their previous values
+func (s *HostListener) ApplyKernelParameters(ctx context.Context, in *pb.HostKernelParametersRequest) (out *pb.HostKernelParameters, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, fail.InvalidParameterError("ref", "cannot be empty string").Message(),
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, in.GetParameters()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Apply kernel parameters on Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't apply kernel parameters on host: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot apply kernel parameters on host: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	previous, err := handler.ApplyKernelParameters(ctx, ref, in.GetParameters())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return &pb.HostKernelParameters{Parameters: previous}, nil
+}
+
+// ListKernelParameters returns the kernel parameters applied on an host by SafeScale
+func (s *HostListener) ListKernelParameters(ctx context.Context, in *pb.Reference) (out *pb.HostKernelParameters, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot list host kernel parameters: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "List kernel parameters of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't list host kernel parameters: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot list host kernel parameters: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	params, err := handler.ListKernelParameters(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(
+			codes.Internal, fmt.Sprintf("cannot list host kernel parameters: %s", getUserMessage(err)),
+		)
+	}
+	return &pb.HostKernelParameters{Parameters: params}, nil
+}
+
+// AddFeature installs a feature on an host and records it in the metadata of the host
+func (s *HostListener) AddFeature(ctx context.Context, in *pb.HostFeatureRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	_, err = s.doFeature(ctx, in, "add")
+	return empty, err
+}
+
+// EnsureFeature installs a feature on an host only if it is not already installed in the version of its specification
+func (s *HostListener) EnsureFeature(ctx context.Context, in *pb.HostFeatureRequest) (out *pb.HostFeatureEnsureResponse, err error) {
+	result, err := s.doFeature(ctx, in, "ensure")
+	if err != nil {
+		return nil, err
+	}
+	return &pb.HostFeatureEnsureResponse{Result: string(result)}, nil
+}
+
+// RemoveFeature uninstalls a feature from an host and forgets it in the metadata of the host
+func (s *HostListener) RemoveFeature(ctx context.Context, in *pb.HostFeatureRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	_, err = s.doFeature(ctx, in, "remove")
+	return empty, err
+}
+
+// doFeature runs the action ("add", "ensure" or "remove") of the feature requested by in on the host
+func (s *HostListener) doFeature(
+	ctx context.Context, in *pb.HostFeatureRequest, action string,
+) (result handlers.FeatureEnsureResult, err error) {
+	if s == nil {
+		return "", status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return "", status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return "", status.Errorf(
+			codes.FailedPrecondition, fail.InvalidParameterError("ref", "cannot be empty string").Message(),
+		)
+	}
+	featureName := in.GetName()
+	if featureName == "" {
+		return "", status.Errorf(
+			codes.InvalidArgument, fail.InvalidParameterError("name", "cannot be empty string").Message(),
+		)
+	}
+
+	tracer := debug.NewTracer(
+		nil, fmt.Sprintf("('%s', '%s', %s)", ref, featureName, action), true,
+	).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	vars := install.Variables{}
+	for k, v := range in.GetParameters() {
+		vars[k] = v
+	}
+	settings := install.Settings{
+		SkipProxy:     in.GetSkipProxy(),
+		WaitCloudInit: in.GetWaitCloudInit(),
+	}
+	if in.GetMethod() != "" {
+		settings.ForceMethod, err = method.Parse(in.GetMethod())
+		if err != nil {
+			return "", status.Errorf(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	jobName := fmt.Sprintf("%s feature %s on Host %s", strings.Title(action), featureName, ref)
+	if err := srvutils.JobRegister(ctx, cancelFunc, jobName); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Infof("Can't %s feature on host: no tenant set", action)
+		return "", status.Errorf(codes.FailedPrecondition, "cannot %s feature on host: no tenant set", action)
+	}
+
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return "", err
+	}
+	handler := HostHandler(svc)
+	switch action {
+	case "add":
+		err = handler.AddFeature(ctx, ref, featureName, vars, settings)
+	case "ensure":
+		result, err = handler.EnsureFeature(ctx, ref, featureName, vars, settings)
+	case "remove":
+		err = handler.RemoveFeature(ctx, ref, featureName, vars, settings)
+	}
+	if err != nil {
+		return "", status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return result, nil
+}
+
 // List lists hosts managed by SafeScale only, or all hosts.
 func (s *HostListener) List(ctx context.Context, in *pb.HostListRequest) (hl *pb.HostList, err error) {
 	if s == nil {
@@ -205,8 +530,31 @@ func (s *HostListener) List(ctx context.Context, in *pb.HostListRequest) (hl *pb
 		return nil, status.Errorf(codes.FailedPrecondition, "cannot list hosts: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
-	hosts, err := handler.List(ctx, all)
+	svc, err := serviceInRegion(tenant, in.GetRegion(), in.GetZone())
+	if err != nil {
+		return nil, err
+	}
+	handler := HostHandler(svc)
+	var (
+		hosts []*abstract.Host
+		next  string
+	)
+	// metadata are shared by all the regions, so the hosts managed by SafeScale are filtered on their recorded region
+	byRegion := !all && in.GetRegion() != ""
+	if in.GetMarker() != "" || in.GetLimit() > 0 {
+		if all {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot list hosts: paging is not available when listing all hosts")
+		}
+		if byRegion {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot list hosts: paging is not available when listing the hosts of a region")
+		}
+		hosts, next, err = handler.ListPage(ctx, in.GetMarker(), int(in.GetLimit()))
+	} else if byRegion {
+		defaultRegion, _ := tenant.Service.GetRegion()
+		hosts, _, err = handler.ListFiltered(ctx, handlers.HostFilter{Region: in.GetRegion(), DefaultRegion: defaultRegion})
+	} else {
+		hosts, err = handler.List(ctx, all)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, getUserMessage(err))
 	}
@@ -221,10 +569,39 @@ func (s *HostListener) List(ctx context.Context, in *pb.HostListRequest) (hl *pb
 		}
 		pbhost = append(pbhost, pbHost)
 	}
-	rv := &pb.HostList{Hosts: pbhost}
+	rv := &pb.HostList{Hosts: pbhost, NextMarker: next}
 	return rv, nil
 }
 
+// Audit reports the discrepancies between the hosts recorded in metadata and the hosts of the provider
+func (s *HostListener) Audit(ctx context.Context, in *googleprotobuf.Empty) (ar *pb.HostAuditReport, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+
+	tracer := debug.NewTracer(nil, "", true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Audit Hosts"); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't audit hosts: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot audit hosts: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	audit, err := handler.Audit(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot audit hosts: %s", getUserMessage(err)))
+	}
+	return &pb.HostAuditReport{Missing: audit.Missing, Unmanaged: audit.Unmanaged}, nil
+}
+
 // Create creates a new host
 func (s *HostListener) Create(ctx context.Context, in *pb.HostDefinition) (h *pb.Host, err error) {
 	if s == nil {
@@ -271,17 +648,40 @@ func (s *HostListener) Create(ctx context.Context, in *pb.HostDefinition) (h *pb
 		sizing = &s
 	}
 
-	handler := HostHandler(tenant.Service)
+	svc, err := serviceInRegion(tenant, in.GetRegion(), in.GetZone())
+	if err != nil {
+		return nil, err
+	}
+	handler := HostHandler(svc)
 	host, err := handler.Create(
-		ctx,
-		name,
-		in.GetNetwork(),
-		in.GetImageId(),
-		in.GetPublic(),
-		sizing,
-		in.Force,
-		in.Domain,
-		in.KeepOnFailure,
+		ctx, handlers.HostCreateRequest{
+			Name:                     name,
+			Networks:                 in.GetNetwork(),
+			OS:                       in.GetImageId(),
+			Public:                   in.GetPublic(),
+			Sizing:                   sizing,
+			Force:                    in.Force,
+			Domain:                   in.Domain,
+			KeepOnFailure:            in.KeepOnFailure,
+			SkipDefaultSecurityGroup: in.GetSkipDefaultSecurityGroup(),
+			SourceSnapshot:           in.GetSourceSnapshot(),
+			ProvisionFromScratch:     in.GetProvisionFromScratch(),
+			SkipReboots:              in.GetSkipReboots(),
+			Spot:                     in.GetSpot(),
+			MaxPrice:                 float64(in.GetMaxPrice()),
+			SecurityGroups:           in.GetSecurityGroups(),
+			NICs:                     in.GetNics(),
+			FixedIPs:                 in.GetFixedIps(),
+			Volumes:                  in.GetVolumes(),
+			AllowCrossNetwork:        in.GetAllowCrossNetwork(),
+			ShieldedVM:               in.GetShieldedVm(),
+			ConfidentialVM:           in.GetConfidentialVm(),
+			Tags:                     in.GetTags(),
+			SystemDiskSize:           int(in.GetSystemDiskSize()),
+			DiskType:                 in.GetDiskType(),
+			AffinityGroup:            in.GetAffinityGroup(),
+			AntiAffinity:             in.GetAntiAffinity(),
+		},
 	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, getUserMessage(err))
@@ -368,7 +768,11 @@ func (s *HostListener) Status(ctx context.Context, in *pb.Reference) (ht *pb.Hos
 		return nil, status.Errorf(codes.FailedPrecondition, "cannot get host status: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return nil, err
+	}
+	handler := HostHandler(svc)
 	host, err := handler.ForceInspect(ctx, ref)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, getUserMessage(err))
@@ -406,36 +810,178 @@ func (s *HostListener) Inspect(ctx context.Context, in *pb.Reference) (h *pb.Hos
 		return nil, status.Errorf(codes.FailedPrecondition, "cannot inspect host: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
-	host, err := handler.ForceInspect(ctx, ref)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return nil, err
+	}
+	handler := HostHandler(svc)
+	details, err := handler.InspectFull(ctx, ref)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot inspect host: %s", getUserMessage(err)))
 	}
-	return srvutils.ToPBHost(host)
+	return srvutils.ToPBHostDetails(details)
 }
 
-// Delete an host
-func (s *HostListener) Delete(ctx context.Context, in *pb.Reference) (empty *googleprotobuf.Empty, err error) {
-	empty = &googleprotobuf.Empty{}
+// ListVolumes lists the volumes attached to an host, with their mount details
+func (s *HostListener) ListVolumes(ctx context.Context, in *pb.Reference) (hvl *pb.HostVolumeList, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot list host volumes: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "List volumes of Host "+in.GetName()); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't list host volumes: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot list host volumes: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	volumes, err := handler.ListAttachedVolumes(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot list host volumes: %s", getUserMessage(err)))
+	}
+
+	hvl = &pb.HostVolumeList{}
+	for _, v := range volumes {
+		pbv, err := srvutils.ToPBHostVolume(v)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, err.Error())
+		}
+		hvl.Volumes = append(hvl.Volumes, pbv)
+	}
+	return hvl, nil
+}
+
+// DiskUsage returns the space and inode usage of the filesystems mounted on an host
+func (s *HostListener) DiskUsage(ctx context.Context, in *pb.Reference) (hdu *pb.HostDiskUsage, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot get host disk usage: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Disk usage of Host "+in.GetName()); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't get host disk usage: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot get host disk usage: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	list, err := handler.DiskUsage(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot get host disk usage: %s", getUserMessage(err)))
+	}
+
+	hdu = &pb.HostDiskUsage{}
+	for _, v := range list {
+		pbfsu, err := srvutils.ToPBFilesystemUsage(v)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, err.Error())
+		}
+		hdu.Filesystems = append(hdu.Filesystems, pbfsu)
+	}
+	return hdu, nil
+}
+
+// GetMetrics returns the uptime, load, memory and CPU count of an host
+func (s *HostListener) GetMetrics(ctx context.Context, in *pb.Reference) (hm *pb.HostMetrics, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot get host metrics: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Metrics of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't get host metrics: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot get host metrics: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	metrics, err := handler.GetMetrics(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot get host metrics: %s", getUserMessage(err)))
+	}
+
+	hm, err = srvutils.ToPBHostMetrics(metrics)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	return hm, nil
+}
+
+// Delete an host
+func (s *HostListener) Delete(ctx context.Context, in *pb.HostDeleteRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
 	if s == nil {
 		return empty, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
 	}
 	if in == nil {
 		return empty, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
 	}
-	ref := srvutils.GetReference(in)
+	ref := srvutils.GetReference(in.GetHost())
 	if ref == "" {
 		return empty, status.Errorf(
 			codes.FailedPrecondition, "cannot get host status: neither name nor id given as reference",
 		)
 	}
 
-	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	detachVolumes := in.GetDetachVolumes()
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, detachVolumes), true).WithStopwatch().GoingIn()
 	defer tracer.OnExitTrace()()
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
 	ctx, cancelFunc := context.WithCancel(ctx)
-	if err := srvutils.JobRegister(ctx, cancelFunc, "Delete Host "+in.GetName()); err == nil {
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Delete Host "+ref); err == nil {
 		defer srvutils.JobDeregister(ctx)
 	}
 
@@ -445,8 +991,12 @@ func (s *HostListener) Delete(ctx context.Context, in *pb.Reference) (empty *goo
 		return empty, status.Errorf(codes.FailedPrecondition, "cannot delete host: no tenant set")
 	}
 
-	handler := HostHandler(tenant.Service)
-	err = handler.Delete(ctx, ref)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return empty, err
+	}
+	handler := HostHandler(svc)
+	err = handler.Delete(ctx, ref, detachVolumes)
 	if err != nil {
 		return empty, status.Errorf(codes.Internal, getUserMessage(err))
 	}
@@ -484,7 +1034,11 @@ func (s *HostListener) SSH(ctx context.Context, in *pb.Reference) (sc *pb.SshCon
 		return nil, status.Errorf(codes.FailedPrecondition, "cannot ssh host: no tenant set")
 	}
 
-	handler := HostHandler(currentTenant.Service)
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return nil, err
+	}
+	handler := HostHandler(svc)
 	sshConfig, err := handler.SSH(ctx, ref)
 	if err != nil {
 		if _, ok := err.(fail.ErrNotFound); ok {
@@ -494,3 +1048,433 @@ func (s *HostListener) SSH(ctx context.Context, in *pb.Reference) (sc *pb.SshCon
 	}
 	return srvutils.ToPBSshConfig(sshConfig)
 }
+
+// GetGateways returns the primary and secondary gateways of the default network of a host
+func (s *HostListener) GetGateways(ctx context.Context, in *pb.Reference) (gws *pb.HostGateways, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot get host gateways: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Gateways of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't get host gateways: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot get host gateways: no tenant set")
+	}
+
+	svc, err := serviceOfHost(tenant, ref)
+	if err != nil {
+		return nil, err
+	}
+	handler := HostHandler(svc)
+	primary, secondary, err := handler.GetGateways(ctx, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil, status.Errorf(codes.NotFound, getUserMessage(err))
+		}
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	gws = &pb.HostGateways{}
+	if gws.Primary, err = srvutils.ToPBHost(primary); err != nil {
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	if secondary != nil {
+		if gws.Secondary, err = srvutils.ToPBHost(secondary); err != nil {
+			return nil, status.Errorf(codes.Internal, getUserMessage(err))
+		}
+	}
+	return gws, nil
+}
+
+// ListNetworkInterfaces lists the network interfaces of a host
+func (s *HostListener) ListNetworkInterfaces(ctx context.Context, in *pb.Reference) (hnl *pb.HostNetworkInterfaceList, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot list host network interfaces: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "List network interfaces of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't list host network interfaces: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot list host network interfaces: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	nics, err := handler.GetNetworkInterfaces(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(
+			codes.Internal, fmt.Sprintf("cannot list host network interfaces: %s", getUserMessage(err)),
+		)
+	}
+
+	hnl = &pb.HostNetworkInterfaceList{}
+	for i := range nics {
+		pbn, err := srvutils.ToPBHostNetworkInterface(&nics[i])
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, err.Error())
+		}
+		hnl.Interfaces = append(hnl.Interfaces, pbn)
+	}
+	return hnl, nil
+}
+
+// ListListeningPorts returns the TCP and UDP ports on which an host is listening
+func (s *HostListener) ListListeningPorts(ctx context.Context, in *pb.Reference) (hpl *pb.HostListeningPortList, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot list host listening ports: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "List listening ports of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't list host listening ports: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot list host listening ports: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	ports, err := handler.GetListeningPorts(ctx, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotAvailable); ok {
+			return nil, status.Errorf(codes.FailedPrecondition, getUserMessage(err))
+		}
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot list host listening ports: %s", getUserMessage(err)))
+	}
+
+	hpl = &pb.HostListeningPortList{}
+	for i := range ports {
+		pbp, err := srvutils.ToPBHostListeningPort(&ports[i])
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, err.Error())
+		}
+		hpl.Ports = append(hpl.Ports, pbp)
+	}
+	return hpl, nil
+}
+
+// ListProcesses returns the processes running on a host, sorted by CPU or memory usage
+func (s *HostListener) ListProcesses(ctx context.Context, in *pb.HostProcessListRequest) (hpl *pb.HostProcessList, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot list host processes: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s', %d)", ref, in.GetSortBy(), in.GetLimit()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "List processes of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't list host processes: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot list host processes: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	list, err := handler.GetProcessList(
+		ctx, ref, handlers.ProcessListOptions{SortBy: in.GetSortBy(), Limit: int(in.GetLimit())},
+	)
+	if err != nil {
+		if _, ok := err.(fail.ErrInvalidParameter); ok {
+			return nil, status.Errorf(codes.InvalidArgument, getUserMessage(err))
+		}
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot list host processes: %s", getUserMessage(err)))
+	}
+
+	hpl = &pb.HostProcessList{}
+	for _, p := range list {
+		hpl.Processes = append(hpl.Processes, srvutils.ToPBHostProcess(p))
+	}
+	return hpl, nil
+}
+
+// CheckConnectivity checks from a host the reachability of other hosts or endpoints
+func (s *HostListener) CheckConnectivity(ctx context.Context, in *pb.HostConnectivityRequest) (rl *pb.HostConnectivityResultList, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot check connectivity of host: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v)", ref, in.GetTargets()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	targets := make([]abstract.ConnTarget, 0, len(in.GetTargets()))
+	for _, v := range in.GetTargets() {
+		target, err := handlers.ParseConnTarget(v)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, fmt.Sprintf("cannot check connectivity of host: %s", getUserMessage(err)))
+		}
+		targets = append(targets, target)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Check connectivity of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't check connectivity of host: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot check connectivity of host: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	results, err := handler.CheckConnectivity(ctx, ref, targets)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot check connectivity of host: %s", getUserMessage(err)))
+	}
+
+	rl = &pb.HostConnectivityResultList{}
+	for _, r := range results {
+		rl.Results = append(rl.Results, srvutils.ToPBHostConnectivityResult(r))
+	}
+	return rl, nil
+}
+
+// Console returns the console output of a host
+func (s *HostListener) Console(ctx context.Context, in *pb.HostConsoleRequest) (out *pb.HostConsoleOutput, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot get host console output: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %d)", ref, in.GetLines()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Console of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't get host console output: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot get host console output: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	output, err := handler.Console(ctx, ref, int(in.GetLines()))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot get host console output: %s", getUserMessage(err)))
+	}
+	return &pb.HostConsoleOutput{Output: output}, nil
+}
+
+// Snapshot creates a snapshot of the boot disk of a host
+func (s *HostListener) Snapshot(ctx context.Context, in *pb.HostSnapshotRequest) (out *pb.HostSnapshot, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot snapshot host: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(
+		nil, fmt.Sprintf("('%s', '%s', %v)", ref, in.GetName(), in.GetQuiesce()), true,
+	).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Snapshot of Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't snapshot host: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot snapshot host: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	snapshotID, err := handler.Snapshot(ctx, ref, in.GetName(), in.GetQuiesce())
+	if err != nil {
+		if _, ok := err.(fail.ErrNotImplemented); ok {
+			return nil, status.Errorf(codes.Unimplemented, fmt.Sprintf("cannot snapshot host: %s", getUserMessage(err)))
+		}
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot snapshot host: %s", getUserMessage(err)))
+	}
+	return &pb.HostSnapshot{Id: snapshotID, Name: in.GetName()}, nil
+}
+
+// Rename renames a host
+func (s *HostListener) Rename(ctx context.Context, in *pb.HostRenameRequest) (_ *pb.Host, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot rename host: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, in.GetNewName()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Rename Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't rename host: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot rename host: no tenant set")
+	}
+
+	handler := HostHandler(tenant.Service)
+	host, err := handler.Rename(ctx, ref, in.GetNewName())
+	if err != nil {
+		if _, ok := err.(fail.ErrDuplicate); ok {
+			return nil, status.Errorf(codes.AlreadyExists, fmt.Sprintf("cannot rename host: %s", getUserMessage(err)))
+		}
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot rename host: %s", getUserMessage(err)))
+	}
+	log.Infof("Host '%s' renamed to '%s'", ref, host.Name)
+	return srvutils.ToPBHost(host)
+}
+
+// Clone creates a host configured as another one
+func (s *HostListener) Clone(ctx context.Context, in *pb.HostCloneRequest) (_ *pb.Host, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetHost())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot clone host: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, in.GetNewName()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Clone Host "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't clone host: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot clone host: no tenant set")
+	}
+
+	overrides := handlers.HostCloneOverrides{
+		Networks:    in.GetNetwork(),
+		WithVolumes: in.GetWithVolumes(),
+	}
+	if in.Sizing != nil {
+		sizing, err := srvutils.FromPBHostSizing(in.Sizing)
+		if err != nil {
+			return nil, err
+		}
+		overrides.Sizing = &sizing
+	}
+
+	handler := HostHandler(tenant.Service)
+	host, err := handler.Clone(ctx, ref, in.GetNewName(), overrides)
+	if err != nil {
+		if _, ok := err.(fail.ErrDuplicate); ok {
+			return nil, status.Errorf(codes.AlreadyExists, fmt.Sprintf("cannot clone host: %s", getUserMessage(err)))
+		}
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot clone host: %s", getUserMessage(err)))
+	}
+	log.Infof("Host '%s' cloned as '%s'", ref, host.Name)
+	return srvutils.ToPBHost(host)
+}
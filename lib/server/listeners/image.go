@@ -60,7 +60,11 @@ func (s *ImageListener) List(ctx context.Context, in *pb.ImageListRequest) (il *
 		return nil, status.Errorf(codes.FailedPrecondition, "cannot list images: no tenant set")
 	}
 
-	handler := ImageHandler(currentTenant.Service)
+	svc, err := serviceInRegion(tenant, in.GetRegion(), in.GetZone())
+	if err != nil {
+		return nil, err
+	}
+	handler := ImageHandler(svc)
 	images, err := handler.List(ctx, in.GetAll())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, getUserMessage(err))
@@ -19,6 +19,7 @@ package listeners
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/CS-SI/SafeScale/lib/utils/debug"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/ipversion"
 	srvutils "github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
+	"github.com/CS-SI/SafeScale/lib/utils/temporal"
 )
 
 // NetworkHandler ...
@@ -111,6 +113,7 @@ func (s *NetworkListener) Create(ctx context.Context, in *pb.NetworkDefinition)
 		in.FailOver,
 		in.Domain,
 		in.KeepOnFailure,
+		in.GetDnsServers(),
 	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, getUserMessage(err))
@@ -212,8 +215,8 @@ func (s *NetworkListener) Inspect(ctx context.Context, in *pb.Reference) (net *p
 	return srvutils.ToPBNetwork(network)
 }
 
-// Delete a network
-func (s *NetworkListener) Delete(ctx context.Context, in *pb.Reference) (buf *googleprotobuf.Empty, err error) {
+// InspectTopology returns the topology of a network: its gateways, its hosts and its subnets
+func (s *NetworkListener) InspectTopology(ctx context.Context, in *pb.Reference) (_ *pb.NetworkTopology, err error) {
 	if s == nil {
 		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
 	}
@@ -223,7 +226,7 @@ func (s *NetworkListener) Delete(ctx context.Context, in *pb.Reference) (buf *go
 	ref := srvutils.GetReference(in)
 	if ref == "" {
 		return nil, status.Errorf(
-			codes.FailedPrecondition, "cannot inspect network: neither name nor id given as reference",
+			codes.FailedPrecondition, "cannot inspect topology of network: neither name nor id given as reference",
 		)
 	}
 
@@ -232,7 +235,51 @@ func (s *NetworkListener) Delete(ctx context.Context, in *pb.Reference) (buf *go
 	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
 
 	ctx, cancelFunc := context.WithCancel(ctx)
-	if err := srvutils.JobRegister(ctx, cancelFunc, "Delete network "+in.GetName()); err == nil {
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Inspect topology of network "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't inspect topology of network: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot inspect topology of network: no tenant set")
+	}
+
+	handler := NetworkHandler(tenant.Service)
+	topology, err := handler.InspectTopology(ctx, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return nil, status.Errorf(codes.NotFound, fmt.Sprintf("cannot inspect topology of network: %s", getUserMessage(err)))
+		}
+		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot inspect topology of network: %s", getUserMessage(err)))
+	}
+	return srvutils.ToPBNetworkTopology(topology), nil
+}
+
+// Delete a network
+func (s *NetworkListener) Delete(ctx context.Context, in *pb.NetworkDeleteRequest) (buf *googleprotobuf.Empty, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetNetwork())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot inspect network: neither name nor id given as reference",
+		)
+	}
+
+	waitForHosts := time.Duration(in.GetWaitForHosts()) * time.Second
+	tracer := debug.NewTracer(
+		nil, fmt.Sprintf("('%s', %s)", ref, temporal.FormatDuration(waitForHosts)), true,
+	).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Delete network "+ref); err == nil {
 		defer srvutils.JobDeregister(ctx)
 	}
 
@@ -243,7 +290,7 @@ func (s *NetworkListener) Delete(ctx context.Context, in *pb.Reference) (buf *go
 	}
 
 	handler := NetworkHandler(currentTenant.Service)
-	err = handler.Delete(ctx, ref)
+	err = handler.Delete(ctx, ref, waitForHosts)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, getUserMessage(err))
 	}
@@ -291,3 +338,218 @@ func (s *NetworkListener) Destroy(ctx context.Context, in *pb.Reference) (buf *g
 	log.Infof("Network '%s' successfully deleted.", ref)
 	return &googleprotobuf.Empty{}, nil
 }
+
+// UpdateDNSServers replaces the DNS servers of a network and of its started hosts
+func (s *NetworkListener) UpdateDNSServers(ctx context.Context, in *pb.NetworkDNSServersRequest) (rv *pb.NetworkDNSServersResponse, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetNetwork())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot update DNS servers of network: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %v, %v)", ref, in.GetDnsServers(), in.GetDryRun()), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Update DNS servers of network "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot update DNS servers of network: no tenant set")
+	}
+
+	handler := NetworkHandler(currentTenant.Service)
+	hosts, err := handler.UpdateDNSServers(ctx, ref, in.GetDnsServers(), in.GetDryRun())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return &pb.NetworkDNSServersResponse{Hosts: hosts}, nil
+}
+
+// routeErrorCode returns the gRPC code corresponding to an error of static route management
+func routeErrorCode(err error) codes.Code {
+	switch err.(type) {
+	case fail.ErrNotImplemented:
+		return codes.Unimplemented
+	case fail.ErrDuplicate:
+		return codes.AlreadyExists
+	case fail.ErrNotFound:
+		return codes.NotFound
+	case fail.ErrInvalidParameter:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
+
+// AddRoute injects a static route in a network
+func (s *NetworkListener) AddRoute(ctx context.Context, in *pb.NetworkRouteRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	if s == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return empty, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetNetwork())
+	if ref == "" {
+		return empty, status.Errorf(
+			codes.FailedPrecondition, "cannot add route to network: neither name nor id given as reference",
+		)
+	}
+	destination, nextHop := in.GetRoute().GetDestination(), in.GetRoute().GetNextHop()
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s', '%s')", ref, destination, nextHop), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Add route to network "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, "cannot add route to network: no tenant set")
+	}
+
+	handler := NetworkHandler(tenant.Service)
+	err = handler.AddRoute(ctx, ref, destination, nextHop)
+	if err != nil {
+		return empty, status.Errorf(routeErrorCode(err), fmt.Sprintf("cannot add route to network: %s", getUserMessage(err)))
+	}
+	return empty, nil
+}
+
+// RemoveRoute removes a static route from a network
+func (s *NetworkListener) RemoveRoute(ctx context.Context, in *pb.NetworkRouteRequest) (empty *googleprotobuf.Empty, err error) {
+	empty = &googleprotobuf.Empty{}
+	if s == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return empty, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetNetwork())
+	if ref == "" {
+		return empty, status.Errorf(
+			codes.FailedPrecondition, "cannot remove route from network: neither name nor id given as reference",
+		)
+	}
+	destination := in.GetRoute().GetDestination()
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", ref, destination), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Remove route from network "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		return empty, status.Errorf(codes.FailedPrecondition, "cannot remove route from network: no tenant set")
+	}
+
+	handler := NetworkHandler(tenant.Service)
+	err = handler.RemoveRoute(ctx, ref, destination)
+	if err != nil {
+		return empty, status.Errorf(routeErrorCode(err), fmt.Sprintf("cannot remove route from network: %s", getUserMessage(err)))
+	}
+	return empty, nil
+}
+
+// ListRoutes lists the static routes of a network
+func (s *NetworkListener) ListRoutes(ctx context.Context, in *pb.Reference) (rl *pb.NetworkRouteList, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in)
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot list routes of network: neither name nor id given as reference",
+		)
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s')", ref), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "List routes of network "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot list routes of network: no tenant set")
+	}
+
+	handler := NetworkHandler(tenant.Service)
+	routes, err := handler.ListRoutes(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(routeErrorCode(err), fmt.Sprintf("cannot list routes of network: %s", getUserMessage(err)))
+	}
+
+	rl = &pb.NetworkRouteList{}
+	for _, r := range routes {
+		rl.Routes = append(rl.Routes, srvutils.ToPBNetworkRoute(r))
+	}
+	return rl, nil
+}
+
+// AllocateSubnetCIDR returns the first free CIDR of the requested size in the address space of a network
+func (s *NetworkListener) AllocateSubnetCIDR(ctx context.Context, in *pb.NetworkSubnetCIDRRequest) (out *pb.NetworkSubnetCIDR, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	ref := srvutils.GetReference(in.GetNetwork())
+	if ref == "" {
+		return nil, status.Errorf(
+			codes.FailedPrecondition, "cannot allocate subnet CIDR: neither name nor id given as reference",
+		)
+	}
+	prefixLen := int(in.GetPrefixLen())
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %d)", ref, prefixLen), true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Allocate subnet CIDR in network "+ref); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot allocate subnet CIDR: no tenant set")
+	}
+
+	handler := NetworkHandler(tenant.Service)
+	cidr, err := handler.AllocateSubnetCIDR(ctx, ref, prefixLen)
+	if err != nil {
+		code := codes.Internal
+		if _, ok := err.(fail.ErrOverflow); ok {
+			code = codes.ResourceExhausted
+		}
+		return nil, status.Errorf(code, fmt.Sprintf("cannot allocate subnet CIDR: %s", getUserMessage(err)))
+	}
+	return &pb.NetworkSubnetCIDR{Cidr: cidr}, nil
+}
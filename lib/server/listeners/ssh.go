@@ -88,6 +88,66 @@ func (s *SSHListener) Run(ctx context.Context, in *pb.SshCommand) (sr *pb.SshRes
 	}, err
 }
 
+// RunOnHosts executes a command on several hosts, the failure on a host not stopping the run on the others
+func (s *SSHListener) RunOnHosts(ctx context.Context, in *pb.SshRunOnHostsRequest) (out *pb.SshRunOnHostsResponse, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+	if in == nil {
+		return nil, status.Errorf(codes.InvalidArgument, fail.InvalidParameterError("in", "cannot be nil").Message())
+	}
+	var hostNames []string
+	for _, ref := range in.GetHosts() {
+		hostNames = append(hostNames, srvutils.GetReference(ref))
+	}
+	command := in.GetCommand()
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("(%v, <command>, %d)", hostNames, in.GetParallelism()), true).
+		WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	jobName := fmt.Sprintf("SSH Run %s on %d hosts", command, len(hostNames))
+	if err := srvutils.JobRegister(ctx, cancelFunc, jobName); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot execute ssh command: no tenant set")
+	}
+
+	handler := SSHHandler(tenant.Service)
+	results, err := handler.RunOnHosts(ctx, hostNames, command, outputs.COLLECT, int(in.GetParallelism()))
+	if err != nil {
+		if _, ok := err.(fail.ErrInvalidParameter); ok {
+			return nil, status.Errorf(codes.InvalidArgument, getUserMessage(err))
+		}
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+
+	out = &pb.SshRunOnHostsResponse{}
+	for _, hostName := range hostNames {
+		result, ok := results[hostName]
+		if !ok {
+			continue
+		}
+		delete(results, hostName)
+		pbResult := &pb.SshHostResult{
+			Host:      hostName,
+			Status:    int32(result.RetCode),
+			OutputStd: result.Stdout,
+			OutputErr: result.Stderr,
+		}
+		if result.Err != nil {
+			pbResult.Error = getUserMessage(result.Err)
+		}
+		out.Results = append(out.Results, pbResult)
+	}
+	return out, nil
+}
+
 // Copy copy file from/to an host
 func (s *SSHListener) Copy(ctx context.Context, in *pb.SshCopyCommand) (sr *pb.SshResponse, err error) {
 	if s == nil {
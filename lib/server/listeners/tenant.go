@@ -28,6 +28,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/CS-SI/SafeScale/lib"
+	"github.com/CS-SI/SafeScale/lib/server/handlers"
 	"github.com/CS-SI/SafeScale/lib/server/iaas"
 	srvutils "github.com/CS-SI/SafeScale/lib/server/utils"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
@@ -46,6 +47,35 @@ var (
 // GetCurrentTenant contains the current tenant
 var GetCurrentTenant = getCurrentTenant
 
+// serviceInRegion returns the service of the tenant, working in the region 'region' and the zone 'zone' when set
+// instead of the ones of the tenant configuration
+func serviceInRegion(tenant *Tenant, region, zone string) (iaas.Service, error) {
+	svc, err := tenant.Service.InRegion(region, zone)
+	if err != nil {
+		if _, ok := err.(fail.ErrInvalidRequest); ok {
+			return nil, status.Errorf(codes.InvalidArgument, getUserMessage(err))
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, getUserMessage(err))
+	}
+	return svc, nil
+}
+
+// serviceOfHost returns the service of the tenant working in the region where the host identified by 'ref' has been
+// created, as recorded in its metadata; the service of the tenant is returned for the hosts without recorded region
+func serviceOfHost(tenant *Tenant, ref string) (iaas.Service, error) {
+	region, zone, err := handlers.HostRegion(tenant.Service, ref)
+	if err != nil {
+		if _, ok := err.(fail.ErrNotFound); ok {
+			return tenant.Service, nil
+		}
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	if region == "" {
+		return tenant.Service, nil
+	}
+	return serviceInRegion(tenant, region, zone)
+}
+
 // getCurrentTenant returns the tenant used for commands or, if not set, set the tenant to use if it is the only one registered
 func getCurrentTenant() *Tenant {
 	if currentTenant == nil {
@@ -162,3 +192,34 @@ func (s *TenantListener) Set(ctx context.Context, in *pb.TenantName) (empty *goo
 	log.Infof("Current tenant is now '%s'", name)
 	return empty, nil
 }
+
+// Quotas returns the limits and usages of the resources of the current tenant
+func (s *TenantListener) Quotas(ctx context.Context, in *googleprotobuf.Empty) (tq *pb.TenantQuotas, err error) {
+	if s == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, fail.InvalidInstanceError().Message())
+	}
+
+	tracer := debug.NewTracer(nil, "", true).WithStopwatch().GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogError(tracer.TraceMessage(""), &err)()
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	if err := srvutils.JobRegister(ctx, cancelFunc, "Tenant Quotas"); err == nil {
+		defer srvutils.JobDeregister(ctx)
+	}
+
+	tenant := GetCurrentTenant()
+	if tenant == nil {
+		log.Info("Can't get tenant quotas: no tenant set")
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot get tenant quotas: no tenant set")
+	}
+
+	quotas, err := tenant.Service.GetQuotas()
+	if err != nil {
+		if _, ok := fail.Cause(err).(fail.ErrNotImplemented); ok {
+			return nil, status.Errorf(codes.Unimplemented, "quotas are not available for tenant '%s'", tenant.name)
+		}
+		return nil, status.Errorf(codes.Internal, getUserMessage(err))
+	}
+	return srvutils.ToPBTenantQuotas(quotas)
+}
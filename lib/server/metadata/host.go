@@ -17,6 +17,7 @@
 package metadata
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/graymeta/stow"
@@ -290,6 +291,7 @@ func (mh *Host) Delete() (err error) {
 }
 
 // Browse walks through host folder and executes a callback for each entries
+// Entries that cannot be deserialized are logged and skipped
 func (mh *Host) Browse(callback func(*abstract.Host) error) (err error) {
 	defer fail.OnPanic(&err)()
 
@@ -309,7 +311,8 @@ func (mh *Host) Browse(callback func(*abstract.Host) error) (err error) {
 			host := abstract.NewHost()
 			nerr := host.Deserialize(buf)
 			if nerr != nil {
-				return nerr
+				logrus.Warnf("skipping corrupted host metadata entry: %v", nerr)
+				return nil
 			}
 
 			cav := callback(host)
@@ -320,6 +323,39 @@ func (mh *Host) Browse(callback func(*abstract.Host) error) (err error) {
 	return err
 }
 
+// BrowsePage walks through a page of host folder, in order of host IDs, and executes a callback for each entry.
+// Browsing starts after the host whose ID is 'marker' (from the first one if empty) and stops after 'limit' hosts
+// (0 meaning no limit), on cancellation of ctx or when callback returns metadata.ErrStopBrowsing.
+// Returns the marker to use to browse the next page, empty if all the hosts have been browsed.
+// Entries that cannot be deserialized are logged and skipped
+func (mh *Host) BrowsePage(ctx context.Context, marker string, limit int, callback func(*abstract.Host) error) (next string, err error) {
+	defer fail.OnPanic(&err)()
+
+	if mh == nil {
+		return "", fail.InvalidInstanceError()
+	}
+	if mh.item == nil {
+		return "", fail.InvalidParameterError("mh.item", "cannot be nil")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', %d)", marker, limit), true).GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogErrorWithLevel(tracer.TraceMessage(""), &err, logrus.TraceLevel)()
+
+	return mh.item.BrowsePageInto(
+		ctx, ByIDFolderName, marker, limit, func(buf []byte) error {
+			host := abstract.NewHost()
+			nerr := host.Deserialize(buf)
+			if nerr != nil {
+				logrus.Warnf("skipping corrupted host metadata entry: %v", nerr)
+				return nil
+			}
+
+			return callback(host)
+		},
+	)
+}
+
 // SaveHost saves the Host definition in Object Storage
 func SaveHost(svc iaas.Service, host *abstract.Host) (mh *Host, err error) {
 	defer fail.OnPanic(&err)()
@@ -381,6 +417,70 @@ func RemoveHost(svc iaas.Service, host *abstract.Host) (err error) {
 	return ch.Delete()
 }
 
+// RenameHost moves the host definition in Object Storage under the name 'newName'
+// The entry by name is written before the entry by ID is updated, and the old entry by name is removed last; on
+// failure, the entries already written are restored so the host stays reachable by its old name only
+func RenameHost(svc iaas.Service, host *abstract.Host, newName string) (err error) {
+	defer fail.OnPanic(&err)()
+
+	if svc == nil {
+		return fail.InvalidParameterError("svc", "cannot be nil")
+	}
+	if host == nil {
+		return fail.InvalidParameterError("host", "cannot be nil")
+	}
+	if newName == "" {
+		return fail.InvalidParameterError("newName", "cannot be empty string")
+	}
+
+	tracer := debug.NewTracer(nil, fmt.Sprintf("('%s', '%s')", host.Name, newName), true).GoingIn()
+	defer tracer.OnExitTrace()()
+	defer fail.OnExitLogErrorWithLevel(tracer.TraceMessage(""), &err, logrus.TraceLevel)()
+
+	mh, err := NewHost(svc)
+	if err != nil {
+		return err
+	}
+	ch, err := mh.Carry(host)
+	if err != nil {
+		return err
+	}
+
+	oldName := host.Name
+	host.Name = newName
+	defer func() {
+		if err != nil {
+			host.Name = oldName
+		}
+	}()
+
+	err = ch.item.WriteInto(ByNameFolderName, newName)
+	if err != nil {
+		return err
+	}
+	err = ch.item.WriteInto(ByIDFolderName, host.ID)
+	if err != nil {
+		if derr := ch.item.DeleteFrom(ByNameFolderName, newName); derr != nil {
+			logrus.Errorf("cleaning up on failure, failed to remove metadata of host '%s' by name: %v", newName, derr)
+		}
+		return err
+	}
+
+	err = ch.item.DeleteFrom(ByNameFolderName, oldName)
+	if err != nil {
+		// Restores the previous state: the entry by ID carries the old name again
+		host.Name = oldName
+		if derr := ch.item.WriteInto(ByIDFolderName, host.ID); derr != nil {
+			logrus.Errorf("cleaning up on failure, failed to restore metadata of host '%s' by ID: %v", oldName, derr)
+		}
+		if derr := ch.item.DeleteFrom(ByNameFolderName, newName); derr != nil {
+			logrus.Errorf("cleaning up on failure, failed to remove metadata of host '%s' by name: %v", newName, derr)
+		}
+		return fail.Wrap(err, fmt.Sprintf("failed to remove metadata of host '%s' by name", oldName))
+	}
+	return nil
+}
+
 // LoadHost gets the host definition from Object Storage
 // logic: Read by ID; if error is ErrNotFound then read by name; if error is ErrNotFound return this error
 //        In case of any other error, abort the retry to propagate the error
@@ -25,9 +25,13 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
+// ignoreFrozenKey is the grpc metadata key telling the daemon to mutate frozen hosts anyway
+const ignoreFrozenKey = "ignore-frozen"
+
 var (
 	clientRPCUUID       uuid.UUID
 	uuidSet             bool
+	ignoreFrozen        bool
 	mutexContextManager sync.Mutex
 )
 
@@ -41,6 +45,9 @@ func GetContext(storeUUID bool) (context.Context, error) {
 		return nil, err
 	}
 	clientContext = metadata.AppendToOutgoingContext(clientContext, "UUID", aUUID)
+	if GetIgnoreFrozen() {
+		clientContext = metadata.AppendToOutgoingContext(clientContext, ignoreFrozenKey, "true")
+	}
 	return clientContext, nil
 }
 
@@ -64,6 +71,20 @@ func GetUUID() string {
 	return clientRPCUUID.String()
 }
 
+// SetIgnoreFrozen tells if the next requests are allowed to mutate frozen hosts
+func SetIgnoreFrozen(ignore bool) {
+	mutexContextManager.Lock()
+	defer mutexContextManager.Unlock()
+	ignoreFrozen = ignore
+}
+
+// GetIgnoreFrozen tells if the requests are allowed to mutate frozen hosts
+func GetIgnoreFrozen() bool {
+	mutexContextManager.Lock()
+	defer mutexContextManager.Unlock()
+	return ignoreFrozen
+}
+
 // generateUUID ...
 func generateUUID(store bool) (string, error) {
 	mutexContextManager.Lock()
@@ -78,3 +99,18 @@ func generateUUID(store bool) (string, error) {
 	}
 	return newUUID.String(), nil
 }
+
+// --------------------- SERVER ---------------------------------
+
+// IgnoreFrozen tells if the client asked to mutate frozen hosts anyway
+func IgnoreFrozen(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	values := md.Get(ignoreFrozenKey)
+	return len(values) > 0 && values[0] == "true"
+}
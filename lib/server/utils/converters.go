@@ -18,15 +18,15 @@ package utils
 
 import (
 	"math"
+	"sort"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	pb "github.com/CS-SI/SafeScale/lib"
 	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract"
-	"github.com/CS-SI/SafeScale/lib/server/iaas/abstract/enums/hostproperty"
 	propsv1 "github.com/CS-SI/SafeScale/lib/server/iaas/abstract/properties/v1"
 	"github.com/CS-SI/SafeScale/lib/system"
-	"github.com/CS-SI/SafeScale/lib/utils/data"
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 )
 
@@ -43,11 +43,15 @@ func ToPBSshConfig(from *system.SSHConfig) (gw *pb.SshConfig, err error) {
 		}
 	}
 	return &pb.SshConfig{
-		Gateway:    gw,
-		Host:       from.Host,
-		Port:       int32(from.Port),
-		PrivateKey: from.PrivateKey,
-		User:       from.User,
+		Gateway:             gw,
+		Host:                from.Host,
+		Port:                int32(from.Port),
+		PrivateKey:          from.PrivateKey,
+		User:                from.User,
+		HostKey:             from.HostKey,
+		ServerAliveInterval: int32(from.ServerAliveInterval),
+		ServerAliveCountMax: int32(from.ServerAliveCountMax),
+		ConnectTimeout:      int32(from.ConnectTimeout),
 	}, nil
 }
 
@@ -72,6 +76,12 @@ func ToSystemSSHConfig(from *pb.SshConfig) (gw *system.SSHConfig, err error) {
 		PrivateKey:    from.PrivateKey,
 		Port:          int(from.Port),
 		GatewayConfig: gw,
+		HostKey:       from.HostKey,
+		SSHConnectionOptions: system.SSHConnectionOptions{
+			ServerAliveInterval: int(from.ServerAliveInterval),
+			ServerAliveCountMax: int(from.ServerAliveCountMax),
+			ConnectTimeout:      int(from.ConnectTimeout),
+		},
 	}, nil
 }
 
@@ -101,6 +111,108 @@ func ToPBVolumeAttachment(in *abstract.VolumeAttachment) (*pb.VolumeAttachment,
 	}, nil
 }
 
+// ToPBHostVolume converts an abstract.AttachedVolume to a *pb.HostVolume
+func ToPBHostVolume(in *abstract.AttachedVolume) (*pb.HostVolume, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+	return &pb.HostVolume{
+		Id:         in.ID,
+		Name:       in.Name,
+		Size:       int32(in.Size),
+		Device:     in.Device,
+		MountPoint: in.MountPoint,
+		FileSystem: in.FileSystem,
+	}, nil
+}
+
+// ToPBHostNetworkInterface converts an abstract.HostNetworkInterface to a *pb.HostNetworkInterface
+func ToPBHostNetworkInterface(in *abstract.HostNetworkInterface) (*pb.HostNetworkInterface, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+	return &pb.HostNetworkInterface{
+		Name:         in.Name,
+		MacAddress:   in.MACAddress,
+		NetworkId:    in.NetworkID,
+		NetworkName:  in.NetworkName,
+		PrivateIps:   in.PrivateIPs,
+		PublicIp:     in.PublicIP,
+		DefaultRoute: in.Default,
+	}, nil
+}
+
+// ToPBHostListeningPort converts an abstract.ListeningPort to a *pb.HostListeningPort
+func ToPBHostListeningPort(in *abstract.ListeningPort) (*pb.HostListeningPort, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+	return &pb.HostListeningPort{
+		Protocol: in.Protocol,
+		Address:  in.Address,
+		Port:     int32(in.Port),
+		Process:  in.Process,
+	}, nil
+}
+
+// ToPBHostProcess converts an abstract.ProcessInfo to a *pb.HostProcess
+func ToPBHostProcess(in abstract.ProcessInfo) *pb.HostProcess {
+	return &pb.HostProcess{
+		Pid:     int32(in.PID),
+		Ppid:    int32(in.PPID),
+		User:    in.User,
+		Cpu:     float32(in.CPU),
+		Memory:  float32(in.Memory),
+		Command: in.Command,
+	}
+}
+
+// ToPBHostConnectivityResult converts an abstract.ConnResult to a *pb.HostConnectivityResult
+func ToPBHostConnectivityResult(in abstract.ConnResult) *pb.HostConnectivityResult {
+	return &pb.HostConnectivityResult{
+		Target:    in.Target.String(),
+		Success:   in.Success,
+		LatencyMs: int64(in.Latency / time.Millisecond),
+		Error:     in.Error,
+	}
+}
+
+// ToPBFilesystemUsage converts an abstract.FilesystemUsage to a *pb.FilesystemUsage
+func ToPBFilesystemUsage(in *abstract.FilesystemUsage) (*pb.FilesystemUsage, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+	return &pb.FilesystemUsage{
+		Device:     in.Device,
+		Type:       in.Type,
+		MountPoint: in.MountPoint,
+		Size:       in.Size,
+		Used:       in.Used,
+		Available:  in.Available,
+		Inodes:     in.Inodes,
+		InodesUsed: in.InodesUsed,
+		InodesFree: in.InodesFree,
+	}, nil
+}
+
+// ToPBHostMetrics converts an abstract.HostMetrics to a *pb.HostMetrics
+func ToPBHostMetrics(in *abstract.HostMetrics) (*pb.HostMetrics, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+	return &pb.HostMetrics{
+		Uptime:      int64(in.Uptime.Seconds()),
+		Load1:       in.Load1,
+		Load5:       in.Load5,
+		Load15:      in.Load15,
+		MemoryTotal: in.MemoryTotal,
+		MemoryUsed:  in.MemoryUsed,
+		MemoryFree:  in.MemoryFree,
+		CpuCount:    int32(in.CPUCount),
+		Unavailable: in.Unavailable,
+	}, nil
+}
+
 // ToPBVolumeInfo converts an api.Volume to a *VolumeInfo
 func ToPBVolumeInfo(volume *abstract.Volume, mounts map[string]*propsv1.HostLocalMount) (*pb.VolumeInfo, error) {
 	if volume == nil {
@@ -208,53 +320,76 @@ func ToPBShareMountList(hostName string, share *propsv1.HostShare, mounts map[st
 
 // ToPBHost convert an host from api to protocolbuffer format
 func ToPBHost(in *abstract.Host) (*pb.Host, error) {
-	var (
-		hostNetworkV1 *propsv1.HostNetwork
-		hostSizingV1  *propsv1.HostSizing
-		hostVolumesV1 *propsv1.HostVolumes
-		volumes       []string
-	)
-
 	if in == nil {
 		return nil, fail.InvalidParameterError("in", "cannot be nil")
 	}
 
-	err := in.Properties.LockForRead(hostproperty.NetworkV1).ThenUse(
-		func(clonable data.Clonable) error {
-			hostNetworkV1 = clonable.(*propsv1.HostNetwork)
-			return in.Properties.LockForRead(hostproperty.SizingV1).ThenUse(
-				func(clonable data.Clonable) error {
-					hostSizingV1 = clonable.(*propsv1.HostSizing)
-					return in.Properties.LockForRead(hostproperty.VolumesV1).ThenUse(
-						func(clonable data.Clonable) error {
-							hostVolumesV1 = clonable.(*propsv1.HostVolumes)
-							for k := range hostVolumesV1.VolumesByName {
-								volumes = append(volumes, k)
-							}
-							return nil
-						},
-					)
-				},
-			)
-		},
-	)
+	details, err := abstract.NewHostDetails(in)
 	if err != nil {
 		return nil, err
 	}
-	return &pb.Host{
-		Cpu:                 int32(hostSizingV1.AllocatedSize.Cores),
-		Disk:                int32(hostSizingV1.AllocatedSize.DiskSize),
-		GatewayId:           hostNetworkV1.DefaultGatewayID,
-		Id:                  in.ID,
-		PublicIp:            in.GetPublicIP(),
-		PrivateIp:           in.GetPrivateIP(),
-		Name:                in.Name,
-		PrivateKey:          in.PrivateKey,
-		Password:            in.Password,
-		Ram:                 hostSizingV1.AllocatedSize.RAMSize,
-		State:               pb.HostState(in.LastState),
-		AttachedVolumeNames: volumes,
-	}, nil
+	return ToPBHostDetails(details)
+}
+
+// ToPBHostDetails converts the details of an host to protocolbuffer format
+func ToPBHostDetails(in *abstract.HostDetails) (*pb.Host, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+
+	out := &pb.Host{
+		GatewayId:        in.Network.DefaultGatewayID,
+		Id:               in.ID,
+		PublicIp:         in.GetPublicIP(),
+		PrivateIp:        in.GetPrivateIP(),
+		Name:             in.Name,
+		PrivateKey:       in.PrivateKey,
+		Password:         in.Password,
+		State:            pb.HostState(in.LastState),
+		Hostname:         in.System.HostName,
+		Purpose:          in.Description.Purpose,
+		Frozen:           in.Freeze.Frozen,
+		Spot:             in.Description.Spot,
+		Preempted:        in.Description.Preempted,
+		AvailabilityZone: in.Description.AvailabilityZone,
+	}
+	for name := range in.SecurityGroups.ByName {
+		out.SecurityGroups = append(out.SecurityGroups, name)
+	}
+	sort.Strings(out.SecurityGroups)
+	if in.Sizing.AllocatedSize != nil {
+		out.Cpu = int32(in.Sizing.AllocatedSize.Cores)
+		out.Disk = int32(in.Sizing.AllocatedSize.DiskSize)
+		out.Ram = in.Sizing.AllocatedSize.RAMSize
+	}
+	if in.SizingV2 != nil {
+		out.BootDiskType = in.SizingV2.BootDiskType
+	}
+	out.AttachedVolumeNames = sortedKeys(in.Volumes.VolumesByName)
+	out.NetworkNames = sortedKeys(in.Network.NetworksByName)
+	out.ShareNames = sortedKeys(in.Shares.ByName)
+	for path := range in.Mounts.LocalMountsByPath {
+		out.MountPaths = append(out.MountPaths, path)
+	}
+	for path := range in.Mounts.RemoteMountsByPath {
+		out.MountPaths = append(out.MountPaths, path)
+	}
+	sort.Strings(out.MountPaths)
+	for name := range in.Features.Installed {
+		out.InstalledFeatures = append(out.InstalledFeatures, name)
+	}
+	sort.Strings(out.InstalledFeatures)
+	return out, nil
+}
+
+// sortedKeys returns the keys of the map, sorted
+func sortedKeys(in map[string]string) []string {
+	var out []string
+	for k := range in {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
 }
 
 // ToPBHostDefinition ...
@@ -350,8 +485,36 @@ func ToPBImage(in *abstract.Image) (*pb.Image, error) {
 		return nil, fail.InvalidParameterError("in", "cannot be nil")
 	}
 	return &pb.Image{
-		Id:   in.ID,
-		Name: in.Name,
+		Id:           in.ID,
+		Name:         in.Name,
+		OsFamily:     in.OSFamily,
+		MinDiskGb:    int32(in.MinDiskGB),
+		MinRamGb:     in.MinRAMGB,
+		Architecture: in.Architecture,
+	}, nil
+}
+
+// ToPBQuota converts a quota from api to protocolbuffer format
+func ToPBQuota(in abstract.Quota) *pb.Quota {
+	return &pb.Quota{
+		Limit:     int32(in.Limit),
+		Used:      int32(in.Used),
+		Unlimited: in.Unlimited,
+	}
+}
+
+// ToPBTenantQuotas converts quotas from api to protocolbuffer format
+func ToPBTenantQuotas(in *abstract.Quotas) (*pb.TenantQuotas, error) {
+	if in == nil {
+		return nil, fail.InvalidParameterError("in", "cannot be nil")
+	}
+	return &pb.TenantQuotas{
+		Instances:      ToPBQuota(in.Instances),
+		Vcpus:          ToPBQuota(in.VCPUs),
+		Ram:            ToPBQuota(in.RAM),
+		FloatingIps:    ToPBQuota(in.FloatingIPs),
+		Volumes:        ToPBQuota(in.Volumes),
+		SecurityGroups: ToPBQuota(in.SecurityGroups),
 	}, nil
 }
 
@@ -375,6 +538,38 @@ func ToPBNetwork(in *abstract.Network) (*pb.Network, error) {
 	}, nil
 }
 
+// ToPBNetworkRoute converts an abstract.NetworkRoute to a *pb.NetworkRoute
+func ToPBNetworkRoute(in abstract.NetworkRoute) *pb.NetworkRoute {
+	return &pb.NetworkRoute{
+		Destination: in.Destination,
+		NextHop:     in.NextHop,
+	}
+}
+
+// ToPBNetworkTopology converts an *abstract.NetworkTopology to a *pb.NetworkTopology
+func ToPBNetworkTopology(in *abstract.NetworkTopology) *pb.NetworkTopology {
+	out := &pb.NetworkTopology{
+		Id:    in.ID,
+		Name:  in.Name,
+		Cidr:  in.CIDR,
+		Hosts: in.Hosts,
+	}
+	for _, gw := range in.Gateways {
+		out.Gateways = append(out.Gateways, &pb.NetworkTopologyGateway{
+			Id:        gw.ID,
+			Name:      gw.Name,
+			PrivateIp: gw.PrivateIP,
+			PublicIp:  gw.PublicIP,
+			Primary:   gw.Primary,
+			Missing:   gw.Missing,
+		})
+	}
+	for _, subnet := range in.Subnets {
+		out.Subnets = append(out.Subnets, ToPBNetworkTopology(subnet))
+	}
+	return out
+}
+
 // ToPBFileList convert a list of file names from api to protocolbuffer FileList format
 func ToPBFileList(fileNames []string, uploadDates []string, fileSizes []int64, fileBuckets [][]string) *pb.FileList {
 	var files []*pb.File
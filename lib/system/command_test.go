@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package system
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/CS-SI/SafeScale/lib/utils/cli/enums/outputs"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestRunWithTimeoutKillsCommand(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "sshkey")
+	require.Nil(t, err)
+
+	sc := &SSHCommand{cmd: exec.Command("sleep", "30"), keyFile: keyFile}
+
+	begin := time.Now()
+	retcode, _, _, err := sc.RunWithTimeout(nil, outputs.COLLECT, time.Second)
+	require.NotNil(t, err)
+	assert.IsType(t, fail.ErrTimeout{}, err)
+	assert.Equal(t, RetCodeTimeout, retcode)
+	assert.True(t, time.Since(begin) < 10*time.Second)
+
+	// the process has been killed and reaped
+	require.NotNil(t, sc.cmd.ProcessState)
+	assert.Equal(t, syscall.ESRCH, syscall.Kill(sc.cmd.Process.Pid, 0))
+}
+
+func TestRunWithTimeoutCommandEndingInTime(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "sshkey")
+	require.Nil(t, err)
+
+	sc := &SSHCommand{cmd: exec.Command("sh", "-c", "echo done; exit 3"), keyFile: keyFile}
+
+	retcode, stdout, _, err := sc.RunWithTimeout(nil, outputs.COLLECT, 10*time.Second)
+	require.Nil(t, err)
+	assert.Equal(t, 3, retcode)
+	assert.Equal(t, "done\n", stdout)
+}
+
+func TestHostKeyOptions(t *testing.T) {
+	options, knownHostsFile, err := hostKeyOptions(&SSHConfig{})
+	require.Nil(t, err)
+	assert.Nil(t, knownHostsFile)
+	assert.Contains(t, options, "-oStrictHostKeyChecking=no")
+
+	options, knownHostsFile, err = hostKeyOptions(&SSHConfig{HostKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHostKey\n"})
+	require.Nil(t, err)
+	require.NotNil(t, knownHostsFile)
+	defer func() { _ = removeTempFile(knownHostsFile) }()
+	assert.Contains(t, options, "-oStrictHostKeyChecking=yes")
+	assert.Contains(t, options, "-oUserKnownHostsFile="+knownHostsFile.Name())
+	assert.Contains(t, options, "-oHostKeyAlias="+hostKeyAlias)
+
+	content, err := ioutil.ReadFile(knownHostsFile.Name())
+	require.Nil(t, err)
+	assert.Equal(t, hostKeyAlias+" ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHostKey\n", string(content))
+}
+
+func TestParseHostKey(t *testing.T) {
+	key, err := parseHostKey("\n" + hostKeyAlias + " ecdsa-sha2-nistp256 AAAAE2VjZHNh\n")
+	require.Nil(t, err)
+	assert.Equal(t, "ecdsa-sha2-nistp256 AAAAE2VjZHNh", key)
+
+	_, err = parseHostKey("otherhost ssh-rsa AAAAB3NzaC1yc2E\n")
+	assert.NotNil(t, err)
+	_, err = parseHostKey("")
+	assert.NotNil(t, err)
+}
+
+func TestIdentityOptions(t *testing.T) {
+	prefix, options, keyFile, err := identityOptions(&SSHConfig{PrivateKey: "key"})
+	require.Nil(t, err)
+	require.NotNil(t, keyFile)
+	defer func() { _ = removeTempFile(keyFile) }()
+	assert.Empty(t, prefix)
+	assert.Equal(t, "-i '"+keyFile.Name()+"'", options)
+	content, err := ioutil.ReadFile(keyFile.Name())
+	require.Nil(t, err)
+	assert.Equal(t, "key", string(content))
+
+	prefix, options, keyFile, err = identityOptions(&SSHConfig{PrivateKey: "key", KeyPath: "/secure/id_rsa"})
+	require.Nil(t, err)
+	assert.Nil(t, keyFile)
+	assert.Empty(t, prefix)
+	assert.Equal(t, "-i '/secure/id_rsa'", options)
+
+	prefix, options, keyFile, err = identityOptions(
+		&SSHConfig{PrivateKey: "key", KeyPath: "/secure/id_rsa", AgentSocket: "/run/agent.sock"},
+	)
+	require.Nil(t, err)
+	assert.Nil(t, keyFile)
+	assert.Equal(t, "SSH_AUTH_SOCK='/run/agent.sock' ", prefix)
+	assert.Equal(t, "-oIdentitiesOnly=no", options)
+}
+
+func TestIdentityOptionsAreQuoted(t *testing.T) {
+	prefix, options, _, err := identityOptions(&SSHConfig{KeyPath: "/tmp/key; touch /tmp/pwned"})
+	require.Nil(t, err)
+	assert.Empty(t, prefix)
+	out, err := exec.Command("bash", "-c", "printf '%s\\n' "+options).Output()
+	require.Nil(t, err)
+	assert.Equal(t, "-i\n/tmp/key; touch /tmp/pwned\n", string(out))
+
+	prefix, _, _, err = identityOptions(&SSHConfig{AgentSocket: "/run/a b'c.sock"})
+	require.Nil(t, err)
+	out, err = exec.Command("bash", "-c", prefix+"printenv SSH_AUTH_SOCK").Output()
+	require.Nil(t, err)
+	assert.Equal(t, "/run/a b'c.sock\n", string(out))
+}
+
+func TestCloudInitDoneCommandWithoutCloudInit(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "sshkey")
+	require.Nil(t, err)
+
+	// Without cloud-init in PATH, there is nothing to wait for
+	cmd := exec.Command("sh", "-c", cloudInitDoneCommand)
+	cmd.Env = []string{"PATH=" + t.TempDir()}
+	sc := &SSHCommand{cmd: cmd, keyFile: keyFile}
+
+	retcode, _, _, err := sc.RunWithTimeout(nil, outputs.COLLECT, 10*time.Second)
+	require.Nil(t, err)
+	assert.Equal(t, 0, retcode)
+}
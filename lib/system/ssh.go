@@ -51,7 +51,14 @@ import (
 //      To make profit of this multiplexing functionality, we have to change the way we manage ports for tunnels: we have to always
 //      use the same port for all access to a same host (not the case currently)
 //      May not be used for interactive ssh connection...
-const sshOptions = "-q -oIdentitiesOnly=yes -oStrictHostKeyChecking=no -oUserKnownHostsFile=/dev/null -oPubkeyAuthentication=yes -oPasswordAuthentication=no"
+const sshOptions = "-q -oIdentitiesOnly=yes -oPubkeyAuthentication=yes -oPasswordAuthentication=no"
+
+// hostKeyAlias is the name used to register the host key in generated known_hosts files, so the check does not depend
+// on the address used to reach the host (which is 127.0.0.1 through tunnels)
+const hostKeyAlias = "safescale-host"
+
+// RetCodeTimeout is the return code of a command killed because it ran out of time
+const RetCodeTimeout = -2
 
 var (
 	sshErrorMap = map[int]string{
@@ -119,21 +126,81 @@ func IsSCPRetryable(code int) bool {
 
 // SSHConfig helper to manage ssh session
 type SSHConfig struct {
-	User          string
-	Host          string
-	PrivateKey    string
+	User       string
+	Host       string
+	PrivateKey string
+	// AgentSocket is the socket of an ssh-agent holding the key to authenticate with; if set, PrivateKey and KeyPath
+	// are not used
+	AgentSocket string
+	// KeyPath is the path of a file containing the private key to authenticate with; if set, PrivateKey is not used
+	KeyPath       string
 	Port          int
 	LocalPort     int
 	GatewayConfig *SSHConfig
-	cmdTpl        string
+	// HostKey is the public key of the SSH server ("<type> <base64 key>"); if set, the host key is verified on
+	// connection, otherwise it is not checked
+	HostKey string
+	SSHConnectionOptions
+	cmdTpl string
+}
+
+const (
+	// DefaultServerAliveInterval is the default interval in seconds between keepalive messages sent to the SSH server
+	DefaultServerAliveInterval = 60
+	// DefaultServerAliveCountMax is the default number of keepalive messages left unanswered before disconnecting
+	DefaultServerAliveCountMax = 3
+)
+
+// SSHConnectionOptions tunes how the connections to the SSH server (and the tunnels through the gateways) detect
+// broken links; zero values mean the defaults
+type SSHConnectionOptions struct {
+	// ServerAliveInterval is the interval in seconds between keepalive messages (default DefaultServerAliveInterval)
+	ServerAliveInterval int
+	// ServerAliveCountMax is the number of keepalive messages left unanswered before disconnecting
+	// (default DefaultServerAliveCountMax)
+	ServerAliveCountMax int
+	// ConnectTimeout is the timeout in seconds of the establishment of the connection (default: the one of the system)
+	ConnectTimeout int
+}
+
+// Validate checks the options are in acceptable ranges
+func (opts SSHConnectionOptions) Validate() error {
+	if opts.ServerAliveInterval < 0 || opts.ServerAliveInterval > 3600 {
+		return fail.InvalidParameterError("ServerAliveInterval", "must be between 0 (default) and 3600 seconds")
+	}
+	if opts.ServerAliveCountMax < 0 || opts.ServerAliveCountMax > 100 {
+		return fail.InvalidParameterError("ServerAliveCountMax", "must be between 0 (default) and 100")
+	}
+	if opts.ConnectTimeout < 0 || opts.ConnectTimeout > 600 {
+		return fail.InvalidParameterError("ConnectTimeout", "must be between 0 (default) and 600 seconds")
+	}
+	return nil
+}
+
+// sshArgs returns the ssh options corresponding to the connection options
+func (opts SSHConnectionOptions) sshArgs() string {
+	interval := opts.ServerAliveInterval
+	if interval <= 0 {
+		interval = DefaultServerAliveInterval
+	}
+	countMax := opts.ServerAliveCountMax
+	if countMax <= 0 {
+		countMax = DefaultServerAliveCountMax
+	}
+	args := fmt.Sprintf("-oServerAliveInterval=%d -oServerAliveCountMax=%d", interval, countMax)
+	if opts.ConnectTimeout > 0 {
+		args += fmt.Sprintf(" -oConnectTimeout=%d", opts.ConnectTimeout)
+	}
+	return args
 }
 
 // SSHTunnel a SSH tunnel
 type SSHTunnel struct {
-	port      int
-	cmd       *exec.Cmd
-	cmdString string
-	keyFile   *os.File
+	port           int
+	cmd            *exec.Cmd
+	cmdString      string
+	keyFile        *os.File
+	knownHostsFile *os.File
 }
 
 // SSHErrorString returns if possible the string corresponding to SSH execution
@@ -155,7 +222,11 @@ func SCPErrorString(retcode int) string {
 // Close closes ssh tunnel
 func (tunnel *SSHTunnel) Close() error {
 	defer func() {
-		lazyErr := utils.LazyRemove(tunnel.keyFile.Name())
+		lazyErr := removeTempFile(tunnel.keyFile)
+		if lazyErr != nil {
+			logrus.Error(lazyErr)
+		}
+		lazyErr = removeTempFile(tunnel.knownHostsFile)
 		if lazyErr != nil {
 			logrus.Error(lazyErr)
 		}
@@ -233,6 +304,64 @@ func CreateTempFileFromString(content string, filemode os.FileMode) (*os.File, e
 	return f, nil
 }
 
+// identityOptions returns the ssh options authenticating with the key of cfg, and a prefix to the command setting its
+// environment. The private key is written in a temporary file, returned to be removed by the caller with
+// removeTempFile, only if neither an agent nor a key file is configured
+// The options must be placed before sshOptions on the command line, ssh keeping the first value of an option
+func identityOptions(cfg *SSHConfig) (prefix string, options string, keyFile *os.File, err error) {
+	switch {
+	case cfg.AgentSocket != "":
+		// the keys of the agent are not identity files, they are ignored with -oIdentitiesOnly=yes
+		return "SSH_AUTH_SOCK=" + ShellQuote(cfg.AgentSocket) + " ", "-oIdentitiesOnly=no", nil, nil
+	case cfg.KeyPath != "":
+		return "", "-i " + ShellQuote(cfg.KeyPath), nil, nil
+	default:
+		f, err := CreateTempFileFromString(cfg.PrivateKey, 0400)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("unable to create temporary key file: %s", err.Error())
+		}
+		return "", "-i " + ShellQuote(f.Name()), f, nil
+	}
+}
+
+// hostKeyOptions returns the ssh options checking the host key of cfg
+// If cfg.HostKey is set, a known_hosts file containing only this key is generated and returned, to be removed by the
+// caller with removeTempFile; otherwise host key checking is disabled
+func hostKeyOptions(cfg *SSHConfig) (string, *os.File, error) {
+	if cfg.HostKey == "" {
+		return "-oStrictHostKeyChecking=no -oUserKnownHostsFile=/dev/null", nil, nil
+	}
+
+	f, err := CreateTempFileFromString(fmt.Sprintf("%s %s\n", hostKeyAlias, strings.TrimSpace(cfg.HostKey)), 0600)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create temporary known_hosts file: %s", err.Error())
+	}
+	options := fmt.Sprintf(
+		"-oStrictHostKeyChecking=yes -oUserKnownHostsFile=%s -oGlobalKnownHostsFile=/dev/null -oHostKeyAlias=%s",
+		f.Name(), hostKeyAlias,
+	)
+	return options, f, nil
+}
+
+// removeTempFile removes a temporary file created for a command (key or known_hosts file), if any
+func removeTempFile(f *os.File) error {
+	if f == nil {
+		return nil
+	}
+	return utils.LazyRemove(f.Name())
+}
+
+// parseHostKey returns the host key registered for hostKeyAlias in the content of a known_hosts file
+func parseHostKey(content string) (string, error) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == hostKeyAlias {
+			return fields[1] + " " + fields[2], nil
+		}
+	}
+	return "", fmt.Errorf("no host key received")
+}
+
 func isTunnelReady(port int) bool {
 	// Try to create a server with the port
 	server, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
@@ -250,7 +379,7 @@ func isTunnelReady(port int) bool {
 // buildTunnel create SSH from local host to remote host through gateway
 // if localPort is set to 0 then it's automatically chosen
 func buildTunnel(cfg *SSHConfig) (*SSHTunnel, error) {
-	f, err := CreateTempFileFromString(cfg.GatewayConfig.PrivateKey, 0400)
+	prefix, idOptions, f, err := identityOptions(cfg.GatewayConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -262,10 +391,15 @@ func buildTunnel(cfg *SSHConfig) (*SSHTunnel, error) {
 		}
 	}
 
-	options := sshOptions + " -oServerAliveInterval=60"
+	hkOptions, knownHostsFile, err := hostKeyOptions(cfg.GatewayConfig)
+	if err != nil {
+		return nil, err
+	}
+	options := sshOptions + " " + hkOptions + " " + cfg.GatewayConfig.sshArgs()
 	cmdString := fmt.Sprintf(
-		"ssh -i %s -C -NL 127.0.0.1:%d:%s:%d %s@%s %s -p %d",
-		f.Name(),
+		"%sssh %s -C -NL 127.0.0.1:%d:%s:%d %s@%s %s -p %d",
+		prefix,
+		idOptions,
 		localPort,
 		cfg.Host,
 		cfg.Port,
@@ -276,8 +410,9 @@ func buildTunnel(cfg *SSHConfig) (*SSHTunnel, error) {
 	)
 	if cfg.Host != "127.0.0.1" {
 		cmdString = fmt.Sprintf(
-			"ssh -i %s -C -NL %d:%s:%d %s@%s %s -p %d",
-			f.Name(),
+			"%sssh %s -C -NL %d:%s:%d %s@%s %s -p %d",
+			prefix,
+			idOptions,
 			localPort,
 			cfg.Host,
 			cfg.Port,
@@ -292,6 +427,12 @@ func buildTunnel(cfg *SSHConfig) (*SSHTunnel, error) {
 	err = cmd.Start()
 	//	err = cmd.Wait()
 	if err != nil {
+		if nerr := removeTempFile(knownHostsFile); nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
+		if nerr := removeTempFile(f); nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
 		return nil, err
 	}
 
@@ -299,16 +440,18 @@ func buildTunnel(cfg *SSHConfig) (*SSHTunnel, error) {
 		if cmdString != "" {
 			logrus.Debugf("[TRACE] %s", cmdString)
 		}
-		_ = os.MkdirAll(utils.AbsPathify(fmt.Sprintf("$HOME/.safescale/forensics/%s", cfg.Host)), 0777)
-		partials := strings.Split(f.Name(), "/")
-		dumpName := utils.AbsPathify(
-			fmt.Sprintf(
-				"$HOME/.safescale/forensics/%s/%s.sshkey", cfg.Host, partials[len(partials)-1],
-			),
-		)
-		err = ioutil.WriteFile(dumpName, []byte(cfg.GatewayConfig.PrivateKey), 0644)
-		if err != nil {
-			logrus.Warnf("[TRACE] Failure storing key in %s", dumpName)
+		if f != nil {
+			_ = os.MkdirAll(utils.AbsPathify(fmt.Sprintf("$HOME/.safescale/forensics/%s", cfg.Host)), 0777)
+			partials := strings.Split(f.Name(), "/")
+			dumpName := utils.AbsPathify(
+				fmt.Sprintf(
+					"$HOME/.safescale/forensics/%s/%s.sshkey", cfg.Host, partials[len(partials)-1],
+				),
+			)
+			err = ioutil.WriteFile(dumpName, []byte(cfg.GatewayConfig.PrivateKey), 0644)
+			if err != nil {
+				logrus.Warnf("[TRACE] Failure storing key in %s", dumpName)
+			}
 		}
 	}
 
@@ -316,26 +459,35 @@ func buildTunnel(cfg *SSHConfig) (*SSHTunnel, error) {
 		time.Sleep(10 * time.Millisecond)
 	}
 	return &SSHTunnel{
-		port:      localPort,
-		cmd:       cmd,
-		cmdString: cmdString,
-		keyFile:   f,
+		port:           localPort,
+		cmd:            cmd,
+		cmdString:      cmdString,
+		keyFile:        f,
+		knownHostsFile: knownHostsFile,
 	}, nil
 }
 
 // SSHCommand defines a SSH command
 type SSHCommand struct {
-	cmd     *exec.Cmd
-	tunnels []*SSHTunnel
-	keyFile *os.File
+	cmd            *exec.Cmd
+	tunnels        []*SSHTunnel
+	keyFile        *os.File
+	knownHostsFile *os.File
 }
 
 func (sc *SSHCommand) closeTunneling() error {
+	err := CloseTunnels(sc.tunnels)
+	sc.tunnels = []*SSHTunnel{}
+	return err
+}
+
+// CloseTunnels closes the tunnels created by CreateTunneling
+// Tunnels are closed starting from the last one created, which is the one relying on all the others
+func CloseTunnels(tunnels []*SSHTunnel) error {
 	var err error
-	for _, t := range sc.tunnels {
-		err = t.Close()
+	for i := len(tunnels) - 1; i >= 0; i-- {
+		err = tunnels[i].Close()
 	}
-	sc.tunnels = []*SSHTunnel{}
 
 	// Tunnels are imbricated only last error is significant
 	if err != nil {
@@ -474,7 +626,9 @@ func (sc *SSHCommand) Run(t concurrency.Task, outs outputs.Enum) (int, string, s
 	return sc.RunWithTimeout(t, outs, 0)
 }
 
-// RunWithTimeout ...
+// RunWithTimeout runs the command and waits for it to complete, for at most 'timeout' (no limit if timeout <= 0)
+// If the command runs out of time, it is killed (closing its tunnels) and RunWithTimeout returns RetCodeTimeout
+// with a fail.ErrTimeout
 func (sc *SSHCommand) RunWithTimeout(task concurrency.Task, outs outputs.Enum, timeout time.Duration) (int, string, string, error) {
 	tracer := debug.NewTracer(task, fmt.Sprintf("(%s, %v)", outs.String(), timeout), true).WithStopwatch().GoingIn()
 	tracer.Trace("command=\n%s\n", sc.Display())
@@ -557,12 +711,15 @@ func (sc *SSHCommand) RunWithTimeout(task concurrency.Task, outs outputs.Enum, t
 	if err != nil {
 		return -1, "", "", err
 	}
-	_, err = subtask.StartWithTimeout(
+	// The timeout is enforced by taskExecute and not by the task itself: a task out of time is only abandoned,
+	// leaving the process running; here the process is killed and the task ends once it is really gone
+	_, err = subtask.Start(
 		sc.taskExecute, data.Map{
 			"stdout":          stdoutPipe,
 			"stderr":          stderrPipe,
 			"collect_outputs": outs != outputs.DISPLAY,
-		}, timeout,
+			"timeout":         timeout,
+		},
 	)
 	if err != nil {
 		return -1, "", "", err
@@ -573,6 +730,12 @@ func (sc *SSHCommand) RunWithTimeout(task concurrency.Task, outs outputs.Enum, t
 		return -1, "", "", err
 	}
 	if result, ok := r.(data.Map); ok {
+		if timedOut, ok := result["timedout"].(bool); ok && timedOut {
+			return RetCodeTimeout, result["stdout"].(string), result["stderr"].(string), fail.TimeoutError(
+				fmt.Sprintf("timeout of %s waiting for the command [%s] to end", temporal.FormatDuration(timeout), sc.Display()),
+				timeout, nil,
+			)
+		}
 		return result["retcode"].(int), result["stdout"].(string), result["stderr"].(string), nil
 	}
 	return -1, "", "", fail.InconsistentError("'result' should have been of type 'data.Map'")
@@ -594,6 +757,7 @@ func (sc *SSHCommand) taskExecute(task concurrency.Task, p concurrency.TaskParam
 	var (
 		stdoutPipe, stderrPipe io.ReadCloser
 		collectOutputs         bool
+		timeout                time.Duration
 	)
 	stdoutPipe, ok = params["stdout"].(io.ReadCloser)
 	if !ok {
@@ -612,6 +776,9 @@ func (sc *SSHCommand) taskExecute(task concurrency.Task, p concurrency.TaskParam
 	if collectOutputs, ok = params["collect_outputs"].(bool); !ok {
 		return nil, fail.InvalidParameterError("p['collect_outputs']", "is missing or is not of type bool")
 	}
+	if timeout, ok = params["timeout"].(time.Duration); !ok {
+		return nil, fail.InvalidParameterError("p['timeout']", "is missing or is not of type time.Duration")
+	}
 
 	var (
 		stdoutBridge, stderrBridge cli.PipeBridge
@@ -621,9 +788,10 @@ func (sc *SSHCommand) taskExecute(task concurrency.Task, p concurrency.TaskParam
 	)
 
 	result := data.Map{
-		"retcode": -1,
-		"stdout":  "",
-		"stderr":  "",
+		"retcode":  -1,
+		"stdout":   "",
+		"stderr":   "",
+		"timedout": false,
 	}
 
 	if !collectOutputs {
@@ -654,6 +822,27 @@ func (sc *SSHCommand) taskExecute(task concurrency.Task, p concurrency.TaskParam
 		return result, err
 	}
 
+	// Kills the process if it runs out of time; sc.Wait() will then return and close the tunnels
+	timedOutCh := make(chan struct{})
+	if timeout > 0 {
+		timer := time.AfterFunc(
+			timeout, func() {
+				close(timedOutCh)
+				if err := sc.Kill(); err != nil {
+					logrus.Warnf("failed to kill command [%s] out of time: %v", sc.Display(), err)
+				}
+			},
+		)
+		defer timer.Stop()
+	}
+	defer func() {
+		select {
+		case <-timedOutCh:
+			result["timedout"] = true
+		default:
+		}
+	}()
+
 	if collectOutputs {
 		msgOut, err = ioutil.ReadAll(stdoutPipe)
 		if err != nil {
@@ -720,7 +909,8 @@ func (sc *SSHCommand) taskExecute(task concurrency.Task, p concurrency.TaskParam
 
 func (sc *SSHCommand) cleanup() error {
 	err1 := sc.closeTunneling()
-	err2 := utils.LazyRemove(sc.keyFile.Name())
+	err2 := removeTempFile(sc.keyFile)
+	err3 := removeTempFile(sc.knownHostsFile)
 	if err1 != nil {
 		logrus.Errorf("closeTunneling() failed: %s\n", reflect.TypeOf(err1).String())
 		return fmt.Errorf("unable to close SSH tunnels: %s", err1.Error())
@@ -728,6 +918,9 @@ func (sc *SSHCommand) cleanup() error {
 	if err2 != nil {
 		return fmt.Errorf("unable to close SSH tunnels: %s", err2.Error())
 	}
+	if err3 != nil {
+		return fmt.Errorf("unable to close SSH tunnels: %s", err3.Error())
+	}
 	return nil
 }
 
@@ -737,7 +930,9 @@ func recCreateTunnels(ssh *SSHConfig, tunnels *[]*SSHTunnel) (*SSHTunnel, error)
 		if err != nil {
 			return nil, err
 		}
-		cfg := ssh
+		// works on a copy to not alter the chain of gateways of the caller with local tunnel endpoints
+		cfgCopy := *ssh
+		cfg := &cfgCopy
 		if tunnel != nil {
 			gateway := *ssh.GatewayConfig
 			gateway.Port = tunnel.port
@@ -775,17 +970,60 @@ func (ssh *SSHConfig) CreateTunneling() ([]*SSHTunnel, *SSHConfig, error) {
 	return tunnels, &sshConfig, nil
 }
 
-func createSSHCmd(sshConfig *SSHConfig, cmdString, username, shell string, withTty, withSudo bool) (string, *os.File, error) {
-	f, err := CreateTempFileFromString(sshConfig.PrivateKey, 0400)
+// PortForward forwards the local port 'localPort' to 'remoteHost':'remotePort' as seen from the host, through the
+// chain of gateways of the host; 'remoteHost' may be "localhost" to reach a service of the host itself
+// If localPort is 0, a free port is chosen. Returns the local port used and the tunnels to close with CloseTunnels
+// when the forwarding is not needed anymore.
+func (ssh *SSHConfig) PortForward(localPort int, remoteHost string, remotePort int) (int, []*SSHTunnel, error) {
+	if localPort < 0 || localPort > 65535 {
+		return 0, nil, fmt.Errorf("invalid local port %d", localPort)
+	}
+	if remoteHost == "" {
+		return 0, nil, fmt.Errorf("invalid empty remote host")
+	}
+	if remotePort <= 0 || remotePort > 65535 {
+		return 0, nil, fmt.Errorf("invalid remote port %d", remotePort)
+	}
+
+	tunnels, hostConfig, err := ssh.CreateTunneling()
 	if err != nil {
-		return "", nil, fmt.Errorf("unable to create temporary key file: %s", err.Error())
+		return 0, nil, err
 	}
+	forward := &SSHConfig{
+		Host:          remoteHost,
+		Port:          remotePort,
+		LocalPort:     localPort,
+		GatewayConfig: hostConfig,
+	}
+	tunnel, err := buildTunnel(forward)
+	if err != nil {
+		if cerr := CloseTunnels(tunnels); cerr != nil {
+			logrus.Warnf("failed to close SSH tunnels: %v", cerr)
+		}
+		return 0, nil, fmt.Errorf("unable to create SSH tunnel to %s:%d: %s", remoteHost, remotePort, err.Error())
+	}
+	return tunnel.port, append(tunnels, tunnel), nil
+}
 
-	options := sshOptions + " -oLogLevel=error"
+func createSSHCmd(sshConfig *SSHConfig, cmdString, username, shell string, withTty, withSudo bool) (string, *os.File, *os.File, error) {
+	hkOptions, knownHostsFile, err := hostKeyOptions(sshConfig)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	prefix, idOptions, f, err := identityOptions(sshConfig)
+	if err != nil {
+		if nerr := removeTempFile(knownHostsFile); nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
+		return "", nil, nil, err
+	}
+
+	options := sshOptions + " " + hkOptions + " " + sshConfig.sshArgs() + " -oLogLevel=error"
 
 	sshCmdString := fmt.Sprintf(
-		"ssh -i %s %s -p %d %s@%s",
-		f.Name(),
+		"%sssh %s %s -p %d %s@%s",
+		prefix,
+		idOptions,
 		options,
 		sshConfig.Port,
 		sshConfig.User,
@@ -828,7 +1066,7 @@ func createSSHCmd(sshConfig *SSHConfig, cmdString, username, shell string, withT
 	}
 	logrus.Debugf("createSSHCmd() sshCmdString: %s\n", sshCmdString)
 
-	return sshCmdString, f, nil
+	return sshCmdString, f, knownHostsFile, nil
 
 }
 
@@ -847,15 +1085,38 @@ func (ssh *SSHConfig) command(cmdString string, withTty, withSudo bool) (*SSHCom
 	if err != nil {
 		return nil, fmt.Errorf("unable to create command : %s", err.Error())
 	}
-	sshCmdString, keyFile, err := createSSHCmd(sshConfig, cmdString, "", "", withTty, withSudo)
+	sshCmdString, keyFile, knownHostsFile, err := createSSHCmd(sshConfig, cmdString, "", "", withTty, withSudo)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create command : %s", err.Error())
 	}
 	cmd := exec.Command("bash", "-c", sshCmdString)
 	sshCommand := SSHCommand{
-		cmd:     cmd,
-		tunnels: tunnels,
-		keyFile: keyFile,
+		cmd:            cmd,
+		tunnels:        tunnels,
+		keyFile:        keyFile,
+		knownHostsFile: knownHostsFile,
+	}
+	return &sshCommand, nil
+}
+
+// CommandWithInput returns the cmd struct to execute cmdString remotely, its standard input being read from 'input'
+// Unlike cmdString, the content of 'input' appears neither on the command line nor in the logs, and may carry secrets
+func (ssh *SSHConfig) CommandWithInput(cmdString string, input io.Reader) (*SSHCommand, error) {
+	tunnels, sshConfig, err := ssh.CreateTunneling()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create command : %s", err.Error())
+	}
+	sshCmdString, keyFile, knownHostsFile, err := createSSHCmd(sshConfig, "", "", "", false, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create command : %s", err.Error())
+	}
+	cmd := exec.Command("bash", "-c", sshCmdString+" "+ShellQuote(cmdString))
+	cmd.Stdin = input
+	sshCommand := SSHCommand{
+		cmd:            cmd,
+		tunnels:        tunnels,
+		keyFile:        keyFile,
+		knownHostsFile: knownHostsFile,
 	}
 	return &sshCommand, nil
 }
@@ -956,6 +1217,124 @@ func (ssh *SSHConfig) WaitServerReady(phase string, timeout time.Duration) (out
 	return stdout, nil
 }
 
+// cloudInitDoneCommand exits with 0 once cloud-init has finished on the host (or if cloud-init is not used), with 1
+// while it is running; polling the marker file keeps each SSH command short, unlike 'cloud-init status --wait'
+const cloudInitDoneCommand = "if ! command -v cloud-init >/dev/null 2>&1 || [ -f /etc/cloud/cloud-init.disabled ]; then exit 0; fi; " +
+	"test -f /var/lib/cloud/instance/boot-finished"
+
+// WaitCloudInitDone waits that cloud-init has completely finished on the remote host, for 'timeout' duration;
+// SSH may be ready long before (and apt/dpkg locked by cloud-init meanwhile)
+// Returns immediately on hosts not using cloud-init
+func (ssh *SSHConfig) WaitCloudInitDone(timeout time.Duration) (err error) {
+	if ssh == nil {
+		return fail.InvalidInstanceError()
+	}
+	if ssh.Host == "" {
+		return fail.InvalidInstanceContentError("ssh.Host", "cannot be empty string")
+	}
+
+	defer debug.NewTracer(nil, fmt.Sprintf("(%s)", temporal.FormatDuration(timeout)), false).GoingIn().OnExitTrace()()
+
+	begins := time.Now()
+	retryErr := retry.WhileUnsuccessfulDelay5Seconds(
+		func() error {
+			cmd, err := ssh.Command(cloudInitDoneCommand)
+			if err != nil {
+				return err
+			}
+
+			retcode, stdout, stderr, err := cmd.RunWithTimeout(nil, outputs.COLLECT, timeout)
+			if err != nil {
+				return err
+			}
+			switch retcode {
+			case 0:
+				return nil
+			case 1:
+				return fmt.Errorf("cloud-init still running")
+			case 255:
+				return fmt.Errorf("remote SSH not ready: error code: 255; Output [%s]; Error [%s]", stdout, stderr)
+			default:
+				return fail.AbortedError(
+					"", fmt.Errorf("failed to check cloud-init: error code: %d; Output [%s]; Error [%s]", retcode, stdout, stderr),
+				)
+			}
+		},
+		timeout,
+	)
+	if retryErr != nil {
+		switch realErr := retryErr.(type) {
+		case retry.ErrAborted:
+			return realErr.Cause()
+		case retry.ErrTimeout:
+			return fail.TimeoutError(
+				fmt.Sprintf("timeout waiting cloud-init to finish on host '%s'", ssh.Host), timeout, realErr,
+			)
+		default:
+			return retryErr
+		}
+	}
+	logrus.Debugf("cloud-init done on host [%s], checked in [%s]", ssh.Host, temporal.FormatDuration(time.Since(begins)))
+	return nil
+}
+
+// ScanHostKey connects to the host without checking its key and returns the key presented by the SSH server, in the
+// format expected by HostKey; the gateways are checked with their own HostKey, if set
+func (ssh *SSHConfig) ScanHostKey(timeout time.Duration) (string, error) {
+	if ssh == nil {
+		return "", fail.InvalidInstanceError()
+	}
+
+	tunnels, sshConfig, err := ssh.CreateTunneling()
+	if err != nil {
+		return "", fmt.Errorf("unable to create tunnels : %s", err.Error())
+	}
+
+	prefix, idOptions, identityFile, err := identityOptions(sshConfig)
+	if err != nil {
+		_ = CloseTunnels(tunnels)
+		return "", err
+	}
+	// the known_hosts file is filled by ssh on connection, so it is not given to SSHCommand that removes it
+	knownHostsFile, err := CreateTempFileFromString("", 0600)
+	if err != nil {
+		_ = CloseTunnels(tunnels)
+		_ = removeTempFile(identityFile)
+		return "", fmt.Errorf("unable to create temporary known_hosts file: %s", err.Error())
+	}
+	defer func() {
+		if nerr := removeTempFile(knownHostsFile); nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
+	}()
+
+	options := fmt.Sprintf(
+		"%s %s -oLogLevel=error -oStrictHostKeyChecking=no -oUserKnownHostsFile=%s -oGlobalKnownHostsFile=/dev/null -oHostKeyAlias=%s -oHashKnownHosts=no",
+		sshOptions, sshConfig.sshArgs(), knownHostsFile.Name(), hostKeyAlias,
+	)
+	sshCmdString := fmt.Sprintf(
+		"%sssh %s %s -p %d %s@%s true", prefix, idOptions, options, sshConfig.Port, sshConfig.User, sshConfig.Host,
+	)
+	sshCommand := SSHCommand{
+		cmd:     exec.Command("bash", "-c", sshCmdString),
+		tunnels: tunnels,
+		keyFile: identityFile,
+	}
+	retcode, _, _, err := sshCommand.RunWithTimeout(nil, outputs.COLLECT, timeout)
+	if err != nil {
+		return "", err
+	}
+	if retcode != 0 {
+		return "", fmt.Errorf("unable to connect to host: %s", SSHErrorString(retcode))
+	}
+
+	content, err := ioutil.ReadFile(knownHostsFile.Name())
+	if err != nil {
+		return "", err
+	}
+	return parseHostKey(string(content))
+}
+
 // Copy copies a file/directory from/to local to/from remote
 func (ssh *SSHConfig) Copy(remotePath, localPath string, isUpload bool) (int, string, string, error) {
 	tunnels, sshConfig, err := ssh.CreateTunneling()
@@ -963,37 +1342,43 @@ func (ssh *SSHConfig) Copy(remotePath, localPath string, isUpload bool) (int, st
 		return 0, "", "", fmt.Errorf("unable to create tunnels : %s", err.Error())
 	}
 
-	identityfile, err := CreateTempFileFromString(sshConfig.PrivateKey, 0400)
+	prefix, idOptions, identityfile, err := identityOptions(sshConfig)
 	if err != nil {
-		return 0, "", "", fmt.Errorf("unable to create temporary key file: %s", err.Error())
+		return 0, "", "", err
 	}
 
-	cmdTemplate, err := template.New("Command").Parse(`scp -i {{.IdentityFile}} -P {{.Port}} {{.Options}} {{if .IsUpload}}"{{.LocalPath}}" {{.User}}@{{.Host}}:"{{.RemotePath}}"{{else}}{{.User}}@{{.Host}}:"{{.RemotePath}}" "{{.LocalPath}}"{{end}}`)
+	cmdTemplate, err := template.New("Command").Parse(`{{.Prefix}}scp {{.IdentityOptions}} -P {{.Port}} {{.Options}} {{if .IsUpload}}"{{.LocalPath}}" {{.User}}@{{.Host}}:"{{.RemotePath}}"{{else}}{{.User}}@{{.Host}}:"{{.RemotePath}}" "{{.LocalPath}}"{{end}}`)
 	if err != nil {
 		return 0, "", "", fmt.Errorf("error parsing command template: %s", err.Error())
 	}
 
-	options := sshOptions + " -oLogLevel=error"
+	hkOptions, knownHostsFile, err := hostKeyOptions(sshConfig)
+	if err != nil {
+		return 0, "", "", err
+	}
+	options := sshOptions + " " + hkOptions + " " + sshConfig.sshArgs() + " -oLogLevel=error"
 	var copyCommand bytes.Buffer
 	if err := cmdTemplate.Execute(
 		&copyCommand, struct {
-			IdentityFile string
-			Port         int
-			Options      string
-			User         string
-			Host         string
-			RemotePath   string
-			LocalPath    string
-			IsUpload     bool
+			Prefix          string
+			IdentityOptions string
+			Port            int
+			Options         string
+			User            string
+			Host            string
+			RemotePath      string
+			LocalPath       string
+			IsUpload        bool
 		}{
-			IdentityFile: identityfile.Name(),
-			Port:         sshConfig.Port,
-			Options:      options,
-			User:         sshConfig.User,
-			Host:         sshConfig.Host,
-			RemotePath:   remotePath,
-			LocalPath:    localPath,
-			IsUpload:     isUpload,
+			Prefix:          prefix,
+			IdentityOptions: idOptions,
+			Port:            sshConfig.Port,
+			Options:         options,
+			User:            sshConfig.User,
+			Host:            sshConfig.Host,
+			RemotePath:      remotePath,
+			LocalPath:       localPath,
+			IsUpload:        isUpload,
 		},
 	); err != nil {
 		return 0, "", "", fmt.Errorf("error executing template: %s", err.Error())
@@ -1002,9 +1387,10 @@ func (ssh *SSHConfig) Copy(remotePath, localPath string, isUpload bool) (int, st
 	sshCmdString := copyCommand.String()
 	cmd := exec.Command("bash", "-c", sshCmdString)
 	sshCommand := SSHCommand{
-		cmd:     cmd,
-		tunnels: tunnels,
-		keyFile: identityfile,
+		cmd:            cmd,
+		tunnels:        tunnels,
+		keyFile:        identityfile,
+		knownHostsFile: knownHostsFile,
 	}
 
 	return sshCommand.Run(nil, outputs.COLLECT) // FIXME: It CAN lock, use .RunWithTimeout instead
@@ -1022,7 +1408,7 @@ func (ssh *SSHConfig) Exec(cmdString string) error {
 		}
 		return fmt.Errorf("unable to create command : %s", err.Error())
 	}
-	sshCmdString, keyFile, err := createSSHCmd(sshConfig, cmdString, "", "", false, false)
+	sshCmdString, keyFile, knownHostsFile, err := createSSHCmd(sshConfig, cmdString, "", "", false, false)
 	if err != nil {
 		for _, t := range tunnels {
 			nerr := t.Close()
@@ -1036,6 +1422,10 @@ func (ssh *SSHConfig) Exec(cmdString string) error {
 				logrus.Warnf("Error removing file %v", nerr)
 			}
 		}
+		nerr := removeTempFile(knownHostsFile)
+		if nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
 		return fmt.Errorf("unable to create command : %s", err.Error())
 	}
 	bash, err := exec.LookPath("bash")
@@ -1052,6 +1442,10 @@ func (ssh *SSHConfig) Exec(cmdString string) error {
 				logrus.Warnf("Error removing file %v", nerr)
 			}
 		}
+		nerr := removeTempFile(knownHostsFile)
+		if nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
 		return fmt.Errorf("unable to create command : %s", err.Error())
 	}
 	var args []string
@@ -1061,7 +1455,11 @@ func (ssh *SSHConfig) Exec(cmdString string) error {
 		args = []string{"-c", sshCmdString}
 	}
 	err = syscall.Exec(bash, args, nil)
-	nerr := utils.LazyRemove(keyFile.Name())
+	nerr := removeTempFile(keyFile)
+	if nerr != nil {
+		logrus.Warnf("Error removing (lazy) file %v", nerr)
+	}
+	nerr = removeTempFile(knownHostsFile)
 	if nerr != nil {
 		logrus.Warnf("Error removing (lazy) file %v", nerr)
 	}
@@ -1081,7 +1479,7 @@ func (ssh *SSHConfig) Enter(username, shell string) error {
 		return fmt.Errorf("unable to create command : %s", err.Error())
 	}
 
-	sshCmdString, keyFile, err := createSSHCmd(sshConfig, "", username, shell, true, false)
+	sshCmdString, keyFile, knownHostsFile, err := createSSHCmd(sshConfig, "", username, shell, true, false)
 	if err != nil {
 		for _, t := range tunnels {
 			nerr := t.Close()
@@ -1095,6 +1493,10 @@ func (ssh *SSHConfig) Enter(username, shell string) error {
 				logrus.Warnf("Error removing file %v", nerr)
 			}
 		}
+		nerr := removeTempFile(knownHostsFile)
+		if nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
 		return fmt.Errorf("unable to create command : %s", err.Error())
 	}
 
@@ -1112,6 +1514,10 @@ func (ssh *SSHConfig) Enter(username, shell string) error {
 				logrus.Warnf("Error removing file %v", nerr)
 			}
 		}
+		nerr := removeTempFile(knownHostsFile)
+		if nerr != nil {
+			logrus.Warnf("Error removing file %v", nerr)
+		}
 		return fmt.Errorf("unable to create command : %s", err.Error())
 	}
 
@@ -1120,7 +1526,11 @@ func (ssh *SSHConfig) Enter(username, shell string) error {
 	proc.Stdout = os.Stdout
 	proc.Stderr = os.Stderr
 	err = proc.Run()
-	nerr := utils.LazyRemove(keyFile.Name())
+	nerr := removeTempFile(keyFile)
+	if nerr != nil {
+		logrus.Warnf("Error removing file %v", nerr)
+	}
+	nerr = removeTempFile(knownHostsFile)
 	if nerr != nil {
 		logrus.Warnf("Error removing file %v", nerr)
 	}
@@ -1137,16 +1547,17 @@ func (ssh *SSHConfig) CommandContext(ctx context.Context, cmdString string) (*SS
 	if err != nil {
 		return nil, fmt.Errorf("unable to create command : %s", err.Error())
 	}
-	sshCmdString, keyFile, err := createSSHCmd(sshConfig, cmdString, "", "", false, false)
+	sshCmdString, keyFile, knownHostsFile, err := createSSHCmd(sshConfig, cmdString, "", "", false, false)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create command : %s", err.Error())
 	}
 
 	cmd := exec.CommandContext(ctx, "bash", "-c", sshCmdString)
 	sshCommand := SSHCommand{
-		cmd:     cmd,
-		tunnels: tunnels,
-		keyFile: keyFile,
+		cmd:            cmd,
+		tunnels:        tunnels,
+		keyFile:        keyFile,
+		knownHostsFile: knownHostsFile,
 	}
 	return &sshCommand, nil
 }
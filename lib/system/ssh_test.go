@@ -75,3 +75,118 @@ func Test_Command(t *testing.T) {
 		assert.Equal(t, usr.Name, strings.Trim(string(out), "\n"))
 	}
 }
+
+func Test_CommandConnectionOptions(t *testing.T) {
+	sshConf := system.SSHConfig{
+		User:        "safescale",
+		Host:        "192.168.0.2",
+		Port:        22,
+		AgentSocket: "/tmp/agent.sock",
+	}
+	cmd, err := sshConf.Command("whoami")
+	assert.Nil(t, err)
+	assert.Contains(t, cmd.Display(), "-oServerAliveInterval=60 -oServerAliveCountMax=3")
+	assert.NotContains(t, cmd.Display(), "-oConnectTimeout")
+
+	sshConf.SSHConnectionOptions = system.SSHConnectionOptions{
+		ServerAliveInterval: 15,
+		ServerAliveCountMax: 8,
+		ConnectTimeout:      20,
+	}
+	assert.Nil(t, sshConf.Validate())
+	cmd, err = sshConf.Command("whoami")
+	assert.Nil(t, err)
+	assert.Contains(t, cmd.Display(), "-oServerAliveInterval=15 -oServerAliveCountMax=8 -oConnectTimeout=20")
+
+	assert.NotNil(t, system.SSHConnectionOptions{ServerAliveInterval: -1}.Validate())
+	assert.NotNil(t, system.SSHConnectionOptions{ServerAliveCountMax: 1000}.Validate())
+	assert.NotNil(t, system.SSHConnectionOptions{ConnectTimeout: 3600}.Validate())
+}
+
+func Test_CommandWithInput(t *testing.T) {
+	sshConf := system.SSHConfig{
+		User:        "safescale",
+		Host:        "192.168.0.2",
+		Port:        22,
+		AgentSocket: "/tmp/agent.sock",
+	}
+	cmd, err := sshConf.CommandWithInput("sudo chpasswd", strings.NewReader("safescale:Secr3t-Passw0rd\n"))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(cmd.Display(), "safescale@192.168.0.2 'sudo chpasswd'"), cmd.Display())
+	assert.NotContains(t, cmd.Display(), "Secr3t-Passw0rd")
+	assert.NotContains(t, cmd.Display(), "ENDSSH")
+}
+
+func Test_CreateTunnelingThreeHops(t *testing.T) {
+	usr, err := user.Current()
+	assert.Nil(t, err)
+	content, err := ioutil.ReadFile(fmt.Sprintf("%s/.ssh/id_rsa", usr.HomeDir))
+	if err != nil {
+		t.Skip()
+	}
+
+	hop := system.SSHConfig{
+		User:       usr.Name,
+		Host:       "127.0.0.1",
+		Port:       22,
+		PrivateKey: string(content),
+	}
+	jump := hop
+	bastion := hop
+	bastion.GatewayConfig = &jump
+	gateway := hop
+	gateway.GatewayConfig = &bastion
+	sshConf := hop
+	sshConf.GatewayConfig = &gateway
+
+	tunnels, cfg, err := sshConf.CreateTunneling()
+	if err != nil {
+		t.Skip()
+	}
+	assert.Equal(t, 3, len(tunnels))
+	assert.Equal(t, "127.0.0.1", cfg.Host)
+	assert.NotEqual(t, 22, cfg.Port)
+
+	// the chain of the caller must not be altered by tunnel building
+	assert.Equal(t, 22, sshConf.GatewayConfig.Port)
+	assert.Equal(t, 22, sshConf.GatewayConfig.GatewayConfig.Port)
+
+	err = system.CloseTunnels(tunnels)
+	assert.Nil(t, err)
+}
+
+func Test_PortForwardRejectsInvalidPorts(t *testing.T) {
+	sshConf := system.SSHConfig{User: "user", Host: "127.0.0.1", Port: 22}
+
+	_, _, err := sshConf.PortForward(-1, "localhost", 5432)
+	assert.NotNil(t, err)
+	_, _, err = sshConf.PortForward(0, "", 5432)
+	assert.NotNil(t, err)
+	_, _, err = sshConf.PortForward(0, "localhost", 0)
+	assert.NotNil(t, err)
+	_, _, err = sshConf.PortForward(0, "localhost", 70000)
+	assert.NotNil(t, err)
+}
+
+func Test_PortForwardThroughGateway(t *testing.T) {
+	usr, err := user.Current()
+	assert.Nil(t, err)
+	content, err := ioutil.ReadFile(fmt.Sprintf("%s/.ssh/id_rsa", usr.HomeDir))
+	if err != nil {
+		t.Skip()
+	}
+
+	gateway := system.SSHConfig{User: usr.Name, Host: "127.0.0.1", Port: 22, PrivateKey: string(content)}
+	sshConf := gateway
+	sshConf.GatewayConfig = &gateway
+
+	port, tunnels, err := sshConf.PortForward(0, "localhost", 22)
+	if err != nil {
+		t.Skip()
+	}
+	assert.NotEqual(t, 0, port)
+	assert.Equal(t, 2, len(tunnels))
+
+	err = system.CloseTunnels(tunnels)
+	assert.Nil(t, err)
+}
@@ -19,6 +19,7 @@ package system
 import (
 	"fmt"
 	"os/exec"
+	"strings"
 	"sync/atomic"
 	"syscall"
 
@@ -67,3 +68,8 @@ func ExtractRetCode(err error) (string, int, error) {
 	}
 	return msg, retCode, fmt.Errorf("error is not an 'ExitError'")
 }
+
+// ShellQuote protects 'in' with single quotes to be used as a single word in a shell command
+func ShellQuote(in string) string {
+	return "'" + strings.Replace(in, "'", `'"'"'`, -1) + "'"
+}
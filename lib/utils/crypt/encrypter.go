@@ -0,0 +1,139 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypt
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// Encrypter encrypts content before it is stored and decrypts it when read back
+type Encrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+	// Enabled tells if the Encrypter really transforms content
+	Enabled() bool
+}
+
+// NoopEncrypter is an Encrypter leaving content untouched
+type NoopEncrypter struct{}
+
+// Encrypt returns plaintext as is
+func (NoopEncrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	return plaintext, nil
+}
+
+// Decrypt returns ciphertext as is
+func (NoopEncrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	return ciphertext, nil
+}
+
+// Enabled returns false
+func (NoopEncrypter) Enabled() bool {
+	return false
+}
+
+// keyIDHeader prefixes content encrypted by AESEncrypter; it is followed by the length of the key id on 1 byte,
+// then by the key id itself
+var keyIDHeader = []byte("SSKID")
+
+// AESEncrypter is an Encrypter using 256-bit AES-GCM
+// The id of the key used to encrypt is stored alongside the ciphertext, allowing to decrypt content encrypted
+// with a previous key after a key rotation.
+type AESEncrypter struct {
+	keyID string
+	keys  map[string]*Key
+}
+
+// NewAESEncrypter returns an AESEncrypter encrypting with 'key', identified by 'keyID'
+func NewAESEncrypter(keyID string, key *Key) (*AESEncrypter, error) {
+	if key == nil {
+		return nil, fail.InvalidParameterError("key", "cannot be nil")
+	}
+	if len(keyID) > 255 {
+		return nil, fail.InvalidParameterError("keyID", "cannot be longer than 255 characters")
+	}
+	return &AESEncrypter{
+		keyID: keyID,
+		keys:  map[string]*Key{keyID: key},
+	}, nil
+}
+
+// AddDecryptionKey registers a previous key, used only to decrypt content encrypted with it
+func (e *AESEncrypter) AddDecryptionKey(keyID string, key *Key) error {
+	if e == nil {
+		return fail.InvalidInstanceError()
+	}
+	if key == nil {
+		return fail.InvalidParameterError("key", "cannot be nil")
+	}
+	if keyID == e.keyID {
+		return fail.InvalidParameterError("keyID", "cannot be the id of the current key")
+	}
+	e.keys[keyID] = key
+	return nil
+}
+
+// Encrypt encrypts plaintext with the current key
+func (e *AESEncrypter) Encrypt(plaintext []byte) ([]byte, error) {
+	if e == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+
+	ciphertext, err := Encrypt(plaintext, e.keys[e.keyID])
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(keyIDHeader)+1+len(e.keyID)+len(ciphertext))
+	out = append(out, keyIDHeader...)
+	out = append(out, byte(len(e.keyID)))
+	out = append(out, e.keyID...)
+	return append(out, ciphertext...), nil
+}
+
+// Decrypt decrypts ciphertext with the key it has been encrypted with
+// Content without key id (encrypted before the key id was stored) is decrypted with the current key.
+func (e *AESEncrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	if e == nil {
+		return nil, fail.InvalidInstanceError()
+	}
+
+	keyID := e.keyID
+	if bytes.HasPrefix(ciphertext, keyIDHeader) && len(ciphertext) > len(keyIDHeader) {
+		idLen := int(ciphertext[len(keyIDHeader)])
+		start := len(keyIDHeader) + 1
+		if len(ciphertext) < start+idLen {
+			return nil, fmt.Errorf("malformed ciphertext")
+		}
+		keyID = string(ciphertext[start : start+idLen])
+		ciphertext = ciphertext[start+idLen:]
+	}
+
+	key, ok := e.keys[keyID]
+	if !ok {
+		return nil, fail.NotFoundError(fmt.Sprintf("failed to find encryption key with id '%s'", keyID))
+	}
+	return Decrypt(ciphertext, key)
+}
+
+// Enabled returns true
+func (e *AESEncrypter) Enabled() bool {
+	return true
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const metadataContent = `{"id":"2ea4d5b4-0d7e-4bd7-bfd5-1b0d4a5a2d3e","name":"host1","properties":{}}`
+
+func TestAESEncrypter_RoundTrip(t *testing.T) {
+	key, err := NewEncryptionKey([]byte("my tenant key"))
+	require.Nil(t, err)
+	e, err := NewAESEncrypter("2020-01", key)
+	require.Nil(t, err)
+
+	ciphertext, err := e.Encrypt([]byte(metadataContent))
+	require.Nil(t, err)
+	assert.NotContains(t, string(ciphertext), "host1")
+
+	plaintext, err := e.Decrypt(ciphertext)
+	require.Nil(t, err)
+	assert.Equal(t, metadataContent, string(plaintext))
+}
+
+func TestAESEncrypter_WrongKey(t *testing.T) {
+	key, err := NewEncryptionKey([]byte("my tenant key"))
+	require.Nil(t, err)
+	e, err := NewAESEncrypter("2020-01", key)
+	require.Nil(t, err)
+	ciphertext, err := e.Encrypt([]byte(metadataContent))
+	require.Nil(t, err)
+
+	wrongKey, err := NewEncryptionKey([]byte("not my tenant key"))
+	require.Nil(t, err)
+	other, err := NewAESEncrypter("2020-01", wrongKey)
+	require.Nil(t, err)
+	_, err = other.Decrypt(ciphertext)
+	assert.NotNil(t, err)
+
+	// Same key but unknown key id
+	unknown, err := NewAESEncrypter("2020-02", key)
+	require.Nil(t, err)
+	_, err = unknown.Decrypt(ciphertext)
+	assert.NotNil(t, err)
+}
+
+func TestAESEncrypter_KeyRotation(t *testing.T) {
+	oldKey, err := NewEncryptionKey([]byte("old key"))
+	require.Nil(t, err)
+	newKey, err := NewEncryptionKey([]byte("new key"))
+	require.Nil(t, err)
+
+	before, err := NewAESEncrypter("old", oldKey)
+	require.Nil(t, err)
+	oldCiphertext, err := before.Encrypt([]byte(metadataContent))
+	require.Nil(t, err)
+
+	after, err := NewAESEncrypter("new", newKey)
+	require.Nil(t, err)
+	require.Nil(t, after.AddDecryptionKey("old", oldKey))
+
+	plaintext, err := after.Decrypt(oldCiphertext)
+	require.Nil(t, err)
+	assert.Equal(t, metadataContent, string(plaintext))
+
+	newCiphertext, err := after.Encrypt(plaintext)
+	require.Nil(t, err)
+	_, err = before.Decrypt(newCiphertext)
+	assert.NotNil(t, err)
+}
+
+func TestAESEncrypter_DecryptWithoutKeyID(t *testing.T) {
+	key, err := NewEncryptionKey([]byte("my tenant key"))
+	require.Nil(t, err)
+	legacy, err := Encrypt([]byte(metadataContent), key)
+	require.Nil(t, err)
+
+	e, err := NewAESEncrypter("", key)
+	require.Nil(t, err)
+	plaintext, err := e.Decrypt(legacy)
+	require.Nil(t, err)
+	assert.Equal(t, metadataContent, string(plaintext))
+}
+
+func TestNoopEncrypter(t *testing.T) {
+	var e Encrypter = NoopEncrypter{}
+	assert.False(t, e.Enabled())
+
+	out, err := e.Encrypt([]byte(metadataContent))
+	require.Nil(t, err)
+	assert.Equal(t, metadataContent, string(out))
+
+	out, err = e.Decrypt(out)
+	require.Nil(t, err)
+	assert.Equal(t, metadataContent, string(out))
+}
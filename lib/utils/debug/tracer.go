@@ -18,22 +18,28 @@
 package debug
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/CS-SI/SafeScale/lib/utils/concurrency"
+	"github.com/CS-SI/SafeScale/lib/utils/debug/tracing"
 	"github.com/CS-SI/SafeScale/lib/utils/temporal"
 )
 
 // Tracer ...
 type Tracer interface {
 	WithStopwatch() Tracer
+	WithContext(ctx context.Context) Tracer
+	WithSpanAttribute(key, value string) Tracer
+	Context() context.Context
 	GoingInMessage() string
 	GoingIn() Tracer
 	GoingOutMessage() string
@@ -55,6 +61,9 @@ type tracer struct {
 	inDone       bool
 	outDone      bool
 	sw           temporal.Stopwatch
+	ctx          context.Context
+	attrs        map[string]string
+	span         *tracing.Span
 }
 
 const (
@@ -105,6 +114,35 @@ func (t *tracer) WithStopwatch() *tracer {
 	return t
 }
 
+// WithContext sets the context carrying the parent of the span emitted when spans are exported (see package tracing)
+func (t *tracer) WithContext(ctx context.Context) *tracer {
+	if !t.IsNull() {
+		t.ctx = ctx
+	}
+	return t
+}
+
+// WithSpanAttribute adds an attribute to the span emitted when spans are exported (see package tracing)
+func (t *tracer) WithSpanAttribute(key, value string) *tracer {
+	if !t.IsNull() {
+		if t.attrs == nil {
+			t.attrs = map[string]string{}
+		}
+		t.attrs[key] = value
+		t.span.SetAttribute(key, value)
+	}
+	return t
+}
+
+// Context returns the context given to WithContext; once the tracer is going in, it carries the span emitted (if spans
+// are exported), to be passed to the callees for their spans to be its children
+func (t *tracer) Context() context.Context {
+	if t.IsNull() || t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
 // GoingInMessage returns the content of the message when entering the function
 func (t *tracer) GoingInMessage() string {
 	if t.IsNull() {
@@ -119,6 +157,9 @@ func (t *tracer) GoingIn() *tracer {
 		if t.sw != nil {
 			t.sw.Start()
 		}
+		if t.span == nil && tracing.Enabled() {
+			t.ctx, t.span = tracing.Start(t.ctx, t.funcName, t.spanAttributes())
+		}
 		if t.enabled {
 			t.inDone = true
 			msg := t.GoingInMessage()
@@ -144,6 +185,14 @@ func (t *tracer) GoingOut() *tracer {
 		if t.sw != nil {
 			t.sw.Stop()
 		}
+		if t.span != nil {
+			var duration time.Duration
+			if t.sw != nil {
+				duration = t.sw.Duration()
+			}
+			t.span.End(duration)
+			t.span = nil
+		}
 		if t.enabled {
 			t.outDone = true
 			msg := t.GoingOutMessage()
@@ -158,6 +207,22 @@ func (t *tracer) GoingOut() *tracer {
 	return t
 }
 
+// spanAttributes returns the attributes of the span mirroring the tracer
+func (t *tracer) spanAttributes() map[string]string {
+	attrs := map[string]string{
+		"code.function": t.funcName,
+		"code.filepath": t.fileName,
+		"params":        t.callerParams,
+	}
+	if t.taskSig != "" {
+		attrs["task"] = t.taskSig
+	}
+	for k, v := range t.attrs {
+		attrs[k] = v
+	}
+	return attrs
+}
+
 // OnExitTrace returns a function that will log the output message using TRACE level.
 func (t *tracer) OnExitTrace() func() {
 	if t.IsNull() || t.outDone {
@@ -186,10 +251,15 @@ func (t *tracer) TraceMessage(format string, a ...interface{}) string {
 
 // Trace traces a message
 func (t *tracer) Trace(format string, a ...interface{}) *tracer {
-	if !t.IsNull() && t.enabled {
-		msg := t.TraceMessage(format, a...)
-		if msg != "" {
-			logrus.Tracef(msg)
+	if !t.IsNull() {
+		if t.span != nil {
+			t.span.AddEvent(fmt.Sprintf(format, a...))
+		}
+		if t.enabled {
+			msg := t.TraceMessage(format, a...)
+			if msg != "" {
+				logrus.Tracef(msg)
+			}
 		}
 	}
 	return t
@@ -197,10 +267,15 @@ func (t *tracer) Trace(format string, a ...interface{}) *tracer {
 
 // TraceAsError traces a message with error level
 func (t *tracer) TraceAsError(format string, a ...interface{}) *tracer {
-	if !t.IsNull() && t.enabled {
-		msg := t.TraceMessage(format, a...)
-		if msg != "" {
-			logrus.Errorf(msg)
+	if !t.IsNull() {
+		if t.span != nil {
+			t.span.SetError(fmt.Sprintf(format, a...))
+		}
+		if t.enabled {
+			msg := t.TraceMessage(format, a...)
+			if msg != "" {
+				logrus.Errorf(msg)
+			}
 		}
 	}
 	return t
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package debug
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/utils/debug/tracing"
+)
+
+type parentKey struct{}
+
+// recordedSpan is a span recorded by recorder
+type recordedSpan struct {
+	name     string
+	parent   string
+	attrs    map[string]string
+	events   []string
+	failed   bool
+	duration time.Duration
+	ended    bool
+}
+
+func (s *recordedSpan) SetAttribute(key, value string) { s.attrs[key] = value }
+func (s *recordedSpan) AddEvent(msg string)            { s.events = append(s.events, msg) }
+func (s *recordedSpan) SetError(msg string)            { s.failed = true }
+func (s *recordedSpan) End(duration time.Duration)     { s.duration, s.ended = duration, true }
+
+// recorder is a tracing.Exporter recording the spans started
+type recorder struct {
+	lock  sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, tracing.ExportedSpan) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	parent, _ := ctx.Value(parentKey{}).(string)
+	span := &recordedSpan{name: name, parent: parent, attrs: map[string]string{}}
+	for k, v := range attrs {
+		span.attrs[k] = v
+	}
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, parentKey{}, name), span
+}
+
+func TestTracerWithoutExporterEmitsNoSpan(t *testing.T) {
+	tracing.SetExporter(nil)
+	ctx := context.WithValue(context.Background(), parentKey{}, "caller")
+	tracer := NewTracer(nil, "('host')", true).WithStopwatch().WithContext(ctx).
+		WithSpanAttribute("host.name", "host").GoingIn()
+	assert.Nil(t, tracer.span)
+	assert.Equal(t, ctx, tracer.Context())
+	tracer.Trace("step").GoingOut()
+}
+
+func TestTracerEmitsSpan(t *testing.T) {
+	rec := &recorder{}
+	tracing.SetExporter(rec)
+	defer tracing.SetExporter(nil)
+
+	tracer := NewTracer(nil, "('host')", false).WithStopwatch().WithSpanAttribute("provider", "ovh").GoingIn()
+	tracer.WithSpanAttribute("phase", "phase2").Trace("step %d", 1)
+	tracer.GoingOut()
+	tracer.GoingOut()
+
+	if assert.Equal(t, 1, len(rec.spans)) {
+		span := rec.spans[0]
+		assert.Contains(t, span.name, "TestTracerEmitsSpan")
+		assert.Equal(t, "ovh", span.attrs["provider"])
+		assert.Equal(t, "phase2", span.attrs["phase"])
+		assert.Equal(t, "('host')", span.attrs["params"])
+		assert.True(t, span.ended)
+		assert.NotZero(t, span.duration)
+		assert.Equal(t, []string{"step 1"}, span.events)
+		assert.False(t, span.failed)
+	}
+
+	NewTracer(nil, "()", false).GoingIn().TraceAsError("failed").GoingOut()
+	if assert.Equal(t, 2, len(rec.spans)) {
+		assert.True(t, rec.spans[1].failed)
+	}
+}
+
+func TestTracerContextCarriesSpan(t *testing.T) {
+	rec := &recorder{}
+	tracing.SetExporter(rec)
+	defer tracing.SetExporter(nil)
+
+	parent := NewTracer(nil, "()", false).WithContext(context.Background()).GoingIn()
+	child := NewTracer(nil, "()", false).WithContext(parent.Context()).GoingIn()
+	child.GoingOut()
+	parent.GoingOut()
+
+	if assert.Equal(t, 2, len(rec.spans)) {
+		assert.Equal(t, "", rec.spans[0].parent)
+		assert.Equal(t, rec.spans[0].name, rec.spans[1].parent)
+	}
+}
@@ -0,0 +1,119 @@
+//go:build otel
+// +build otel
+
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/CS-SI/SafeScale"
+
+// otelExporter exports the spans to an OpenTelemetry tracer
+type otelExporter struct {
+	tracer trace.Tracer
+}
+
+// Start ...
+func (e otelExporter) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, ExportedSpan) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	ctx, span := e.tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+	return ctx, otelSpan{span: span}
+}
+
+// otelSpan is an OpenTelemetry span
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+func (s otelSpan) AddEvent(msg string) {
+	s.span.AddEvent(msg)
+}
+
+func (s otelSpan) SetError(msg string) {
+	s.span.SetStatus(codes.Error, msg)
+}
+
+// End records 'duration', if not zero, in the attribute "duration"
+func (s otelSpan) End(duration time.Duration) {
+	if duration > 0 {
+		s.span.SetAttributes(attribute.String("duration", duration.String()))
+	}
+	s.span.End()
+}
+
+// SetTracerProvider sets the OpenTelemetry provider receiving the spans of the debug tracers; nil stops the export
+func SetTracerProvider(tp trace.TracerProvider) {
+	if tp == nil {
+		SetExporter(nil)
+		return
+	}
+	SetExporter(otelExporter{tracer: tp.Tracer(instrumentationName)})
+}
+
+// Configure exports the spans of the service 'serviceName' with OTLP over HTTP to the collector whose endpoint
+// (host:port) is in the environment variable SAFESCALE_TRACING_ENDPOINT; nothing is exported if it is not set
+// The provider is also registered as the OpenTelemetry global provider
+// Returns the function flushing the spans not sent yet, to call before exiting
+func Configure(serviceName string) (shutdown func(), err error) {
+	endpoint := os.Getenv(EndpointEnvVar)
+	if endpoint == "" {
+		return func() {}, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if os.Getenv(InsecureEnvVar) != "" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exp, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace exporter to '%s': %s", endpoint, err.Error())
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	SetTracerProvider(tp)
+
+	return func() {
+		SetExporter(nil)
+		_ = tp.Shutdown(context.Background())
+	}, nil
+}
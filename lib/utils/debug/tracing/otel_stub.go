@@ -0,0 +1,37 @@
+//go:build !otel
+// +build !otel
+
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"fmt"
+	"os"
+)
+
+// Configure fails if the environment variable SAFESCALE_TRACING_ENDPOINT asks to export the spans, the OpenTelemetry
+// exporter being available only when compiled with the tag 'otel'
+func Configure(serviceName string) (shutdown func(), err error) {
+	if os.Getenv(EndpointEnvVar) != "" {
+		return nil, fmt.Errorf(
+			"%s is set but the spans cannot be exported, compile with the tag 'otel' (go build -tags otel)", EndpointEnvVar,
+		)
+	}
+	return func() {}, nil
+}
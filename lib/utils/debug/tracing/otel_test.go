@@ -0,0 +1,69 @@
+//go:build otel
+// +build otel
+
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key string) string {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestOpenTelemetryExport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer SetTracerProvider(nil)
+
+	ctx, parent := Start(context.Background(), "parent", map[string]string{"provider": "ovh"})
+	_, child := Start(ctx, "child", nil)
+	child.AddEvent("step 1")
+	child.SetError("failed")
+	child.End(0)
+	parent.SetAttribute("phase", "ready")
+	parent.End(time.Second)
+
+	spans := recorder.Ended()
+	if assert.Equal(t, 2, len(spans)) {
+		assert.Equal(t, "child", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		if assert.Equal(t, 1, len(spans[0].Events())) {
+			assert.Equal(t, "step 1", spans[0].Events()[0].Name)
+		}
+		assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Equal(t, "parent", spans[1].Name())
+		assert.Equal(t, "ovh", spanAttribute(spans[1], "provider"))
+		assert.Equal(t, "ready", spanAttribute(spans[1], "phase"))
+		assert.Equal(t, "1s", spanAttribute(spans[1], "duration"))
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+// Package tracing bridges the debug tracers to a span exporter: once an exporter is set, each tracer going in then out
+// emits a span
+// The OpenTelemetry exporter is available when compiled with the tag 'otel' (go build -tags otel)
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Exporter starts the spans of the debug tracers
+type Exporter interface {
+	// Start starts a span named 'name', child of the span carried by ctx if any, and returns the context carrying it
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, ExportedSpan)
+}
+
+// ExportedSpan is a span started by an Exporter
+type ExportedSpan interface {
+	SetAttribute(key, value string)
+	AddEvent(msg string)
+	SetError(msg string)
+	End(duration time.Duration)
+}
+
+const (
+	// EndpointEnvVar is the environment variable containing the endpoint (host:port) of the collector receiving the
+	// spans
+	EndpointEnvVar = "SAFESCALE_TRACING_ENDPOINT"
+	// InsecureEnvVar is the environment variable disabling TLS with the collector when set
+	InsecureEnvVar = "SAFESCALE_TRACING_INSECURE"
+)
+
+var (
+	lock     sync.RWMutex
+	exporter Exporter
+)
+
+// SetExporter sets the exporter receiving the spans of the debug tracers; nil stops the export
+func SetExporter(e Exporter) {
+	lock.Lock()
+	defer lock.Unlock()
+	exporter = e
+}
+
+// Enabled tells if the spans are exported
+func Enabled() bool {
+	lock.RLock()
+	defer lock.RUnlock()
+	return exporter != nil
+}
+
+// Span is an exported span; the methods of a nil *Span do nothing
+type Span struct {
+	span ExportedSpan
+}
+
+// Start starts a span named 'name', child of the span carried by ctx if any
+// Returns the context carrying the new span, to be passed to the callees, and the span; if the spans are not
+// exported, returns ctx unchanged and a nil span
+func Start(ctx context.Context, name string, attrs map[string]string) (context.Context, *Span) {
+	lock.RLock()
+	e := exporter
+	lock.RUnlock()
+	if e == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, span := e.Start(ctx, name, attrs)
+	return ctx, &Span{span: span}
+}
+
+// SetAttribute sets the attribute 'key' of the span
+func (s *Span) SetAttribute(key, value string) {
+	if s != nil {
+		s.span.SetAttribute(key, value)
+	}
+}
+
+// AddEvent records the message 'msg' in the span
+func (s *Span) AddEvent(msg string) {
+	if s != nil {
+		s.span.AddEvent(msg)
+	}
+}
+
+// SetError marks the span as failed with the message 'msg'
+func (s *Span) SetError(msg string) {
+	if s != nil {
+		s.span.SetError(msg)
+	}
+}
+
+// End ends the span; 'duration', if not zero, is the measure of the stopwatch of the tracer
+func (s *Span) End(duration time.Duration) {
+	if s != nil {
+		s.span.End(duration)
+	}
+}
@@ -21,11 +21,11 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	grpcstatus "google.golang.org/grpc/status"
@@ -442,6 +442,23 @@ func ForbiddenError(msg string) ErrForbidden {
 	}
 }
 
+// IsAuthenticationError tells if err, or one of its causes, is an ErrUnauthorized or an ErrForbidden
+// Such errors are not expected to disappear by retrying
+func IsAuthenticationError(err error) bool {
+	for err != nil {
+		switch err.(type) {
+		case ErrUnauthorized, *ErrUnauthorized, ErrForbidden, *ErrForbidden:
+			return true
+		}
+		cause, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = cause.Cause()
+	}
+	return false
+}
+
 // ErrAborted ...
 type ErrAborted struct {
 	ErrCore
@@ -545,10 +562,33 @@ func NotImplementedError(what string) ErrNotImplemented {
 	}
 }
 
+// ErrAlteredNothing is returned when an action had nothing to change (the resource was already in the wanted state)
+type ErrAlteredNothing struct {
+	ErrCore
+}
+
+// AddConsequence adds an error 'err' to the list of consequences
+func (e ErrAlteredNothing) AddConsequence(err error) error {
+	e.ErrCore = e.ErrCore.Reset(e.ErrCore.AddConsequence(err))
+	return e
+}
+
+// AlteredNothingError creates a ErrAlteredNothing error
+func AlteredNothingError(msg string) ErrAlteredNothing {
+	return ErrAlteredNothing{
+		ErrCore: ErrCore{
+			message:      msg,
+			cause:        nil,
+			consequences: []error{},
+		},
+	}
+}
+
 // ErrList ...
 type ErrList struct {
 	ErrCore
 	errors []error
+	labels []string
 }
 
 // ErrListError creates a ErrList
@@ -563,8 +603,62 @@ func ErrListError(errors []error) error {
 	}
 }
 
+// ErrListErrorWithContext creates a ErrList where each error is labeled with the resource it belongs to
+// (like "security group web"), so the message tells what failed; nil errors are ignored
+func ErrListErrorWithContext(errors map[string]error) error {
+	labels := make([]string, 0, len(errors))
+	for label := range errors {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	labeled := make([]LabeledError, 0, len(labels))
+	for _, label := range labels {
+		labeled = append(labeled, LabeledError{Label: label, Err: errors[label]})
+	}
+	return ErrListErrorWithLabels(labeled)
+}
+
+// LabeledError is an error labeled with the resource it belongs to
+type LabeledError struct {
+	Label string
+	Err   error
+}
+
+// ErrListErrorWithLabels creates a ErrList from labeled errors, in their order; unlike ErrListErrorWithContext,
+// several errors can share the same label. Nil errors are ignored
+func ErrListErrorWithLabels(errors []LabeledError) error {
+	list := ErrList{ErrCore: ErrCore{}}
+	for _, e := range errors {
+		if e.Err != nil {
+			list.errors = append(list.errors, e.Err)
+			list.labels = append(list.labels, e.Label)
+		}
+	}
+	if len(list.errors) == 0 {
+		return nil
+	}
+	return list
+}
+
+// Errors returns the errors of the list
+func (e ErrList) Errors() []error {
+	return e.errors
+}
+
 func (e ErrList) Error() string {
-	return spew.Sdump(e.errors)
+	msgs := make([]string, 0, len(e.errors))
+	for i, err := range e.errors {
+		msg := "<nil>"
+		if err != nil {
+			msg = err.Error()
+		}
+		if i < len(e.labels) {
+			msg = e.labels[i] + ": " + msg
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "; ") + e.CauseFormatter()
 }
 
 // AddConsequence adds an error 'err' to the list of consequences
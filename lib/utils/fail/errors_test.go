@@ -147,3 +147,53 @@ func TestRecognizeErrCore(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestIsAuthenticationError(t *testing.T) {
+	require.True(t, IsAuthenticationError(UnauthorizedError("token expired")))
+	require.True(t, IsAuthenticationError(ForbiddenError("not allowed")))
+	require.True(t, IsAuthenticationError(AbortedError("", UnauthorizedError("token expired"))))
+	require.True(t, IsAuthenticationError(TimeoutError("too long", 0, ForbiddenError("not allowed"))))
+	require.False(t, IsAuthenticationError(TimeoutError("too long", 0, nil)))
+	require.False(t, IsAuthenticationError(fmt.Errorf("network issue")))
+	require.False(t, IsAuthenticationError(nil))
+}
+
+func TestErrListErrorWithContext(t *testing.T) {
+	require.Nil(t, ErrListErrorWithContext(nil))
+	require.Nil(t, ErrListErrorWithContext(map[string]error{"security group web": nil}))
+
+	err := ErrListErrorWithContext(
+		map[string]error{
+			"security group web": fmt.Errorf("permission denied"),
+			"security group ssh": NotFoundError("not found"),
+			"security group db":  nil,
+		},
+	)
+	require.NotNil(t, err)
+	require.Equal(t, "security group ssh: not found; security group web: permission denied", err.Error())
+	require.Len(t, err.(ErrList).Errors(), 2)
+
+	err = AddConsequence(err, fmt.Errorf("host not deleted"))
+	require.Len(t, Consequences(err), 1)
+	require.True(t, strings.HasPrefix(err.Error(), "security group ssh: not found; security group web: permission denied"))
+	require.True(t, strings.Contains(err.Error(), "host not deleted"))
+
+	err = ErrListError([]error{fmt.Errorf("first"), fmt.Errorf("second")})
+	require.Equal(t, "first; second", err.Error())
+}
+
+func TestErrListErrorWithLabels(t *testing.T) {
+	require.Nil(t, ErrListErrorWithLabels(nil))
+	require.Nil(t, ErrListErrorWithLabels([]LabeledError{{Label: "host srv", Err: nil}}))
+
+	err := ErrListErrorWithLabels(
+		[]LabeledError{
+			{Label: "host srv", Err: fmt.Errorf("share data not updated")},
+			{Label: "share web", Err: nil},
+			{Label: "host srv", Err: fmt.Errorf("share logs not updated")},
+		},
+	)
+	require.NotNil(t, err)
+	require.Equal(t, "host srv: share data not updated; host srv: share logs not updated", err.Error())
+	require.Len(t, err.(ErrList).Errors(), 2)
+}
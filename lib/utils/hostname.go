@@ -0,0 +1,50 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// hostnameLabel matches a label of hostname as defined by RFC 1123
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// ValidateHostname checks that name is a valid hostname (short or fully qualified) according to RFC 1123
+func ValidateHostname(name string) error {
+	if name == "" {
+		return fail.InvalidParameterError("name", "cannot be empty string")
+	}
+	if len(name) > 253 {
+		return fail.InvalidParameterError("name", "cannot be longer than 253 characters")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return fail.InvalidParameterError(
+				"name", "'"+name+"' is not a valid hostname (RFC 1123: labels of 1 to 63 letters, digits or hyphens, not starting or ending with a hyphen)",
+			)
+		}
+	}
+	return nil
+}
+
+// ShortHostname returns the first label of hostname
+func ShortHostname(name string) string {
+	return strings.SplitN(name, ".", 2)[0]
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateHostname(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{"short", "node-1", false},
+		{"fqdn", "node-1.cluster.example.com", false},
+		{"digits only", "1234", false},
+		{"empty", "", true},
+		{"leading hyphen", "-node", true},
+		{"trailing hyphen", "node-", true},
+		{"underscore", "node_1", true},
+		{"empty label", "node..example.com", true},
+		{"label too long", strings.Repeat("a", 64), true},
+		{"too long", strings.Repeat("a.", 127), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHostname(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHostname(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShortHostname(t *testing.T) {
+	if got := ShortHostname("node-1.cluster.example.com"); got != "node-1" {
+		t.Errorf("unexpected short hostname: %s", got)
+	}
+	if got := ShortHostname("node-1"); got != "node-1" {
+		t.Errorf("unexpected short hostname: %s", got)
+	}
+}
@@ -17,8 +17,10 @@
 package utils
 
 import (
+	"encoding/binary"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -89,6 +91,62 @@ func LongToIPv4(value uint32) string {
 	return fmt.Sprintf("%d.%d.%d.%d", value>>24, (value&0x00FFFFFF)>>16, (value&0x0000FFFF)>>8, value&0x000000FF)
 }
 
+// AllocateCIDR returns the first CIDR of size 'prefixLen' inside 'parent' that does not overlap any of the CIDRs in
+// 'used'; used CIDRs are not required to be aligned nor sorted, the ones outside 'parent' are ignored
+// Returns fail.ErrOverflow if 'parent' has no room left for such a CIDR
+func AllocateCIDR(parent string, used []string, prefixLen int) (string, error) {
+	_, parentNet, err := net.ParseCIDR(parent)
+	if err != nil || parentNet.IP.To4() == nil {
+		return "", fail.InvalidParameterError("parent", "must be a valid IPv4 CIDR")
+	}
+	parentBits, _ := parentNet.Mask.Size()
+	if prefixLen < parentBits || prefixLen > 32 {
+		return "", fail.InvalidParameterError(
+			"prefixLen", fmt.Sprintf("must be between %d and 32 to fit in '%s'", parentBits, parent),
+		)
+	}
+
+	type interval struct{ start, end uint64 }
+	start, end := cidrToInterval(parentNet)
+	var intervals []interval
+	for _, u := range used {
+		_, usedNet, err := net.ParseCIDR(u)
+		if err != nil || usedNet.IP.To4() == nil {
+			return "", fail.InvalidParameterError("used", fmt.Sprintf("'%s' is not a valid IPv4 CIDR", u))
+		}
+		first, last := cidrToInterval(usedNet)
+		if last < start || first > end {
+			continue
+		}
+		intervals = append(intervals, interval{first, last})
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start < intervals[j].start })
+
+	size := uint64(1) << uint(32-prefixLen)
+	candidate := start
+	for _, i := range intervals {
+		if candidate+size-1 < i.start {
+			break
+		}
+		if i.end >= candidate {
+			// moves to the first aligned address after the allocated interval
+			candidate = (i.end/size + 1) * size
+		}
+	}
+	if candidate+size-1 > end {
+		return "", fail.OverflowError(fmt.Sprintf("no free CIDR of size /%d left in '%s'", prefixLen, parent), 0, nil)
+	}
+	return fmt.Sprintf("%s/%d", LongToIPv4(uint32(candidate)), prefixLen), nil
+}
+
+// cidrToInterval returns the first and last addresses of an IPv4 network, as uint64 to allow computations past the
+// last address without overflow
+func cidrToInterval(n *net.IPNet) (uint64, uint64) {
+	first := uint64(binary.BigEndian.Uint32(n.IP.To4()))
+	ones, _ := n.Mask.Size()
+	return first, first + (uint64(1) << uint(32-ones)) - 1
+}
+
 // IsCIDRRoutable tells if the network is routable
 func IsCIDRRoutable(cidr string) (bool, error) {
 	first, last, err := CIDRToIPv4Range(cidr)
@@ -106,6 +164,56 @@ func IsCIDRRoutable(cidr string) (bool, error) {
 	return true, nil
 }
 
+// NormalizeDNSServers validates the IP addresses of DNS servers and removes duplicates, keeping the order
+// An empty list is valid and means the provider defaults have to be used
+func NormalizeDNSServers(servers []string) ([]string, error) {
+	var result []string
+	seen := map[string]bool{}
+	for _, v := range servers {
+		v = strings.TrimSpace(v)
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return nil, fail.InvalidParameterError("servers", fmt.Sprintf("'%s' is not a valid IP address", v))
+		}
+		key := ip.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// ValidateFixedIP checks that 'ip' is an usable host address of the network 'cidr'
+// (neither the network address nor the broadcast address)
+func ValidateFixedIP(cidr string, ip string) error {
+	_, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fail.InvalidParameterError("cidr", fmt.Sprintf("'%s' is not a valid CIDR", cidr))
+	}
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil {
+		return fail.InvalidParameterError("ip", fmt.Sprintf("'%s' is not a valid IP address", ip))
+	}
+	if !ipnet.Contains(addr) {
+		return fail.InvalidParameterError("ip", fmt.Sprintf("'%s' is not in network '%s'", ip, cidr))
+	}
+	if ip4 := addr.To4(); ip4 != nil {
+		first, last, err := CIDRToLongRange(ipnet.String())
+		if err != nil {
+			return err
+		}
+		value := IPv4ToLong(ip4.String())
+		if value == first || value == last {
+			return fail.InvalidParameterError(
+				"ip", fmt.Sprintf("'%s' is the network or broadcast address of '%s'", ip, cidr),
+			)
+		}
+	}
+	return nil
+}
+
 func init() {
 	notRoutables := []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
 
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+func TestNormalizeDNSServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", nil, nil, false},
+		{"valid", []string{"8.8.8.8", "1.1.1.1"}, []string{"8.8.8.8", "1.1.1.1"}, false},
+		{"duplicates", []string{"8.8.8.8", " 1.1.1.1", "8.8.8.8"}, []string{"8.8.8.8", "1.1.1.1"}, false},
+		{"ipv6", []string{"2001:4860:4860::8888"}, []string{"2001:4860:4860::8888"}, false},
+		{"invalid", []string{"8.8.8.8", "dns.google"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDNSServers(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NormalizeDNSServers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeDNSServers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFixedIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidr    string
+		ip      string
+		wantErr bool
+	}{
+		{"valid", "192.168.1.0/24", "192.168.1.10", false},
+		{"unaligned cidr", "192.168.1.12/24", "192.168.1.10", false},
+		{"outside", "192.168.1.0/24", "192.168.2.10", true},
+		{"network address", "192.168.1.0/24", "192.168.1.0", true},
+		{"broadcast address", "192.168.1.0/24", "192.168.1.255", true},
+		{"invalid ip", "192.168.1.0/24", "192.168.1", true},
+		{"invalid cidr", "192.168.1.0", "192.168.1.10", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFixedIP(tt.cidr, tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFixedIP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllocateCIDR(t *testing.T) {
+	tests := []struct {
+		name      string
+		parent    string
+		used      []string
+		prefixLen int
+		want      string
+		wantErr   bool
+	}{
+		{"empty network", "10.0.0.0/16", nil, 24, "10.0.0.0/24", false},
+		{"after allocated", "10.0.0.0/16", []string{"10.0.0.0/24", "10.0.1.0/24"}, 24, "10.0.2.0/24", false},
+		{"gap between allocated", "10.0.0.0/16", []string{"10.0.2.0/24", "10.0.0.0/24"}, 24, "10.0.1.0/24", false},
+		{"gap too small", "10.0.0.0/16", []string{"10.0.0.0/24", "10.0.2.0/23"}, 23, "10.0.4.0/23", false},
+		{"aligned after small subnet", "10.0.0.0/16", []string{"10.0.0.0/26"}, 24, "10.0.1.0/24", false},
+		{"fills fragment", "10.0.0.0/24", []string{"10.0.0.0/26", "10.0.0.128/25"}, 26, "10.0.0.64/26", false},
+		{"ignores outside", "10.0.0.0/24", []string{"192.168.0.0/24"}, 25, "10.0.0.0/25", false},
+		{"end of address space", "255.255.255.0/24", []string{"255.255.255.0/25"}, 25, "255.255.255.128/25", false},
+		{"exhausted", "10.0.0.0/24", []string{"10.0.0.0/25", "10.0.0.128/26"}, 25, "", true},
+		{"prefix too short", "10.0.0.0/24", nil, 16, "", true},
+		{"invalid used", "10.0.0.0/24", []string{"10.0.0.0"}, 25, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AllocateCIDR(tt.parent, tt.used, tt.prefixLen)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AllocateCIDR() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("AllocateCIDR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateCIDRExhaustionIsOverflow(t *testing.T) {
+	_, err := AllocateCIDR("10.0.0.0/24", []string{"10.0.0.0/24"}, 26)
+	if _, ok := err.(fail.ErrOverflow); !ok {
+		t.Errorf("AllocateCIDR() error = %v (%T), want fail.ErrOverflow", err, err)
+	}
+}
@@ -18,8 +18,11 @@ package metadata
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas"
@@ -31,29 +34,37 @@ import (
 // Folder describes a metadata folder
 type Folder struct {
 	// path contains the base path where to read/write record in Object Storage
-	path     string
-	service  iaas.Service
-	crypt    bool
-	cryptKey *crypt.Key
+	path      string
+	service   iaas.Service
+	crypt     bool
+	encrypter crypt.Encrypter
 }
 
 // FolderDecoderCallback is the prototype of the function that will decode data read from Metadata
 type FolderDecoderCallback func([]byte) error
 
+// ErrStopBrowsing can be returned by a browse callback to stop browsing without error
+var ErrStopBrowsing = errors.New("stop browsing")
+
 // NewFolder creates a new Metadata Folder object, ready to help access the metadata inside it
+// 'path' is relative to the metadata prefix of the tenant, if any
 func NewFolder(svc iaas.Service, path string) (*Folder, error) {
 	if svc == nil {
 		return nil, fail.InvalidParameterError("svc", "cannot be nil!")
 	}
-	cryptKey := svc.GetMetadataKey()
-	crypto := cryptKey != nil && len(cryptKey) > 0
-	f := &Folder{
-		path:    strings.Trim(path, "/"),
-		service: svc,
-		crypt:   crypto,
+	encrypter := svc.GetMetadataEncrypter()
+	if encrypter == nil {
+		encrypter = crypt.NoopEncrypter{}
+	}
+	path = strings.Trim(path, "/")
+	if prefix := strings.Trim(svc.GetMetadataPrefix(), "/"); prefix != "" {
+		path = strings.Trim(prefix+"/"+path, "/")
 	}
-	if crypto {
-		f.cryptKey = cryptKey
+	f := &Folder{
+		path:      path,
+		service:   svc,
+		crypt:     encrypter.Enabled(),
+		encrypter: encrypter,
 	}
 	return f, nil
 }
@@ -68,7 +79,7 @@ func (f *Folder) GetBucket() objectstorage.Bucket {
 	return f.service.GetMetadataBucket()
 }
 
-// GetPath returns the base path of the folder
+// GetPath returns the base path of the folder, including the metadata prefix of the tenant
 func (f *Folder) GetPath() string {
 	return f.path
 }
@@ -140,7 +151,7 @@ func (f *Folder) Read(path string, name string, callback FolderDecoderCallback)
 	}
 	data := buffer.Bytes()
 	if f.crypt {
-		data, err = crypt.Decrypt(data, f.cryptKey)
+		data, err = f.encrypter.Decrypt(data)
 		if err != nil {
 			if _, ok := err.(fail.ErrNotFound); ok {
 				return fail.NotFoundError(fmt.Sprintf("failed to decrypt metadata '%s/%s': %v", path, name, err))
@@ -165,13 +176,9 @@ func (f *Folder) Write(path string, name string, content []byte) error {
 		err  error
 	)
 
-	if f.crypt {
-		data, err = crypt.Encrypt(content, f.cryptKey)
-		if err != nil {
-			return err
-		}
-	} else {
-		data = content
+	data, err = f.encrypter.Encrypt(content)
+	if err != nil {
+		return err
 	}
 
 	source := bytes.NewBuffer(data)
@@ -181,56 +188,130 @@ func (f *Folder) Write(path string, name string, content []byte) error {
 
 // Browse browses the content of a specific path in Metadata and executes 'cb' on each entry
 func (f *Folder) Browse(path string, callback FolderDecoderCallback) error {
-	list, err := f.service.GetMetadataBucket().List(f.absolutePath(path), objectstorage.NoPrefix)
+	_, err := f.BrowsePage(context.Background(), path, "", 0, callback)
+	return err
+}
+
+// BrowsePage browses the content of a specific path in Metadata in lexical order and executes 'callback' on each
+// entry, starting after the entry named 'marker' (from the first one if empty) and stopping after 'limit' entries
+// (0 meaning no limit).
+// Returns the marker to use to browse the next page, empty if all the entries have been browsed.
+// Entries are read one at a time, without holding any lock between them; the browsing stops with an ErrAborted if
+// ctx is cancelled, or without error if callback returns ErrStopBrowsing. In both cases, the marker returned
+// allows to resume after the last entry browsed.
+func (f *Folder) BrowsePage(ctx context.Context, path string, marker string, limit int, callback FolderDecoderCallback) (string, error) {
+	if ctx == nil {
+		return "", fail.InvalidParameterError("ctx", "cannot be nil")
+	}
+	if callback == nil {
+		return "", fail.InvalidParameterError("callback", "cannot be nil")
+	}
+
+	absPath := f.absolutePath(path)
+	list, err := f.service.GetMetadataBucket().List(absPath, objectstorage.NoPrefix)
 	if err != nil {
-		return fail.Wrap(err, "Error browsing metadata: listing objects")
+		return "", fail.Wrap(err, "Error browsing metadata: listing objects")
 	}
 
 	// Special case where there is only an empty folder...
-	if len(list) == 1 && list[0] == f.absolutePath(path) {
-		return nil
+	if len(list) == 1 && list[0] == absPath {
+		return "", nil
 	}
 
+	// Markers are the names of the entries relative to the browsed path
+	prefix := strings.Trim(absPath, "/") + "/"
+	names := make([]string, 0, len(list))
+	paths := make(map[string]string, len(list))
 	for _, i := range list {
-		var buffer bytes.Buffer
-		_, err = f.service.GetMetadataBucket().ReadObject(i, &buffer, 0, 0)
-		if err != nil {
-			return fail.Wrap(err, "Error browsing metadata: reading from buffer")
+		name := strings.TrimPrefix(i, prefix)
+		names = append(names, name)
+		paths[name] = i
+	}
+	return browseEntries(ctx, names, marker, limit, func(name string) error {
+		return f.browseEntry(paths[name], callback)
+	})
+}
+
+// browseEntries calls 'browse' on the entries of 'names' in lexical order, as described in Folder.BrowsePage
+func browseEntries(ctx context.Context, names []string, marker string, limit int, browse func(string) error) (string, error) {
+	sort.Strings(names)
+	start := 0
+	if marker != "" {
+		start = sort.SearchStrings(names, marker)
+		if start < len(names) && names[start] == marker {
+			start++
+		}
+	}
+
+	last := marker
+	for idx := start; idx < len(names); idx++ {
+		if limit > 0 && idx-start == limit {
+			return last, nil
 		}
-		data := buffer.Bytes()
-		if f.crypt {
-			dal := len(data)
-
-			data, err = crypt.Decrypt(data, f.cryptKey)
-			if err != nil {
-				if dal > 0 {
-					return fail.ForbiddenError(fmt.Sprintf("problem decrypting data with the key provided in (tenants.metadata.CryptKey): %s", err))
+		select {
+		case <-ctx.Done():
+			return last, fail.AbortedError("browsing metadata aborted", ctx.Err())
+		default:
+		}
+
+		err := browse(names[idx])
+		if err != nil {
+			if err == ErrStopBrowsing {
+				if idx+1 == len(names) {
+					return "", nil
 				}
-				return err
+				return names[idx], nil
 			}
+			return last, err
 		}
+		last = names[idx]
+	}
+	return "", nil
+}
+
+// browseEntry reads the metadata entry at 'path' and executes 'callback' on its content
+func (f *Folder) browseEntry(path string, callback FolderDecoderCallback) error {
+	var buffer bytes.Buffer
+	_, err := f.service.GetMetadataBucket().ReadObject(path, &buffer, 0, 0)
+	if err != nil {
+		return fail.Wrap(err, "Error browsing metadata: reading from buffer")
+	}
+	data := buffer.Bytes()
+	if f.crypt {
+		dal := len(data)
 
-		err = callback(data)
+		data, err = f.encrypter.Decrypt(data)
 		if err != nil {
-			if _, ok := err.(*json.SyntaxError); ok && strings.Contains(err.Error(), "invalid character") {
-				if f.crypt {
-					err = fail.SyntaxError(
-						fmt.Sprintf(
-							"seems metadata '%s' is crypted but there was a problem decrypting with the key provided in (tenants.metadata.CryptKey)", i,
-						),
-					)
-					return err
-				} else {
-					err = fail.SyntaxError(
-						fmt.Sprintf(
-							"seems metadata '%s' is unencrypted but a encryption key is provided in (tenants.metadata.CryptKey), this leads to a decryption error; please remove decryption key", i,
-						),
-					)
-					return err
-				}
+			if dal > 0 {
+				return fail.ForbiddenError(fmt.Sprintf("problem decrypting data with the key provided in (tenants.metadata.CryptKey): %s", err))
+			}
+			return err
+		}
+	}
+
+	err = callback(data)
+	if err != nil {
+		if err == ErrStopBrowsing {
+			return err
+		}
+		if _, ok := err.(*json.SyntaxError); ok && strings.Contains(err.Error(), "invalid character") {
+			if f.crypt {
+				err = fail.SyntaxError(
+					fmt.Sprintf(
+						"seems metadata '%s' is crypted but there was a problem decrypting with the key provided in (tenants.metadata.CryptKey)", path,
+					),
+				)
+				return err
+			} else {
+				err = fail.SyntaxError(
+					fmt.Sprintf(
+						"seems metadata '%s' is unencrypted but a encryption key is provided in (tenants.metadata.CryptKey), this leads to a decryption error; please remove decryption key", path,
+					),
+				)
+				return err
 			}
-			return fail.Wrap(err, "Error browsing metadata: running callback")
 		}
+		return fail.Wrap(err, "Error browsing metadata: running callback")
 	}
 	return nil
 }
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/CS-SI/SafeScale/lib/server/iaas"
+	"github.com/CS-SI/SafeScale/lib/server/iaas/objectstorage"
+	"github.com/CS-SI/SafeScale/lib/utils/crypt"
+	"github.com/CS-SI/SafeScale/lib/utils/fail"
+)
+
+// memoryBucket keeps the objects in memory; the calls not overridden panic
+type memoryBucket struct {
+	objectstorage.Bucket
+	objects map[string][]byte
+}
+
+func (b *memoryBucket) List(path, prefix string) ([]string, error) {
+	var list []string
+	for k := range b.objects {
+		if k == path || strings.HasPrefix(k, path+"/") {
+			list = append(list, k)
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func (b *memoryBucket) ReadObject(name string, w io.Writer, from, to int64) (objectstorage.Object, error) {
+	content, ok := b.objects[name]
+	if !ok {
+		return nil, fail.NotFoundError(fmt.Sprintf("object '%s' not found", name))
+	}
+	_, err := w.Write(content)
+	return nil, err
+}
+
+func (b *memoryBucket) WriteObject(name string, r io.Reader, size int64, _ objectstorage.ObjectMetadata) (objectstorage.Object, error) {
+	var buffer bytes.Buffer
+	_, err := buffer.ReadFrom(r)
+	b.objects[name] = buffer.Bytes()
+	return nil, err
+}
+
+func (b *memoryBucket) DeleteObject(name string) error {
+	delete(b.objects, name)
+	return nil
+}
+
+// prefixedService is a service using the metadata prefix 'prefix'; the calls not overridden panic
+type prefixedService struct {
+	iaas.Service
+	prefix string
+	bucket *memoryBucket
+}
+
+func (s prefixedService) GetMetadataBucket() objectstorage.Bucket {
+	return s.bucket
+}
+
+func (s prefixedService) GetMetadataEncrypter() crypt.Encrypter {
+	return crypt.NoopEncrypter{}
+}
+
+func (s prefixedService) GetMetadataPrefix() string {
+	return s.prefix
+}
+
+func TestBrowseEntriesPages(t *testing.T) {
+	names := []string{"e", "c", "a", "d", "b"}
+
+	var browsed []string
+	browse := func(name string) error {
+		browsed = append(browsed, name)
+		return nil
+	}
+	next, err := browseEntries(context.Background(), names, "", 2, browse)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", next)
+	next, err = browseEntries(context.Background(), names, next, 2, browse)
+	assert.Nil(t, err)
+	assert.Equal(t, "d", next)
+	next, err = browseEntries(context.Background(), names, next, 2, browse)
+	assert.Nil(t, err)
+	assert.Equal(t, "", next)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, browsed)
+}
+
+func TestBrowseEntriesStopsOnCallbackRequest(t *testing.T) {
+	var browsed []string
+	next, err := browseEntries(context.Background(), []string{"a", "b", "c"}, "", 0, func(name string) error {
+		browsed = append(browsed, name)
+		if name == "b" {
+			return ErrStopBrowsing
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "b", next)
+	assert.Equal(t, []string{"a", "b"}, browsed)
+}
+
+func TestBrowseEntriesCancelledMidIteration(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = fmt.Sprintf("host-%02d", i)
+	}
+	var browsed []string
+	next, err := browseEntries(ctx, names, "", 0, func(name string) error {
+		browsed = append(browsed, name)
+		if len(browsed) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	assert.NotNil(t, err)
+	_, ok := err.(fail.ErrAborted)
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(browsed))
+	assert.Equal(t, "host-02", next)
+
+	// Browsing resumes after the last entry browsed
+	browsed = nil
+	next, err = browseEntries(context.Background(), names, next, 0, func(name string) error {
+		browsed = append(browsed, name)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", next)
+	assert.Equal(t, names[3:], browsed)
+}
+
+func TestFolderUsesMetadataPrefix(t *testing.T) {
+	bucket := &memoryBucket{objects: map[string][]byte{}}
+	dev := prefixedService{prefix: "/safescale/dev/", bucket: bucket}
+	prod := prefixedService{prefix: "safescale/prod", bucket: bucket}
+
+	devFolder, err := NewFolder(dev, "hosts")
+	assert.Nil(t, err)
+	assert.Equal(t, "safescale/dev/hosts", devFolder.GetPath())
+	prodFolder, err := NewFolder(prod, "hosts")
+	assert.Nil(t, err)
+
+	assert.Nil(t, devFolder.Write("byID", "id1", []byte("dev")))
+	assert.Nil(t, prodFolder.Write("byID", "id1", []byte("prod")))
+	_, ok := bucket.objects["safescale/dev/hosts/byID/id1"]
+	assert.True(t, ok)
+	_, ok = bucket.objects["safescale/prod/hosts/byID/id1"]
+	assert.True(t, ok)
+
+	// The same keys of each deployment don't collide
+	var content string
+	err = devFolder.Read("byID", "id1", func(data []byte) error {
+		content = string(data)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", content)
+
+	// Browse only walks the namespace of the deployment
+	var browsed []string
+	err = prodFolder.Browse("byID", func(data []byte) error {
+		browsed = append(browsed, string(data))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prod"}, browsed)
+
+	assert.Nil(t, devFolder.Delete("byID", "id1"))
+	_, ok = bucket.objects["safescale/prod/hosts/byID/id1"]
+	assert.True(t, ok)
+
+	// Without prefix, the paths are unchanged
+	folder, err := NewFolder(prefixedService{bucket: bucket}, "/hosts/")
+	assert.Nil(t, err)
+	assert.Equal(t, "hosts", folder.GetPath())
+}
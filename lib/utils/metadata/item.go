@@ -17,6 +17,7 @@
 package metadata
 
 import (
+	"context"
 	"sync"
 
 	"github.com/CS-SI/SafeScale/lib/server/iaas"
@@ -193,6 +194,19 @@ func (i *Item) Browse(callback func([]byte) error) error {
 	return i.BrowseInto(".", callback)
 }
 
+// BrowsePageInto walks through a page of the entries of a subfolder of item folder and executes a callback for each
+// entry; see Folder.BrowsePage for details
+func (i *Item) BrowsePageInto(ctx context.Context, path string, marker string, limit int, callback func([]byte) error) (string, error) {
+	if callback == nil {
+		return "", fail.InvalidParameterError("callback", "cannot be nil!")
+	}
+
+	if path == "" {
+		path = "."
+	}
+	return i.folder.BrowsePage(ctx, path, marker, limit, callback)
+}
+
 // Acquire waits until the lock is available, then locks the metadata
 func (i *Item) Acquire() {
 	i.lock.Lock()
@@ -18,6 +18,8 @@ package utils
 
 import (
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/CS-SI/SafeScale/lib/utils/fail"
 
@@ -39,6 +41,45 @@ func GeneratePassword(length uint8) (string, error) {
 	return pass, nil
 }
 
+// ValidatePassword checks that pass is complex enough to be used as a user password on a host: at least 12
+// characters, using at least 3 of the 4 classes lowercase letters, uppercase letters, digits and symbols
+// Characters that cannot be passed safely to a shell (quotes, backslash, '$', spaces and control characters) are refused.
+func ValidatePassword(pass string) error {
+	if len(pass) < 12 {
+		return fail.InvalidParameterError("password", "must contain at least 12 characters")
+	}
+	if strings.ContainsAny(pass, "'\"\\`$") {
+		return fail.InvalidParameterError("password", "cannot contain any of the characters '\"\\`$")
+	}
+	var lower, upper, digit, symbol bool
+	for _, r := range pass {
+		switch {
+		case unicode.IsSpace(r) || unicode.IsControl(r):
+			return fail.InvalidParameterError("password", "cannot contain spaces or control characters")
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return fail.InvalidParameterError(
+			"password", "must contain at least 3 of lowercase letters, uppercase letters, digits and symbols",
+		)
+	}
+	return nil
+}
+
 func init() {
 	var err error
 	// generator is created with characters allowed
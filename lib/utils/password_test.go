@@ -0,0 +1,60 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{"valid", "Sfc-2020.pass", false},
+		{"too short", "Sf-2020.p", true},
+		{"no uppercase", "sfc-2020.pass", false},
+		{"only letters and digits", "sfc2020passwd", true},
+		{"only letters and symbols", "Sfc-abcd.pass", false},
+		{"only lowercase and symbols", "sfc-abcd.pass", true},
+		{"colon", "Sfc:2020.pass", false},
+		{"quote", "Sfc'2020.pass", true},
+		{"dollar", "Sfc$2020.pass", true},
+		{"space", "Sfc 2020.pass", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePassword(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePassword(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratedPasswordIsValid(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pass, err := GeneratePassword(16)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidatePassword(pass); err != nil {
+			t.Errorf("generated password %q is not valid: %v", pass, err)
+		}
+	}
+}
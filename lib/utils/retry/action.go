@@ -21,6 +21,7 @@ package retry
 
 import (
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -99,6 +100,33 @@ func BackoffSelector() Backoff {
 	return Constant
 }
 
+// helperOfficer returns the officer of the helpers retrying every 'delay': the algorithm set by SAFESCALE_ALGO_DELAY
+// if any, else the jittered implementation with a constant delay, randomized as set by SAFESCALE_RETRY_JITTER
+func helperOfficer(delay time.Duration) *Officer {
+	if os.Getenv("SAFESCALE_ALGO_DELAY") != "" {
+		return BackoffSelector()(delay)
+	}
+	return Jitter(delay, delay, helpersJitter())
+}
+
+// helpersJitter returns the fraction of the delay of the helpers which is randomized, read from SAFESCALE_RETRY_JITTER
+// (between 0 and 1); there is no jitter by default
+func helpersJitter() float64 {
+	value := os.Getenv("SAFESCALE_RETRY_JITTER")
+	if value == "" {
+		return 0
+	}
+	jitter, err := strconv.ParseFloat(value, 64)
+	if err != nil || jitter < 0 {
+		logrus.Warnf("invalid value '%s' of SAFESCALE_RETRY_JITTER, ignored", value)
+		return 0
+	}
+	if jitter > 1 {
+		return 1
+	}
+	return jitter
+}
+
 // WhileUnsuccessful retries every 'delay' while 'run' is unsuccessful with a 'timeout'
 func WhileUnsuccessful(run func() error, delay time.Duration, timeout time.Duration) error {
 	if delay > timeout {
@@ -109,6 +137,51 @@ func WhileUnsuccessful(run func() error, delay time.Duration, timeout time.Durat
 	if delay <= 0 {
 		delay = time.Second
 	}
+	return whileUnsuccessful(run, helperOfficer(delay), timeout, nil).loop()
+}
+
+// WhileUnsuccessfulWithLimit retries every 'delay' while 'run' is unsuccessful, at most 'maxAttempts' times (0 meaning
+// no limit), expiring after 'timeout'
+// If the attempts are exhausted before the timeout, returns a fail.ErrTimeout wrapping the last error of 'run'.
+func WhileUnsuccessfulWithLimit(run func() error, delay time.Duration, timeout time.Duration, maxAttempts uint) error {
+	if timeout > 0 && delay > timeout {
+		logrus.Warnf("unexpected: delay greater than timeout ?? : (%s) > (%s)", delay, timeout)
+		delay = timeout / 4
+	}
+
+	if delay <= 0 {
+		delay = time.Second
+	}
+	a := whileUnsuccessful(run, helperOfficer(delay), timeout, nil)
+	if maxAttempts > 0 {
+		a.Arbiter = PrevailDone(a.Arbiter, Attempts(maxAttempts))
+	}
+	return a.loop()
+}
+
+// WhileUnsuccessfulWithJitter retries while 'run' is unsuccessful, waiting after each try a delay doubling from 'base'
+// up to 'max' and randomized, so concurrent retries do not hit the provider at the same time; expires after 'timeout'
+func WhileUnsuccessfulWithJitter(run func() error, base time.Duration, max time.Duration, timeout time.Duration) error {
+	if base <= 0 {
+		base = time.Second
+	}
+	if max < base {
+		max = base
+	}
+	if timeout > 0 && max > timeout {
+		logrus.Warnf("unexpected: max delay greater than timeout ?? : (%s) > (%s)", max, timeout)
+		max = timeout / 4
+		if base > max {
+			base = max
+		}
+	}
+
+	return whileUnsuccessful(run, ExponentialJitter(base, max), timeout, nil).loop()
+}
+
+// whileUnsuccessful builds the action shared by the WhileUnsuccessful helpers, retrying 'run' while unsuccessful,
+// waiting after each try the delay decided by 'officer', expiring after 'timeout'
+func whileUnsuccessful(run func() error, officer *Officer, timeout time.Duration, notify Notify) action {
 	var arbiter Arbiter
 	if timeout <= 0 {
 		arbiter = Unsuccessful()
@@ -117,12 +190,12 @@ func WhileUnsuccessful(run func() error, delay time.Duration, timeout time.Durat
 	}
 	return action{
 		Arbiter: arbiter,
-		Officer: BackoffSelector()(delay),
+		Officer: officer,
 		Run:     run,
 		First:   nil,
 		Last:    nil,
-		Notify:  nil,
-	}.loop()
+		Notify:  notify,
+	}
 }
 
 // WhileUnsuccessfulTimeout retries every 'delay' while 'run' is unsuccessful with a 'timeout'
@@ -135,20 +208,7 @@ func WhileUnsuccessfulTimeout(run func() error, delay time.Duration, timeout tim
 	if delay <= 0 {
 		delay = time.Second
 	}
-	var arbiter Arbiter
-	if timeout <= 0 {
-		arbiter = Unsuccessful()
-	} else {
-		arbiter = PrevailDone(Unsuccessful(), Timeout(timeout))
-	}
-	return action{
-		Arbiter: arbiter,
-		Officer: BackoffSelector()(delay),
-		Run:     run,
-		First:   nil,
-		Last:    nil,
-		Notify:  nil,
-	}.loopWithTimeout(timeout)
+	return whileUnsuccessful(run, helperOfficer(delay), timeout, nil).loopWithTimeout(timeout)
 }
 
 // WhileUnsuccessfulDelay1Second retries while 'run' is unsuccessful (ie 'run' returns an error != nil),
@@ -183,20 +243,7 @@ func WhileUnsuccessfulWithNotify(run func() error, delay time.Duration, timeout
 	if delay <= 0 {
 		delay = time.Second
 	}
-	var arbiter Arbiter
-	if timeout <= 0 {
-		arbiter = Unsuccessful()
-	} else {
-		arbiter = PrevailDone(Unsuccessful(), Timeout(timeout))
-	}
-	return action{
-		Arbiter: arbiter,
-		Officer: BackoffSelector()(delay),
-		Run:     run,
-		First:   nil,
-		Last:    nil,
-		Notify:  notify,
-	}.loop()
+	return whileUnsuccessful(run, helperOfficer(delay), timeout, notify).loop()
 }
 
 // WhileUnsuccessfulWhereRetcode255WithNotify retries while 'run' is unsuccessful (ie 'run' returns an error != nil
@@ -222,7 +269,7 @@ func WhileUnsuccessfulWhereRetcode255WithNotify(run func() error, delay time.Dur
 	}
 	return action{
 		Arbiter: arbiter,
-		Officer: BackoffSelector()(delay),
+		Officer: helperOfficer(delay),
 		Run:     run,
 		First:   nil,
 		Last:    nil,
@@ -270,7 +317,7 @@ func WhileSuccessful(run func() error, delay time.Duration, timeout time.Duratio
 	}
 	return action{
 		Arbiter: arbiter,
-		Officer: BackoffSelector()(delay),
+		Officer: helperOfficer(delay),
 		Run:     run,
 		First:   nil,
 		Last:    nil,
@@ -314,7 +361,7 @@ func WhileSuccessfulWithNotify(run func() error, delay time.Duration, timeout ti
 	}
 	return action{
 		Arbiter: arbiter,
-		Officer: BackoffSelector()(delay),
+		Officer: helperOfficer(delay),
 		Run:     run,
 		First:   nil,
 		Last:    nil,
@@ -19,6 +19,7 @@ package retry
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -451,3 +452,70 @@ func TestRefactorSwitch(t *testing.T) {
 		t.Error("Unexpected problem")
 	}
 }
+
+func TestWhileUnsuccessfulWithLimitExhaustsAttempts(t *testing.T) {
+	calls := 0
+	err := WhileUnsuccessfulWithLimit(
+		func() error {
+			calls++
+			return fmt.Errorf("always fails")
+		},
+		10*time.Millisecond, 5*time.Second, 3,
+	)
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if _, ok := err.(fail.ErrTimeout); !ok {
+		t.Fatalf("expected a fail.ErrTimeout, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("expected the number of attempts in the error, got '%s'", err.Error())
+	}
+	if fail.Cause(err).Error() != "always fails" {
+		t.Errorf("expected the last error as cause, got '%v'", fail.Cause(err))
+	}
+}
+
+func TestWhileUnsuccessfulWithLimitTimesOut(t *testing.T) {
+	calls := 0
+	begin := time.Now()
+	err := WhileUnsuccessfulWithLimit(
+		func() error {
+			calls++
+			return fmt.Errorf("always fails")
+		},
+		10*time.Millisecond, 100*time.Millisecond, 1000,
+	)
+	if time.Since(begin) > 2*time.Second {
+		t.Errorf("timeout not respected")
+	}
+	if calls >= 1000 {
+		t.Errorf("expected the timeout to stop the attempts, got %d attempts", calls)
+	}
+	if _, ok := err.(fail.ErrTimeout); !ok {
+		t.Fatalf("expected a fail.ErrTimeout, got %T: %v", err, err)
+	}
+	if strings.Contains(err.Error(), "attempts") {
+		t.Errorf("expected a timeout error, got '%s'", err.Error())
+	}
+}
+
+func TestWhileUnsuccessfulWithLimitSucceeds(t *testing.T) {
+	calls := 0
+	err := WhileUnsuccessfulWithLimit(
+		func() error {
+			calls++
+			if calls < 2 {
+				return fmt.Errorf("fails once")
+			}
+			return nil
+		},
+		10*time.Millisecond, 5*time.Second, 3,
+	)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
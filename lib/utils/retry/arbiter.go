@@ -216,3 +216,33 @@ func Max(limit uint) Arbiter {
 		return verdict.Done, nil
 	}
 }
+
+// Attempts returns Abort with a timeout error after a limited number of tries, for callers bounded by a timeout
+// that also want to bound the number of calls
+func Attempts(limit uint) Arbiter {
+	return func(t Try) (verdict.Enum, error) {
+		if t.Err != nil {
+			if _, ok := t.Err.(ErrAborted); ok {
+				return verdict.Done, t.Err
+			}
+
+			if _, ok := t.Err.(*ErrAborted); ok {
+				return verdict.Done, t.Err
+			}
+
+			if _, ok := t.Err.(fail.ErrRuntimePanic); ok {
+				return verdict.Done, t.Err
+			}
+
+			if _, ok := t.Err.(*fail.ErrRuntimePanic); ok {
+				return verdict.Done, t.Err
+			}
+
+			if t.Count >= limit {
+				return verdict.Abort, AttemptsExhaustedError(t.Count, time.Since(t.Start), t.Err)
+			}
+			return verdict.Retry, nil
+		}
+		return verdict.Done, nil
+	}
+}
@@ -17,6 +17,12 @@ func TimeoutError(limit time.Duration, err error) ErrTimeout {
 	return fail.TimeoutError(msg, limit, err)
 }
 
+// AttemptsExhaustedError returns the timeout error of retries stopped after 'count' attempts
+func AttemptsExhaustedError(count uint, elapsed time.Duration, err error) ErrTimeout {
+	msg := fmt.Sprintf("retries stopped after %d attempts in %s", count, temporal.FormatDuration(elapsed))
+	return fail.TimeoutError(msg, elapsed, err)
+}
+
 // ErrLimit is used when a limit is reached.
 type ErrLimit = fail.ErrOverflow
 
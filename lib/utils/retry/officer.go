@@ -2,9 +2,20 @@ package retry
 
 import (
 	"math"
+	"math/rand"
+	"sync"
 	"time"
 )
 
+// defaultJitter is the fraction of the delay randomized by ExponentialJitter
+const defaultJitter = 0.5
+
+var (
+	jitterLock sync.Mutex
+	// jitterRand is the source of randomness of ExponentialJitter
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
 // Officer sleeps or selects any amount of time for each try
 type Officer struct {
 	Block func(Try)
@@ -69,3 +80,54 @@ func Fibonacci(duration time.Duration) *Officer {
 	}
 	return &o
 }
+
+// SeedJitter seeds the randomness of ExponentialJitter, making the sequence of its delays deterministic (for tests)
+func SeedJitter(seed int64) {
+	jitterLock.Lock()
+	defer jitterLock.Unlock()
+
+	jitterRand = rand.New(rand.NewSource(seed))
+}
+
+// ExponentialJitter sleeps for duration base * 2^(tries-1), bounded by max, of which the upper half is randomized
+// to spread the tries of concurrent actions
+func ExponentialJitter(base time.Duration, max time.Duration) *Officer {
+	return Jitter(base, max, defaultJitter)
+}
+
+// Jitter sleeps for duration base * 2^(tries-1), bounded by max, reduced by a random part of at most 'jitter'
+// (between 0 and 1) of it; with max equal to base and a jitter of 0, it sleeps for duration base like Constant
+func Jitter(base time.Duration, max time.Duration, jitter float64) *Officer {
+	o := Officer{
+		Block: func(t Try) {
+			jitterLock.Lock()
+			delay := jitteredDelay(t.Count, base, max, jitter, jitterRand)
+			jitterLock.Unlock()
+			time.Sleep(delay)
+		},
+	}
+	return &o
+}
+
+// jitteredDelay returns base * 2^(count-1) bounded by max, reduced by a random part of at most 'jitter' (between 0 and 1)
+// of it; a jitter of 0 gives the exponential delay without randomization
+func jitteredDelay(count uint, base time.Duration, max time.Duration, jitter float64, rnd *rand.Rand) time.Duration {
+	if max < base {
+		max = base
+	}
+	delay := base
+	for i := uint(1); i < count && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	if jitter <= 0 || rnd == nil {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return delay - time.Duration(rnd.Float64()*jitter*float64(delay))
+}
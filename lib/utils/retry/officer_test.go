@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package retry
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitteredDelay(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+
+	expected := []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second,
+		time.Second,
+	}
+	for i, want := range expected {
+		assert.Equal(t, want, jitteredDelay(uint(i+1), base, max, 0, nil))
+	}
+
+	rnd1, rnd2 := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+	for i, full := range expected {
+		got := jitteredDelay(uint(i+1), base, max, defaultJitter, rnd1)
+		assert.True(t, got >= full/2 && got <= full, fmt.Sprintf("delay %s out of bounds for try %d", got, i+1))
+		assert.Equal(t, got, jitteredDelay(uint(i+1), base, max, defaultJitter, rnd2))
+	}
+}
+
+func TestWhileUnsuccessfulWithJitter(t *testing.T) {
+	SeedJitter(1)
+
+	tries := 0
+	err := WhileUnsuccessfulWithJitter(
+		func() error {
+			tries++
+			if tries < 3 {
+				return fmt.Errorf("not yet")
+			}
+			return nil
+		}, time.Millisecond, 5*time.Millisecond, time.Second,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, tries)
+
+	err = WhileUnsuccessfulWithJitter(
+		func() error {
+			return fmt.Errorf("never")
+		}, time.Millisecond, 5*time.Millisecond, 50*time.Millisecond,
+	)
+	assert.NotNil(t, err)
+}
+
+func TestHelpersJitter(t *testing.T) {
+	defer func() {
+		_ = os.Unsetenv("SAFESCALE_RETRY_JITTER")
+	}()
+
+	_ = os.Unsetenv("SAFESCALE_RETRY_JITTER")
+	assert.Equal(t, float64(0), helpersJitter())
+
+	for value, expected := range map[string]float64{"0.25": 0.25, "3": 1, "-1": 0, "wrong": 0} {
+		_ = os.Setenv("SAFESCALE_RETRY_JITTER", value)
+		assert.Equal(t, expected, helpersJitter(), value)
+	}
+}
+
+func TestHelpersDelegateToJitterWithoutRandomization(t *testing.T) {
+	_ = os.Unsetenv("SAFESCALE_ALGO_DELAY")
+	_ = os.Unsetenv("SAFESCALE_RETRY_JITTER")
+
+	var tries []time.Time
+	err := WhileUnsuccessful(
+		func() error {
+			tries = append(tries, time.Now())
+			if len(tries) < 3 {
+				return fmt.Errorf("not yet")
+			}
+			return nil
+		}, 20*time.Millisecond, time.Second,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(tries))
+	for i := 1; i < len(tries); i++ {
+		assert.True(t, tries[i].Sub(tries[i-1]) >= 20*time.Millisecond)
+	}
+}
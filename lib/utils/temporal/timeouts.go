@@ -17,6 +17,7 @@
 package temporal
 
 import (
+	"context"
 	"os"
 	"time"
 
@@ -126,3 +127,25 @@ func GetExecutionTimeout() time.Duration {
 func GetLongOperationTimeout() time.Duration {
 	return GetTimeoutFromEnv("SAFESCALE_HOST_LONG_OPERATION_TIMEOUT", LongHostOperationTimeout)
 }
+
+// EffectiveTimeout returns the smallest duration between 'fallback' and the time remaining before the deadline of
+// 'ctx'; if ctx is nil or has no deadline, returns 'fallback'
+// If the deadline is already reached, returns a very small duration (and never 0 or less, as many callers interpret
+// such a value as "no timeout").
+func EffectiveTimeout(ctx context.Context, fallback time.Duration) time.Duration {
+	if ctx == nil {
+		return fallback
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return fallback
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return time.Millisecond
+	}
+	if fallback > 0 && fallback < remaining {
+		return fallback
+	}
+	return remaining
+}
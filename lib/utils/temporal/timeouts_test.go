@@ -0,0 +1,44 @@
+/*
+ * Copyright 2018-2020, CS Systemes d'Information, http://csgroup.eu
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package temporal
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEffectiveTimeout(t *testing.T) {
+	if got := EffectiveTimeout(context.Background(), time.Minute); got != time.Minute {
+		t.Errorf("without deadline, expected %v, got %v", time.Minute, got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if got := EffectiveTimeout(ctx, time.Minute); got > 10*time.Second || got < 9*time.Second {
+		t.Errorf("expected the remaining time before deadline, got %v", got)
+	}
+	if got := EffectiveTimeout(ctx, time.Second); got != time.Second {
+		t.Errorf("expected fallback %v, got %v", time.Second, got)
+	}
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	if got := EffectiveTimeout(expired, time.Minute); got <= 0 || got > time.Second {
+		t.Errorf("expected a very small positive duration, got %v", got)
+	}
+}